@@ -0,0 +1,140 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+)
+
+const (
+	assignNextOrderBy = " ORDER BY priority DESC, created ASC"
+
+	assignStatsMaxResults = 100
+
+	unassignedName = "Unassigned"
+)
+
+// instanceDefaultJQL returns the default query of the instance, set with
+// `/jira instance set-jql-default`.
+func instanceDefaultJQL(instance Instance) (string, error) {
+	jql := instance.Common().DefaultJQL
+	if jql == "" {
+		return "", errors.Errorf("%s has no default query. A system administrator can set one with `/jira instance set-jql-default`", instance.GetID())
+	}
+	return jql, nil
+}
+
+// assignNextJQL returns the query of the unassigned issues of the default
+// query, in its order, or else from the highest priority and the oldest.
+func assignNextJQL(defaultJQL string) string {
+	orderBy := jqlOrderByRegexp.FindString(defaultJQL)
+	if orderBy == "" {
+		orderBy = assignNextOrderBy
+	}
+	return "(" + stripJQLOrderBy(defaultJQL) + ") AND assignee is EMPTY" + orderBy
+}
+
+// mdAssigneeStats counts the issues by assignee, the most loaded first.
+func mdAssigneeStats(issues []jira.Issue, total int) string {
+	counts := map[string]int{}
+	for _, issue := range issues {
+		name := unassignedName
+		if issue.Fields != nil && issue.Fields.Assignee != nil {
+			name = issue.Fields.Assignee.DisplayName
+		}
+		counts[name]++
+	}
+	names := []string{}
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	rows := []string{}
+	for _, name := range names {
+		rows = append(rows, fmt.Sprintf("* **%s**: %d", name, counts[name]))
+	}
+	msg := strings.Join(rows, "\n")
+	if total > len(issues) {
+		msg += fmt.Sprintf("\n\nOnly the first %d of the %d issues are counted.", len(issues), total)
+	}
+	return msg
+}
+
+func executeAssignNext(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	if len(args) != 0 {
+		return p.responsef(header, "Please use `/jira assign next`.")
+	}
+	jql, err := instanceDefaultJQL(instance)
+	if err != nil {
+		return p.responsef(header, "%v.", err)
+	}
+
+	client, _, connection, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	issues, err := client.SearchIssues(assignNextJQL(jql), &jira.SearchOptions{
+		MaxResults: 1,
+		Fields:     []string{"summary"},
+	})
+	if err != nil {
+		return p.responsef(header, "Failed to search the issues. Error: %v.", err)
+	}
+	if len(issues) == 0 {
+		return p.responsef(header, "No issue of the default query of %s is unassigned.", instance.GetID())
+	}
+
+	msg, err := p.AssignIssue(instance, user.MattermostUserID, issues[0].Key, "", &connection.User)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	return p.responsef(header, "%s", msg)
+}
+
+func executeAssignStats(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	if len(args) != 0 {
+		return p.responsef(header, "Please use `/jira assign stats`.")
+	}
+	jql, err := instanceDefaultJQL(instance)
+	if err != nil {
+		return p.responsef(header, "%v.", err)
+	}
+
+	client, _, _, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	issues, total, err := client.SearchIssuesWithTotal(jql, &jira.SearchOptions{
+		MaxResults: assignStatsMaxResults,
+		Fields:     []string{"assignee"},
+	})
+	if err != nil {
+		return p.responsef(header, "Failed to search the issues. Error: %v.", err)
+	}
+	if total == 0 {
+		return p.responsef(header, "No issue matches the default query of %s.", instance.GetID())
+	}
+	return p.responsef(header, "#### Assignees of the %d issue(s) of the default query of %s\n%s", total, instance.GetID(), mdAssigneeStats(issues, total))
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignNextJQL(t *testing.T) {
+	assert.Equal(t, "(project = KT AND statusCategory != Done) AND assignee is EMPTY ORDER BY priority DESC, created ASC",
+		assignNextJQL("project = KT AND statusCategory != Done"))
+	assert.Equal(t, "(project = KT OR project = OPS) AND assignee is EMPTY ORDER BY rank ASC",
+		assignNextJQL("project = KT OR project = OPS ORDER BY rank ASC"))
+}
+
+func TestMdAssigneeStats(t *testing.T) {
+	assigned := func(name string) jira.Issue {
+		return jira.Issue{Fields: &jira.IssueFields{Assignee: &jira.User{DisplayName: name}}}
+	}
+	issues := []jira.Issue{
+		assigned("Bob"),
+		{Fields: &jira.IssueFields{}},
+		assigned("Alice"),
+		assigned("Bob"),
+		{Fields: &jira.IssueFields{}},
+		assigned("Carol"),
+	}
+
+	assert.Equal(t, "* **Bob**: 2\n* **Unassigned**: 2\n* **Alice**: 1\n* **Carol**: 1", mdAssigneeStats(issues, len(issues)))
+	assert.Equal(t, "* **Alice**: 1\n\nOnly the first 1 of the 250 issues are counted.", mdAssigneeStats(issues[2:3], 250))
+}
@@ -10,12 +10,25 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/kvstore"
 	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
 )
 
 const userRedirectPageKey = "user-redirect"
 
 func (p *Plugin) httpACJSON(w http.ResponseWriter, r *http.Request, instanceID types.ID) (int, error) {
+	p.client.Log.Info("Atlassian Connect: serving the app descriptor", "instance", instanceID.String())
+
+	// Jira may take longer than the setup expiry to fetch the descriptor,
+	// and retry the installation later. Keep the instance waiting for its
+	// installed callback while Jira is still fetching the descriptor.
+	instance, err := p.instanceStore.LoadInstance(instanceID)
+	if ci, ok := instance.(*cloudInstance); err == nil && ok && !ci.Installed {
+		if err = p.instanceStore.CreateInactiveCloudInstance(instanceID, ci.SetupWizardUserID); err != nil {
+			p.client.Log.Warn("Atlassian Connect: failed to extend the setup of the instance", "instance", instanceID.String(), "error", err.Error())
+		}
+	}
+
 	return p.respondTemplate(w, r, "application/json", map[string]string{
 		"BaseURL":                      p.GetPluginURL(),
 		"RouteACJSON":                  instancePath(routeACJSON, instanceID),
@@ -42,10 +55,15 @@ func (p *Plugin) httpACInstalled(w http.ResponseWriter, r *http.Request) (int, e
 			errors.WithMessage(err, "failed to unmarshal request"))
 	}
 	instanceID := types.ID(asc.BaseURL)
+	p.client.Log.Info("Atlassian Connect: received the installed callback", "instance", asc.BaseURL)
 
-	// Only allow this operation once, a JIRA instance must already exist
-	// for asc.BaseURL, but not already installed.
+	// A JIRA instance must already exist for asc.BaseURL. It is either
+	// waiting for this callback, or already installed by an earlier one.
 	instance, err := p.instanceStore.LoadInstance(instanceID)
+	if errors.Cause(err) == kvstore.ErrNotFound {
+		return respondErr(w, http.StatusNotFound,
+			errors.Errorf("Jira instance %s must first be added to Mattermost, or its setup has expired", asc.BaseURL))
+	}
 	if err != nil {
 		return respondErr(w, http.StatusInternalServerError,
 			errors.WithMessage(err, "failed to load instance "+asc.BaseURL))
@@ -59,25 +77,54 @@ func (p *Plugin) httpACInstalled(w http.ResponseWriter, r *http.Request) (int, e
 		return respondErr(w, http.StatusBadRequest,
 			errors.Errorf("Must be a JIRA Cloud instance, is %s", instance.Common().Type))
 	}
-	if ci.Installed {
+
+	// Jira retries the callback when it times out waiting for us, so the
+	// same installation may arrive more than once. It is completed again,
+	// in case the first attempt failed halfway. Any other installation of
+	// an installed instance is refused.
+	repeated := ci.Installed
+	if repeated && !ci.isSameInstallation(&asc) {
 		return respondErr(w, http.StatusForbidden,
 			errors.Errorf("Jira instance %s is already installed", asc.BaseURL))
 	}
+	if repeated {
+		p.client.Log.Info("Atlassian Connect: the instance is already installed, completing its installation again", "instance", asc.BaseURL)
+	}
 
 	// Create a permanent instance record, also store it as current
 	newInstance := newCloudInstance(p, instanceID, true, string(body), &asc)
+	newInstance.SetupWizardUserID = ci.SetupWizardUserID
 	err = p.InstallInstance(newInstance)
 	if err != nil {
+		p.client.Log.Warn("Atlassian Connect: failed to install the instance", "instance", asc.BaseURL, "error", err.Error())
+		if !repeated {
+			// Put back the expiring record so that a retry of the callback
+			// can complete the installation, and an abandoned one leaves
+			// nothing behind. The installed record may have been stored
+			// already, and would not be replaced.
+			restoreErr := p.instanceStore.DeleteInstance(instanceID)
+			if restoreErr == nil {
+				restoreErr = p.instanceStore.CreateInactiveCloudInstance(instanceID, ci.SetupWizardUserID)
+			}
+			if restoreErr != nil {
+				p.client.Log.Warn("Atlassian Connect: failed to restore the pending instance", "instance", asc.BaseURL, "error", restoreErr.Error())
+			}
+		}
 		return respondErr(w, http.StatusInternalServerError, err)
 	}
+	p.client.Log.Info("Atlassian Connect: stored the installed instance", "instance", asc.BaseURL)
 
 	// Setup autolink
 	err = p.AddAutolinksForCloudInstance(newInstance)
 	if err != nil {
 		p.client.Log.Info("could not install autolinks for cloud instance", "instance", ci.BaseURL, "err", err)
+	} else {
+		p.client.Log.Info("Atlassian Connect: installed the autolinks", "instance", asc.BaseURL)
 	}
 
-	_ = p.setupFlow.ForUser(ci.SetupWizardUserID).Go(stepInstalledJiraApp)
+	if !repeated {
+		_ = p.setupFlow.ForUser(ci.SetupWizardUserID).Go(stepInstalledJiraApp)
+	}
 
 	return respondJSON(w, []string{"OK"})
 }
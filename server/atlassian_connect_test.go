@@ -0,0 +1,219 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/enterprise"
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/kvstore"
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// installStore keeps a single instance record, and can fail to store the
+// list of instances.
+type installStore struct {
+	mockInstanceStore
+	instance          Instance
+	pending           int
+	storeInstancesErr error
+}
+
+func (store *installStore) CreateInactiveCloudInstance(id types.ID, actingUserID string) error {
+	store.pending++
+	ci := newCloudInstance(nil, id, false, "", &AtlassianSecurityContext{BaseURL: id.String()})
+	ci.SetupWizardUserID = actingUserID
+	store.instance = ci
+	return nil
+}
+
+func (store *installStore) LoadInstance(id types.ID) (Instance, error) {
+	if store.instance == nil {
+		return nil, errors.Wrap(kvstore.ErrNotFound, id.String())
+	}
+	return store.instance, nil
+}
+
+func (store *installStore) DeleteInstance(types.ID) error {
+	store.instance = nil
+	return nil
+}
+
+func (store *installStore) StoreInstance(instance Instance) error {
+	store.instance = instance
+	return nil
+}
+
+func (store *installStore) StoreInstances(*Instances) error {
+	return store.storeInstancesErr
+}
+
+func TestACInstalled(t *testing.T) {
+	// Jira answers no request, so that the autolinks are not installed.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	asc := AtlassianSecurityContext{
+		Key:          "mattermost-plugin-jira",
+		ClientKey:    "client-key",
+		SharedSecret: "shared-secret",
+		BaseURL:      ts.URL,
+	}
+	installed := func(p *Plugin) *cloudInstance {
+		data, _ := json.Marshal(asc)
+		return newCloudInstance(p, types.ID(ts.URL), true, string(data), &asc)
+	}
+
+	for name, tc := range map[string]struct {
+		instance          func(p *Plugin) Instance
+		callback          AtlassianSecurityContext
+		storeInstancesErr error
+		expectedStatus    int
+		expectInstalled   bool
+		expectPending     int
+	}{
+		"unknown instance": {
+			instance:       func(p *Plugin) Instance { return nil },
+			callback:       asc,
+			expectedStatus: http.StatusNotFound,
+		},
+		"repeated callback completes the installation": {
+			instance:        func(p *Plugin) Instance { return installed(p) },
+			callback:        asc,
+			expectedStatus:  http.StatusOK,
+			expectInstalled: true,
+		},
+		"another installation is refused": {
+			instance: func(p *Plugin) Instance { return installed(p) },
+			callback: AtlassianSecurityContext{
+				ClientKey:    "client-key",
+				SharedSecret: "another-secret",
+				BaseURL:      ts.URL,
+			},
+			expectedStatus:  http.StatusForbidden,
+			expectInstalled: true,
+		},
+		"failed installation is left pending": {
+			instance: func(p *Plugin) Instance {
+				return newCloudInstance(p, types.ID(ts.URL), false, "", &AtlassianSecurityContext{BaseURL: ts.URL})
+			},
+			callback:          asc,
+			storeInstancesErr: errors.New("KV store unavailable"),
+			expectedStatus:    http.StatusInternalServerError,
+			expectPending:     1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			p := &Plugin{}
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+			p.enterpriseChecker = enterprise.NewEnterpriseChecker(api)
+			store := &installStore{
+				instance:          tc.instance(p),
+				storeInstancesErr: tc.storeInstancesErr,
+			}
+			p.instanceStore = store
+
+			path, err := filepath.Abs("..")
+			require.NoError(t, err)
+			api.On("GetBundlePath").Return(path, nil)
+			api.On("GetLicense").Return(&model.License{SkuShortName: "enterprise"})
+			api.On("GetConfig").Return(&model.Config{})
+			api.On("UnregisterCommand", mock.Anything, mock.Anything).Return(nil)
+			api.On("RegisterCommand", mock.Anything).Return(nil)
+			api.On("PublishWebSocketEvent", mock.Anything, mock.Anything, mock.Anything)
+			api.On("PublishPluginClusterEvent", mock.AnythingOfType("model.PluginClusterEvent"), mock.AnythingOfType("model.PluginClusterEventSendOptions")).Return(nil)
+			api.On("LogInfo", mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+			api.On("LogInfo", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+			api.On("LogWarn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+
+			body, err := json.Marshal(tc.callback)
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, routeACInstalled, bytes.NewReader(body))
+			status, _ := p.httpACInstalled(w, r)
+			assert.Equal(t, tc.expectedStatus, status)
+			assert.Equal(t, tc.expectPending, store.pending)
+
+			if tc.expectedStatus == http.StatusNotFound {
+				return
+			}
+			ci := store.instance.(*cloudInstance)
+			assert.Equal(t, tc.expectInstalled, ci.Installed)
+			if tc.expectInstalled {
+				assert.Equal(t, "shared-secret", ci.AtlassianSecurityContext.SharedSecret)
+			}
+		})
+	}
+}
+
+func TestCreateInactiveCloudInstance(t *testing.T) {
+	jiraURL := types.ID("https://mmtest.atlassian.net")
+	key := hashkey(prefixInstance, jiraURL.String())
+	pending, err := json.Marshal(newCloudInstance(nil, jiraURL, false, "", &AtlassianSecurityContext{BaseURL: jiraURL.String()}))
+	require.NoError(t, err)
+	installed, err := json.Marshal(newCloudInstance(nil, jiraURL, true, "", &AtlassianSecurityContext{BaseURL: jiraURL.String()}))
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		existing    []byte
+		set         bool
+		expectedErr string
+	}{
+		"new instance": {
+			set: true,
+		},
+		"pending instance": {
+			existing: pending,
+			set:      true,
+		},
+		"installed instance": {
+			existing:    installed,
+			expectedErr: "Jira instance https://mmtest.atlassian.net is already installed",
+		},
+		"changed meanwhile": {
+			existing:    pending,
+			expectedErr: "Jira instance https://mmtest.atlassian.net was changed while it was being set up, please try again",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			p := &Plugin{}
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+
+			api.On("KVGet", key).Return(tc.existing, (*model.AppError)(nil))
+			api.On("KVSetWithOptions", key, mock.Anything, model.PluginKVSetOptions{
+				Atomic:          true,
+				OldValue:        tc.existing,
+				ExpireInSeconds: 15 * 60,
+			}).Return(tc.set, (*model.AppError)(nil))
+			api.On("LogDebug", mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+
+			err := NewStore(p).CreateInactiveCloudInstance(jiraURL, "userID")
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			api.AssertNumberOfCalls(t, "KVSetWithOptions", 1)
+		})
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	autoConnectUsersPerPage = 200
+
+	// autoConnectListMax is the number of users listed in each part of the
+	// report, the others are only counted.
+	autoConnectListMax = 20
+)
+
+type autoConnectMatch struct {
+	User     *model.User
+	JiraUser jira.User
+}
+
+// autoConnectReport is what `/jira admin auto-connect` does, or would do
+// without `--confirm`.
+type autoConnectReport struct {
+	InstanceID       types.ID
+	Supported        bool
+	AlreadyConnected int
+	Matched          []autoConnectMatch
+	// Hidden are the users whose email address is hidden in Jira by the
+	// privacy settings of their account, who cannot be matched.
+	Hidden    []*model.User
+	Unmatched []*model.User
+}
+
+// prompted are the users who get a DM to connect.
+func (r autoConnectReport) prompted() []*model.User {
+	return append(append([]*model.User{}, r.Hidden...), r.Unmatched...)
+}
+
+// mdAutoConnectUsers lists the usernames, up to autoConnectListMax.
+func mdAutoConnectUsers(users []*model.User) string {
+	names := []string{}
+	for i, user := range users {
+		if i == autoConnectListMax {
+			names = append(names, fmt.Sprintf("and %d more", len(users)-i))
+			break
+		}
+		names = append(names, "@"+user.Username)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (r autoConnectReport) Markdown() string {
+	msg := fmt.Sprintf("#### Auto-connect to %s\n", r.InstanceID)
+	if !r.Supported {
+		msg += "The authentication of this Jira instance does not let the plugin act on behalf of the users, only Jira Cloud instances installed as an Atlassian Connect app do. No connection can be made for them.\n"
+	}
+	msg += fmt.Sprintf("%d user(s) are already connected, %d would be connected to the Jira account with their email address, and %d would get a DM to connect.\n",
+		r.AlreadyConnected, len(r.Matched), len(r.Hidden)+len(r.Unmatched))
+
+	if len(r.Matched) > 0 {
+		rows := []string{}
+		for i, m := range r.Matched {
+			if i == autoConnectListMax {
+				rows = append(rows, fmt.Sprintf("* and %d more", len(r.Matched)-i))
+				break
+			}
+			rows = append(rows, fmt.Sprintf("* @%s: %s", m.User.Username, m.JiraUser.DisplayName))
+		}
+		msg += "\nConnected by email:\n" + strings.Join(rows, "\n") + "\n"
+	}
+	if len(r.Hidden) > 0 {
+		msg += "\nPrompted to connect, their email address is hidden in Jira: " + mdAutoConnectUsers(r.Hidden) + "\n"
+	}
+	if len(r.Unmatched) > 0 {
+		msg += "\nPrompted to connect: " + mdAutoConnectUsers(r.Unmatched) + "\n"
+	}
+	return msg
+}
+
+// listAutoConnectUsers returns the active Mattermost users, leaving out the
+// bots and the users of other servers.
+func (p *Plugin) listAutoConnectUsers() ([]*model.User, error) {
+	users := []*model.User{}
+	for page := 0; ; page++ {
+		batch, err := p.client.User.List(&model.UserGetOptions{
+			Page:    page,
+			PerPage: autoConnectUsersPerPage,
+			Active:  true,
+		})
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to list the users")
+		}
+		for _, user := range batch {
+			if !user.IsBot && !user.IsRemote() {
+				users = append(users, user)
+			}
+		}
+		if len(batch) < autoConnectUsersPerPage {
+			return users, nil
+		}
+	}
+}
+
+// findJiraUserByEmail returns the only Jira account whose visible email
+// address is the same, regardless of case, or nil. hidden tells whether the
+// search found accounts whose email address is hidden, which are not matched.
+func findJiraUserByEmail(client Client, instance Instance, email string) (jiraUser *jira.User, hidden bool, err error) {
+	users, err := searchJiraUsers(client, instance, email, 2)
+	if err != nil {
+		return nil, false, err
+	}
+	matches := []jira.User{}
+	for _, user := range users {
+		switch {
+		case user.EmailAddress == "":
+			hidden = true
+		case strings.EqualFold(user.EmailAddress, email):
+			matches = append(matches, user)
+		}
+	}
+	if len(matches) != 1 || matches[0].AccountID == "" {
+		return nil, hidden && len(matches) == 0, nil
+	}
+	return &matches[0], false, nil
+}
+
+// planAutoConnect matches the users who are not connected to the instance to
+// the Jira accounts with their verified email address, searched with the
+// client. The Jira accounts connected to other users are left out.
+func (p *Plugin) planAutoConnect(instance Instance, client Client, users []*model.User) autoConnectReport {
+	report := autoConnectReport{
+		InstanceID: instance.GetID(),
+		Supported:  client != nil,
+	}
+	for _, user := range users {
+		if _, err := p.userStore.LoadConnection(instance.GetID(), types.ID(user.Id)); err == nil {
+			report.AlreadyConnected++
+			continue
+		}
+		if client == nil || user.Email == "" || !user.EmailVerified {
+			report.Unmatched = append(report.Unmatched, user)
+			continue
+		}
+
+		jiraUser, hidden, err := findJiraUserByEmail(client, instance, user.Email)
+		if err != nil {
+			p.client.Log.Debug("Failed to search the Jira user by email", "UserID", user.Id, "Error", err.Error())
+		}
+		if hidden {
+			report.Hidden = append(report.Hidden, user)
+			continue
+		}
+		if jiraUser == nil {
+			report.Unmatched = append(report.Unmatched, user)
+			continue
+		}
+		if connected, _ := p.userStore.LoadMattermostUserID(instance.GetID(), jiraUser.AccountID); connected != "" {
+			report.Unmatched = append(report.Unmatched, user)
+			continue
+		}
+		report.Matched = append(report.Matched, autoConnectMatch{User: user, JiraUser: *jiraUser})
+	}
+	return report
+}
+
+// applyAutoConnect connects the matched users, without the welcome DM of the
+// users who connect themselves, and DMs the others a link to connect. It
+// returns the number of connected and prompted users.
+func (p *Plugin) applyAutoConnect(instance Instance, report autoConnectReport) (connected, prompted int, err error) {
+	var errs []string
+	for _, m := range report.Matched {
+		connection := &Connection{
+			User: jira.User{
+				AccountID:   m.JiraUser.AccountID,
+				Key:         m.JiraUser.Key,
+				Name:        m.JiraUser.Name,
+				DisplayName: m.JiraUser.DisplayName,
+			},
+			Settings: p.defaultConnectionSettings(),
+		}
+		if connectErr := p.storeUserConnection(instance, types.ID(m.User.Id), connection, false); connectErr != nil {
+			errs = append(errs, fmt.Sprintf("@%s: %v", m.User.Username, connectErr))
+			continue
+		}
+		connected++
+	}
+
+	for _, user := range report.prompted() {
+		_, dmErr := p.CreateBotDMtoMMUserID(user.Id,
+			"A system administrator invites you to link your Jira account on %s, to use Jira from Mattermost: [connect your Jira account](%s%s).",
+			instance.GetID(), p.GetPluginURL(), instancePath(routeUserConnect, instance.GetID()))
+		if dmErr != nil {
+			errs = append(errs, fmt.Sprintf("@%s: %v", user.Username, dmErr))
+			continue
+		}
+		prompted++
+	}
+
+	if len(errs) > 0 {
+		err = errors.New(strings.Join(errs, ", "))
+	}
+	return connected, prompted, err
+}
+
+// autoConnectClient returns the client that searches the Jira users when the
+// instance lets the plugin act on behalf of them, or nil.
+func autoConnectClient(instance Instance) (Client, error) {
+	ci, ok := instance.(*cloudInstance)
+	if !ok {
+		return nil, nil
+	}
+	jiraClient, err := ci.getClientForBot()
+	if err != nil {
+		return nil, err
+	}
+	return newCloudClient(jiraClient), nil
+}
+
+func executeAdminAutoConnect(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	authorized, err := authorizedSysAdmin(p, header.UserId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira admin auto-connect` can only be run by a system administrator.")
+	}
+	confirm := len(args) == 2 && args[1] == "--confirm"
+	if len(args) != 1 && !confirm {
+		return p.responsef(header, "Please specify a Jira instance, `/jira admin auto-connect [jiraURL] [--confirm]`.")
+	}
+
+	instances, err := p.instanceStore.LoadInstances()
+	if err != nil {
+		return p.responsef(header, "Failed to load instances. Error: %v.", err)
+	}
+	instanceID := types.ID(args[0])
+	if found := instances.getByAlias(args[0]); found != nil {
+		instanceID = found.InstanceID
+	}
+	instance, err := p.instanceStore.LoadInstance(instanceID)
+	if err != nil {
+		return p.responsef(header, "Failed to load instance. Error: %v.", err)
+	}
+	client, err := autoConnectClient(instance)
+	if err != nil {
+		return p.responsef(header, "Failed to get a Jira client for %s. Error: %v.", instance.GetID(), err)
+	}
+
+	go p.runAutoConnect(header.UserId, instance, client, confirm)
+
+	if !confirm {
+		return p.responsef(header, "Matching the users who are not connected to %s. You will get a DM with the result.", instance.GetID())
+	}
+	return p.responsef(header, "Connecting the users who are not connected to %s. You will get a DM when it is done.", instance.GetID())
+}
+
+// runAutoConnect matches the users, connects them if confirm is set, and DMs
+// the result to the system administrator who asked for it. It searches Jira
+// once per user, longer than a command may take.
+func (p *Plugin) runAutoConnect(adminID string, instance Instance, client Client, confirm bool) {
+	users, err := p.listAutoConnectUsers()
+	if err != nil {
+		p.errorf("runAutoConnect: %v", err)
+		if _, dmErr := p.CreateBotDMtoMMUserID(adminID, "Failed to auto-connect the users to %s: %v.", instance.GetID(), err); dmErr != nil {
+			p.infof("runAutoConnect: failed to report to %s: %v", adminID, dmErr)
+		}
+		return
+	}
+
+	report := p.planAutoConnect(instance, client, users)
+	msg := ""
+	if !confirm {
+		msg = fmt.Sprintf("%s\nRun `/jira admin auto-connect %s --confirm` to do it.", report.Markdown(), instance.GetID())
+	} else {
+		connected, prompted, applyErr := p.applyAutoConnect(instance, report)
+		msg = fmt.Sprintf("Connected %d user(s) to %s by email, and sent a DM to connect to %d user(s).", connected, instance.GetID(), prompted)
+		if applyErr != nil {
+			msg += fmt.Sprintf(" Some failed: %v", applyErr)
+		}
+		p.infof("runAutoConnect: %s", msg)
+	}
+	if _, err = p.CreateBotDMtoMMUserID(adminID, "%s", msg); err != nil {
+		p.infof("runAutoConnect: failed to report to %s: %v", adminID, err)
+	}
+}
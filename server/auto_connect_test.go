@@ -0,0 +1,106 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+type autoConnectTestClient struct {
+	testClient
+	usersByEmail map[string]string
+}
+
+func (client autoConnectTestClient) RESTGet(endpoint string, params map[string]string, dest interface{}) error {
+	data, ok := client.usersByEmail[params["query"]]
+	if !ok {
+		data = `[]`
+	}
+	return json.Unmarshal([]byte(data), dest)
+}
+
+type autoConnectUserStore struct {
+	mockUserStoreKV
+	jiraAccounts map[string]types.ID
+}
+
+func (store autoConnectUserStore) LoadMattermostUserID(instanceID types.ID, jiraAccountID string) (types.ID, error) {
+	mattermostUserID, ok := store.jiraAccounts[jiraAccountID]
+	if !ok {
+		return "", errors.New("TESTING not found")
+	}
+	return mattermostUserID, nil
+}
+
+func TestPlanAutoConnect(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("LogDebug", mockAnythingOfTypeBatch("string", 13)...).Return(nil).Maybe()
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.userStore = autoConnectUserStore{
+		mockUserStoreKV: mockUserStoreKV{
+			connections: map[types.ID]*Connection{"connected": {}},
+		},
+		jiraAccounts: map[string]types.ID{"taken-account": "someone-else"},
+	}
+
+	instance := &testInstance{InstanceCommon: InstanceCommon{InstanceID: mockInstance1URL, Type: CloudInstanceType}}
+	client := autoConnectTestClient{usersByEmail: map[string]string{
+		"Alice@example.com":   `[{"accountId": "alice-account", "displayName": "Alice", "emailAddress": "alice@example.com"}]`,
+		"bob@example.com":     `[{"accountId": "bob-1"}, {"accountId": "bob-2"}]`,
+		"carol@example.com":   `[{"accountId": "taken-account", "emailAddress": "carol@example.com"}]`,
+		"dan@example.com":     `[{"accountId": "dan-account", "emailAddress": "dan@example.com.au"}]`,
+		"unverified@test.com": `[{"accountId": "unverified-account", "emailAddress": "unverified@test.com"}]`,
+	}}
+	users := []*model.User{
+		{Id: "connected", Username: "connected", Email: "connected@example.com", EmailVerified: true},
+		{Id: "alice", Username: "alice", Email: "Alice@example.com", EmailVerified: true},
+		{Id: "bob", Username: "bob", Email: "bob@example.com", EmailVerified: true},
+		{Id: "carol", Username: "carol", Email: "carol@example.com", EmailVerified: true},
+		{Id: "dan", Username: "dan", Email: "dan@example.com", EmailVerified: true},
+		{Id: "erin", Username: "erin", Email: "unverified@test.com"},
+	}
+
+	t.Run("matched by email", func(t *testing.T) {
+		report := p.planAutoConnect(instance, client, users[:3])
+		assert.True(t, report.Supported)
+		assert.Equal(t, 1, report.AlreadyConnected)
+		require.Len(t, report.Matched, 1)
+		assert.Equal(t, "alice", report.Matched[0].User.Username)
+		assert.Equal(t, "alice-account", report.Matched[0].JiraUser.AccountID)
+		assert.Empty(t, report.Unmatched)
+		require.Len(t, report.Hidden, 1)
+		assert.Equal(t, "bob", report.Hidden[0].Username)
+		assert.Contains(t, report.Markdown(), "* @alice: Alice")
+		assert.Contains(t, report.Markdown(), "their email address is hidden in Jira: @bob")
+		assert.Len(t, report.prompted(), 1)
+	})
+
+	t.Run("taken, mismatching and unverified emails are unmatched", func(t *testing.T) {
+		report := p.planAutoConnect(instance, client, users[3:])
+		assert.Empty(t, report.Matched)
+		assert.Empty(t, report.Hidden)
+		assert.Len(t, report.Unmatched, 3)
+	})
+
+	t.Run("unsupported instance", func(t *testing.T) {
+		report := p.planAutoConnect(instance, nil, users)
+		assert.False(t, report.Supported)
+		assert.Equal(t, 1, report.AlreadyConnected)
+		assert.Empty(t, report.Matched)
+		assert.Len(t, report.Unmatched, 5)
+		assert.Contains(t, report.Markdown(), "No connection can be made")
+	})
+}
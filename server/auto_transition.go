@@ -0,0 +1,263 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils"
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	prefixAutoTransition = "auto_transition_"
+
+	// autoTransitionCreated is the condition of the rules that apply to the
+	// new issues.
+	autoTransitionCreated = "created"
+
+	autoTransitionLabel = "label"
+
+	// serviceAccountRequestTimeout is the timeout of the requests of the
+	// service account, unless the instance has its own.
+	serviceAccountRequestTimeout = 30 * time.Second
+)
+
+// autoTransitionRule transitions the issues to a status when an event
+// matches: when they are created, when a label is added to them, or when one
+// of their fields changes to a value.
+type autoTransitionRule struct {
+	// Field is autoTransitionCreated, autoTransitionLabel, or the lowercase
+	// name of a field, e.g. "priority".
+	Field string
+	Value string
+
+	// ToStatus is the status the issue is moved to, or the name of the
+	// transition.
+	ToStatus string
+}
+
+func (r autoTransitionRule) String() string {
+	if r.Field == autoTransitionCreated {
+		return autoTransitionCreated + "=" + r.ToStatus
+	}
+	return r.Field + ":" + r.Value + "=" + r.ToStatus
+}
+
+// parseAutoTransitions parses the JiraAutoTransitions setting, e.g.
+// "label:ready-for-qa=In QA, priority:Highest=Escalated, created=Triage".
+func parseAutoTransitions(setting string) ([]autoTransitionRule, error) {
+	rules := []autoTransitionRule{}
+	for _, pair := range strings.Split(setting, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		condition, toStatus, ok := strings.Cut(pair, "=")
+		condition = strings.TrimSpace(condition)
+		toStatus = strings.TrimSpace(toStatus)
+		if !ok || condition == "" || toStatus == "" {
+			return nil, errors.Errorf("invalid auto-transition %q, expected `condition=status`", pair)
+		}
+
+		rule := autoTransitionRule{ToStatus: toStatus}
+		if strings.EqualFold(condition, autoTransitionCreated) {
+			rule.Field = autoTransitionCreated
+			rules = append(rules, rule)
+			continue
+		}
+		field, value, ok := strings.Cut(condition, ":")
+		field = strings.ToLower(strings.TrimSpace(field))
+		value = strings.TrimSpace(value)
+		if !ok || field == "" || value == "" {
+			return nil, errors.Errorf("invalid auto-transition condition %q, expected `created`, `label:<name>` or `<field>:<value>`", condition)
+		}
+		if field == "labels" {
+			field = autoTransitionLabel
+		}
+		rule.Field, rule.Value = field, value
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// matches tells whether the event creates the issue, adds the label to it, or
+// changes the field to the value of the rule.
+func (r autoTransitionRule) matches(jwh *JiraWebhook) bool {
+	switch r.Field {
+	case autoTransitionCreated:
+		return jwh.WebhookEvent == issueCreated
+	case autoTransitionLabel:
+		if jwh.WebhookEvent == issueCreated {
+			return jwh.Issue.Fields != nil && containsFold(jwh.Issue.Fields.Labels, r.Value)
+		}
+		for _, item := range jwh.ChangeLog.Items {
+			if strings.EqualFold(item.Field, "labels") &&
+				containsFold(strings.Fields(item.ToString), r.Value) && !containsFold(strings.Fields(item.FromString), r.Value) {
+				return true
+			}
+		}
+	default:
+		for _, item := range jwh.ChangeLog.Items {
+			if (strings.EqualFold(item.Field, r.Field) || strings.EqualFold(item.FieldID, r.Field)) && strings.EqualFold(item.ToString, r.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func autoTransitionKey(instanceID types.ID, issueKey string) string {
+	return hashkey(prefixAutoTransition, instanceID.String()+"/"+strings.ToUpper(issueKey))
+}
+
+// isAutoTransitionLoop tells whether the event is one of the plugin's own
+// transitions: that of the service account, into the status the plugin moved
+// the issue to. The other events of the service account, and those of the
+// users, are not.
+func (p *Plugin) isAutoTransitionLoop(instanceID types.ID, client Client, jwh *JiraWebhook) bool {
+	_, toName, ok := jwh.statusTransition()
+	if !ok {
+		return false
+	}
+	var toStatus string
+	err := p.client.KV.Get(autoTransitionKey(instanceID, jwh.Issue.Key), &toStatus)
+	if err != nil || !strings.EqualFold(toStatus, toName) {
+		return false
+	}
+
+	actors := jwh.webhookActors()
+	if p.getConfig().serviceAccountIDs.ContainsAny(actors...) {
+		return true
+	}
+	self, err := client.GetSelf()
+	if err != nil {
+		p.client.Log.Debug("Failed to get the service account", "Error", err.Error())
+		return false
+	}
+	for _, actor := range actors {
+		if (self.AccountID != "" && actor == self.AccountID) || (self.Name != "" && actor == self.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// autoTransition applies the first rule that matches the event, with the
+// client of the service account, if its transition is available. It returns
+// the status the issue was moved to, or "".
+func (p *Plugin) autoTransition(instanceID types.ID, client Client, jwh *JiraWebhook, rules []autoTransitionRule) (string, error) {
+	var rule *autoTransitionRule
+	for i := range rules {
+		if rules[i].matches(jwh) {
+			rule = &rules[i]
+			break
+		}
+	}
+	if rule == nil || p.isAutoTransitionLoop(instanceID, client, jwh) {
+		return "", nil
+	}
+	if jwh.Issue.Fields != nil && jwh.Issue.Fields.Status != nil && strings.EqualFold(jwh.Issue.Fields.Status.Name, rule.ToStatus) {
+		return "", nil
+	}
+
+	transitions, err := getIssueTransitions(client, jwh.Issue.Key)
+	if err != nil {
+		return "", err
+	}
+	var transition *jira.Transition
+	for i, t := range transitions {
+		if strings.EqualFold(t.To.Name, rule.ToStatus) || strings.EqualFold(t.Name, rule.ToStatus) {
+			transition = &transitions[i]
+			break
+		}
+	}
+	if transition == nil {
+		return "", errors.Errorf("the transition of %s to %q is not available", jwh.Issue.Key, rule.ToStatus)
+	}
+
+	// Remember the transition first, so that its own event is ignored.
+	if _, err = p.client.KV.Set(autoTransitionKey(instanceID, jwh.Issue.Key), transition.To.Name, pluginapi.SetExpiry(selfChangeExpiry)); err != nil {
+		return "", errors.WithMessage(err, "failed to record the auto-transition")
+	}
+	if err = client.DoTransition(jwh.Issue.Key, transition.ID); err != nil {
+		return "", err
+	}
+	return transition.To.Name, nil
+}
+
+// serviceAccountClient returns the client of the Jira account of the Admin
+// API Token, which the plugin acts with on its own. It goes through the HTTP
+// client of the instance, with its limits, metrics and timeout.
+func (p *Plugin) serviceAccountClient(instance Instance) (Client, error) {
+	conf := p.getConfig()
+	if conf.AdminAPIToken == "" || conf.AdminEmail == "" {
+		return nil, errors.New("the Admin API Token and Admin Email must be set to transition the issues automatically")
+	}
+	token, err := p.adminAPIToken()
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := p.instanceHTTPClient(instance.GetID())
+	if err != nil {
+		return nil, err
+	}
+	httpClient = utils.WrapHTTPClient(httpClient,
+		utils.WithRequestSizeLimit(conf.maxAttachmentSize),
+		utils.WithResponseSizeLimit(conf.maxAttachmentSize))
+	httpClient = p.metrics.withMetrics(httpClient)
+	transport := jira.BasicAuthTransport{Username: conf.AdminEmail, Password: token, Transport: httpClient.Transport}
+	httpClient.Transport = &transport
+	httpClient.Timeout = serviceAccountRequestTimeout
+	instance.Common().applyRequestTimeout(httpClient)
+
+	jiraClient, err := jira.NewClient(httpClient, instance.GetJiraBaseURL())
+	if err != nil {
+		return nil, err
+	}
+	if instance.Common().IsCloudInstance() {
+		return newCloudClient(jiraClient), nil
+	}
+	return newServerClient(jiraClient), nil
+}
+
+// applyAutoTransitions applies the JiraAutoTransitions rules to the event.
+func (p *Plugin) applyAutoTransitions(instanceID types.ID, wh *webhook) {
+	rules := p.getConfig().autoTransitions
+	if len(rules) == 0 || wh.Issue.Key == "" || wh.eventTypes.ContainsAny(eventDeleted) {
+		return
+	}
+	instance, err := p.instanceStore.LoadInstance(instanceID)
+	if err != nil {
+		return
+	}
+	client, err := p.serviceAccountClient(instance)
+	if err != nil {
+		p.client.Log.Warn("Failed to transition the issue automatically", "IssueKey", wh.Issue.Key, "Error", err.Error())
+		return
+	}
+	toStatus, err := p.autoTransition(instanceID, client, wh.JiraWebhook, rules)
+	if err != nil {
+		p.client.Log.Warn("Failed to transition the issue automatically", "IssueKey", wh.Issue.Key, "Error", err.Error())
+		return
+	}
+	if toStatus != "" {
+		p.client.Log.Debug("Transitioned the issue automatically", "IssueKey", wh.Issue.Key, "Status", toStatus)
+	}
+}
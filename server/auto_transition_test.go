@@ -0,0 +1,185 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type autoTransitionTestClient struct {
+	testClient
+	transitioned *[]string
+}
+
+func (client autoTransitionTestClient) DoTransition(issueKey string, transitionID string) error {
+	*client.transitioned = append(*client.transitioned, issueKey)
+	return nil
+}
+
+func autoTransitionWebhook(t *testing.T, data string) *JiraWebhook {
+	jwh := &JiraWebhook{}
+	require.NoError(t, json.Unmarshal([]byte(data), jwh))
+	return jwh
+}
+
+func TestParseAutoTransitions(t *testing.T) {
+	rules, err := parseAutoTransitions("label:ready-for-qa=In QA, Priority:Highest = Escalated,, created=Triage, labels:urgent=In Progress")
+	require.NoError(t, err)
+	assert.Equal(t, []autoTransitionRule{
+		{Field: autoTransitionLabel, Value: "ready-for-qa", ToStatus: "In QA"},
+		{Field: "priority", Value: "Highest", ToStatus: "Escalated"},
+		{Field: autoTransitionCreated, ToStatus: "Triage"},
+		{Field: autoTransitionLabel, Value: "urgent", ToStatus: "In Progress"},
+	}, rules)
+
+	rules, err = parseAutoTransitions("")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	for _, setting := range []string{"In QA", "label:ready-for-qa=", "ready-for-qa=In QA", "label:=In QA"} {
+		_, err = parseAutoTransitions(setting)
+		assert.Error(t, err, setting)
+	}
+}
+
+func TestAutoTransitionRuleMatches(t *testing.T) {
+	labelAdded := autoTransitionWebhook(t, `{"webhookEvent": "jira:issue_updated", "changelog": {"items": [
+		{"field": "labels", "fromString": "backend", "toString": "backend ready-for-qa"}]}}`)
+	labelKept := autoTransitionWebhook(t, `{"webhookEvent": "jira:issue_updated", "changelog": {"items": [
+		{"field": "labels", "fromString": "ready-for-qa", "toString": "ready-for-qa backend"}]}}`)
+	createdWithLabel := autoTransitionWebhook(t, `{"webhookEvent": "jira:issue_created", "issue": {"fields": {"labels": ["Ready-For-QA"]}}}`)
+	priorityChanged := autoTransitionWebhook(t, `{"webhookEvent": "jira:issue_updated", "changelog": {"items": [
+		{"field": "priority", "fieldId": "priority", "fromString": "High", "toString": "Highest"}]}}`)
+
+	label := autoTransitionRule{Field: autoTransitionLabel, Value: "ready-for-qa", ToStatus: "In QA"}
+	priority := autoTransitionRule{Field: "priority", Value: "highest", ToStatus: "Escalated"}
+	created := autoTransitionRule{Field: autoTransitionCreated, ToStatus: "Triage"}
+
+	assert.True(t, label.matches(labelAdded))
+	assert.False(t, label.matches(labelKept), "the label was already there")
+	assert.True(t, label.matches(createdWithLabel))
+	assert.False(t, label.matches(priorityChanged))
+
+	assert.True(t, priority.matches(priorityChanged))
+	assert.False(t, priority.matches(labelAdded))
+
+	assert.True(t, created.matches(createdWithLabel))
+	assert.False(t, created.matches(labelAdded))
+}
+
+func (client autoTransitionTestClient) GetSelf() (*jira.User, error) {
+	return &jira.User{AccountID: "plugin-bot"}, nil
+}
+
+func TestAutoTransition(t *testing.T) {
+	rules := []autoTransitionRule{
+		{Field: autoTransitionLabel, Value: "testing", ToStatus: "In Testing"},
+		{Field: autoTransitionLabel, Value: "review", ToStatus: "In Review"},
+		{Field: "status", Value: "In Testing", ToStatus: "To Do"},
+	}
+	labelAdded := func(actor, label string) string {
+		return `{"webhookEvent": "jira:issue_updated", "issue": {"key": "TEST-10", "fields": {"status": {"name": "To Do"}}},
+			"user": {"accountId": "` + actor + `"}, "changelog": {"items": [{"field": "labels", "toString": "` + label + `"}]}}`
+	}
+	movedToTesting := func(actor string) string {
+		return `{"webhookEvent": "jira:issue_updated", "issue": {"key": "TEST-10", "fields": {"status": {"name": "In Testing"}}},
+			"user": {"accountId": "` + actor + `"}, "changelog": {"items": [{"field": "status", "from": "1", "fromString": "To Do", "to": "3", "toString": "In Testing"}]}}`
+	}
+
+	for name, tc := range map[string]struct {
+		webhook         string
+		transitionedTo  string
+		serviceAccounts string
+		expectedStatus  string
+		expectedError   bool
+	}{
+		"transitions the issue": {
+			webhook:        labelAdded("alice", "testing"),
+			expectedStatus: "In Testing",
+		},
+		"no matching rule": {
+			webhook: labelAdded("alice", "backend"),
+		},
+		"already in the status": {
+			webhook: `{"webhookEvent": "jira:issue_updated", "issue": {"key": "TEST-10", "fields": {"status": {"name": "In Testing"}}},
+				"changelog": {"items": [{"field": "labels", "toString": "testing"}]}}`,
+		},
+		"the event of its own transition": {
+			webhook:        movedToTesting("plugin-bot"),
+			transitionedTo: "In Testing",
+		},
+		"the event of its own transition by a listed service account": {
+			webhook:         movedToTesting("svc"),
+			transitionedTo:  "In Testing",
+			serviceAccounts: "svc",
+		},
+		"a user moves the issue into the status of its transition": {
+			webhook:        movedToTesting("alice"),
+			transitionedTo: "In Testing",
+			expectedStatus: "To Do",
+		},
+		"the service account moves the issue into another status": {
+			webhook:        movedToTesting("plugin-bot"),
+			transitionedTo: "In Progress",
+			expectedStatus: "To Do",
+		},
+		"another event of the service account": {
+			webhook:         labelAdded("svc", "testing"),
+			transitionedTo:  "In Testing",
+			serviceAccounts: "svc",
+			expectedStatus:  "In Testing",
+		},
+		"the transition is not available": {
+			webhook:       labelAdded("alice", "review"),
+			expectedError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			key := autoTransitionKey(testInstance1.InstanceID, "TEST-10")
+			if tc.transitionedTo != "" {
+				data, err := json.Marshal(tc.transitionedTo)
+				require.NoError(t, err)
+				api.On("KVGet", key).Return(data, nil)
+			} else {
+				api.On("KVGet", key).Return(nil, nil)
+			}
+			api.On("KVSetWithOptions", key, mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+			api.On("LogDebug", mock.Anything, mock.Anything, mock.Anything).Maybe()
+
+			p := Plugin{}
+			p.updateConfig(func(conf *config) {
+				conf.serviceAccountIDs = parseServiceAccountIDs(tc.serviceAccounts)
+			})
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+
+			transitioned := []string{}
+			client := autoTransitionTestClient{transitioned: &transitioned}
+			status, err := p.autoTransition(testInstance1.InstanceID, client, autoTransitionWebhook(t, tc.webhook), rules)
+			if tc.expectedError {
+				assert.Error(t, err)
+				assert.Empty(t, transitioned)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, status)
+			if tc.expectedStatus != "" {
+				assert.Equal(t, []string{"TEST-10"}, transitioned)
+				api.AssertCalled(t, "KVSetWithOptions", key, mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions"))
+			} else {
+				assert.Empty(t, transitioned)
+				api.AssertNotCalled(t, "KVSetWithOptions", key, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// The request parameters of the automation webhook that map a custom payload,
+// as sent by a Jira automation "Send web request" action, to a post.
+const (
+	automationParamKey     = "key"
+	automationParamSummary = "summary"
+	automationParamMessage = "message"
+
+	automationDefaultKeyPath     = "issue.key"
+	automationDefaultSummaryPath = "issue.fields.summary"
+)
+
+// automationTemplateRegexp matches the `{{path}}` placeholders of a message
+// template.
+var automationTemplateRegexp = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// automationMapping tells where the issue key and summary are found in an
+// automation payload, and how to word the post about it.
+type automationMapping struct {
+	KeyPath     string
+	SummaryPath string
+	Message     string
+}
+
+func newAutomationMapping(values url.Values) automationMapping {
+	m := automationMapping{
+		KeyPath:     values.Get(automationParamKey),
+		SummaryPath: values.Get(automationParamSummary),
+		Message:     values.Get(automationParamMessage),
+	}
+	if m.KeyPath == "" {
+		m.KeyPath = automationDefaultKeyPath
+	}
+	if m.SummaryPath == "" {
+		m.SummaryPath = automationDefaultSummaryPath
+	}
+	return m
+}
+
+// lookupJSONPath returns the value at a dot separated path of a decoded JSON
+// document, e.g. `issue.fields.labels.0`. A leading `$.` is allowed.
+func lookupJSONPath(data interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return data, true
+	}
+	for _, step := range strings.Split(path, ".") {
+		switch v := data.(type) {
+		case map[string]interface{}:
+			value, ok := v[step]
+			if !ok {
+				return nil, false
+			}
+			data = value
+		case []interface{}:
+			i, err := strconv.Atoi(step)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			data = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return data, true
+}
+
+// jsonValueString returns a JSON value as post text: strings and numbers as
+// they are, anything else as JSON.
+func jsonValueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	bb, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(bb)
+}
+
+func lookupJSONPathString(data interface{}, path string) string {
+	value, ok := lookupJSONPath(data, path)
+	if !ok {
+		return ""
+	}
+	return jsonValueString(value)
+}
+
+// render returns the message and the issue link of the post about a payload.
+func (m automationMapping) render(payload interface{}, jiraURL string) (message, link string, err error) {
+	key := lookupJSONPathString(payload, m.KeyPath)
+	if key != "" {
+		title := key
+		if summary := lookupJSONPathString(payload, m.SummaryPath); summary != "" {
+			title += ": " + truncate(summary, 80)
+		}
+		link = fmt.Sprintf("[%s](%s/browse/%s)", title, jiraURL, url.PathEscape(key))
+	}
+
+	if m.Message == "" {
+		if link == "" {
+			return "", "", errors.Errorf("no issue key found at %q, and no message template", m.KeyPath)
+		}
+		return link, "", nil
+	}
+
+	message = automationTemplateRegexp.ReplaceAllStringFunc(m.Message, func(placeholder string) string {
+		path := automationTemplateRegexp.FindStringSubmatch(placeholder)[1]
+		return lookupJSONPathString(payload, path)
+	})
+	return message, link, nil
+}
+
+// httpAutomationWebhook posts the custom payloads of Jira automation rules to
+// a channel. Unlike the other webhooks, the payload is not a Jira event: the
+// request parameters map it to a post.
+func (p *Plugin) httpAutomationWebhook(w http.ResponseWriter, r *http.Request, instanceID types.ID) (int, error) {
+	conf := p.getConfig()
+	if conf.Secret == "" {
+		return respondErr(w, http.StatusForbidden,
+			fmt.Errorf("JIRA plugin not configured correctly; must provide Secret"))
+	}
+	status, err := verifyHTTPSecret(conf.Secret, r.FormValue("secret"))
+	if err != nil {
+		return respondErr(w, status, err)
+	}
+
+	teamName := r.FormValue("team")
+	if teamName == "" {
+		return respondErr(w, http.StatusBadRequest,
+			errors.New("request URL: no team name found"))
+	}
+	channelName := r.FormValue("channel")
+	if channelName == "" {
+		return respondErr(w, http.StatusBadRequest,
+			errors.New("request URL: no channel name found"))
+	}
+
+	instance, err := p.instanceStore.LoadInstance(instanceID)
+	if err != nil {
+		return respondErr(w, http.StatusBadRequest, err)
+	}
+	channel, err := p.client.Channel.GetByNameForTeamName(teamName, channelName, false)
+	if err != nil {
+		return respondErr(w, http.StatusBadRequest, err)
+	}
+
+	bb, err := io.ReadAll(r.Body)
+	if err != nil {
+		return respondErr(w, http.StatusBadRequest, err)
+	}
+	var payload interface{}
+	if err = json.Unmarshal(bb, &payload); err != nil {
+		return respondErr(w, http.StatusBadRequest,
+			errors.WithMessage(err, "failed to unmarshal the automation payload"))
+	}
+	if conf.EnableWebhookEventLogging {
+		p.client.Log.Debug("Automation Webhook Event Log", "event", string(bb))
+	}
+
+	message, link, err := newAutomationMapping(r.URL.Query()).render(payload, instance.GetJiraBaseURL())
+	if err != nil {
+		return respondErr(w, http.StatusBadRequest, err)
+	}
+
+	post := &model.Post{
+		ChannelId: channel.Id,
+		UserId:    p.getUserID(),
+	}
+	if link == "" {
+		post.Message = message
+	} else {
+		model.ParseSlackAttachment(post, []*model.SlackAttachment{
+			{
+				Color:    "#95b7d0",
+				Fallback: message,
+				Pretext:  message,
+				Text:     link,
+			},
+		})
+	}
+	if err = p.createPost(post); err != nil {
+		return respondErr(w, http.StatusInternalServerError, err)
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// An automation rule sending the smart values of the issue.
+	automationIssuePayload = `{
+		"issue": {
+			"key": "KT-42",
+			"fields": {"summary": "Release the mobile app", "labels": ["mobile", "release"], "storyPoints": 5}
+		},
+		"rule": {"name": "Release checklist"}
+	}`
+
+	// An automation rule sending a payload of its own shape.
+	automationCustomPayload = `{
+		"ticket": "OPS-7",
+		"title": "Rotate the certificates",
+		"deployment": {"environment": "production", "succeeded": true}
+	}`
+)
+
+func decodePayload(t *testing.T, s string) interface{} {
+	var payload interface{}
+	require.NoError(t, json.Unmarshal([]byte(s), &payload))
+	return payload
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	payload := decodePayload(t, automationIssuePayload)
+
+	for path, expected := range map[string]string{
+		"issue.key":                "KT-42",
+		"$.issue.key":              "KT-42",
+		"issue.fields.labels.1":    "release",
+		"issue.fields.labels":      `["mobile","release"]`,
+		"issue.fields.storyPoints": "5",
+		"issue.fields.labels.2":    "",
+		"issue.fields.missing":     "",
+		"rule.name.first":          "",
+	} {
+		assert.Equal(t, expected, lookupJSONPathString(payload, path), path)
+	}
+}
+
+func TestAutomationMappingRender(t *testing.T) {
+	const jiraURL = "https://jira.example.com"
+
+	for name, tc := range map[string]struct {
+		payload         string
+		params          url.Values
+		expectedMessage string
+		expectedLink    string
+		expectErr       bool
+	}{
+		"issue smart values, default mapping": {
+			payload:         automationIssuePayload,
+			params:          url.Values{},
+			expectedMessage: "[KT-42: Release the mobile app](https://jira.example.com/browse/KT-42)",
+		},
+		"issue smart values with a message": {
+			payload:         automationIssuePayload,
+			params:          url.Values{"message": {"**{{rule.name}}**: {{ issue.fields.labels.0 }} is ready"}},
+			expectedMessage: "**Release checklist**: mobile is ready",
+			expectedLink:    "[KT-42: Release the mobile app](https://jira.example.com/browse/KT-42)",
+		},
+		"custom payload": {
+			payload: automationCustomPayload,
+			params: url.Values{
+				"key":     {"ticket"},
+				"summary": {"title"},
+				"message": {"Deployed to {{deployment.environment}}: {{deployment.succeeded}}"},
+			},
+			expectedMessage: "Deployed to production: true",
+			expectedLink:    "[OPS-7: Rotate the certificates](https://jira.example.com/browse/OPS-7)",
+		},
+		"custom payload without an issue": {
+			payload:         automationCustomPayload,
+			params:          url.Values{"message": {"{{title}} ({{unknown}})"}},
+			expectedMessage: "Rotate the certificates ()",
+		},
+		"neither an issue nor a message": {
+			payload:   automationCustomPayload,
+			params:    url.Values{},
+			expectErr: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			message, link, err := newAutomationMapping(tc.params).render(decodePayload(t, tc.payload), jiraURL)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedMessage, message)
+			assert.Equal(t, tc.expectedLink, link)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	// boardTopIssues is the number of issues listed per column of a board.
+	boardTopIssues = 3
+
+	// boardListLimit is the number of boards looked up by name, or offered
+	// by the autocomplete.
+	boardListLimit = 50
+
+	boardConstraintNone = "none"
+)
+
+// boardColumn is a column of an Agile board, with the issues it holds.
+type boardColumn struct {
+	Name   string
+	Count  int
+	Min    int
+	Max    int
+	Issues []jira.Issue
+}
+
+func (c boardColumn) overLimit() bool {
+	return c.Max > 0 && c.Count > c.Max
+}
+
+func (c boardColumn) underLimit() bool {
+	return c.Min > 0 && c.Count < c.Min
+}
+
+// resolveBoard returns the board with the ID, or else the name, given.
+func resolveBoard(client Client, idOrName string) (*jira.Board, error) {
+	if id, err := strconv.Atoi(idOrName); err == nil {
+		return client.GetBoard(id)
+	}
+
+	boards, err := client.ListBoards(boardListLimit)
+	if err != nil {
+		return nil, err
+	}
+	var partial *jira.Board
+	for i, board := range boards {
+		if strings.EqualFold(board.Name, idOrName) {
+			return &boards[i], nil
+		}
+		if partial == nil && strings.Contains(strings.ToLower(board.Name), strings.ToLower(idOrName)) {
+			partial = &boards[i]
+		}
+	}
+	if partial == nil {
+		return nil, errors.Errorf("no board named %q was found", idOrName)
+	}
+	return partial, nil
+}
+
+// getBoardColumns returns the columns of the board, each with its issue count
+// and first issues. The WIP limits are only set when the board enforces them.
+func getBoardColumns(client Client, boardID int, config *jira.BoardConfiguration) ([]boardColumn, error) {
+	withLimits := config.ColumnConfig.ConstraintType != "" && config.ColumnConfig.ConstraintType != boardConstraintNone
+
+	columns := []boardColumn{}
+	for _, c := range config.ColumnConfig.Columns {
+		column := boardColumn{Name: c.Name}
+		if withLimits {
+			column.Min, column.Max = c.Min, c.Max
+		}
+
+		statusIDs := []string{}
+		for _, status := range c.Status {
+			statusIDs = append(statusIDs, status.ID)
+		}
+		if len(statusIDs) > 0 {
+			issues, total, err := client.SearchBoardIssues(boardID, fmt.Sprintf("status in (%s)", strings.Join(statusIDs, ",")), boardTopIssues)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "failed to get the issues of column %q", c.Name)
+			}
+			column.Issues, column.Count = issues, total
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+func formatBoard(board *jira.Board, columns []boardColumn, jiraURL string) string {
+	msg := fmt.Sprintf("#### [%s](%s/secure/RapidBoard.jspa?rapidView=%d)\n", board.Name, jiraURL, board.ID)
+	if len(columns) == 0 {
+		return msg + "This board has no columns."
+	}
+
+	msg += "| Column | Issues | WIP limit |\n|:--|--:|:--|\n"
+	for _, column := range columns {
+		count := strconv.Itoa(column.Count)
+		if column.overLimit() || column.underLimit() {
+			count = fmt.Sprintf(":warning: **%d**", column.Count)
+		}
+
+		limits := []string{}
+		if column.Min > 0 {
+			limits = append(limits, fmt.Sprintf("min %d", column.Min))
+		}
+		if column.Max > 0 {
+			limits = append(limits, fmt.Sprintf("max %d", column.Max))
+		}
+		msg += fmt.Sprintf("| %s | %s | %s |\n", column.Name, count, strings.Join(limits, ", "))
+	}
+
+	for _, column := range columns {
+		if len(column.Issues) == 0 {
+			continue
+		}
+		issues := []string{}
+		for _, issue := range column.Issues {
+			summary := ""
+			if issue.Fields != nil {
+				summary = " " + truncate(issue.Fields.Summary, 60)
+			}
+			issues = append(issues, fmt.Sprintf("[%s](%s/browse/%s)%s", issue.Key, jiraURL, issue.Key, summary))
+		}
+		line := strings.Join(issues, ", ")
+		if more := column.Count - len(column.Issues); more > 0 {
+			line += fmt.Sprintf(", and %d more", more)
+		}
+		msg += fmt.Sprintf("\n* **%s**: %s", column.Name, line)
+	}
+	return msg
+}
+
+// boardSummary returns a snapshot of the columns, and their issues, of a
+// board, as seen by the user.
+func (p *Plugin) boardSummary(instance Instance, client Client, idOrName string) (string, error) {
+	board, err := resolveBoard(client, idOrName)
+	if err != nil {
+		return "", err
+	}
+	config, err := client.GetBoardConfiguration(board.ID)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to get the board configuration")
+	}
+	columns, err := getBoardColumns(client, board.ID, config)
+	if err != nil {
+		return "", err
+	}
+	return formatBoard(board, columns, instance.GetJiraBaseURL()), nil
+}
+
+func (p *Plugin) httpAutocompleteBoards(w http.ResponseWriter, r *http.Request) (int, error) {
+	mattermostUserID := types.ID(r.Header.Get("Mattermost-User-Id"))
+	out := []model.AutocompleteListItem{}
+
+	_, instance, err := p.LoadUserInstance(mattermostUserID, "")
+	if err != nil {
+		return respondJSON(w, out)
+	}
+	client, _, _, err := p.getClient(instance.GetID(), mattermostUserID)
+	if err != nil {
+		return respondJSON(w, out)
+	}
+	boards, err := client.ListBoards(boardListLimit)
+	if err != nil {
+		return respondErr(w, http.StatusInternalServerError, err)
+	}
+
+	for _, board := range boards {
+		out = append(out, model.AutocompleteListItem{
+			Item:     strconv.Itoa(board.ID),
+			HelpText: fmt.Sprintf("%s (%s)", board.Name, board.Type),
+		})
+	}
+	return respondJSON(w, out)
+}
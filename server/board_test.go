@@ -0,0 +1,134 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type boardsClient struct {
+	testClient
+	boards []jira.Board
+	issues map[string][]jira.Issue
+	totals map[string]int
+}
+
+func (client boardsClient) ListBoards(limit int) ([]jira.Board, error) {
+	return client.boards, nil
+}
+
+func (client boardsClient) GetBoard(boardID int) (*jira.Board, error) {
+	for i, board := range client.boards {
+		if board.ID == boardID {
+			return &client.boards[i], nil
+		}
+	}
+	return nil, errors.New("board not found")
+}
+
+func (client boardsClient) SearchBoardIssues(boardID int, jql string, maxResults int) ([]jira.Issue, int, error) {
+	issues := client.issues[jql]
+	if len(issues) > maxResults {
+		issues = issues[:maxResults]
+	}
+	return issues, client.totals[jql], nil
+}
+
+func boardIssue(key, summary string) jira.Issue {
+	return jira.Issue{Key: key, Fields: &jira.IssueFields{Summary: summary}}
+}
+
+func TestResolveBoard(t *testing.T) {
+	client := boardsClient{
+		boards: []jira.Board{
+			{ID: 1, Name: "Platform Kanban", Type: "kanban"},
+			{ID: 2, Name: "Platform", Type: "scrum"},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		idOrName   string
+		expectedID int
+		expectErr  bool
+	}{
+		"by ID":                   {idOrName: "1", expectedID: 1},
+		"exact name first":        {idOrName: "platform", expectedID: 2},
+		"partial name":            {idOrName: "kanban", expectedID: 1},
+		"unknown name":            {idOrName: "mobile", expectErr: true},
+		"unknown ID":              {idOrName: "3", expectErr: true},
+		"name with several words": {idOrName: "Platform Kanban", expectedID: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			board, err := resolveBoard(client, tc.idOrName)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedID, board.ID)
+		})
+	}
+}
+
+func TestBoardColumns(t *testing.T) {
+	client := boardsClient{
+		issues: map[string][]jira.Issue{
+			"status in (1)":   {boardIssue("KT-1", "First"), boardIssue("KT-2", "Second")},
+			"status in (2,3)": {boardIssue("KT-3", "Third"), boardIssue("KT-4", "Fourth"), boardIssue("KT-5", "Fifth"), boardIssue("KT-6", "Sixth")},
+		},
+		totals: map[string]int{
+			"status in (1)":   2,
+			"status in (2,3)": 7,
+		},
+	}
+	config := &jira.BoardConfiguration{}
+	config.ColumnConfig.ConstraintType = "issueCount"
+	config.ColumnConfig.Columns = []jira.BoardConfigurationColumn{
+		{Name: "To Do", Status: []jira.BoardConfigurationColumnStatus{{ID: "1"}}, Min: 3},
+		{Name: "In Progress", Status: []jira.BoardConfigurationColumnStatus{{ID: "2"}, {ID: "3"}}, Max: 5},
+		{Name: "Backlog"},
+	}
+
+	t.Run("columns with their limits", func(t *testing.T) {
+		columns, err := getBoardColumns(client, 1, config)
+		require.NoError(t, err)
+		require.Len(t, columns, 3)
+
+		assert.Equal(t, 2, columns[0].Count)
+		assert.True(t, columns[0].underLimit())
+		assert.Equal(t, 7, columns[1].Count)
+		assert.Len(t, columns[1].Issues, boardTopIssues)
+		assert.True(t, columns[1].overLimit())
+		assert.Equal(t, 0, columns[2].Count)
+		assert.False(t, columns[2].overLimit() || columns[2].underLimit())
+
+		msg := formatBoard(&jira.Board{ID: 1, Name: "Team board"}, columns, "https://jira.example.com")
+		assert.Contains(t, msg, "#### [Team board](https://jira.example.com/secure/RapidBoard.jspa?rapidView=1)")
+		assert.Contains(t, msg, "| To Do | :warning: **2** | min 3 |")
+		assert.Contains(t, msg, "| In Progress | :warning: **7** | max 5 |")
+		assert.Contains(t, msg, "| Backlog | 0 |  |")
+		assert.Contains(t, msg, "* **In Progress**: [KT-3](https://jira.example.com/browse/KT-3) Third, [KT-4](https://jira.example.com/browse/KT-4) Fourth, [KT-5](https://jira.example.com/browse/KT-5) Fifth, and 4 more")
+		assert.NotContains(t, msg, "**Backlog**")
+	})
+
+	t.Run("no limits unless the board enforces them", func(t *testing.T) {
+		config.ColumnConfig.ConstraintType = boardConstraintNone
+		columns, err := getBoardColumns(client, 1, config)
+		require.NoError(t, err)
+
+		msg := formatBoard(&jira.Board{ID: 1, Name: "Team board"}, columns, "https://jira.example.com")
+		assert.Contains(t, msg, "| To Do | 2 |  |")
+		assert.Contains(t, msg, "| In Progress | 7 |  |")
+	})
+
+	t.Run("no columns", func(t *testing.T) {
+		msg := formatBoard(&jira.Board{ID: 1, Name: "Team board"}, nil, "https://jira.example.com")
+		assert.Contains(t, msg, "This board has no columns.")
+	})
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg" // decodes the JPEG bot icons
+	_ "image/png"  // decodes the PNG bot icons
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	keyBotAppearance = "bot_appearance"
+
+	botIconMaxSize      = 2 * 1024 * 1024
+	botIconMaxDimension = 4096
+	botIconFetchTimeout = 10 * time.Second
+
+	flagBotName = "--name"
+)
+
+// botAppearance is how a system administrator customized the bot, e.g. to
+// tell apart the plugins of several deployments. It is applied again on
+// activation.
+type botAppearance struct {
+	DisplayName string `json:"display_name,omitempty"`
+	Icon        []byte `json:"icon,omitempty"`
+}
+
+func (p *Plugin) loadBotAppearance() (*botAppearance, error) {
+	appearance := &botAppearance{}
+	if err := p.client.KV.Get(keyBotAppearance, appearance); err != nil {
+		return nil, errors.WithMessage(err, "failed to load the appearance of the bot")
+	}
+	return appearance, nil
+}
+
+func (p *Plugin) storeBotAppearance(appearance *botAppearance) error {
+	if appearance.DisplayName == "" && len(appearance.Icon) == 0 {
+		return p.client.KV.Delete(keyBotAppearance)
+	}
+	_, err := p.client.KV.Set(keyBotAppearance, appearance)
+	return errors.WithMessage(err, "failed to store the appearance of the bot")
+}
+
+// botEnsureOptions returns the bot to ensure on activation, and its profile
+// image, as customized by the system administrators.
+func (p *Plugin) botEnsureOptions() (*model.Bot, pluginapi.EnsureBotOption) {
+	bot := &model.Bot{
+		OwnerId:     manifest.Id, // Workaround to support older server version affected by https://github.com/mattermost/mattermost-server/pull/21560
+		Username:    botUserName,
+		DisplayName: botDisplayName,
+		Description: botDescription,
+	}
+	icon := pluginapi.ProfileImagePath(filepath.Join("assets", "profile.png"))
+
+	appearance, err := p.loadBotAppearance()
+	if err != nil {
+		p.client.Log.Warn("Failed to load the appearance of the bot, the default one is used", "Error", err.Error())
+		return bot, icon
+	}
+	if appearance.DisplayName != "" {
+		bot.DisplayName = appearance.DisplayName
+	}
+	if len(appearance.Icon) > 0 {
+		icon = pluginapi.ProfileImageBytes(appearance.Icon)
+	}
+	return bot, icon
+}
+
+// validateBotIcon checks that the image is a PNG or JPEG image that
+// Mattermost accepts as a profile image.
+func validateBotIcon(data []byte) error {
+	if len(data) > botIconMaxSize {
+		return errors.Errorf("the image is larger than %s", types.ByteSize(botIconMaxSize))
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return errors.New("the file is not a PNG or JPEG image")
+	}
+	if format != "png" && format != "jpeg" {
+		return errors.Errorf("the image is a %s image, not a PNG or JPEG one", format)
+	}
+	if config.Width > botIconMaxDimension || config.Height > botIconMaxDimension {
+		return errors.Errorf("the image is %dx%d pixels, more than %dx%d", config.Width, config.Height, botIconMaxDimension, botIconMaxDimension)
+	}
+	return nil
+}
+
+// readBotIcon reads the image, failing if it is larger than the icons can be.
+func readBotIcon(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, botIconMaxSize+1))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to read the image")
+	}
+	if len(data) > botIconMaxSize {
+		return nil, errors.Errorf("the image is larger than %s", types.ByteSize(botIconMaxSize))
+	}
+	return data, nil
+}
+
+// postIDFromPermalink returns the ID of the post of a Mattermost permalink,
+// e.g. https://mattermost.example.com/team/pl/<post ID>, or "".
+func postIDFromPermalink(siteURL, link string) string {
+	if siteURL == "" || !strings.HasPrefix(link, strings.TrimRight(siteURL, "/")+"/") {
+		return ""
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) < 2 || segments[len(segments)-2] != "pl" || !model.IsValidId(segments[len(segments)-1]) {
+		return ""
+	}
+	return segments[len(segments)-1]
+}
+
+// fetchBotIcon returns the image of the first image file attached to the post
+// of a permalink, or else the image at the URL.
+func (p *Plugin) fetchBotIcon(source string) ([]byte, error) {
+	if postID := postIDFromPermalink(p.GetSiteURL(), source); postID != "" {
+		post, err := p.client.Post.GetPost(postID)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to load the post")
+		}
+		for _, fileID := range post.FileIds {
+			info, err := p.client.File.GetInfo(fileID)
+			if err != nil || !info.IsImage() {
+				continue
+			}
+			r, err := p.client.File.Get(fileID)
+			if err != nil {
+				return nil, errors.WithMessage(err, "failed to load the image of the post")
+			}
+			return readBotIcon(r)
+		}
+		return nil, errors.New("the post has no image attached")
+	}
+
+	u, err := url.Parse(source)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("the image must be an http(s) URL, or the link to a post with an image attached")
+	}
+	httpClient := &http.Client{Timeout: botIconFetchTimeout}
+	resp, err := httpClient.Get(source)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to download the image")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to download the image: %s", resp.Status)
+	}
+	return readBotIcon(resp.Body)
+}
+
+// parseSetBotIconArgs returns the image of `/jira admin set-bot-icon`, a URL
+// or `reset`, and the display name given after `--name`, `reset` included.
+func parseSetBotIconArgs(args []string) (source, displayName string, err error) {
+	for i, arg := range args {
+		if arg != flagBotName {
+			continue
+		}
+		displayName = strings.TrimSpace(strings.Join(args[i+1:], " "))
+		if displayName == "" {
+			return "", "", errors.New("`--name` needs a display name, or `reset`")
+		}
+		args = args[:i]
+		break
+	}
+	switch len(args) {
+	case 0:
+		if displayName == "" {
+			return "", "", errors.New("please specify an image or a display name")
+		}
+	case 1:
+		source = args[0]
+	default:
+		return "", "", errors.Errorf("unexpected %q", args[1])
+	}
+	if len(displayName) > model.UserFirstNameMaxRunes {
+		return "", "", errors.Errorf("the display name is longer than %d characters", model.UserFirstNameMaxRunes)
+	}
+	return source, displayName, nil
+}
+
+func (p *Plugin) defaultBotIcon() ([]byte, error) {
+	bundlePath, err := p.client.System.GetBundlePath()
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get bundle path")
+	}
+	return os.ReadFile(filepath.Join(bundlePath, "assets", "profile.png"))
+}
+
+func executeAdminSetBotIcon(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	authorized, err := authorizedSysAdmin(p, header.UserId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira admin set-bot-icon` can only be run by a system administrator.")
+	}
+	source, displayName, err := parseSetBotIconArgs(args)
+	if err != nil {
+		return p.responsef(header, "%v. Please use `/jira admin set-bot-icon [image URL|post link|reset] [--name display name|reset]`.", err)
+	}
+
+	appearance, err := p.loadBotAppearance()
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	botUserID := p.getUserID()
+	done := []string{}
+
+	if source != "" {
+		var icon []byte
+		if source == settingReset {
+			icon, err = p.defaultBotIcon()
+		} else {
+			icon, err = p.fetchBotIcon(source)
+		}
+		if err == nil {
+			err = validateBotIcon(icon)
+		}
+		if err != nil {
+			return p.responsef(header, "Failed to set the icon of the bot: %v.", err)
+		}
+		if err = p.client.User.SetProfileImage(botUserID, bytes.NewReader(icon)); err != nil {
+			return p.responsef(header, "Failed to set the icon of the bot. Error: %v.", err)
+		}
+		appearance.Icon = icon
+		if source == settingReset {
+			appearance.Icon = nil
+		}
+		done = append(done, "icon")
+	}
+
+	if displayName != "" {
+		name := displayName
+		if displayName == settingReset {
+			name = botDisplayName
+		}
+		if _, err = p.client.Bot.Patch(botUserID, &model.BotPatch{DisplayName: &name}); err != nil {
+			return p.responsef(header, "Failed to set the display name of the bot. Error: %v.", err)
+		}
+		appearance.DisplayName = name
+		if displayName == settingReset {
+			appearance.DisplayName = ""
+		}
+		done = append(done, "display name")
+	}
+
+	if err = p.storeBotAppearance(appearance); err != nil {
+		return p.responsef(header, "The %s of the bot changed, but will not be kept when the plugin restarts. Error: %v.", strings.Join(done, " and "), err)
+	}
+	return p.responsef(header, "The %s of the bot changed. It is kept when the plugin restarts.", strings.Join(done, " and "))
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBotIcon(t *testing.T, width, height int) []byte {
+	buf := &bytes.Buffer{}
+	require.NoError(t, png.Encode(buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestValidateBotIcon(t *testing.T) {
+	assert.NoError(t, validateBotIcon(testBotIcon(t, 128, 128)))
+
+	err := validateBotIcon(testBotIcon(t, botIconMaxDimension+1, 1))
+	assert.ErrorContains(t, err, "pixels")
+
+	err = validateBotIcon([]byte("not an image"))
+	assert.ErrorContains(t, err, "not a PNG or JPEG image")
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, gif.Encode(buf, image.NewPaletted(image.Rect(0, 0, 8, 8), color.Palette{color.Black}), nil))
+	err = validateBotIcon(buf.Bytes())
+	assert.ErrorContains(t, err, "gif")
+
+	err = validateBotIcon(make([]byte, botIconMaxSize+1))
+	assert.ErrorContains(t, err, "larger than")
+}
+
+func TestParseSetBotIconArgs(t *testing.T) {
+	source, displayName, err := parseSetBotIconArgs([]string{"https://example.com/icon.png"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/icon.png", source)
+	assert.Empty(t, displayName)
+
+	source, displayName, err = parseSetBotIconArgs([]string{"reset", "--name", "Jira", "Production"})
+	require.NoError(t, err)
+	assert.Equal(t, "reset", source)
+	assert.Equal(t, "Jira Production", displayName)
+
+	source, displayName, err = parseSetBotIconArgs([]string{"--name", "reset"})
+	require.NoError(t, err)
+	assert.Empty(t, source)
+	assert.Equal(t, "reset", displayName)
+
+	for _, args := range [][]string{{}, {"--name"}, {"a", "b"}, {"--name", strings.Repeat("x", 100)}} {
+		_, _, err = parseSetBotIconArgs(args)
+		assert.Error(t, err, args)
+	}
+}
+
+func TestPostIDFromPermalink(t *testing.T) {
+	postID := "p4kfsxwzfbnrtyjtqeann7xphw"
+	assert.Equal(t, postID, postIDFromPermalink("https://mm.example.com/", "https://mm.example.com/team/pl/"+postID))
+	assert.Empty(t, postIDFromPermalink("https://mm.example.com", "https://other.example.com/team/pl/"+postID))
+	assert.Empty(t, postIDFromPermalink("https://mm.example.com", "https://mm.example.com/team/channels/town-square"))
+	assert.Empty(t, postIDFromPermalink("", "https://mm.example.com/team/pl/"+postID))
+}
+
+func TestFetchBotIcon(t *testing.T) {
+	icon := testBotIcon(t, 16, 16)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/icon.png":
+			_, _ = w.Write(icon)
+		case "/large.png":
+			_, _ = w.Write(make([]byte, botIconMaxSize+1))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	p := &Plugin{}
+	got, err := p.fetchBotIcon(ts.URL + "/icon.png")
+	require.NoError(t, err)
+	assert.Equal(t, icon, got)
+
+	_, err = p.fetchBotIcon(ts.URL + "/large.png")
+	assert.ErrorContains(t, err, "larger than")
+
+	_, err = p.fetchBotIcon(ts.URL + "/missing.png")
+	assert.ErrorContains(t, err, "404")
+
+	_, err = p.fetchBotIcon("file:///etc/passwd")
+	assert.Error(t, err)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// broadcastInterval is the wait between two broadcast DMs, so that a
+// broadcast to many users does not flood the server.
+var broadcastInterval = 200 * time.Millisecond
+
+// broadcastCommandRegexp matches the raw command up to the message, with an
+// optional --instance flag before it. The message is taken from the raw
+// command so that its markdown, e.g. its line breaks, is kept.
+var broadcastCommandRegexp = regexp.MustCompile(`^\s*/jira\s+admin\s+broadcast(?:\s+--instance(?:=|\s+)(\S+))?(?:\s+|$)`)
+
+// parseBroadcastCommand returns the instance that the broadcast is scoped to,
+// if any, and the message.
+func parseBroadcastCommand(command string) (instance, message string, err error) {
+	m := broadcastCommandRegexp.FindStringSubmatchIndex(command)
+	if m == nil {
+		return "", "", errors.New("the message must follow `/jira admin broadcast [--instance=jiraURL]`")
+	}
+	if m[2] >= 0 {
+		instance = command[m[2]:m[3]]
+	}
+	message = strings.TrimSpace(command[m[1]:])
+	if message == "" {
+		return "", "", errors.New("the message is empty")
+	}
+	return instance, message, nil
+}
+
+// broadcastRecipients returns the users with at least one connection, or with
+// a connection to the instance if one is given.
+func (p *Plugin) broadcastRecipients(instanceID types.ID) ([]types.ID, error) {
+	recipients := []types.ID{}
+	err := p.userStore.MapUsers(func(user *User) error {
+		if user.ConnectedInstances.IsEmpty() {
+			return nil
+		}
+		if instanceID != "" && !user.ConnectedInstances.Contains(instanceID) {
+			return nil
+		}
+		recipients = append(recipients, user.MattermostUserID)
+		return nil
+	})
+	return recipients, err
+}
+
+// sendBroadcast DMs the message to the recipients, one at a time, and then
+// tells the sender how many were reached.
+func (p *Plugin) sendBroadcast(senderID string, recipients []types.ID, message string) {
+	sent := 0
+	for i, recipient := range recipients {
+		if i > 0 {
+			time.Sleep(broadcastInterval)
+		}
+		if _, err := p.CreateBotDMtoMMUserID(recipient.String(), "%s", message); err != nil {
+			p.infof("sendBroadcast: failed to send the message to %s: %v", recipient, err)
+			continue
+		}
+		sent++
+	}
+	p.infof("sendBroadcast: the message from %s was sent to %d of %d user(s)", senderID, sent, len(recipients))
+
+	_, err := p.CreateBotDMtoMMUserID(senderID, "Your broadcast was sent to %d of %d user(s).", sent, len(recipients))
+	if err != nil {
+		p.infof("sendBroadcast: failed to report to %s: %v", senderID, err)
+	}
+}
+
+func executeAdminBroadcast(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	authorized, err := authorizedSysAdmin(p, header.UserId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira admin broadcast` can only be run by a system administrator.")
+	}
+
+	jiraURL, message, err := parseBroadcastCommand(header.Command)
+	if err != nil {
+		return p.responsef(header, "Please specify a message to send, e.g. `/jira admin broadcast Please reconnect your Jira account`: %v.", err)
+	}
+
+	instanceID := types.ID("")
+	scope := "connected to Jira"
+	if jiraURL != "" {
+		instanceID, err = p.resolveInstanceURL(jiraURL)
+		if err != nil {
+			return p.responsef(header, "Failed to parse the Jira URL. Error: %v.", err)
+		}
+		instances, err := p.instanceStore.LoadInstances()
+		if err != nil {
+			return p.responsef(header, "Failed to load the Jira instances. Error: %v.", err)
+		}
+		if !instances.checkIfExists(instanceID) {
+			return p.responsef(header, "Jira instance %s is not installed.", instanceID)
+		}
+		scope = "connected to " + instanceID.String()
+	}
+
+	recipients, err := p.broadcastRecipients(instanceID)
+	if err != nil {
+		return p.responsef(header, "Failed to list the connected users. Error: %v.", err)
+	}
+	if len(recipients) == 0 {
+		return p.responsef(header, "No users are %s, the message was not sent.", scope)
+	}
+
+	go p.sendBroadcast(header.UserId, recipients, message)
+
+	return p.responsef(header, "Sending the message to the %d user(s) %s. You will get a DM when it is done.", len(recipients), scope)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+func TestParseBroadcastCommand(t *testing.T) {
+	for name, tc := range map[string]struct {
+		command          string
+		expectedInstance string
+		expectedMessage  string
+		expectError      bool
+	}{
+		"message": {
+			command:         "/jira admin broadcast Please **reconnect** your account",
+			expectedMessage: "Please **reconnect** your account",
+		},
+		"markdown on several lines": {
+			command:         "/jira admin broadcast We are migrating Jira.\n\n* Disconnect\n* Reconnect",
+			expectedMessage: "We are migrating Jira.\n\n* Disconnect\n* Reconnect",
+		},
+		"with --instance=": {
+			command:          "/jira admin broadcast --instance=https://jira.example.com Please reconnect",
+			expectedInstance: "https://jira.example.com",
+			expectedMessage:  "Please reconnect",
+		},
+		"with --instance": {
+			command:          "/jira  admin broadcast --instance prod Please reconnect",
+			expectedInstance: "prod",
+			expectedMessage:  "Please reconnect",
+		},
+		"--instance later in the message": {
+			command:         "/jira admin broadcast Use --instance=prod from now on",
+			expectedMessage: "Use --instance=prod from now on",
+		},
+		"no message": {
+			command:     "/jira admin broadcast --instance=prod",
+			expectError: true,
+		},
+		"empty": {
+			command:     "/jira admin broadcast",
+			expectError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			instance, message, err := parseBroadcastCommand(tc.command)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedInstance, instance)
+			assert.Equal(t, tc.expectedMessage, message)
+		})
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	connected := func(id types.ID, instances ...*testInstance) *User {
+		user := NewUser(id)
+		for _, instance := range instances {
+			user.ConnectedInstances.Set(instance.Common())
+		}
+		return user
+	}
+
+	p := &Plugin{}
+	p.updateConfig(func(conf *config) {
+		conf.botUserID = "bot_id"
+	})
+	p.userStore = purgeUserStore{users: map[types.ID]*User{
+		"user1": connected("user1", testInstance1),
+		"user2": connected("user2", testInstance1, testInstance2),
+		"user3": connected("user3"),
+	}}
+
+	recipients, err := p.broadcastRecipients("")
+	require.NoError(t, err)
+	assert.Equal(t, []types.ID{"user1", "user2"}, recipients)
+
+	recipients, err = p.broadcastRecipients(testInstance2.InstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, []types.ID{"user2"}, recipients)
+
+	defaultInterval := broadcastInterval
+	broadcastInterval = 0
+	defer func() { broadcastInterval = defaultInterval }()
+
+	api := &plugintest.API{}
+	api.On("GetDirectChannel", mock.AnythingOfType("string"), "bot_id").Return(func(userID, botID string) *model.Channel {
+		return &model.Channel{Id: "dm_" + userID}
+	}, nil)
+	messages := map[string]string{}
+	api.On("CreatePost", mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+		post := args.Get(0).(*model.Post)
+		messages[post.ChannelId] = post.Message
+	}).Return(&model.Post{}, nil)
+	api.On("LogInfo", mock.Anything, mock.Anything, mock.Anything).Return()
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+
+	p.sendBroadcast("admin", []types.ID{"user1", "user2"}, "Please **reconnect**\n\nThanks")
+	assert.Equal(t, map[string]string{
+		"dm_user1": "Please **reconnect**\n\nThanks",
+		"dm_user2": "Please **reconnect**\n\nThanks",
+		"dm_admin": "Your broadcast was sent to 2 of 2 user(s).",
+	}, messages)
+}
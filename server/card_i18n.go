@@ -0,0 +1,147 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+// Labels of the subscription cards, in English.
+const (
+	cardLabelAssignee     = "Assignee"
+	cardLabelPriority     = "Priority"
+	cardLabelLinks        = "Links"
+	cardLabelSubscription = "Subscription"
+	cardLabelTimeInStatus = "Time in Status"
+	cardLabelDue          = "Due"
+	cardLabelDevelopment  = "Development"
+)
+
+// cardLabelTranslations are the labels of the subscription cards, by
+// lowercase language. The data from Jira, e.g. the status names, is posted
+// as is.
+var cardLabelTranslations = map[string]map[string]string{
+	"de": {
+		cardLabelAssignee:     "Zugewiesene Person",
+		cardLabelPriority:     "Priorität",
+		cardLabelLinks:        "Verknüpfungen",
+		cardLabelSubscription: "Abonnement",
+		cardLabelTimeInStatus: "Zeit im Status",
+		cardLabelDue:          "Fällig",
+		cardLabelDevelopment:  "Entwicklung",
+	},
+	"es": {
+		cardLabelAssignee:     "Responsable",
+		cardLabelPriority:     "Prioridad",
+		cardLabelLinks:        "Vínculos",
+		cardLabelSubscription: "Suscripción",
+		cardLabelTimeInStatus: "Tiempo en el estado",
+		cardLabelDue:          "Vencimiento",
+		cardLabelDevelopment:  "Desarrollo",
+	},
+	"fr": {
+		cardLabelAssignee:     "Responsable",
+		cardLabelPriority:     "Priorité",
+		cardLabelLinks:        "Liens",
+		cardLabelSubscription: "Abonnement",
+		cardLabelTimeInStatus: "Temps dans le statut",
+		cardLabelDue:          "Échéance",
+		cardLabelDevelopment:  "Développement",
+	},
+	"ja": {
+		cardLabelAssignee:     "担当者",
+		cardLabelPriority:     "優先度",
+		cardLabelLinks:        "リンク",
+		cardLabelSubscription: "サブスクリプション",
+		cardLabelTimeInStatus: "ステータスの経過時間",
+		cardLabelDue:          "期限",
+		cardLabelDevelopment:  "開発",
+	},
+	"pt-br": {
+		cardLabelAssignee:     "Responsável",
+		cardLabelPriority:     "Prioridade",
+		cardLabelLinks:        "Links",
+		cardLabelSubscription: "Assinatura",
+		cardLabelTimeInStatus: "Tempo no status",
+		cardLabelDue:          "Vencimento",
+		cardLabelDevelopment:  "Desenvolvimento",
+	},
+}
+
+// localizeCardLabel returns the label in the language, e.g. "pt-BR", or in
+// its base language, "pt". It falls back to English.
+func localizeCardLabel(language, label string) string {
+	language = strings.ToLower(strings.ReplaceAll(language, "_", "-"))
+	if translated, ok := cardLabelTranslations[language][label]; ok {
+		return translated
+	}
+	if i := strings.Index(language, "-"); i > 0 {
+		if translated, ok := cardLabelTranslations[language[:i]][label]; ok {
+			return translated
+		}
+	}
+	return label
+}
+
+// localizeCardFields returns copies of the fields with their labels in the
+// language.
+func localizeCardFields(language string, fields []*model.SlackAttachmentField) []*model.SlackAttachmentField {
+	if len(fields) == 0 {
+		return fields
+	}
+	localized := make([]*model.SlackAttachmentField, 0, len(fields))
+	for _, field := range fields {
+		f := *field
+		if f.Title != "" {
+			f.Title = localizeCardLabel(language, f.Title)
+		}
+		localized = append(localized, &f)
+	}
+	return localized
+}
+
+// parseTeamLanguages parses the TeamLanguages setting, e.g. "sales=de,
+// support-fr=fr", into languages by lowercase team name.
+func parseTeamLanguages(setting string) (map[string]string, error) {
+	languages := map[string]string{}
+	for _, pair := range strings.Split(setting, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, errors.Errorf("invalid team language %q, expected the form `team name=language`", pair)
+		}
+		languages[strings.ToLower(strings.TrimSpace(parts[0]))] = strings.TrimSpace(parts[1])
+	}
+	return languages, nil
+}
+
+// channelLanguage returns the language of the cards posted in the channel:
+// the one set for its team in the plugin settings, else the default language
+// of the server. Mattermost has no language setting of its own for teams.
+func (p *Plugin) channelLanguage(channelID string) string {
+	conf := p.getConfig()
+	if len(conf.teamLanguages) == 0 {
+		return conf.defaultServerLocale
+	}
+
+	channel, err := p.client.Channel.Get(channelID)
+	if err != nil || channel.TeamId == "" {
+		return conf.defaultServerLocale
+	}
+	team, err := p.client.Team.Get(channel.TeamId)
+	if err != nil {
+		return conf.defaultServerLocale
+	}
+	if language, ok := conf.teamLanguages[strings.ToLower(team.Name)]; ok {
+		return language
+	}
+	return conf.defaultServerLocale
+}
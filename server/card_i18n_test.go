@@ -0,0 +1,82 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalizeCardLabel(t *testing.T) {
+	assert.Equal(t, "Priorität", localizeCardLabel("de", cardLabelPriority))
+	assert.Equal(t, "Priorität", localizeCardLabel("de-AT", cardLabelPriority))
+	assert.Equal(t, "Prioridade", localizeCardLabel("pt_BR", cardLabelPriority))
+	assert.Equal(t, "Priority", localizeCardLabel("en", cardLabelPriority))
+	assert.Equal(t, "Priority", localizeCardLabel("", cardLabelPriority))
+	assert.Equal(t, "Priority", localizeCardLabel("xx", cardLabelPriority))
+	assert.Equal(t, "Unknown label", localizeCardLabel("de", "Unknown label"))
+}
+
+func TestParseTeamLanguages(t *testing.T) {
+	languages, err := parseTeamLanguages(" Sales=de, support-fr = fr ,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"sales": "de", "support-fr": "fr"}, languages)
+
+	for _, invalid := range []string{"sales", "=de", "sales="} {
+		_, err = parseTeamLanguages(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestPostToChannelTeamLanguage(t *testing.T) {
+	bb, err := os.ReadFile(filepath.Join("testdata", "webhook-issue-created.json"))
+	require.NoError(t, err)
+
+	api := &plugintest.API{}
+	api.On("GetChannel", "thechannelid").Return(&model.Channel{Id: "thechannelid", TeamId: "theteamid"}, nil)
+	api.On("GetTeam", "theteamid").Return(&model.Team{Id: "theteamid", Name: "equipe"}, nil)
+	api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(func(post *model.Post) *model.Post {
+		return post.Clone()
+	}, nil)
+
+	p := Plugin{}
+	p.updateConfig(func(conf *config) {
+		conf.teamLanguages = map[string]string{"equipe": "fr"}
+		conf.defaultServerLocale = "en"
+		conf.DisplaySubscriptionNameInNotifications = true
+	})
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.userStore = mockUserStore{}
+
+	wh, err := ParseWebhook(bb)
+	require.NoError(t, err)
+
+	post, _, err := wh.PostToChannel(&p, "", "thechannelid", "theuserid", "Bugs", RenderStyleFull, "", nil)
+	require.NoError(t, err)
+
+	attachments := post.Attachments()
+	require.Len(t, attachments, 1)
+	assert.Contains(t, attachments[0].Pretext, "\nAbonnement: **Bugs**")
+	titles := []string{}
+	values := []string{}
+	for _, field := range attachments[0].Fields {
+		titles = append(titles, field.Title)
+		values = append(values, field.Value.(string))
+	}
+	assert.Equal(t, []string{"Priorité"}, titles)
+	// The data from Jira is not translated.
+	assert.Contains(t, values, "High")
+
+	// The fields of the event are left in English for the other channels.
+	assert.Equal(t, cardLabelPriority, wh.(*webhook).fields[0].Title)
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+const (
+	prefixChannelReadOnly = "channel_read_only_"
+
+	channelReadOnlyMessage = "Jira writes are disabled in this channel."
+)
+
+// isChannelReadOnly reports whether changes to Jira, e.g. creating or
+// assigning issues, are disabled in the channel. Reading issues and
+// subscription posts are not affected.
+func (p *Plugin) isChannelReadOnly(channelID string) bool {
+	if channelID == "" {
+		return false
+	}
+
+	var readOnly bool
+	err := p.client.KV.Get(prefixChannelReadOnly+channelID, &readOnly)
+	if err != nil {
+		p.client.Log.Warn("Failed to load the Jira read-only flag of the channel", "ChannelID", channelID, "Error", err.Error())
+		return false
+	}
+	return readOnly
+}
+
+func (p *Plugin) setChannelReadOnly(channelID string, readOnly bool) error {
+	if !readOnly {
+		return p.client.KV.Delete(prefixChannelReadOnly + channelID)
+	}
+	_, err := p.client.KV.Set(prefixChannelReadOnly+channelID, true)
+	return err
+}
+
+// canManageChannelReadOnly reports whether the user is a system or channel
+// administrator.
+func (p *Plugin) canManageChannelReadOnly(userID, channelID string) (bool, error) {
+	authorized, err := authorizedSysAdmin(p, userID)
+	if err != nil || authorized {
+		return authorized, err
+	}
+	return p.client.User.HasPermissionToChannel(userID, channelID, model.PermissionManageChannelRoles), nil
+}
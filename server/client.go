@@ -18,6 +18,7 @@ import (
 
 	jira "github.com/andygrunwald/go-jira"
 	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
 
 	"github.com/mattermost/mattermost/server/public/plugin"
 	"github.com/mattermost/mattermost/server/public/pluginapi"
@@ -38,6 +39,7 @@ type Client interface {
 	ProjectService
 	SearchService
 	UserService
+	BoardService
 }
 
 // RESTService is the low-level interface for invoking the upstream service.
@@ -65,24 +67,48 @@ type ProjectService interface {
 // SearchService is the interface for search-related APIs.
 type SearchService interface {
 	SearchIssues(jql string, options *jira.SearchOptions) ([]jira.Issue, error)
+	SearchIssuesWithTotal(jql string, options *jira.SearchOptions) ([]jira.Issue, int, error)
 	SearchUsersAssignableToIssue(issueKey, query string, maxResults int) ([]jira.User, error)
 	SearchUsersAssignableInProject(projectKey, query string, maxResults int) ([]jira.User, error)
 	SearchAutoCompleteFields(params map[string]string) (*AutoCompleteResult, error)
 	GetUserVisibilityGroups(params map[string]string) (*CommentVisibilityResult, error)
+	GetFilter(filterID int) (*jira.Filter, error)
 }
 
 // IssueService is the interface for issue-related APIs.
 type IssueService interface {
 	GetIssue(key string, options *jira.GetQueryOptions) (*jira.Issue, error)
+	GetRemoteLinks(issueKey string) ([]jira.RemoteLink, error)
+	GetIssueLinkTypes() ([]jira.IssueLinkType, error)
+	GetFields() ([]jira.Field, error)
 	CreateIssue(issue *jira.Issue) (*jira.Issue, error)
 
 	AddAttachment(mmClient pluginapi.Client, issueKey, fileID string, maxSize types.ByteSize) (mattermostName, jiraName, mime string, err error)
 	AddComment(issueKey string, comment *jira.Comment) (*jira.Comment, error)
+	AddInternalComment(issueKey string, comment *jira.Comment) (*jira.Comment, error)
+	AddWorklog(issueKey string, record *jira.WorklogRecord) (*jira.WorklogRecord, error)
+	DeleteComment(issueKey, commentID string) error
+	GetComments(issueKey string, startAt, maxResults int) (*CommentPage, error)
+	GetDevStatusSummary(issueID string) (*DevStatusSummary, error)
+	GetDevStatusPullRequests(issueID, applicationType string) ([]DevStatusPullRequest, error)
 	DoTransition(issueKey, transitionID string) error
 	GetCreateMetaInfo(api plugin.API, options *jira.GetQueryOptions) (*jira.CreateMetaInfo, error)
 	GetTransitions(issueKey string) ([]jira.Transition, error)
 	UpdateAssignee(issueKey string, user *jira.User) error
 	UpdateComment(issueKey string, comment *jira.Comment) (*jira.Comment, error)
+	GetWatchers(issueKey string) (*IssueWatchers, error)
+	AddWatcher(issueKey string, connection *Connection) error
+	RemoveWatcher(issueKey string, connection *Connection) error
+}
+
+// BoardService is the interface for the Agile board APIs.
+type BoardService interface {
+	ListBoards(limit int) ([]jira.Board, error)
+	GetBoard(boardID int) (*jira.Board, error)
+	GetBoardConfiguration(boardID int) (*jira.BoardConfiguration, error)
+	SearchBoardIssues(boardID int, jql string, maxResults int) ([]jira.Issue, int, error)
+	ListProjectBoards(projectKey, boardType string) ([]jira.Board, error)
+	ListBoardSprints(boardID int, states string) ([]jira.Sprint, error)
 }
 
 // JiraClient is the common implementation of most Jira APIs, except those that are
@@ -191,6 +217,45 @@ func (client JiraClient) GetIssue(key string, options *jira.GetQueryOptions) (*j
 	return issue, nil
 }
 
+// GetRemoteLinks returns the remote links of an issue with issueKey.
+func (client JiraClient) GetRemoteLinks(issueKey string) ([]jira.RemoteLink, error) {
+	links, resp, err := client.Jira.Issue.GetRemoteLinks(issueKey)
+	if err != nil {
+		return nil, userFriendlyJiraError(resp, err)
+	}
+	if links == nil {
+		return nil, nil
+	}
+	return *links, nil
+}
+
+// GetIssueLinkTypes returns the issue link types of the instance.
+func (client JiraClient) GetIssueLinkTypes() ([]jira.IssueLinkType, error) {
+	linkTypes, resp, err := client.Jira.IssueLinkType.GetList()
+	if err != nil {
+		return nil, userFriendlyJiraError(resp, err)
+	}
+	return linkTypes, nil
+}
+
+// GetFields returns all the fields of the instance, with their schema.
+func (client JiraClient) GetFields() ([]jira.Field, error) {
+	fields, resp, err := client.Jira.Field.GetList()
+	if err != nil {
+		return nil, userFriendlyJiraError(resp, err)
+	}
+	return fields, nil
+}
+
+// GetFilter returns a saved filter, with its JQL.
+func (client JiraClient) GetFilter(filterID int) (*jira.Filter, error) {
+	filter, resp, err := client.Jira.Filter.Get(filterID)
+	if err != nil {
+		return nil, userFriendlyJiraError(resp, err)
+	}
+	return filter, nil
+}
+
 // GetTransitions returns transitions for an issue with issueKey.
 func (client JiraClient) GetTransitions(issueKey string) ([]jira.Transition, error) {
 	transitions, resp, err := client.Jira.Issue.GetTransitions(issueKey)
@@ -227,6 +292,215 @@ func (client JiraClient) AddComment(issueKey string, comment *jira.Comment) (*ji
 	return added, err
 }
 
+// AddWorklog logs time spent on an issue.
+func (client JiraClient) AddWorklog(issueKey string, record *jira.WorklogRecord) (*jira.WorklogRecord, error) {
+	added, resp, err := client.Jira.Issue.AddWorklogRecord(issueKey, record)
+	if err != nil {
+		return nil, userFriendlyJiraError(resp, err)
+	}
+	return added, nil
+}
+
+// IssueWatchers tells how many users watch an issue, and whether the user of
+// the client is one of them.
+type IssueWatchers struct {
+	WatchCount int  `json:"watchCount"`
+	IsWatching bool `json:"isWatching"`
+}
+
+// GetWatchers returns the watchers of an issue.
+func (client JiraClient) GetWatchers(issueKey string) (*IssueWatchers, error) {
+	watchers := &IssueWatchers{}
+	if err := client.RESTGet(fmt.Sprintf("2/issue/%s/watchers", issueKey), nil, watchers); err != nil {
+		return nil, err
+	}
+	return watchers, nil
+}
+
+// updateWatchers adds or removes a watcher of an issue, identified by the body
+// or by the query parameters, which differ between Jira Cloud and Jira Server.
+func (client JiraClient) updateWatchers(method, issueKey string, body interface{}, params map[string]string) error {
+	endpointURL, err := endpointURL(fmt.Sprintf("2/issue/%s/watchers", issueKey))
+	if err != nil {
+		return err
+	}
+	req, err := client.Jira.NewRequest(method, endpointURL, body)
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	for k, v := range params {
+		q.Add(k, v)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := client.Jira.Do(req, nil)
+	if err != nil {
+		return userFriendlyJiraError(resp, err)
+	}
+	return nil
+}
+
+// jsmCommentProperty is a property of a comment of Jira Service Management.
+type jsmCommentProperty struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+// AddInternalComment adds a comment to an issue of a Jira Service Management
+// project that only its agents see, not the customers.
+func (client JiraClient) AddInternalComment(issueKey string, comment *jira.Comment) (*jira.Comment, error) {
+	payload := struct {
+		Body       string               `json:"body"`
+		Properties []jsmCommentProperty `json:"properties"`
+	}{
+		Body: comment.Body,
+		Properties: []jsmCommentProperty{{
+			Key:   jsmPublicCommentProperty,
+			Value: map[string]bool{"internal": true},
+		}},
+	}
+	req, err := client.Jira.NewRequest(http.MethodPost, fmt.Sprintf("rest/api/2/issue/%s/comment", issueKey), payload)
+	if err != nil {
+		return nil, err
+	}
+	added := &jira.Comment{}
+	resp, err := client.Jira.Do(req, added)
+	if err != nil {
+		return nil, userFriendlyJiraError(resp, err)
+	}
+	return added, nil
+}
+
+// DeleteComment deletes a comment of an issue.
+func (client JiraClient) DeleteComment(issueKey, commentID string) error {
+	req, err := client.Jira.NewRequest(http.MethodDelete, fmt.Sprintf("rest/api/2/issue/%s/comment/%s", issueKey, commentID), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Jira.Do(req, nil)
+	if err != nil {
+		return userFriendlyJiraError(resp, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// CommentPage is a page of the comments of an issue, oldest first.
+type CommentPage struct {
+	StartAt    int             `json:"startAt"`
+	MaxResults int             `json:"maxResults"`
+	Total      int             `json:"total"`
+	Comments   []ListedComment `json:"comments"`
+}
+
+// ListedComment is a comment of an issue with its properties, e.g. whether it
+// is internal to the agents of Jira Service Management.
+type ListedComment struct {
+	jira.Comment
+	Properties []jsmCommentProperty `json:"properties,omitempty"`
+}
+
+// GetComments returns a page of the comments of an issue that the user can
+// see, oldest first. With maxResults 0 only the total is returned.
+func (client JiraClient) GetComments(issueKey string, startAt, maxResults int) (*CommentPage, error) {
+	page := &CommentPage{}
+	err := client.RESTGet(fmt.Sprintf("2/issue/%s/comment", issueKey), map[string]string{
+		"startAt":    strconv.Itoa(startAt),
+		"maxResults": strconv.Itoa(maxResults),
+		"expand":     "properties",
+	}, page)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// DevStatusSummary counts the branches, commits and pull requests linked to
+// an issue in the development panel of Jira Cloud.
+type DevStatusSummary struct {
+	Branch      DevStatusSummaryItem `json:"branch"`
+	Repository  DevStatusSummaryItem `json:"repository"`
+	PullRequest DevStatusSummaryItem `json:"pullrequest"`
+}
+
+type DevStatusSummaryItem struct {
+	Overall struct {
+		Count   int `json:"count"`
+		Details struct {
+			OpenCount int `json:"openCount"`
+		} `json:"details"`
+	} `json:"overall"`
+	// ByInstanceType are the development tools, e.g. GitHub, by name.
+	ByInstanceType map[string]struct {
+		Count int    `json:"count"`
+		Name  string `json:"name"`
+	} `json:"byInstanceType"`
+}
+
+// DevStatusPullRequest is a pull request linked to an issue.
+type DevStatusPullRequest struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Status string `json:"status"`
+}
+
+// devStatusGet gets an endpoint of the development information API, which is
+// not part of the REST API.
+func (client JiraClient) devStatusGet(endpoint string, params map[string]string, dest interface{}) error {
+	req, err := client.Jira.NewRequest(http.MethodGet, "rest/dev-status/latest/"+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	for k, v := range params {
+		q.Add(k, v)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := client.Jira.Do(req, dest)
+	if err != nil {
+		return userFriendlyJiraError(resp, err)
+	}
+	return nil
+}
+
+// GetDevStatusSummary returns the development information of an issue, by
+// issue ID.
+func (client JiraClient) GetDevStatusSummary(issueID string) (*DevStatusSummary, error) {
+	result := struct {
+		Summary DevStatusSummary `json:"summary"`
+	}{}
+	if err := client.devStatusGet("issue/summary", map[string]string{"issueId": issueID}, &result); err != nil {
+		return nil, err
+	}
+	return &result.Summary, nil
+}
+
+// GetDevStatusPullRequests returns the pull requests linked to an issue in a
+// development tool, e.g. GitHub.
+func (client JiraClient) GetDevStatusPullRequests(issueID, applicationType string) ([]DevStatusPullRequest, error) {
+	result := struct {
+		Detail []struct {
+			PullRequests []DevStatusPullRequest `json:"pullRequests"`
+		} `json:"detail"`
+	}{}
+	err := client.devStatusGet("issue/detail", map[string]string{
+		"issueId":         issueID,
+		"applicationType": applicationType,
+		"dataType":        "pullrequest",
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	pullRequests := []DevStatusPullRequest{}
+	for _, detail := range result.Detail {
+		pullRequests = append(pullRequests, detail.PullRequests...)
+	}
+	return pullRequests, nil
+}
+
 // UpdateComment changes a comment of an issue.
 func (client JiraClient) UpdateComment(issueKey string, comment *jira.Comment) (*jira.Comment, error) {
 	updated, resp, err := client.Jira.Issue.UpdateComment(issueKey, comment)
@@ -236,11 +510,94 @@ func (client JiraClient) UpdateComment(issueKey string, comment *jira.Comment) (
 	return updated, err
 }
 
+// ListBoards returns the Agile boards that the user can see.
+func (client JiraClient) ListBoards(limit int) ([]jira.Board, error) {
+	opts := &jira.BoardListOptions{
+		SearchOptions: jira.SearchOptions{MaxResults: limit},
+	}
+	list, resp, err := client.Jira.Board.GetAllBoards(opts)
+	if err != nil {
+		return nil, userFriendlyJiraError(resp, err)
+	}
+	return list.Values, nil
+}
+
+// GetBoard returns an Agile board.
+func (client JiraClient) GetBoard(boardID int) (*jira.Board, error) {
+	board, resp, err := client.Jira.Board.GetBoard(boardID)
+	if err != nil {
+		return nil, userFriendlyJiraError(resp, err)
+	}
+	return board, nil
+}
+
+// ListProjectBoards returns the Agile boards of a project, of a type such as
+// "scrum", or of any type if boardType is empty.
+func (client JiraClient) ListProjectBoards(projectKey, boardType string) ([]jira.Board, error) {
+	opts := &jira.BoardListOptions{
+		BoardType:      boardType,
+		ProjectKeyOrID: projectKey,
+		SearchOptions:  jira.SearchOptions{MaxResults: 50},
+	}
+	list, resp, err := client.Jira.Board.GetAllBoards(opts)
+	if err != nil {
+		return nil, userFriendlyJiraError(resp, err)
+	}
+	return list.Values, nil
+}
+
+// ListBoardSprints returns the sprints of an Agile board in the states, a
+// comma separated list such as "active,future".
+func (client JiraClient) ListBoardSprints(boardID int, states string) ([]jira.Sprint, error) {
+	list, resp, err := client.Jira.Board.GetAllSprintsWithOptions(boardID, &jira.GetAllSprintsOptions{
+		State:         states,
+		SearchOptions: jira.SearchOptions{MaxResults: 50},
+	})
+	if err != nil {
+		return nil, userFriendlyJiraError(resp, err)
+	}
+	return list.Values, nil
+}
+
+// GetBoardConfiguration returns the configuration of an Agile board, its
+// columns in particular.
+func (client JiraClient) GetBoardConfiguration(boardID int) (*jira.BoardConfiguration, error) {
+	config, resp, err := client.Jira.Board.GetBoardConfiguration(boardID)
+	if err != nil {
+		return nil, userFriendlyJiraError(resp, err)
+	}
+	return config, nil
+}
+
+// SearchBoardIssues returns the first issues of an Agile board that match
+// jql, and how many match overall.
+func (client JiraClient) SearchBoardIssues(boardID int, jql string, maxResults int) ([]jira.Issue, int, error) {
+	req, err := client.Jira.NewRequest(http.MethodGet, fmt.Sprintf("rest/agile/1.0/board/%d/issue", boardID), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	q := req.URL.Query()
+	q.Add("jql", jql)
+	q.Add("maxResults", strconv.Itoa(maxResults))
+	q.Add("fields", "summary,status")
+	req.URL.RawQuery = q.Encode()
+
+	result := struct {
+		Issues []jira.Issue `json:"issues"`
+		Total  int          `json:"total"`
+	}{}
+	resp, err := client.Jira.Do(req, &result)
+	if err != nil {
+		return nil, 0, userFriendlyJiraError(resp, err)
+	}
+	return result.Issues, result.Total, nil
+}
+
 // SearchIssues searches issues as specified by jql and options.
 func (client JiraClient) SearchIssues(jql string, options *jira.SearchOptions) ([]jira.Issue, error) {
 	found, resp, err := client.Jira.Issue.Search(jql, options)
 	if err != nil {
-		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
+		if resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized) {
 			return nil, errors.New("not authorized to search issues")
 		}
 		return nil, userFriendlyJiraError(resp, err)
@@ -248,6 +605,22 @@ func (client JiraClient) SearchIssues(jql string, options *jira.SearchOptions) (
 	return found, nil
 }
 
+// SearchIssuesWithTotal searches issues as specified by jql and options, and
+// also returns the total number of issues matching the jql.
+func (client JiraClient) SearchIssuesWithTotal(jql string, options *jira.SearchOptions) ([]jira.Issue, int, error) {
+	found, resp, err := client.Jira.Issue.Search(jql, options)
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized) {
+			return nil, 0, errors.New("not authorized to search issues")
+		}
+		return nil, 0, userFriendlyJiraError(resp, err)
+	}
+	if resp == nil {
+		return found, len(found), nil
+	}
+	return found, resp.Total, nil
+}
+
 type Result struct {
 	Value       string `json:"value"`
 	DisplayName string `json:"displayName"`
@@ -501,6 +874,11 @@ func (e RESTError) StatusCode() int {
 	return e.Status
 }
 
+// Unwrap gives access to the underlying error, e.g. a failed OAuth2 token refresh.
+func (e RESTError) Unwrap() error {
+	return e.error
+}
+
 // StatusCode is a convenience function that returns the status code if err implements a
 // StatusCoder, otherwise it returns http.StatusOK/http.StatusInternalServerError depending
 // on the err value.
@@ -515,6 +893,38 @@ func StatusCode(err error) int {
 	return coder.StatusCode()
 }
 
+const (
+	AuthStatusOK      = "OK"
+	AuthStatusExpired = "expired"
+	AuthStatusRevoked = "revoked"
+	AuthStatusFailed  = "failed"
+)
+
+// AuthStatus classifies the error returned by an authenticated Jira call as
+// OK, an expired token, a revoked authorization, or some other failure.
+func AuthStatus(err error) string {
+	if err == nil {
+		return AuthStatusOK
+	}
+
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		// The refresh token was rejected, so the user's grant is gone.
+		if retrieveErr.ErrorCode == "invalid_grant" || retrieveErr.ErrorCode == "unauthorized_client" {
+			return AuthStatusRevoked
+		}
+		return AuthStatusExpired
+	}
+
+	switch StatusCode(err) {
+	case http.StatusUnauthorized:
+		return AuthStatusExpired
+	case http.StatusForbidden:
+		return AuthStatusRevoked
+	}
+	return AuthStatusFailed
+}
+
 func userFriendlyJiraError(resp *jira.Response, err error) error {
 	jerr, ok := err.(*jira.Error)
 	if !ok {
@@ -531,16 +941,9 @@ func userFriendlyJiraError(resp *jira.Response, err error) error {
 		return RESTError{err, resp.StatusCode}
 	}
 
-	message := ""
-	for k, v := range jerr.Errors {
-		message += fmt.Sprintf(" - %s: %s\n", k, v)
-	}
-	for _, m := range jerr.ErrorMessages {
-		message += fmt.Sprintf(" - %s\n", m)
-	}
-
+	envelope := &jiraErrorEnvelope{Errors: jerr.Errors, ErrorMessages: jerr.ErrorMessages}
 	if resp != nil {
-		return RESTError{errors.New(message), resp.StatusCode}
+		return RESTError{envelope, resp.StatusCode}
 	}
-	return RESTError{errors.New(message), 0}
+	return RESTError{envelope, 0}
 }
@@ -6,6 +6,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strconv"
 
 	jira "github.com/andygrunwald/go-jira"
@@ -75,6 +76,18 @@ func (client jiraCloudClient) GetUserGroups(connection *Connection) ([]*jira.Use
 	return groups, nil
 }
 
+// AddWatcher adds the user of the connection, by account ID, to the watchers
+// of an issue.
+func (client jiraCloudClient) AddWatcher(issueKey string, connection *Connection) error {
+	return client.updateWatchers(http.MethodPost, issueKey, connection.AccountID, nil)
+}
+
+// RemoveWatcher removes the user of the connection, by account ID, from the
+// watchers of an issue.
+func (client jiraCloudClient) RemoveWatcher(issueKey string, connection *Connection) error {
+	return client.updateWatchers(http.MethodDelete, issueKey, nil, map[string]string{"accountId": connection.AccountID})
+}
+
 func (client jiraCloudClient) ListProjects(query string, limit int, expandIssueTypes bool) (jira.ProjectList, error) {
 	type searchResult struct {
 		Values     jira.ProjectList `json:"values"`
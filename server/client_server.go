@@ -6,6 +6,7 @@ package main
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	jira "github.com/andygrunwald/go-jira"
 	"github.com/blang/semver/v4"
@@ -36,11 +37,35 @@ func newServerClient(jiraClient *jira.Client) Client {
 	}
 }
 
+// createMetaPageSize is the number of issue types, or fields, requested per
+// page of the Jira 9+ createmeta endpoints.
+const createMetaPageSize = 50
+
+// createMetaPage holds the pagination of the Jira 9+ createmeta endpoints.
+type createMetaPage struct {
+	StartAt    int  `json:"startAt"`
+	MaxResults int  `json:"maxResults"`
+	Total      int  `json:"total"`
+	IsLast     bool `json:"isLast"`
+}
+
+// next returns where the following page starts, and false if this page, of
+// count values starting at startAt, is the last one.
+func (page createMetaPage) next(startAt, count int) (int, bool) {
+	next := startAt + count
+	if page.IsLast || count == 0 || (page.Total > 0 && next >= page.Total) {
+		return next, false
+	}
+	return next, true
+}
+
 type ProjectIssueInfo struct {
+	createMetaPage
 	Values []*jira.MetaIssueType `json:"values"`
 }
 
 type FieldInfo struct {
+	createMetaPage
 	Values []map[string]interface{} `json:"values"`
 }
 
@@ -48,64 +73,92 @@ type JiraServerVersionInfo struct {
 	Version string `json:"version"`
 }
 
-// GetIssueInfo returns the issues information based on project id.
-func (client jiraServerClient) GetIssueInfo(projectID string) (*ProjectIssueInfo, *jira.Response, error) {
-	apiEndpoint := fmt.Sprintf("%s%s/issuetypes", APIEndpointCreateIssueMeta, projectID)
-	req, err := client.Jira.NewRequest(http.MethodGet, apiEndpoint, nil)
+func (client jiraServerClient) getCreateMetaPage(apiEndpoint string, startAt int, out interface{}) (*jira.Response, error) {
+	req, err := client.Jira.NewRequest(http.MethodGet, fmt.Sprintf("%s?startAt=%d&maxResults=%d", apiEndpoint, startAt, createMetaPageSize), nil)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-
-	issues := ProjectIssueInfo{}
-	response, err := client.Jira.Do(req, &issues)
-	return &issues, response, err
+	return client.Jira.Do(req, out)
 }
 
-func (client jiraServerClient) GetCreateMetaInfoForPivotJiraVersion(api plugin.API, options *jira.GetQueryOptions) (*jira.CreateMetaInfo, *jira.Response, error) {
-	projectID := options.ProjectKeys
-	proj, resp, err := client.Jira.Project.Get(projectID)
-	if err != nil {
-		return nil, resp, errors.Wrap(err, "failed to get project for CreateMetaInfo")
+// GetIssueInfo returns all the issue types of a project, page by page.
+func (client jiraServerClient) GetIssueInfo(projectID string) (*ProjectIssueInfo, *jira.Response, error) {
+	apiEndpoint := fmt.Sprintf("%s%s/issuetypes", APIEndpointCreateIssueMeta, projectID)
+	issues := &ProjectIssueInfo{}
+	for startAt, more := 0, true; more; {
+		page := ProjectIssueInfo{}
+		resp, err := client.getCreateMetaPage(apiEndpoint, startAt, &page)
+		if err != nil {
+			return nil, resp, err
+		}
+		issues.Values = append(issues.Values, page.Values...)
+		startAt, more = page.next(startAt, len(page.Values))
 	}
+	return issues, nil, nil
+}
 
-	issueInfo, resp, err := client.GetIssueInfo(proj.ID)
-	if err != nil {
-		return nil, resp, errors.Wrap(err, "failed to get create meta info")
+// GetIssueTypeFields returns all the fields of an issue type of a project,
+// keyed by field ID, page by page.
+func (client jiraServerClient) GetIssueTypeFields(projectID, issueTypeID string) (map[string]interface{}, *jira.Response, error) {
+	apiEndpoint := fmt.Sprintf("%s%s/issuetypes/%s", APIEndpointCreateIssueMeta, projectID, issueTypeID)
+	fields := make(map[string]interface{})
+	for startAt, more := 0, true; more; {
+		page := FieldInfo{}
+		resp, err := client.getCreateMetaPage(apiEndpoint, startAt, &page)
+		if err != nil {
+			return nil, resp, err
+		}
+		for _, fieldValue := range page.Values {
+			fields[fmt.Sprintf("%v", fieldValue["fieldId"])] = fieldValue
+		}
+		startAt, more = page.next(startAt, len(page.Values))
 	}
+	return fields, nil, nil
+}
 
-	for _, issueType := range issueInfo.Values {
-		apiEndpoint := fmt.Sprintf("%s%s/issuetypes/%s", APIEndpointCreateIssueMeta, proj.ID, issueType.Id)
-		req, rErr := client.Jira.NewRequest(http.MethodGet, apiEndpoint, nil)
-		if rErr != nil {
-			api.LogDebug("Failed to get the issue type.", "IssueType", issueType, "Error", rErr.Error())
+// GetCreateMetaInfoForPivotJiraVersion builds the createmeta of Jira 9+, that
+// no longer serves it in one call, from the per project endpoints. Only the
+// requested projects are fetched, and the fields of their issue types only
+// when they are expanded.
+func (client jiraServerClient) GetCreateMetaInfoForPivotJiraVersion(api plugin.API, options *jira.GetQueryOptions) (*jira.CreateMetaInfo, *jira.Response, error) {
+	meta := new(jira.CreateMetaInfo)
+	for _, projectKey := range strings.Split(options.ProjectKeys, ",") {
+		projectKey = strings.TrimSpace(projectKey)
+		if projectKey == "" {
 			continue
 		}
 
-		fieldInfo := FieldInfo{}
-		resp, err = client.Jira.Do(req, &fieldInfo)
+		proj, resp, err := client.Jira.Project.Get(projectKey)
 		if err != nil {
-			api.LogDebug("Failed to get the response for field info.", "Error", err.Error())
-			continue
+			return nil, resp, errors.Wrap(err, "failed to get project for CreateMetaInfo")
 		}
 
-		fieldMap := make(map[string]interface{})
-		for _, fieldValue := range fieldInfo.Values {
-			fieldMap[fmt.Sprintf("%v", fieldValue["fieldId"])] = fieldValue
+		issueInfo, resp, err := client.GetIssueInfo(proj.ID)
+		if err != nil {
+			return nil, resp, errors.Wrap(err, "failed to get create meta info")
 		}
-		issueType.Fields = fieldMap
-	}
-	project := &jira.MetaProject{
-		Expand:     proj.Expand,
-		Self:       proj.Self,
-		Id:         proj.ID,
-		Key:        proj.Key,
-		Name:       proj.Name,
-		IssueTypes: issueInfo.Values,
-	}
 
-	meta := new(jira.CreateMetaInfo)
-	meta.Projects = append(meta.Projects, project)
-	return meta, resp, err
+		if strings.Contains(options.Expand, "fields") {
+			for _, issueType := range issueInfo.Values {
+				fields, _, fErr := client.GetIssueTypeFields(proj.ID, issueType.Id)
+				if fErr != nil {
+					api.LogDebug("Failed to get the response for field info.", "IssueType", issueType.Id, "Error", fErr.Error())
+					continue
+				}
+				issueType.Fields = fields
+			}
+		}
+
+		meta.Projects = append(meta.Projects, &jira.MetaProject{
+			Expand:     proj.Expand,
+			Self:       proj.Self,
+			Id:         proj.ID,
+			Key:        proj.Key,
+			Name:       proj.Name,
+			IssueTypes: issueInfo.Values,
+		})
+	}
+	return meta, nil, nil
 }
 
 func (client jiraServerClient) GetCreateMetaInfoForSpecificJiraVersion(api plugin.API, currentVersion, pivotVersion semver.Version, options *jira.GetQueryOptions) (*jira.CreateMetaInfo, *jira.Response, error) {
@@ -176,6 +229,18 @@ func (client jiraServerClient) GetUserGroups(connection *Connection) ([]*jira.Us
 	return result.Groups.Items, nil
 }
 
+// AddWatcher adds the user of the connection, by username, to the watchers of
+// an issue.
+func (client jiraServerClient) AddWatcher(issueKey string, connection *Connection) error {
+	return client.updateWatchers(http.MethodPost, issueKey, connection.Name, nil)
+}
+
+// RemoveWatcher removes the user of the connection, by username, from the
+// watchers of an issue.
+func (client jiraServerClient) RemoveWatcher(issueKey string, connection *Connection) error {
+	return client.updateWatchers(http.MethodDelete, issueKey, nil, map[string]string{"username": connection.Name})
+}
+
 func (client jiraServerClient) ListProjects(query string, limit int, expandIssueTypes bool) (jira.ProjectList, error) {
 	queryOptions := &jira.GetQueryOptions{}
 	if expandIssueTypes {
@@ -0,0 +1,126 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newJira9Server serves the projects, and the paginated createmeta endpoints,
+// of a Jira 9 server. Every issue type has fieldsPerIssueType fields.
+func newJira9Server(issueTypes map[string][]string, fieldsPerIssueType int, calls *int32) *httptest.Server {
+	page := func(w http.ResponseWriter, r *http.Request, values []interface{}) {
+		atomic.AddInt32(calls, 1)
+		startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+		maxResults, _ := strconv.Atoi(r.URL.Query().Get("maxResults"))
+		end := startAt + maxResults
+		if end > len(values) {
+			end = len(values)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"startAt":    startAt,
+			"maxResults": maxResults,
+			"total":      len(values),
+			"isLast":     end == len(values),
+			"values":     values[startAt:end],
+		})
+	}
+
+	mux := http.NewServeMux()
+	for projectKey, names := range issueTypes {
+		projectID := "id-" + projectKey
+		mux.HandleFunc("/rest/api/2/project/"+projectKey, func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(jira.Project{ID: projectID, Key: projectKey, Name: projectKey})
+		})
+		mux.HandleFunc("/rest/api/2/issue/createmeta/"+projectID+"/issuetypes", func(w http.ResponseWriter, r *http.Request) {
+			values := []interface{}{}
+			for _, name := range names {
+				values = append(values, jira.MetaIssueType{Id: projectKey + "-" + name, Name: name})
+			}
+			page(w, r, values)
+		})
+		for _, name := range names {
+			mux.HandleFunc("/rest/api/2/issue/createmeta/"+projectID+"/issuetypes/"+projectKey+"-"+name, func(w http.ResponseWriter, r *http.Request) {
+				values := []interface{}{}
+				for i := 0; i < fieldsPerIssueType; i++ {
+					values = append(values, map[string]interface{}{"fieldId": fmt.Sprintf("customfield_%d", i), "name": fmt.Sprintf("Field %d", i)})
+				}
+				page(w, r, values)
+			})
+		}
+	}
+	return httptest.NewServer(mux)
+}
+
+func TestGetCreateMetaInfoForPivotJiraVersion(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("LogDebug", mock.AnythingOfType("string"), mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+
+	manyTypes := []string{}
+	for i := 0; i < createMetaPageSize+5; i++ {
+		manyTypes = append(manyTypes, fmt.Sprintf("Type%d", i))
+	}
+
+	var calls int32
+	ts := newJira9Server(map[string][]string{
+		"BIG":   manyTypes,
+		"SMALL": {"Task", "Bug"},
+	}, createMetaPageSize*2+1, &calls)
+	defer ts.Close()
+
+	jiraClient, err := jira.NewClient(nil, ts.URL)
+	require.NoError(t, err)
+	client := newServerClient(jiraClient).(*jiraServerClient)
+
+	t.Run("all the pages of issue types and fields are fetched", func(t *testing.T) {
+		meta, _, err := client.GetCreateMetaInfoForPivotJiraVersion(api, &jira.GetQueryOptions{
+			Expand:      "projects.issuetypes.fields",
+			ProjectKeys: "SMALL",
+		})
+		require.NoError(t, err)
+		require.Len(t, meta.Projects, 1)
+		project := meta.Projects[0]
+		assert.Equal(t, "SMALL", project.Key)
+		require.Len(t, project.IssueTypes, 2)
+		assert.Len(t, project.IssueTypes[0].Fields, createMetaPageSize*2+1)
+		assert.Contains(t, project.IssueTypes[1].Fields, fmt.Sprintf("customfield_%d", createMetaPageSize*2))
+	})
+
+	t.Run("several projects, without the fields", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		meta, _, err := client.GetCreateMetaInfoForPivotJiraVersion(api, &jira.GetQueryOptions{
+			ProjectKeys: "BIG, SMALL",
+		})
+		require.NoError(t, err)
+		require.Len(t, meta.Projects, 2)
+		assert.Equal(t, "BIG", meta.Projects[0].Key)
+		assert.Len(t, meta.Projects[0].IssueTypes, createMetaPageSize+5)
+		assert.Nil(t, meta.Projects[0].IssueTypes[0].Fields)
+		assert.Equal(t, "SMALL", meta.Projects[1].Key)
+
+		// Two pages of issue types for BIG, and one for SMALL.
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("unknown project", func(t *testing.T) {
+		_, resp, err := client.GetCreateMetaInfoForPivotJiraVersion(api, &jira.GetQueryOptions{
+			ProjectKeys: "NOPE",
+		})
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -5,9 +5,12 @@ package main
 
 import (
 	"net/http"
+	"net/url"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
 )
 
 func TestEndpointNameFromRequest(t *testing.T) {
@@ -41,3 +44,33 @@ func TestEndpointNameFromRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthStatus(t *testing.T) {
+	tokenErr := func(code string) error {
+		return errors.Wrap(&url.Error{
+			Op:  "Get",
+			URL: "https://api.atlassian.com/ex/jira/id/rest/api/2/myself",
+			Err: &oauth2.RetrieveError{ErrorCode: code},
+		}, "error in getting token from token source")
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"no error", nil, AuthStatusOK},
+		{"unauthorized", RESTError{errors.New("unauthorized"), http.StatusUnauthorized}, AuthStatusExpired},
+		{"forbidden", RESTError{errors.New("forbidden"), http.StatusForbidden}, AuthStatusRevoked},
+		{"refresh token rejected", tokenErr("invalid_grant"), AuthStatusRevoked},
+		{"refresh token rejected inside a REST error", RESTError{tokenErr("invalid_grant"), http.StatusInternalServerError}, AuthStatusRevoked},
+		{"other token error", tokenErr("temporarily_unavailable"), AuthStatusExpired},
+		{"server error", RESTError{errors.New("boom"), http.StatusInternalServerError}, AuthStatusFailed},
+		{"plain error", errors.New("network down"), AuthStatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, AuthStatus(tt.err))
+		})
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	jira "github.com/andygrunwald/go-jira"
 	"github.com/pkg/errors"
@@ -28,15 +30,38 @@ const commandTrigger = "jira"
 var jiraCommandHandler = CommandHandler{
 	handlers: map[string]CommandHandlerFunc{
 		"assign":                       executeAssign,
+		"assign/next":                  executeAssignNext,
+		"assign/stats":                 executeAssignStats,
+		"attach":                       executeAttach,
+		"channel/read-only":            executeChannelReadOnly,
+		"channel/create-reply":         executeChannelCreateReply,
+		"channel/default-issue-type":   executeChannelDefaultIssueType,
+		"channel/dev-info":             executeChannelDevInfo,
 		"connect":                      executeConnect,
+		"connect/all":                  executeConnectAll,
+		"connect/status":               executeConnectStatus,
 		"disconnect":                   executeDisconnect,
 		"help":                         executeHelp,
 		"me":                           executeMe,
 		"about":                        executeAbout,
+		"version":                      executeVersion,
+		"ping":                         executePing,
+		"admin/reconnect-reminder":     executeAdminReconnectReminder,
+		"admin/purge-orphans":          executeAdminPurgeOrphans,
+		"admin/auto-connect":           executeAdminAutoConnect,
+		"admin/set-bot-icon":           executeAdminSetBotIcon,
+		"admin/broadcast":              executeAdminBroadcast,
+		"admin/export-metrics":         executeAdminExportMetrics,
 		"install/cloud":                executeInstanceInstallCloud,
 		"install/cloud-oauth":          executeInstanceInstallCloudOAuth,
 		"install/server":               executeInstanceInstallServer,
 		"instance/alias":               executeInstanceAlias,
+		"instance/ca":                  executeInstanceCA,
+		"instance/headers":             executeInstanceHeaders,
+		"instance/health-summary":      executeInstanceHealthSummary,
+		"instance/set-auth-timeout":    executeInstanceSetAuthTimeout,
+		"instance/set-jql-default":     executeInstanceSetJQLDefault,
+		"instance/set-issue-type":      executeInstanceSetIssueType,
 		"instance/unalias":             executeInstanceUnalias,
 		"instance/connect":             executeConnect,
 		"instance/disconnect":          executeDisconnect,
@@ -44,44 +69,151 @@ var jiraCommandHandler = CommandHandler{
 		"instance/install/cloud-oauth": executeInstanceInstallCloudOAuth,
 		"instance/install/server":      executeInstanceInstallServer,
 		"instance/list":                executeInstanceList,
-		"instance/settings":            executeSettings,
+		"instance/settings":            executeInstanceSettings,
+		"instance/test-webhook":        executeInstanceTestWebhook,
 		"instance/uninstall":           executeInstanceUninstall,
 		"instance/v2":                  executeInstanceV2Legacy,
 		"instance/default":             executeDefaultInstance,
+		"issue-type-fields":            executeIssueTypeFields,
+		"issue-types":                  executeIssueTypes,
 		"issue/assign":                 executeAssign,
+		"issue/assign/next":            executeAssignNext,
+		"issue/assign/stats":           executeAssignStats,
+		"issue/attach":                 executeAttach,
 		"issue/transition":             executeTransition,
+		"issue/reopen":                 executeReopen,
 		"issue/unassign":               executeUnassign,
 		"issue/view":                   executeView,
+		"issue/watch":                  executeWatch,
+		"issue/unwatch":                executeUnwatch,
+		"issue/worklog":                executeWorklog,
+		"issue/describe":               executeDescribe,
 		"settings":                     executeSettings,
+		"search":                       executeSearch,
+		"mine":                         executeMine,
 		"subscribe/list":               executeSubscribeList,
+		"subscribe/stats":              executeSubscribeStats,
+		"subscribe/preview":            executeSubscribePreview,
+		"subscribe/dry-run":            executeSubscribeDryRun,
+		"subscribe/who":                executeSubscribeWho,
+		"subscribe/auto":               executeSubscribeAuto,
+		"subscribe/import-from-filter": executeSubscribeImportFromFilter,
+		"subscribe/resync":             executeSubscribeResync,
+		"subscribe/since":              executeSubscribeSince,
+		"subscribe/exclude-self":       executeSubscribeExcludeSelf,
+		"subscribe/on-create-only-new": executeSubscribeOnCreateOnlyNew,
+		"subscribe/webhook":            executeSubscribeWebhook,
+		"subscribe/quiet":              executeSubscribeQuiet,
+		"comment":                      executeComment,
+		"comment/delete":               executeCommentDelete,
+		"comment/list":                 executeCommentList,
+		"board":                        executeBoard,
+		"epic":                         executeEpic,
+		"issue/comment":                executeComment,
+		"issue/comment/delete":         executeCommentDelete,
+		"issue/comment/list":           executeCommentList,
 		"transition":                   executeTransition,
+		"reopen":                       executeReopen,
 		"unassign":                     executeUnassign,
 		"uninstall":                    executeInstanceUninstall,
 		"view":                         executeView,
+		"watch":                        executeWatch,
+		"unwatch":                      executeUnwatch,
+		"worklog":                      executeWorklog,
+		"describe":                     executeDescribe,
 		"v2revert":                     executeV2Revert,
 		"webhook":                      executeWebhookURL,
 		"setup":                        executeSetup,
 	},
 	defaultHandler: executeJiraDefault,
+	writeHandlers: map[string]bool{
+		"assign":               true,
+		"assign/next":          true,
+		"attach":               true,
+		"comment":              true,
+		"comment/delete":       true,
+		"issue/assign":         true,
+		"issue/assign/next":    true,
+		"issue/attach":         true,
+		"issue/comment":        true,
+		"issue/comment/delete": true,
+		"issue/reopen":         true,
+		"issue/transition":     true,
+		"issue/unassign":       true,
+		"issue/unwatch":        true,
+		"issue/watch":          true,
+		"issue/worklog":        true,
+		"reopen":               true,
+		"transition":           true,
+		"unassign":             true,
+		"unwatch":              true,
+		"watch":                true,
+		"worklog":              true,
+	},
 }
 
 const helpTextHeader = "###### Mattermost Jira Plugin - Slash Command Help\n"
 
 const commonHelpText = "\n" +
 	"* `/jira connect [jiraURL]` - Connect your Mattermost account to your Jira account\n" +
-	"* `/jira disconnect [jiraURL]` - Disconnect your Mattermost account from your Jira account\n" +
-	"* `/jira [issue] assign [issue-key] [assignee]` - Change the assignee of a Jira issue\n" +
+	"* `/jira connect all` - Link your Jira accounts on all the installed Jira instances you are not connected to yet, one after the other\n" +
+	"* `/jira connect status` - Check that your Jira connections are still working\n" +
+	"* `/jira disconnect [jiraURL] [--confirm]` - Disconnect your Mattermost account from your Jira account; with several connections and no jiraURL, they are listed to pick from\n" +
+	"* `/jira [issue] assign [issue-key] [assignee]` - Change the assignee of a Jira issue; [assignee] can be `me`, a @mention of a connected user, or a Jira username, account ID or name to search for\n" +
+	"* `/jira [issue] assign next` - Assign to yourself the next unassigned issue of the default query of the instance, in its order or else by priority and age\n" +
+	"* `/jira [issue] assign stats` - Count the issues of the default query of the instance by assignee\n" +
+	"* `/jira [issue] attach [issue-key]` - Attach the files of the thread's root message to a Jira issue; run it as a reply in that thread\n" +
+	"* `/jira board [board]` - Show the columns of a Kanban board, with their issue counts, top issues and WIP limits\n" +
+	"* `/jira epic [epic-key]` - List the child issues of an epic by status, with its progress\n" +
+	"* `/jira [issue] comment [issue-key] [--internal] [text]` - Add a comment to a Jira issue; with `--internal`, a comment of a Jira Service Management issue that only the agents see\n" +
+	"* `/jira [issue] comment delete [issue-key] [comment-id]` - Delete a comment that you attached to a Jira issue from Mattermost\n" +
+	"* `/jira [issue] comment list [issue-key] [--page number]` - Show the latest comments of a Jira issue, or older ones with `--page`\n" +
 	"* `/jira [issue] create [text]` - Create a new Issue with 'text' inserted into the description field\n" +
+	"* `/jira [issue] create --parent [issue-key] [text]` - Create a new sub-task of the issue 'issue-key'\n" +
+	"* `/jira [issue] create --assignee @user [text]` - Create a new Issue assigned to the Jira account of a Mattermost user\n" +
+	"* `/jira [issue] create --sprint [current|sprint-id|\"sprint name\"] [text]` - Create a new Issue in the active sprint, or in an open sprint, of the boards of its project\n" +
+	"* `/jira [issue] create --reporter [@user|jira-username|email] [text]` - Create a new Issue on behalf of someone else, with the Modify Reporter permission in its project\n" +
+	"* `/jira [issue] create --from [issue-key] [text]` - Create a new Issue pre-filled with the summary, description, labels, components and custom fields of an existing issue\n" +
 	"* `/jira [issue] transition [issue-key] [state]` - Change the state of a Jira issue\n" +
+	"* `/jira [issue] transition [issue-key]` - List the transitions of a Jira issue, numbered, to apply one with `/jira transition [issue-key] #N`\n" +
+	"* `/jira [issue] reopen [issue-key]` - Move a done issue back to an open state, using its workflow's reopen transition\n" +
+	"* `/jira issue-types [project-key]` - List the issue types that you can create in a project\n" +
+	"* `/jira issue-type-fields [project-key] [issue-type] [--page=N]` - List the fields, with their IDs and allowed values, of an issue type of a project\n" +
 	"* `/jira [issue] unassign [issue-key]` - Unassign the Jira issue\n" +
+	"* `/jira [issue] watch [issue-key]` - Watch a Jira issue, to be notified of its updates by Jira\n" +
+	"* `/jira [issue] unwatch [issue-key]` - Stop watching a Jira issue\n" +
+	"* `/jira [issue] worklog [issue-key] [duration] [comment]` - Log the time you spent on a Jira issue, e.g. `/jira worklog KT-12 1h 30m Reviewed the fix`\n" +
 	"* `/jira [issue] view [issue-key]` - View the details of a specific Jira issue\n" +
+	"* `/jira [issue] describe [issue-key]` - Share the details of a specific Jira issue with the channel\n" +
+	"* `/jira mine [project-key] [--all]` - List the open issues assigned to you, of a project if specified, grouped by status; with `--all` the done ones too\n" +
+	"* `/jira search [--format cards|table] [--sort field] [jql]` - Search the issues matching a JQL query, shown as cards or as a table; `--sort` orders the results shown by key, summary, status, assignee or priority. Without a query, the default query of the instance, if an admin set one, is run. The results have buttons to the next and previous pages\n" +
 	"* `/jira help` - Launch the Jira plugin command line help syntax\n" +
 	"* `/jira me` - Display information about the current user\n" +
 	"* `/jira about` - Display build info\n" +
+	"* `/jira ping` - Check that the plugin is running, without contacting Jira\n" +
+	"* `/jira version` - Display the plugin version, and the version of each Jira instance\n" +
 	"* `/jira instance list` - List installed Jira instances\n" +
-	"* `/jira instance settings [setting] [value]` - Update your user settings\n" +
-	"  * [setting] can be `notifications`\n" +
-	"  * [value] can be `on` or `off`\n" +
+	"* `/jira channel read-only [on|off]` - Disable or enable Jira writes (create, assign, transition, comment, worklog, watch) in this channel; channel and system administrators only\n" +
+	"* `/jira channel create-reply [default|ephemeral|public]` - Show the issues created in this channel only to their creator, post them to the channel as a card, or, by default, both show them to their creator and announce them with a link; channel and system administrators only\n" +
+	"* `/jira channel default-issue-type [issue type|none]` - Pick an issue type in `/jira create` in this channel, in the projects that have it, over that of the Jira instance; channel and system administrators only\n" +
+	"* `/jira channel dev-info [on|off]` - Show the branches, commits and pull requests of the Jira Cloud issues shown in this channel by `/jira view` and by the subscriptions; channel and system administrators only\n" +
+	"* `/jira settings notifications [on|off|assigned]` - Update your notifications settings for all the instances that do not override them\n" +
+	"* `/jira instance settings [setting] [value]` - Update your user settings for an instance\n" +
+	"  * [setting] can be `notifications`, `ignore-own-actions`, `compact`, `notify-dm-on-subscribe-match`, `mention-only` or `daily-summary`\n" +
+	"  * [value] can be `on` or `off`, and `assigned` for `notifications` to only be notified about the issues assigned to you, or `inherit` to follow your global notifications settings\n" +
+	"* `/jira settings compact [on|off]` - Receive your notifications as a single line, with a link to the issue\n" +
+	"* `/jira settings notify-dm-on-subscribe-match [on|off]` - Get a DM when a subscription of a channel you are in posts about an issue assigned to or reported by you\n" +
+	"* `/jira settings mention-only [on|off]` - Only get the notifications of comments and descriptions that mention you in Jira\n" +
+	"* `/jira settings daily-summary [HH:MM|off]` - Get a DM every day at a time of your timezone with the open issues assigned to you\n" +
+	"* `/jira settings custom-status [on|off|set|unset|clear]` - Set your Mattermost custom status from the status or the labels of the issues assigned to you, e.g. `set label:incident :rotating_light: On incident`\n" +
+	"* `/jira settings notification-footer [on|off]` - Show or hide the hints added by your administrators to your notifications\n" +
+	"* `/jira settings notify-status-entry [add|remove|list|clear] [status] [--project key]` - Get a DM when an issue you can see moves into a status, e.g. `add Ready for QA --project QA`\n" +
+	"* `/jira settings notify-priority-threshold [priority|off] [--skip-unprioritized]` - Only get the notifications of the issues of a priority or above, e.g. `High`; the issues without a priority are notified unless `--skip-unprioritized` is given\n" +
+	"* `/jira settings notify-channel-on-mention [on|off] [@username...]` - Post the Jira events mentioning the connected members of this channel, or the named members, to this channel, e.g. a shared triage channel; users allowed to manage its subscriptions only\n" +
+	"* `/jira settings reset` - Restore all your settings to their defaults, for all your Jira instances. Use `/jira instance settings reset` to only reset those of an instance\n" +
+	"* `/jira settings export [--instance jiraURL]` - Show your settings for an instance as JSON, to copy them to another one\n" +
+	"* `/jira settings import [--instance jiraURL] [settings]` - Replace your settings for an instance with those exported from another one\n" +
+	"* `/jira settings notifications quiet-hours [start] [end] [--queue]` - Mute your notifications every day between two times (HH:MM, in your timezone); with `--queue` they are delivered afterwards. Use `quiet-hours off` to turn it off\n" +
 	""
 
 const sysAdminHelpText = "\n###### For System Administrators:\n" +
@@ -97,11 +229,36 @@ const sysAdminHelpText = "\n###### For System Administrators:\n" +
 	"Manage channel subscriptions:\n" +
 	"* `/jira subscribe ` - Configure the Jira notifications sent to this channel\n" +
 	"* `/jira subscribe list` - Display all the the subscription rules setup across all the channels and teams on your Mattermost instance\n" +
+	"* `/jira subscribe preview [JQL]` - Show how many issues currently match a JQL query, as a check before subscribing to it\n" +
+	"* `/jira subscribe dry-run [issue-key] [JQL]` - Explain whether an issue matches a JQL query, and which of its clauses the issue satisfies\n" +
+	"* `/jira subscribe who [issue-key]` - List the subscriptions that an update of the issue would notify, and why the others would not\n" +
+	"* `/jira subscribe stats` - Rank the subscriptions in this channel by the number of posts they made in the last 24 hours and 7 days\n" +
+	"* `/jira subscribe auto [project-key]` - Subscribe this channel to the new and updated issues of a project, by default the one whose key is in the channel header or purpose\n" +
+	"* `/jira subscribe import-from-filter [filter-id] [--pin]` - Subscribe this channel to the new and updated issues matching the JQL of a saved Jira filter; with `--pin` the subscription can be resynced with the filter\n" +
+	"* `/jira subscribe resync [subscription]` - Update a subscription pinned to a Jira filter with the current JQL of the filter\n" +
+	"* `/jira subscribe since [duration] [subscription]` - Post once the current state of the issues matching the subscriptions of this channel, or one of them, that were updated in the last duration, e.g. `24h`\n" +
+	"* `/jira subscribe exclude-self [on|off] [subscription]` - Skip, or post again, the events of a subscription that were triggered by service accounts or by changes made with the plugin\n" +
+	"* `/jira subscribe on-create-only-new [on|off] [subscription]` - Post the creation of an issue once per subscription, not again when it is created anew, e.g. by the automation of a reopened issue\n" +
+	"* `/jira subscribe webhook [regenerate|revoke] [subscription]` - Show, replace or remove the webhook URL whose events are only delivered to a subscription of this channel\n" +
+	"* `/jira subscribe quiet [add|remove|list|clear|summary]` - Silence all the subscriptions of this channel during recurring windows, e.g. `add mon-fri 14:00-15:00 Europe/Paris`; with `summary on` the number of updates suppressed is posted when a window ends\n" +
 	"Other:\n" +
+	"* `/jira admin reconnect-reminder` - Check all user connections, and send a reconnect reminder to the users whose connection expired or was revoked\n" +
+	"* `/jira admin purge-orphans [--confirm]` - List the connections, subscriptions and instance list entries of instances that no longer exist; with `--confirm` they are deleted\n" +
+	"* `/jira admin auto-connect [jiraURL] [--confirm]` - DM you the users who are not connected to a Jira Cloud instance installed as an Atlassian Connect app and the Jira accounts whose visible email address is their verified one; with `--confirm` they are connected, and the others get a DM to connect\n" +
+	"* `/jira admin set-bot-icon [image URL|post link|reset] [--name display name|reset]` - Change the avatar of the bot to a PNG or JPEG image, from a URL or attached to a post, and its display name, e.g. to tell apart the Jira plugins of several deployments\n" +
+	"* `/jira admin broadcast [--instance=jiraURL] [message]` - Send a markdown message as a DM to all the users connected to Jira, or to an instance\n" +
+	"* `/jira admin export-metrics` - Show as JSON the counters of the commands, Jira API calls, webhook events and notification errors since the plugin started on this server\n" +
 	"* `/jira instance alias [URL] [alias-name]` - assign an alias to an instance\n" +
+	"* `/jira instance ca [jiraURL] [PEM]` - Trust the PEM encoded CA certificates for a Jira Server or Data Center instance, e.g. one using an internal CA. Use `clear` instead of the PEM to remove them\n" +
+	"* `/jira instance headers [jiraURL] [set|clear] [name] [value]` - List, set or clear the static headers sent with all the requests to a Jira Server or Data Center instance, e.g. for an API gateway. Use `clear all` to remove them all. Their values are stored encrypted and never shown\n" +
+	"* `/jira instance health-summary` - Show the reachability and latency of the Jira instances, their connected users and subscriptions, and the notification error rate. It is also posted regularly to the channel set in the plugin settings\n" +
+	"* `/jira instance set-auth-timeout [jiraURL] [seconds]` - Time out the requests to a slow Jira instance after a number of seconds, up to 300. Use `default` instead of the seconds to remove it\n" +
+	"* `/jira instance set-jql-default [jiraURL] [JQL]` - Set the query that `/jira search` runs on an instance when it is given none, and that `/jira assign next` and `/jira assign stats` use. Use `none` instead of the query to remove it\n" +
+	"* `/jira instance set-issue-type [jiraURL] [issue type]` - Set the issue type that `/jira create` picks on an instance, in the projects that have it, unless the channel has its own. Use `none` instead of the issue type to remove it\n" +
 	"* `/jira instance unalias [alias-name]` - remve an alias from an instance\n" +
 	"* `/jira instance v2 <jiraURL>` - Set the Jira instance to process \"v2\" webhooks and subscriptions (not prefixed with the instance ID)\n" +
 	"* `/jira instance default <jiraURL>` - Set a default instance in case of multiple Jira instances\n" +
+	"* `/jira instance test-webhook [jiraURL] [issue-key]` - Send a test event to the subscriptions webhook, and list the subscriptions it matches, without posting anything\n" +
 	"* `/jira webhook [--instance=<jiraURL>]` -  Show the Mattermost webhook to receive JQL queries\n" +
 	"* `/jira v2revert ` - Revert to V2 jira plugin data model\n" +
 	""
@@ -151,12 +308,26 @@ func (p *Plugin) createJiraCommand(enableAutocomplete, enableOptInstance bool) (
 func addSubCommands(jira *model.AutocompleteData, optInstance bool) {
 	// Top-level common commands
 	jira.AddCommand(createViewCommand(optInstance))
+	jira.AddCommand(createSearchCommand(optInstance))
+	jira.AddCommand(createMineCommand(optInstance))
+	jira.AddCommand(createDescribeCommand(optInstance))
 	jira.AddCommand(createTransitionCommand(optInstance))
+	jira.AddCommand(createReopenCommand(optInstance))
 	jira.AddCommand(createAssignCommand(optInstance))
+	jira.AddCommand(createAttachCommand(optInstance))
+	jira.AddCommand(createCommentCommand(optInstance))
 	jira.AddCommand(createUnassignCommand(optInstance))
+	jira.AddCommand(createWorklogCommand(optInstance))
+	jira.AddCommand(createWatchCommand(optInstance, true))
+	jira.AddCommand(createWatchCommand(optInstance, false))
 	jira.AddCommand(createConnectCommand())
 	jira.AddCommand(createDisconnectCommand())
-	jira.AddCommand(createSettingsCommand(optInstance))
+	jira.AddCommand(createSettingsCommand(optInstance, false))
+	jira.AddCommand(createIssueTypesCommand(optInstance))
+	jira.AddCommand(createIssueTypeFieldsCommand(optInstance))
+	jira.AddCommand(createBoardCommand(optInstance))
+	jira.AddCommand(createEpicCommand(optInstance))
+	jira.AddCommand(createChannelCommand())
 
 	// Generic commands
 	jira.AddCommand(createIssueCommand(optInstance))
@@ -166,11 +337,14 @@ func addSubCommands(jira *model.AutocompleteData, optInstance bool) {
 	jira.AddCommand(createSubscribeCommand(optInstance))
 	jira.AddCommand(createWebhookCommand(optInstance))
 	jira.AddCommand(createSetupCommand())
+	jira.AddCommand(createAdminCommand())
 
 	// Help and info
 	jira.AddCommand(model.NewAutocompleteData("help", "", "Display help for `/jira` command"))
 	jira.AddCommand(model.NewAutocompleteData("me", "", "Display information about the current user"))
 	jira.AddCommand(command.BuildInfoAutocomplete("about"))
+	jira.AddCommand(model.NewAutocompleteData("ping", "", "Check that the plugin is running, without contacting Jira"))
+	jira.AddCommand(model.NewAutocompleteData("version", "", "Display the plugin version, and the version of each Jira instance"))
 }
 
 func createInstanceCommand(optInstance bool) *model.AutocompleteData {
@@ -179,7 +353,7 @@ func createInstanceCommand(optInstance bool) *model.AutocompleteData {
 	instance.AddCommand(createAliasCommand())
 	instance.AddCommand(createUnAliasCommand())
 	instance.AddCommand(createConnectCommand())
-	instance.AddCommand(createSettingsCommand(optInstance))
+	instance.AddCommand(createSettingsCommand(optInstance, true))
 	instance.AddCommand(createDisconnectCommand())
 	instance.AddCommand(createDefaultInstanceCommand())
 
@@ -204,22 +378,81 @@ func createInstanceCommand(optInstance bool) *model.AutocompleteData {
 		"list", "", "List installed Jira instances")
 	list.RoleID = model.SystemAdminRoleId
 
+	ca := model.NewAutocompleteData(
+		"ca", "[URL] [PEM|clear]", "Trust custom CA certificates for a Jira Server or Data Center instance")
+	ca.AddTextArgument("Jira URL", "Enter the Jira URL, e.g. https://jira.example.com", "")
+	ca.AddTextArgument("PEM encoded CA certificates, or clear", "Paste the PEM encoded CA certificates, or `clear` to remove them", "")
+	ca.RoleID = model.SystemAdminRoleId
+
+	headers := model.NewAutocompleteData(
+		"headers", "[URL] [set|clear] [name] [value]", "Send custom headers with the requests to a Jira Server or Data Center instance")
+	headers.AddTextArgument("Jira URL", "Enter the Jira URL, e.g. https://jira.example.com", "")
+	headers.AddStaticListArgument("Action, none to list the headers", false, []model.AutocompleteListItem{
+		{HelpText: "Send a header with all the requests", Item: headersSet},
+		{HelpText: "Stop sending a header, or all of them", Item: headersClear},
+	})
+	headers.AddTextArgument("Header name, or all to clear them all", "[name]", "")
+	headers.AddTextArgument("Header value", "[value]", "")
+	headers.RoleID = model.SystemAdminRoleId
+
+	setAuthTimeout := model.NewAutocompleteData(
+		"set-auth-timeout", "[URL] [seconds|default]", "Set the timeout of the requests to a slow Jira instance")
+	setAuthTimeout.AddDynamicListArgument("Jira instance", makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias), true)
+	setAuthTimeout.AddTextArgument("Number of seconds, or default", "[seconds|default]", "")
+	setAuthTimeout.RoleID = model.SystemAdminRoleId
+
+	setJQLDefault := model.NewAutocompleteData(
+		"set-jql-default", "[URL] [JQL|none]", "Set the query that /jira search runs when it is given none")
+	setJQLDefault.AddDynamicListArgument("Jira instance", makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias), true)
+	setJQLDefault.AddTextArgument("JQL query, or none", "[JQL|none]", "")
+	setJQLDefault.RoleID = model.SystemAdminRoleId
+
+	setIssueType := model.NewAutocompleteData(
+		"set-issue-type", "[URL] [issue type|none]", "Set the issue type that /jira create picks when none is given")
+	setIssueType.AddDynamicListArgument("Jira instance", makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias), true)
+	setIssueType.AddTextArgument("Issue type name, or none", "[issue type|none]", "")
+	setIssueType.RoleID = model.SystemAdminRoleId
+
 	instance.AddCommand(createConnectCommand())
 	instance.AddCommand(createDisconnectCommand())
 	instance.AddCommand(list)
-	instance.AddCommand(createSettingsCommand(optInstance))
+	instance.AddCommand(createSettingsCommand(optInstance, true))
 	instance.AddCommand(install)
 	instance.AddCommand(uninstall)
+	instance.AddCommand(ca)
+	instance.AddCommand(headers)
+	instance.AddCommand(setAuthTimeout)
+	instance.AddCommand(setJQLDefault)
+	instance.AddCommand(setIssueType)
+
+	healthSummary := model.NewAutocompleteData(
+		"health-summary", "", "Show the health of the Jira instances")
+	healthSummary.RoleID = model.SystemAdminRoleId
+	instance.AddCommand(healthSummary)
+
+	testWebhook := model.NewAutocompleteData(
+		"test-webhook", "[URL] [issue-key]", "Send a test event to the subscriptions webhook of a Jira instance")
+	testWebhook.AddDynamicListArgument("Jira instance", makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias), true)
+	testWebhook.AddTextArgument("Issue key, to test with a real issue", "[issue-key]", "")
+	testWebhook.RoleID = model.SystemAdminRoleId
+	instance.AddCommand(testWebhook)
 	return instance
 }
 
 func createIssueCommand(optInstance bool) *model.AutocompleteData {
 	issue := model.NewAutocompleteData(
-		"issue", "[view|assign|transition]", "View and manage Jira issues")
+		"issue", "[view|assign|attach|transition]", "View and manage Jira issues")
 	issue.AddCommand(createViewCommand(optInstance))
+	issue.AddCommand(createDescribeCommand(optInstance))
 	issue.AddCommand(createTransitionCommand(optInstance))
+	issue.AddCommand(createReopenCommand(optInstance))
 	issue.AddCommand(createAssignCommand(optInstance))
+	issue.AddCommand(createAttachCommand(optInstance))
+	issue.AddCommand(createCommentCommand(optInstance))
 	issue.AddCommand(createUnassignCommand(optInstance))
+	issue.AddCommand(createWorklogCommand(optInstance))
+	issue.AddCommand(createWatchCommand(optInstance, true))
+	issue.AddCommand(createWatchCommand(optInstance, false))
 	return issue
 }
 
@@ -258,8 +491,11 @@ func createUnAliasCommand() *model.AutocompleteData {
 
 func createDisconnectCommand() *model.AutocompleteData {
 	disconnect := model.NewAutocompleteData(
-		"disconnect", "[Jira URL]", "Disconnect your Mattermost account from your Jira account")
+		"disconnect", "[Jira URL] [--confirm]", "Disconnect your Mattermost account from your Jira account")
 	disconnect.AddDynamicListArgument("Jira URL", makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias), false)
+	disconnect.AddStaticListArgument("", false, []model.AutocompleteListItem{
+		{HelpText: "Disconnect without asking for confirmation", Item: "--confirm"},
+	})
 	return disconnect
 }
 
@@ -270,23 +506,146 @@ func createDefaultInstanceCommand() *model.AutocompleteData {
 	return defaultInstance
 }
 
-func createSettingsCommand(optInstance bool) *model.AutocompleteData {
+func createSettingsCommand(optInstance, instanceLevel bool) *model.AutocompleteData {
 	settings := model.NewAutocompleteData(
-		"settings", "[list|notifications]", "View or update your user settings")
+		"settings", "[list|notifications|ignore-own-actions|compact|notify-dm-on-subscribe-match|mention-only|daily-summary|notify-channel-on-mention|custom-status|notification-footer|notify-priority-threshold|notify-status-entry|reset|export|import]", "View or update your user settings")
 
 	list := model.NewAutocompleteData(
 		"list", "", "View your current settings")
 	settings.AddCommand(list)
 
-	notifications := model.NewAutocompleteData(
-		"notifications", "[on|off]", "Update your user notifications settings")
-	notifications.AddStaticListArgument("value", true, []model.AutocompleteListItem{
+	notificationValues := []model.AutocompleteListItem{
 		{HelpText: "Turn notifications on", Item: "on"},
 		{HelpText: "Turn notifications off", Item: "off"},
-	})
+		{HelpText: "Only notify me about the issues assigned to me", Item: settingAssigned},
+	}
+	notificationsHint, notificationsHelp := "[on|off|assigned]", "Update your user notifications settings"
+	if instanceLevel {
+		notificationValues = append(notificationValues, model.AutocompleteListItem{
+			HelpText: "Follow my global notifications settings", Item: settingInherit})
+		notificationsHint, notificationsHelp = "[on|off|assigned|inherit]", "Override your notifications settings for an instance"
+	}
+	notificationValues = append(notificationValues, model.AutocompleteListItem{
+		HelpText: "Mute notifications every day between two times, e.g. `quiet-hours 22:00 07:00 --queue`", Item: settingQuietHours})
+
+	notifications := model.NewAutocompleteData(
+		"notifications", notificationsHint, notificationsHelp)
+	notifications.AddStaticListArgument("value", true, notificationValues)
 	withFlagInstance(notifications, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
 	settings.AddCommand(notifications)
 
+	ignoreOwnActions := model.NewAutocompleteData(
+		"ignore-own-actions", "[on|off]", "Skip notifications for changes you made yourself")
+	ignoreOwnActions.AddStaticListArgument("value", true, []model.AutocompleteListItem{
+		{HelpText: "Don't notify me about my own actions", Item: "on"},
+		{HelpText: "Notify me about my own actions too", Item: "off"},
+	})
+	withFlagInstance(ignoreOwnActions, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	settings.AddCommand(ignoreOwnActions)
+
+	compact := model.NewAutocompleteData(
+		settingCompact, "[on|off]", "Receive your notifications as a single line")
+	compact.AddStaticListArgument("value", true, []model.AutocompleteListItem{
+		{HelpText: "One line per notification, with a link to the issue", Item: "on"},
+		{HelpText: "Full notifications", Item: "off"},
+	})
+	withFlagInstance(compact, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	settings.AddCommand(compact)
+
+	notifyOnSubscriptionMatch := model.NewAutocompleteData(
+		settingNotifyOnSubscriptionMatch, "[on|off]", "Get a DM when a subscription of your channels posts about your issues")
+	notifyOnSubscriptionMatch.AddStaticListArgument("value", true, []model.AutocompleteListItem{
+		{HelpText: "DM me when subscriptions post about the issues assigned to or reported by me", Item: "on"},
+		{HelpText: "Only the channel posts", Item: "off"},
+	})
+	withFlagInstance(notifyOnSubscriptionMatch, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	settings.AddCommand(notifyOnSubscriptionMatch)
+
+	mentionOnly := model.NewAutocompleteData(
+		settingMentionOnly, "[on|off]", "Only get notifications that mention you in Jira")
+	mentionOnly.AddStaticListArgument("value", true, []model.AutocompleteListItem{
+		{HelpText: "Only notify me when a comment or description mentions me", Item: "on"},
+		{HelpText: "All my notifications", Item: "off"},
+	})
+	withFlagInstance(mentionOnly, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	settings.AddCommand(mentionOnly)
+
+	dailySummary := model.NewAutocompleteData(
+		settingDailySummary, "[HH:MM|off]", "Get a DM with your open issues every day")
+	dailySummary.AddTextArgument("A time of the day in your timezone, e.g. 08:30, or off", "[HH:MM|off]", "")
+	withFlagInstance(dailySummary, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	settings.AddCommand(dailySummary)
+
+	notifyChannelOnMention := model.NewAutocompleteData(
+		settingNotifyChannelOnMention, "[on|off] [@username...]", "Post the Jira mentions of the members of this channel, or of some users, to this channel")
+	notifyChannelOnMention.AddStaticListArgument("value", false, []model.AutocompleteListItem{
+		{HelpText: "Post the events mentioning the connected members of this channel, or the users named after it", Item: "on"},
+		{HelpText: "Stop posting the mentions to this channel", Item: "off"},
+	})
+	withFlagInstance(notifyChannelOnMention, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	settings.AddCommand(notifyChannelOnMention)
+
+	customStatus := model.NewAutocompleteData(
+		settingCustomStatus, "[on|off|set|unset|clear]", "Set your Mattermost custom status from the issues assigned to you")
+	customStatus.AddStaticListArgument("value", false, []model.AutocompleteListItem{
+		{HelpText: "Set my custom status when I'm assigned an issue that is mapped to one", Item: settingOn},
+		{HelpText: "Stop setting my custom status", Item: settingOff},
+		{HelpText: "Map the issues with a status or a label to a custom status, e.g. `set label:incident :rotating_light: On incident`", Item: "set"},
+		{HelpText: "Remove one of my mappings, e.g. `unset label:incident`", Item: "unset"},
+		{HelpText: "Remove the custom status set from Jira", Item: "clear"},
+	})
+	withFlagInstance(customStatus, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	settings.AddCommand(customStatus)
+
+	notificationFooter := model.NewAutocompleteData(
+		settingNotificationFooter, "[on|off]", "Show or hide the hints added to your notifications")
+	notificationFooter.AddStaticListArgument("value", true, []model.AutocompleteListItem{
+		{HelpText: "Add the hints of the administrators to my notifications", Item: settingOn},
+		{HelpText: "Leave them out", Item: settingOff},
+	})
+	withFlagInstance(notificationFooter, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	settings.AddCommand(notificationFooter)
+
+	priorityThreshold := model.NewAutocompleteData(
+		settingNotifyPriorityThreshold, "[priority|off] [--skip-unprioritized]", "Only get the notifications of the issues of a priority or above")
+	priorityThreshold.AddTextArgument("The lowest priority notified, e.g. High, or off", "[priority|off]", "")
+	priorityThreshold.AddStaticListArgument("", false, []model.AutocompleteListItem{
+		{HelpText: "Also leave out the issues without a priority", Item: flagSkipUnprioritized},
+	})
+	withFlagInstance(priorityThreshold, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	settings.AddCommand(priorityThreshold)
+
+	statusEntry := model.NewAutocompleteData(
+		settingNotifyStatusEntry, "[add|remove|list|clear] [status] [--project key]", "Get a DM when an issue moves into a status")
+	statusEntry.AddStaticListArgument("action", true, []model.AutocompleteListItem{
+		{HelpText: "Get a DM when an issue moves into the status, e.g. `add Ready for QA --project QA`", Item: "add"},
+		{HelpText: "Remove a rule", Item: "remove"},
+		{HelpText: "List my rules", Item: "list"},
+		{HelpText: "Remove all my rules", Item: "clear"},
+	})
+	statusEntry.AddTextArgument("Status name, and optionally --project key", "[status] [--project key]", "")
+	withFlagInstance(statusEntry, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	settings.AddCommand(statusEntry)
+
+	resetHelp := "Restore all your settings to their defaults"
+	if instanceLevel {
+		resetHelp = "Restore your settings for an instance to their defaults"
+	}
+	reset := model.NewAutocompleteData(settingReset, "", resetHelp)
+	if instanceLevel {
+		withFlagInstance(reset, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	}
+	settings.AddCommand(reset)
+
+	export := model.NewAutocompleteData(settingExport, "", "Show your settings as JSON, to copy them to another instance")
+	withFlagInstance(export, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	settings.AddCommand(export)
+
+	importSettings := model.NewAutocompleteData(settingImport, "[settings]", "Replace your settings with those exported from another instance")
+	importSettings.AddTextArgument("The JSON shown by `/jira settings export`", "[settings]", "")
+	withFlagInstance(importSettings, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	settings.AddCommand(importSettings)
+
 	return settings
 }
 
@@ -298,26 +657,187 @@ func createViewCommand(optInstance bool) *model.AutocompleteData {
 	return view
 }
 
+func createMineCommand(optInstance bool) *model.AutocompleteData {
+	mine := model.NewAutocompleteData(
+		"mine", "[project-key] [--all]", "List the open issues assigned to you")
+	mine.AddTextArgument("Project key, by default all the projects", "[project-key]", "")
+	mine.AddStaticListArgument("Include the done issues", false, []model.AutocompleteListItem{
+		{HelpText: "List the done issues too", Item: mineAllFlag},
+	})
+	withFlagInstance(mine, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	return mine
+}
+
+func createSearchCommand(optInstance bool) *model.AutocompleteData {
+	search := model.NewAutocompleteData(
+		"search", "[--format cards|table] [--sort field] [jql]", "Search Jira issues with JQL")
+	search.AddNamedStaticListArgument("format", "How to show the issues", false, []model.AutocompleteListItem{
+		{HelpText: "One card per issue", Item: searchFormatCards},
+		{HelpText: "A compact table", Item: searchFormatTable},
+	})
+	sortItems := []model.AutocompleteListItem{}
+	for _, field := range searchSortFields {
+		sortItems = append(sortItems, model.AutocompleteListItem{HelpText: "Sort by " + field, Item: field})
+	}
+	search.AddNamedStaticListArgument("sort", "The column to sort the issues by", false, sortItems)
+	search.AddTextArgument("JQL query", "[jql]", "")
+	withFlagInstance(search, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	return search
+}
+
+func createDescribeCommand(optInstance bool) *model.AutocompleteData {
+	describe := model.NewAutocompleteData(
+		"describe", "[issue]", "Share a Jira issue with the channel")
+	withParamIssueKey(describe)
+	withFlagInstance(describe, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	return describe
+}
+
 func createTransitionCommand(optInstance bool) *model.AutocompleteData {
 	transition := model.NewAutocompleteData(
 		"transition", "[Jira issue] [To state]", "Change the state of a Jira issue")
 	withParamIssueKey(transition)
 	// TODO: Implement dynamic transition autocomplete
-	transition.AddTextArgument("To state", "", "")
+	transition.AddTextArgument("To state, or #N to apply the transition N of the list shown without a state", "", "")
 	withFlagInstance(transition, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
 	return transition
 }
 
+func createReopenCommand(optInstance bool) *model.AutocompleteData {
+	reopen := model.NewAutocompleteData(
+		"reopen", "[Jira issue]", "Move a done Jira issue back to an open state")
+	withParamIssueKey(reopen)
+	withFlagInstance(reopen, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	return reopen
+}
+
 func createAssignCommand(optInstance bool) *model.AutocompleteData {
 	assign := model.NewAutocompleteData(
 		"assign", "[Jira issue] [user]", "Change the assignee of a Jira issue")
 	withParamIssueKey(assign)
 	// TODO: Implement dynamic Jira user search autocomplete
-	assign.AddTextArgument("User", "", "")
+	assign.AddTextArgument("me, @user, or a Jira username or account ID", "", "")
 	withFlagInstance(assign, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
 	return assign
 }
 
+func createAttachCommand(optInstance bool) *model.AutocompleteData {
+	attach := model.NewAutocompleteData(
+		"attach", "[Jira issue]", "Attach the files of the thread's root message to a Jira issue")
+	withParamIssueKey(attach)
+	withFlagInstance(attach, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	return attach
+}
+
+func createChannelCommand() *model.AutocompleteData {
+	channel := model.NewAutocompleteData(
+		"channel", "[read-only|create-reply|default-issue-type|dev-info]", "Manage the Jira settings of this channel")
+	readOnly := model.NewAutocompleteData(
+		"read-only", "[on|off]", "Disable or enable Jira writes in this channel")
+	readOnly.AddStaticListArgument("value", false, []model.AutocompleteListItem{
+		{HelpText: "Disable Jira writes in this channel", Item: settingOn},
+		{HelpText: "Enable Jira writes in this channel", Item: settingOff},
+	})
+	channel.AddCommand(readOnly)
+
+	createReply := model.NewAutocompleteData(
+		"create-reply", "[default|ephemeral|public]", "Choose how the issues created in this channel are announced")
+	createReply.AddStaticListArgument("value", false, []model.AutocompleteListItem{
+		{HelpText: "Show the issue to its creator, and announce it with a link", Item: createReplyDefault},
+		{HelpText: "Only show the issue to its creator", Item: createReplyEphemeral},
+		{HelpText: "Post the issue to the channel as a card", Item: createReplyPublic},
+	})
+	channel.AddCommand(createReply)
+
+	defaultIssueType := model.NewAutocompleteData(
+		"default-issue-type", "[issue type|none]", "Pick an issue type in /jira create in this channel")
+	defaultIssueType.AddTextArgument("Issue type name, or none for that of the Jira instance", "[issue type|none]", "")
+	channel.AddCommand(defaultIssueType)
+
+	devInfo := model.NewAutocompleteData(
+		"dev-info", "[on|off]", "Show the development information of the issues in this channel")
+	devInfo.AddStaticListArgument("value", false, []model.AutocompleteListItem{
+		{HelpText: "Show the branches, commits and pull requests of the Jira Cloud issues", Item: settingOn},
+		{HelpText: "Leave them out", Item: settingOff},
+	})
+	channel.AddCommand(devInfo)
+	return channel
+}
+
+func createIssueTypesCommand(optInstance bool) *model.AutocompleteData {
+	issueTypes := model.NewAutocompleteData(
+		"issue-types", "[project key]", "List the issue types that you can create in a project")
+	issueTypes.AddTextArgument("Project key", "Enter the project key, e.g. MM", "")
+	withFlagInstance(issueTypes, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	return issueTypes
+}
+
+func createIssueTypeFieldsCommand(optInstance bool) *model.AutocompleteData {
+	fields := model.NewAutocompleteData(
+		"issue-type-fields", "[project key] [issue type]", "List the fields of an issue type of a project")
+	fields.AddTextArgument("Project key", "Enter the project key, e.g. MM", "")
+	fields.AddTextArgument("Issue type", "Enter the issue type, e.g. Bug", "")
+	withFlagInstance(fields, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	return fields
+}
+
+func createBoardCommand(optInstance bool) *model.AutocompleteData {
+	board := model.NewAutocompleteData(
+		"board", "[board]", "Show the columns of a Kanban board, with their issues and WIP limits")
+	board.AddDynamicListArgument("Board ID or name", makeAutocompleteRoute(routeAutocompleteBoards), true)
+	withFlagInstance(board, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	return board
+}
+
+func createEpicCommand(optInstance bool) *model.AutocompleteData {
+	epic := model.NewAutocompleteData(
+		"epic", "[epic-key]", "List the child issues of an epic by status, with its progress")
+	withParamIssueKey(epic)
+	withFlagInstance(epic, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	return epic
+}
+
+func createCommentCommand(optInstance bool) *model.AutocompleteData {
+	comment := model.NewAutocompleteData(
+		"comment", "[issue-key|delete|list]", "Comment on Jira issues, and manage the comments you attached to them")
+
+	deleteComment := model.NewAutocompleteData(
+		"delete", "[issue-key] [comment-id]", "Delete a comment that you attached from Mattermost")
+	withParamIssueKey(deleteComment)
+	deleteComment.AddTextArgument("Comment ID", "[comment-id]", "")
+	withFlagInstance(deleteComment, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	comment.AddCommand(deleteComment)
+
+	list := model.NewAutocompleteData(
+		"list", "[issue-key] [--page number]", "Show the latest comments of a Jira issue")
+	withParamIssueKey(list)
+	list.AddNamedTextArgument("page", "Page of older comments, from 1 for the latest", "[number]", "", false)
+	withFlagInstance(list, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	comment.AddCommand(list)
+	return comment
+}
+
+func createWorklogCommand(optInstance bool) *model.AutocompleteData {
+	worklog := model.NewAutocompleteData(
+		"worklog", "[issue-key] [duration] [comment]", "Log the time you spent on a Jira issue")
+	withParamIssueKey(worklog)
+	worklog.AddTextArgument("Time spent, e.g. 1h 30m, then optionally a comment", "[duration] [comment]", "")
+	withFlagInstance(worklog, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	return worklog
+}
+
+func createWatchCommand(optInstance, watch bool) *model.AutocompleteData {
+	command := model.NewAutocompleteData(
+		"unwatch", "[issue-key]", "Stop watching a Jira issue")
+	if watch {
+		command = model.NewAutocompleteData(
+			"watch", "[issue-key]", "Watch a Jira issue")
+	}
+	withParamIssueKey(command)
+	withFlagInstance(command, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	return command
+}
+
 func createUnassignCommand(optInstance bool) *model.AutocompleteData {
 	unassign := model.NewAutocompleteData(
 		"unassign", "[Jira issue]", "Unassign a Jira issue")
@@ -328,7 +848,7 @@ func createUnassignCommand(optInstance bool) *model.AutocompleteData {
 
 func createSubscribeCommand(optInstance bool) *model.AutocompleteData {
 	subscribe := model.NewAutocompleteData(
-		"subscribe", "[edit|list]", "List or configure the Jira notifications sent to this channel")
+		"subscribe", "[edit|list|stats|preview|dry-run|who|auto|import-from-filter|resync|since]", "List or configure the Jira notifications sent to this channel")
 	subscribe.AddCommand(model.NewAutocompleteData(
 		"edit", "", "Configure the Jira notifications sent to this channel"))
 
@@ -336,6 +856,95 @@ func createSubscribeCommand(optInstance bool) *model.AutocompleteData {
 		"list", "", "List the Jira notifications sent to this channel")
 	withFlagInstance(list, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
 	subscribe.AddCommand(list)
+
+	stats := model.NewAutocompleteData(
+		"stats", "", "Rank the subscriptions in this channel by recent post volume")
+	withFlagInstance(stats, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	subscribe.AddCommand(stats)
+
+	preview := model.NewAutocompleteData(
+		"preview", "[JQL]", "Show how many issues currently match a JQL query")
+	withFlagInstance(preview, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	subscribe.AddCommand(preview)
+
+	dryRun := model.NewAutocompleteData(
+		"dry-run", "[issue-key] [JQL]", "Explain whether an issue matches a JQL query")
+	withParamIssueKey(dryRun)
+	withFlagInstance(dryRun, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	subscribe.AddCommand(dryRun)
+
+	who := model.NewAutocompleteData(
+		"who", "[issue-key]", "List the subscriptions that an update of the issue would notify")
+	withParamIssueKey(who)
+	withFlagInstance(who, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	subscribe.AddCommand(who)
+
+	auto := model.NewAutocompleteData(
+		"auto", "[project-key]", "Subscribe this channel to the project in its header or purpose")
+	auto.AddTextArgument("Project key, by default the one in the channel header or purpose", "[project-key]", "")
+	withFlagInstance(auto, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	subscribe.AddCommand(auto)
+
+	importFromFilter := model.NewAutocompleteData(
+		"import-from-filter", "[filter-id] [--pin]", "Subscribe this channel to the issues of a saved Jira filter")
+	importFromFilter.AddTextArgument("ID of the saved Jira filter", "[filter-id]", "")
+	importFromFilter.AddStaticListArgument("Pin the subscription to the filter, to resync it later", false, []model.AutocompleteListItem{
+		{HelpText: "Keep the filter ID, to pick up its changes with `/jira subscribe resync`", Item: subscribeFilterPinFlag},
+	})
+	withFlagInstance(importFromFilter, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	subscribe.AddCommand(importFromFilter)
+
+	resync := model.NewAutocompleteData(
+		"resync", "[subscription]", "Update a subscription with the current JQL of its Jira filter")
+	resync.AddTextArgument("ID or name of the subscription", "[subscription]", "")
+	withFlagInstance(resync, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	subscribe.AddCommand(resync)
+
+	since := model.NewAutocompleteData(
+		"since", "[duration] [subscription]", "Backfill this channel with the issues updated recently")
+	since.AddTextArgument("How far back to look, e.g. 90m, 24h, 2d or 1w", "[duration]", "")
+	since.AddTextArgument("ID or name of the subscription, by default all the subscriptions of this channel", "[subscription]", "")
+	withFlagInstance(since, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	subscribe.AddCommand(since)
+
+	excludeSelf := model.NewAutocompleteData(
+		"exclude-self", "[on|off] [subscription]", "Skip the events triggered by service accounts or by changes made with the plugin")
+	excludeSelf.AddStaticListArgument("value", true, []model.AutocompleteListItem{
+		{HelpText: "Skip the self triggered events", Item: settingOn},
+		{HelpText: "Post all the events", Item: settingOff},
+	})
+	excludeSelf.AddTextArgument("ID or name of the subscription", "[subscription]", "")
+	withFlagInstance(excludeSelf, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	subscribe.AddCommand(excludeSelf)
+
+	onCreateOnlyNew := model.NewAutocompleteData(
+		"on-create-only-new", "[on|off] [subscription]", "Post the creation of an issue once, not again when it is created anew")
+	onCreateOnlyNew.AddStaticListArgument("value", true, []model.AutocompleteListItem{
+		{HelpText: "Skip the creation events of the issues already posted", Item: settingOn},
+		{HelpText: "Post all the creation events", Item: settingOff},
+	})
+	onCreateOnlyNew.AddTextArgument("ID or name of the subscription", "[subscription]", "")
+	withFlagInstance(onCreateOnlyNew, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	subscribe.AddCommand(onCreateOnlyNew)
+
+	webhook := model.NewAutocompleteData(
+		"webhook", "[regenerate|revoke] [subscription]", "Show the webhook URL whose events are only delivered to a subscription")
+	webhook.AddTextArgument("Optionally regenerate or revoke, then the ID or name of the subscription", "[regenerate|revoke] [subscription]", "")
+	withFlagInstance(webhook, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	subscribe.AddCommand(webhook)
+
+	quiet := model.NewAutocompleteData(
+		"quiet", "[add|remove|list|clear|summary]", "Silence the subscriptions of this channel during recurring windows")
+	quiet.AddStaticListArgument("action", true, []model.AutocompleteListItem{
+		{HelpText: "Add a window, e.g. `add mon-fri 14:00-15:00 Europe/Paris`", Item: "add"},
+		{HelpText: "Remove a window by its number in the list", Item: "remove"},
+		{HelpText: "List the windows", Item: "list"},
+		{HelpText: "Remove all the windows", Item: "clear"},
+		{HelpText: "Post the number of updates suppressed when a window ends, on or off", Item: "summary"},
+	})
+	quiet.AddTextArgument("The days, times and timezone of the window, the number of a window, or on|off", "[days] [HH:MM-HH:MM] [timezone]", "")
+	withFlagInstance(quiet, optInstance, makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias))
+	subscribe.AddCommand(quiet)
 	return subscribe
 }
 
@@ -354,20 +963,77 @@ func createSetupCommand() *model.AutocompleteData {
 	return setup
 }
 
+func createAdminCommand() *model.AutocompleteData {
+	admin := model.NewAutocompleteData(
+		"admin", "[reconnect-reminder|purge-orphans|auto-connect|set-bot-icon|broadcast|export-metrics]", "Manage the Jira plugin")
+	admin.RoleID = model.SystemAdminRoleId
+
+	reminder := model.NewAutocompleteData(
+		"reconnect-reminder", "", "Send a reconnect reminder to users whose Jira connection is broken")
+	reminder.RoleID = model.SystemAdminRoleId
+	admin.AddCommand(reminder)
+
+	purge := model.NewAutocompleteData(
+		"purge-orphans", "[--confirm]", "List, and with --confirm delete, the connections, subscriptions and instance list entries of instances that no longer exist")
+	purge.RoleID = model.SystemAdminRoleId
+	purge.AddStaticListArgument("", false, []model.AutocompleteListItem{
+		{HelpText: "Delete the orphaned connections and subscriptions", Item: "--confirm"},
+	})
+	admin.AddCommand(purge)
+
+	autoConnect := model.NewAutocompleteData(
+		"auto-connect", "[jiraURL] [--confirm]", "List, and with --confirm connect, the users with the same email address in Jira")
+	autoConnect.RoleID = model.SystemAdminRoleId
+	autoConnect.AddDynamicListArgument("Jira instance", makeAutocompleteRoute(routeAutocompleteInstalledInstanceWithAlias), true)
+	autoConnect.AddStaticListArgument("", false, []model.AutocompleteListItem{
+		{HelpText: "Connect the matched users, and prompt the others to connect", Item: "--confirm"},
+	})
+	admin.AddCommand(autoConnect)
+
+	setBotIcon := model.NewAutocompleteData(
+		"set-bot-icon", "[image URL|post link|reset] [--name display name|reset]", "Change the avatar and the display name of the bot")
+	setBotIcon.RoleID = model.SystemAdminRoleId
+	setBotIcon.AddTextArgument("A PNG or JPEG image URL, the link to a post with an image attached, or reset", "[image URL|post link|reset]", "")
+	setBotIcon.AddNamedTextArgument("name", "Display name of the bot, or reset", "[display name|reset]", "", false)
+	admin.AddCommand(setBotIcon)
+
+	broadcast := model.NewAutocompleteData(
+		"broadcast", "[--instance=jiraURL] [message]", "Send a message as a DM to all the users connected to Jira")
+	broadcast.RoleID = model.SystemAdminRoleId
+	broadcast.AddTextArgument("The markdown message to send", "[message]", "")
+	admin.AddCommand(broadcast)
+
+	exportMetrics := model.NewAutocompleteData(
+		"export-metrics", "", "Show the plugin counters as JSON")
+	exportMetrics.RoleID = model.SystemAdminRoleId
+	admin.AddCommand(exportMetrics)
+	return admin
+}
+
 type CommandHandlerFunc func(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse
 
 type CommandHandler struct {
 	handlers       map[string]CommandHandlerFunc
 	defaultHandler CommandHandlerFunc
+
+	// writeHandlers are the commands that change Jira, which are disabled in
+	// channels marked as read-only for Jira.
+	writeHandlers map[string]bool
 }
 
 func (ch CommandHandler) Handle(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
 	for n := len(args); n > 0; n-- {
-		h := ch.handlers[strings.Join(args[:n], "/")]
+		path := strings.Join(args[:n], "/")
+		h := ch.handlers[path]
 		if h != nil {
+			p.metrics.countCommand(strings.Join(args[:n], " "))
+			if ch.writeHandlers[path] && p.isChannelReadOnly(header.ChannelId) {
+				return p.responsef(header, channelReadOnlyMessage)
+			}
 			return h(p, c, header, args[n:]...)
 		}
 	}
+	p.metrics.countCommand("help")
 	return ch.defaultHandler(p, c, header, args...)
 }
 
@@ -397,11 +1063,14 @@ func (p *Plugin) help(args *model.CommandArgs) *model.CommandResponse {
 }
 
 func (p *Plugin) ExecuteCommand(c *plugin.Context, commandArgs *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
-	err := p.CheckSiteURL()
-	if err != nil {
-		return p.responsef(commandArgs, err.Error()), nil
-	}
 	args := strings.Fields(commandArgs.Command)
+	// /jira ping is the first thing to check, it answers however the plugin
+	// is configured.
+	if len(args) != 2 || args[0] != "/jira" || args[1] != "ping" {
+		if err := p.CheckSiteURL(); err != nil {
+			return p.responsef(commandArgs, err.Error()), nil
+		}
+	}
 	if len(args) == 0 || args[0] != "/jira" {
 		return p.help(commandArgs), nil
 	}
@@ -409,33 +1078,94 @@ func (p *Plugin) ExecuteCommand(c *plugin.Context, commandArgs *model.CommandArg
 }
 
 func executeDisconnect(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
-	if len(args) > 1 {
+	jiraURL, args, err := p.parseCommandFlagInstanceURL(args)
+	if err != nil {
+		return p.responsef(header, "Failed to load the Jira instance. Error: %v.", err)
+	}
+	confirm := false
+	remaining := []string{}
+	for _, arg := range args {
+		if arg == "--confirm" {
+			confirm = true
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	if len(remaining) > 1 || (len(remaining) == 1 && jiraURL != "") {
 		return p.help(header)
 	}
-	jiraURL := ""
-	if len(args) > 0 {
-		jiraURL = args[0]
+	if len(remaining) == 1 {
+		jiraURL = remaining[0]
+	}
+
+	mattermostUserID := types.ID(header.UserId)
+	user, err := p.userStore.LoadUser(mattermostUserID)
+	if errors.Cause(err) == kvstore.ErrNotFound || (err == nil && user.ConnectedInstances.IsEmpty()) {
+		return p.responsef(header, "Your account is not connected to Jira. Please use `/jira connect` to connect your account.")
 	}
-	instances, err := p.instanceStore.LoadInstances()
 	if err != nil {
-		return p.responsef(header, "Failed to load instances. Error: %v.", err)
+		return p.responsef(header, "Could not complete the **disconnection** request. Error: %v", err)
 	}
-	instance := instances.getByAlias(jiraURL)
-	if instance != nil {
-		jiraURL = instance.InstanceID.String()
+
+	var instanceID types.ID
+	switch {
+	case jiraURL != "":
+		instanceID, err = p.resolveInstanceURL(jiraURL)
+		if err != nil {
+			return p.responsef(header, "Failed to parse the Jira URL. Error: %v.", err)
+		}
+	case user.ConnectedInstances.Len() == 1:
+		instanceID = user.ConnectedInstances.IDs()[0]
+	default:
+		return p.responsef(header, "%s", p.mdDisconnectChoices(user))
+	}
+	if !user.ConnectedInstances.Contains(instanceID) {
+		return p.responsef(header, "You do not currently have a Jira account at %s linked to your Mattermost account. Please use `/jira connect` to connect your account.", instanceID)
+	}
+
+	if !confirm {
+		return p.responsef(header, "%s", p.mdDisconnectConfirmation(instanceID, mattermostUserID))
 	}
-	disconnected, err := p.DisconnectUser(jiraURL, types.ID(header.UserId))
+
+	disconnected, err := p.DisconnectUser(instanceID.String(), mattermostUserID)
 	if errors.Cause(err) == kvstore.ErrNotFound {
-		errorStr := "Your account is not connected to Jira. Please use `/jira connect` to connect your account."
-		if jiraURL != "" {
-			errorStr = fmt.Sprintf("You do not currently have a Jira account at %s linked to your Mattermost account. Please use `/jira connect` to connect your account.", jiraURL)
-		}
-		return p.responsef(header, errorStr)
+		return p.responsef(header, "You do not currently have a Jira account at %s linked to your Mattermost account. Please use `/jira connect` to connect your account.", instanceID)
 	}
 	if err != nil {
 		return p.responsef(header, "Could not complete the **disconnection** request. Error: %v", err)
 	}
-	return p.responsef(header, "You have successfully disconnected your Jira account (**%s**).", disconnected.DisplayName)
+	return p.responsef(header, "You have successfully disconnected your Jira account (**%s**) from %s.", disconnected.DisplayName, instanceID)
+}
+
+// mdDisconnectChoices lists the instances the user is connected to, with the
+// command disconnecting each.
+func (p *Plugin) mdDisconnectChoices(user *User) string {
+	instances, _ := p.instanceStore.LoadInstances()
+	msg := "You are connected to several Jira instances, please pick the one to disconnect:\n"
+	for _, instanceID := range user.ConnectedInstances.IDs() {
+		name := instanceID.String()
+		if instances != nil {
+			if alias := instances.getAlias(instanceID); alias != "" {
+				name = alias
+			}
+		}
+		msg += fmt.Sprintf("* %s: `/jira disconnect %s`\n", instanceID, name)
+	}
+	return strings.TrimSuffix(msg, "\n")
+}
+
+// mdDisconnectConfirmation describes what disconnecting from the instance
+// does, and how to confirm it.
+func (p *Plugin) mdDisconnectConfirmation(instanceID, mattermostUserID types.ID) string {
+	account := ""
+	if conn, err := p.userStore.LoadConnection(instanceID, mattermostUserID); err == nil && conn.DisplayName != "" {
+		account = fmt.Sprintf(" (**%s**)", conn.DisplayName)
+	}
+	msg := fmt.Sprintf("This will disconnect your Jira account%s from %s. You will stop receiving its notifications, and will need to connect again to use it from Mattermost.", account, instanceID)
+	if instance, err := p.instanceStore.LoadInstance(instanceID); err == nil && instance.Common().Type == CloudOAuthInstanceType {
+		msg += " The access of Mattermost to your Jira account will also be revoked."
+	}
+	return msg + fmt.Sprintf("\n\nRun `/jira disconnect %s --confirm` to confirm.", instanceID)
 }
 
 func executeDefaultInstance(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
@@ -470,10 +1200,13 @@ func executeDefaultInstance(p *Plugin, c *plugin.Context, header *model.CommandA
 }
 
 func executeConnect(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
-	if len(args) > 1 {
+	jiraURL, args, err := p.parseCommandFlagInstanceURL(args)
+	if err != nil {
+		return p.responsef(header, "Failed to identify the Jira instance. Error: %v.", err)
+	}
+	if len(args) > 1 || (len(args) == 1 && jiraURL != "") {
 		return p.help(header)
 	}
-	jiraURL := ""
 	if len(args) > 0 {
 		jiraURL = args[0]
 	}
@@ -497,9 +1230,16 @@ func executeConnect(p *Plugin, c *plugin.Context, header *model.CommandArgs, arg
 	if jiraURL == "" {
 		if info.connectable.Len() == 1 {
 			jiraURL = info.connectable.IDs()[0].String()
+		} else if info.connectable.IsEmpty() && info.User.ConnectedInstances.Len() == 1 {
+			jiraURL = info.User.ConnectedInstances.IDs()[0].String()
 		}
 	}
 	instanceID := types.ID(jiraURL)
+	if instanceID != "" && info.User.ConnectedInstances.Contains(instanceID) {
+		status, detail := p.probeConnection(instanceID, types.ID(header.UserId))
+		p.client.Post.SendEphemeralPost(header.UserId, p.alreadyConnectedPost(header, instanceID, status, detail))
+		return &model.CommandResponse{}
+	}
 	if info.connectable.IsEmpty() {
 		return p.responsef(header,
 			"You already have connected all available Jira accounts. Please use `/jira disconnect --instance=%s` to disconnect.",
@@ -523,6 +1263,155 @@ func executeConnect(p *Plugin, c *plugin.Context, header *model.CommandArgs, arg
 		p.GetPluginURL(), link)
 }
 
+// alreadyConnectedPost is the response to a user connecting to an instance
+// they are already connected to, instead of starting the flow again. It
+// has a button to disconnect, and to start the flow anyway.
+func (p *Plugin) alreadyConnectedPost(header *model.CommandArgs, instanceID types.ID, status, detail string) *model.Post {
+	text := fmt.Sprintf("You're already connected to %s as **%s**. Use `/jira disconnect --instance=%s` first to reconnect.",
+		instanceID, detail, instanceID)
+	if status != AuthStatusOK {
+		text = fmt.Sprintf("You're already connected to %s, but the connection does not work: **%s** (%s). Use `/jira disconnect --instance=%s` first to reconnect.",
+			instanceID, status, detail, instanceID)
+	}
+
+	post := &model.Post{
+		UserId:    p.getUserID(),
+		ChannelId: header.ChannelId,
+		RootId:    header.RootId,
+	}
+	model.ParseSlackAttachment(post, []*model.SlackAttachment{
+		{
+			Text: text,
+			Actions: []*model.PostAction{
+				{
+					Name:  "Reconnect",
+					Type:  model.PostActionTypeButton,
+					Style: "danger",
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s%s%s", manifest.Id, routeAPI, routeAPIUserForceReconnect),
+						Context: map[string]interface{}{
+							"instance_id": instanceID.String(),
+						},
+					},
+				},
+			},
+		},
+	})
+	return post
+}
+
+func executeConnectStatus(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	if len(args) != 0 {
+		return p.help(header)
+	}
+	mattermostUserID := types.ID(header.UserId)
+
+	user, err := p.userStore.LoadUser(mattermostUserID)
+	if err != nil || user.ConnectedInstances.IsEmpty() {
+		return p.responsef(header, "You are not connected to any Jira instance. Please type `/jira connect` to connect.")
+	}
+
+	resp := "###### Jira connection status:\n"
+	for _, instanceID := range user.ConnectedInstances.IDs() {
+		status, detail := p.probeConnection(instanceID, mattermostUserID)
+		if status == AuthStatusOK {
+			resp += fmt.Sprintf("* %s: **%s**, connected as %s\n", instanceID, status, detail)
+			continue
+		}
+
+		resp += fmt.Sprintf("* %s: **%s** (%s). %s\n", instanceID, status, detail, p.reconnectPromptMessage(instanceID))
+	}
+
+	return p.responsef(header, "%s", resp)
+}
+
+func (p *Plugin) reconnectPromptMessage(instanceID types.ID) string {
+	return fmt.Sprintf("Please reconnect: run `/jira disconnect %s --confirm`, then [link your Jira account](%s%s) again.",
+		instanceID, p.GetPluginURL(), instancePath(routeUserConnect, instanceID))
+}
+
+// probeConnection makes a lightweight authenticated call to Jira on behalf of
+// the user, and returns the connection status along with the Jira user's
+// display name if it works, or the error otherwise.
+func (p *Plugin) probeConnection(instanceID, mattermostUserID types.ID) (string, string) {
+	instance, err := p.instanceStore.LoadInstance(instanceID)
+	if err != nil {
+		return AuthStatusFailed, "the Jira instance could not be loaded"
+	}
+	connection, err := p.userStore.LoadConnection(instanceID, mattermostUserID)
+	if err != nil {
+		return AuthStatusFailed, "the connection could not be loaded"
+	}
+
+	client, err := instance.GetClient(connection)
+	if err == nil {
+		var self *jira.User
+		self, err = client.GetSelf()
+		if err == nil {
+			return AuthStatusOK, self.DisplayName
+		}
+	}
+
+	return AuthStatus(err), err.Error()
+}
+
+func executeAdminReconnectReminder(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	authorized, err := authorizedSysAdmin(p, header.UserId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira admin reconnect-reminder` can only be run by a system administrator.")
+	}
+	if len(args) != 0 {
+		return p.help(header)
+	}
+
+	summary, err := p.sendReconnectReminders(time.Now())
+	if err != nil {
+		return p.responsef(header, "Failed to check the user connections: %v\n%s", err, summary)
+	}
+	return p.responsef(header, "%s", summary)
+}
+
+func executeAdminPurgeOrphans(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	authorized, err := authorizedSysAdmin(p, header.UserId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira admin purge-orphans` can only be run by a system administrator.")
+	}
+	confirm := false
+	switch {
+	case len(args) == 0:
+	case len(args) == 1 && args[0] == "--confirm":
+		confirm = true
+	default:
+		return p.help(header)
+	}
+
+	report, err := p.findOrphans()
+	if err != nil {
+		return p.responsef(header, "Failed to check the connections and subscriptions: %v", err)
+	}
+	if !confirm {
+		msg := report.Markdown()
+		if !report.isEmpty() {
+			msg += "\n\nRun `/jira admin purge-orphans --confirm` to delete them."
+		}
+		return p.responsef(header, "%s", msg)
+	}
+
+	purged, err := p.purgeOrphans(report)
+	msg := fmt.Sprintf("Deleted %d connection(s), %d subscription(s) and %d subscription template(s) of instances that no longer exist, and removed %d instance(s) from the instance list.",
+		len(purged.Connections), purged.subscriptions(), purged.templates(), len(purged.ListedInstances))
+	if err != nil {
+		msg += fmt.Sprintf(" Some could not be deleted: %v", err)
+	}
+	return p.responsef(header, "%s", msg)
+}
+
 func executeInstanceAlias(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
 	authorized, err := authorizedSysAdmin(p, header.UserId)
 	if err != nil {
@@ -632,6 +1521,16 @@ func executeInstanceUnalias(p *Plugin, c *plugin.Context, header *model.CommandA
 }
 
 func executeSettings(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	return p.executeSettings(header, false, args)
+}
+
+// executeInstanceSettings is executeSettings for notifications set per
+// instance, overriding the global ones.
+func executeInstanceSettings(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	return p.executeSettings(header, true, args)
+}
+
+func (p *Plugin) executeSettings(header *model.CommandArgs, instanceLevel bool, args []string) *model.CommandResponse {
 	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
 	if err != nil {
 		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
@@ -643,14 +1542,43 @@ func executeSettings(p *Plugin, c *plugin.Context, header *model.CommandArgs, ar
 	}
 
 	if len(args) == 0 {
-		return p.responsef(header, "Current settings:\n%s", conn.Settings.String())
+		return p.responsef(header, "Current settings:\n%s", conn.Settings.stringWith(user.Settings))
 	}
 
 	switch args[0] {
 	case "list":
-		return p.responsef(header, "Current settings:\n%s", conn.Settings.String())
+		return p.responsef(header, "Current settings:\n%s", conn.Settings.stringWith(user.Settings))
 	case "notifications":
-		return p.settingsNotifications(header, instance.GetID(), user.MattermostUserID, conn, args)
+		if instanceLevel {
+			return p.settingsInstanceNotifications(header, user, instance.GetID(), conn, args)
+		}
+		return p.settingsNotifications(header, user, instance.GetID(), conn, args)
+	case "ignore-own-actions":
+		return p.settingsIgnoreOwnActions(header, instance.GetID(), user.MattermostUserID, conn, args)
+	case settingCompact:
+		return p.settingsCompact(header, instance.GetID(), user.MattermostUserID, conn, args)
+	case settingNotifyOnSubscriptionMatch:
+		return p.settingsNotifyOnSubscriptionMatch(header, instance.GetID(), user.MattermostUserID, conn, args)
+	case settingMentionOnly:
+		return p.settingsMentionOnly(header, instance.GetID(), user.MattermostUserID, conn, args)
+	case settingDailySummary:
+		return p.settingsDailySummary(header, instance.GetID(), user.MattermostUserID, conn, args)
+	case settingNotifyChannelOnMention:
+		return p.settingsNotifyChannelOnMention(header, instance.GetID(), args)
+	case settingCustomStatus:
+		return p.settingsCustomStatus(header, instance.GetID(), user.MattermostUserID, conn, args)
+	case settingNotificationFooter:
+		return p.settingsNotificationFooter(header, instance.GetID(), user.MattermostUserID, conn, args)
+	case settingNotifyStatusEntry:
+		return p.settingsNotifyStatusEntry(header, instance.GetID(), user.MattermostUserID, conn, args)
+	case settingNotifyPriorityThreshold:
+		return p.settingsNotifyPriorityThreshold(header, instance.GetID(), user.MattermostUserID, conn, args)
+	case settingReset:
+		return p.settingsReset(header, user, instance.GetID(), instanceLevel, args)
+	case settingExport:
+		return p.settingsExport(header, instance.GetID(), conn, args)
+	case settingImport:
+		return p.settingsImport(header, user, instance.GetID(), conn, args)
 	default:
 		return p.responsef(header, "Unknown setting.")
 	}
@@ -679,7 +1607,7 @@ func executeView(p *Plugin, c *plugin.Context, header *model.CommandArgs, args .
 		return p.responsef(header, "Your username is not connected to Jira. Please type `jira connect`.")
 	}
 
-	attachment, err := p.getIssueAsSlackAttachment(instance, conn, strings.ToUpper(issueID), true)
+	attachment, err := p.getIssueAsSlackAttachment(instance, conn, header.ChannelId, strings.ToUpper(issueID), true)
 	if err != nil {
 		return p.responsef(header, err.Error())
 	}
@@ -696,6 +1624,62 @@ func executeView(p *Plugin, c *plugin.Context, header *model.CommandArgs, args .
 	return &model.CommandResponse{}
 }
 
+// executeDescribe posts the issue to the channel, unlike executeView which
+// only shows it to the user.
+func executeDescribe(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	if len(args) != 1 {
+		return p.responsef(header, "Please specify an issue key in the form `/jira describe <issue-key>`.")
+	}
+	issueKey := strings.ToUpper(args[0])
+
+	if !p.client.User.HasPermissionToChannel(header.UserId, header.ChannelId, model.PermissionCreatePost) {
+		return p.responsef(header, "You do not have permission to post in this channel.")
+	}
+
+	conn, err := p.userStore.LoadConnection(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "Your username is not connected to Jira. Please type `jira connect`.")
+	}
+	client, err := instance.GetClient(conn)
+	if err != nil {
+		return p.responsef(header, err.Error())
+	}
+
+	issue, err := getIssueToView(client, issueKey)
+	if err != nil {
+		return p.responsef(header, err.Error())
+	}
+	// The members of the channel may not be allowed to see an issue with any
+	// security level, whatever the subscriptions are set to do with them.
+	if getIssueFieldValue(issue, securityLevelField).Len() > 0 {
+		return p.responsef(header, "%s has a security level, so it is not shared with the channel. Use `/jira view %s` to see it yourself.", issueKey, issueKey)
+	}
+
+	attachment, err := p.issueAsSlackAttachment(instance, user.MattermostUserID, client, issue, true)
+	if err != nil {
+		return p.responsef(header, err.Error())
+	}
+	p.addDueDateBadge(attachment, issue, types.ID(header.UserId))
+	p.addDevInfo(attachment, instance, user.MattermostUserID, client, header.ChannelId, issue)
+
+	post := &model.Post{
+		UserId:    p.getUserID(),
+		ChannelId: header.ChannelId,
+		RootId:    header.RootId,
+	}
+	post.AddProp("attachments", attachment)
+	setIssuePostProps(post, instance.GetID(), issue.Key)
+
+	if err = p.client.Post.CreatePost(post); err != nil {
+		return p.responsef(header, "Failed to share the issue. Error: %v.", err)
+	}
+	return &model.CommandResponse{}
+}
+
 // executeV2Revert reverts the store from v3 to v2 and instructs the user how
 // to proceed with downgrading
 func executeV2Revert(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
@@ -810,7 +1794,7 @@ func executeSubscribeList(p *Plugin, c *plugin.Context, header *model.CommandArg
 		return p.responsef(header, "No arguments were expected.")
 	}
 
-	msg, err := p.listChannelSubscriptions(instance.GetID(), header.TeamId)
+	msg, err := p.listChannelSubscriptions(instance.GetID(), header.TeamId, types.ID(header.UserId))
 	if err != nil {
 		return p.responsef(header, "%v", err)
 	}
@@ -818,6 +1802,268 @@ func executeSubscribeList(p *Plugin, c *plugin.Context, header *model.CommandArg
 	return p.responsef(header, msg)
 }
 
+func executeSubscribeStats(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	_, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to identify the Jira instance. Error: %v.", err)
+	}
+	if len(args) != 0 {
+		return p.responsef(header, "No arguments were expected.")
+	}
+
+	if err = p.hasPermissionToManageSubscription(instance.GetID(), header.UserId, header.ChannelId); err != nil {
+		return p.responsef(header, "You do not have permission to view the Jira subscriptions of this channel: %v.", err)
+	}
+
+	subs, err := p.getSubscriptionsForChannel(instance.GetID(), header.ChannelId)
+	if err != nil {
+		return p.responsef(header, "Failed to load the subscriptions of this channel. Error: %v.", err)
+	}
+
+	stats, err := p.getSubscriptionStats(instance.GetID())
+	if err != nil {
+		return p.responsef(header, "Failed to load the subscription statistics. Error: %v.", err)
+	}
+
+	return p.responsef(header, "%s", formatSubscriptionStats(subs, stats, time.Now()))
+}
+
+func executeSubscribePreview(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to identify the Jira instance. Error: %v.", err)
+	}
+	jql := strings.TrimSpace(strings.Join(args, " "))
+	if jql == "" {
+		return p.responsef(header, "Please specify a JQL query, e.g. `/jira subscribe preview project = KT AND status = Open`.")
+	}
+
+	if err = p.hasPermissionToManageSubscription(instance.GetID(), header.UserId, header.ChannelId); err != nil {
+		return p.responsef(header, "You do not have permission to manage the Jira subscriptions of this channel: %v.", err)
+	}
+
+	client, _, _, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+
+	msg, err := p.previewJQL(instance.GetID(), client, jql)
+	if err != nil {
+		return p.responsef(header, "Failed to preview the query. Error: %v.", err)
+	}
+	return p.responsef(header, "%s", msg)
+}
+
+func executeSubscribeDryRun(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to identify the Jira instance. Error: %v.", err)
+	}
+	if len(args) < 2 {
+		return p.responsef(header, "Please specify an issue key and a JQL query, e.g. `/jira subscribe dry-run KT-12 project = KT AND status = Open`.")
+	}
+
+	if err = p.hasPermissionToManageSubscription(instance.GetID(), header.UserId, header.ChannelId); err != nil {
+		return p.responsef(header, "You do not have permission to manage the Jira subscriptions of this channel: %v.", err)
+	}
+
+	client, _, _, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+
+	msg, err := p.dryRunJQL(instance.GetID(), client, strings.ToUpper(args[0]), strings.Join(args[1:], " "))
+	if err != nil {
+		return p.responsef(header, "Failed to run the query. Error: %v.", err)
+	}
+	return p.responsef(header, "%s", msg)
+}
+
+func executeBoard(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	if len(args) == 0 {
+		return p.responsef(header, "Please specify a board, by ID or name, e.g. `/jira board 12`.")
+	}
+
+	client, _, _, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+
+	msg, err := p.boardSummary(instance, client, strings.Join(args, " "))
+	if err != nil {
+		return p.responsef(header, "Failed to show the board. Error: %v.", err)
+	}
+	return p.responsef(header, "%s", msg)
+}
+
+func executeEpic(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	if len(args) != 1 {
+		return p.responsef(header, "Please specify an epic key, e.g. `/jira epic KT-12`.")
+	}
+
+	client, _, _, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+
+	msg, err := p.epicSummary(instance, client, args[0])
+	if err != nil {
+		return p.responsef(header, "Failed to show the epic. Error: %v.", err)
+	}
+	return p.responsef(header, "%s", msg)
+}
+
+func executeComment(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	internal := false
+	words := []string{}
+	for _, arg := range args {
+		if arg == "--internal" && !internal {
+			internal = true
+			continue
+		}
+		words = append(words, arg)
+	}
+	if len(words) < 2 {
+		return p.responsef(header, "Please specify an issue key and a comment in the form `/jira comment <issue-key> [--internal] <text>`.")
+	}
+	issueKey := strings.ToUpper(words[0])
+
+	client, _, _, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	added, isInternal, err := p.addCommandComment(instance, client, user.MattermostUserID, issueKey, strings.Join(words[1:], " "), internal)
+	if err != nil {
+		return p.responsef(header, "Failed to add the comment. Error: %v.", err)
+	}
+
+	msg := fmt.Sprintf("Commented on [%s](%s/browse/%s).", issueKey, instance.GetJiraBaseURL(), issueKey)
+	if isInternal {
+		msg = fmt.Sprintf("Added an internal comment, that only the agents see, to [%s](%s/browse/%s).", issueKey, instance.GetJiraBaseURL(), issueKey)
+	} else if internal {
+		msg += " `--internal` only applies to Jira Service Management projects, the comment is visible to everyone who can see the issue."
+	}
+	if added != nil && added.ID != "" {
+		msg += fmt.Sprintf(" To delete it, use `/jira comment delete %s %s`.", issueKey, added.ID)
+	}
+	return p.responsef(header, "%s", msg)
+}
+
+func executeCommentDelete(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	if len(args) != 2 {
+		return p.responsef(header, "Please specify an issue key and a comment ID in the form `/jira comment delete <issue-key> <comment-id>`.")
+	}
+	issueKey, commentID := strings.ToUpper(args[0]), args[1]
+
+	if err = p.DeleteComment(instance.GetID(), user.MattermostUserID, issueKey, commentID); err != nil {
+		return p.responsef(header, "Failed to delete the comment. Error: %v.", err)
+	}
+	return p.responsef(header, "Deleted comment %s of [%s](%s/browse/%s).", commentID, issueKey, instance.GetJiraBaseURL(), issueKey)
+}
+
+func executeSubscribeAuto(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to identify the Jira instance. Error: %v.", err)
+	}
+	if len(args) > 1 {
+		return p.responsef(header, "Please specify at most one project key, e.g. `/jira subscribe auto KT`.")
+	}
+
+	if err = p.hasPermissionToManageSubscription(instance.GetID(), header.UserId, header.ChannelId); err != nil {
+		return p.responsef(header, "You do not have permission to manage the Jira subscriptions of this channel: %v.", err)
+	}
+
+	client, _, connection, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+
+	if len(args) == 0 {
+		channel, appErr := p.client.Channel.Get(header.ChannelId)
+		if appErr != nil {
+			return p.responsef(header, "Failed to load the channel. Error: %v.", appErr)
+		}
+		project := findProjectInTexts(client, channel.Header, channel.Purpose)
+		if project == nil {
+			return p.responsef(header, "No key of a project of %s was found in the header or purpose of this channel. Please specify one: `/jira subscribe auto [project-key]`.", instance.GetID())
+		}
+		return p.responsef(header, "Found the project **%s** (`%s`) in the channel header or purpose. Run `/jira subscribe auto %s` to confirm, and subscribe this channel to its created, updated and transitioned issues.",
+			project.Name, project.Key, project.Key)
+	}
+
+	project, err := client.GetProject(strings.ToUpper(args[0]))
+	if err != nil {
+		return p.responsef(header, "Failed to find the project %s. Error: %v.", args[0], err)
+	}
+
+	subscription := newAutoSubscription(instance.GetID(), header.ChannelId, project)
+	if err = p.addChannelSubscription(instance.GetID(), subscription, client); err != nil {
+		return p.responsef(header, "Failed to create the subscription. Error: %v.", err)
+	}
+
+	p.UpdateUserDefaults(user.MattermostUserID, instance.GetID(), &SavedFieldValues{
+		ProjectKey: project.Key,
+	})
+
+	err = p.client.Post.CreatePost(&model.Post{
+		UserId:    p.getConfig().botUserID,
+		ChannelId: subscription.ChannelID,
+		Message:   fmt.Sprintf("Jira subscription, \"%v\", was added to this channel by %v", subscription.Name, connection.DisplayName),
+	})
+	if err != nil {
+		p.client.Log.Warn("Failed to post about the new subscription", "error", err.Error())
+	}
+
+	return p.responsef(header, "This channel is now subscribed to the created, updated and transitioned issues of %s. Use `/jira subscribe edit` to refine the subscription %q.",
+		project.Key, subscription.Name)
+}
+
+func executeSubscribeWho(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	authorized, err := authorizedSysAdmin(p, header.UserId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira subscribe who` can only be run by a system administrator.")
+	}
+
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to identify the Jira instance. Error: %v.", err)
+	}
+	if len(args) != 1 {
+		return p.responsef(header, "Please specify an issue key in the form `/jira subscribe who <issue-key>`.")
+	}
+
+	client, _, _, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+
+	msg, err := p.subscribeWho(instance.GetID(), client, strings.ToUpper(args[0]))
+	if err != nil {
+		return p.responsef(header, "Failed to evaluate the subscriptions. Error: %v.", err)
+	}
+	return p.responsef(header, "%s", msg)
+}
+
 func authorizedSysAdmin(p *Plugin, userID string) (bool, error) {
 	user, err := p.client.User.Get(userID)
 	if err != nil {
@@ -984,11 +2230,18 @@ func executeAssign(p *Plugin, c *plugin.Context, header *model.CommandArgs, args
 	issueKey := strings.ToUpper(args[0])
 	userSearch := strings.Join(args[1:], " ")
 	var assignee *jira.User
-	if strings.HasPrefix(userSearch, "@") {
+	switch {
+	case strings.HasPrefix(userSearch, "@"):
 		assignee, err = p.GetJiraUserFromMentions(instance.GetID(), header.UserMentions, userSearch)
 		if err != nil {
 			return p.responsef(header, "%v", err)
 		}
+	case strings.EqualFold(userSearch, "me"):
+		connection, err := p.userStore.LoadConnection(instance.GetID(), types.ID(header.UserId))
+		if err != nil {
+			return p.responsef(header, "%v", err)
+		}
+		assignee = &connection.User
 	}
 
 	msg, err := p.AssignIssue(instance, types.ID(header.UserId), issueKey, userSearch, assignee)
@@ -999,13 +2252,98 @@ func executeAssign(p *Plugin, c *plugin.Context, header *model.CommandArgs, args
 	return p.responsef(header, msg)
 }
 
+func executeAttach(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	_, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	if len(args) != 1 {
+		return p.responsef(header, "Please specify an issue key in the form `/jira attach <issue-key>`.")
+	}
+	if header.RootId == "" {
+		return p.responsef(header, "Please run `/jira attach <issue-key>` as a reply in the thread of the message whose files you want to attach.")
+	}
+
+	msg, err := p.AttachFilesToIssue(&InAttachFilesToIssue{
+		mattermostUserID: types.ID(header.UserId),
+		InstanceID:       instance.GetID(),
+		PostID:           header.RootId,
+		ChannelID:        header.ChannelId,
+		IssueKey:         strings.ToUpper(args[0]),
+	})
+	if err != nil {
+		return p.responsef(header, "Failed to attach files: %v", err)
+	}
+
+	return p.responsef(header, "%s", msg)
+}
+
+func executeChannelReadOnly(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	if len(args) == 0 {
+		if p.isChannelReadOnly(header.ChannelId) {
+			return p.responsef(header, "Jira writes are **disabled** in this channel.")
+		}
+		return p.responsef(header, "Jira writes are **enabled** in this channel.")
+	}
+	if len(args) != 1 || (args[0] != settingOn && args[0] != settingOff) {
+		return p.responsef(header, "Please use `/jira channel read-only [on|off]`.")
+	}
+
+	authorized, err := p.canManageChannelReadOnly(header.UserId, header.ChannelId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira channel read-only` can only be run by a channel or system administrator.")
+	}
+
+	readOnly := args[0] == settingOn
+	if err = p.setChannelReadOnly(header.ChannelId, readOnly); err != nil {
+		return p.responsef(header, "Failed to update the channel. Error: %v.", err)
+	}
+	if readOnly {
+		return p.responsef(header, "Jira writes are now disabled in this channel. Subscription posts and reading issues are not affected.")
+	}
+	return p.responsef(header, "Jira writes are now enabled in this channel.")
+}
+
+func executeIssueTypeFields(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	_, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	projectKey, issueTypeName, page, err := parseIssueTypeFieldsArgs(args)
+	if err != nil {
+		return p.responsef(header, "%v. Usage: `/jira issue-type-fields <project-key> <issue-type> [--page=N]`.", err)
+	}
+
+	client, _, _, err := p.getClient(instance.GetID(), types.ID(header.UserId))
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+
+	metaInfo, err := client.GetCreateMetaInfo(p.API, &jira.GetQueryOptions{
+		Expand:      "projects.issuetypes.fields",
+		ProjectKeys: projectKey,
+	})
+	if err != nil {
+		return p.responsef(header, "Failed to get the fields of project %s. Error: %v.", projectKey, err)
+	}
+
+	msg, err := formatIssueTypeFields(metaInfo, projectKey, issueTypeName, page)
+	if err != nil {
+		return p.responsef(header, "%v.", err)
+	}
+	return p.responsef(header, "%s", msg)
+}
+
 // TODO should transition command post to channel? Options?
 func executeTransition(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
 	instanceURL, args, err := p.parseCommandFlagInstanceURL(args)
 	if err != nil {
 		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
 	}
-	if len(args) < 2 {
+	if len(args) < 1 {
 		return p.help(header)
 	}
 	issueKey := strings.ToUpper(args[0])
@@ -1017,12 +2355,22 @@ func executeTransition(p *Plugin, c *plugin.Context, header *model.CommandArgs,
 		return p.responsef(header, "Failed to identify Jira instance %s. Error: %v.", instanceURL, err)
 	}
 
-	msg, err := p.TransitionIssue(&InTransitionIssue{
+	in := &InTransitionIssue{
 		InstanceID:       instanceID,
 		mattermostUserID: mattermostUserID,
 		IssueKey:         issueKey,
 		ToState:          toState,
-	})
+	}
+	var msg string
+	number, byNumber := parseTransitionNumber(toState)
+	switch {
+	case byNumber:
+		msg, err = p.TransitionIssueByNumber(in, number)
+	case toState == "":
+		msg, err = p.ListTransitions(in)
+	default:
+		msg, err = p.TransitionIssue(in)
+	}
 	if err != nil {
 		return p.responsef(header, err.Error())
 	}
@@ -1071,6 +2419,9 @@ func executeMe(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...
 
 	resp := sbullet("Mattermost site URL", p.GetSiteURL())
 	resp += sbullet("Mattermost user ID", fmt.Sprintf("`%s`", mattermostUserID))
+	if info.User != nil && info.User.Settings != nil {
+		resp += sbullet("Global notifications", notificationsString(info.User.Settings.Notifications, info.User.Settings.AssignedOnly))
+	}
 
 	switch {
 	case info.IsConnected:
@@ -1089,7 +2440,7 @@ func executeMe(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...
 			}
 
 			resp += connectionBullet(info.User.ConnectedInstances.Get(instanceID), connection, info.User.DefaultInstanceID == instanceID)
-			resp += fmt.Sprintf("   * %s\n", connection.Settings)
+			resp += fmt.Sprintf("   * %s\n", connection.Settings.stringWith(info.User.Settings))
 			if connection.SavedFieldValues != nil && connection.SavedFieldValues.ProjectKey != "" {
 				resp += fmt.Sprintf("   * Default project: `%s`\n", connection.SavedFieldValues.ProjectKey)
 			}
@@ -1144,6 +2495,21 @@ func executeAbout(p *Plugin, c *plugin.Context, header *model.CommandArgs, args
 	return p.responsef(header, text)
 }
 
+// executePing replies from the state of the plugin only, so that it answers
+// when Jira, or the KV store, does not.
+func executePing(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	bot := "not registered"
+	if p.getConfig().botUserID != "" {
+		bot = "registered"
+	}
+	instances := "unknown"
+	if loaded := p.loadedInstances(); loaded != nil {
+		instances = strconv.Itoa(loaded.Len())
+	}
+	return p.responsef(header, "pong - Jira plugin version %s, bot user %s, %s installed Jira instance(s).",
+		manifest.Version, bot, instances)
+}
+
 func executeWebhookURL(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
 	authorized, err := authorizedSysAdmin(p, header.UserId)
 	if err != nil {
@@ -1169,7 +2535,7 @@ func executeWebhookURL(p *Plugin, c *plugin.Context, header *model.CommandArgs,
 		return p.responsef(header, err.Error())
 	}
 
-	subWebhookURL, legacyWebhookURL, err := p.GetWebhookURL(jiraURL, header.TeamId, header.ChannelId)
+	subWebhookURL, legacyWebhookURL, automationWebhookURL, err := p.GetWebhookURL(jiraURL, header.TeamId, header.ChannelId)
 	if err != nil {
 		return p.responsef(header, err.Error())
 	}
@@ -1186,8 +2552,12 @@ func executeWebhookURL(p *Plugin, c *plugin.Context, header *model.CommandArgs,
 			"   - `%s`\n"+
 			"   - right-click on [link](%s) and \"Copy Link Address\" to copy\n"+
 			" Visit the [Legacy Webhooks](https://mattermost.gitbook.io/plugin-jira/administrator-guide/notification-management#legacy-webhooks) page to learn more about this feature.\n"+
+			"##### Jira automation\n"+
+			"A \"Send web request\" action of a Jira automation rule can post its custom payload to this channel:\n"+
+			"   - `%s`\n"+
+			"   - add `&key=` and `&summary=` with the paths of the issue key and summary in the payload, `issue.key` and `issue.fields.summary` by default, and `&message=` with a URL-encoded template such as `Released {{version.name}}` to word the post\n"+
 			"",
-		instanceID, instance.GetManageWebhooksURL(), subWebhookURL, subWebhookURL, legacyWebhookURL, legacyWebhookURL)
+		instanceID, instance.GetManageWebhooksURL(), subWebhookURL, subWebhookURL, legacyWebhookURL, legacyWebhookURL, automationWebhookURL)
 }
 
 func executeSetup(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
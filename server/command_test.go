@@ -6,6 +6,7 @@ package main
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"fmt"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -172,12 +173,12 @@ func TestPlugin_ExecuteCommand_Settings(t *testing.T) {
 		"no params, with notifications": {
 			commandArgs:  &model.CommandArgs{Command: "/jira settings", UserId: mockUserIDWithNotifications},
 			numInstances: 1,
-			expectedMsg:  "Current settings:\n\tNotifications: on",
+			expectedMsg:  "Current settings:\n\tNotifications: on\n\tIgnore my own actions: on",
 		},
 		"no params, without notifications": {
 			commandArgs:  &model.CommandArgs{Command: "/jira settings", UserId: mockUserIDWithoutNotifications},
 			numInstances: 1,
-			expectedMsg:  "Current settings:\n\tNotifications: off",
+			expectedMsg:  "Current settings:\n\tNotifications: off\n\tIgnore my own actions: on",
 		},
 		"unknown setting": {
 			commandArgs:  &model.CommandArgs{Command: "/jira settings" + " test", UserId: mockUserIDWithoutNotifications},
@@ -187,12 +188,12 @@ func TestPlugin_ExecuteCommand_Settings(t *testing.T) {
 		"set notifications without value": {
 			commandArgs:  &model.CommandArgs{Command: "/jira settings" + " notifications", UserId: mockUserIDWithoutNotifications},
 			numInstances: 1,
-			expectedMsg:  "`/jira settings notifications [value]`\n* Invalid value. Accepted values are: `on` or `off`.",
+			expectedMsg:  "`/jira settings notifications [value]`\n* Invalid value. Accepted values are: `on`, `off` or `assigned`.",
 		},
 		"set notification with unknown value": {
 			commandArgs:  &model.CommandArgs{Command: "/jira settings notifications test", UserId: mockUserIDWithoutNotifications},
 			numInstances: 1,
-			expectedMsg:  "`/jira settings notifications [value]`\n* Invalid value. Accepted values are: `on` or `off`.",
+			expectedMsg:  "`/jira settings notifications [value]`\n* Invalid value. Accepted values are: `on`, `off` or `assigned`.",
 		},
 		"enable notifications": {
 			commandArgs:  &model.CommandArgs{Command: "/jira settings notifications on", UserId: mockUserIDWithoutNotifications},
@@ -204,6 +205,61 @@ func TestPlugin_ExecuteCommand_Settings(t *testing.T) {
 			numInstances: 1,
 			expectedMsg:  "Settings updated. Notifications off.",
 		},
+		"notifications for the assigned issues only": {
+			commandArgs:  &model.CommandArgs{Command: "/jira settings notifications assigned", UserId: mockUserIDWithoutNotifications},
+			numInstances: 1,
+			expectedMsg:  "Settings updated. Notifications on for the issues assigned to you only.",
+		},
+		"set ignore-own-actions with unknown value": {
+			commandArgs:  &model.CommandArgs{Command: "/jira settings ignore-own-actions test", UserId: mockUserIDWithNotifications},
+			numInstances: 1,
+			expectedMsg:  "`/jira settings ignore-own-actions [value]`\n* Invalid value. Accepted values are: `on` or `off`.",
+		},
+		"disable ignore-own-actions": {
+			commandArgs:  &model.CommandArgs{Command: "/jira settings ignore-own-actions off", UserId: mockUserIDWithNotifications},
+			numInstances: 1,
+			expectedMsg:  "Settings updated. Ignore my own actions off.",
+		},
+		"set compact with unknown value": {
+			commandArgs:  &model.CommandArgs{Command: "/jira settings compact test", UserId: mockUserIDWithNotifications},
+			numInstances: 1,
+			expectedMsg:  "`/jira settings compact [value]`\n* Invalid value. Accepted values are: `on` or `off`.",
+		},
+		"enable compact notifications": {
+			commandArgs:  &model.CommandArgs{Command: "/jira settings compact on", UserId: mockUserIDWithNotifications},
+			numInstances: 1,
+			expectedMsg:  "Settings updated. Compact notifications on.",
+		},
+		"reset settings": {
+			commandArgs:  &model.CommandArgs{Command: "/jira settings reset", UserId: mockUserIDWithoutNotifications},
+			numInstances: 1,
+			expectedMsg:  "Settings reset to the defaults.\nChanged:\n\tNotifications: off → on\nCurrent settings:\n\tNotifications: on\n\tIgnore my own actions: on",
+		},
+		"reset default settings": {
+			commandArgs:  &model.CommandArgs{Command: "/jira settings reset", UserId: mockUserIDWithNotifications},
+			numInstances: 1,
+			expectedMsg:  "Settings reset to the defaults.\nNothing changed, your settings were the defaults.\nCurrent settings:\n\tNotifications: on\n\tIgnore my own actions: on",
+		},
+		"reset settings with a value": {
+			commandArgs:  &model.CommandArgs{Command: "/jira settings reset all", UserId: mockUserIDWithNotifications},
+			numInstances: 1,
+			expectedMsg:  "`/jira settings reset` restores all your settings to their defaults, it takes no value.",
+		},
+		"export settings": {
+			commandArgs:  &model.CommandArgs{Command: "/jira settings export", UserId: mockUserIDWithNotifications},
+			numInstances: 1,
+			expectedMsg:  "Your settings for https://jiraurl1.com:\n```\n{\"notifications\":true}\n```\nTo copy them to another Jira instance, run `/jira settings import --instance [jiraURL]` followed by them.",
+		},
+		"import settings": {
+			commandArgs:  &model.CommandArgs{Command: "/jira settings import ```{\"notifications\":true, \"compact_notifications\":true}```", UserId: mockUserIDWithoutNotifications},
+			numInstances: 1,
+			expectedMsg:  "Settings imported to https://jiraurl1.com.\nChanged:\n\tNotifications: off → on\n\tCompact notifications: off → on\nCurrent settings:\n\tNotifications: on\n\tIgnore my own actions: on\n\tCompact notifications: on",
+		},
+		"import invalid settings": {
+			commandArgs:  &model.CommandArgs{Command: "/jira settings import {\"quiet_hours\":{\"start\":\"25:00\",\"end\":\"07:00\"}}", UserId: mockUserIDWithoutNotifications},
+			numInstances: 1,
+			expectedMsg:  "Failed to import the settings: invalid quiet hours: \"25:00\" is not a valid time, please use the 24-hour HH:MM format.\n`/jira settings import --instance [jiraURL] [settings]`\n* [settings] are those shown by `/jira settings export --instance [jiraURL]` for another Jira instance.",
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -258,12 +314,12 @@ func TestPlugin_ExecuteCommand_Instance_Settings(t *testing.T) {
 		"no params, with notifications": {
 			commandArgs:  &model.CommandArgs{Command: "/jira instance settings", UserId: mockUserIDWithNotifications},
 			numInstances: 1,
-			expectedMsg:  "Current settings:\n\tNotifications: on",
+			expectedMsg:  "Current settings:\n\tNotifications: on\n\tIgnore my own actions: on",
 		},
 		"no params, without notifications": {
 			commandArgs:  &model.CommandArgs{Command: "/jira instance settings", UserId: mockUserIDWithoutNotifications},
 			numInstances: 1,
-			expectedMsg:  "Current settings:\n\tNotifications: off",
+			expectedMsg:  "Current settings:\n\tNotifications: off\n\tIgnore my own actions: on",
 		},
 		"unknown setting": {
 			commandArgs:  &model.CommandArgs{Command: "/jira instance settings" + " test", UserId: mockUserIDWithoutNotifications},
@@ -273,12 +329,12 @@ func TestPlugin_ExecuteCommand_Instance_Settings(t *testing.T) {
 		"set notifications without value": {
 			commandArgs:  &model.CommandArgs{Command: "/jira instance settings" + " notifications", UserId: mockUserIDWithoutNotifications},
 			numInstances: 1,
-			expectedMsg:  "`/jira settings notifications [value]`\n* Invalid value. Accepted values are: `on` or `off`.",
+			expectedMsg:  "`/jira instance settings notifications [value]`\n* Invalid value. Accepted values are: `on`, `off`, `assigned` or `inherit`.",
 		},
 		"set notification with unknown value": {
 			commandArgs:  &model.CommandArgs{Command: "/jira instance settings notifications test", UserId: mockUserIDWithoutNotifications},
 			numInstances: 1,
-			expectedMsg:  "`/jira settings notifications [value]`\n* Invalid value. Accepted values are: `on` or `off`.",
+			expectedMsg:  "`/jira instance settings notifications [value]`\n* Invalid value. Accepted values are: `on`, `off`, `assigned` or `inherit`.",
 		},
 		"enable notifications": {
 			commandArgs:  &model.CommandArgs{Command: "/jira instance settings notifications on", UserId: mockUserIDWithoutNotifications},
@@ -290,6 +346,11 @@ func TestPlugin_ExecuteCommand_Instance_Settings(t *testing.T) {
 			numInstances: 1,
 			expectedMsg:  "Settings updated. Notifications off.",
 		},
+		"inherit the global notifications, without global settings": {
+			commandArgs:  &model.CommandArgs{Command: "/jira instance settings notifications inherit", UserId: mockUserIDWithNotifications},
+			numInstances: 1,
+			expectedMsg:  "Settings updated. Notifications stay on until you set your global settings with `/jira settings notifications`.",
+		},
 		"multiple instances are present: Notifications off": {
 			commandArgs:  &model.CommandArgs{Command: "/jira instance settings notifications off --instance https://jiraurl1.com", UserId: mockUserIDWithNotifications},
 			numInstances: 2,
@@ -300,6 +361,11 @@ func TestPlugin_ExecuteCommand_Instance_Settings(t *testing.T) {
 			numInstances: 2,
 			expectedMsg:  "Settings updated. Notifications on.",
 		},
+		"reset the settings of an instance": {
+			commandArgs:  &model.CommandArgs{Command: "/jira instance settings reset --instance https://jiraurl1.com", UserId: mockUserIDWithoutNotifications},
+			numInstances: 2,
+			expectedMsg:  "Settings reset to the defaults.\nChanged:\n\tNotifications: off → on\nCurrent settings:\n\tNotifications: on\n\tIgnore my own actions: on",
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -334,7 +400,9 @@ func TestPlugin_ExecuteCommand_Installation(t *testing.T) {
 	api.On("KVSetWithExpiry", mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return(nil)
 	api.On("KVGet", keyInstances).Return(nil, nil)
 	api.On("KVGet", "rsa_key").Return(nil, nil)
+	api.On("KVGet", mock.AnythingOfType("string")).Return(nil, nil)
 	api.On("PublishWebSocketEvent", mock.AnythingOfType("string"), mock.Anything, mock.Anything)
+	api.On("PublishPluginClusterEvent", mock.AnythingOfType("model.PluginClusterEvent"), mock.AnythingOfType("model.PluginClusterEventSendOptions")).Return(nil)
 	api.On("UnregisterCommand", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
 
 	sysAdminUser := &model.User{
@@ -640,3 +708,272 @@ func TestPlugin_ExecuteCommand_Assign(t *testing.T) {
 		})
 	}
 }
+
+func TestPlugin_ExecuteCommand_ChannelReadOnly(t *testing.T) {
+	p := &Plugin{}
+	p.updateConfig(func(conf *config) {
+		conf.mattermostSiteURL = mattermostSiteURL
+	})
+
+	tests := map[string]struct {
+		command     string
+		readOnly    bool
+		expectedMsg string
+	}{
+		"write command in a read-only channel": {
+			command:     "/jira assign VALID @someone",
+			readOnly:    true,
+			expectedMsg: channelReadOnlyMessage,
+		},
+		"nested write command in a read-only channel": {
+			command:     "/jira issue transition VALID done",
+			readOnly:    true,
+			expectedMsg: channelReadOnlyMessage,
+		},
+		"watch in a read-only channel": {
+			command:     "/jira watch VALID",
+			readOnly:    true,
+			expectedMsg: channelReadOnlyMessage,
+		},
+		"write command in a regular channel": {
+			command:     "/jira assign",
+			expectedMsg: "Please specify an issue key and an assignee search string",
+		},
+		"read command in a read-only channel": {
+			command:     "/jira channel read-only",
+			readOnly:    true,
+			expectedMsg: "Jira writes are **disabled** in this channel.",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			value := []byte("false")
+			if tt.readOnly {
+				value = []byte("true")
+			}
+			api.On("KVGet", prefixChannelReadOnly+"channelID").Return(value, nil)
+			api.On("SendEphemeralPost", mock.AnythingOfType("string"), mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+				post := args.Get(1).(*model.Post)
+				assert.True(t, strings.HasPrefix(post.Message, tt.expectedMsg), "Actual: %s", post.Message)
+			}).Once().Return(&model.Post{})
+
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+			p.instanceStore = p.getMockInstanceStoreKV(1)
+			p.userStore = getMockUserStoreKV()
+
+			cmdResponse, appError := p.ExecuteCommand(&plugin.Context{}, &model.CommandArgs{
+				Command:   tt.command,
+				UserId:    mockUserIDWithNotifications,
+				ChannelId: "channelID",
+			})
+			require.Nil(t, appError)
+			require.NotNil(t, cmdResponse)
+			api.AssertCalled(t, "SendEphemeralPost", mock.AnythingOfType("string"), mock.AnythingOfType("*model.Post"))
+		})
+	}
+}
+
+func TestPlugin_ExecuteCommand_Describe(t *testing.T) {
+	p := &Plugin{}
+	p.updateConfig(func(conf *config) {
+		conf.mattermostSiteURL = mattermostSiteURL
+	})
+
+	tests := map[string]struct {
+		command       string
+		canPost       bool
+		expectedMsg   string
+		expectCreated bool
+	}{
+		"no issue key": {
+			command:     "/jira describe",
+			canPost:     true,
+			expectedMsg: "Please specify an issue key in the form `/jira describe <issue-key>`.",
+		},
+		"no permission to post": {
+			command:     "/jira describe TEST-1",
+			expectedMsg: "You do not have permission to post in this channel.",
+		},
+		"issue with a security level": {
+			command:     "/jira describe secure-1",
+			canPost:     true,
+			expectedMsg: "SECURE-1 has a security level, so it is not shared with the channel. Use `/jira view SECURE-1` to see it yourself.",
+		},
+		"issue shared with the channel": {
+			command:       "/jira issue describe test-1",
+			canPost:       true,
+			expectCreated: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("KVGet", mock.AnythingOfType("string")).Return(nil, nil)
+			api.On("HasPermissionToChannel", mockUserIDWithNotifications, "channelID", model.PermissionCreatePost).Return(tt.canPost)
+			api.On("SendEphemeralPost", mock.AnythingOfType("string"), mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+				post := args.Get(1).(*model.Post)
+				assert.Equal(t, tt.expectedMsg, post.Message)
+			}).Return(&model.Post{})
+			api.On("CreatePost", mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+				post := args.Get(0).(*model.Post)
+				assert.Equal(t, "channelID", post.ChannelId)
+				assert.NotEmpty(t, post.Attachments())
+			}).Return(func(post *model.Post) *model.Post { return post.Clone() }, nil)
+
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+			p.instanceStore = p.getMockInstanceStoreKV(1)
+			p.userStore = getMockUserStoreKV()
+
+			cmdResponse, appError := p.ExecuteCommand(&plugin.Context{}, &model.CommandArgs{
+				Command:   tt.command,
+				UserId:    mockUserIDWithNotifications,
+				ChannelId: "channelID",
+			})
+			require.Nil(t, appError)
+			require.NotNil(t, cmdResponse)
+			if tt.expectCreated {
+				api.AssertCalled(t, "CreatePost", mock.AnythingOfType("*model.Post"))
+				api.AssertNotCalled(t, "SendEphemeralPost", mock.AnythingOfType("string"), mock.AnythingOfType("*model.Post"))
+			} else {
+				api.AssertNotCalled(t, "CreatePost", mock.AnythingOfType("*model.Post"))
+			}
+		})
+	}
+}
+
+func TestAlreadyConnectedPost(t *testing.T) {
+	p := &Plugin{}
+	p.updateConfig(func(conf *config) {
+		conf.botUserID = "bot_id"
+	})
+	header := &model.CommandArgs{UserId: "user_id", ChannelId: "channel_id", RootId: "root_id"}
+
+	post := p.alreadyConnectedPost(header, testInstance1.InstanceID, AuthStatusOK, "Jane Doe")
+	assert.Equal(t, "bot_id", post.UserId)
+	assert.Equal(t, "channel_id", post.ChannelId)
+	assert.Equal(t, "root_id", post.RootId)
+
+	attachments := post.Attachments()
+	require.Len(t, attachments, 1)
+	assert.Equal(t, fmt.Sprintf("You're already connected to %s as **Jane Doe**. Use `/jira disconnect --instance=%s` first to reconnect.",
+		testInstance1.InstanceID, testInstance1.InstanceID), attachments[0].Text)
+	require.Len(t, attachments[0].Actions, 1)
+	action := attachments[0].Actions[0]
+	assert.Equal(t, "Reconnect", action.Name)
+	assert.Equal(t, fmt.Sprintf("/plugins/%s/api/v2/force-reconnect", manifest.Id), action.Integration.URL)
+	assert.Equal(t, testInstance1.InstanceID.String(), action.Integration.Context["instance_id"])
+
+	post = p.alreadyConnectedPost(header, testInstance1.InstanceID, AuthStatusExpired, "the token has expired")
+	assert.Contains(t, post.Attachments()[0].Text, "but the connection does not work: **"+AuthStatusExpired+"** (the token has expired)")
+}
+
+func TestPlugin_ExecuteCommand_Disconnect(t *testing.T) {
+	p := &Plugin{}
+	p.updateConfig(func(conf *config) {
+		conf.mattermostSiteURL = mattermostSiteURL
+	})
+
+	tests := map[string]struct {
+		command       string
+		bothConnected bool
+		expectedMsg   string
+	}{
+		"asks for confirmation": {
+			command: "/jira disconnect",
+			expectedMsg: fmt.Sprintf("This will disconnect your Jira account from %s. You will stop receiving its notifications, and will need to connect again to use it from Mattermost.\n\n"+
+				"Run `/jira disconnect %s --confirm` to confirm.", mockInstance1URL, mockInstance1URL),
+		},
+		"with --instance": {
+			command:       "/jira disconnect --instance " + mockInstance2URL,
+			bothConnected: true,
+			expectedMsg: fmt.Sprintf("This will disconnect your Jira account from %s. You will stop receiving its notifications, and will need to connect again to use it from Mattermost.\n\n"+
+				"Run `/jira disconnect %s --confirm` to confirm.", mockInstance2URL, mockInstance2URL),
+		},
+		"several connections": {
+			command:       "/jira disconnect",
+			bothConnected: true,
+			expectedMsg: fmt.Sprintf("You are connected to several Jira instances, please pick the one to disconnect:\n"+
+				"* %s: `/jira disconnect %s`\n* %s: `/jira disconnect %s`", mockInstance1URL, mockInstance1URL, mockInstance2URL, mockInstance2URL),
+		},
+		"not connected to the instance": {
+			command:     "/jira disconnect " + mockInstance2URL + " --confirm",
+			expectedMsg: fmt.Sprintf("You do not currently have a Jira account at %s linked to your Mattermost account. Please use `/jira connect` to connect your account.", mockInstance2URL),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("SendEphemeralPost", mock.AnythingOfType("string"), mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+				post := args.Get(1).(*model.Post)
+				assert.Equal(t, tt.expectedMsg, post.Message)
+			}).Return(&model.Post{})
+
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+			p.instanceStore = p.getMockInstanceStoreKV(2)
+			userStore := getMockUserStoreKV()
+			if tt.bothConnected {
+				userStore.users[mockUserIDWithNotifications].ConnectedInstances.Set(testInstance2.Common())
+			}
+			p.userStore = userStore
+
+			cmdResponse, appError := p.ExecuteCommand(&plugin.Context{}, &model.CommandArgs{
+				Command:   tt.command,
+				UserId:    mockUserIDWithNotifications,
+				ChannelId: "channelID",
+			})
+			require.Nil(t, appError)
+			require.NotNil(t, cmdResponse)
+			api.AssertCalled(t, "SendEphemeralPost", mock.AnythingOfType("string"), mock.AnythingOfType("*model.Post"))
+		})
+	}
+}
+
+func TestPlugin_ExecuteCommand_Ping(t *testing.T) {
+	tests := map[string]struct {
+		botUserID   string
+		instances   *Instances
+		expectedMsg string
+	}{
+		"activated": {
+			botUserID:   "botUserID",
+			instances:   NewInstances(testInstance1.Common(), testInstance2.Common()),
+			expectedMsg: "pong - Jira plugin version " + manifest.Version + ", bot user registered, 2 installed Jira instance(s).",
+		},
+		"instances not loaded and no bot": {
+			expectedMsg: "pong - Jira plugin version " + manifest.Version + ", bot user not registered, unknown installed Jira instance(s).",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := &Plugin{}
+			p.updateConfig(func(conf *config) {
+				conf.botUserID = tt.botUserID
+			})
+			api := &plugintest.API{}
+			api.On("SendEphemeralPost", "userID", mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+				post := args.Get(1).(*model.Post)
+				assert.Equal(t, tt.expectedMsg, post.Message)
+			}).Once().Return(&model.Post{})
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+			p.setInstances(tt.instances)
+
+			cmdResponse, appError := p.ExecuteCommand(&plugin.Context{}, &model.CommandArgs{
+				Command:   "/jira ping",
+				UserId:    "userID",
+				ChannelId: "channelID",
+			})
+			require.Nil(t, appError)
+			require.NotNil(t, cmdResponse)
+			api.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	commentListPageSize = 5
+	commentListFlagPage = "--page"
+
+	// commentListBodyMaxLength keeps a page of long comments in one post.
+	commentListBodyMaxLength = 1000
+
+	// jiraTimeLayout is the layout of the times in the Jira REST API.
+	jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+	permissionServiceDeskAgent = "SERVICEDESK_AGENT"
+)
+
+// isInternal tells whether the comment only shows to the agents of Jira
+// Service Management.
+func (c ListedComment) isInternal() bool {
+	for _, property := range c.Properties {
+		if property.Key != jsmPublicCommentProperty {
+			continue
+		}
+		value, ok := property.Value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if internal, _ := value["internal"].(bool); internal {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCommentListArgs returns the issue key and the page, 1 for the latest
+// comments, of `/jira comment list`.
+func parseCommentListArgs(args []string) (string, int, error) {
+	issueKey := ""
+	page := 1
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == commentListFlagPage:
+			if i+1 == len(args) {
+				return "", 0, errors.New("`--page` needs a page number")
+			}
+			i++
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n < 1 {
+				return "", 0, errors.Errorf("invalid page %q, it must be a number from 1", args[i])
+			}
+			page = n
+		case issueKey == "":
+			issueKey = strings.ToUpper(args[i])
+		default:
+			return "", 0, errors.Errorf("unexpected %q", args[i])
+		}
+	}
+	if issueKey == "" {
+		return "", 0, errors.New("please specify an issue key")
+	}
+	return issueKey, page, nil
+}
+
+// listComments renders a page of the comments of the issue that the user can
+// see, the latest first. The comments internal to the agents of Jira Service
+// Management are left out for the users that are not agents.
+func (p *Plugin) listComments(instance Instance, client Client, mattermostUserID types.ID, issueKey string, page int) (string, error) {
+	// The total tells where the latest comments are, since Jira Server only
+	// lists them oldest first.
+	counted, err := client.GetComments(issueKey, 0, 0)
+	if err != nil {
+		return "", err
+	}
+	issueLink := fmt.Sprintf("[%s](%s/browse/%s)", issueKey, instance.GetJiraBaseURL(), issueKey)
+	if counted.Total == 0 {
+		return fmt.Sprintf("%s has no comments.", issueLink), nil
+	}
+	pages := (counted.Total + commentListPageSize - 1) / commentListPageSize
+	if page > pages {
+		return "", errors.Errorf("%s has %d page(s) of comments", issueKey, pages)
+	}
+
+	startAt := counted.Total - page*commentListPageSize
+	maxResults := commentListPageSize
+	if startAt < 0 {
+		maxResults += startAt
+		startAt = 0
+	}
+	listed, err := client.GetComments(issueKey, startAt, maxResults)
+	if err != nil {
+		return "", err
+	}
+
+	agent := false
+	for _, comment := range listed.Comments {
+		if comment.isInternal() {
+			projectKey, _, _ := strings.Cut(issueKey, "-")
+			agent, err = hasProjectPermission(client, projectKey, permissionServiceDeskAgent)
+			if err != nil {
+				p.client.Log.Debug("Failed to check whether the user is an agent, the internal comments are left out", "IssueKey", issueKey, "Error", err.Error())
+			}
+			break
+		}
+	}
+
+	loc := p.userLocation(mattermostUserID)
+	rows := []string{}
+	for i := len(listed.Comments) - 1; i >= 0; i-- {
+		comment := listed.Comments[i]
+		if comment.isInternal() && !agent {
+			continue
+		}
+
+		row := "**" + comment.Author.DisplayName + "**"
+		if created, parseErr := time.Parse(jiraTimeLayout, comment.Created); parseErr == nil {
+			row += " on " + created.In(loc).Format("Jan 2, 2006 15:04 MST")
+		}
+		if comment.isInternal() {
+			row += " (internal)"
+		} else if comment.Visibility.Value != "" {
+			row += fmt.Sprintf(" (visible to %s %s)", comment.Visibility.Type, comment.Visibility.Value)
+		}
+		rows = append(rows, row+"\n"+quoteIssueComment(truncate(preProcessText(comment.Body), commentListBodyMaxLength)))
+	}
+
+	msg := fmt.Sprintf("#### Comments of %s, page %d of %d\n", issueLink, page, pages)
+	if len(rows) == 0 {
+		msg += "You cannot see the comments of this page.\n"
+	} else {
+		msg += strings.Join(rows, "\n\n") + "\n"
+	}
+	if page < pages {
+		msg += fmt.Sprintf("\nFor older comments, use `/jira comment list %s --page %d`.", issueKey, page+1)
+	}
+	return msg, nil
+}
+
+func executeCommentList(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	issueKey, page, err := parseCommentListArgs(args)
+	if err != nil {
+		return p.responsef(header, "Failed to list the comments: %v. Please use `/jira comment list <issue-key> [--page <number>]`.", err)
+	}
+
+	client, _, _, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	msg, err := p.listComments(instance, client, user.MattermostUserID, issueKey, page)
+	if err != nil {
+		return p.responsef(header, "Failed to list the comments. Error: %v.", err)
+	}
+	return p.responsef(header, "%s", msg)
+}
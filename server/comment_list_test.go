@@ -0,0 +1,127 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type commentListTestClient struct {
+	testClient
+	comments []ListedComment
+	agent    bool
+}
+
+func (client commentListTestClient) GetComments(issueKey string, startAt, maxResults int) (*CommentPage, error) {
+	end := startAt + maxResults
+	if end > len(client.comments) {
+		end = len(client.comments)
+	}
+	return &CommentPage{
+		StartAt:    startAt,
+		MaxResults: maxResults,
+		Total:      len(client.comments),
+		Comments:   client.comments[startAt:end],
+	}, nil
+}
+
+func (client commentListTestClient) RESTGet(endpoint string, params map[string]string, dest interface{}) error {
+	return json.Unmarshal([]byte(fmt.Sprintf(`{"permissions":{"SERVICEDESK_AGENT":{"havePermission":%v}}}`, client.agent)), dest)
+}
+
+func testListedComments(t *testing.T, count int, internal ...int) []ListedComment {
+	comments := []ListedComment{}
+	for i := 1; i <= count; i++ {
+		data := fmt.Sprintf(`{"id": "%d", "author": {"displayName": "User %d"}, "body": "Comment *%d*", "created": "2024-01-%02dT10:00:00.000+0000"}`, i, i, i, i)
+		for _, n := range internal {
+			if n == i {
+				data = data[:len(data)-1] + `, "properties": [{"key": "sd.public.comment", "value": {"internal": true}}]}`
+			}
+		}
+		comment := ListedComment{}
+		require.NoError(t, json.Unmarshal([]byte(data), &comment))
+		comments = append(comments, comment)
+	}
+	return comments
+}
+
+func TestParseCommentListArgs(t *testing.T) {
+	issueKey, page, err := parseCommentListArgs([]string{"kt-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "KT-1", issueKey)
+	assert.Equal(t, 1, page)
+
+	issueKey, page, err = parseCommentListArgs([]string{"--page", "3", "KT-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "KT-1", issueKey)
+	assert.Equal(t, 3, page)
+
+	for _, args := range [][]string{{}, {"KT-1", "--page"}, {"KT-1", "--page", "0"}, {"KT-1", "KT-2"}} {
+		_, _, err = parseCommentListArgs(args)
+		assert.Error(t, err, args)
+	}
+}
+
+func TestListComments(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetUser", "userID").Return(&model.User{Id: "userID", Timezone: model.StringMap{
+		"useAutomaticTimezone": "false",
+		"manualTimezone":       "Europe/Paris",
+	}}, nil)
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+
+	t.Run("the latest comments first", func(t *testing.T) {
+		client := commentListTestClient{comments: testListedComments(t, 7)}
+		msg, err := p.listComments(testInstance1, client, "userID", "KT-1", 1)
+		require.NoError(t, err)
+		assert.Contains(t, msg, "page 1 of 2")
+		assert.Contains(t, msg, "**User 7** on Jan 7, 2024 11:00 CET\n> Comment **7**")
+		assert.Less(t, strings.Index(msg, "User 7"), strings.Index(msg, "User 3"))
+		assert.NotContains(t, msg, "User 2")
+		assert.Contains(t, msg, "`/jira comment list KT-1 --page 2`")
+	})
+
+	t.Run("the oldest comments on the last page", func(t *testing.T) {
+		client := commentListTestClient{comments: testListedComments(t, 7)}
+		msg, err := p.listComments(testInstance1, client, "userID", "KT-1", 2)
+		require.NoError(t, err)
+		assert.Contains(t, msg, "User 2")
+		assert.Contains(t, msg, "User 1")
+		assert.NotContains(t, msg, "User 3")
+		assert.NotContains(t, msg, "--page")
+
+		_, err = p.listComments(testInstance1, client, "userID", "KT-1", 3)
+		assert.Error(t, err)
+	})
+
+	t.Run("the internal comments only show to the agents", func(t *testing.T) {
+		client := commentListTestClient{comments: testListedComments(t, 3, 2)}
+		msg, err := p.listComments(testInstance1, client, "userID", "KT-1", 1)
+		require.NoError(t, err)
+		assert.NotContains(t, msg, "User 2")
+		assert.Contains(t, msg, "User 3")
+
+		client.agent = true
+		msg, err = p.listComments(testInstance1, client, "userID", "KT-1", 1)
+		require.NoError(t, err)
+		assert.Contains(t, msg, "**User 2** on Jan 2, 2024 11:00 CET (internal)")
+	})
+
+	t.Run("no comments", func(t *testing.T) {
+		msg, err := p.listComments(testInstance1, commentListTestClient{}, "userID", "KT-1", 1)
+		require.NoError(t, err)
+		assert.Contains(t, msg, "has no comments")
+	})
+}
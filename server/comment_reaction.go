@@ -0,0 +1,243 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	// The props of the posts about an issue, e.g. subscription cards, that
+	// the reactions of the users can act upon.
+	postPropIssueKey   = "jira_issue_key"
+	postPropInstanceID = "jira_instance_id"
+
+	commentDialogElement = "comment"
+)
+
+// setIssuePostProps marks the post as being about the issue.
+func setIssuePostProps(post *model.Post, instanceID types.ID, issueKey string) {
+	if issueKey == "" {
+		return
+	}
+	post.AddProp(postPropIssueKey, issueKey)
+	post.AddProp(postPropInstanceID, instanceID.String())
+}
+
+// issuePostProps returns the issue that the post is about, if it is one.
+func issuePostProps(post *model.Post) (types.ID, string, bool) {
+	issueKey, _ := post.GetProp(postPropIssueKey).(string)
+	instanceID, _ := post.GetProp(postPropInstanceID).(string)
+	if issueKey == "" || instanceID == "" {
+		return "", "", false
+	}
+	return types.ID(instanceID), issueKey, true
+}
+
+// normalizeEmojiName returns the name of an emoji set as `:name:` or `name`.
+func normalizeEmojiName(emoji string) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(emoji), ":"))
+}
+
+// commentDialogState is the context of the comment dialog, passed along from
+// the prompt to the submission.
+type commentDialogState struct {
+	InstanceID string `json:"instance_id"`
+	IssueKey   string `json:"issue_key"`
+	PostID     string `json:"post_id"`
+	EmojiName  string `json:"emoji_name"`
+}
+
+// ReactionHasBeenAdded prompts the users reacting to an issue card with the
+// comment emoji for a comment to add to the issue. Reactions do not carry the
+// trigger needed to open a dialog, so the prompt is an ephemeral reply whose
+// button opens it.
+func (p *Plugin) ReactionHasBeenAdded(c *plugin.Context, reaction *model.Reaction) {
+	emoji := normalizeEmojiName(p.getConfig().CommentReactionEmoji)
+	if emoji == "" || reaction.EmojiName != emoji || reaction.UserId == p.getUserID() {
+		return
+	}
+	post, err := p.client.Post.GetPost(reaction.PostId)
+	if err != nil {
+		return
+	}
+	instanceID, issueKey, ok := issuePostProps(post)
+	if !ok {
+		return
+	}
+
+	reply := makePost(p.getUserID(), post.ChannelId, "")
+	reply.RootId = threadRootID(post, post.RootId)
+	switch {
+	case p.isChannelReadOnly(post.ChannelId):
+		reply.Message = channelReadOnlyMessage
+	case !p.isConnected(instanceID, types.ID(reaction.UserId)):
+		reply.Message = p.connectPromptMessage(instanceID)
+	default:
+		reply.AddProp("attachments", []*model.SlackAttachment{
+			{
+				Text: fmt.Sprintf("Add a comment to %s?", issueKey),
+				Actions: []*model.PostAction{
+					{
+						Name: "Add a comment",
+						Type: "button",
+						Integration: &model.PostActionIntegration{
+							URL: fmt.Sprintf("/plugins/%s%s%s", manifest.Id, routeAPI, routeIssueCommentDialog),
+							Context: map[string]interface{}{
+								"instance_id": instanceID.String(),
+								"issue_key":   issueKey,
+								"post_id":     post.Id,
+								"emoji_name":  reaction.EmojiName,
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+	p.client.Post.SendEphemeralPost(reaction.UserId, reply)
+}
+
+func (p *Plugin) isConnected(instanceID, mattermostUserID types.ID) bool {
+	_, err := p.userStore.LoadConnection(instanceID, mattermostUserID)
+	return err == nil
+}
+
+// httpOpenCommentDialog opens the comment dialog from the button of the
+// prompt, which is then removed.
+func (p *Plugin) httpOpenCommentDialog(w http.ResponseWriter, r *http.Request) (int, error) {
+	var requestData model.PostActionIntegrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+		return respondErr(w, http.StatusBadRequest, errors.New("unmarshall the body"))
+	}
+	// The user who clicked is the one Mattermost authenticated, the body can
+	// name anyone.
+	if mattermostUserID := r.Header.Get("Mattermost-User-Id"); mattermostUserID == "" || mattermostUserID != requestData.UserId {
+		return respondErr(w, http.StatusUnauthorized, errors.New("not authorized"))
+	}
+
+	state := commentDialogState{}
+	state.InstanceID, _ = requestData.Context["instance_id"].(string)
+	state.IssueKey, _ = requestData.Context["issue_key"].(string)
+	state.PostID, _ = requestData.Context["post_id"].(string)
+	state.EmojiName, _ = requestData.Context["emoji_name"].(string)
+	if state.InstanceID == "" || state.IssueKey == "" {
+		return respondErr(w, http.StatusBadRequest, errors.New("no issue was found in context data"))
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return respondErr(w, http.StatusInternalServerError, err)
+	}
+
+	err = p.client.Frontend.OpenInteractiveDialog(model.OpenDialogRequest{
+		TriggerId: requestData.TriggerId,
+		URL:       fmt.Sprintf("/plugins/%s%s%s", manifest.Id, routeAPI, routeIssueCommentDialogSubmit),
+		Dialog: model.Dialog{
+			Title:       "Comment on " + state.IssueKey,
+			SubmitLabel: "Comment",
+			Elements: []model.DialogElement{
+				{
+					DisplayName: "Comment",
+					Name:        commentDialogElement,
+					Type:        "textarea",
+					HelpText:    "Mentions of Mattermost users connected to Jira are turned into Jira mentions.",
+				},
+			},
+			State:          string(data),
+			NotifyOnCancel: true,
+		},
+	})
+	if err != nil {
+		return respondErr(w, http.StatusInternalServerError, errors.WithMessage(err, "failed to open the comment dialog"))
+	}
+
+	p.client.Post.DeleteEphemeralPost(requestData.UserId, requestData.PostId)
+	return respondJSON(w, &model.PostActionIntegrationResponse{})
+}
+
+// httpSubmitCommentDialog adds the comment of the dialog to the issue, on
+// behalf of the user, and removes the reaction that prompted it. Cancelling
+// the dialog removes the reaction as well.
+func (p *Plugin) httpSubmitCommentDialog(w http.ResponseWriter, r *http.Request) (int, error) {
+	var request model.SubmitDialogRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return respondErr(w, http.StatusBadRequest, errors.New("unmarshall the body"))
+	}
+	if mattermostUserID := r.Header.Get("Mattermost-User-Id"); mattermostUserID == "" || mattermostUserID != request.UserId {
+		return respondErr(w, http.StatusUnauthorized, errors.New("not authorized"))
+	}
+	state := commentDialogState{}
+	if err := json.Unmarshal([]byte(request.State), &state); err != nil || state.IssueKey == "" {
+		return respondErr(w, http.StatusBadRequest, errors.New("no issue was found in the dialog state"))
+	}
+	mattermostUserID := types.ID(request.UserId)
+
+	if request.Cancelled {
+		p.removeCommentReaction(request.UserId, state)
+		return http.StatusOK, nil
+	}
+
+	text, _ := request.Submission[commentDialogElement].(string)
+	if strings.TrimSpace(text) == "" {
+		return respondJSON(w, &model.SubmitDialogResponse{
+			Errors: map[string]string{commentDialogElement: "Please enter a comment."},
+		})
+	}
+	if p.isChannelReadOnly(request.ChannelId) {
+		return respondJSON(w, &model.SubmitDialogResponse{Error: channelReadOnlyMessage})
+	}
+
+	instanceID := types.ID(state.InstanceID)
+	client, instance, _, err := p.getClient(instanceID, mattermostUserID)
+	if err != nil {
+		return respondJSON(w, &model.SubmitDialogResponse{Error: err.Error()})
+	}
+	added, err := client.AddComment(state.IssueKey, &jira.Comment{
+		Body: p.mapMentions(instanceID, text),
+	})
+	if err != nil {
+		return respondJSON(w, &model.SubmitDialogResponse{Error: fmt.Sprintf("Failed to comment on %s: %v", state.IssueKey, err)})
+	}
+	p.recordSelfChange(instanceID, state.IssueKey, mattermostUserID)
+	p.removeCommentReaction(request.UserId, state)
+
+	msg := fmt.Sprintf("Commented on [%s](%s/browse/%s).", state.IssueKey, instance.GetJiraBaseURL(), state.IssueKey)
+	if added != nil && added.ID != "" {
+		if err = p.trackComment(instanceID, mattermostUserID, state.IssueKey, added.ID); err != nil {
+			p.client.Log.Warn("Failed to keep track of the comment", "issue", state.IssueKey, "comment", added.ID, "error", err.Error())
+		} else {
+			msg += fmt.Sprintf(" To delete the comment, use `/jira comment delete %s %s`.", state.IssueKey, added.ID)
+		}
+	}
+	reply := makePost(p.getUserID(), request.ChannelId, msg)
+	if post, err := p.client.Post.GetPost(state.PostID); err == nil {
+		reply.RootId = threadRootID(post, post.RootId)
+	}
+	p.client.Post.SendEphemeralPost(request.UserId, reply)
+	return http.StatusOK, nil
+}
+
+func (p *Plugin) removeCommentReaction(mattermostUserID string, state commentDialogState) {
+	if state.PostID == "" || state.EmojiName == "" {
+		return
+	}
+	err := p.client.Post.RemoveReaction(&model.Reaction{
+		UserId:    mattermostUserID,
+		PostId:    state.PostID,
+		EmojiName: state.EmojiName,
+	})
+	if err != nil {
+		p.client.Log.Debug("Failed to remove the comment reaction", "PostID", state.PostID, "error", err.Error())
+	}
+}
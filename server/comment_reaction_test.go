@@ -0,0 +1,151 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssuePostProps(t *testing.T) {
+	post := &model.Post{}
+	_, _, ok := issuePostProps(post)
+	assert.False(t, ok)
+
+	setIssuePostProps(post, testInstance1.InstanceID, "TEST-10")
+	instanceID, issueKey, ok := issuePostProps(post)
+	require.True(t, ok)
+	assert.Equal(t, testInstance1.InstanceID, instanceID)
+	assert.Equal(t, "TEST-10", issueKey)
+
+	assert.Equal(t, "speech_balloon", normalizeEmojiName(" :Speech_Balloon: "))
+}
+
+func setupCommentReactionTest(t *testing.T) (*Plugin, *plugintest.API) {
+	api := &plugintest.API{}
+	p := &Plugin{}
+	p.updateConfig(func(conf *config) {
+		conf.botUserID = "bot_id"
+		conf.CommentReactionEmoji = ":speech_balloon:"
+	})
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.instanceStore = p.getMockInstanceStoreKV(1)
+	p.userStore = getMockUserStoreKV()
+
+	card := &model.Post{Id: "card_id", ChannelId: "channel_id"}
+	setIssuePostProps(card, testInstance1.InstanceID, "TEST-10")
+	api.On("GetPost", "card_id").Return(card, nil)
+	api.On("GetPost", "other_id").Return(&model.Post{Id: "other_id", ChannelId: "channel_id"}, nil)
+	api.On("KVGet", prefixChannelReadOnly+"channel_id").Return(nil, nil)
+	return p, api
+}
+
+func TestReactionHasBeenAdded(t *testing.T) {
+	p, api := setupCommentReactionTest(t)
+	prompts := []*model.Post{}
+	api.On("SendEphemeralPost", mockUserIDWithNotifications, mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+		prompts = append(prompts, args.Get(1).(*model.Post).Clone())
+	}).Return(func(_ string, post *model.Post) *model.Post { return post.Clone() })
+
+	p.ReactionHasBeenAdded(nil, &model.Reaction{UserId: mockUserIDWithNotifications, PostId: "card_id", EmojiName: "thumbsup"})
+	p.ReactionHasBeenAdded(nil, &model.Reaction{UserId: mockUserIDWithNotifications, PostId: "other_id", EmojiName: "speech_balloon"})
+	p.ReactionHasBeenAdded(nil, &model.Reaction{UserId: "bot_id", PostId: "card_id", EmojiName: "speech_balloon"})
+	assert.Empty(t, prompts, "only the comment emoji on an issue card prompts for a comment")
+
+	p.ReactionHasBeenAdded(nil, &model.Reaction{UserId: mockUserIDWithNotifications, PostId: "card_id", EmojiName: "speech_balloon"})
+	require.Len(t, prompts, 1)
+	assert.Equal(t, "card_id", prompts[0].RootId)
+	attachments := prompts[0].Attachments()
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "Add a comment to TEST-10?", attachments[0].Text)
+	require.Len(t, attachments[0].Actions, 1)
+	assert.Equal(t, map[string]interface{}{
+		"instance_id": testInstance1.InstanceID.String(),
+		"issue_key":   "TEST-10",
+		"post_id":     "card_id",
+		"emoji_name":  "speech_balloon",
+	}, attachments[0].Actions[0].Integration.Context)
+}
+
+func TestSubmitCommentDialog(t *testing.T) {
+	state, err := json.Marshal(commentDialogState{
+		InstanceID: testInstance1.InstanceID.String(),
+		IssueKey:   "TEST-10",
+		PostID:     "card_id",
+		EmojiName:  "speech_balloon",
+	})
+	require.NoError(t, err)
+	reaction := &model.Reaction{UserId: mockUserIDWithNotifications, PostId: "card_id", EmojiName: "speech_balloon"}
+
+	submitAs := func(p *Plugin, headerUserID string, request model.SubmitDialogRequest) *httptest.ResponseRecorder {
+		request.UserId = mockUserIDWithNotifications
+		request.ChannelId = "channel_id"
+		request.State = string(state)
+		body, err := json.Marshal(request)
+		require.NoError(t, err)
+		r := httptest.NewRequest(http.MethodPost, makeAPIRoute(routeIssueCommentDialogSubmit), bytes.NewReader(body))
+		r.Header.Set("Mattermost-User-Id", headerUserID)
+		w := httptest.NewRecorder()
+		_, _ = p.httpSubmitCommentDialog(w, r)
+		return w
+	}
+	submit := func(p *Plugin, request model.SubmitDialogRequest) *httptest.ResponseRecorder {
+		return submitAs(p, mockUserIDWithNotifications, request)
+	}
+
+	t.Run("another user is refused", func(t *testing.T) {
+		p, api := setupCommentReactionTest(t)
+		w := submitAs(p, "someone_else", model.SubmitDialogRequest{Submission: map[string]any{commentDialogElement: "Fixed in the last build"}})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		w = submitAs(p, "", model.SubmitDialogRequest{Cancelled: true})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		api.AssertNotCalled(t, "RemoveReaction", mock.Anything)
+	})
+
+	t.Run("an empty comment is refused", func(t *testing.T) {
+		p, api := setupCommentReactionTest(t)
+		w := submit(p, model.SubmitDialogRequest{Submission: map[string]any{commentDialogElement: "  "}})
+		response := model.SubmitDialogResponse{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Please enter a comment.", response.Errors[commentDialogElement])
+		api.AssertNotCalled(t, "RemoveReaction", mock.Anything)
+	})
+
+	t.Run("cancelling removes the reaction", func(t *testing.T) {
+		p, api := setupCommentReactionTest(t)
+		api.On("RemoveReaction", reaction).Return(nil).Once()
+		w := submit(p, model.SubmitDialogRequest{Cancelled: true})
+		assert.Equal(t, http.StatusOK, w.Code)
+		api.AssertCalled(t, "RemoveReaction", reaction)
+	})
+
+	t.Run("the comment is added, and the reaction removed", func(t *testing.T) {
+		p, api := setupCommentReactionTest(t)
+		api.On("KVGet", keyWithInstanceID(testInstance1.InstanceID, JiraSubscriptionsKey)).Return(nil, nil)
+		api.On("RemoveReaction", reaction).Return(nil).Once()
+		var reply *model.Post
+		api.On("SendEphemeralPost", mockUserIDWithNotifications, mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+			reply = args.Get(1).(*model.Post).Clone()
+		}).Return(func(_ string, post *model.Post) *model.Post { return post.Clone() })
+
+		w := submit(p, model.SubmitDialogRequest{Submission: map[string]any{commentDialogElement: "Fixed in the last build"}})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Body.String())
+		api.AssertCalled(t, "RemoveReaction", reaction)
+		require.NotNil(t, reply)
+		assert.Equal(t, "card_id", reply.RootId)
+		assert.Equal(t, "Commented on [TEST-10](https://jiraurl1.com/browse/TEST-10).", reply.Message)
+	})
+}
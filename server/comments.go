@@ -0,0 +1,132 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	prefixComment = "comment_"
+
+	// jsmPublicCommentProperty is the comment property of Jira Service
+	// Management that tells whether the customers see the comment.
+	jsmPublicCommentProperty = "sd.public.comment"
+
+	projectTypeServiceDesk = "service_desk"
+)
+
+// trackedComment is a Jira comment that the plugin added for a Mattermost
+// user. Only those can be deleted from Mattermost, and only by their author.
+type trackedComment struct {
+	MattermostUserID types.ID `json:"mattermost_user_id"`
+	IssueKey         string   `json:"issue_key"`
+	CommentID        string   `json:"comment_id"`
+}
+
+func commentKey(instanceID types.ID, issueKey, commentID string) string {
+	return hashkey(prefixComment, fmt.Sprintf("%s/%s/%s", instanceID, strings.ToUpper(issueKey), commentID))
+}
+
+func (p *Plugin) trackComment(instanceID, mattermostUserID types.ID, issueKey, commentID string) error {
+	_, err := p.client.KV.Set(commentKey(instanceID, issueKey, commentID), &trackedComment{
+		MattermostUserID: mattermostUserID,
+		IssueKey:         strings.ToUpper(issueKey),
+		CommentID:        commentID,
+	})
+	return err
+}
+
+// loadTrackedComment returns the comment added by the plugin, or nil if the
+// plugin did not add it.
+func (p *Plugin) loadTrackedComment(instanceID types.ID, issueKey, commentID string) (*trackedComment, error) {
+	var comment trackedComment
+	if err := p.client.KV.Get(commentKey(instanceID, issueKey, commentID), &comment); err != nil {
+		return nil, err
+	}
+	if comment.CommentID == "" {
+		return nil, nil
+	}
+	return &comment, nil
+}
+
+// DeleteComment deletes a comment that the plugin added for the user, with
+// their own Jira connection.
+func (p *Plugin) DeleteComment(instanceID, mattermostUserID types.ID, issueKey, commentID string) error {
+	comment, err := p.loadTrackedComment(instanceID, issueKey, commentID)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load the comment")
+	}
+	if comment == nil {
+		return errors.Errorf("comment %s of %s was not added from Mattermost, only those can be deleted here", commentID, strings.ToUpper(issueKey))
+	}
+	if comment.MattermostUserID != mattermostUserID {
+		return errors.New("only the author of the comment can delete it")
+	}
+
+	client, _, _, err := p.getClient(instanceID, mattermostUserID)
+	if err != nil {
+		return err
+	}
+	if err = client.DeleteComment(comment.IssueKey, comment.CommentID); err != nil {
+		return err
+	}
+
+	if err = p.client.KV.Delete(commentKey(instanceID, issueKey, commentID)); err != nil {
+		p.client.Log.Warn("Failed to forget a deleted comment", "issue", comment.IssueKey, "comment", comment.CommentID, "error", err.Error())
+	}
+	return nil
+}
+
+// isServiceDeskIssue tells whether the issue belongs to a Jira Service
+// Management project, where the comments can be internal.
+func isServiceDeskIssue(client Client, issueKey string) (bool, error) {
+	projectKey, _, _ := strings.Cut(issueKey, "-")
+	project := struct {
+		ProjectTypeKey string `json:"projectTypeKey"`
+	}{}
+	if err := client.RESTGet("/2/project/"+projectKey, nil, &project); err != nil {
+		return false, errors.WithMessagef(err, "failed to load the project of %s", issueKey)
+	}
+	return project.ProjectTypeKey == projectTypeServiceDesk, nil
+}
+
+// addCommandComment adds a comment written with `/jira comment`, internal to
+// the agents when asked on a Jira Service Management issue. It returns
+// whether the comment is internal.
+func (p *Plugin) addCommandComment(instance Instance, client Client, mattermostUserID types.ID, issueKey, text string, internal bool) (*jira.Comment, bool, error) {
+	if internal {
+		var err error
+		internal, err = isServiceDeskIssue(client, issueKey)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	comment := &jira.Comment{Body: p.mapMentions(instance.GetID(), text)}
+	var added *jira.Comment
+	var err error
+	if internal {
+		added, err = client.AddInternalComment(issueKey, comment)
+	} else {
+		added, err = client.AddComment(issueKey, comment)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	p.recordSelfChange(instance.GetID(), issueKey, mattermostUserID)
+
+	if added != nil && added.ID != "" {
+		if err = p.trackComment(instance.GetID(), mattermostUserID, issueKey, added.ID); err != nil {
+			p.client.Log.Warn("Failed to keep track of the comment", "issue", issueKey, "comment", added.ID, "error", err.Error())
+		}
+	}
+	return added, internal, nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+func TestDeleteComment(t *testing.T) {
+	instanceID := testInstance1.InstanceID
+	tracked := func(mattermostUserID types.ID, issueKey string) []byte {
+		bb, err := json.Marshal(&trackedComment{MattermostUserID: mattermostUserID, IssueKey: issueKey, CommentID: "10100"})
+		require.NoError(t, err)
+		return bb
+	}
+
+	api := &plugintest.API{}
+	api.On("KVGet", commentKey(instanceID, "NOPE-1", "10100")).Return(nil, nil)
+	api.On("KVGet", commentKey(instanceID, existingIssueKey, "10100")).Return(tracked(mockUserIDWithNotifications, existingIssueKey), nil)
+	api.On("KVGet", commentKey(instanceID, noPermissionsIssueKey, "10100")).Return(tracked(mockUserIDWithNotifications, noPermissionsIssueKey), nil)
+	api.On("KVSetWithOptions", commentKey(instanceID, existingIssueKey, "10100"), []byte(nil), mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil).Once()
+
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.instanceStore = p.getMockInstanceStoreKV(1)
+	p.userStore = getMockUserStoreKV()
+
+	t.Run("comments not added from Mattermost are refused", func(t *testing.T) {
+		err := p.DeleteComment(instanceID, mockUserIDWithNotifications, "nope-1", "10100")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "was not added from Mattermost")
+	})
+
+	t.Run("only the author can delete a comment", func(t *testing.T) {
+		err := p.DeleteComment(instanceID, mockUserIDWithoutNotifications, existingIssueKey, "10100")
+		require.EqualError(t, err, "only the author of the comment can delete it")
+	})
+
+	t.Run("Jira refuses", func(t *testing.T) {
+		err := p.DeleteComment(instanceID, mockUserIDWithNotifications, noPermissionsIssueKey, "10100")
+		require.Error(t, err)
+	})
+
+	t.Run("the comment is deleted, and forgotten", func(t *testing.T) {
+		err := p.DeleteComment(instanceID, mockUserIDWithNotifications, existingIssueKey, "10100")
+		require.NoError(t, err)
+		api.AssertCalled(t, "KVSetWithOptions", commentKey(instanceID, existingIssueKey, "10100"), []byte(nil), model.PluginKVSetOptions{})
+	})
+}
+
+type jsmTestClient struct {
+	testClient
+	projectType string
+	added       map[string]*jira.Comment
+}
+
+func (client jsmTestClient) RESTGet(endpoint string, params map[string]string, dest interface{}) error {
+	return json.Unmarshal([]byte(`{"projectTypeKey":"`+client.projectType+`"}`), dest)
+}
+
+func (client jsmTestClient) AddComment(issueKey string, comment *jira.Comment) (*jira.Comment, error) {
+	client.added["public"] = comment
+	return &jira.Comment{ID: "10200", Body: comment.Body}, nil
+}
+
+func (client jsmTestClient) AddInternalComment(issueKey string, comment *jira.Comment) (*jira.Comment, error) {
+	client.added["internal"] = comment
+	return &jira.Comment{ID: "10201", Body: comment.Body}, nil
+}
+
+func TestAddCommandComment(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("KVSetWithOptions", mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+	api.On("KVGet", keyWithInstanceID(testInstance1.InstanceID, JiraSubscriptionsKey)).Return(nil, nil)
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+
+	for name, tc := range map[string]struct {
+		projectType      string
+		internal         bool
+		expectedInternal bool
+		expectedID       string
+	}{
+		"internal on a service desk":     {projectType: projectTypeServiceDesk, internal: true, expectedInternal: true, expectedID: "10201"},
+		"public on a service desk":       {projectType: projectTypeServiceDesk, internal: false, expectedID: "10200"},
+		"internal on a software project": {projectType: "software", internal: true, expectedID: "10200"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := jsmTestClient{projectType: tc.projectType, added: map[string]*jira.Comment{}}
+			added, internal, err := p.addCommandComment(testInstance1, client, mockUserIDWithNotifications, "HELP-12", "Refund approved, waiting on finance", tc.internal)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedInternal, internal)
+			assert.Equal(t, tc.expectedID, added.ID)
+			assert.Len(t, client.added, 1)
+			api.AssertCalled(t, "KVSetWithOptions", commentKey(testInstance1.InstanceID, "HELP-12", tc.expectedID), mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions"))
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	prefixConnectAll = "connect_all_"
+
+	// connectAllExpiry is how long the list of connect links keeps being
+	// updated as the user connects.
+	connectAllExpiry = time.Hour
+)
+
+// connectAllPost identifies the ephemeral post listing the instances that
+// are left to connect, so that it can be updated as the user connects.
+type connectAllPost struct {
+	PostID    string `json:"post_id"`
+	ChannelID string `json:"channel_id"`
+	RootID    string `json:"root_id,omitempty"`
+}
+
+func executeConnectAll(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	if len(args) != 0 {
+		return p.help(header)
+	}
+
+	info, err := p.GetUserInfo(types.ID(header.UserId), nil)
+	if err != nil {
+		return p.responsef(header, "Failed to connect: "+err.Error())
+	}
+	if info.Instances.IsEmpty() || info.Instances.Len() == 1 {
+		return executeConnect(p, c, header)
+	}
+	if info.connectable.IsEmpty() {
+		return p.responsef(header, "You already have connected all available Jira accounts.")
+	}
+
+	post := &model.Post{
+		UserId:    p.getUserID(),
+		ChannelId: header.ChannelId,
+		RootId:    header.RootId,
+		Message:   p.connectAllMessage(info),
+	}
+	p.client.Post.SendEphemeralPost(header.UserId, post)
+
+	if post.Id != "" {
+		err = p.storeConnectAllPost(types.ID(header.UserId), &connectAllPost{
+			PostID:    post.Id,
+			ChannelID: post.ChannelId,
+			RootID:    post.RootId,
+		})
+		if err != nil {
+			p.client.Log.Warn("Failed to store the connect all post", "error", err.Error())
+		}
+	}
+	return &model.CommandResponse{}
+}
+
+func (p *Plugin) connectAllMessage(info *UserInfo) string {
+	if info.connectable.IsEmpty() {
+		return "You have connected all available Jira accounts."
+	}
+
+	msg := fmt.Sprintf("#### Link your Jira accounts\nYou are not connected to %d of the %d installed Jira instances. Please link them one after the other:\n",
+		info.connectable.Len(), info.Instances.Len())
+	for i, instanceID := range info.connectable.IDs() {
+		label := instanceID.String()
+		if alias := info.Instances.getAlias(instanceID); alias != "" {
+			label = fmt.Sprintf("%s (%s)", alias, instanceID)
+		}
+		msg += fmt.Sprintf("%d. [Link your account on %s](%s%s)\n", i+1, label, p.GetPluginURL(), instancePath(routeUserConnect, instanceID))
+	}
+	return msg
+}
+
+func (p *Plugin) storeConnectAllPost(mattermostUserID types.ID, post *connectAllPost) error {
+	_, err := p.client.KV.Set(prefixConnectAll+mattermostUserID.String(), post, pluginapi.SetExpiry(connectAllExpiry))
+	return err
+}
+
+// updateConnectAllPost removes the instances that the user has connected to
+// from the list sent by `/jira connect all`, if there still is one.
+func (p *Plugin) updateConnectAllPost(mattermostUserID types.ID, info *UserInfo) error {
+	key := prefixConnectAll + mattermostUserID.String()
+	var stored *connectAllPost
+	if err := p.client.KV.Get(key, &stored); err != nil {
+		return errors.WithMessage(err, "failed to load the connect all post")
+	}
+	if stored == nil {
+		return nil
+	}
+
+	p.client.Post.UpdateEphemeralPost(mattermostUserID.String(), &model.Post{
+		Id:        stored.PostID,
+		UserId:    p.getUserID(),
+		ChannelId: stored.ChannelID,
+		RootId:    stored.RootID,
+		Message:   p.connectAllMessage(info),
+	})
+
+	if info.connectable.IsEmpty() {
+		return p.client.KV.Delete(key)
+	}
+	return nil
+}
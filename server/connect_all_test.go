@@ -0,0 +1,39 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectAllMessage(t *testing.T) {
+	p := &Plugin{}
+	p.updateConfig(func(conf *config) {
+		conf.mattermostSiteURL = mattermostSiteURL
+	})
+
+	instances := NewInstances()
+	instances.Set(&InstanceCommon{InstanceID: "https://one.atlassian.net"})
+	instances.Set(&InstanceCommon{InstanceID: "https://two.example.com", Alias: "two"})
+	instances.Set(&InstanceCommon{InstanceID: "https://three.example.com"})
+
+	t.Run("instances left to connect", func(t *testing.T) {
+		connectable := NewInstances()
+		connectable.Set(instances.Get("https://two.example.com"))
+		connectable.Set(instances.Get("https://three.example.com"))
+
+		msg := p.connectAllMessage(&UserInfo{Instances: instances, connectable: connectable})
+		assert.Contains(t, msg, "You are not connected to 2 of the 3 installed Jira instances.")
+		assert.Contains(t, msg, "[Link your account on two (https://two.example.com)]("+p.GetPluginURL()+instancePath(routeUserConnect, "https://two.example.com")+")")
+		assert.Contains(t, msg, "[Link your account on https://three.example.com]")
+		assert.NotContains(t, msg, "one.atlassian.net")
+	})
+
+	t.Run("all connected", func(t *testing.T) {
+		msg := p.connectAllMessage(&UserInfo{Instances: instances, connectable: NewInstances()})
+		assert.Equal(t, "You have connected all available Jira accounts.", msg)
+	})
+}
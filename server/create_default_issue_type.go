@@ -0,0 +1,158 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	prefixChannelDefaultIssueType = "channel_default_issue_type_"
+
+	defaultIssueTypeNone = "none"
+)
+
+// channelDefaultIssueType returns the name of the issue type that the issues
+// created in the channel have by default, or "".
+func (p *Plugin) channelDefaultIssueType(channelID string) string {
+	if channelID == "" {
+		return ""
+	}
+
+	var name string
+	err := p.client.KV.Get(prefixChannelDefaultIssueType+channelID, &name)
+	if err != nil {
+		p.client.Log.Warn("Failed to load the default issue type of the channel", "ChannelID", channelID, "Error", err.Error())
+		return ""
+	}
+	return name
+}
+
+func (p *Plugin) setChannelDefaultIssueType(channelID, name string) error {
+	if name == "" {
+		return p.client.KV.Delete(prefixChannelDefaultIssueType + channelID)
+	}
+	_, err := p.client.KV.Set(prefixChannelDefaultIssueType+channelID, name)
+	return err
+}
+
+// defaultIssueType returns the name of the issue type that `/jira create`
+// picks when none is given: that of the channel, or else that of the instance.
+func (p *Plugin) defaultIssueType(instance Instance, channelID string) string {
+	if name := p.channelDefaultIssueType(channelID); name != "" {
+		return name
+	}
+	return instance.Common().DefaultIssueType
+}
+
+// resolveDefaultIssueType returns the ID of the issue type of the project
+// with the name, if it can be created in the project, or else a note to
+// choose another one. Sub-task issue types are left out.
+func resolveDefaultIssueType(metaInfo *jira.CreateMetaInfo, projectKey, name string) (id, note string) {
+	if name == "" || metaInfo == nil {
+		return "", ""
+	}
+	for _, project := range metaInfo.Projects {
+		if !strings.EqualFold(project.Key, projectKey) {
+			continue
+		}
+		for _, it := range project.IssueTypes {
+			if !it.Subtasks && strings.EqualFold(it.Name, name) {
+				return it.Id, ""
+			}
+		}
+	}
+	return "", fmt.Sprintf("The default issue type %q cannot be created in %s, please choose another one.", name, projectKey)
+}
+
+// applyDefaultIssueType sets the default issue type of the create modal, when
+// it is for a single project.
+func (p *Plugin) applyDefaultIssueType(instanceID types.ID, channelID, projectKeys string, cimd *CreateMetaInfo) {
+	if strings.Contains(projectKeys, ",") {
+		return
+	}
+	instance, err := p.instanceStore.LoadInstance(instanceID)
+	if err != nil {
+		return
+	}
+	cimd.DefaultIssueTypeID, cimd.DefaultIssueTypeNote = resolveDefaultIssueType(cimd.CreateMetaInfo, projectKeys, p.defaultIssueType(instance, channelID))
+}
+
+func executeInstanceSetIssueType(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	authorized, err := authorizedSysAdmin(p, header.UserId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira instance set-issue-type` can only be run by a system administrator.")
+	}
+	if len(args) < 2 {
+		return p.responsef(header, "Please specify a Jira instance and an issue type, `/jira instance set-issue-type [jiraURL] [issue type]`, or `none` instead of the issue type to remove it.")
+	}
+
+	instances, err := p.instanceStore.LoadInstances()
+	if err != nil {
+		return p.responsef(header, "Failed to load instances. Error: %v.", err)
+	}
+	instanceID := types.ID(args[0])
+	if found := instances.getByAlias(args[0]); found != nil {
+		instanceID = found.InstanceID
+	}
+	instance, err := p.instanceStore.LoadInstance(instanceID)
+	if err != nil {
+		return p.responsef(header, "Failed to load instance. Error: %v.", err)
+	}
+
+	name := strings.TrimSpace(strings.Join(args[1:], " "))
+	if strings.EqualFold(name, defaultIssueTypeNone) {
+		name = ""
+	}
+	instance.Common().DefaultIssueType = name
+	if err = p.instanceStore.StoreInstance(instance); err != nil {
+		return p.responsef(header, "Failed to save instance. Error: %v.", err)
+	}
+
+	if name == "" {
+		return p.responsef(header, "`/jira create` on %s no longer picks an issue type.", instanceID)
+	}
+	return p.responsef(header, "`/jira create` on %s now picks the %q issue type, in the projects that have it, unless the channel has its own.", instanceID, name)
+}
+
+func executeChannelDefaultIssueType(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	usage := "Please use `/jira channel default-issue-type [issue type|none]`."
+	if len(args) == 0 {
+		name := p.channelDefaultIssueType(header.ChannelId)
+		if name == "" {
+			return p.responsef(header, "Issues created in this channel have the default issue type of the Jira instance, if any. %s", usage)
+		}
+		return p.responsef(header, "Issues created in this channel are of the %q issue type by default. %s", name, usage)
+	}
+
+	authorized, err := p.canManageChannelReadOnly(header.UserId, header.ChannelId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira channel default-issue-type` can only be run by a channel or system administrator.")
+	}
+
+	name := strings.TrimSpace(strings.Join(args, " "))
+	if strings.EqualFold(name, defaultIssueTypeNone) {
+		name = ""
+	}
+	if err = p.setChannelDefaultIssueType(header.ChannelId, name); err != nil {
+		return p.responsef(header, "Failed to update the channel. Error: %v.", err)
+	}
+	if name == "" {
+		return p.responsef(header, "Issues created in this channel now have the default issue type of the Jira instance, if any.")
+	}
+	return p.responsef(header, "Issues created in this channel are now of the %q issue type by default, in the projects that have it.", name)
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveDefaultIssueType(t *testing.T) {
+	metaInfo := &jira.CreateMetaInfo{
+		Projects: []*jira.MetaProject{{
+			Key: "BUG",
+			IssueTypes: []*jira.MetaIssueType{
+				{Id: "10001", Name: "Task"},
+				{Id: "10002", Name: "Bug"},
+				{Id: "10003", Name: "Sub-task", Subtasks: true},
+			},
+		}},
+	}
+
+	id, note := resolveDefaultIssueType(metaInfo, "BUG", "bug")
+	assert.Equal(t, "10002", id)
+	assert.Empty(t, note)
+
+	id, note = resolveDefaultIssueType(metaInfo, "BUG", "")
+	assert.Empty(t, id)
+	assert.Empty(t, note)
+
+	for _, name := range []string{"Story", "Sub-task"} {
+		id, note = resolveDefaultIssueType(metaInfo, "BUG", name)
+		assert.Empty(t, id, name)
+		assert.Contains(t, note, "cannot be created in BUG", name)
+	}
+
+	id, note = resolveDefaultIssueType(metaInfo, "OTHER", "Bug")
+	assert.Empty(t, id)
+	assert.Contains(t, note, "cannot be created in OTHER")
+}
+
+func TestDefaultIssueType(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("KVGet", prefixChannelDefaultIssueType+"channel-with-default").Return([]byte(`"Task"`), nil)
+	api.On("KVGet", prefixChannelDefaultIssueType+"other-channel").Return(nil, nil)
+
+	p := Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+
+	instance := &testInstance{InstanceCommon: InstanceCommon{DefaultIssueType: "Bug"}}
+	assert.Equal(t, "Task", p.defaultIssueType(instance, "channel-with-default"))
+	assert.Equal(t, "Bug", p.defaultIssueType(instance, "other-channel"))
+	assert.Equal(t, "Bug", p.defaultIssueType(instance, ""))
+}
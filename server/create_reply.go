@@ -0,0 +1,154 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	prefixChannelCreateReply = "channel_create_reply_"
+
+	// createReplyDefault shows the card of a created issue to its creator,
+	// and announces it to the channel with a link.
+	createReplyDefault = "default"
+	// createReplyEphemeral only shows the card to the creator.
+	createReplyEphemeral = "ephemeral"
+	// createReplyPublic posts the card to the channel.
+	createReplyPublic = "public"
+)
+
+// channelCreateReply returns how the issues created in the channel are
+// announced.
+func (p *Plugin) channelCreateReply(channelID string) string {
+	if channelID == "" {
+		return createReplyDefault
+	}
+
+	var mode string
+	err := p.client.KV.Get(prefixChannelCreateReply+channelID, &mode)
+	if err != nil {
+		p.client.Log.Warn("Failed to load how created issues are announced in the channel", "ChannelID", channelID, "Error", err.Error())
+		return createReplyDefault
+	}
+	switch mode {
+	case createReplyEphemeral, createReplyPublic:
+		return mode
+	default:
+		return createReplyDefault
+	}
+}
+
+func (p *Plugin) setChannelCreateReply(channelID, mode string) error {
+	if mode == createReplyDefault {
+		return p.client.KV.Delete(prefixChannelCreateReply + channelID)
+	}
+	_, err := p.client.KV.Set(prefixChannelCreateReply+channelID, mode)
+	return err
+}
+
+// replyToCreatedIssue tells the creator of an issue that it was created, and,
+// as the channel is set up, announces it to the channel. Issues with a
+// security level are not announced if their security level is kept out of
+// subscriptions.
+func (p *Plugin) replyToCreatedIssue(instance Instance, mattermostUserID types.ID, channelID, rootID string, issue *jira.Issue, attachment []*model.SlackAttachment, warning string) error {
+	botUserID := instance.Common().getConfig().botUserID
+	mode := p.channelCreateReply(channelID)
+	if mode != createReplyEphemeral && p.getConfig().SecurityLevelEmptyForJiraSubscriptions && getIssueFieldValue(issue, securityLevelField).Len() > 0 {
+		mode = createReplyEphemeral
+		warning += fmt.Sprintf("\n%s has a security level, so it was not posted to the channel.", issue.Key)
+	}
+
+	if mode == createReplyPublic {
+		post := &model.Post{
+			Message:   fmt.Sprintf("Created a Jira issue: %s", mdKeySummaryLink(issue, instance)),
+			ChannelId: channelID,
+			RootId:    rootID,
+			UserId:    mattermostUserID.String(),
+		}
+		post.AddProp("attachments", attachment)
+		setIssuePostProps(post, instance.GetID(), issue.Key)
+		if err := p.client.Post.CreatePost(post); err != nil {
+			return err
+		}
+		if warning != "" {
+			p.client.Post.SendEphemeralPost(mattermostUserID.String(), &model.Post{
+				Message:   fmt.Sprintf("Created Jira issue %s.%s", issue.Key, warning),
+				ChannelId: channelID,
+				RootId:    threadRootID(post, rootID),
+				UserId:    botUserID,
+			})
+		}
+		return nil
+	}
+
+	// Reply with an ephemeral post with the Jira issue formatted as slack attachment.
+	reply := &model.Post{
+		Message:   fmt.Sprintf("Created Jira issue [%s](%s/browse/%s)", issue.Key, instance.GetJiraBaseURL(), issue.Key) + warning,
+		ChannelId: channelID,
+		RootId:    rootID,
+		UserId:    botUserID,
+	}
+	reply.AddProp("attachments", attachment)
+	p.client.Post.SendEphemeralPost(mattermostUserID.String(), reply)
+
+	if mode == createReplyEphemeral {
+		return nil
+	}
+
+	// Create a public post for all the channel members
+	publicReply := &model.Post{
+		Message:   fmt.Sprintf("Created a Jira issue: %s", mdKeySummaryLink(issue, instance)),
+		ChannelId: channelID,
+		RootId:    rootID,
+		UserId:    mattermostUserID.String(),
+	}
+	setIssuePostProps(publicReply, instance.GetID(), issue.Key)
+	return p.client.Post.CreatePost(publicReply)
+}
+
+// threadRootID returns the thread of a post: its root, or the post itself if it
+// starts one.
+func threadRootID(post *model.Post, rootID string) string {
+	if rootID != "" {
+		return rootID
+	}
+	return post.Id
+}
+
+func executeChannelCreateReply(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	usage := "Please use `/jira channel create-reply [default|ephemeral|public]`."
+	if len(args) == 0 {
+		return p.responsef(header, "Issues created in this channel are announced with the **%s** reply. %s", p.channelCreateReply(header.ChannelId), usage)
+	}
+	if len(args) != 1 || (args[0] != createReplyDefault && args[0] != createReplyEphemeral && args[0] != createReplyPublic) {
+		return p.responsef(header, usage)
+	}
+
+	authorized, err := p.canManageChannelReadOnly(header.UserId, header.ChannelId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira channel create-reply` can only be run by a channel or system administrator.")
+	}
+
+	if err = p.setChannelCreateReply(header.ChannelId, args[0]); err != nil {
+		return p.responsef(header, "Failed to update the channel. Error: %v.", err)
+	}
+	switch args[0] {
+	case createReplyEphemeral:
+		return p.responsef(header, "Issues created in this channel are now only shown to their creator.")
+	case createReplyPublic:
+		return p.responsef(header, "Issues created in this channel are now posted to the channel as a card.")
+	default:
+		return p.responsef(header, "Issues created in this channel are now shown to their creator, and announced to the channel with a link.")
+	}
+}
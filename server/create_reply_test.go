@@ -0,0 +1,117 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplyToCreatedIssue(t *testing.T) {
+	created := "Created Jira issue [TEST-1](https://jiraurl1.com/browse/TEST-1)"
+	announced := "Created a Jira issue: [TEST-1: Fix the build (To Do)](https://jiraurl1.com/browse/TEST-1)"
+
+	for name, tc := range map[string]struct {
+		mode              string
+		securityLevel     bool
+		hideSecurityLevel bool
+		expectedEphemeral string
+		expectedPublic    string
+		expectPublicCard  bool
+	}{
+		"default": {
+			expectedEphemeral: created,
+			expectedPublic:    announced,
+		},
+		"ephemeral": {
+			mode:              createReplyEphemeral,
+			expectedEphemeral: created,
+		},
+		"public": {
+			mode:             createReplyPublic,
+			expectedPublic:   announced,
+			expectPublicCard: true,
+		},
+		"public with a security level": {
+			mode:             createReplyPublic,
+			securityLevel:    true,
+			expectedPublic:   announced,
+			expectPublicCard: true,
+		},
+		"public with a hidden security level": {
+			mode:              createReplyPublic,
+			securityLevel:     true,
+			hideSecurityLevel: true,
+			expectedEphemeral: created + "\nTEST-1 has a security level, so it was not posted to the channel.",
+		},
+		"default with a hidden security level": {
+			securityLevel:     true,
+			hideSecurityLevel: true,
+			expectedEphemeral: created + "\nTEST-1 has a security level, so it was not posted to the channel.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := &Plugin{}
+			p.updateConfig(func(conf *config) {
+				conf.botUserID = "bot_id"
+				conf.SecurityLevelEmptyForJiraSubscriptions = tc.hideSecurityLevel
+			})
+
+			api := &plugintest.API{}
+			var value []byte
+			if tc.mode != "" {
+				value = []byte(`"` + tc.mode + `"`)
+			}
+			api.On("KVGet", prefixChannelCreateReply+"channel_id").Return(value, nil)
+			ephemeral := ""
+			api.On("SendEphemeralPost", "user_id", mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+				post := args.Get(1).(*model.Post)
+				ephemeral = post.Message
+				assert.Equal(t, "bot_id", post.UserId)
+				assert.NotEmpty(t, post.Attachments())
+			}).Return(&model.Post{})
+			public := []*model.Post{}
+			api.On("CreatePost", mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+				public = append(public, args.Get(0).(*model.Post).Clone())
+			}).Return(func(post *model.Post) *model.Post { return post.Clone() }, nil)
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+
+			instance := *testInstance1
+			instance.Plugin = p
+			issue := &jira.Issue{
+				Key: "TEST-1",
+				Fields: &jira.IssueFields{
+					Summary:  "Fix the build",
+					Status:   &jira.Status{Name: "To Do"},
+					Unknowns: map[string]interface{}{},
+				},
+			}
+			if tc.securityLevel {
+				issue.Fields.Unknowns[securityLevelField] = map[string]interface{}{"id": "10000", "name": "Internal"}
+			}
+			card := []*model.SlackAttachment{{Text: "card"}}
+
+			err := p.replyToCreatedIssue(&instance, "user_id", "channel_id", "", issue, card, "")
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedEphemeral, ephemeral)
+			if tc.expectedPublic == "" {
+				assert.Empty(t, public)
+				return
+			}
+			require.Len(t, public, 1)
+			assert.Equal(t, tc.expectedPublic, public[0].Message)
+			assert.Equal(t, "user_id", public[0].UserId)
+			assert.Equal(t, tc.expectPublicCard, len(public[0].Attachments()) > 0)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+)
+
+const (
+	userSearchAllRoute = "2/user/search"
+
+	permissionModifyReporter = "MODIFY_REPORTER"
+)
+
+type myPermissions struct {
+	Permissions map[string]struct {
+		HavePermission bool `json:"havePermission"`
+	} `json:"permissions"`
+}
+
+// hasProjectPermission tells whether the user of the client has the Jira
+// permission, e.g. MODIFY_REPORTER, in the project.
+func hasProjectPermission(client Client, projectKey, permission string) (bool, error) {
+	result := myPermissions{}
+	err := client.RESTGet("2/mypermissions", map[string]string{
+		"projectKey":  projectKey,
+		"permissions": permission,
+	}, &result)
+	if err != nil {
+		return false, err
+	}
+	return result.Permissions[permission].HavePermission, nil
+}
+
+// searchJiraUsers finds the active Jira users by name, username or email
+// address, whether they can be assigned issues or not, e.g. the customers.
+func searchJiraUsers(client Client, instance Instance, query string, maxResults int) ([]jira.User, error) {
+	// Jira Cloud only searches by query, Jira Server by username.
+	queryKey := "username"
+	if instance.Common().IsCloudInstance() {
+		queryKey = "query"
+	}
+	users := []jira.User{}
+	err := client.RESTGet(userSearchAllRoute, map[string]string{
+		queryKey:     query,
+		"maxResults": strconv.Itoa(maxResults),
+	}, &users)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// resolveCreateReporter returns the Jira user that the new issue is reported
+// by: the Jira user of a Mattermost user, e.g. `@name`, or the Jira user with
+// the username or the email address. The reporter can only be set by the users
+// with the Modify Reporter permission in the project.
+func (p *Plugin) resolveCreateReporter(instance Instance, client Client, projectKey, reporter string) (*jira.User, error) {
+	reporter = strings.TrimSpace(reporter)
+	allowed, err := hasProjectPermission(client, projectKey, permissionModifyReporter)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to check whether you can set the reporter of the issues of %s", projectKey)
+	}
+	if !allowed {
+		return nil, errors.Errorf("you do not have the Modify Reporter permission in %s", projectKey)
+	}
+
+	if strings.HasPrefix(reporter, "@") {
+		return p.resolveCreateAssignee(instance.GetID(), reporter)
+	}
+
+	if len(reporter) < MinUserSearchQueryLength {
+		return nil, errors.Errorf("`%s` contains less than %v characters", reporter, MinUserSearchQueryLength)
+	}
+	users, err := searchJiraUsers(client, instance, reporter, 10)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to find %s in Jira", reporter)
+	}
+	if exact := findExactJiraUser(users, reporter); exact != nil {
+		users = []jira.User{*exact}
+	}
+	switch {
+	case len(users) == 0:
+		return nil, errors.Errorf("%s was not found in Jira", reporter)
+	case len(users) > 1:
+		return nil, errors.Errorf("%s matches %d or more Jira users, please use their Jira username or email address", reporter, len(users))
+	}
+
+	// Jira Cloud only accepts the account ID, Jira Server only the username.
+	if users[0].AccountID != "" {
+		return &jira.User{AccountID: users[0].AccountID}, nil
+	}
+	return &jira.User{Name: users[0].Name}, nil
+}
+
+// mdReporterWarning explains why the issue was reported by its creator
+// rather than by the reporter they asked for.
+func mdReporterWarning(reporter, reason string) string {
+	return fmt.Sprintf("\nThe issue is reported by you rather than by %s: %s.", reporter, reason)
+}
@@ -0,0 +1,325 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	settingCustomStatus = "custom-status"
+
+	customStatusMatchStatus = "status"
+	customStatusMatchLabel  = "label"
+)
+
+// customStatusEvents are the events after which the custom status of the
+// assignee of the issue is updated: the issue was created for or assigned to
+// them, or its status or labels changed.
+var customStatusEvents = NewStringSet(eventCreated, eventUpdatedAssignee, eventUpdatedStatus,
+	eventUpdatedLabels, eventUpdatedReopened, eventUpdatedResolved)
+
+// customStatusRule maps the issues with a status, or with a label, to the
+// Mattermost custom status of their assignee, e.g. the label incident to
+// :rotating_light: On incident.
+type customStatusRule struct {
+	Match string `json:"match"`
+	Value string `json:"value"`
+	Emoji string `json:"emoji"`
+	Text  string `json:"text"`
+}
+
+// key identifies the issues a rule selects, whatever the case and separators
+// of its value.
+func (r customStatusRule) key() string {
+	return r.Match + ":" + normalizeCustomStatusValue(r.Value)
+}
+
+func (r customStatusRule) String() string {
+	return fmt.Sprintf("`%s:%s` → :%s: %s", r.Match, r.Value, r.Emoji, r.Text)
+}
+
+// normalizeCustomStatusValue makes `In_Progress` and `in-progress` match the
+// `In Progress` status, as the command arguments cannot contain spaces.
+func normalizeCustomStatusValue(s string) string {
+	return strings.ToLower(strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-'
+	}), " "))
+}
+
+func (r customStatusRule) matches(issue *jira.Issue) bool {
+	if issue.Fields == nil {
+		return false
+	}
+	value := normalizeCustomStatusValue(r.Value)
+	switch r.Match {
+	case customStatusMatchStatus:
+		return issue.Fields.Status != nil && normalizeCustomStatusValue(issue.Fields.Status.Name) == value
+	case customStatusMatchLabel:
+		for _, label := range issue.Fields.Labels {
+			if normalizeCustomStatusValue(label) == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r customStatusRule) isCustomStatus(cs *model.CustomStatus) bool {
+	return cs != nil && cs.Emoji == r.Emoji && cs.Text == r.Text
+}
+
+// parseCustomStatusMatch parses `status:<name>` or `label:<name>`.
+func parseCustomStatusMatch(s string) (match, value string, err error) {
+	match, value, ok := strings.Cut(strings.TrimSpace(s), ":")
+	match = strings.ToLower(strings.TrimSpace(match))
+	value = strings.TrimSpace(value)
+	if !ok || value == "" || (match != customStatusMatchStatus && match != customStatusMatchLabel) {
+		return "", "", errors.Errorf("invalid match %q, expected `status:<name>` or `label:<name>`", s)
+	}
+	return match, value, nil
+}
+
+// newCustomStatusRule returns the rule setting the custom status with the
+// emoji, e.g. `:rotating_light:`, and the text to the issues the match,
+// e.g. `label:incident`, selects.
+func newCustomStatusRule(match, emoji, text string) (customStatusRule, error) {
+	kind, value, err := parseCustomStatusMatch(match)
+	if err != nil {
+		return customStatusRule{}, err
+	}
+	emoji = strings.TrimSpace(emoji)
+	if len(emoji) < 3 || !strings.HasPrefix(emoji, ":") || !strings.HasSuffix(emoji, ":") {
+		return customStatusRule{}, errors.Errorf("invalid emoji %q, expected the form `:emoji_name:`", emoji)
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return customStatusRule{}, errors.Errorf("the custom status of %s has no text", match)
+	}
+	if utf8.RuneCountInString(text) > model.CustomStatusTextMaxRunes {
+		return customStatusRule{}, errors.Errorf("the custom status of %s is longer than %d characters", match, model.CustomStatusTextMaxRunes)
+	}
+	return customStatusRule{
+		Match: kind,
+		Value: value,
+		Emoji: strings.Trim(emoji, ":"),
+		Text:  text,
+	}, nil
+}
+
+// parseCustomStatuses parses the JiraCustomStatuses setting, e.g.
+// "label:incident=:rotating_light: On incident, status:In Review=:eyes: Reviewing".
+func parseCustomStatuses(setting string) ([]customStatusRule, error) {
+	rules := []customStatusRule{}
+	for _, pair := range strings.Split(setting, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		match, status, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid custom status %q, expected the form `label:<name>=:emoji: text` or `status:<name>=:emoji: text`", pair)
+		}
+		emoji, text, _ := strings.Cut(strings.TrimSpace(status), " ")
+		rule, err := newCustomStatusRule(match, emoji, text)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// customStatusRules returns the rules of the user, which come first, and then
+// those of the admins that the user did not replace.
+func (p *Plugin) customStatusRules(settings *ConnectionSettings) []customStatusRule {
+	rules := []customStatusRule{}
+	own := map[string]bool{}
+	if settings != nil {
+		for _, rule := range settings.CustomStatuses {
+			rules = append(rules, rule)
+			own[rule.key()] = true
+		}
+	}
+	for _, rule := range p.getConfig().customStatuses {
+		if !own[rule.key()] {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// updateAssigneeCustomStatus sets the custom status of the assignee of the
+// issue of the event to the first rule that the issue matches, if the
+// assignee opted in. The custom status is left alone when no rule matches,
+// the user may be working on another issue that does.
+func (p *Plugin) updateAssigneeCustomStatus(instanceID types.ID, wh *webhook) {
+	if wh.Issue.Fields == nil || wh.Issue.Fields.Assignee == nil || !wh.eventTypes.ContainsAny(customStatusEvents.Elems()...) {
+		return
+	}
+	jiraUserID := wh.Issue.Fields.Assignee.AccountID
+	if jiraUserID == "" {
+		jiraUserID = wh.Issue.Fields.Assignee.Name
+	}
+	if jiraUserID == "" {
+		return
+	}
+	mattermostUserID, err := p.userStore.LoadMattermostUserID(instanceID, jiraUserID)
+	if err != nil {
+		return
+	}
+	c, err := p.userStore.LoadConnection(instanceID, mattermostUserID)
+	if err != nil || c.Settings == nil || !c.Settings.CustomStatus {
+		return
+	}
+
+	for _, rule := range p.customStatusRules(c.Settings) {
+		if !rule.matches(&wh.Issue) {
+			continue
+		}
+		if user, err := p.client.User.Get(mattermostUserID.String()); err == nil && rule.isCustomStatus(user.GetCustomStatus()) {
+			return
+		}
+		if appErr := p.API.UpdateUserCustomStatus(mattermostUserID.String(), &model.CustomStatus{Emoji: rule.Emoji, Text: rule.Text}); appErr != nil {
+			p.client.Log.Warn("Failed to update the custom status of the assignee", "IssueKey", wh.Issue.Key, "UserID", mattermostUserID.String(), "Error", appErr.Error())
+		}
+		return
+	}
+}
+
+// clearCustomStatus removes the custom status of the user if one of the rules
+// set it, and reports whether it did. A custom status the user set is kept.
+func (p *Plugin) clearCustomStatus(mattermostUserID types.ID, settings *ConnectionSettings) (bool, error) {
+	user, err := p.client.User.Get(mattermostUserID.String())
+	if err != nil {
+		return false, err
+	}
+	current := user.GetCustomStatus()
+	for _, rule := range p.customStatusRules(settings) {
+		if rule.isCustomStatus(current) {
+			if appErr := p.API.RemoveUserCustomStatus(mattermostUserID.String()); appErr != nil {
+				return false, appErr
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *Plugin) settingsCustomStatus(header *model.CommandArgs, instanceID, mattermostUserID types.ID, connection *Connection, args []string) *model.CommandResponse {
+	const helpText = "`/jira settings custom-status [value]`\n" +
+		"* `on` or `off` turns on or off setting your custom status from the issues assigned to you\n" +
+		"* `set <status:name|label:name> <:emoji:> <text>` maps the issues with a status, or a label, to a custom status, e.g. `set label:incident :rotating_light: On incident`. Write the spaces of a status as `_`, e.g. `status:In_Progress`\n" +
+		"* `unset <status:name|label:name>` removes one of your mappings\n" +
+		"* `clear` removes the custom status set from Jira"
+
+	if connection.Settings == nil {
+		connection.Settings = &ConnectionSettings{}
+	}
+	if len(args) < 2 {
+		return p.responsef(header, "%s", p.customStatusSummary(connection.Settings)+"\n\n"+helpText)
+	}
+
+	msg := ""
+	switch args[1] {
+	case settingOn, settingOff:
+		if len(args) != 2 {
+			return p.responsef(header, helpText)
+		}
+		connection.Settings.CustomStatus = args[1] == settingOn
+		msg = "Settings updated. Custom status from Jira " + args[1] + "."
+	case "set":
+		if len(args) < 5 {
+			return p.responsef(header, helpText)
+		}
+		rule, err := newCustomStatusRule(args[2], args[3], strings.Join(args[4:], " "))
+		if err != nil {
+			return p.responsef(header, "%v.\n%s", err, helpText)
+		}
+		rules := []customStatusRule{rule}
+		for _, r := range connection.Settings.CustomStatuses {
+			if r.key() != rule.key() {
+				rules = append(rules, r)
+			}
+		}
+		connection.Settings.CustomStatuses = rules
+		msg = "Settings updated. Mapped " + rule.String() + "."
+		if !connection.Settings.CustomStatus {
+			msg += " Use `/jira settings custom-status on` to have your custom status set from Jira."
+		}
+	case "unset":
+		if len(args) != 3 {
+			return p.responsef(header, helpText)
+		}
+		match, value, err := parseCustomStatusMatch(args[2])
+		if err != nil {
+			return p.responsef(header, "%v.\n%s", err, helpText)
+		}
+		key := customStatusRule{Match: match, Value: value}.key()
+		rules := []customStatusRule{}
+		for _, r := range connection.Settings.CustomStatuses {
+			if r.key() != key {
+				rules = append(rules, r)
+			}
+		}
+		if len(rules) == len(connection.Settings.CustomStatuses) {
+			return p.responsef(header, "You have no mapping for `%s`.", args[2])
+		}
+		connection.Settings.CustomStatuses = rules
+		msg = fmt.Sprintf("Settings updated. Removed your mapping for `%s`.", args[2])
+	case "clear":
+		if len(args) != 2 {
+			return p.responsef(header, helpText)
+		}
+		cleared, err := p.clearCustomStatus(mattermostUserID, connection.Settings)
+		if err != nil {
+			return p.responsef(header, "Failed to clear your custom status. Error: %v.", err)
+		}
+		if !cleared {
+			return p.responsef(header, "Your custom status was not set from Jira, it is kept.")
+		}
+		return p.responsef(header, "Your custom status was cleared.")
+	default:
+		return p.responsef(header, helpText)
+	}
+
+	if err := p.userStore.StoreConnection(instanceID, mattermostUserID, connection); err != nil {
+		p.errorf("settingsCustomStatus, err: %v", err)
+		return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
+	}
+	return p.responsef(header, "%s", msg)
+}
+
+// customStatusSummary lists the rules that apply to the user, in order.
+func (p *Plugin) customStatusSummary(settings *ConnectionSettings) string {
+	state := settingOff
+	if settings.CustomStatus {
+		state = settingOn
+	}
+	summary := "Custom status from Jira: " + state
+	rules := p.customStatusRules(settings)
+	if len(rules) == 0 {
+		return summary + "\nNo issue is mapped to a custom status."
+	}
+	own := map[string]bool{}
+	for _, rule := range settings.CustomStatuses {
+		own[rule.key()] = true
+	}
+	for _, rule := range rules {
+		summary += "\n* " + rule.String()
+		if !own[rule.key()] {
+			summary += " (set by your system administrator)"
+		}
+	}
+	return summary
+}
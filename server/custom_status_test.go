@@ -0,0 +1,116 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCustomStatuses(t *testing.T) {
+	rules, err := parseCustomStatuses(" label:incident=:rotating_light: On incident, status:In Review=:eyes: Reviewing ,")
+	require.NoError(t, err)
+	assert.Equal(t, []customStatusRule{
+		{Match: customStatusMatchLabel, Value: "incident", Emoji: "rotating_light", Text: "On incident"},
+		{Match: customStatusMatchStatus, Value: "In Review", Emoji: "eyes", Text: "Reviewing"},
+	}, rules)
+
+	for _, setting := range []string{
+		"incident=:rotating_light: On incident",
+		"label:incident",
+		"priority:High=:fire: On fire",
+		"label:incident=rotating_light On incident",
+		"label:incident=:rotating_light:",
+	} {
+		_, err = parseCustomStatuses(setting)
+		assert.Error(t, err, setting)
+	}
+}
+
+func TestCustomStatusRuleMatches(t *testing.T) {
+	issue := &jira.Issue{Fields: &jira.IssueFields{
+		Status: &jira.Status{Name: "In Progress"},
+		Labels: []string{"Customer", "incident"},
+	}}
+	assert.True(t, customStatusRule{Match: customStatusMatchStatus, Value: "in_progress"}.matches(issue))
+	assert.True(t, customStatusRule{Match: customStatusMatchLabel, Value: "INCIDENT"}.matches(issue))
+	assert.False(t, customStatusRule{Match: customStatusMatchStatus, Value: "incident"}.matches(issue))
+	assert.False(t, customStatusRule{Match: customStatusMatchLabel, Value: "outage"}.matches(issue))
+}
+
+func TestUpdateAssigneeCustomStatus(t *testing.T) {
+	incident := customStatusRule{Match: customStatusMatchLabel, Value: "incident", Emoji: "rotating_light", Text: "On incident"}
+	mine := customStatusRule{Match: customStatusMatchLabel, Value: "incident", Emoji: "fire", Text: "Firefighting"}
+
+	for name, tc := range map[string]struct {
+		settings  *ConnectionSettings
+		eventType string
+		current   *model.CustomStatus
+		expected  *model.CustomStatus
+	}{
+		"assigned an issue mapped by the admins": {
+			settings:  &ConnectionSettings{CustomStatus: true},
+			eventType: eventUpdatedAssignee,
+			expected:  &model.CustomStatus{Emoji: "rotating_light", Text: "On incident"},
+		},
+		"the mapping of the user comes first": {
+			settings:  &ConnectionSettings{CustomStatus: true, CustomStatuses: []customStatusRule{mine}},
+			eventType: eventCreated,
+			expected:  &model.CustomStatus{Emoji: "fire", Text: "Firefighting"},
+		},
+		"not opted in": {
+			settings:  &ConnectionSettings{CustomStatuses: []customStatusRule{mine}},
+			eventType: eventUpdatedAssignee,
+		},
+		"already set": {
+			settings:  &ConnectionSettings{CustomStatus: true},
+			eventType: eventUpdatedLabels,
+			current:   &model.CustomStatus{Emoji: "rotating_light", Text: "On incident"},
+		},
+		"another change": {
+			settings:  &ConnectionSettings{CustomStatus: true},
+			eventType: eventUpdatedDescription,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			user := &model.User{Id: "mm-alice"}
+			if tc.current != nil {
+				require.NoError(t, user.SetCustomStatus(tc.current))
+			}
+			api.On("GetUser", "mm-alice").Return(user, nil)
+			if tc.expected != nil {
+				api.On("UpdateUserCustomStatus", "mm-alice", tc.expected).Return(nil).Once()
+			}
+
+			p := &Plugin{}
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+			p.updateConfig(func(conf *config) {
+				conf.customStatuses = []customStatusRule{incident}
+			})
+			p.userStore = subscriptionMatchUserStore{connections: map[string]*Connection{
+				"alice": {User: jira.User{AccountID: "alice"}, Settings: tc.settings},
+			}}
+
+			wh := subscriptionMatchWebhook(&jira.User{AccountID: "alice"}, nil)
+			wh.eventTypes = NewStringSet(tc.eventType)
+			wh.Issue.Fields.Labels = []string{"incident"}
+			p.updateAssigneeCustomStatus(testInstance1.InstanceID, wh)
+
+			if tc.expected != nil {
+				api.AssertCalled(t, "UpdateUserCustomStatus", "mm-alice", tc.expected)
+			} else {
+				api.AssertNotCalled(t, "UpdateUserCustomStatus", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
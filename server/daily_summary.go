@@ -0,0 +1,250 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/kvstore"
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	settingDailySummary = "daily-summary"
+
+	dailySummaryInterval = 5 * time.Minute
+
+	dailySummaryJQL        = "assignee = currentUser() AND statusCategory != Done ORDER BY status ASC, updated DESC"
+	dailySummaryMaxResults = 50
+
+	// dailySummaryDateLayout is how the day of the last summary is stored,
+	// in the user's timezone.
+	dailySummaryDateLayout = "2006-01-02"
+
+	// keyDailySummaryUsers is the index of the users of an instance with a
+	// daily summary, so that the job does not go through all the users.
+	keyDailySummaryUsers = "daily_summary_users"
+
+	// prefixDailySummaryDay is the prefix of the keys of the day the last
+	// summary of a user was sent. It is stored apart from the connection,
+	// which the job then does not rewrite, and only matters until the next
+	// day.
+	prefixDailySummaryDay = "daily_summary_day_"
+	dailySummaryDayTTL    = 48 * time.Hour
+)
+
+// dailySummaryDue reports whether the daily summary of the settings should be
+// sent, now being in the user's timezone and lastDay the day the last one was
+// sent: it is once the time of the day the user chose has passed, if none was
+// sent yet that day.
+func dailySummaryDue(settings *ConnectionSettings, lastDay string, now time.Time) bool {
+	if settings == nil || settings.DailySummary == "" {
+		return false
+	}
+	at, err := parseQuietHoursClock(settings.DailySummary)
+	if err != nil {
+		return false
+	}
+	if now.Hour()*60+now.Minute() < at {
+		return false
+	}
+	return lastDay != now.Format(dailySummaryDateLayout)
+}
+
+func dailySummaryDayKey(instanceID, mattermostUserID types.ID) string {
+	return hashkey(prefixDailySummaryDay, instanceID.String()+"/"+mattermostUserID.String())
+}
+
+// loadDailySummaryDay returns the day the last daily summary of the user was
+// sent, "" if none was sent lately.
+func (p *Plugin) loadDailySummaryDay(instanceID, mattermostUserID types.ID) (string, error) {
+	day := ""
+	if err := p.client.KV.Get(dailySummaryDayKey(instanceID, mattermostUserID), &day); err != nil {
+		return "", err
+	}
+	return day, nil
+}
+
+func (p *Plugin) storeDailySummaryDay(instanceID, mattermostUserID types.ID, day string) error {
+	_, err := p.client.KV.Set(dailySummaryDayKey(instanceID, mattermostUserID), day, pluginapi.SetExpiry(dailySummaryDayTTL))
+	return err
+}
+
+// updateDailySummaryUsers adds the user to the users with a daily summary, or
+// removes them.
+func (p *Plugin) updateDailySummaryUsers(instanceID, mattermostUserID types.ID, hasSummary bool) error {
+	return p.updateIndex(keyWithInstanceID(instanceID, keyDailySummaryUsers), mattermostUserID.String(), hasSummary)
+}
+
+// startDailySummary records the daily summary of the settings just stored for
+// the user. The first summary is sent tomorrow rather than right away when
+// the time has passed today.
+func (p *Plugin) startDailySummary(instanceID, mattermostUserID types.ID, settings *ConnectionSettings) error {
+	hasSummary := settings != nil && settings.DailySummary != ""
+	if err := p.updateDailySummaryUsers(instanceID, mattermostUserID, hasSummary); err != nil {
+		return err
+	}
+	if !hasSummary {
+		return nil
+	}
+	now := time.Now().In(p.userLocation(mattermostUserID))
+	if !dailySummaryDue(settings, "", now) {
+		return nil
+	}
+	return p.storeDailySummaryDay(instanceID, mattermostUserID, now.Format(dailySummaryDateLayout))
+}
+
+// mdIssuesByStatus lists the issues grouped by status, the statuses in the
+// order they first appear in.
+func mdIssuesByStatus(instance Instance, issues []jira.Issue, total int) string {
+	statuses := []string{}
+	byStatus := map[string][]string{}
+	for i := range issues {
+		issue := &issues[i]
+		status := "Unknown status"
+		summary := ""
+		if issue.Fields != nil {
+			if issue.Fields.Status != nil && issue.Fields.Status.Name != "" {
+				status = issue.Fields.Status.Name
+			}
+			summary = truncate(issue.Fields.Summary, maxIssueSummaryLength)
+		}
+		if _, ok := byStatus[status]; !ok {
+			statuses = append(statuses, status)
+		}
+		byStatus[status] = append(byStatus[status],
+			strings.TrimSpace(fmt.Sprintf("* [%s](%s/browse/%s) %s", issue.Key, instance.GetJiraBaseURL(), issue.Key, summary)))
+	}
+
+	msg := ""
+	for _, status := range statuses {
+		msg += fmt.Sprintf("\n**%s**\n%s\n", status, strings.Join(byStatus[status], "\n"))
+	}
+	if total > len(issues) {
+		msg += fmt.Sprintf("\n...and %d more.\n", total-len(issues))
+	}
+	return strings.TrimSuffix(msg, "\n")
+}
+
+// dailySummaryMessage lists the open issues of the daily summary.
+func dailySummaryMessage(instance Instance, issues []jira.Issue, total int) string {
+	return fmt.Sprintf("#### Your open Jira issues\nYou have %d open issue(s) assigned to you in %s.\n", total, instance.GetID()) +
+		mdIssuesByStatus(instance, issues, total)
+}
+
+// sendDailySummaries DMs the users whose daily summary is due the list of
+// the open issues assigned to them. Users without any are not sent a DM.
+func (p *Plugin) sendDailySummaries(now time.Time) {
+	instances, err := p.instanceStore.LoadInstances()
+	if err != nil {
+		p.client.Log.Warn("Failed to send the daily summaries", "error", err.Error())
+		return
+	}
+	for _, instanceID := range instances.IDs() {
+		userIDs, err := p.loadIndex(keyWithInstanceID(instanceID, keyDailySummaryUsers))
+		if err != nil {
+			p.infof("sendDailySummaries: failed to load the users of %s: %v", instanceID, err)
+			continue
+		}
+		for _, userID := range userIDs {
+			p.sendDailySummaryIfDue(instanceID, types.ID(userID), now)
+		}
+	}
+}
+
+func (p *Plugin) sendDailySummaryIfDue(instanceID, mattermostUserID types.ID, now time.Time) {
+	connection, err := p.userStore.LoadConnection(instanceID, mattermostUserID)
+	if err != nil && errors.Cause(err) != kvstore.ErrNotFound {
+		p.infof("sendDailySummaries: failed to load the connection of %s: %v", mattermostUserID, err)
+		return
+	}
+	if err != nil || connection.Settings == nil || connection.Settings.DailySummary == "" {
+		// The user disconnected since, or reset their settings.
+		if err = p.updateDailySummaryUsers(instanceID, mattermostUserID, false); err != nil {
+			p.infof("sendDailySummaries: failed to update the users with a summary: %v", err)
+		}
+		return
+	}
+
+	lastDay, err := p.loadDailySummaryDay(instanceID, mattermostUserID)
+	if err != nil {
+		p.infof("sendDailySummaries: failed to load the summary day: %v", err)
+		return
+	}
+	local := now.In(p.userLocation(mattermostUserID))
+	if !dailySummaryDue(connection.Settings, lastDay, local) {
+		return
+	}
+
+	// The day is marked as done before sending: a user whose connection is
+	// broken gets no summary rather than many attempts.
+	if err = p.storeDailySummaryDay(instanceID, mattermostUserID, local.Format(dailySummaryDateLayout)); err != nil {
+		p.infof("sendDailySummaries: failed to store the summary day: %v", err)
+		return
+	}
+	if err = p.sendDailySummary(instanceID, mattermostUserID); err != nil {
+		p.infof("sendDailySummaries: failed to send a summary to %s: %v", mattermostUserID, err)
+	}
+}
+
+func (p *Plugin) sendDailySummary(instanceID, mattermostUserID types.ID) error {
+	client, instance, _, err := p.getClient(instanceID, mattermostUserID)
+	if err != nil {
+		return err
+	}
+	issues, total, err := client.SearchIssuesWithTotal(dailySummaryJQL, &jira.SearchOptions{
+		MaxResults: dailySummaryMaxResults,
+		Fields:     []string{"summary", "status"},
+	})
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+	_, err = p.postBotDM(mattermostUserID, dailySummaryMessage(instance, issues, total), "", "")
+	return err
+}
+
+func (p *Plugin) settingsDailySummary(header *model.CommandArgs, instanceID, mattermostUserID types.ID, connection *Connection, args []string) *model.CommandResponse {
+	const helpText = "`/jira settings daily-summary [value]`\n* [value] is a time of the day in your timezone, in the 24-hour HH:MM format, e.g. `08:30`, or `off`."
+
+	if len(args) != 2 {
+		return p.responsef(header, "%s", helpText)
+	}
+
+	at := ""
+	if args[1] != settingOff {
+		minutes, err := parseQuietHoursClock(args[1])
+		if err != nil {
+			return p.responsef(header, "%v.\n%s", err, helpText)
+		}
+		at = fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+	}
+
+	if connection.Settings == nil {
+		connection.Settings = &ConnectionSettings{}
+	}
+	connection.Settings.DailySummary = at
+	if err := p.userStore.StoreConnection(instanceID, mattermostUserID, connection); err != nil {
+		p.errorf("settingsDailySummary, err: %v", err)
+		return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
+	}
+	if err := p.startDailySummary(instanceID, mattermostUserID, connection.Settings); err != nil {
+		p.errorf("settingsDailySummary, err: %v", err)
+		return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
+	}
+
+	if at == "" {
+		return p.responsef(header, "Settings updated. Daily summary off.")
+	}
+	return p.responsef(header, "Settings updated. You will get a DM with your open issues every day at %s, in the %s timezone, when you have some.", at, p.userLocation(mattermostUserID).String())
+}
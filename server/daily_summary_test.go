@@ -0,0 +1,122 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+func TestDailySummaryDue(t *testing.T) {
+	now := time.Date(2024, 3, 10, 8, 30, 0, 0, time.UTC)
+
+	for name, tc := range map[string]struct {
+		settings *ConnectionSettings
+		last     string
+		expected bool
+	}{
+		"no settings": {
+			expected: false,
+		},
+		"off": {
+			settings: &ConnectionSettings{},
+			expected: false,
+		},
+		"before the time": {
+			settings: &ConnectionSettings{DailySummary: "09:00"},
+			expected: false,
+		},
+		"at the time": {
+			settings: &ConnectionSettings{DailySummary: "08:30"},
+			expected: true,
+		},
+		"after the time, sent yesterday": {
+			settings: &ConnectionSettings{DailySummary: "07:00"},
+			last:     "2024-03-09",
+			expected: true,
+		},
+		"after the time, already sent today": {
+			settings: &ConnectionSettings{DailySummary: "07:00"},
+			last:     "2024-03-10",
+			expected: false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, dailySummaryDue(tc.settings, tc.last, now))
+		})
+	}
+}
+
+type dailySummaryUserStore struct {
+	mockUserStore
+	settings *ConnectionSettings
+}
+
+func (store dailySummaryUserStore) LoadConnection(types.ID, types.ID) (*Connection, error) {
+	return &Connection{Settings: store.settings}, nil
+}
+
+func TestSendDailySummaryIfDue(t *testing.T) {
+	const userID = types.ID("user1")
+	now := time.Date(2024, 3, 10, 8, 30, 0, 0, time.UTC)
+	indexKey := keyWithInstanceID(testInstance1.InstanceID, keyDailySummaryUsers)
+
+	setup := func(settings *ConnectionSettings, lastDay string) (*Plugin, *plugintest.API) {
+		api := &plugintest.API{}
+		api.On("KVGet", indexKey).Return([]byte(`["user1"]`), (*model.AppError)(nil))
+		day, _ := json.Marshal(lastDay)
+		api.On("KVGet", dailySummaryDayKey(testInstance1.InstanceID, userID)).Return(day, (*model.AppError)(nil))
+		api.On("GetUser", mock.Anything).Return(&model.User{Timezone: map[string]string{"useAutomaticTimezone": "true", "automaticTimezone": "UTC"}}, (*model.AppError)(nil))
+		p := &Plugin{userStore: dailySummaryUserStore{settings: settings}}
+		p.SetAPI(api)
+		p.client = pluginapi.NewClient(api, p.Driver)
+		return p, api
+	}
+
+	t.Run("turned off since", func(t *testing.T) {
+		p, api := setup(&ConnectionSettings{}, "")
+		api.On("KVSetWithOptions", indexKey, []byte(nil), mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, (*model.AppError)(nil))
+
+		p.sendDailySummaryIfDue(testInstance1.InstanceID, userID, now)
+		api.AssertCalled(t, "KVSetWithOptions", indexKey, []byte(nil), mock.AnythingOfType("model.PluginKVSetOptions"))
+	})
+
+	t.Run("already sent today", func(t *testing.T) {
+		p, api := setup(&ConnectionSettings{DailySummary: "07:00"}, "2024-03-10")
+
+		p.sendDailySummaryIfDue(testInstance1.InstanceID, userID, now)
+		api.AssertNotCalled(t, "KVSetWithOptions", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDailySummaryMessage(t *testing.T) {
+	issue := func(key, summary, status string) jira.Issue {
+		return jira.Issue{Key: key, Fields: &jira.IssueFields{Summary: summary, Status: &jira.Status{Name: status}}}
+	}
+	issues := []jira.Issue{
+		issue("KT-1", "First", "To Do"),
+		issue("KT-2", "Second", "In Progress"),
+		issue("KT-3", "Third", "To Do"),
+	}
+
+	assert.Equal(t, "#### Your open Jira issues\n"+
+		"You have 4 open issue(s) assigned to you in https://jiraurl1.com.\n"+
+		"\n**To Do**\n"+
+		"* [KT-1](https://jiraurl1.com/browse/KT-1) First\n"+
+		"* [KT-3](https://jiraurl1.com/browse/KT-3) Third\n"+
+		"\n**In Progress**\n"+
+		"* [KT-2](https://jiraurl1.com/browse/KT-2) Second\n"+
+		"\n...and 1 more.",
+		dailySummaryMessage(testInstance1, issues, 4))
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	prefixChannelDevInfo = "channel_dev_info_"
+
+	// devInfoTTL is how long the development information of an issue is
+	// remembered, so that a burst of events does not fetch it every time.
+	devInfoTTL = time.Minute
+
+	// devInfoPullRequestsMax is the number of open pull requests linked in
+	// the development field.
+	devInfoPullRequestsMax = 3
+
+	devStatusOpen = "OPEN"
+)
+
+// channelShowsDevInfo reports whether the issues shown in the channel, by
+// `/jira view` and by the subscriptions, have their development information.
+func (p *Plugin) channelShowsDevInfo(channelID string) bool {
+	if channelID == "" {
+		return false
+	}
+
+	var show bool
+	err := p.client.KV.Get(prefixChannelDevInfo+channelID, &show)
+	if err != nil {
+		p.client.Log.Warn("Failed to load the development information setting of the channel", "ChannelID", channelID, "Error", err.Error())
+		return false
+	}
+	return show
+}
+
+func (p *Plugin) setChannelShowsDevInfo(channelID string, show bool) error {
+	if !show {
+		return p.client.KV.Delete(prefixChannelDevInfo + channelID)
+	}
+	_, err := p.client.KV.Set(prefixChannelDevInfo+channelID, true)
+	return err
+}
+
+func devInfoCount(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// renderDevInfo renders the development information of an issue, e.g. "2
+// branches, 1 open PR: [#12 Fix the login](url)", or "" when it has none. The
+// open pull requests are fetched from each development tool that has some.
+func renderDevInfo(client Client, issue *jira.Issue, summary *DevStatusSummary) string {
+	parts := []string{}
+	if n := summary.Branch.Overall.Count; n > 0 {
+		parts = append(parts, devInfoCount(n, "branch", "branches"))
+	}
+	if n := summary.Repository.Overall.Count; n > 0 {
+		parts = append(parts, devInfoCount(n, "commit", "commits"))
+	}
+	prs := summary.PullRequest.Overall
+	switch {
+	case prs.Details.OpenCount > 0:
+		parts = append(parts, devInfoCount(prs.Details.OpenCount, "open PR", "open PRs"))
+	case prs.Count > 0:
+		parts = append(parts, devInfoCount(prs.Count, "PR", "PRs"))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	value := strings.Join(parts, ", ")
+
+	if prs.Details.OpenCount == 0 {
+		return value
+	}
+	applicationTypes := []string{}
+	for applicationType, byType := range summary.PullRequest.ByInstanceType {
+		if byType.Count > 0 {
+			applicationTypes = append(applicationTypes, applicationType)
+		}
+	}
+	sort.Strings(applicationTypes)
+	links := []string{}
+	for _, applicationType := range applicationTypes {
+		pullRequests, err := client.GetDevStatusPullRequests(issue.ID, applicationType)
+		if err != nil {
+			continue
+		}
+		for _, pr := range pullRequests {
+			if pr.Status != devStatusOpen || pr.URL == "" || len(links) == devInfoPullRequestsMax {
+				continue
+			}
+			links = append(links, fmt.Sprintf("[%s](%s)", strings.TrimSpace(pr.ID+" "+pr.Name), pr.URL))
+		}
+	}
+	if len(links) > 0 {
+		value += ": " + strings.Join(links, ", ")
+	}
+	return value
+}
+
+// issueDevInfo returns the rendered development information of an issue of a
+// Jira Cloud instance, fetched on behalf of the user, or "" when it has none
+// or the instance does not expose it. It is cached per user, who may not see
+// all the repositories.
+func (p *Plugin) issueDevInfo(instance Instance, mattermostUserID types.ID, client Client, issue *jira.Issue) string {
+	if !instance.Common().IsCloudInstance() || issue.ID == "" {
+		return ""
+	}
+	key := mattermostUserID.String() + "/" + issue.ID
+	if value, ok := p.devInfoCache.get(instance.GetID(), key); ok {
+		return value
+	}
+
+	value := ""
+	summary, err := client.GetDevStatusSummary(issue.ID)
+	if err != nil {
+		p.client.Log.Debug("Failed to get the development information of the issue", "IssueKey", issue.Key, "Error", err.Error())
+	} else {
+		value = renderDevInfo(client, issue, summary)
+	}
+	p.devInfoCache.set(instance.GetID(), key, value, devInfoTTL)
+	return value
+}
+
+func devInfoField(value string) *model.SlackAttachmentField {
+	if value == "" {
+		return nil
+	}
+	return &model.SlackAttachmentField{
+		Title: cardLabelDevelopment,
+		Value: value,
+	}
+}
+
+// addDevInfo adds the development information of the issue, fetched on behalf
+// of the user, to its card, when the channel shows it.
+func (p *Plugin) addDevInfo(attachments []*model.SlackAttachment, instance Instance, mattermostUserID types.ID, client Client, channelID string, issue *jira.Issue) {
+	if len(attachments) == 0 || !p.channelShowsDevInfo(channelID) {
+		return
+	}
+	if field := devInfoField(p.issueDevInfo(instance, mattermostUserID, client, issue)); field != nil {
+		attachments[0].Fields = append(attachments[0].Fields, field)
+	}
+}
+
+// cardDevInfo returns the development information of the issue of a
+// subscription card, when the channel shows it, fetched with the Jira
+// connection of the creator of the subscription.
+func (p *Plugin) cardDevInfo(instanceID types.ID, channelID string, sub ChannelSubscription, issue *jira.Issue) string {
+	if sub.CreatedBy == "" || !p.channelShowsDevInfo(channelID) {
+		return ""
+	}
+	client, instance, _, err := p.getClient(instanceID, sub.CreatedBy)
+	if err != nil {
+		return ""
+	}
+	return p.issueDevInfo(instance, sub.CreatedBy, client, issue)
+}
+
+func executeChannelDevInfo(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	if len(args) == 0 {
+		if p.channelShowsDevInfo(header.ChannelId) {
+			return p.responsef(header, "The issues shown in this channel **have** their development information.")
+		}
+		return p.responsef(header, "The issues shown in this channel **do not have** their development information.")
+	}
+	if len(args) != 1 || (args[0] != settingOn && args[0] != settingOff) {
+		return p.responsef(header, "Please use `/jira channel dev-info [on|off]`.")
+	}
+
+	authorized, err := p.canManageChannelReadOnly(header.UserId, header.ChannelId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira channel dev-info` can only be run by a channel or system administrator.")
+	}
+
+	show := args[0] == settingOn
+	if err = p.setChannelShowsDevInfo(header.ChannelId, show); err != nil {
+		return p.responsef(header, "Failed to update the channel. Error: %v.", err)
+	}
+	if show {
+		return p.responsef(header, "The issues of Jira Cloud shown in this channel, by `/jira view` and by the subscriptions, now have their branches, commits and pull requests, when they have some.")
+	}
+	return p.responsef(header, "The issues shown in this channel no longer have their development information.")
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type devInfoTestClient struct {
+	testClient
+	summary      string
+	pullRequests map[string][]DevStatusPullRequest
+	calls        *int
+}
+
+func (client devInfoTestClient) GetDevStatusSummary(issueID string) (*DevStatusSummary, error) {
+	*client.calls++
+	summary := &DevStatusSummary{}
+	if err := json.Unmarshal([]byte(client.summary), summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+func (client devInfoTestClient) GetDevStatusPullRequests(issueID, applicationType string) ([]DevStatusPullRequest, error) {
+	return client.pullRequests[applicationType], nil
+}
+
+func TestRenderDevInfo(t *testing.T) {
+	issue := &jira.Issue{ID: "10001", Key: "KT-1"}
+	calls := 0
+
+	for name, tc := range map[string]struct {
+		summary      string
+		pullRequests map[string][]DevStatusPullRequest
+		expected     string
+	}{
+		"no dev info": {
+			summary:  `{}`,
+			expected: "",
+		},
+		"branches and commits": {
+			summary:  `{"branch": {"overall": {"count": 2}}, "repository": {"overall": {"count": 1}}}`,
+			expected: "2 branches, 1 commit",
+		},
+		"merged PRs": {
+			summary:  `{"branch": {"overall": {"count": 1}}, "pullrequest": {"overall": {"count": 2, "details": {"openCount": 0}}}}`,
+			expected: "1 branch, 2 PRs",
+		},
+		"open PRs with links": {
+			summary: `{"branch": {"overall": {"count": 2}}, "pullrequest": {"overall": {"count": 2, "details": {"openCount": 1}}, "byInstanceType": {"GitHub": {"count": 2, "name": "GitHub"}}}}`,
+			pullRequests: map[string][]DevStatusPullRequest{"GitHub": {
+				{ID: "#12", Name: "Fix the login", URL: "https://github.com/org/repo/pull/12", Status: "OPEN"},
+				{ID: "#10", Name: "Old", URL: "https://github.com/org/repo/pull/10", Status: "MERGED"},
+			}},
+			expected: "2 branches, 1 open PR: [#12 Fix the login](https://github.com/org/repo/pull/12)",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := devInfoTestClient{summary: tc.summary, pullRequests: tc.pullRequests, calls: &calls}
+			summary, err := client.GetDevStatusSummary(issue.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, renderDevInfo(client, issue, summary))
+		})
+	}
+}
+
+func TestAddDevInfo(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("KVGet", prefixChannelDevInfo+"dev-channel").Return([]byte("true"), nil)
+	api.On("KVGet", prefixChannelDevInfo+"other-channel").Return(nil, nil)
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+
+	calls := 0
+	client := devInfoTestClient{summary: `{"branch": {"overall": {"count": 2}}}`, calls: &calls}
+	issue := &jira.Issue{ID: "10001", Key: "KT-1"}
+	cloud := &testInstance{InstanceCommon: InstanceCommon{InstanceID: mockInstance1URL, Type: CloudInstanceType}}
+	attachments := func() []*model.SlackAttachment {
+		return []*model.SlackAttachment{{}}
+	}
+
+	t.Run("the channel does not show it", func(t *testing.T) {
+		got := attachments()
+		p.addDevInfo(got, cloud, "user1", client, "other-channel", issue)
+		assert.Empty(t, got[0].Fields)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("Jira Server has none", func(t *testing.T) {
+		got := attachments()
+		p.addDevInfo(got, testInstance1, "user1", client, "dev-channel", issue)
+		assert.Empty(t, got[0].Fields)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("cached briefly", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			got := attachments()
+			p.addDevInfo(got, cloud, "user1", client, "dev-channel", issue)
+			require.Len(t, got[0].Fields, 1)
+			assert.Equal(t, cardLabelDevelopment, got[0].Fields[0].Title)
+			assert.Equal(t, "2 branches", got[0].Fields[0].Value)
+		}
+		assert.Equal(t, 1, calls)
+
+		got := attachments()
+		p.addDevInfo(got, cloud, "user2", client, "dev-channel", issue)
+		require.Len(t, got[0].Fields, 1)
+		assert.Equal(t, 2, calls, "the development information is cached per user")
+	})
+}
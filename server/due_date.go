@@ -0,0 +1,124 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	defaultDueSoonDays = 2
+
+	dueDateOverdue = "overdue"
+	dueDateSoon    = "soon"
+
+	dueDateLayout = "2006-01-02"
+
+	// overdueColor is the color of the cards of the overdue issues.
+	overdueColor = "#d24b4e"
+)
+
+// parseDueSoonDays parses the DueSoonDays setting, the number of days before
+// the due date from which an issue is due soon, 0 to never show it.
+func parseDueSoonDays(setting string) (int, error) {
+	setting = strings.TrimSpace(setting)
+	if setting == "" {
+		return defaultDueSoonDays, nil
+	}
+	n, err := strconv.Atoi(setting)
+	if err != nil || n < 0 {
+		return 0, errors.Errorf("invalid number of days %q before the due date, it must be 0 or a positive number", setting)
+	}
+	return n, nil
+}
+
+// dueDateState tells whether the unresolved issue is overdue or due soon, on
+// the day of now in its location, or returns "" when it is neither, is
+// resolved, or has no due date.
+func dueDateState(issue *jira.Issue, now time.Time, dueSoonDays int) (string, time.Time) {
+	if issue == nil || issue.Fields == nil || issue.Fields.Resolution != nil {
+		return "", time.Time{}
+	}
+	due := time.Time(issue.Fields.Duedate)
+	if due.IsZero() {
+		return "", time.Time{}
+	}
+
+	// The due date is a day, without a time or a timezone.
+	due = time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, now.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case due.Before(today):
+		return dueDateOverdue, due
+	case dueSoonDays > 0 && due.Before(today.AddDate(0, 0, dueSoonDays+1)):
+		return dueDateSoon, due
+	}
+	return "", due
+}
+
+// mdDueDateBadge returns e.g. "⚠️ Overdue since 2026-10-10", or "" when the
+// issue is neither overdue nor due soon.
+func mdDueDateBadge(state string, due time.Time) string {
+	switch state {
+	case dueDateOverdue:
+		return "⚠️ Overdue since " + due.Format(dueDateLayout)
+	case dueDateSoon:
+		return "Due soon, on " + due.Format(dueDateLayout)
+	}
+	return ""
+}
+
+// dueDateField returns the card field with the due date badge of the issue,
+// or nil if it has none.
+func dueDateField(state string, due time.Time) *model.SlackAttachmentField {
+	badge := mdDueDateBadge(state, due)
+	if badge == "" {
+		return nil
+	}
+	return &model.SlackAttachmentField{
+		Title: cardLabelDue,
+		Value: badge,
+		Short: true,
+	}
+}
+
+// addDueDateBadge adds the due date badge of the issue to its card, in red
+// when it is overdue, as of today in the timezone of the user seeing it.
+func (p *Plugin) addDueDateBadge(attachments []*model.SlackAttachment, issue *jira.Issue, mattermostUserID types.ID) {
+	if len(attachments) == 0 || issue.Fields == nil || time.Time(issue.Fields.Duedate).IsZero() {
+		return
+	}
+	loc := time.Local
+	if mattermostUserID != "" {
+		loc = p.userLocation(mattermostUserID)
+	}
+	state, due := dueDateState(issue, time.Now().In(loc), p.getConfig().dueSoonDays)
+	field := dueDateField(state, due)
+	if field == nil {
+		return
+	}
+	attachments[0].Fields = append(attachments[0].Fields, field)
+	if state == dueDateOverdue {
+		attachments[0].Color = overdueColor
+	}
+}
+
+// mdHeadlineDueDateBadge returns the badge appended to the headline of the
+// subscription posts without a card, e.g. " · ⚠️ Overdue since 2026-10-10".
+func mdHeadlineDueDateBadge(state string, due time.Time) string {
+	badge := mdDueDateBadge(state, due)
+	if badge == "" {
+		return ""
+	}
+	return fmt.Sprintf(" · %s", badge)
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dueDateIssue(due string, resolved bool) *jira.Issue {
+	issue := &jira.Issue{Key: "TEST-1", Fields: &jira.IssueFields{}}
+	if due != "" {
+		t, err := time.Parse(dueDateLayout, due)
+		if err != nil {
+			panic(err)
+		}
+		issue.Fields.Duedate = jira.Date(t)
+	}
+	if resolved {
+		issue.Fields.Resolution = &jira.Resolution{Name: "Done"}
+	}
+	return issue
+}
+
+func TestParseDueSoonDays(t *testing.T) {
+	days, err := parseDueSoonDays("")
+	require.NoError(t, err)
+	assert.Equal(t, defaultDueSoonDays, days)
+	days, err = parseDueSoonDays(" 0 ")
+	require.NoError(t, err)
+	assert.Equal(t, 0, days)
+	_, err = parseDueSoonDays("-1")
+	assert.Error(t, err)
+	_, err = parseDueSoonDays("soon")
+	assert.Error(t, err)
+}
+
+func TestDueDateState(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	for name, tc := range map[string]struct {
+		issue       *jira.Issue
+		dueSoonDays int
+		state       string
+		badge       string
+	}{
+		"overdue": {
+			issue: dueDateIssue("2026-10-13", false),
+			state: dueDateOverdue,
+			badge: "⚠️ Overdue since 2026-10-13",
+		},
+		"due today": {
+			issue:       dueDateIssue("2026-10-14", false),
+			dueSoonDays: 2,
+			state:       dueDateSoon,
+			badge:       "Due soon, on 2026-10-14",
+		},
+		"due soon": {
+			issue:       dueDateIssue("2026-10-16", false),
+			dueSoonDays: 2,
+			state:       dueDateSoon,
+			badge:       "Due soon, on 2026-10-16",
+		},
+		"due later": {
+			issue:       dueDateIssue("2026-10-17", false),
+			dueSoonDays: 2,
+		},
+		"due soon, without due soon window": {
+			issue: dueDateIssue("2026-10-14", false),
+		},
+		"overdue but resolved": {
+			issue: dueDateIssue("2026-10-01", true),
+		},
+		"no due date": {
+			issue:       dueDateIssue("", false),
+			dueSoonDays: 2,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			state, due := dueDateState(tc.issue, now, tc.dueSoonDays)
+			assert.Equal(t, tc.state, state)
+			assert.Equal(t, tc.badge, mdDueDateBadge(state, due))
+		})
+	}
+}
+
+func TestDueDateStateTimezone(t *testing.T) {
+	issue := dueDateIssue("2026-10-14", false)
+	// Still the 14th in Los Angeles, already the 15th in Tokyo.
+	now := time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	state, _ := dueDateState(issue, now.In(losAngeles), 1)
+	assert.Equal(t, dueDateSoon, state)
+	state, _ = dueDateState(issue, now.In(tokyo), 1)
+	assert.Equal(t, dueDateOverdue, state)
+}
+
+func TestAddDueDateBadge(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetUser", "userID").Return(&model.User{Id: "userID", Timezone: model.StringMap{
+		"useAutomaticTimezone": "false",
+		"manualTimezone":       "UTC",
+	}}, nil)
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+
+	attachments := []*model.SlackAttachment{{Color: "#95b7d0"}}
+	p.addDueDateBadge(attachments, dueDateIssue("2020-01-01", false), "userID")
+	assert.Equal(t, overdueColor, attachments[0].Color)
+	require.Len(t, attachments[0].Fields, 1)
+	assert.Equal(t, cardLabelDue, attachments[0].Fields[0].Title)
+	assert.Equal(t, "⚠️ Overdue since 2020-01-01", attachments[0].Fields[0].Value)
+
+	attachments = []*model.SlackAttachment{{Color: "#95b7d0"}}
+	p.addDueDateBadge(attachments, dueDateIssue("", false), "userID")
+	assert.Equal(t, "#95b7d0", attachments[0].Color)
+	assert.Empty(t, attachments[0].Fields)
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+)
+
+const (
+	// epicChildrenLimit is the number of child issues of an epic listed.
+	epicChildrenLimit = 100
+
+	epicProgressBarWidth = 10
+
+	issueTypeEpic = "epic"
+
+	// epicHierarchyLevel is the hierarchy level of the issue types of the
+	// epics on Jira Cloud, whatever they are named.
+	epicHierarchyLevel = 1
+
+	// epicNameFieldSchema identifies the Epic Name field of Jira Software,
+	// which only the epics have on Jira Server.
+	epicNameFieldSchema = "com.pyxis.greenhopper.jira:gh-epic-label"
+)
+
+// epicStatusCategories are the status categories of the children of an epic,
+// in the order they are listed.
+var epicStatusCategories = []struct {
+	key  string
+	name string
+}{
+	{jira.StatusCategoryToDo, "To Do"},
+	{jira.StatusCategoryInProgress, "In Progress"},
+	{jira.StatusCategoryComplete, "Done"},
+}
+
+// epicChildQueries returns the JQL conditions that find the children of an
+// epic, the most likely first: team-managed projects on Jira Cloud use the
+// parent field, while Jira Server and the company-managed projects use Epic
+// Link.
+func epicChildQueries(epicKey string, cloud bool) []string {
+	parent := fmt.Sprintf(`parent = %q`, epicKey)
+	epicLink := fmt.Sprintf(`"Epic Link" = %q`, epicKey)
+	if cloud {
+		return []string{parent, epicLink}
+	}
+	return []string{epicLink, parent}
+}
+
+// findEpicChildren returns the first child issues of an epic, their total and
+// how many of them are done. The first query that finds some wins, as the
+// Epic Link field does not exist on every instance: an error is only returned
+// if no query could run.
+func findEpicChildren(client Client, epicKey string, cloud bool) ([]jira.Issue, int, int, error) {
+	var lastErr error
+	ran := false
+	for _, jql := range epicChildQueries(epicKey, cloud) {
+		issues, total, err := client.SearchIssuesWithTotal(jql+" ORDER BY status, key", &jira.SearchOptions{
+			MaxResults: epicChildrenLimit,
+			Fields:     []string{"summary", "status"},
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if total == 0 {
+			ran = true
+			continue
+		}
+
+		done := 0
+		if total == len(issues) {
+			for _, issue := range issues {
+				if issueStatusCategory(issue) == jira.StatusCategoryComplete {
+					done++
+				}
+			}
+			return issues, total, done, nil
+		}
+		// Only the first children are listed, the search counts those done.
+		_, done, err = client.SearchIssuesWithTotal(jql+" AND statusCategory = Done", &jira.SearchOptions{
+			MaxResults: 1,
+			Fields:     []string{"key"},
+		})
+		if err != nil {
+			return nil, 0, 0, errors.WithMessage(err, "failed to count the child issues done")
+		}
+		return issues, total, done, nil
+	}
+	if !ran {
+		return nil, 0, 0, lastErr
+	}
+	return []jira.Issue{}, 0, 0, nil
+}
+
+func progressBar(done, total int) string {
+	filled := 0
+	percent := 0
+	if total > 0 {
+		filled = done * epicProgressBarWidth / total
+		percent = done * 100 / total
+	}
+	return fmt.Sprintf("`%s%s` %d/%d done (%d%%)",
+		strings.Repeat("█", filled), strings.Repeat("░", epicProgressBarWidth-filled), done, total, percent)
+}
+
+func issueStatusCategory(issue jira.Issue) string {
+	if issue.Fields == nil || issue.Fields.Status == nil {
+		return jira.StatusCategoryToDo
+	}
+	switch key := issue.Fields.Status.StatusCategory.Key; key {
+	case jira.StatusCategoryInProgress, jira.StatusCategoryComplete:
+		return key
+	default:
+		return jira.StatusCategoryToDo
+	}
+}
+
+func formatEpic(epic *jira.Issue, children []jira.Issue, total, done int, jiraURL string) string {
+	msg := fmt.Sprintf("#### [%s: %s](%s/browse/%s)\n", epic.Key, epic.Fields.Summary, jiraURL, epic.Key)
+	if total == 0 {
+		return msg + "This epic has no child issues."
+	}
+
+	byCategory := map[string][]jira.Issue{}
+	for _, child := range children {
+		category := issueStatusCategory(child)
+		byCategory[category] = append(byCategory[category], child)
+	}
+
+	msg += progressBar(done, total) + "\n"
+	for _, category := range epicStatusCategories {
+		issues := byCategory[category.key]
+		if len(issues) == 0 {
+			continue
+		}
+		msg += fmt.Sprintf("\n**%s** (%d)\n", category.name, len(issues))
+		for _, issue := range issues {
+			status := ""
+			if issue.Fields != nil && issue.Fields.Status != nil {
+				status = " - " + issue.Fields.Status.Name
+			}
+			summary := ""
+			if issue.Fields != nil {
+				summary = truncate(issue.Fields.Summary, 80)
+			}
+			msg += fmt.Sprintf("* [%s](%s/browse/%s) %s%s\n", issue.Key, jiraURL, issue.Key, summary, status)
+		}
+	}
+	if total > len(children) {
+		msg += fmt.Sprintf("\nShowing the first %d of %d child issues.", len(children), total)
+	}
+	return strings.TrimSuffix(msg, "\n")
+}
+
+// getEpic returns the summary and the issue type of an epic, or an error if
+// the issue is not an epic: its issue type is at the hierarchy level of the
+// epics on Jira Cloud, or it has an Epic Name on Jira Server.
+func (p *Plugin) getEpic(instance Instance, client Client, epicKey string) (*jira.Issue, error) {
+	fields := []string{"summary", "issuetype"}
+	epicNameField := ""
+	if !instance.Common().IsCloudInstance() {
+		var err error
+		epicNameField, err = p.getCustomFieldID(instance.GetID(), client, epicNameFieldSchema)
+		if err != nil {
+			return nil, err
+		}
+		if epicNameField != "" {
+			fields = append(fields, epicNameField)
+		}
+	}
+
+	raw := struct {
+		Fields map[string]json.RawMessage `json:"fields"`
+	}{}
+	if err := client.RESTGet("/2/issue/"+epicKey, map[string]string{"fields": strings.Join(fields, ",")}, &raw); err != nil {
+		return nil, err
+	}
+	summary := ""
+	_ = json.Unmarshal(raw.Fields["summary"], &summary)
+	issueType := struct {
+		Name           string `json:"name"`
+		HierarchyLevel *int   `json:"hierarchyLevel"`
+	}{}
+	_ = json.Unmarshal(raw.Fields["issuetype"], &issueType)
+
+	isEpic := false
+	switch {
+	case issueType.HierarchyLevel != nil:
+		isEpic = *issueType.HierarchyLevel == epicHierarchyLevel
+	case epicNameField != "":
+		epicName := ""
+		_ = json.Unmarshal(raw.Fields[epicNameField], &epicName)
+		isEpic = epicName != ""
+	}
+	if !isEpic {
+		name := "issue"
+		if issueType.Name != "" {
+			name = strings.ToLower(issueType.Name)
+		}
+		article := "a"
+		if strings.ContainsAny(name[:1], "aeiou") {
+			article = "an"
+		}
+		return nil, errors.Errorf("%s is %s %s, not an epic", epicKey, article, name)
+	}
+
+	return &jira.Issue{
+		Key: epicKey,
+		Fields: &jira.IssueFields{
+			Summary: summary,
+			Type:    jira.IssueType{Name: issueType.Name},
+		},
+	}, nil
+}
+
+// epicSummary returns the child issues of an epic, grouped by status
+// category, as seen by the user.
+func (p *Plugin) epicSummary(instance Instance, client Client, epicKey string) (string, error) {
+	epic, err := p.getEpic(instance, client, strings.ToUpper(epicKey))
+	if err != nil {
+		return "", err
+	}
+
+	children, total, done, err := findEpicChildren(client, epic.Key, instance.Common().IsCloudInstance())
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to get the child issues")
+	}
+	return formatEpic(epic, children, total, done, instance.GetJiraBaseURL()), nil
+}
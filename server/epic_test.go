@@ -0,0 +1,206 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type epicClient struct {
+	testClient
+	issueTypes map[string]string
+	// hierarchyLevels are those of the issue types on Jira Cloud.
+	hierarchyLevels map[string]int
+	results         map[string][]jira.Issue
+	// total and done are the counts of the children beyond those returned.
+	total   int
+	done    int
+	failing map[string]bool
+	queries *[]string
+}
+
+func (client epicClient) GetFields() ([]jira.Field, error) {
+	return []jira.Field{{ID: "customfield_10011", Schema: jira.FieldSchema{Custom: epicNameFieldSchema}}}, nil
+}
+
+func (client epicClient) RESTGet(endpoint string, params map[string]string, dest interface{}) error {
+	key := strings.TrimPrefix(endpoint, "/2/issue/")
+	issueType, ok := client.issueTypes[key]
+	if !ok {
+		return errors.New("issue does not exist")
+	}
+	fields := map[string]interface{}{"summary": "Summary of " + key}
+	if level, ok := client.hierarchyLevels[issueType]; ok {
+		fields["issuetype"] = map[string]interface{}{"name": issueType, "hierarchyLevel": level}
+	} else {
+		fields["issuetype"] = map[string]interface{}{"name": issueType}
+		if strings.Contains(params["fields"], "customfield_10011") && issueType == "Epic" {
+			fields["customfield_10011"] = "Name of " + key
+		}
+	}
+	data, err := json.Marshal(map[string]interface{}{"fields": fields})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (client epicClient) SearchIssuesWithTotal(jql string, options *jira.SearchOptions) ([]jira.Issue, int, error) {
+	*client.queries = append(*client.queries, jql)
+	if strings.Contains(jql, " AND statusCategory = Done") {
+		return nil, client.done, nil
+	}
+	field := strings.SplitN(jql, " = ", 2)[0]
+	if client.failing[field] {
+		return nil, 0, errors.New("field does not exist")
+	}
+	issues := client.results[field]
+	if client.total > 0 {
+		return issues, client.total, nil
+	}
+	return issues, len(issues), nil
+}
+
+func childIssue(key, status, category string) jira.Issue {
+	return jira.Issue{Key: key, Fields: &jira.IssueFields{
+		Summary: "Child " + key,
+		Status:  &jira.Status{Name: status, StatusCategory: jira.StatusCategory{Key: category}},
+	}}
+}
+
+func TestFindEpicChildren(t *testing.T) {
+	children := []jira.Issue{childIssue("KT-2", "Open", jira.StatusCategoryToDo)}
+
+	for name, tc := range map[string]struct {
+		cloud           bool
+		results         map[string][]jira.Issue
+		failing         map[string]bool
+		expectedQueries []string
+		expectedTotal   int
+		expectErr       bool
+	}{
+		"server, Epic Link": {
+			results:         map[string][]jira.Issue{`"Epic Link"`: children},
+			expectedQueries: []string{`"Epic Link"`},
+			expectedTotal:   1,
+		},
+		"cloud, parent": {
+			cloud:           true,
+			results:         map[string][]jira.Issue{"parent": children},
+			expectedQueries: []string{"parent"},
+			expectedTotal:   1,
+		},
+		"cloud, company-managed project": {
+			cloud:           true,
+			results:         map[string][]jira.Issue{`"Epic Link"`: children},
+			expectedQueries: []string{"parent", `"Epic Link"`},
+			expectedTotal:   1,
+		},
+		"no Epic Link field, no children": {
+			failing:         map[string]bool{`"Epic Link"`: true},
+			expectedQueries: []string{`"Epic Link"`, "parent"},
+		},
+		"no query can run": {
+			failing:         map[string]bool{`"Epic Link"`: true, "parent": true},
+			expectedQueries: []string{`"Epic Link"`, "parent"},
+			expectErr:       true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			queries := []string{}
+			client := epicClient{results: tc.results, failing: tc.failing, queries: &queries}
+
+			_, total, _, err := findEpicChildren(client, "KT-1", tc.cloud)
+			if tc.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedTotal, total)
+			}
+
+			fields := []string{}
+			for _, jql := range queries {
+				fields = append(fields, strings.SplitN(jql, " = ", 2)[0])
+			}
+			assert.Equal(t, tc.expectedQueries, fields)
+			assert.Contains(t, queries[0], `"KT-1"`)
+		})
+	}
+}
+
+func TestEpicSummary(t *testing.T) {
+	p := &Plugin{}
+	queries := []string{}
+	client := epicClient{
+		issueTypes: map[string]string{"KT-1": "Epic", "KT-9": "Bug"},
+		results: map[string][]jira.Issue{`"Epic Link"`: {
+			childIssue("KT-2", "Open", jira.StatusCategoryToDo),
+			childIssue("KT-3", "In Review", jira.StatusCategoryInProgress),
+			childIssue("KT-4", "Closed", jira.StatusCategoryComplete),
+			childIssue("KT-5", "Done", jira.StatusCategoryComplete),
+		}},
+		queries: &queries,
+	}
+
+	t.Run("children grouped by status category", func(t *testing.T) {
+		msg, err := p.epicSummary(testInstance1, client, "kt-1")
+		require.NoError(t, err)
+		assert.Equal(t, "#### [KT-1: Summary of KT-1](https://jiraurl1.com/browse/KT-1)\n"+
+			"`█████░░░░░` 2/4 done (50%)\n"+
+			"\n**To Do** (1)\n"+
+			"* [KT-2](https://jiraurl1.com/browse/KT-2) Child KT-2 - Open\n"+
+			"\n**In Progress** (1)\n"+
+			"* [KT-3](https://jiraurl1.com/browse/KT-3) Child KT-3 - In Review\n"+
+			"\n**Done** (2)\n"+
+			"* [KT-4](https://jiraurl1.com/browse/KT-4) Child KT-4 - Closed\n"+
+			"* [KT-5](https://jiraurl1.com/browse/KT-5) Child KT-5 - Done", msg)
+	})
+
+	t.Run("not an epic", func(t *testing.T) {
+		_, err := p.epicSummary(testInstance1, client, "KT-9")
+		require.Error(t, err)
+		assert.Equal(t, "KT-9 is a bug, not an epic", err.Error())
+	})
+
+	t.Run("more children than listed", func(t *testing.T) {
+		many := client
+		many.total = 250
+		many.done = 100
+		msg, err := p.epicSummary(testInstance1, many, "KT-1")
+		require.NoError(t, err)
+		assert.Contains(t, msg, "`████░░░░░░` 100/250 done (40%)\n")
+		assert.True(t, strings.HasSuffix(msg, "\nShowing the first 4 of 250 child issues."))
+		assert.Equal(t, `"Epic Link" = "KT-1" AND statusCategory = Done`, queries[len(queries)-1])
+	})
+
+	t.Run("epics of Jira Cloud are found by hierarchy level", func(t *testing.T) {
+		cloud := newCloudInstance(p, "https://mmtest.atlassian.net", true, "", &AtlassianSecurityContext{BaseURL: "https://mmtest.atlassian.net"})
+		cloudClient := epicClient{
+			issueTypes:      map[string]string{"KT-1": "Feature", "KT-9": "Improvement"},
+			hierarchyLevels: map[string]int{"Feature": 1, "Improvement": 0},
+			results:         map[string][]jira.Issue{"parent": {childIssue("KT-2", "Open", jira.StatusCategoryToDo)}},
+			queries:         &queries,
+		}
+
+		msg, err := p.epicSummary(cloud, cloudClient, "KT-1")
+		require.NoError(t, err)
+		assert.Contains(t, msg, "0/1 done")
+
+		_, err = p.epicSummary(cloud, cloudClient, "KT-9")
+		require.Error(t, err)
+		assert.Equal(t, "KT-9 is an improvement, not an epic", err.Error())
+	})
+
+	t.Run("progress bar", func(t *testing.T) {
+		assert.Equal(t, "`░░░░░░░░░░` 0/3 done (0%)", progressBar(0, 3))
+		assert.Equal(t, "`██████████` 3/3 done (100%)", progressBar(3, 3))
+	})
+}
@@ -0,0 +1,281 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	// healthSummaryCheckInterval is how often the job checks whether the
+	// health summary is due.
+	healthSummaryCheckInterval = 15 * time.Minute
+
+	defaultHealthSummaryInterval  = 24 * time.Hour
+	maxHealthSummaryIntervalHours = 168
+
+	// healthSummaryProbeTimeout caps the time an instance has to answer the
+	// probe before it is reported unreachable.
+	healthSummaryProbeTimeout = 10 * time.Second
+
+	keyHealthSummaryLastPosted = "health_summary_last_posted"
+)
+
+// instanceHealth is the state of an instance in the health summary.
+type instanceHealth struct {
+	InstanceID     types.ID
+	Alias          string
+	Reachable      bool
+	Latency        time.Duration
+	Error          string
+	ConnectedUsers int
+	Subscriptions  int
+}
+
+type healthSummary struct {
+	Instances []instanceHealth
+
+	// The webhook events posted to, or that failed to be posted to, the
+	// subscribed channels since the plugin was activated on this server.
+	Delivered int64
+	Failed    int64
+}
+
+// parseHealthSummaryInterval returns the time between two health summaries,
+// set in hours.
+func parseHealthSummaryInterval(setting string) (time.Duration, error) {
+	setting = strings.TrimSpace(setting)
+	if setting == "" {
+		return defaultHealthSummaryInterval, nil
+	}
+	hours, err := strconv.Atoi(setting)
+	if err != nil || hours < 1 || hours > maxHealthSummaryIntervalHours {
+		return 0, errors.Errorf("invalid health summary interval %q, it must be a number of hours between 1 and %d", setting, maxHealthSummaryIntervalHours)
+	}
+	return time.Duration(hours) * time.Hour, nil
+}
+
+// healthSummaryDue reports whether the health summary should be posted. The
+// job runs on rounded intervals, a summary is due up to half of one early so
+// that it does not drift later by one interval each time.
+func healthSummaryDue(lastPosted, now time.Time, interval time.Duration) bool {
+	if lastPosted.IsZero() {
+		return true
+	}
+	return now.Sub(lastPosted) >= interval-healthSummaryCheckInterval/2
+}
+
+// probeInstance times an unauthenticated request to the server info of the
+// instance, which Jira answers without a connection.
+func (p *Plugin) probeInstance(instance Instance) (time.Duration, error) {
+	httpClient, err := p.instanceHTTPClient(instance.GetID())
+	if err != nil {
+		return 0, err
+	}
+	probe := *httpClient
+	probe.Timeout = healthSummaryProbeTimeout
+
+	start := time.Now()
+	resp, err := probe.Get(strings.TrimSuffix(instance.GetJiraBaseURL(), "/") + "/rest/api/2/serverInfo")
+	latency := time.Since(start)
+	if err != nil {
+		return latency, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return latency, errors.Errorf("Jira returned http status code %d", resp.StatusCode)
+	}
+	return latency, nil
+}
+
+// collectHealthSummary probes the installed instances, and counts their
+// connected users and subscriptions.
+func (p *Plugin) collectHealthSummary() (*healthSummary, error) {
+	instances, err := p.instanceStore.LoadInstances()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to load the Jira instances")
+	}
+
+	connectedUsers := map[types.ID]int{}
+	err = p.userStore.MapUsers(func(user *User) error {
+		if user.ConnectedInstances.IsEmpty() {
+			return nil
+		}
+		for _, instanceID := range user.ConnectedInstances.IDs() {
+			connectedUsers[instanceID]++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to count the connected users")
+	}
+
+	metrics := p.metrics.snapshot()
+	summary := &healthSummary{
+		Delivered: metrics.WebhookDeliveries,
+		Failed:    metrics.WebhookDeliveryFailures,
+	}
+	if instances.IsEmpty() {
+		return summary, nil
+	}
+	for _, instanceID := range instances.IDs() {
+		health := instanceHealth{
+			InstanceID:     instanceID,
+			Alias:          instances.Get(instanceID).Alias,
+			ConnectedUsers: connectedUsers[instanceID],
+		}
+
+		instance, err := p.instanceStore.LoadInstance(instanceID)
+		if err != nil {
+			health.Error = "the instance could not be loaded"
+		} else {
+			health.Latency, err = p.probeInstance(instance)
+			if err != nil {
+				health.Error = err.Error()
+			} else {
+				health.Reachable = true
+			}
+		}
+
+		subs, err := p.getSubscriptions(instanceID)
+		if err == nil {
+			health.Subscriptions = len(subs.Channel.ByID)
+		}
+		summary.Instances = append(summary.Instances, health)
+	}
+	return summary, nil
+}
+
+// mdHealthSummary renders the health summary as a markdown table of the
+// instances, followed by the notification error rate.
+func mdHealthSummary(summary *healthSummary) string {
+	var b strings.Builder
+	b.WriteString("#### Jira health summary\n")
+	if len(summary.Instances) == 0 {
+		b.WriteString("No Jira instances are installed.\n")
+	} else {
+		b.WriteString("| Instance | Status | Latency | Connected users | Subscriptions |\n")
+		b.WriteString("| --- | --- | --- | --- | --- |\n")
+		for _, health := range summary.Instances {
+			name := health.InstanceID.String()
+			if health.Alias != "" {
+				name += " (" + health.Alias + ")"
+			}
+			status := ":white_check_mark: Reachable"
+			latency := fmt.Sprintf("%d ms", health.Latency.Milliseconds())
+			if !health.Reachable {
+				status = ":x: Unreachable: " + health.Error
+				if health.Latency == 0 {
+					latency = "-"
+				}
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %d | %d |\n",
+				escapeTableCell(name), escapeTableCell(status), latency, health.ConnectedUsers, health.Subscriptions)
+		}
+	}
+
+	b.WriteString("\nNotifications since the plugin started on this server: ")
+	total := summary.Delivered + summary.Failed
+	if total == 0 {
+		b.WriteString("none.")
+	} else {
+		fmt.Fprintf(&b, "%d delivered, %d failed (%.1f%% error rate).",
+			summary.Delivered, summary.Failed, float64(summary.Failed)*100/float64(total))
+	}
+	return b.String()
+}
+
+// checkHealthSummaryChannel returns why the bot cannot post to the channel of
+// the health summary, if it cannot.
+func (p *Plugin) checkHealthSummaryChannel(channelID string) error {
+	channel, err := p.client.Channel.Get(channelID)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get the channel")
+	}
+	if channel.DeleteAt > 0 {
+		return errors.New("the channel is archived")
+	}
+	if _, err = p.client.Channel.GetMember(channelID, p.getUserID()); err != nil {
+		return errors.WithMessage(err, "the bot is not a member of the channel")
+	}
+	return nil
+}
+
+// postScheduledHealthSummary posts the health summary to the channel set in
+// the plugin settings, when it is due.
+func (p *Plugin) postScheduledHealthSummary(now time.Time) {
+	conf := p.getConfig()
+	channelID := strings.TrimSpace(conf.HealthSummaryChannelID)
+	if channelID == "" {
+		return
+	}
+
+	var lastPosted int64
+	if err := p.client.KV.Get(keyHealthSummaryLastPosted, &lastPosted); err != nil {
+		p.client.Log.Warn("Failed to load the time of the last health summary", "error", err.Error())
+		return
+	}
+	last := time.Time{}
+	if lastPosted > 0 {
+		last = time.Unix(lastPosted, 0)
+	}
+	if !healthSummaryDue(last, now, conf.healthSummaryInterval) {
+		return
+	}
+
+	// The summary is marked as posted first: an inaccessible channel is
+	// warned about once per interval rather than at every check.
+	if _, err := p.client.KV.Set(keyHealthSummaryLastPosted, now.Unix()); err != nil {
+		p.client.Log.Warn("Failed to store the time of the last health summary", "error", err.Error())
+		return
+	}
+	if err := p.checkHealthSummaryChannel(channelID); err != nil {
+		p.client.Log.Warn("Skipped the health summary, its channel is not accessible", "ChannelID", channelID, "error", err.Error())
+		return
+	}
+
+	summary, err := p.collectHealthSummary()
+	if err != nil {
+		p.client.Log.Warn("Failed to collect the health summary", "error", err.Error())
+		return
+	}
+	if err = p.createPost(makePost(p.getUserID(), channelID, mdHealthSummary(summary))); err != nil {
+		p.client.Log.Warn("Failed to post the health summary", "ChannelID", channelID, "error", err.Error())
+	}
+}
+
+func executeInstanceHealthSummary(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	authorized, err := authorizedSysAdmin(p, header.UserId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira instance health-summary` can only be run by a system administrator.")
+	}
+	if len(args) != 0 {
+		return p.help(header)
+	}
+
+	summary, err := p.collectHealthSummary()
+	if err != nil {
+		return p.responsef(header, "Failed to collect the health summary. Error: %v.", err)
+	}
+	msg := mdHealthSummary(summary)
+	if strings.TrimSpace(p.getConfig().HealthSummaryChannelID) == "" {
+		msg += "\n\nSet the Health Summary Channel ID in the plugin settings to post this summary regularly."
+	}
+	return p.responsef(header, "%s", msg)
+}
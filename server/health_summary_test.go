@@ -0,0 +1,112 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHealthSummaryInterval(t *testing.T) {
+	interval, err := parseHealthSummaryInterval("")
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, interval)
+
+	interval, err = parseHealthSummaryInterval(" 6 ")
+	require.NoError(t, err)
+	assert.Equal(t, 6*time.Hour, interval)
+
+	for _, invalid := range []string{"0", "-1", "169", "daily", "1.5"} {
+		_, err = parseHealthSummaryInterval(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestHealthSummaryDue(t *testing.T) {
+	posted := time.Date(2026, 10, 14, 9, 0, 3, 0, time.UTC)
+
+	assert.True(t, healthSummaryDue(time.Time{}, posted, 24*time.Hour))
+	assert.False(t, healthSummaryDue(posted, posted.Add(12*time.Hour), 24*time.Hour))
+	assert.True(t, healthSummaryDue(posted, time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC), 24*time.Hour),
+		"the check on the next rounded interval must not wait for the seconds of the last post")
+	assert.False(t, healthSummaryDue(posted, time.Date(2026, 10, 15, 8, 45, 0, 0, time.UTC), 24*time.Hour))
+}
+
+func TestMdHealthSummary(t *testing.T) {
+	t.Run("instances", func(t *testing.T) {
+		msg := mdHealthSummary(&healthSummary{
+			Instances: []instanceHealth{
+				{InstanceID: mockInstance1URL, Alias: "prod", Reachable: true, Latency: 123 * time.Millisecond, ConnectedUsers: 4, Subscriptions: 7},
+				{InstanceID: mockInstance2URL, Error: "dial tcp: i/o timeout | retry", ConnectedUsers: 1},
+			},
+			Delivered: 117,
+			Failed:    3,
+		})
+		assert.Equal(t, "#### Jira health summary\n"+
+			"| Instance | Status | Latency | Connected users | Subscriptions |\n"+
+			"| --- | --- | --- | --- | --- |\n"+
+			"| https://jiraurl1.com (prod) | :white_check_mark: Reachable | 123 ms | 4 | 7 |\n"+
+			"| https://jiraurl2.com | :x: Unreachable: dial tcp: i/o timeout \\| retry | - | 1 | 0 |\n"+
+			"\nNotifications since the plugin started on this server: 117 delivered, 3 failed (2.5% error rate).", msg)
+	})
+
+	t.Run("nothing installed nor notified", func(t *testing.T) {
+		assert.Equal(t, "#### Jira health summary\nNo Jira instances are installed.\n"+
+			"\nNotifications since the plugin started on this server: none.", mdHealthSummary(&healthSummary{}))
+	})
+}
+
+func TestPostScheduledHealthSummary(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+
+	setup := func(lastPosted []byte) (*Plugin, *plugintest.API) {
+		p := &Plugin{}
+		p.updateConfig(func(conf *config) {
+			conf.botUserID = "bot_id"
+			conf.HealthSummaryChannelID = "ops_channel"
+			conf.healthSummaryInterval = 24 * time.Hour
+		})
+		api := &plugintest.API{}
+		api.On("KVGet", keyHealthSummaryLastPosted).Return(lastPosted, nil)
+		p.SetAPI(api)
+		p.client = pluginapi.NewClient(api, p.Driver)
+		return p, api
+	}
+
+	t.Run("not due", func(t *testing.T) {
+		p, api := setup([]byte("1791961200")) // 7:00 UTC
+		defer api.AssertExpectations(t)
+		p.postScheduledHealthSummary(now)
+	})
+
+	t.Run("the bot is not in the channel", func(t *testing.T) {
+		p, api := setup(nil)
+		defer api.AssertExpectations(t)
+		api.On("KVSetWithOptions", keyHealthSummaryLastPosted, []byte("1791968400"), mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+		api.On("GetChannel", "ops_channel").Return(&model.Channel{Id: "ops_channel"}, nil)
+		api.On("GetChannelMember", "ops_channel", "bot_id").Return(nil, model.NewAppError("GetChannelMember", "not found", nil, "", http.StatusNotFound))
+		api.On("LogWarn", "Skipped the health summary, its channel is not accessible",
+			"ChannelID", "ops_channel", "error", mock.AnythingOfType("string")).Return()
+
+		p.postScheduledHealthSummary(now)
+		api.AssertNotCalled(t, "CreatePost", mock.Anything)
+	})
+
+	t.Run("no channel", func(t *testing.T) {
+		p, api := setup(nil)
+		p.updateConfig(func(conf *config) {
+			conf.HealthSummaryChannelID = ""
+		})
+		p.postScheduledHealthSummary(now)
+		api.AssertNotCalled(t, "KVGet", keyHealthSummaryLastPosted)
+	})
+}
@@ -28,29 +28,41 @@ import (
 const (
 	routeAPIGetTeamFields                       = "/get-team-fields"
 	routeAPIGetCommentVisibilityFields          = "/get-comment-visibility-fields"
+	routeAPIGetIssueLinkTypes                   = "/get-issue-link-types"
 	routeAutocomplete                           = "/autocomplete"
 	routeAutocompleteConnect                    = "/connect"
 	routeAutocompleteUserInstance               = "/user-instance"
 	routeAutocompleteInstalledInstance          = "/installed-instance"
 	routeAutocompleteInstalledInstanceWithAlias = "/installed-instance-with-alias"
+	routeAutocompleteBoards                     = "/boards"
 	routeAPI                                    = "/api/v2"
 	routeInstancePath                           = "/instance/{id}"
 	routeAPICreateIssue                         = "/create-issue"
 	routeAPIGetCreateIssueMetadata              = "/get-create-issue-metadata-for-project"
 	routeAPIGetJiraProjectMetadata              = "/get-jira-project-metadata"
+	routeAPIGetIssueTemplate                    = "/get-issue-template"
 	routeAPIGetSearchIssues                     = "/get-search-issues"
 	routeAPIGetAutoCompleteFields               = "/get-search-autocomplete-fields"
 	routeAPIGetSearchUsers                      = "/get-search-users"
 	routeAPIAttachCommentToIssue                = "/attach-comment-to-issue"
+	routeAPICreateSubtasksFromPost              = "/create-subtasks-from-post"
 	routeAPIUserInfo                            = "/userinfo"
 	routeAPISubscribeWebhook                    = "/webhook"
+	routeAPISubscriptionWebhook                 = "/subscription-webhook"
 	routeAPISubscriptionsChannel                = "/subscriptions/channel"
 	routeAPISubscriptionTemplates               = "/subscription-templates"
 	routeAPISubscriptionsChannelWithID          = routeAPISubscriptionsChannel + "/{id:[A-Za-z0-9]+}"
 	routeAPISubscriptionTemplatesWithID         = routeAPISubscriptionTemplates + "/{id:[A-Za-z0-9]+}"
 	routeAPISettingsInfo                        = "/settingsinfo"
 	routeIssueTransition                        = "/transition"
+	routeIssueAssignToMe                        = "/assign-to-me"
+	routeIssueTransitionPicker                  = "/transition-picker"
+	routeIssueCommentDialog                     = "/comment-dialog"
+	routeIssueCommentDialogSubmit               = "/comment-dialog/submit"
+	routeIssueSnooze                            = "/snooze-issue"
+	routeSearchPage                             = "/search-page"
 	routeAPIUserDisconnect                      = "/api/v3/disconnect"
+	routeAPIUserForceReconnect                  = "/force-reconnect"
 	routeACInstalled                            = "/ac/installed"
 	routeACJSON                                 = "/ac/atlassian-connect.json"
 	routeACUninstalled                          = "/ac/uninstalled"
@@ -59,6 +71,7 @@ const (
 	routeACUserConnected                        = "/ac/user_connected.html"
 	routeACUserDisconnected                     = "/ac/user_disconnected.html"
 	routeIncomingWebhook                        = "/webhook"
+	routeIncomingAutomationWebhook              = "/automation"
 	routeOAuth1Complete                         = "/oauth1/complete.html"
 	routeUserStart                              = "/user/start"
 	routeUserConnect                            = "/user/connect"
@@ -100,21 +113,32 @@ func (p *Plugin) initializeRouter() {
 	autocompleteRouter.HandleFunc(routeAutocompleteUserInstance, p.checkAuth(p.handleResponse(p.httpAutocompleteUserInstance))).Methods(http.MethodGet)
 	autocompleteRouter.HandleFunc(routeAutocompleteInstalledInstance, p.checkAuth(p.handleResponse(p.httpAutocompleteInstalledInstance))).Methods(http.MethodGet)
 	autocompleteRouter.HandleFunc(routeAutocompleteInstalledInstanceWithAlias, p.checkAuth(p.handleResponse(p.httpAutocompleteInstalledInstanceWithAlias))).Methods(http.MethodGet)
+	autocompleteRouter.HandleFunc(routeAutocompleteBoards, p.checkAuth(p.handleResponse(p.httpAutocompleteBoards))).Methods(http.MethodGet)
 
 	apiRouter := p.router.PathPrefix(routeAPI).Subrouter()
 
 	// Issue APIs
 	apiRouter.HandleFunc(routeAPIGetTeamFields, p.checkAuth(p.handleResponse(p.httpGetTeamFields))).Methods(http.MethodGet)
 	apiRouter.HandleFunc(routeAPIGetCommentVisibilityFields, p.checkAuth(p.handleResponse(p.httpGetCommentVisibilityFields))).Methods(http.MethodGet)
+	apiRouter.HandleFunc(routeAPIGetIssueLinkTypes, p.checkAuth(p.handleResponse(p.httpGetIssueLinkTypes))).Methods(http.MethodGet)
 	apiRouter.HandleFunc(routeAPIGetAutoCompleteFields, p.checkAuth(p.handleResponse(p.httpGetAutoCompleteFields))).Methods(http.MethodGet)
 	apiRouter.HandleFunc(routeAPICreateIssue, p.checkAuth(p.handleResponse(p.httpCreateIssue))).Methods(http.MethodPost)
 	apiRouter.HandleFunc(routeAPIGetCreateIssueMetadata, p.checkAuth(p.handleResponse(p.httpGetCreateIssueMetadataForProjects))).Methods(http.MethodGet)
 	apiRouter.HandleFunc(routeAPIGetJiraProjectMetadata, p.checkAuth(p.handleResponse(p.httpGetJiraProjectMetadata))).Methods(http.MethodGet)
+	apiRouter.HandleFunc(routeAPIGetIssueTemplate, p.checkAuth(p.handleResponse(p.httpGetIssueTemplate))).Methods(http.MethodGet)
 	apiRouter.HandleFunc(routeAPIGetSearchIssues, p.checkAuth(p.handleResponse(p.httpGetSearchIssues))).Methods(http.MethodGet)
 	apiRouter.HandleFunc(routeAPIGetSearchUsers, p.checkAuth(p.handleResponse(p.httpGetSearchUsers))).Methods(http.MethodGet)
 	apiRouter.HandleFunc(routeAPIAttachCommentToIssue, p.checkAuth(p.handleResponse(p.httpAttachCommentToIssue))).Methods(http.MethodPost)
+	apiRouter.HandleFunc(routeAPICreateSubtasksFromPost, p.checkAuth(p.handleResponse(p.httpCreateSubtasksFromPost))).Methods(http.MethodPost)
 	apiRouter.HandleFunc(routeIssueTransition, p.handleResponse(p.httpTransitionIssuePostAction)).Methods(http.MethodPost)
+	apiRouter.HandleFunc(routeIssueAssignToMe, p.handleResponse(p.httpAssignToMePostAction)).Methods(http.MethodPost)
+	apiRouter.HandleFunc(routeIssueTransitionPicker, p.handleResponse(p.httpTransitionPickerPostAction)).Methods(http.MethodPost)
+	apiRouter.HandleFunc(routeIssueCommentDialog, p.handleResponse(p.httpOpenCommentDialog)).Methods(http.MethodPost)
+	apiRouter.HandleFunc(routeIssueCommentDialogSubmit, p.handleResponse(p.httpSubmitCommentDialog)).Methods(http.MethodPost)
+	apiRouter.HandleFunc(routeIssueSnooze, p.handleResponse(p.httpSnoozeIssue)).Methods(http.MethodPost)
+	apiRouter.HandleFunc(routeSearchPage, p.handleResponse(p.httpSearchPage)).Methods(http.MethodPost)
 	apiRouter.HandleFunc(routeSharePublicly, p.handleResponse(p.httpShareIssuePublicly)).Methods(http.MethodPost)
+	apiRouter.HandleFunc(routeAPIUserForceReconnect, p.handleResponse(p.httpForceReconnectPostAction)).Methods(http.MethodPost)
 	apiRouter.HandleFunc(routeGetIssueByKey, p.handleResponse(p.httpGetIssueByKey)).Methods(http.MethodGet)
 
 	// User APIs
@@ -146,11 +170,15 @@ func (p *Plugin) initializeRouter() {
 
 	// Firehose webhook setup for channel subscriptions
 	instanceRouter.HandleFunc(makeAPIRoute(routeAPISubscribeWebhook), p.handleResponseWithCallbackInstance(p.httpSubscribeWebhook)).Methods(http.MethodPost)
+	instanceRouter.HandleFunc(makeAPIRoute(routeAPISubscriptionWebhook), p.handleResponseWithCallbackInstance(p.httpSubscriptionWebhook)).Methods(http.MethodPost)
 
 	// To support Plugin v2.x webhook URLs
 	apiRouter.HandleFunc(routeAPISubscribeWebhook, p.handleResponseWithCallbackInstance(p.httpSubscribeWebhook)).Methods(http.MethodPost)
 	instanceRouter.HandleFunc(routeIncomingWebhook, p.handleResponseWithCallbackInstance(p.httpWebhook)).Methods(http.MethodPost)
 
+	// Custom payloads of Jira automation rules
+	instanceRouter.HandleFunc(routeIncomingAutomationWebhook, p.handleResponseWithCallbackInstance(p.httpAutomationWebhook)).Methods(http.MethodPost)
+
 	// Channel Subscriptions
 	apiRouter.HandleFunc(routeAPISubscriptionsChannelWithID, p.checkAuth(p.handleResponse(p.httpChannelGetSubscriptions))).Methods(http.MethodGet)
 	apiRouter.HandleFunc(routeAPISubscriptionsChannel, p.checkAuth(p.handleResponse(p.httpChannelCreateSubscription))).Methods(http.MethodPost)
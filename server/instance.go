@@ -42,6 +42,18 @@ type InstanceCommon struct {
 	IsV2Legacy bool
 
 	SetupWizardUserID string
+
+	// RequestTimeoutSeconds is the timeout of the requests made to Jira on
+	// behalf of the users, 0 for none. The check of the Jira URL on install
+	// keeps its own.
+	RequestTimeoutSeconds int `json:",omitempty"`
+
+	// DefaultJQL is the query of `/jira search` when it is given none.
+	DefaultJQL string `json:",omitempty"`
+
+	// DefaultIssueType is the name of the issue type that `/jira create`
+	// picks when none is given, unless the channel has its own.
+	DefaultIssueType string `json:",omitempty"`
 }
 
 func newInstanceCommon(p *Plugin, instanceType InstanceType, instanceID types.ID) *InstanceCommon {
@@ -0,0 +1,216 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/dghubble/oauth1"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils"
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	instanceCAKey = "ca_certificates"
+	caClear       = "clear"
+)
+
+// pemBlockRegexp matches the PEM blocks of a bundle that was pasted in a
+// slash command, where the line breaks may have been turned into spaces.
+var pemBlockRegexp = regexp.MustCompile(`-----BEGIN ([A-Z ]+)-----([A-Za-z0-9+/=\s]*)-----END ([A-Z ]+)-----`)
+
+// caHTTPClients caches the HTTP clients by PEM bundle, so that they, and
+// their connection pools, are reused across requests.
+var caHTTPClients sync.Map
+
+// normalizePEM restores the line breaks of a PEM bundle.
+func normalizePEM(bundle string) string {
+	blocks := []string{}
+	for _, m := range pemBlockRegexp.FindAllStringSubmatch(bundle, -1) {
+		body := strings.Join(strings.Fields(m[2]), "")
+		lines := []string{"-----BEGIN " + m[1] + "-----"}
+		for len(body) > 64 {
+			lines = append(lines, body[:64])
+			body = body[64:]
+		}
+		if body != "" {
+			lines = append(lines, body)
+		}
+		lines = append(lines, "-----END "+m[3]+"-----")
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+	return strings.Join(blocks, "\n") + "\n"
+}
+
+// parseCABundle returns the system root certificates, along with the
+// certificates of a PEM bundle.
+func parseCABundle(bundle string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	rest := []byte(bundle)
+	count := 0
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, errors.Errorf("the bundle contains a %s, only certificates are expected", strings.ToLower(block.Type))
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to parse a certificate of the bundle")
+		}
+		pool.AddCert(cert)
+		count++
+	}
+	if count == 0 {
+		return nil, errors.New("no PEM encoded certificate was found")
+	}
+	return pool, nil
+}
+
+func newCAHTTPClient(bundle string) (*http.Client, error) {
+	if cached, ok := caHTTPClients.Load(bundle); ok {
+		return cached.(*http.Client), nil
+	}
+
+	pool, err := parseCABundle(bundle)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+	client := &http.Client{Transport: transport}
+
+	cached, _ := caHTTPClients.LoadOrStore(bundle, client)
+	return cached.(*http.Client), nil
+}
+
+func (p *Plugin) storeInstanceCA(instanceID types.ID, bundle string) error {
+	encrypted, err := encrypt([]byte(bundle), []byte(p.getConfig().EncryptionKey))
+	if err != nil {
+		return errors.WithMessage(err, "failed to encrypt the CA certificates")
+	}
+	_, err = p.client.KV.Set(keyWithInstanceID(instanceID, instanceCAKey), encode(encrypted))
+	return err
+}
+
+// loadInstanceCA returns the PEM bundle of the CA certificates trusted for
+// the instance, "" if there is none.
+func (p *Plugin) loadInstanceCA(instanceID types.ID) (string, error) {
+	var encoded string
+	if err := p.client.KV.Get(keyWithInstanceID(instanceID, instanceCAKey), &encoded); err != nil {
+		return "", err
+	}
+	if encoded == "" {
+		return "", nil
+	}
+
+	encrypted, err := decode(encoded)
+	if err != nil {
+		return "", err
+	}
+	bundle, err := decrypt(encrypted, []byte(p.getConfig().EncryptionKey))
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to decrypt the CA certificates")
+	}
+	return string(bundle), nil
+}
+
+func (p *Plugin) deleteInstanceCA(instanceID types.ID) error {
+	return p.client.KV.Delete(keyWithInstanceID(instanceID, instanceCAKey))
+}
+
+// instanceHTTPClient returns the HTTP client to use to call the instance. It
+// trusts the CA certificates that were added for the instance, if any, and
+// sends its custom headers.
+func (p *Plugin) instanceHTTPClient(instanceID types.ID) (*http.Client, error) {
+	bundle, err := p.loadInstanceCA(instanceID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to load the CA certificates of "+instanceID.String())
+	}
+	headers, err := p.loadInstanceHeaders(instanceID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to load the custom headers of "+instanceID.String())
+	}
+
+	httpClient := http.DefaultClient
+	if bundle != "" {
+		httpClient, err = newCAHTTPClient(bundle)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return withHeaders(httpClient, headers), nil
+}
+
+// instanceOAuth1Context passes the HTTP client of the instance to the OAuth1
+// clients.
+func (p *Plugin) instanceOAuth1Context(instanceID types.ID) (context.Context, error) {
+	httpClient, err := p.instanceHTTPClient(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	if httpClient == http.DefaultClient {
+		return oauth1.NoContext, nil
+	}
+	return context.WithValue(oauth1.NoContext, oauth1.HTTPClient, httpClient), nil
+}
+
+func executeInstanceCA(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	authorized, err := authorizedSysAdmin(p, header.UserId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira instance ca` can only be run by a system administrator.")
+	}
+	if len(args) < 2 {
+		return p.responsef(header, "Please specify a Jira URL and a PEM encoded CA certificate bundle, `/jira instance ca [jiraURL] [PEM]`, or `/jira instance ca [jiraURL] clear` to remove it.")
+	}
+
+	jiraURL, err := utils.NormalizeJiraURL(args[0])
+	if err != nil {
+		return p.responsef(header, "Failed to parse the Jira URL. Error: %v.", err)
+	}
+	if utils.IsJiraCloudURL(jiraURL) {
+		return p.responsef(header, "`%s` is a Jira Cloud URL, custom CA certificates are only supported for Jira Server or Data Center.", jiraURL)
+	}
+	instanceID := types.ID(jiraURL)
+
+	if len(args) == 2 && strings.EqualFold(args[1], caClear) {
+		if err = p.deleteInstanceCA(instanceID); err != nil {
+			return p.responsef(header, "Failed to remove the CA certificates. Error: %v.", err)
+		}
+		return p.responsef(header, "Removed the CA certificates of %s, the system CA certificates are used.", jiraURL)
+	}
+
+	bundle := normalizePEM(strings.Join(args[1:], " "))
+	if _, err = parseCABundle(bundle); err != nil {
+		return p.responsef(header, "Invalid CA certificate bundle. Error: %v.", err)
+	}
+	if err = p.storeInstanceCA(instanceID, bundle); err != nil {
+		return p.responsef(header, "Failed to store the CA certificates. Error: %v.", err)
+	}
+	return p.responsef(header, "The CA certificates are now trusted for %s, in addition to the system ones.", jiraURL)
+}
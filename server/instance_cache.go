@@ -0,0 +1,90 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// instanceCachePruneSize is the number of entries of an instance at which,
+// and at each multiple of which, the expired ones are dropped, so that the
+// entries that are never read again do not pile up.
+const instanceCachePruneSize = 1000
+
+type instanceCacheEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// instanceCache remembers what the plugin fetched from the Jira instances, by
+// instance and by key, e.g. the issue types of each project, for a while. All
+// that is cached about an instance is dropped at once when it changes. The
+// zero value is ready to use.
+type instanceCache[V any] struct {
+	lock    sync.Mutex
+	entries map[types.ID]map[string]instanceCacheEntry[V]
+	now     func() time.Time
+}
+
+func (c *instanceCache[V]) timeNow() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *instanceCache[V]) get(instanceID types.ID, key string) (V, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var none V
+	entry, ok := c.entries[instanceID][key]
+	if !ok {
+		return none, false
+	}
+	if !entry.expires.IsZero() && c.timeNow().After(entry.expires) {
+		delete(c.entries[instanceID], key)
+		return none, false
+	}
+	return entry.value, true
+}
+
+// set remembers the value for ttl, or until the instance changes when ttl is
+// 0.
+func (c *instanceCache[V]) set(instanceID types.ID, key string, value V, ttl time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[types.ID]map[string]instanceCacheEntry[V]{}
+	}
+	if c.entries[instanceID] == nil {
+		c.entries[instanceID] = map[string]instanceCacheEntry[V]{}
+	}
+	entries := c.entries[instanceID]
+	if _, ok := entries[key]; !ok && len(entries) > 0 && len(entries)%instanceCachePruneSize == 0 {
+		now := c.timeNow()
+		for k, e := range entries {
+			if !e.expires.IsZero() && now.After(e.expires) {
+				delete(entries, k)
+			}
+		}
+	}
+
+	entry := instanceCacheEntry[V]{value: value}
+	if ttl > 0 {
+		entry.expires = c.timeNow().Add(ttl)
+	}
+	entries[key] = entry
+}
+
+// invalidate drops all that is cached for an instance.
+func (c *instanceCache[V]) invalidate(instanceID types.ID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, instanceID)
+}
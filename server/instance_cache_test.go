@@ -0,0 +1,47 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceCache(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	c := instanceCache[jiraServerInfo]{now: func() time.Time { return now }}
+
+	_, ok := c.get(testInstance1.InstanceID, "")
+	assert.False(t, ok)
+
+	c.set(testInstance1.InstanceID, "", jiraServerInfo{Version: "9.12.2", DeploymentType: "Server"}, serverInfoTTL)
+	c.set(testInstance1.InstanceID, "forever", jiraServerInfo{Version: "8.20.0"}, 0)
+	info, ok := c.get(testInstance1.InstanceID, "")
+	require.True(t, ok)
+	assert.Equal(t, "9.12.2", info.Version)
+	_, ok = c.get(testInstance2.InstanceID, "")
+	assert.False(t, ok)
+
+	now = now.Add(serverInfoTTL + time.Second)
+	_, ok = c.get(testInstance1.InstanceID, "")
+	assert.False(t, ok, "the entry expired")
+	_, ok = c.get(testInstance1.InstanceID, "forever")
+	assert.True(t, ok, "the entry without a ttl is kept")
+
+	c.invalidate(testInstance1.InstanceID)
+	_, ok = c.get(testInstance1.InstanceID, "forever")
+	assert.False(t, ok, "the entries of the instance are dropped")
+
+	c.set(testInstance1.InstanceID, "forever", jiraServerInfo{}, 0)
+	for i := 1; i < instanceCachePruneSize; i++ {
+		c.set(testInstance1.InstanceID, strconv.Itoa(i), jiraServerInfo{}, time.Minute)
+	}
+	now = now.Add(2 * time.Minute)
+	c.set(testInstance1.InstanceID, "new", jiraServerInfo{}, time.Minute)
+	assert.Len(t, c.entries[testInstance1.InstanceID], 2, "the expired entries are pruned")
+}
@@ -30,8 +30,8 @@ type cloudInstance struct {
 	// Initially a new instance is created with an expiration time. The
 	// admin is expected to upload it to the Jira instance, and we will
 	// then receive a /installed callback that initializes the instance
-	// and makes it permanent. No subsequent /installed will be accepted
-	// for the instance.
+	// and makes it permanent. Subsequent /installed callbacks are only
+	// accepted as retries of the same installation.
 	Installed bool
 
 	// For cloud instances (atlassian-connect.json install and user auth)
@@ -89,6 +89,14 @@ func (p *Plugin) installInactiveCloudInstance(rawURL string, actingUserID string
 	return jiraURL, err
 }
 
+// isSameInstallation returns true if the security context is the one the
+// instance was installed with, as sent again by a retried callback.
+func (ci *cloudInstance) isSameInstallation(asc *AtlassianSecurityContext) bool {
+	return ci.AtlassianSecurityContext != nil &&
+		ci.AtlassianSecurityContext.ClientKey == asc.ClientKey &&
+		ci.AtlassianSecurityContext.SharedSecret == asc.SharedSecret
+}
+
 func (ci *cloudInstance) GetMattermostKey() string {
 	return ci.AtlassianSecurityContext.Key
 }
@@ -202,10 +210,11 @@ func cloudManageWebhooksURL(jiraURL string) string {
 }
 
 func (ci *cloudInstance) GetClient(connection *Connection) (Client, error) {
-	client, _, err := ci.getClientForConnection(connection)
+	client, httpClient, err := ci.getClientForConnection(connection)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to get Jira client for user "+connection.DisplayName)
 	}
+	ci.applyRequestTimeout(httpClient)
 	return newCloudClient(client), nil
 }
 
@@ -229,6 +238,7 @@ func (ci *cloudInstance) getClientForConnection(connection *Connection) (*jira.C
 	httpClient = utils.WrapHTTPClient(httpClient,
 		utils.WithRequestSizeLimit(conf.maxAttachmentSize),
 		utils.WithResponseSizeLimit(conf.maxAttachmentSize))
+	httpClient = ci.metrics.withMetrics(httpClient)
 
 	jiraClient, err := jira.NewClient(httpClient, oauth2Conf.BaseURL)
 	return jiraClient, httpClient, err
@@ -248,6 +258,7 @@ func (ci *cloudInstance) getClientForBot() (*jira.Client, error) {
 	httpClient = utils.WrapHTTPClient(httpClient,
 		utils.WithRequestSizeLimit(conf.maxAttachmentSize),
 		utils.WithResponseSizeLimit(conf.maxAttachmentSize))
+	httpClient = ci.metrics.withMetrics(httpClient)
 
 	return jira.NewClient(httpClient, jwtConf.BaseURL)
 }
@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	jira "github.com/andygrunwald/go-jira"
 	"github.com/mattermost/mattermost/server/public/model"
@@ -124,10 +126,11 @@ func (p *Plugin) installCloudOAuthInstance(rawURL string) (string, *cloudOAuthIn
 }
 
 func (ci *cloudOAuthInstance) GetClient(connection *Connection) (Client, error) {
-	client, _, err := ci.getClientForConnection(connection)
+	client, httpClient, err := ci.getClientForConnection(connection)
 	if err != nil {
 		return nil, errors.WithMessage(err, fmt.Sprintf("failed to get Jira client for the user %s", connection.DisplayName))
 	}
+	ci.applyRequestTimeout(httpClient)
 	return newCloudClient(client), nil
 }
 
@@ -212,6 +215,66 @@ func (ci *cloudOAuthInstance) GetOAuthConfig() *oauth2.Config {
 	}
 }
 
+// jiraOAuthRevokeURL is where the tokens of a connection are revoked when the
+// user disconnects.
+var jiraOAuthRevokeURL = "https://auth.atlassian.com/oauth/revoke"
+
+const (
+	// revokeTokenTimeout bounds the revocation of a token when the instance
+	// has no request timeout of its own.
+	revokeTokenTimeout = 10 * time.Second
+
+	maxConcurrentTokenRevocations = 4
+)
+
+// tokenRevocations bounds the revocations in progress, so that disconnecting
+// many users at once does not flood Atlassian.
+var tokenRevocations = make(chan struct{}, maxConcurrentTokenRevocations)
+
+// revokeToken asks Atlassian to revoke the refresh token of a connection, so
+// that it cannot be used anymore once the connection is deleted. It is a best
+// effort, the user can still revoke the access of the app in their Atlassian
+// account.
+func (ci *cloudOAuthInstance) revokeToken(connection *Connection) error {
+	if connection.OAuth2Token == nil || connection.OAuth2Token.RefreshToken == "" {
+		return nil
+	}
+	httpClient := &http.Client{Timeout: revokeTokenTimeout}
+	ci.applyRequestTimeout(httpClient)
+	// The token itself does not go in the error.
+	resp, err := httpClient.PostForm(jiraOAuthRevokeURL, url.Values{
+		"token":           {connection.OAuth2Token.RefreshToken},
+		"token_type_hint": {"refresh_token"},
+		"client_id":       {ci.JiraClientID},
+		"client_secret":   {ci.JiraClientSecret},
+	})
+	if err != nil {
+		return errors.New("failed to reach Atlassian")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("Atlassian returned http status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// revokeTokenInBackground revokes the token of a deleted connection without
+// holding up the disconnection, a few at a time.
+func (ci *cloudOAuthInstance) revokeTokenInBackground(connection *Connection) {
+	if connection.OAuth2Token == nil || connection.OAuth2Token.RefreshToken == "" {
+		return
+	}
+	revoked := &Connection{OAuth2Token: &oauth2.Token{RefreshToken: connection.OAuth2Token.RefreshToken}}
+	go func() {
+		tokenRevocations <- struct{}{}
+		defer func() { <-tokenRevocations }()
+
+		if err := ci.revokeToken(revoked); err != nil {
+			ci.Plugin.client.Log.Warn("Failed to revoke the OAuth token of a disconnected user", "instance", ci.GetID().String(), "error", err.Error())
+		}
+	}()
+}
+
 func (ci *cloudOAuthInstance) GetURL() string {
 	return "https://api.atlassian.com/ex/jira/" + ci.JiraResourceID
 }
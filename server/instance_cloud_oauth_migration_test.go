@@ -542,6 +542,7 @@ func TestCloudOAuthMigration(t *testing.T) {
 			api.On("UnregisterCommand", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
 			api.On("RegisterCommand", mock.Anything).Return(nil)
 			api.On("PublishWebSocketEvent", mock.AnythingOfType("string"), mock.Anything, mock.Anything)
+			api.On("PublishPluginClusterEvent", mock.AnythingOfType("model.PluginClusterEvent"), mock.AnythingOfType("model.PluginClusterEventSendOptions")).Return(nil)
 
 			installedInstanceID := tc.setup(p, api)
 
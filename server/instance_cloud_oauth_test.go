@@ -0,0 +1,73 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestCloudOAuthRevokeToken(t *testing.T) {
+	var form map[string]string
+	status := http.StatusOK
+	stuck := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status == 0 {
+			<-stuck
+			return
+		}
+		require.NoError(t, r.ParseForm())
+		form = map[string]string{}
+		for key := range r.PostForm {
+			form[key] = r.PostForm.Get(key)
+		}
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+	defer close(stuck)
+
+	defaultRevokeURL := jiraOAuthRevokeURL
+	jiraOAuthRevokeURL = ts.URL
+	defer func() { jiraOAuthRevokeURL = defaultRevokeURL }()
+
+	ci := &cloudOAuthInstance{
+		InstanceCommon:   &InstanceCommon{RequestTimeoutSeconds: 1},
+		JiraClientID:     "client_id",
+		JiraClientSecret: "client_secret",
+	}
+
+	t.Run("revokes the refresh token", func(t *testing.T) {
+		err := ci.revokeToken(&Connection{OAuth2Token: &oauth2.Token{AccessToken: "access", RefreshToken: "refresh"}})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"token":           "refresh",
+			"token_type_hint": "refresh_token",
+			"client_id":       "client_id",
+			"client_secret":   "client_secret",
+		}, form)
+	})
+
+	t.Run("no token", func(t *testing.T) {
+		form = nil
+		require.NoError(t, ci.revokeToken(&Connection{}))
+		assert.Nil(t, form)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		status = http.StatusUnauthorized
+		err := ci.revokeToken(&Connection{OAuth2Token: &oauth2.Token{RefreshToken: "refresh"}})
+		require.EqualError(t, err, "Atlassian returned http status code 401")
+	})
+
+	t.Run("Atlassian does not answer", func(t *testing.T) {
+		status = 0
+		err := ci.revokeToken(&Connection{OAuth2Token: &oauth2.Token{RefreshToken: "refresh"}})
+		require.EqualError(t, err, "failed to reach Atlassian")
+	})
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const defaultJQLNone = "none"
+
+func executeInstanceSetJQLDefault(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	authorized, err := authorizedSysAdmin(p, header.UserId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira instance set-jql-default` can only be run by a system administrator.")
+	}
+	if len(args) < 2 {
+		return p.responsef(header, "Please specify a Jira instance and a JQL query, `/jira instance set-jql-default [jiraURL] [JQL]`, or `none` instead of the query to remove it.")
+	}
+
+	instances, err := p.instanceStore.LoadInstances()
+	if err != nil {
+		return p.responsef(header, "Failed to load instances. Error: %v.", err)
+	}
+	instanceID := types.ID(args[0])
+	if found := instances.getByAlias(args[0]); found != nil {
+		instanceID = found.InstanceID
+	}
+	instance, err := p.instanceStore.LoadInstance(instanceID)
+	if err != nil {
+		return p.responsef(header, "Failed to load instance. Error: %v.", err)
+	}
+
+	jql := strings.TrimSpace(strings.Join(args[1:], " "))
+	if strings.EqualFold(jql, defaultJQLNone) {
+		jql = ""
+	} else {
+		client, _, _, clientErr := p.getClient(instanceID, types.ID(header.UserId))
+		if clientErr != nil {
+			return p.responsef(header, "Please connect to %s so that the query can be validated. Error: %v.", instanceID, clientErr)
+		}
+		if err = p.validateJQL(instanceID, client, jql); err != nil {
+			return p.responsef(header, "The query cannot be used: %v.", err)
+		}
+	}
+
+	instance.Common().DefaultJQL = jql
+	if err = p.instanceStore.StoreInstance(instance); err != nil {
+		return p.responsef(header, "Failed to save instance. Error: %v.", err)
+	}
+
+	if jql == "" {
+		return p.responsef(header, "`/jira search` on %s now requires a query.", instanceID)
+	}
+	return p.responsef(header, "`/jira search` on %s now runs `%s` when no query is given.", instanceID, jql)
+}
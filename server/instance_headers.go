@@ -0,0 +1,222 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils"
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	instanceHeadersKey = "custom_headers"
+
+	headersSet   = "set"
+	headersClear = "clear"
+	headersAll   = "all"
+)
+
+// headerNameRegexp matches the valid HTTP header names.
+var headerNameRegexp = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
+
+// reservedHeaders are set by the HTTP and OAuth clients, and cannot be
+// overridden by custom headers.
+var reservedHeaders = NewStringSet(
+	"Authorization", "Connection", "Content-Length", "Content-Type", "Cookie",
+	"Host", "Keep-Alive", "Proxy-Authorization", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+)
+
+// headerTransport adds static headers to all the requests sent through it.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+}
+
+func (t *headerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	for name, values := range t.headers {
+		r.Header[name] = values
+	}
+	return t.base.RoundTrip(r)
+}
+
+// withHeaders returns a client sending the headers with all its requests,
+// through the transport of httpClient, which is left unchanged.
+func withHeaders(httpClient *http.Client, headers http.Header) *http.Client {
+	if len(headers) == 0 {
+		return httpClient
+	}
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped := *httpClient
+	wrapped.Transport = &headerTransport{base: base, headers: headers}
+	return &wrapped
+}
+
+// validateCustomHeader returns the canonical name of a custom header.
+func validateCustomHeader(name, value string) (string, error) {
+	if !headerNameRegexp.MatchString(name) {
+		return "", errors.Errorf("`%s` is not a valid header name", name)
+	}
+	name = http.CanonicalHeaderKey(name)
+	if reservedHeaders.ContainsAny(name) {
+		return "", errors.Errorf("the %s header is set by the plugin, it cannot be replaced", name)
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return "", errors.New("the header value cannot span several lines")
+	}
+	return name, nil
+}
+
+// storeInstanceHeaders stores the custom headers of the instance, encrypted
+// since their values are often credentials.
+func (p *Plugin) storeInstanceHeaders(instanceID types.ID, headers http.Header) error {
+	if len(headers) == 0 {
+		return p.client.KV.Delete(keyWithInstanceID(instanceID, instanceHeadersKey))
+	}
+	data, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+	encrypted, err := encrypt(data, []byte(p.getConfig().EncryptionKey))
+	if err != nil {
+		return errors.WithMessage(err, "failed to encrypt the custom headers")
+	}
+	_, err = p.client.KV.Set(keyWithInstanceID(instanceID, instanceHeadersKey), encode(encrypted))
+	return err
+}
+
+// loadInstanceHeaders returns the custom headers sent to the instance, none
+// if there are none.
+func (p *Plugin) loadInstanceHeaders(instanceID types.ID) (http.Header, error) {
+	var encoded string
+	if err := p.client.KV.Get(keyWithInstanceID(instanceID, instanceHeadersKey), &encoded); err != nil {
+		return nil, err
+	}
+	if encoded == "" {
+		return http.Header{}, nil
+	}
+
+	encrypted, err := decode(encoded)
+	if err != nil {
+		return nil, err
+	}
+	data, err := decrypt(encrypted, []byte(p.getConfig().EncryptionKey))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to decrypt the custom headers")
+	}
+	headers := http.Header{}
+	if err = json.Unmarshal(data, &headers); err != nil {
+		return nil, errors.WithMessage(err, "failed to parse the custom headers")
+	}
+	return headers, nil
+}
+
+// mdInstanceHeaders lists the names of the custom headers, never their
+// values.
+func mdInstanceHeaders(instanceID types.ID, headers http.Header) string {
+	if len(headers) == 0 {
+		return fmt.Sprintf("No custom headers are sent to %s.", instanceID)
+	}
+	names := []string{}
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	msg := fmt.Sprintf("Custom headers sent to %s:\n", instanceID)
+	for _, name := range names {
+		msg += fmt.Sprintf("* `%s: ********`\n", name)
+	}
+	return strings.TrimSuffix(msg, "\n")
+}
+
+func executeInstanceHeaders(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	authorized, err := authorizedSysAdmin(p, header.UserId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira instance headers` can only be run by a system administrator.")
+	}
+	usage := "Please use `/jira instance headers [jiraURL]` to list the custom headers, `/jira instance headers [jiraURL] set [name] [value]` to set one, " +
+		"or `/jira instance headers [jiraURL] clear [name|all]` to remove them."
+	if len(args) == 0 {
+		return p.responsef(header, usage)
+	}
+
+	instanceID, err := p.resolveInstanceURL(args[0])
+	if err != nil {
+		return p.responsef(header, "Failed to parse the Jira URL. Error: %v.", err)
+	}
+	if utils.IsJiraCloudURL(instanceID.String()) {
+		return p.responsef(header, "`%s` is a Jira Cloud URL, custom headers are only supported for Jira Server or Data Center.", instanceID)
+	}
+	headers, err := p.loadInstanceHeaders(instanceID)
+	if err != nil {
+		return p.responsef(header, "Failed to load the custom headers. Error: %v.", err)
+	}
+
+	switch {
+	case len(args) == 1:
+		return p.responsef(header, "%s", mdInstanceHeaders(instanceID, headers))
+
+	case len(args) >= 4 && args[1] == headersSet:
+		value := strings.Join(args[3:], " ")
+		name, err := validateCustomHeader(args[2], value)
+		if err != nil {
+			return p.responsef(header, "Invalid header: %v.", err)
+		}
+		headers.Set(name, value)
+		if err = p.storeInstanceHeaders(instanceID, headers); err != nil {
+			return p.responsef(header, "Failed to store the custom headers. Error: %v.", err)
+		}
+		return p.responsef(header, "The %s header is now sent with all the requests to %s.", name, instanceID)
+
+	case len(args) == 3 && args[1] == headersClear:
+		if strings.EqualFold(args[2], headersAll) {
+			headers = http.Header{}
+		} else {
+			name := http.CanonicalHeaderKey(args[2])
+			if headers.Get(name) == "" {
+				return p.responsef(header, "No %s header is sent to %s.", name, instanceID)
+			}
+			headers.Del(name)
+		}
+		if err = p.storeInstanceHeaders(instanceID, headers); err != nil {
+			return p.responsef(header, "Failed to store the custom headers. Error: %v.", err)
+		}
+		return p.responsef(header, "%s", mdInstanceHeaders(instanceID, headers))
+	}
+	return p.responsef(header, usage)
+}
+
+// resolveInstanceURL returns the ID of the instance with the alias, or else
+// the normalized Jira URL, which need not be installed yet.
+func (p *Plugin) resolveInstanceURL(aliasOrURL string) (types.ID, error) {
+	instances, err := p.instanceStore.LoadInstances()
+	if err != nil {
+		return "", err
+	}
+	if found := instances.getByAlias(aliasOrURL); found != nil {
+		return found.InstanceID, nil
+	}
+	jiraURL, err := utils.NormalizeJiraURL(aliasOrURL)
+	if err != nil {
+		return "", err
+	}
+	return types.ID(jiraURL), nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils"
+)
+
+func TestInstanceHeadersHTTPClient(t *testing.T) {
+	received := http.Header{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		if r.Header.Get("X-Gateway-Key") != "s3cr3t" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"state":"RUNNING"}`))
+	}))
+	defer ts.Close()
+
+	t.Run("the gateway rejects requests without the header", func(t *testing.T) {
+		_, err := utils.CheckJiraURLWithClient(http.DefaultClient, mattermostSiteURL, ts.URL, false)
+		require.Error(t, err)
+	})
+
+	t.Run("the headers are sent, including to the availability probe", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("X-Gateway-Key", "s3cr3t")
+		client := withHeaders(http.DefaultClient, headers)
+		assert.NotSame(t, http.DefaultClient, client)
+		assert.Nil(t, http.DefaultClient.Transport)
+
+		jiraURL, err := utils.CheckJiraURLWithClient(client, mattermostSiteURL, ts.URL, false)
+		require.NoError(t, err)
+		assert.Equal(t, ts.URL, jiraURL)
+		assert.Equal(t, "s3cr3t", received.Get("X-Gateway-Key"))
+	})
+
+	t.Run("no headers", func(t *testing.T) {
+		assert.Same(t, http.DefaultClient, withHeaders(http.DefaultClient, http.Header{}))
+	})
+}
+
+func TestValidateCustomHeader(t *testing.T) {
+	name, err := validateCustomHeader("x-gateway-key", "s3cr3t")
+	require.NoError(t, err)
+	assert.Equal(t, "X-Gateway-Key", name)
+
+	for _, invalid := range []string{"X Gateway", "X-Gateway:", "", "authorization", "Host", "content-type"} {
+		_, err = validateCustomHeader(invalid, "value")
+		assert.Error(t, err, invalid)
+	}
+
+	_, err = validateCustomHeader("X-Gateway-Key", "value\r\nHost: evil")
+	assert.Error(t, err)
+}
+
+func TestMDInstanceHeaders(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("X-Gateway-Key", "s3cr3t")
+	headers.Set("X-Tenant", "mattermost")
+
+	msg := mdInstanceHeaders("https://jira.example.com", headers)
+	assert.Equal(t, "Custom headers sent to https://jira.example.com:\n* `X-Gateway-Key: ********`\n* `X-Tenant: ********`", msg)
+	assert.NotContains(t, msg, "s3cr3t")
+
+	assert.Equal(t, "No custom headers are sent to https://jira.example.com.", mdInstanceHeaders("https://jira.example.com", nil))
+}
@@ -27,7 +27,15 @@ type serverInstance struct {
 var _ Instance = (*serverInstance)(nil)
 
 func (p *Plugin) installServerInstance(rawURL string) (string, *serverInstance, error) {
-	jiraURL, err := utils.CheckJiraURL(p.GetSiteURL(), rawURL, false)
+	jiraURL, err := utils.NormalizeJiraURL(rawURL)
+	if err != nil {
+		return "", nil, err
+	}
+	httpClient, err := p.instanceHTTPClient(types.ID(jiraURL))
+	if err != nil {
+		return "", nil, err
+	}
+	jiraURL, err = utils.CheckJiraURLWithClient(httpClient, p.GetSiteURL(), jiraURL, false)
 	if err != nil {
 		return "", nil, err
 	}
@@ -82,7 +90,10 @@ func (si *serverInstance) GetUserConnectURL(mattermostUserID string) (returnURL
 		returnErr = errors.WithMessage(returnErr, "failed to get a connect link")
 	}()
 
-	oauth1Config := si.getOAuth1Config()
+	oauth1Config, err := si.getOAuth1TokenConfig()
+	if err != nil {
+		return "", nil, err
+	}
 	token, secret, err := oauth1Config.RequestToken()
 	if err != nil {
 		return "", nil, err
@@ -114,13 +125,20 @@ func (si *serverInstance) GetClient(connection *Connection) (client Client, retu
 		return nil, errors.New("no access token, please use /jira connect")
 	}
 
+	ctx, err := si.instanceOAuth1Context(si.InstanceID)
+	if err != nil {
+		return nil, err
+	}
+
 	token := oauth1.NewToken(connection.Oauth1AccessToken, connection.Oauth1AccessSecret)
 	conf := si.getConfig()
 
-	httpClient := si.getOAuth1Config().Client(oauth1.NoContext, token)
+	httpClient := si.getOAuth1Config().Client(ctx, token)
 	httpClient = utils.WrapHTTPClient(httpClient,
 		utils.WithRequestSizeLimit(conf.maxAttachmentSize),
 		utils.WithResponseSizeLimit(conf.maxAttachmentSize))
+	httpClient = si.metrics.withMetrics(httpClient)
+	si.applyRequestTimeout(httpClient)
 
 	jiraClient, err := jira.NewClient(httpClient, si.GetURL())
 	if err != nil {
@@ -130,6 +148,22 @@ func (si *serverInstance) GetClient(connection *Connection) (client Client, retu
 	return newServerClient(jiraClient), nil
 }
 
+// getOAuth1TokenConfig returns the OAuth1 configuration to request the tokens
+// with, through the HTTP client of the instance, so that its CA certificates
+// and custom headers are used as for the other requests.
+func (si *serverInstance) getOAuth1TokenConfig() (*oauth1.Config, error) {
+	httpClient, err := si.instanceHTTPClient(si.InstanceID)
+	if err != nil {
+		return nil, err
+	}
+	tokenClient := *httpClient
+	si.applyRequestTimeout(&tokenClient)
+
+	oauth1Config := si.getOAuth1Config()
+	oauth1Config.HTTPClient = &tokenClient
+	return oauth1Config, nil
+}
+
 func (si *serverInstance) getOAuth1Config() *oauth1.Config {
 	p := si.Plugin
 	return &oauth1.Config{
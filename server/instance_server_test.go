@@ -0,0 +1,75 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+func TestServerInstanceOAuth1TokenConfig(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/plugins/servlet/oauth/request-token" || r.Header.Get("X-Gateway-Key") != "s3cr3t" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		_, _ = w.Write([]byte("oauth_token=token&oauth_token_secret=secret&oauth_callback_confirmed=true"))
+	}))
+	defer ts.Close()
+
+	instanceID := types.ID(ts.URL)
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	headers, err := json.Marshal(http.Header{"X-Gateway-Key": []string{"s3cr3t"}})
+	require.NoError(t, err)
+	storedCA, err := json.Marshal(encode(bundle))
+	require.NoError(t, err)
+	storedHeaders, err := json.Marshal(encode(headers))
+	require.NoError(t, err)
+
+	api := &plugintest.API{}
+	api.On("KVGet", keyWithInstanceID(instanceID, instanceCAKey)).Return(storedCA, (*model.AppError)(nil))
+	api.On("KVGet", keyWithInstanceID(instanceID, instanceHeadersKey)).Return(storedHeaders, (*model.AppError)(nil))
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.updateConfig(func(conf *config) {
+		conf.rsaKey, _ = rsa.GenerateKey(rand.Reader, 1024) // #nosec G403
+	})
+
+	si := &serverInstance{
+		InstanceCommon: &InstanceCommon{
+			Plugin:                p,
+			InstanceID:            instanceID,
+			Type:                  ServerInstanceType,
+			RequestTimeoutSeconds: 5,
+		},
+		MattermostKey: "mattermost",
+	}
+
+	oauth1Config, err := si.getOAuth1TokenConfig()
+	require.NoError(t, err)
+	require.NotNil(t, oauth1Config.HTTPClient)
+	assert.Equal(t, 5*time.Second, oauth1Config.HTTPClient.Timeout)
+	assert.Zero(t, http.DefaultClient.Timeout)
+
+	token, secret, err := oauth1Config.RequestToken()
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+	assert.Equal(t, "secret", secret)
+}
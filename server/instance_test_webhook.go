@@ -0,0 +1,201 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	// testWebhookParam marks the events sent by `/jira instance test-webhook`,
+	// which are evaluated against the subscriptions but not posted.
+	testWebhookParam = "test"
+
+	testWebhookTimeout = 10 * time.Second
+)
+
+// testWebhookResult is the response of the subscriptions webhook to a test
+// event.
+type testWebhookResult struct {
+	Events          []string `json:"events"`
+	SubscriptionIDs []string `json:"subscription_ids"`
+}
+
+// httpTestWebhook parses a test event, and responds with the subscriptions
+// that it matches. Nothing is posted.
+func (p *Plugin) httpTestWebhook(w http.ResponseWriter, bb []byte, instanceID types.ID) (int, error) {
+	wh, err := ParseWebhook(bb)
+	if err != nil {
+		return respondErr(w, http.StatusBadRequest, err)
+	}
+	v, ok := wh.(*webhook)
+	if !ok {
+		return respondErr(w, http.StatusBadRequest, errors.New("the test event must be a single issue event"))
+	}
+
+	subs, err := p.getChannelsSubscribed(v, instanceID)
+	if err != nil {
+		return respondErr(w, http.StatusInternalServerError, err)
+	}
+
+	result := testWebhookResult{
+		Events:          v.Events().Elems(),
+		SubscriptionIDs: []string{},
+	}
+	for _, sub := range subs {
+		result.SubscriptionIDs = append(result.SubscriptionIDs, sub.ID)
+	}
+	return respondJSON(w, result)
+}
+
+// testWebhookIssue is the issue of the test event, when no real issue is
+// given.
+func testWebhookIssue(instanceID types.ID) *jira.Issue {
+	return &jira.Issue{
+		ID:   "0",
+		Key:  "TEST-1",
+		Self: instanceID.String() + "/rest/api/2/issue/0",
+		Fields: &jira.IssueFields{
+			Summary: "Test event sent by /jira instance test-webhook",
+			Type:    jira.IssueType{Name: "Task"},
+			Project: jira.Project{Key: "TEST"},
+		},
+	}
+}
+
+// testWebhookPayload is a Jira event updating the summary of the issue, to
+// itself.
+func testWebhookPayload(issue *jira.Issue) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"webhookEvent":          "jira:issue_updated",
+		"issue_event_type_name": "issue_updated",
+		"issue":                 issue,
+		"user": jira.User{
+			Name:        "mattermost-test-webhook",
+			DisplayName: "Mattermost test webhook",
+		},
+		"changelog": map[string]interface{}{
+			"items": []map[string]string{
+				{
+					"field":      "summary",
+					"fieldId":    "summary",
+					"fieldtype":  "jira",
+					"fromString": issue.Fields.Summary,
+					"toString":   issue.Fields.Summary,
+				},
+			},
+		},
+	})
+}
+
+// testWebhook sends a test event to the subscriptions webhook URL of the
+// instance, through the Mattermost site URL like Jira does.
+func (p *Plugin) testWebhook(instanceID types.ID, issue *jira.Issue) (string, error) {
+	payload, err := testWebhookPayload(issue)
+	if err != nil {
+		return "", err
+	}
+
+	v := url.Values{}
+	v.Add("secret", p.getConfig().Secret)
+	v.Add(testWebhookParam, "true")
+	webhookURL := p.GetPluginURL() + instancePath(makeAPIRoute(routeAPISubscribeWebhook), instanceID) + "?" + v.Encode()
+
+	client := &http.Client{Timeout: testWebhookTimeout}
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return "", errors.WithMessage(err, "the webhook URL could not be reached from the Mattermost server, please check the Site URL")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Sprintf("The webhook returned `%s`: %s", resp.Status, strings.TrimSpace(string(body))), nil
+	}
+
+	result := testWebhookResult{}
+	if err = json.Unmarshal(body, &result); err != nil {
+		return "", errors.WithMessage(err, "the webhook returned an unexpected response")
+	}
+	return p.formatTestWebhookResult(instanceID, issue.Key, resp.Status, &result), nil
+}
+
+func (p *Plugin) formatTestWebhookResult(instanceID types.ID, issueKey, status string, result *testWebhookResult) string {
+	msg := fmt.Sprintf("The webhook returned `%s`, it accepted a test update of %s (events: %s).\n",
+		status, issueKey, strings.Join(result.Events, ", "))
+	if len(result.SubscriptionIDs) == 0 {
+		return msg + "No subscription matched it. Nothing was posted."
+	}
+
+	subs, err := p.getSubscriptions(instanceID)
+	if err != nil {
+		subs = NewSubscriptions()
+	}
+	msg += "It matched the following subscriptions, nothing was posted to their channels:\n"
+	for _, id := range result.SubscriptionIDs {
+		sub, ok := subs.Channel.ByID[id]
+		if !ok {
+			msg += fmt.Sprintf("* `%s`\n", id)
+			continue
+		}
+		msg += fmt.Sprintf("* %s in %s\n", subscriptionLabel(sub), p.channelLabel(sub.ChannelID))
+	}
+	return msg
+}
+
+func executeInstanceTestWebhook(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	authorized, err := authorizedSysAdmin(p, header.UserId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira instance test-webhook` can only be run by a system administrator.")
+	}
+	if len(args) < 1 || len(args) > 2 {
+		return p.responsef(header, "Please specify a Jira URL, and optionally an issue key, in the form `/jira instance test-webhook [jiraURL] [issue-key]`.")
+	}
+
+	instanceID, err := p.ResolveWebhookInstanceURL(args[0])
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if _, err = p.instanceStore.LoadInstance(instanceID); err != nil {
+		return p.responsef(header, "Failed to load the Jira instance %s. Error: %v.", instanceID, err)
+	}
+
+	issue := testWebhookIssue(instanceID)
+	if len(args) == 2 {
+		client, _, _, clientErr := p.getClient(instanceID, types.ID(header.UserId))
+		if clientErr != nil {
+			return p.responsef(header, "%v", clientErr)
+		}
+		issue, err = getIssueToView(client, strings.ToUpper(args[1]))
+		if err != nil {
+			return p.responsef(header, "%v", err)
+		}
+	}
+
+	msg, err := p.testWebhook(instanceID, issue)
+	if err != nil {
+		return p.responsef(header, "Failed to test the webhook. Error: %v.", err)
+	}
+	return p.responsef(header, "%s", msg)
+}
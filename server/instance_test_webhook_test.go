@@ -0,0 +1,80 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestWebhook(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("LogWarn", mockAnythingOfTypeBatch("string", 13)...).Return(nil).Maybe()
+	api.On("LogDebug", mockAnythingOfTypeBatch("string", 11)...).Return(nil).Maybe()
+	api.On("GetChannel", mock.AnythingOfType("string")).Return(&model.Channel{Name: "town-square"}, nil)
+
+	subs := withExistingChannelSubscriptions([]ChannelSubscription{
+		{
+			ID:        "sub_summary",
+			Name:      "Summaries",
+			ChannelID: "channel1",
+			Filters: SubscriptionFilters{
+				Events:   NewStringSet(eventUpdatedSummary),
+				Projects: NewStringSet("TEST"),
+			},
+		},
+		{
+			ID:        "sub_created",
+			Name:      "New issues",
+			ChannelID: "channel2",
+			Filters: SubscriptionFilters{
+				Events:   NewStringSet(eventCreated),
+				Projects: NewStringSet("TEST"),
+			},
+		},
+	})
+	subscriptionBytes, err := json.Marshal(subs)
+	require.NoError(t, err)
+	api.On("KVGet", keyWithInstanceID(testInstance1.InstanceID, JiraSubscriptionsKey)).Return(subscriptionBytes, nil)
+
+	p := &Plugin{}
+	p.initializeRouter()
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.instanceStore = p.getMockInstanceStoreKV(1)
+
+	ts := httptest.NewServer(http.StripPrefix(p.GetPluginURLPath(), p.router))
+	defer ts.Close()
+	p.updateConfig(func(conf *config) {
+		conf.Secret = someSecret
+		conf.mattermostSiteURL = ts.URL
+	})
+
+	t.Run("matching subscriptions are reported", func(t *testing.T) {
+		msg, err := p.testWebhook(testInstance1.InstanceID, testWebhookIssue(testInstance1.InstanceID))
+		require.NoError(t, err)
+		assert.Contains(t, msg, "The webhook returned `200 OK`, it accepted a test update of TEST-1 (events: event_updated_summary).")
+		assert.Contains(t, msg, "* **Summaries** in ~town-square\n")
+		assert.NotContains(t, msg, "New issues")
+	})
+
+	t.Run("invalid test events are rejected", func(t *testing.T) {
+		webhookURL := ts.URL + p.GetPluginURLPath() + instancePath(makeAPIRoute(routeAPISubscribeWebhook), testInstance1.InstanceID) +
+			"?secret=" + someSecret + "&" + testWebhookParam + "=true"
+		resp, err := http.Post(webhookURL, "application/json", strings.NewReader("{}"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
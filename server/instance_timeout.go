@@ -0,0 +1,96 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	// maxInstanceRequestTimeout caps the request timeout of an instance, so
+	// that a stuck Jira does not hold the plugin's requests forever.
+	maxInstanceRequestTimeout = 300 * time.Second
+
+	requestTimeoutDefault = "default"
+)
+
+// requestTimeout is the timeout of the requests made on behalf of the users
+// of the instance, 0 for the default of the HTTP client.
+func (ic *InstanceCommon) requestTimeout() time.Duration {
+	return time.Duration(ic.RequestTimeoutSeconds) * time.Second
+}
+
+// applyRequestTimeout sets the request timeout of the instance on the HTTP
+// client of a Jira client, if one was set for the instance.
+func (ic *InstanceCommon) applyRequestTimeout(httpClient *http.Client) {
+	if httpClient == nil || ic.RequestTimeoutSeconds <= 0 {
+		return
+	}
+	httpClient.Timeout = ic.requestTimeout()
+}
+
+// parseRequestTimeout returns the timeout in seconds, 0 for `default`.
+func parseRequestTimeout(value string) (int, error) {
+	if strings.EqualFold(value, requestTimeoutDefault) {
+		return 0, nil
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0, errors.Errorf("`%s` is not a number of seconds", value)
+	}
+	if time.Duration(seconds)*time.Second > maxInstanceRequestTimeout {
+		return 0, errors.Errorf("the timeout can be %d seconds at most", int(maxInstanceRequestTimeout.Seconds()))
+	}
+	return seconds, nil
+}
+
+func executeInstanceSetAuthTimeout(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	authorized, err := authorizedSysAdmin(p, header.UserId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira instance set-auth-timeout` can only be run by a system administrator.")
+	}
+	if len(args) != 2 {
+		return p.responsef(header, "Please specify a Jira instance and a number of seconds, `/jira instance set-auth-timeout [jiraURL] [seconds]`, or `default` instead of the seconds to remove the timeout.")
+	}
+
+	instances, err := p.instanceStore.LoadInstances()
+	if err != nil {
+		return p.responsef(header, "Failed to load instances. Error: %v.", err)
+	}
+	instanceID := types.ID(args[0])
+	if found := instances.getByAlias(args[0]); found != nil {
+		instanceID = found.InstanceID
+	}
+
+	seconds, err := parseRequestTimeout(args[1])
+	if err != nil {
+		return p.responsef(header, "Invalid timeout: %v.", err)
+	}
+
+	instance, err := p.instanceStore.LoadInstance(instanceID)
+	if err != nil {
+		return p.responsef(header, "Failed to load instance. Error: %v.", err)
+	}
+	instance.Common().RequestTimeoutSeconds = seconds
+	if err = p.instanceStore.StoreInstance(instance); err != nil {
+		return p.responsef(header, "Failed to save instance. Error: %v.", err)
+	}
+
+	if seconds == 0 {
+		return p.responsef(header, "Requests to %s no longer have a timeout of their own.", instanceID)
+	}
+	return p.responsef(header, "Requests to %s now time out after %d seconds.", instanceID, seconds)
+}
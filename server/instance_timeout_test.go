@@ -0,0 +1,40 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRequestTimeout(t *testing.T) {
+	for value, expected := range map[string]int{
+		"default": 0,
+		"Default": 0,
+		"1":       1,
+		"300":     300,
+	} {
+		seconds, err := parseRequestTimeout(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, seconds, value)
+	}
+
+	for _, value := range []string{"", "0", "-5", "ten", "301"} {
+		_, err := parseRequestTimeout(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestApplyRequestTimeout(t *testing.T) {
+	httpClient := &http.Client{}
+	(&InstanceCommon{}).applyRequestTimeout(httpClient)
+	assert.Equal(t, time.Duration(0), httpClient.Timeout)
+
+	(&InstanceCommon{RequestTimeoutSeconds: 90}).applyRequestTimeout(httpClient)
+	assert.Equal(t, 90*time.Second, httpClient.Timeout)
+}
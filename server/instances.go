@@ -167,6 +167,9 @@ func (p *Plugin) InstallInstance(newInstance Instance) error {
 		return err
 	}
 
+	p.setInstances(updated)
+	p.instanceChanged(newInstance.GetID())
+
 	// Re-register the /jira command with the new number of instances.
 	err = p.registerJiraCommand(p.getConfig().EnableAutocomplete, updated.Len() > 1)
 	if err != nil {
@@ -226,6 +229,9 @@ func (p *Plugin) UninstallInstance(instanceID types.ID, instanceType InstanceTyp
 		return nil, err
 	}
 
+	p.setInstances(updated)
+	p.instanceChanged(instanceID)
+
 	// Re-register the /jira command with the new number of instances.
 	err = p.registerJiraCommand(p.getConfig().EnableAutocomplete, updated.Len() > 1)
 	if err != nil {
@@ -238,6 +244,98 @@ func (p *Plugin) UninstallInstance(instanceID types.ID, instanceType InstanceTyp
 	return instance, nil
 }
 
+func (p *Plugin) setInstances(instances *Instances) {
+	p.instancesLock.Lock()
+	defer p.instancesLock.Unlock()
+	p.instances = instances
+}
+
+// loadedInstances returns the instances last loaded, without reading the KV
+// store, or nil before the plugin is activated.
+func (p *Plugin) loadedInstances() *Instances {
+	p.instancesLock.Lock()
+	defer p.instancesLock.Unlock()
+	return p.instances
+}
+
+// instanceChanged drops what is cached about an instance on this server, and
+// has the other servers of the cluster drop it and reload their instances.
+func (p *Plugin) instanceChanged(instanceID types.ID) {
+	p.invalidateInstanceCaches(instanceID)
+
+	err := p.client.Cluster.PublishPluginEvent(model.PluginClusterEvent{
+		Id:   clusterEventInstanceChanged,
+		Data: []byte(instanceID),
+	}, model.PluginClusterEventSendOptions{
+		SendType: model.PluginClusterEventSendTypeReliable,
+	})
+	if err != nil {
+		p.client.Log.Warn("Failed to notify the cluster of the change of an instance", "InstanceID", instanceID, "error", err.Error())
+	}
+}
+
+// invalidateInstanceCaches drops what is cached about an instance on this
+// server, e.g. its version or its custom fields, so that it is fetched again
+// with its current settings.
+func (p *Plugin) invalidateInstanceCaches(instanceID types.ID) {
+	p.jqlCache.invalidate(instanceID)
+	p.serverInfoCache.invalidate(instanceID)
+	p.customFieldCache.invalidate(instanceID)
+	p.issueTypesCache.invalidate(instanceID)
+	p.issueAncestorCache.invalidate(instanceID)
+	p.priorityOrderCache.invalidate(instanceID)
+	p.devInfoCache.invalidate(instanceID)
+}
+
+// reloadInstances loads the instances again after a configuration change, and
+// drops the caches of the instances loaded before and after, so that their
+// changes take effect without restarting the plugin. The clients are built
+// from the loaded instance on every request, the commands in flight keep
+// theirs. The /jira command is only registered again when its autocomplete
+// changes, to not have it briefly missing on every change.
+func (p *Plugin) reloadInstances(autocompleteChanged bool) error {
+	p.instancesLock.Lock()
+	defer p.instancesLock.Unlock()
+
+	instances, err := p.instanceStore.LoadInstances()
+	if err != nil {
+		return errors.WithMessage(err, "failed to reload the Jira instances")
+	}
+	prev := p.instances
+	p.instances = instances
+
+	stale := instances.IDs()
+	if prev != nil {
+		stale = append(stale, prev.IDs()...)
+	}
+	for _, instanceID := range stale {
+		p.invalidateInstanceCaches(instanceID)
+	}
+
+	multipleInstances := instances.Len() > 1
+	if autocompleteChanged || prev == nil || multipleInstances != (prev.Len() > 1) {
+		if err = p.registerJiraCommand(p.getConfig().EnableAutocomplete, multipleInstances); err != nil {
+			return err
+		}
+	}
+	if prev == nil || !sameInstanceIDs(prev, instances) {
+		p.wsInstancesChanged(instances)
+	}
+	return nil
+}
+
+func sameInstanceIDs(a, b *Instances) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+	for _, instanceID := range a.IDs() {
+		if !b.Contains(instanceID) {
+			return false
+		}
+	}
+	return true
+}
+
 func (p *Plugin) wsInstancesChanged(instances *Instances) {
 	msg := map[string]interface{}{
 		"instances": instances.AsConfigMap(),
@@ -99,6 +99,7 @@ func TestInstallInstance(t *testing.T) {
 			api.On("UnregisterCommand", mock.Anything, mock.Anything).Return(nil)
 			api.On("RegisterCommand", mock.Anything, mock.Anything).Return(nil)
 			api.On("PublishWebSocketEvent", mock.Anything, mock.Anything, mock.Anything)
+			api.On("PublishPluginClusterEvent", mock.AnythingOfType("model.PluginClusterEvent"), mock.AnythingOfType("model.PluginClusterEventSendOptions")).Return(nil)
 
 			path, err := filepath.Abs("..")
 			require.Nil(t, err)
@@ -123,3 +124,77 @@ func TestInstallInstance(t *testing.T) {
 		})
 	}
 }
+
+func TestReloadInstances(t *testing.T) {
+	api := &plugintest.API{}
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	api.On("UnregisterCommand", mock.Anything, mock.Anything).Return(nil)
+	api.On("RegisterCommand", mock.Anything, mock.Anything).Return(nil)
+	api.On("PublishWebSocketEvent", mock.Anything, mock.Anything, mock.Anything)
+	path, err := filepath.Abs("..")
+	require.NoError(t, err)
+	api.On("GetBundlePath").Return(path, nil)
+	api.On("LoadPluginConfiguration", mock.Anything).Return(nil)
+	api.On("GetConfig").Return(&model.Config{})
+
+	store := &mockInstanceStoreKV{Instances: NewInstances(), Plugin: p}
+	store.Instances.Set(testInstance1.Common())
+	p.instanceStore = store
+	p.setInstances(store.Instances)
+
+	p.serverInfoCache.set(testInstance1.InstanceID, "", jiraServerInfo{Version: "8.20.0"}, serverInfoTTL)
+	p.customFieldCache.set(testInstance1.InstanceID, "", map[string]string{epicLinkFieldSchema: "customfield_10014"}, customFieldTTL)
+
+	// An instance set up on another server of the cluster.
+	reloaded := NewInstances()
+	reloaded.Set(testInstance1.Common())
+	reloaded.Set(testInstance2.Common())
+	store.Instances = reloaded
+	require.NoError(t, p.OnConfigurationChange())
+
+	_, ok := p.serverInfoCache.get(testInstance1.InstanceID, "")
+	assert.False(t, ok, "the cached version is dropped")
+	_, ok = p.customFieldCache.get(testInstance1.InstanceID, "")
+	assert.False(t, ok, "the cached fields are dropped")
+	assert.Equal(t, reloaded, p.instances)
+	api.AssertNumberOfCalls(t, "RegisterCommand", 1)
+	api.AssertNumberOfCalls(t, "PublishWebSocketEvent", 1)
+
+	require.NoError(t, p.reloadInstances(false))
+	api.AssertNumberOfCalls(t, "RegisterCommand", 1)
+	api.AssertNumberOfCalls(t, "PublishWebSocketEvent", 1)
+
+	require.NoError(t, p.reloadInstances(true))
+	api.AssertNumberOfCalls(t, "RegisterCommand", 2)
+}
+
+func TestOnPluginClusterEvent(t *testing.T) {
+	api := &plugintest.API{}
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	api.On("PublishWebSocketEvent", mock.Anything, mock.Anything, mock.Anything)
+
+	store := &mockInstanceStoreKV{Instances: NewInstances(), Plugin: p}
+	store.Instances.Set(testInstance1.Common())
+	p.instanceStore = store
+	p.setInstances(store.Instances)
+	p.issueTypesCache.set(testInstance1.InstanceID, "TEST", projectIssueTypes{}, issueTypesTTL)
+
+	p.OnPluginClusterEvent(nil, model.PluginClusterEvent{Id: "other"})
+	_, ok := p.issueTypesCache.get(testInstance1.InstanceID, "TEST")
+	assert.True(t, ok, "other events are ignored")
+
+	// The instance was uninstalled on another server of the cluster.
+	store.Instances = NewInstances()
+	p.OnPluginClusterEvent(nil, model.PluginClusterEvent{
+		Id:   clusterEventInstanceChanged,
+		Data: []byte(testInstance1.InstanceID),
+	})
+	_, ok = p.issueTypesCache.get(testInstance1.InstanceID, "TEST")
+	assert.False(t, ok, "the cached issue types are dropped")
+	assert.Equal(t, 0, p.loadedInstances().Len())
+	api.AssertNumberOfCalls(t, "PublishWebSocketEvent", 1)
+}
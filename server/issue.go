@@ -38,12 +38,17 @@ const (
 	QueryParamInstanceID = "instance_id"
 	QueryParamProjectID  = "project_id"
 
-	expandValueGroups = "groups"
+	expandValueGroups    = "groups"
+	expandValueChangelog = "changelog"
 )
 
 type CreateMetaInfo struct {
 	*jira.CreateMetaInfo
 	IssueTypesWithStatuses []*IssueTypeWithStatuses `json:"issue_types_with_statuses"`
+
+	// The issue type picked in the create modal, or why it could not be
+	DefaultIssueTypeID   string `json:"default_issue_type_id,omitempty"`
+	DefaultIssueTypeNote string `json:"default_issue_type_note,omitempty"`
 }
 
 func makePost(userID, channelID, message string) *model.Post {
@@ -64,10 +69,11 @@ func (p *Plugin) httpShareIssuePublicly(w http.ResponseWriter, r *http.Request)
 
 	jiraBotID := p.getUserID()
 	channelID := requestData.ChannelId
-	mattermostUserID := requestData.UserId
-	if mattermostUserID == "" {
-		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
-			"user not authorized"), w, http.StatusUnauthorized)
+	// The user who clicked is the one Mattermost authenticated, the body can
+	// name anyone.
+	mattermostUserID := r.Header.Get("Mattermost-User-Id")
+	if mattermostUserID == "" || mattermostUserID != requestData.UserId {
+		return respondErr(w, http.StatusUnauthorized, errors.New("not authorized"))
 	}
 
 	val := requestData.Context["issue_key"]
@@ -90,7 +96,7 @@ func (p *Plugin) httpShareIssuePublicly(w http.ResponseWriter, r *http.Request)
 			"No connection could be loaded with given params"), w, http.StatusInternalServerError)
 	}
 
-	attachment, err := p.getIssueAsSlackAttachment(instance, connection, strings.ToUpper(issueKey), false)
+	attachment, err := p.getIssueAsSlackAttachment(instance, connection, channelID, strings.ToUpper(issueKey), false)
 	if err != nil {
 		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
 			"Could not get issue as slack attachment"), w, http.StatusInternalServerError)
@@ -101,6 +107,7 @@ func (p *Plugin) httpShareIssuePublicly(w http.ResponseWriter, r *http.Request)
 		ChannelId: channelID,
 	}
 	post.AddProp("attachments", attachment)
+	setIssuePostProps(post, instance.GetID(), strings.ToUpper(issueKey))
 
 	err = p.client.Post.CreatePost(post)
 	if err != nil {
@@ -126,10 +133,15 @@ func (p *Plugin) httpTransitionIssuePostAction(w http.ResponseWriter, r *http.Re
 	jiraBotID := p.getUserID()
 	channelID := requestData.ChannelId
 
-	mattermostUserID := requestData.UserId
-	if mattermostUserID == "" {
+	// The user who clicked is the one Mattermost authenticated, the body can
+	// name anyone.
+	mattermostUserID := r.Header.Get("Mattermost-User-Id")
+	if mattermostUserID == "" || mattermostUserID != requestData.UserId {
+		return respondErr(w, http.StatusUnauthorized, errors.New("not authorized"))
+	}
+	if p.isChannelReadOnly(channelID) {
 		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
-			"user not authorized"), w, http.StatusUnauthorized)
+			channelReadOnlyMessage), w, http.StatusForbidden)
 	}
 
 	val := requestData.Context["issue_key"]
@@ -153,6 +165,12 @@ func (p *Plugin) httpTransitionIssuePostAction(w http.ResponseWriter, r *http.Re
 			"No instance id was found in context data"), w, http.StatusInternalServerError)
 	}
 
+	if _, err = p.userStore.LoadConnection(types.ID(instanceID), types.ID(mattermostUserID)); err != nil {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			p.connectPromptMessage(types.ID(instanceID))), w, http.StatusUnauthorized)
+	}
+
+	// The transition is resolved against the issue's current transitions, fetched at click time.
 	_, err = p.TransitionIssue(&InTransitionIssue{
 		mattermostUserID: types.ID(mattermostUserID),
 		InstanceID:       types.ID(instanceID),
@@ -169,6 +187,183 @@ func (p *Plugin) httpTransitionIssuePostAction(w http.ResponseWriter, r *http.Re
 	return http.StatusOK, err
 }
 
+func (p *Plugin) httpAssignToMePostAction(w http.ResponseWriter, r *http.Request) (int, error) {
+	var requestData model.PostActionIntegrationRequest
+	err := json.NewDecoder(r.Body).Decode(&requestData)
+	if err != nil {
+		return respondErr(w, http.StatusBadRequest,
+			errors.Wrap(err, "unmarshall the body"))
+	}
+
+	jiraBotID := p.getUserID()
+	channelID := requestData.ChannelId
+	// The user who clicked is the one Mattermost authenticated, the body can
+	// name anyone.
+	mattermostUserID := r.Header.Get("Mattermost-User-Id")
+	if mattermostUserID == "" || mattermostUserID != requestData.UserId {
+		return respondErr(w, http.StatusUnauthorized, errors.New("not authorized"))
+	}
+	if p.isChannelReadOnly(channelID) {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			channelReadOnlyMessage), w, http.StatusForbidden)
+	}
+
+	val := requestData.Context["issue_key"]
+	issueKey, ok := val.(string)
+	if !ok {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			"No issue key was found in context data"), w, http.StatusInternalServerError)
+	}
+
+	val = requestData.Context["instance_id"]
+	instanceID, ok := val.(string)
+	if !ok {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			"No instance id was found in context data"), w, http.StatusInternalServerError)
+	}
+
+	client, instance, connection, err := p.getClient(types.ID(instanceID), types.ID(mattermostUserID))
+	if err != nil {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			p.connectPromptMessage(types.ID(instanceID))), w, http.StatusUnauthorized)
+	}
+
+	// From Jira error: query parameters 'accountId' and 'username' are mutually exclusive.
+	assignee := connection.User
+	if assignee.AccountID != "" {
+		assignee.Name = ""
+	}
+
+	if err = client.UpdateAssignee(issueKey, &assignee); err != nil {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			"Failed to assign this issue to you."), w, http.StatusInternalServerError)
+	}
+	p.recordSelfChange(types.ID(instanceID), issueKey, types.ID(mattermostUserID))
+
+	issue, err := client.GetIssue(issueKey, nil)
+	if err != nil {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			"The issue was assigned to you, but could not be reloaded."), w, http.StatusInternalServerError)
+	}
+
+	p.client.Post.SendEphemeralPost(mattermostUserID, makePost(jiraBotID, channelID,
+		fmt.Sprintf("You have been assigned to Jira issue [%s](%s/browse/%s)", issue.Key, instance.GetJiraBaseURL(), issue.Key)))
+	if keep, _ := requestData.Context["keep_card"].(bool); keep {
+		return respondJSON(w, &model.PostActionIntegrationResponse{})
+	}
+
+	// Re-render the card so the assignee and the available transitions are current.
+	attachments, err := asSlackAttachment(instance, client, issue, true)
+	if err != nil {
+		return respondErr(w, http.StatusInternalServerError, err)
+	}
+	p.addMattermostDiscussions(attachments, client, issue.Key)
+
+	update := &model.Post{}
+	update.AddProp("attachments", attachments)
+	return respondJSON(w, &model.PostActionIntegrationResponse{
+		Update: update,
+	})
+}
+
+// httpTransitionPickerPostAction lets the user pick one of the transitions
+// of the issue, fetched when the transition button of a card is clicked. The
+// choice is handled like a transition picked on a reopen picker.
+func (p *Plugin) httpTransitionPickerPostAction(w http.ResponseWriter, r *http.Request) (int, error) {
+	var requestData model.PostActionIntegrationRequest
+	err := json.NewDecoder(r.Body).Decode(&requestData)
+	if err != nil {
+		return respondErr(w, http.StatusBadRequest,
+			errors.Wrap(err, "unmarshall the body"))
+	}
+
+	jiraBotID := p.getUserID()
+	channelID := requestData.ChannelId
+	// The user who clicked is the one Mattermost authenticated, the body can
+	// name anyone.
+	mattermostUserID := r.Header.Get("Mattermost-User-Id")
+	if mattermostUserID == "" || mattermostUserID != requestData.UserId {
+		return respondErr(w, http.StatusUnauthorized, errors.New("not authorized"))
+	}
+	if p.isChannelReadOnly(channelID) {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			channelReadOnlyMessage), w, http.StatusForbidden)
+	}
+
+	val := requestData.Context["issue_key"]
+	issueKey, ok := val.(string)
+	if !ok {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			"No issue key was found in context data"), w, http.StatusInternalServerError)
+	}
+
+	val = requestData.Context["instance_id"]
+	instanceID, ok := val.(string)
+	if !ok {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			"No instance id was found in context data"), w, http.StatusInternalServerError)
+	}
+
+	client, instance, _, err := p.getClient(types.ID(instanceID), types.ID(mattermostUserID))
+	if err != nil {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			p.connectPromptMessage(types.ID(instanceID))), w, http.StatusUnauthorized)
+	}
+
+	issue, err := client.GetIssue(issueKey, &jira.GetQueryOptions{Fields: "status"})
+	if err != nil {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			"Failed to load this issue."), w, http.StatusInternalServerError)
+	}
+	transitions, err := client.GetTransitions(issueKey)
+	if err != nil {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			"Failed to load the transitions of this issue."), w, http.StatusInternalServerError)
+	}
+
+	// Remove current issue status from possible transitions
+	options := []*model.PostActionOptions{}
+	for _, transition := range transitions {
+		if issue.Fields == nil || issue.Fields.Status == nil || transition.Name != issue.Fields.Status.Name {
+			options = append(options, &model.PostActionOptions{
+				Text:  transition.Name,
+				Value: transition.To.Name,
+			})
+		}
+	}
+	if len(options) == 0 {
+		return respondJSON(w, &model.PostActionIntegrationResponse{
+			EphemeralText: fmt.Sprintf("[%s](%s/browse/%s) has no transitions available to you.", issue.Key, instance.GetJiraBaseURL(), issue.Key),
+		})
+	}
+
+	post := makePost(jiraBotID, channelID, "")
+	post.AddProp("attachments", []*model.SlackAttachment{
+		{
+			Text: fmt.Sprintf("Please pick the transition of [%s](%s/browse/%s):", issue.Key, instance.GetJiraBaseURL(), issue.Key),
+			Actions: []*model.PostAction{
+				{
+					Name:    "Transition issue",
+					Type:    "select",
+					Options: options,
+					Integration: &model.PostActionIntegration{
+						URL:     fmt.Sprintf("/plugins/%s%s%s", manifest.Id, routeAPI, routeIssueTransition),
+						Context: requestData.Context,
+					},
+				},
+			},
+		},
+	})
+	p.client.Post.SendEphemeralPost(mattermostUserID, post)
+	return respondJSON(w, &model.PostActionIntegrationResponse{})
+}
+
+// connectPromptMessage is sent to users who act on an issue card without having connected their Jira account.
+func (p *Plugin) connectPromptMessage(instanceID types.ID) string {
+	return fmt.Sprintf("Your Mattermost account is not connected to Jira. [Click here to link your Jira account](%s%s) and try again.",
+		p.GetPluginURL(), instancePath(routeUserConnect, instanceID))
+}
+
 func (p *Plugin) respondErrWithFeedback(mattermostUserID string, post *model.Post, w http.ResponseWriter, status int) (int, error) {
 	p.client.Post.SendEphemeralPost(mattermostUserID, post)
 	return respondErr(w, status, errors.New(post.Message))
@@ -182,6 +377,42 @@ type InCreateIssue struct {
 	CurrentTeam              string           `json:"current_team"`
 	ChannelID                string           `json:"channel_id"`
 	Fields                   jira.IssueFields `json:"fields"`
+
+	// AssigneeMattermostUsername is the Mattermost user to assign the new
+	// issue to, through their Jira connection to the instance.
+	AssigneeMattermostUsername string `json:"assignee_mattermost_username,omitempty"`
+
+	// Sprint is the open sprint to create the issue in: `current`, or the
+	// ID or name of a sprint of a board of the project.
+	Sprint string `json:"sprint,omitempty"`
+
+	// Reporter is who the issue is filed on behalf of: `@username` of a
+	// connected Mattermost user, or a Jira username or email address.
+	Reporter string `json:"reporter,omitempty"`
+}
+
+// resolveCreateAssignee returns the Jira user that the Mattermost user is
+// connected as.
+func (p *Plugin) resolveCreateAssignee(instanceID types.ID, mattermostUsername string) (*jira.User, error) {
+	mattermostUsername = strings.TrimPrefix(mattermostUsername, "@")
+	mmUser, err := p.client.User.GetByUsername(mattermostUsername)
+	if err != nil {
+		return nil, errors.Errorf("@%s was not found", mattermostUsername)
+	}
+
+	connection, err := p.userStore.LoadConnection(instanceID, types.ID(mmUser.Id))
+	if err != nil {
+		return nil, errors.Errorf("@%s is not connected to %s", mattermostUsername, instanceID)
+	}
+
+	// Jira Cloud only accepts the account ID, Jira Server only the username.
+	if connection.AccountID != "" {
+		return &jira.User{AccountID: connection.AccountID}, nil
+	}
+	if connection.Name != "" {
+		return &jira.User{Name: connection.Name}, nil
+	}
+	return nil, errors.Errorf("@%s is not connected to %s", mattermostUsername, instanceID)
 }
 
 func (p *Plugin) httpCreateIssue(w http.ResponseWriter, r *http.Request) (int, error) {
@@ -209,6 +440,8 @@ func (p *Plugin) CreateIssue(in *InCreateIssue) (*jira.Issue, error) {
 
 	var post *model.Post
 
+	in.Fields.Description = p.mapMentions(instance.GetID(), in.Fields.Description)
+
 	// If this issue is attached to a post, lets add a permalink to the post in the Jira Description
 	if in.PostID != "" {
 		post, err = p.client.Post.GetPost(in.PostID)
@@ -241,6 +474,9 @@ func (p *Plugin) CreateIssue(in *InCreateIssue) (*jira.Issue, error) {
 	if post != nil {
 		channelID = post.ChannelId
 	}
+	if p.isChannelReadOnly(channelID) {
+		return nil, errors.New(channelReadOnlyMessage)
+	}
 
 	for i, notCovered := range in.RequiredFieldsNotCovered {
 		// First position in the slice is the key value (shouldn't change, regardless of localization)
@@ -264,6 +500,18 @@ func (p *Plugin) CreateIssue(in *InCreateIssue) (*jira.Issue, error) {
 		return nil, errors.WithMessagef(err, "failed to get project %q", issue.Fields.Project.Key)
 	}
 
+	if issue.Fields.Parent != nil {
+		if err = p.validateIssueParent(client, issue.Fields); err != nil {
+			return nil, err
+		}
+	}
+
+	if in.Sprint != "" {
+		if err = p.setIssueSprint(instance.GetID(), client, issue.Fields, in.Sprint); err != nil {
+			return nil, err
+		}
+	}
+
 	if len(in.RequiredFieldsNotCovered) > 0 {
 		createURL := MakeCreateIssueURL(instance, project, issue)
 
@@ -285,8 +533,47 @@ func (p *Plugin) CreateIssue(in *InCreateIssue) (*jira.Issue, error) {
 		return nil, errors.Errorf("issue can not be created via API: %s", message)
 	}
 
+	assigneeWarning := ""
+	if in.AssigneeMattermostUsername != "" {
+		var assignee *jira.User
+		assignee, err = p.resolveCreateAssignee(instance.GetID(), in.AssigneeMattermostUsername)
+		if err != nil {
+			assigneeWarning = fmt.Sprintf("\nThe issue was left unassigned: %v.", err)
+		} else {
+			issue.Fields.Assignee = assignee
+		}
+	}
+
+	reporterWarning := ""
+	defaultReporter := issue.Fields.Reporter
+	if in.Reporter != "" {
+		var reporter *jira.User
+		reporter, err = p.resolveCreateReporter(instance, client, project.Key, in.Reporter)
+		if err != nil {
+			reporterWarning = mdReporterWarning(in.Reporter, err.Error())
+		} else {
+			issue.Fields.Reporter = reporter
+		}
+	}
+
 	created, err := client.CreateIssue(issue)
+	if err != nil && issue.Fields.Reporter != defaultReporter && isFieldRejected(err, "reporter") {
+		// Jira refused the reporter, e.g. they can't report issues in this
+		// project. Fall back to the creator, as without the flag.
+		reporterWarning = mdReporterWarning(in.Reporter, "Jira did not accept them as the reporter")
+		issue.Fields.Reporter = defaultReporter
+		created, err = client.CreateIssue(issue)
+	}
+	if err != nil && issue.Fields.Assignee != nil && isFieldRejected(err, "assignee") {
+		// Jira refused the assignee, e.g. they can't be assigned issues in
+		// this project. Creating the issue matters more.
+		assigneeWarning = fmt.Sprintf("\nThe issue was left unassigned: Jira did not accept @%s as the assignee.", strings.TrimPrefix(in.AssigneeMattermostUsername, "@"))
+		issue.Fields.Assignee = nil
+		created, err = client.CreateIssue(issue)
+	}
 	if err != nil {
+		err = p.explainJiraError(instance.GetID(), client, err, "create an issue", "project", project.Key, "issueType", issue.Fields.Type.ID)
+
 		// if have an error and Jira tells us there are required fields send user
 		// link to jira with fields already filled in.  Note the user will also see
 		// these errors in Jira.
@@ -308,24 +595,7 @@ func (p *Plugin) CreateIssue(in *InCreateIssue) (*jira.Issue, error) {
 
 		return nil, errors.WithMessage(err, "failed to create issue")
 	}
-
-	// Reply with an ephemeral post with the Jira issue formatted as slack attachment.
-	msg := fmt.Sprintf("Created Jira issue [%s](%s/browse/%s)", created.Key, instance.GetJiraBaseURL(), created.Key)
-
-	reply := &model.Post{
-		Message:   msg,
-		ChannelId: channelID,
-		RootId:    rootID,
-		UserId:    instance.Common().getConfig().botUserID,
-	}
-
-	attachment, err := instance.Common().getIssueAsSlackAttachment(instance, connection, created.Key, true)
-	if err != nil {
-		return nil, errors.WithMessage(err, "failed to create notification post "+in.PostID)
-	}
-
-	reply.AddProp("attachments", attachment)
-	p.client.Post.SendEphemeralPost(in.mattermostUserID.String(), reply)
+	p.recordSelfChange(instance.GetID(), created.Key, in.mattermostUserID)
 
 	// Fetching issue details as Jira only returns the issue id and issue key at the time of
 	// issue creation. We will not have issue summary in the creation response.
@@ -338,14 +608,12 @@ func (p *Plugin) CreateIssue(in *InCreateIssue) (*jira.Issue, error) {
 		IssueType:  issue.Fields.Type.ID,
 	})
 
-	// Create a public post for all the channel members
-	publicReply := &model.Post{
-		Message:   fmt.Sprintf("Created a Jira issue: %s", mdKeySummaryLink(createdIssue, instance)),
-		ChannelId: channelID,
-		RootId:    rootID,
-		UserId:    in.mattermostUserID.String(),
+	attachment, err := instance.Common().getIssueAsSlackAttachment(instance, connection, in.ChannelID, created.Key, true)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create notification post "+in.PostID)
 	}
-	err = p.client.Post.CreatePost(publicReply)
+
+	err = p.replyToCreatedIssue(instance, in.mattermostUserID, channelID, rootID, createdIssue, attachment, assigneeWarning+reporterWarning)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to create notification post "+in.PostID)
 	}
@@ -386,9 +654,61 @@ func (p *Plugin) httpGetCreateIssueMetadataForProjects(w http.ResponseWriter, r
 		})
 	}
 
+	p.applyDefaultIssueType(types.ID(instanceID), r.FormValue("channel_id"), projectKeys, cimd)
 	return respondJSON(w, cimd)
 }
 
+// validateIssueParent checks that an issue created with a parent is of a
+// sub-task issue type of its project, and that the parent exists in that same
+// project.
+func (p *Plugin) validateIssueParent(client Client, fields *jira.IssueFields) error {
+	projectKey := fields.Project.Key
+	parentKey := strings.ToUpper(fields.Parent.Key)
+	if parentKey == "" {
+		return errors.New("please provide the key of the parent issue")
+	}
+
+	metaInfo, err := client.GetCreateMetaInfo(p.API, &jira.GetQueryOptions{
+		ProjectKeys: projectKey,
+	})
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get the issue types of project %q", projectKey)
+	}
+
+	var issueType *jira.MetaIssueType
+	for _, project := range metaInfo.Projects {
+		if project.Key != projectKey {
+			continue
+		}
+		for _, it := range project.IssueTypes {
+			if (fields.Type.ID != "" && it.Id == fields.Type.ID) || (fields.Type.ID == "" && strings.EqualFold(it.Name, fields.Type.Name)) {
+				issueType = it
+			}
+		}
+	}
+	if issueType == nil {
+		name := fields.Type.Name
+		if name == "" {
+			name = fields.Type.ID
+		}
+		return errors.Errorf("issue type %q was not found in project %s", name, projectKey)
+	}
+	if !issueType.Subtasks {
+		return errors.Errorf("a parent can only be set on a sub-task, and the %q issue type is not a sub-task type in project %s", issueType.Name, projectKey)
+	}
+
+	parent, err := client.GetIssue(parentKey, &jira.GetQueryOptions{Fields: "project"})
+	if err != nil {
+		return errors.WithMessagef(err, "failed to find the parent issue %s", parentKey)
+	}
+	if parent.Fields == nil || parent.Fields.Project.Key != projectKey {
+		return errors.Errorf("the parent issue %s is not in project %s", parentKey, projectKey)
+	}
+
+	fields.Parent = &jira.Parent{Key: parentKey}
+	return nil
+}
+
 func (p *Plugin) GetCreateIssueMetadataForProjects(instanceID, mattermostUserID types.ID, projectKeys string) (*CreateMetaInfo, error) {
 	client, _, _, err := p.getClient(instanceID, mattermostUserID)
 	if err != nil {
@@ -409,8 +729,8 @@ func (p *Plugin) GetCreateIssueMetadataForProjects(instanceID, mattermostUserID
 	}
 
 	return &CreateMetaInfo{
-		metaInfo,
-		projectStatuses,
+		CreateMetaInfo:         metaInfo,
+		IssueTypesWithStatuses: projectStatuses,
 	}, nil
 }
 
@@ -711,6 +1031,9 @@ func (p *Plugin) AttachCommentToIssue(in *InAttachCommentToIssue) (*jira.Comment
 	if post == nil {
 		return nil, errors.New("failed to load post " + in.PostID + ": not found")
 	}
+	if p.isChannelReadOnly(post.ChannelId) {
+		return nil, errors.New(channelReadOnlyMessage)
+	}
 
 	commentUser, err := p.client.User.Get(post.UserId)
 	if err != nil {
@@ -722,7 +1045,7 @@ func (p *Plugin) AttachCommentToIssue(in *InAttachCommentToIssue) (*jira.Comment
 	permalinkMessage := fmt.Sprintf("*@%s attached a* [message|%s] *from @%s*\n", connection.DisplayName, permalink, commentUser.Username)
 
 	jiraComment := jira.Comment{
-		Body: permalinkMessage + post.Message,
+		Body: permalinkMessage + p.mapMentions(instance.GetID(), post.Message),
 	}
 
 	added, err := client.AddComment(in.IssueKey, &jiraComment)
@@ -734,6 +1057,7 @@ func (p *Plugin) AttachCommentToIssue(in *InAttachCommentToIssue) (*jira.Comment
 		// The error was not a permissions error; it was unanticipated. Return it to the client.
 		return nil, errors.WithMessage(err, "failed to attach the comment, postId: "+in.PostID)
 	}
+	p.recordSelfChange(instance.GetID(), in.IssueKey, in.mattermostUserID)
 
 	go func() {
 		conf := instance.Common().getConfig()
@@ -770,6 +1094,19 @@ func (p *Plugin) AttachCommentToIssue(in *InAttachCommentToIssue) (*jira.Comment
 
 	p.UpdateUserDefaults(in.mattermostUserID, in.InstanceID, nil)
 
+	if added != nil && added.ID != "" {
+		if err = p.trackComment(instance.GetID(), in.mattermostUserID, in.IssueKey, added.ID); err != nil {
+			p.client.Log.Warn("Failed to keep track of the comment", "issue", in.IssueKey, "comment", added.ID, "error", err.Error())
+		} else {
+			p.client.Post.SendEphemeralPost(in.mattermostUserID.String(), &model.Post{
+				ChannelId: post.ChannelId,
+				RootId:    rootID,
+				UserId:    p.getConfig().botUserID,
+				Message:   fmt.Sprintf("To delete the comment, use `/jira comment delete %s %s`.", in.IssueKey, added.ID),
+			})
+		}
+	}
+
 	msg := fmt.Sprintf("Message attached to [%s](%s/browse/%s)", in.IssueKey, instance.GetJiraBaseURL(), in.IssueKey)
 
 	// Reply to the post with the issue link that was created
@@ -787,6 +1124,79 @@ func (p *Plugin) AttachCommentToIssue(in *InAttachCommentToIssue) (*jira.Comment
 	return added, nil
 }
 
+type InAttachFilesToIssue struct {
+	mattermostUserID types.ID
+	InstanceID       types.ID
+	PostID           string
+	ChannelID        string
+	IssueKey         string
+}
+
+// AttachFilesToIssue uploads the files of a post to an existing Jira issue, and
+// returns a per-file report of the outcome.
+func (p *Plugin) AttachFilesToIssue(in *InAttachFilesToIssue) (string, error) {
+	client, instance, _, err := p.getClient(in.InstanceID, in.mattermostUserID)
+	if err != nil {
+		return "", err
+	}
+
+	post, err := p.client.Post.GetPost(in.PostID)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to load post "+in.PostID)
+	}
+	if post == nil || post.ChannelId != in.ChannelID {
+		return "", errors.New("failed to load post " + in.PostID + ": not found")
+	}
+	if len(post.FileIds) == 0 {
+		return "", errors.New("the message has no files to attach")
+	}
+
+	if _, err = client.GetIssue(in.IssueKey, nil); err != nil {
+		return "", errors.Errorf("we couldn't find the issue key `%s`. Please confirm the issue key and try again", in.IssueKey)
+	}
+
+	conf := instance.Common().getConfig()
+	var total types.ByteSize
+	attached := 0
+	report := ""
+	for _, fileID := range post.FileIds {
+		fileinfo, e := p.client.File.GetInfo(fileID)
+		if e != nil {
+			report += fmt.Sprintf("* `%s`: failed to load the file: %v\n", fileID, e)
+			continue
+		}
+
+		mattermostName, _, _, e := client.AddAttachment(*p.client, in.IssueKey, fileID, conf.maxAttachmentSize)
+		if e != nil {
+			report += fmt.Sprintf("* %s: %s\n", fileinfo.Name, attachmentErrorMessage(e))
+			continue
+		}
+
+		attached++
+		total += types.ByteSize(fileinfo.Size)
+		report += fmt.Sprintf("* %s: attached (%v)\n", mattermostName, types.ByteSize(fileinfo.Size))
+	}
+
+	msg := fmt.Sprintf("Attached %d of %d files (%v) to [%s](%s/browse/%s):\n",
+		attached, len(post.FileIds), total, in.IssueKey, instance.GetJiraBaseURL(), in.IssueKey)
+	return msg + report, nil
+}
+
+// attachmentErrorMessage turns a failed upload into a message that explains the
+// common causes reported by Jira.
+func attachmentErrorMessage(err error) string {
+	switch StatusCode(err) {
+	case http.StatusForbidden:
+		return "attachments are disabled in Jira, or you do not have permission to add attachments to this issue"
+	case http.StatusRequestEntityTooLarge:
+		return "the file exceeds the Jira attachment size limit"
+	}
+	if strings.Contains(err.Error(), "Maximum attachment size") {
+		return strings.TrimSpace(err.Error())
+	}
+	return "failed to attach: " + strings.TrimSpace(err.Error())
+}
+
 func notifyOnFailedAttachment(instance Instance, mattermostUserID, issueKey string, err error, format string, args ...interface{}) {
 	msg := "Failed to attach to issue: " + issueKey + ", " + fmt.Sprintf(format, args...)
 
@@ -860,7 +1270,7 @@ func (p *Plugin) getIssueDataForCloudWebhook(instance Instance, issueKey string)
 		return nil, err
 	}
 
-	issue, resp, err := jiraClient.Issue.Get(issueKey, nil)
+	issue, resp, err := jiraClient.Issue.Get(issueKey, &jira.GetQueryOptions{Expand: expandValueChangelog})
 	if err != nil {
 		switch {
 		case resp == nil:
@@ -939,13 +1349,27 @@ func getIssueFieldValue(issue *jira.Issue, key string) StringSet {
 	return NewStringSet()
 }
 
-func (p *Plugin) getIssueAsSlackAttachment(instance Instance, connection *Connection, issueKey string, showActions bool) ([]*model.SlackAttachment, error) {
+func (p *Plugin) getIssueAsSlackAttachment(instance Instance, connection *Connection, channelID, issueKey string, showActions bool) ([]*model.SlackAttachment, error) {
 	client, err := instance.GetClient(connection)
 	if err != nil {
 		return nil, err
 	}
 
-	issue, err := client.GetIssue(issueKey, nil)
+	issue, err := getIssueToView(client, issueKey)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := p.issueAsSlackAttachment(instance, connection.MattermostUserID, client, issue, showActions)
+	if err != nil {
+		return nil, err
+	}
+	p.addDueDateBadge(attachments, issue, connection.MattermostUserID)
+	p.addDevInfo(attachments, instance, connection.MattermostUserID, client, channelID, issue)
+	return attachments, nil
+}
+
+func getIssueToView(client Client, issueKey string) (*jira.Issue, error) {
+	issue, err := client.GetIssue(issueKey, &jira.GetQueryOptions{Expand: expandValueChangelog})
 	if err != nil {
 		switch StatusCode(err) {
 		case http.StatusNotFound:
@@ -958,8 +1382,19 @@ func (p *Plugin) getIssueAsSlackAttachment(instance Instance, connection *Connec
 			return nil, errors.WithMessage(err, "request to Jira failed")
 		}
 	}
+	return issue, nil
+}
 
-	return asSlackAttachment(instance, client, issue, showActions)
+func (p *Plugin) issueAsSlackAttachment(instance Instance, mattermostUserID types.ID, client Client, issue *jira.Issue, showActions bool) ([]*model.SlackAttachment, error) {
+	attachments, err := asSlackAttachment(instance, client, issue, showActions)
+	if err != nil {
+		return nil, err
+	}
+	if breadcrumb := p.issueBreadcrumb(instance, mattermostUserID, client, issue); breadcrumb != "" && len(attachments) > 0 {
+		attachments[0].Text = breadcrumb + "\n" + attachments[0].Text
+	}
+	p.addMattermostDiscussions(attachments, client, issue.Key)
+	return attachments, nil
 }
 
 func (p *Plugin) UnassignIssue(instance Instance, mattermostUserID types.ID, issueKey string) (string, error) {
@@ -984,6 +1419,7 @@ func (p *Plugin) UnassignIssue(instance Instance, mattermostUserID types.ID, iss
 		}
 		return "", err
 	}
+	p.recordSelfChange(instance.GetID(), issueKey, mattermostUserID)
 
 	permalink := fmt.Sprintf("%v/browse/%v", instance.GetJiraBaseURL(), issueKey)
 
@@ -993,6 +1429,28 @@ func (p *Plugin) UnassignIssue(instance Instance, mattermostUserID types.ID, iss
 
 const MinUserSearchQueryLength = 3
 
+// jiraCloudAccountIDPattern matches the account IDs of Jira Cloud: 24
+// hexadecimal digits, or a number and a UUID separated by a colon.
+var jiraCloudAccountIDPattern = regexp.MustCompile(`^([0-9a-f]{24}|[0-9]+:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})$`)
+
+// findExactJiraUser returns the user whose account ID, username or email
+// address is the search string, if there is exactly one.
+func findExactJiraUser(users []jira.User, search string) *jira.User {
+	var found *jira.User
+	for i := range users {
+		u := &users[i]
+		if u.AccountID == search ||
+			(u.Name != "" && strings.EqualFold(u.Name, search)) ||
+			(u.EmailAddress != "" && strings.EqualFold(u.EmailAddress, search)) {
+			if found != nil {
+				return nil
+			}
+			found = u
+		}
+	}
+	return found
+}
+
 func (p *Plugin) AssignIssue(instance Instance, mattermostUserID types.ID, issueKey, userSearch string, assignee *jira.User) (string, error) {
 	connection, err := p.userStore.LoadConnection(instance.GetID(), mattermostUserID)
 	if err != nil {
@@ -1004,7 +1462,7 @@ func (p *Plugin) AssignIssue(instance Instance, mattermostUserID types.ID, issue
 	}
 
 	// required minimum of three letters in assignee value
-	if len(userSearch) < MinUserSearchQueryLength {
+	if assignee == nil && len(userSearch) < MinUserSearchQueryLength {
 		errorMsg := fmt.Sprintf("`%s` contains less than %v characters.", userSearch, MinUserSearchQueryLength)
 		return errorMsg, nil
 	}
@@ -1021,13 +1479,24 @@ func (p *Plugin) AssignIssue(instance Instance, mattermostUserID types.ID, issue
 	if assignee != nil {
 		jiraUsers = append(jiraUsers, *assignee)
 	} else {
-		jiraUsers, err = client.SearchUsersAssignableToIssue(issueKey, userSearch, 10)
+		if instance.Common().IsCloudInstance() && jiraCloudAccountIDPattern.MatchString(userSearch) {
+			// The user search of Jira Cloud does not match account IDs, they
+			// are looked up by themselves.
+			jiraUsers, err = SearchUsersAssignableToIssue(client, issueKey, "accountId", userSearch, 1)
+		} else {
+			jiraUsers, err = client.SearchUsersAssignableToIssue(issueKey, userSearch, 10)
+		}
 		if StatusCode(err) == http.StatusUnauthorized {
 			return "You do not have the appropriate permissions to perform this action. Please contact your Jira administrator.", nil
 		}
 		if err != nil {
 			return "", err
 		}
+		// A Jira username or account ID picks its user among the matches,
+		// which may also include users whose names merely contain it.
+		if exact := findExactJiraUser(jiraUsers, userSearch); exact != nil {
+			jiraUsers = []jira.User{*exact}
+		}
 	}
 
 	// handle number of returned jira users
@@ -1036,7 +1505,7 @@ func (p *Plugin) AssignIssue(instance Instance, mattermostUserID types.ID, issue
 	}
 
 	if len(jiraUsers) > 1 {
-		errorMsg := fmt.Sprintf("`%s` matches %d or more users.  Please specify a unique assignee.\n", userSearch, len(jiraUsers))
+		errorMsg := fmt.Sprintf("`%s` matches %d or more users.  Please specify a unique assignee, such as their Jira username or email address.\n", userSearch, len(jiraUsers))
 		for i := range jiraUsers {
 			name := jiraUsers[i].DisplayName
 			extra := jiraUsers[i].Name
@@ -1068,6 +1537,7 @@ func (p *Plugin) AssignIssue(instance Instance, mattermostUserID types.ID, issue
 	if err := client.UpdateAssignee(issueKey, &user); err != nil {
 		return "", err
 	}
+	p.recordSelfChange(instance.GetID(), issueKey, mattermostUserID)
 
 	permalink := fmt.Sprintf("%v/browse/%v", instance.GetJiraBaseURL(), issueKey)
 
@@ -1089,45 +1559,56 @@ func (p *Plugin) TransitionIssue(in *InTransitionIssue) (string, error) {
 		return "", err
 	}
 
-	transitions, err := client.GetTransitions(in.IssueKey)
+	transitions, err := getIssueTransitions(client, in.IssueKey)
 	if err != nil {
-		return "", errors.New("we couldn't find the issue key. Please confirm the issue key and try again. You may not have permissions to access this issue")
-	}
-	if len(transitions) < 1 {
-		return "", errors.New("you do not have the appropriate permissions to perform this action. Please contact your Jira administrator")
+		return "", err
 	}
 
 	var transition jira.Transition
+	var exact *jira.Transition
 	matchingStates := []string{}
 	availableStates := []string{}
 
 	potentialState := strings.ToLower(strings.Join(strings.Fields(in.ToState), ""))
-	for _, t := range transitions {
+	for i, t := range transitions {
 		validState := strings.ToLower(strings.Join(strings.Fields(t.To.Name), ""))
 		if strings.Contains(validState, potentialState) {
 			matchingStates = append(matchingStates, t.To.Name)
 			transition = t
 		}
+		if validState == potentialState {
+			exact = &transitions[i]
+		}
 		availableStates = append(availableStates, t.To.Name)
 	}
 
-	switch len(matchingStates) {
-	case 0:
+	switch {
+	case len(matchingStates) == 0:
 		return "", errors.Errorf("%q is not a valid state. Please use one of: %q",
 			in.ToState, strings.Join(availableStates, ", "))
 
-	case 1:
+	case len(matchingStates) == 1:
 		// proceed
 
+	case exact != nil:
+		// The state is named exactly, e.g. "Open" while "Reopened" also matches.
+		transition = *exact
+
 	default:
 		return "", errors.Errorf("please be more specific, %q matched several states: %q",
 			in.ToState, strings.Join(matchingStates, ", "))
 	}
 
-	err = client.DoTransition(in.IssueKey, transition.ID)
+	return p.doTransition(in, client, instance, transition)
+}
+
+// doTransition applies the transition, and sends the user the updated issue.
+func (p *Plugin) doTransition(in *InTransitionIssue, client Client, instance Instance, transition jira.Transition) (string, error) {
+	err := client.DoTransition(in.IssueKey, transition.ID)
 	if err != nil {
-		return "", err
+		return "", p.explainJiraError(instance.GetID(), client, err, "transition an issue", "issue", in.IssueKey, "transition", transition.ID)
 	}
+	p.recordSelfChange(instance.GetID(), in.IssueKey, in.mattermostUserID)
 
 	msg := fmt.Sprintf("[%s](%v/browse/%v) transitioned to `%s`",
 		in.IssueKey, instance.GetJiraBaseURL(), in.IssueKey, transition.To.Name)
@@ -1150,6 +1631,7 @@ func (p *Plugin) TransitionIssue(in *InTransitionIssue) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	p.addMattermostDiscussions(attachments, client, in.IssueKey)
 
 	post := makePost(p.getUserID(), in.PostToChannelID, msg)
 	post.AddProp("attachments", attachments)
@@ -1215,7 +1697,7 @@ func (p *Plugin) GetIssueByKey(instanceID, mattermostUserID types.ID, issueKey s
 
 func (p *Plugin) GetIssueDataWithAPIToken(issueID, instanceID string) (*jira.Issue, error) {
 	client := &http.Client{}
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/rest/api/2/issue/%s", instanceID, issueID), nil)
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/rest/api/2/issue/%s?expand=%s", instanceID, issueID, expandValueChangelog), nil)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create http request for fetching issue data. IssueID: %s", issueID)
 	}
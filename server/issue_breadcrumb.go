@@ -0,0 +1,143 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	// epicLinkFieldSchema identifies the Epic Link field of Jira Software,
+	// which links the issues of Jira Server and of the company-managed
+	// projects to their epic.
+	epicLinkFieldSchema = "com.pyxis.greenhopper.jira:gh-epic-link"
+
+	// breadcrumbDepth is the number of ancestors resolved, e.g. the parent
+	// story of a sub-task and its epic.
+	breadcrumbDepth = 2
+
+	// issueAncestorTTL is how long the ancestors are remembered, so that the
+	// events of their children do not fetch them over and over.
+	issueAncestorTTL = 5 * time.Minute
+
+	breadcrumbSeparator = " › "
+)
+
+// issueAncestor is the parent or the epic of an issue in a breadcrumb.
+type issueAncestor struct {
+	Key       string
+	Summary   string
+	ParentKey string
+}
+
+// issueParentKey returns the key of the parent of the issue: the parent of
+// a sub-task, or of an issue of a team-managed project, or else its epic.
+func (p *Plugin) issueParentKey(instanceID types.ID, client Client, issue *jira.Issue) string {
+	if issue.Fields == nil {
+		return ""
+	}
+	if issue.Fields.Parent != nil && issue.Fields.Parent.Key != "" {
+		return issue.Fields.Parent.Key
+	}
+	if strings.EqualFold(issue.Fields.Type.Name, issueTypeEpic) {
+		return ""
+	}
+	fieldID, err := p.getCustomFieldID(instanceID, client, epicLinkFieldSchema)
+	if err != nil || fieldID == "" {
+		return ""
+	}
+	epicKey, _ := issue.Fields.Unknowns[fieldID].(string)
+	return epicKey
+}
+
+// getIssueAncestor fetches the minimal fields of an ancestor, including its
+// own parent. The ancestors are cached for each user, as their summary and
+// their own parent may be hidden from some users.
+func (p *Plugin) getIssueAncestor(instanceID, mattermostUserID types.ID, client Client, issueKey string) (issueAncestor, error) {
+	cacheKey := mattermostUserID.String() + "/" + issueKey
+	if ancestor, ok := p.issueAncestorCache.get(instanceID, cacheKey); ok {
+		return ancestor, nil
+	}
+
+	fields := "summary,issuetype,parent"
+	if fieldID, _ := p.getCustomFieldID(instanceID, client, epicLinkFieldSchema); fieldID != "" {
+		fields += "," + fieldID
+	}
+	issue, err := client.GetIssue(issueKey, &jira.GetQueryOptions{Fields: fields})
+	if err != nil {
+		return issueAncestor{}, err
+	}
+	ancestor := issueAncestor{
+		Key:       issueKey,
+		ParentKey: p.issueParentKey(instanceID, client, issue),
+	}
+	if issue.Fields != nil {
+		ancestor.Summary = issue.Fields.Summary
+	}
+	p.issueAncestorCache.set(instanceID, cacheKey, ancestor, issueAncestorTTL)
+	return ancestor, nil
+}
+
+// issueAncestors returns the ancestors of the issue, from the farthest, up to
+// breadcrumbDepth of them. The ancestors that cannot be fetched, e.g. that
+// the user cannot see, end the breadcrumb.
+func (p *Plugin) issueAncestors(instanceID, mattermostUserID types.ID, client Client, issue *jira.Issue) []issueAncestor {
+	ancestors := []issueAncestor{}
+	seen := map[string]bool{issue.Key: true}
+	for key := p.issueParentKey(instanceID, client, issue); key != "" && !seen[key] && len(ancestors) < breadcrumbDepth; {
+		ancestor, err := p.getIssueAncestor(instanceID, mattermostUserID, client, key)
+		if err != nil {
+			break
+		}
+		seen[key] = true
+		ancestors = append([]issueAncestor{ancestor}, ancestors...)
+		key = ancestor.ParentKey
+	}
+	return ancestors
+}
+
+// mdIssueBreadcrumb renders the ancestors of the issue as links, followed by
+// the issue itself, e.g. `EPIC-1 › STORY-2 › TASK-3`. It is empty when the
+// issue has no ancestors.
+func mdIssueBreadcrumb(ancestors []issueAncestor, issueKey, jiraURL string) string {
+	if len(ancestors) == 0 {
+		return ""
+	}
+	crumbs := []string{}
+	for _, ancestor := range ancestors {
+		title := ""
+		if ancestor.Summary != "" {
+			title = fmt.Sprintf(" %q", ancestor.Summary)
+		}
+		crumbs = append(crumbs, fmt.Sprintf("[%s](%s/browse/%s%s)", ancestor.Key, jiraURL, ancestor.Key, title))
+	}
+	return strings.Join(append(crumbs, issueKey), breadcrumbSeparator)
+}
+
+// issueBreadcrumb resolves and renders the breadcrumb of the issue, with the
+// Jira connection of the user.
+func (p *Plugin) issueBreadcrumb(instance Instance, mattermostUserID types.ID, client Client, issue *jira.Issue) string {
+	ancestors := p.issueAncestors(instance.GetID(), mattermostUserID, client, issue)
+	return mdIssueBreadcrumb(ancestors, issue.Key, instance.GetJiraBaseURL())
+}
+
+// cardBreadcrumb returns the breadcrumb of the issue of a subscription card,
+// when enabled, resolved with the Jira connection of the creator of the
+// subscription.
+func (p *Plugin) cardBreadcrumb(instanceID types.ID, sub ChannelSubscription, issue *jira.Issue) string {
+	if !p.getConfig().ShowIssueBreadcrumbInCards || sub.CreatedBy == "" || issue.Key == "" {
+		return ""
+	}
+	client, instance, _, err := p.getClient(instanceID, sub.CreatedBy)
+	if err != nil {
+		return ""
+	}
+	return p.issueBreadcrumb(instance, sub.CreatedBy, client, issue)
+}
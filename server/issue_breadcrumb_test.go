@@ -0,0 +1,152 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const breadcrumbTestEpicLinkField = "customfield_10014"
+
+type breadcrumbTestClient struct {
+	testClient
+	issues     map[string]*jira.Issue
+	issueCalls map[string]int
+}
+
+func (client breadcrumbTestClient) GetFields() ([]jira.Field, error) {
+	return []jira.Field{
+		{ID: "summary", Name: "Summary"},
+		{ID: breadcrumbTestEpicLinkField, Name: "Epic Link", Custom: true, Schema: jira.FieldSchema{Custom: epicLinkFieldSchema}},
+	}, nil
+}
+
+func (client breadcrumbTestClient) GetIssue(key string, options *jira.GetQueryOptions) (*jira.Issue, error) {
+	client.issueCalls[key]++
+	issue, ok := client.issues[key]
+	if !ok {
+		return nil, errors.New("issue does not exist or you do not have permission to see it")
+	}
+	return issue, nil
+}
+
+func newBreadcrumbTestClient() breadcrumbTestClient {
+	return breadcrumbTestClient{
+		issues: map[string]*jira.Issue{
+			"STORY-2": {Key: "STORY-2", Fields: &jira.IssueFields{
+				Summary:  "Checkout flow",
+				Type:     jira.IssueType{Name: "Story"},
+				Unknowns: map[string]interface{}{breadcrumbTestEpicLinkField: "EPIC-1"},
+			}},
+			"EPIC-1": {Key: "EPIC-1", Fields: &jira.IssueFields{
+				Summary: `The "new" store`,
+				Type:    jira.IssueType{Name: "Epic"},
+				Parent:  &jira.Parent{Key: "INIT-0"},
+			}},
+		},
+		issueCalls: map[string]int{},
+	}
+}
+
+func TestIssueBreadcrumb(t *testing.T) {
+	subTask := &jira.Issue{Key: "TASK-3", Fields: &jira.IssueFields{
+		Type:   jira.IssueType{Name: "Sub-task"},
+		Parent: &jira.Parent{Key: "STORY-2"},
+	}}
+
+	t.Run("up to two ancestors", func(t *testing.T) {
+		p := &Plugin{}
+		client := newBreadcrumbTestClient()
+		assert.Equal(t, "[EPIC-1](https://jiraurl1.com/browse/EPIC-1 \"The \\\"new\\\" store\") › "+
+			"[STORY-2](https://jiraurl1.com/browse/STORY-2 \"Checkout flow\") › TASK-3",
+			p.issueBreadcrumb(testInstance1, "user1", client, subTask))
+		assert.Zero(t, client.issueCalls["INIT-0"], "the ancestors beyond the epic are not fetched")
+
+		assert.NotEmpty(t, p.issueBreadcrumb(testInstance1, "user1", client, subTask))
+		assert.Equal(t, map[string]int{"STORY-2": 1, "EPIC-1": 1}, client.issueCalls, "the ancestors are cached")
+
+		p.issueBreadcrumb(testInstance1, "user2", client, subTask)
+		assert.Equal(t, map[string]int{"STORY-2": 2, "EPIC-1": 2}, client.issueCalls, "the ancestors are cached for each user")
+	})
+
+	t.Run("the cached ancestors expire", func(t *testing.T) {
+		now := time.Now()
+		p := &Plugin{}
+		p.issueAncestorCache.now = func() time.Time { return now }
+		client := newBreadcrumbTestClient()
+		p.issueBreadcrumb(testInstance1, "user1", client, subTask)
+		now = now.Add(issueAncestorTTL + time.Second)
+		p.issueBreadcrumb(testInstance1, "user1", client, subTask)
+		assert.Equal(t, 2, client.issueCalls["STORY-2"])
+	})
+
+	t.Run("epic link", func(t *testing.T) {
+		p := &Plugin{}
+		story := &jira.Issue{Key: "STORY-5", Fields: &jira.IssueFields{
+			Type:     jira.IssueType{Name: "Story"},
+			Unknowns: map[string]interface{}{breadcrumbTestEpicLinkField: "EPIC-1"},
+		}}
+		assert.Equal(t, "[EPIC-1](https://jiraurl1.com/browse/EPIC-1 \"The \\\"new\\\" store\") › STORY-5",
+			p.issueBreadcrumb(testInstance1, "user1", newBreadcrumbTestClient(), story))
+	})
+
+	t.Run("no parent", func(t *testing.T) {
+		p := &Plugin{}
+		issue := &jira.Issue{Key: "TASK-4", Fields: &jira.IssueFields{Type: jira.IssueType{Name: "Task"}}}
+		assert.Empty(t, p.issueBreadcrumb(testInstance1, "user1", newBreadcrumbTestClient(), issue))
+	})
+
+	t.Run("the parent cannot be fetched", func(t *testing.T) {
+		p := &Plugin{}
+		issue := &jira.Issue{Key: "TASK-6", Fields: &jira.IssueFields{Parent: &jira.Parent{Key: "SECRET-1"}}}
+		assert.Empty(t, p.issueBreadcrumb(testInstance1, "user1", newBreadcrumbTestClient(), issue))
+	})
+}
+
+func TestPostToChannelBreadcrumb(t *testing.T) {
+	bb, err := os.ReadFile(filepath.Join("testdata", "webhook-issue-created.json"))
+	require.NoError(t, err)
+	breadcrumb := "[EPIC-1](https://some-instance-test.atlassian.net/browse/EPIC-1) › TES-41"
+
+	for renderStyle, expected := range map[string]string{
+		RenderStyleCompact: "Test User **created** story [TES-41: Unit test summary](https://some-instance-test.atlassian.net/browse/TES-41)\n" + breadcrumb,
+		RenderStyleTitle:   "story [TES-41: Unit test summary](https://some-instance-test.atlassian.net/browse/TES-41)",
+	} {
+		t.Run(renderStyle, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(func(post *model.Post) *model.Post {
+				return post.Clone()
+			}, nil)
+			p := Plugin{}
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+			p.userStore = mockUserStore{}
+
+			wh, err := ParseWebhook(bb)
+			require.NoError(t, err)
+			wh.(*webhook).breadcrumb = breadcrumb
+
+			post, _, err := wh.PostToChannel(&p, "", "thechannelid", "theuserid", "", renderStyle, "", nil)
+			require.NoError(t, err)
+			assert.Equal(t, expected, post.Message)
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		p := &Plugin{}
+		assert.Empty(t, p.cardBreadcrumb(testInstance1.InstanceID, ChannelSubscription{CreatedBy: "user_id"}, &jira.Issue{Key: "TES-41"}))
+	})
+}
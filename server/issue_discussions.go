@@ -0,0 +1,105 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// maxDiscussionLinks is the number of links to Mattermost discussions shown
+// on an issue card.
+const maxDiscussionLinks = 5
+
+// parseMattermostPermalink returns the ID of the post that rawURL links to,
+// if it is a permalink (<site URL>/<team>/pl/<post ID>) of this Mattermost
+// server.
+func parseMattermostPermalink(siteURL, rawURL string) (string, bool) {
+	site, err := url.Parse(siteURL)
+	if err != nil || site.Host == "" {
+		return "", false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || !strings.EqualFold(u.Host, site.Host) {
+		return "", false
+	}
+
+	path := strings.TrimPrefix(u.Path, strings.TrimSuffix(site.Path, "/"))
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 3 || parts[1] != "pl" || !model.IsValidId(parts[2]) {
+		return "", false
+	}
+	return parts[2], true
+}
+
+// getMattermostDiscussions returns links to the Mattermost discussions that
+// the issue has remote links to. Failing to read the remote links only hides
+// the discussions, the card is still shown.
+func (p *Plugin) getMattermostDiscussions(client Client, issueKey string) []string {
+	links, err := client.GetRemoteLinks(issueKey)
+	if err != nil {
+		p.client.Log.Debug("Failed to get the remote links of the issue", "issue", issueKey, "error", err.Error())
+		return nil
+	}
+
+	siteURL := p.GetSiteURL()
+	seen := map[string]bool{}
+	discussions := []string{}
+	for _, link := range links {
+		if len(discussions) == maxDiscussionLinks {
+			break
+		}
+		if link.Object == nil {
+			continue
+		}
+		postID, ok := parseMattermostPermalink(siteURL, link.Object.URL)
+		if !ok || seen[postID] {
+			continue
+		}
+		seen[postID] = true
+
+		label, ok := p.discussionLabel(postID)
+		if !ok {
+			continue
+		}
+		discussions = append(discussions, fmt.Sprintf("[%s](%s)", label, link.Object.URL))
+	}
+	return discussions
+}
+
+// discussionLabel names the channel of the post. The names of private
+// channels and DMs are not shown, the permalink itself checks the access of
+// whoever follows it.
+func (p *Plugin) discussionLabel(postID string) (string, bool) {
+	post, err := p.client.Post.GetPost(postID)
+	if err != nil {
+		return "", false
+	}
+	channel, err := p.client.Channel.Get(post.ChannelId)
+	if err != nil {
+		return "", false
+	}
+	if channel.Type != model.ChannelTypeOpen {
+		return "a private conversation", true
+	}
+	return "#" + channel.Name, true
+}
+
+// addMattermostDiscussions adds a "Discussed in" field to the issue card.
+func (p *Plugin) addMattermostDiscussions(attachments []*model.SlackAttachment, client Client, issueKey string) {
+	if len(attachments) == 0 {
+		return
+	}
+	discussions := p.getMattermostDiscussions(client, issueKey)
+	if len(discussions) == 0 {
+		return
+	}
+	attachments[0].Fields = append(attachments[0].Fields, &model.SlackAttachmentField{
+		Title: "Discussed in",
+		Value: strings.Join(discussions, ", "),
+	})
+}
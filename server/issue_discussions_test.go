@@ -0,0 +1,94 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+)
+
+type remoteLinksClient struct {
+	testClient
+	links []jira.RemoteLink
+}
+
+func (client remoteLinksClient) GetRemoteLinks(issueKey string) ([]jira.RemoteLink, error) {
+	return client.links, nil
+}
+
+func remoteLinkTo(url string) jira.RemoteLink {
+	return jira.RemoteLink{Object: &jira.RemoteLinkObject{URL: url}}
+}
+
+func TestParseMattermostPermalink(t *testing.T) {
+	postID := model.NewId()
+
+	for name, tc := range map[string]struct {
+		siteURL  string
+		rawURL   string
+		expected bool
+	}{
+		"permalink":               {siteURL: "https://mm.example.com", rawURL: "https://mm.example.com/team/pl/" + postID, expected: true},
+		"permalink with subpath":  {siteURL: "https://example.com/mm/", rawURL: "https://example.com/mm/team/pl/" + postID, expected: true},
+		"other server":            {siteURL: "https://mm.example.com", rawURL: "https://other.example.com/team/pl/" + postID},
+		"not a permalink":         {siteURL: "https://mm.example.com", rawURL: "https://mm.example.com/team/channels/town-square"},
+		"invalid post ID":         {siteURL: "https://mm.example.com", rawURL: "https://mm.example.com/team/pl/abc"},
+		"site URL not configured": {siteURL: "", rawURL: "https://mm.example.com/team/pl/" + postID},
+	} {
+		t.Run(name, func(t *testing.T) {
+			id, ok := parseMattermostPermalink(tc.siteURL, tc.rawURL)
+			assert.Equal(t, tc.expected, ok)
+			if tc.expected {
+				assert.Equal(t, postID, id)
+			}
+		})
+	}
+}
+
+func TestAddMattermostDiscussions(t *testing.T) {
+	publicPostID, privatePostID := model.NewId(), model.NewId()
+
+	api := &plugintest.API{}
+	api.On("GetPost", publicPostID).Return(&model.Post{Id: publicPostID, ChannelId: "public"}, nil)
+	api.On("GetPost", privatePostID).Return(&model.Post{Id: privatePostID, ChannelId: "private"}, nil)
+	api.On("GetChannel", "public").Return(&model.Channel{Id: "public", Name: "town-square", Type: model.ChannelTypeOpen}, nil)
+	api.On("GetChannel", "private").Return(&model.Channel{Id: "private", Name: "secret", Type: model.ChannelTypePrivate}, nil)
+
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.updateConfig(func(conf *config) {
+		conf.mattermostSiteURL = mattermostSiteURL
+	})
+
+	t.Run("links to this server", func(t *testing.T) {
+		client := remoteLinksClient{links: []jira.RemoteLink{
+			remoteLinkTo(mattermostSiteURL + "/team/pl/" + publicPostID),
+			remoteLinkTo(mattermostSiteURL + "/team/pl/" + publicPostID),
+			remoteLinkTo("https://confluence.example.com/page"),
+			{},
+			remoteLinkTo(mattermostSiteURL + "/team/pl/" + privatePostID),
+		}}
+		attachments := []*model.SlackAttachment{{}}
+
+		p.addMattermostDiscussions(attachments, client, "TEST-1")
+		assert.Len(t, attachments[0].Fields, 1)
+		assert.Equal(t, "Discussed in", attachments[0].Fields[0].Title)
+		assert.Equal(t, "[#town-square]("+mattermostSiteURL+"/team/pl/"+publicPostID+"), "+
+			"[a private conversation]("+mattermostSiteURL+"/team/pl/"+privatePostID+")", attachments[0].Fields[0].Value)
+	})
+
+	t.Run("no Mattermost links", func(t *testing.T) {
+		client := remoteLinksClient{links: []jira.RemoteLink{remoteLinkTo("https://confluence.example.com/page")}}
+		attachments := []*model.SlackAttachment{{}}
+
+		p.addMattermostDiscussions(attachments, client, "TEST-1")
+		assert.Empty(t, attachments[0].Fields)
+	})
+}
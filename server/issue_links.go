@@ -0,0 +1,131 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// issueLinkDescription returns how the link relates the issue to the linked
+// one, e.g. "blocks" or "is blocked by", and the linked issue.
+func issueLinkDescription(link *jira.IssueLink) (string, *jira.Issue) {
+	if link.OutwardIssue != nil {
+		return link.Type.Outward, link.OutwardIssue
+	}
+	return link.Type.Inward, link.InwardIssue
+}
+
+// issueLinkAllowed reports whether a link is in the allowlist of a
+// subscription, which holds link descriptions like "is blocked by", so that
+// each direction of a link type can be picked.
+func issueLinkAllowed(link *jira.IssueLink, allowed StringSet) bool {
+	description, _ := issueLinkDescription(link)
+	for linkType := range allowed {
+		if strings.EqualFold(linkType, description) {
+			return true
+		}
+	}
+	return false
+}
+
+// mdIssueLinksField returns the card field listing the links of the issue
+// allowed by linkTypes, the rest being collapsed to a count. No field is
+// returned without an allowlist, or when the issue has no links.
+func (jwh *JiraWebhook) mdIssueLinksField(linkTypes StringSet) *model.SlackAttachmentField {
+	if len(linkTypes) == 0 || jwh.Issue.Fields == nil || len(jwh.Issue.Fields.IssueLinks) == 0 {
+		return nil
+	}
+
+	lines := []string{}
+	more := 0
+	for _, link := range jwh.Issue.Fields.IssueLinks {
+		description, linked := issueLinkDescription(link)
+		if linked == nil || !issueLinkAllowed(link, linkTypes) {
+			more++
+			continue
+		}
+
+		key := jwh.mdJiraLink(linked.Key, "/browse/"+linked.Key)
+		if key == "" {
+			key = linked.Key
+		}
+		line := description + " " + key
+		if linked.Fields != nil && linked.Fields.Summary != "" {
+			line += " " + truncate(linked.Fields.Summary, 80)
+		}
+		lines = append(lines, line)
+	}
+
+	switch more {
+	case 0:
+	case 1:
+		lines = append(lines, "+1 more link")
+	default:
+		lines = append(lines, fmt.Sprintf("+%d more links", more))
+	}
+
+	return &model.SlackAttachmentField{
+		Title: cardLabelLinks,
+		Value: strings.Join(lines, "\n"),
+		Short: false,
+	}
+}
+
+type issueLinkTypeOption struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// issueLinkTypeOptions returns the link descriptions of the link types of the
+// instance, which subscriptions can allow in their cards.
+func issueLinkTypeOptions(linkTypes []jira.IssueLinkType) []issueLinkTypeOption {
+	seen := StringSet{}
+	options := []issueLinkTypeOption{}
+	for _, linkType := range linkTypes {
+		for _, description := range []string{linkType.Outward, linkType.Inward} {
+			if description == "" || seen.ContainsAny(strings.ToLower(description)) {
+				continue
+			}
+			seen[strings.ToLower(description)] = true
+			options = append(options, issueLinkTypeOption{Label: description, Value: description})
+		}
+	}
+	sort.Slice(options, func(i, j int) bool {
+		return strings.ToLower(options[i].Label) < strings.ToLower(options[j].Label)
+	})
+	return options
+}
+
+func (p *Plugin) httpGetIssueLinkTypes(w http.ResponseWriter, r *http.Request) (int, error) {
+	if r.Method != http.MethodGet {
+		return http.StatusMethodNotAllowed, errors.New("Request: " + r.Method + " is not allowed, must be GET")
+	}
+
+	mattermostUserID := r.Header.Get(headerMattermostUserID)
+	if mattermostUserID == "" {
+		return http.StatusUnauthorized, errors.New("not authorized")
+	}
+
+	instanceID := r.FormValue(instanceIDQueryParam)
+	client, _, _, err := p.getClient(types.ID(instanceID), types.ID(mattermostUserID))
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	linkTypes, err := client.GetIssueLinkTypes()
+	if err != nil {
+		return http.StatusInternalServerError, errors.WithMessage(err, "failed to get the issue link types")
+	}
+
+	return respondJSON(w, issueLinkTypeOptions(linkTypes))
+}
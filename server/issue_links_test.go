@@ -0,0 +1,89 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	blocksLinkType  = jira.IssueLinkType{Name: "Blocks", Inward: "is blocked by", Outward: "blocks"}
+	relatesLinkType = jira.IssueLinkType{Name: "Relates", Inward: "relates to", Outward: "relates to"}
+)
+
+func linkedIssue(key, summary string) *jira.Issue {
+	return &jira.Issue{Key: key, Fields: &jira.IssueFields{Summary: summary}}
+}
+
+func issueLinksWebhook() *JiraWebhook {
+	return &JiraWebhook{Issue: jira.Issue{
+		Key:  "KT-1",
+		Self: "https://jira.example.com/rest/api/2/issue/1",
+		Fields: &jira.IssueFields{IssueLinks: []*jira.IssueLink{
+			{Type: blocksLinkType, OutwardIssue: linkedIssue("KT-2", "Ship the release")},
+			{Type: blocksLinkType, InwardIssue: linkedIssue("KT-3", "Fix the build")},
+			{Type: relatesLinkType, OutwardIssue: linkedIssue("KT-4", "Update the docs")},
+			{Type: relatesLinkType, InwardIssue: linkedIssue("KT-5", "Review the design")},
+		}},
+	}}
+}
+
+func TestIssueLinksField(t *testing.T) {
+	for name, tc := range map[string]struct {
+		linkTypes     StringSet
+		expectedValue string
+	}{
+		"one direction": {
+			linkTypes: NewStringSet("is blocked by"),
+			expectedValue: "is blocked by [KT-3](https://jira.example.com/browse/KT-3) Fix the build\n" +
+				"+3 more links",
+		},
+		"the other direction": {
+			linkTypes: NewStringSet("blocks"),
+			expectedValue: "blocks [KT-2](https://jira.example.com/browse/KT-2) Ship the release\n" +
+				"+3 more links",
+		},
+		"case insensitive": {
+			linkTypes: NewStringSet("Blocks", "RELATES TO"),
+			expectedValue: "blocks [KT-2](https://jira.example.com/browse/KT-2) Ship the release\n" +
+				"relates to [KT-4](https://jira.example.com/browse/KT-4) Update the docs\n" +
+				"relates to [KT-5](https://jira.example.com/browse/KT-5) Review the design\n" +
+				"+1 more link",
+		},
+		"no link allowed": {
+			linkTypes:     NewStringSet("duplicates"),
+			expectedValue: "+4 more links",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			field := issueLinksWebhook().mdIssueLinksField(tc.linkTypes)
+			require.NotNil(t, field)
+			assert.Equal(t, "Links", field.Title)
+			assert.Equal(t, tc.expectedValue, field.Value)
+		})
+	}
+
+	t.Run("without an allowlist", func(t *testing.T) {
+		assert.Nil(t, issueLinksWebhook().mdIssueLinksField(nil))
+	})
+
+	t.Run("without links", func(t *testing.T) {
+		jwh := issueLinksWebhook()
+		jwh.Issue.Fields.IssueLinks = nil
+		assert.Nil(t, jwh.mdIssueLinksField(NewStringSet("blocks")))
+	})
+}
+
+func TestIssueLinkTypeOptions(t *testing.T) {
+	options := issueLinkTypeOptions([]jira.IssueLinkType{relatesLinkType, blocksLinkType})
+	assert.Equal(t, []issueLinkTypeOption{
+		{Label: "blocks", Value: "blocks"},
+		{Label: "is blocked by", Value: "is blocked by"},
+		{Label: "relates to", Value: "relates to"},
+	}, options)
+}
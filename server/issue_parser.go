@@ -6,6 +6,7 @@ package main
 import (
 	"fmt"
 	"regexp"
+	"time"
 
 	jira "github.com/andygrunwald/go-jira"
 
@@ -32,53 +33,71 @@ func reporterSummary(reporter *jira.User) string {
 	return reporterSummary
 }
 
-func getActions(instanceID types.ID, client Client, issue *jira.Issue) ([]*model.PostAction, error) {
-	var actions []*model.PostAction
-
+// getActions returns the actions of an issue card. The transitions are only
+// fetched when the user clicks the transition button, so that they are
+// those of the issue at that time.
+func getActions(instance Instance, issue *jira.Issue) []*model.PostAction {
 	ctx := map[string]interface{}{
 		"issue_key":   issue.ID,
-		"instance_id": instanceID.String(),
+		"instance_id": instance.GetID().String(),
 	}
 
-	integration := &model.PostActionIntegration{
-		URL:     fmt.Sprintf("/plugins/%s%s%s", manifest.Id, routeAPI, routeIssueTransition),
-		Context: ctx,
-	}
-
-	var options []*model.PostActionOptions
-
-	transitions, err := client.GetTransitions(issue.Key)
-	if err != nil {
-		return actions, err
+	return []*model.PostAction{
+		{
+			Name: "Transition issue",
+			Type: "button",
+			Integration: &model.PostActionIntegration{
+				URL:     fmt.Sprintf("/plugins/%s%s%s", manifest.Id, routeAPI, routeIssueTransitionPicker),
+				Context: ctx,
+			},
+		},
+		{
+			Name: "Share publicly",
+			Type: "button",
+			Integration: &model.PostActionIntegration{
+				URL:     fmt.Sprintf("/plugins/%s%s%s", manifest.Id, routeAPI, routeSharePublicly),
+				Context: ctx,
+			},
+		},
+		{
+			Name: "Assign to me",
+			Type: "button",
+			Integration: &model.PostActionIntegration{
+				URL:     fmt.Sprintf("/plugins/%s%s%s", manifest.Id, routeAPI, routeIssueAssignToMe),
+				Context: ctx,
+			},
+		},
 	}
+}
 
-	// Remove current issue status from possible transitions
-	for _, transition := range transitions {
-		if transition.Name != issue.Fields.Status.Name {
-			options = append(options, &model.PostActionOptions{
-				Text:  transition.Name,
-				Value: transition.To.Name,
-			})
-		}
+// subscriptionCardActions are the buttons of the cards posted by the
+// subscriptions. They act with the Jira connection of the user who clicks,
+// and leave the card as it is, since it shows an event of the issue.
+func subscriptionCardActions(instanceID types.ID, issueKey string) []*model.PostAction {
+	ctx := map[string]interface{}{
+		"issue_key":   issueKey,
+		"instance_id": instanceID.String(),
+		"keep_card":   true,
 	}
 
-	actions = append(actions, &model.PostAction{
-		Name:        "Transition issue",
-		Type:        "select",
-		Options:     options,
-		Integration: integration,
-	})
-
-	actions = append(actions, &model.PostAction{
-		Name: "Share publicly",
-		Type: "button",
-		Integration: &model.PostActionIntegration{
-			URL:     fmt.Sprintf("/plugins/%s%s%s", manifest.Id, routeAPI, routeSharePublicly),
-			Context: ctx,
+	return []*model.PostAction{
+		{
+			Name: "Transition issue",
+			Type: "button",
+			Integration: &model.PostActionIntegration{
+				URL:     fmt.Sprintf("/plugins/%s%s%s", manifest.Id, routeAPI, routeIssueTransitionPicker),
+				Context: ctx,
+			},
 		},
-	})
-
-	return actions, nil
+		{
+			Name: "Assign to me",
+			Type: "button",
+			Integration: &model.PostActionIntegration{
+				URL:     fmt.Sprintf("/plugins/%s%s%s", manifest.Id, routeAPI, routeIssueAssignToMe),
+				Context: ctx,
+			},
+		},
+	}
 }
 
 func asSlackAttachment(instance Instance, client Client, issue *jira.Issue, showActions bool) ([]*model.SlackAttachment, error) {
@@ -113,13 +132,15 @@ func asSlackAttachment(instance Instance, client Client, issue *jira.Issue, show
 		})
 	}
 
+	if field := timeInStatusField(issue, time.Now()); field != nil {
+		fields = append(fields, field)
+	}
+
 	var actions []*model.PostAction
-	var err error
 	if showActions {
-		actions, err = getActions(instance.GetID(), client, issue)
-		if err != nil {
-			return []*model.SlackAttachment{}, err
-		}
+		// Post actions cannot open a URL, the card links to the issue instead.
+		text += fmt.Sprintf("\n[View in Jira](%s/browse/%s)", instance.GetJiraBaseURL(), issue.Key)
+		actions = getActions(instance, issue)
 	}
 
 	return []*model.SlackAttachment{
@@ -5,10 +5,15 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	jira "github.com/andygrunwald/go-jira"
 	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -110,25 +115,14 @@ func TestAsSlackAttachment(t *testing.T) {
 			},
 			showActions: true,
 			expectedAttachment: &model.SlackAttachment{
-				Text:  "[MM-57208:  (Open)](https://jiraurl2.com/browse/MM-57208)",
+				Text:  "[MM-57208:  (Open)](https://jiraurl2.com/browse/MM-57208)\n[View in Jira](https://jiraurl2.com/browse/MM-57208)",
 				Color: "#95b7d0",
 				Actions: []*model.PostAction{
 					{
-						Type: "select",
+						Type: "button",
 						Name: "Transition issue",
-						Options: []*model.PostActionOptions{
-							{
-								Value: "To Do",
-							},
-							{
-								Value: "In Progress",
-							},
-							{
-								Value: "In Testing",
-							},
-						},
 						Integration: &model.PostActionIntegration{
-							URL: fmt.Sprintf("/plugins/%s/api/v2/transition", manifest.Id),
+							URL: fmt.Sprintf("/plugins/%s/api/v2/transition-picker", manifest.Id),
 							Context: map[string]any{
 								"issue_key":   "some ID",
 								"instance_id": testInstance2.GetID().String(),
@@ -146,6 +140,17 @@ func TestAsSlackAttachment(t *testing.T) {
 							},
 						},
 					},
+					{
+						Type: "button",
+						Name: "Assign to me",
+						Integration: &model.PostActionIntegration{
+							URL: fmt.Sprintf("/plugins/%s/api/v2/assign-to-me", manifest.Id),
+							Context: map[string]any{
+								"issue_key":   "some ID",
+								"instance_id": testInstance2.GetID().String(),
+							},
+						},
+					},
 				},
 			},
 		},
@@ -158,3 +163,30 @@ func TestAsSlackAttachment(t *testing.T) {
 		})
 	}
 }
+
+func TestPostToChannelCardActions(t *testing.T) {
+	bb, err := os.ReadFile(filepath.Join("testdata", "webhook-issue-created.json"))
+	require.NoError(t, err)
+
+	api := &plugintest.API{}
+	api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(func(post *model.Post) *model.Post {
+		return post.Clone()
+	}, nil)
+	p := Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.userStore = mockUserStore{}
+
+	wh, err := ParseWebhook(bb)
+	require.NoError(t, err)
+	post, _, err := wh.PostToChannel(&p, testInstance1.InstanceID, "thechannelid", "theuserid", "", RenderStyleFull, "", nil)
+	require.NoError(t, err)
+
+	attachments := post.Attachments()
+	require.Len(t, attachments, 1)
+	require.Len(t, attachments[0].Actions, 2)
+	assert.Equal(t, "Transition issue", attachments[0].Actions[0].Name)
+	assert.Equal(t, "Assign to me", attachments[0].Actions[1].Name)
+	assert.Equal(t, "TES-41", attachments[0].Actions[1].Integration.Context["issue_key"])
+	assert.Equal(t, true, attachments[0].Actions[1].Integration.Context["keep_card"])
+}
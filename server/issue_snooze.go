@@ -0,0 +1,101 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	prefixIssueSnooze = "issue_snooze_"
+
+	// issueSnoozeDuration is how long the notifications about an issue are
+	// held back once the user snoozed it.
+	issueSnoozeDuration = 4 * time.Hour
+)
+
+func issueSnoozeKey(instanceID, mattermostUserID types.ID, issueKey string) string {
+	return hashkey(prefixIssueSnooze, instanceID.String()+"/"+strings.ToUpper(issueKey)+"/"+mattermostUserID.String())
+}
+
+// snoozeIssue holds back the notifications about the issue for the user, and
+// returns when they resume. The snooze expires with its key.
+func (p *Plugin) snoozeIssue(instanceID, mattermostUserID types.ID, issueKey string, now time.Time) (time.Time, error) {
+	until := now.Add(issueSnoozeDuration)
+	_, err := p.client.KV.Set(issueSnoozeKey(instanceID, mattermostUserID, issueKey), until.Unix(), pluginapi.SetExpiry(issueSnoozeDuration))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return until, nil
+}
+
+// isIssueSnoozed reports whether the user snoozed the notifications about the
+// issue. The notifications are sent when the snooze cannot be read.
+func (p *Plugin) isIssueSnoozed(instanceID, mattermostUserID types.ID, issueKey string) bool {
+	var until int64
+	if err := p.client.KV.Get(issueSnoozeKey(instanceID, mattermostUserID, issueKey), &until); err != nil {
+		return false
+	}
+	return until > time.Now().Unix()
+}
+
+// addSnoozeIssueAction adds the button that snoozes the issue to a
+// notification about it.
+func addSnoozeIssueAction(post *model.Post, instanceID types.ID, issueKey string) {
+	attachments, _ := post.GetProp("attachments").([]*model.SlackAttachment)
+	attachments = append(attachments, &model.SlackAttachment{
+		Actions: []*model.PostAction{
+			{
+				Name: fmt.Sprintf("Snooze this issue (%dh)", int(issueSnoozeDuration.Hours())),
+				Type: "button",
+				Integration: &model.PostActionIntegration{
+					URL: fmt.Sprintf("/plugins/%s%s%s", manifest.Id, routeAPI, routeIssueSnooze),
+					Context: map[string]interface{}{
+						"instance_id": instanceID.String(),
+						"issue_key":   issueKey,
+					},
+				},
+			},
+		},
+	})
+	post.AddProp("attachments", attachments)
+}
+
+// httpSnoozeIssue snoozes the issue of a notification for the user who
+// clicked its button.
+func (p *Plugin) httpSnoozeIssue(w http.ResponseWriter, r *http.Request) (int, error) {
+	var requestData model.PostActionIntegrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+		return respondErr(w, http.StatusBadRequest, errors.New("unmarshall the body"))
+	}
+	// The user who clicked is the one Mattermost authenticated, the body can
+	// name anyone.
+	if userID := r.Header.Get("Mattermost-User-Id"); userID == "" || userID != requestData.UserId {
+		return respondErr(w, http.StatusUnauthorized, errors.New("not authorized"))
+	}
+	instanceID, _ := requestData.Context["instance_id"].(string)
+	issueKey, _ := requestData.Context["issue_key"].(string)
+	if instanceID == "" || issueKey == "" {
+		return respondErr(w, http.StatusBadRequest, errors.New("no issue was found in context data"))
+	}
+	mattermostUserID := types.ID(requestData.UserId)
+
+	until, err := p.snoozeIssue(types.ID(instanceID), mattermostUserID, issueKey, time.Now())
+	if err != nil {
+		return respondErr(w, http.StatusInternalServerError, errors.WithMessage(err, "failed to snooze the issue"))
+	}
+	return respondJSON(w, &model.PostActionIntegrationResponse{
+		EphemeralText: fmt.Sprintf("The notifications about %s are snoozed until %s.", issueKey, until.In(p.userLocation(mattermostUserID)).Format("15:04 MST")),
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddSnoozeIssueAction(t *testing.T) {
+	post := &model.Post{}
+	addSnoozeIssueAction(post, testInstance1.InstanceID, "TEST-10")
+	attachments := post.Attachments()
+	require.Len(t, attachments, 1)
+	require.Len(t, attachments[0].Actions, 1)
+	assert.Equal(t, "Snooze this issue (4h)", attachments[0].Actions[0].Name)
+	assert.Equal(t, map[string]interface{}{
+		"instance_id": testInstance1.InstanceID.String(),
+		"issue_key":   "TEST-10",
+	}, attachments[0].Actions[0].Integration.Context)
+}
+
+func TestIssueSnoozeKey(t *testing.T) {
+	assert.Equal(t, issueSnoozeKey(testInstance1.InstanceID, "user", "test-10"), issueSnoozeKey(testInstance1.InstanceID, "user", "TEST-10"))
+	assert.NotEqual(t, issueSnoozeKey(testInstance1.InstanceID, "user", "TEST-10"), issueSnoozeKey(testInstance1.InstanceID, "other", "TEST-10"))
+	assert.NotEqual(t, issueSnoozeKey(testInstance1.InstanceID, "user", "TEST-10"), issueSnoozeKey(testInstance1.InstanceID, "user", "TEST-11"))
+}
+
+func TestCreateBotIssueDMPostSnoozed(t *testing.T) {
+	for name, tc := range map[string]struct {
+		until  []byte
+		posted bool
+	}{
+		"not snoozed": {posted: true},
+		"snoozed":     {until: []byte(strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))},
+		"expired":     {until: []byte(strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)), posted: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			p := &Plugin{}
+			p.updateConfig(func(conf *config) {
+				conf.botUserID = "bot_id"
+			})
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+			p.userStore = getMockUserStoreKV()
+
+			api.On("KVGet", issueSnoozeKey(testInstance1.InstanceID, mockUserIDWithNotifications, "TEST-10")).Return(tc.until, nil)
+			api.On("GetDirectChannel", mockUserIDWithNotifications, "bot_id").Return(&model.Channel{Id: "dm"}, nil)
+			var posted *model.Post
+			api.On("CreatePost", mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+				posted = args.Get(0).(*model.Post).Clone()
+			}).Return(func(post *model.Post) *model.Post { return post.Clone() }, nil)
+
+			_, err := p.CreateBotIssueDMPost(testInstance1.InstanceID, mockUserIDWithNotifications, "TEST-10", "TEST-10 was updated", "", "")
+			require.NoError(t, err)
+			if !tc.posted {
+				assert.Nil(t, posted)
+				return
+			}
+			require.NotNil(t, posted)
+			assert.Len(t, posted.Attachments(), 1, "the notification can be snoozed")
+		})
+	}
+}
+
+func TestHTTPSnoozeIssue(t *testing.T) {
+	api := &plugintest.API{}
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	api.On("KVSetWithOptions", issueSnoozeKey(testInstance1.InstanceID, mockUserIDWithNotifications, "TEST-10"), mock.Anything,
+		model.PluginKVSetOptions{ExpireInSeconds: 4 * 60 * 60}).Return(true, nil)
+	api.On("GetUser", mockUserIDWithNotifications).Return(&model.User{Id: mockUserIDWithNotifications, Timezone: map[string]string{"useAutomaticTimezone": "false", "manualTimezone": "UTC"}}, nil)
+
+	body, err := json.Marshal(model.PostActionIntegrationRequest{
+		UserId: mockUserIDWithNotifications,
+		Context: map[string]interface{}{
+			"instance_id": testInstance1.InstanceID.String(),
+			"issue_key":   "TEST-10",
+		},
+	})
+	require.NoError(t, err)
+	request := func(userID string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, makeAPIRoute(routeIssueSnooze), bytes.NewReader(body))
+		r.Header.Set("Mattermost-User-Id", userID)
+		return r
+	}
+
+	status, _ := p.httpSnoozeIssue(httptest.NewRecorder(), request("someone_else"))
+	assert.Equal(t, http.StatusUnauthorized, status, "another user cannot snooze the notifications of the user")
+	api.AssertNotCalled(t, "KVSetWithOptions", mock.Anything, mock.Anything, mock.Anything)
+
+	w := httptest.NewRecorder()
+	status, err = p.httpSnoozeIssue(w, request(mockUserIDWithNotifications))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+
+	response := model.PostActionIntegrationResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Regexp(t, `^The notifications about TEST-10 are snoozed until \d\d:\d\d UTC\.$`, response.EphemeralText)
+}
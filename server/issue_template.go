@@ -0,0 +1,237 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// templateSystemFields are the system fields copied from a template issue,
+// all its custom fields are copied too.
+var templateSystemFields = NewStringSet("summary", "description", "priority", "labels", "components", "fixVersions", "versions")
+
+// issueTemplate are the fields of a template issue that can be set when
+// creating an issue of a type in a project, to pre-populate the create form.
+type issueTemplate struct {
+	IssueKey    string                 `json:"issue_key"`
+	IssueTypeID string                 `json:"issue_type_id"`
+	Fields      map[string]interface{} `json:"fields"`
+
+	// Dropped are the names of the fields of the template that cannot be set
+	// on the create screen of the issue type of the project.
+	Dropped []string `json:"dropped"`
+}
+
+// rawIssue is an issue with its fields as sent by Jira, so that any of them
+// can be copied as is.
+type rawIssue struct {
+	Key    string                 `json:"key"`
+	Fields map[string]interface{} `json:"fields"`
+	Names  map[string]string      `json:"names"`
+}
+
+func isEmptyFieldValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// allowedValueID returns the ID of the allowed value of the field that a
+// value of the template matches, by ID first and then by name: the options of
+// a custom field are shared by the projects, while their components and
+// versions only match by name.
+func (f createMetaField) allowedValueID(value interface{}) (string, bool) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	id, _ := m["id"].(string)
+	name, _ := m["name"].(string)
+	if name == "" {
+		name, _ = m["value"].(string)
+	}
+	for _, allowed := range f.AllowedValues {
+		if id != "" && allowed.ID == id {
+			return allowed.ID, true
+		}
+	}
+	for _, allowed := range f.AllowedValues {
+		label := allowed.Name
+		if label == "" {
+			label = allowed.Value
+		}
+		if name != "" && strings.EqualFold(label, name) {
+			return allowed.ID, true
+		}
+	}
+	return "", false
+}
+
+// templateFieldValue returns the value of the template to set in a field of
+// the target create screen, in the form the create form uses. Only the
+// values the form can edit are copied, the others are dropped.
+func templateFieldValue(field createMetaField, value interface{}) (interface{}, bool) {
+	if len(field.AllowedValues) > 0 {
+		if field.Schema.Type != "array" {
+			id, ok := field.allowedValueID(value)
+			if !ok {
+				return nil, false
+			}
+			return map[string]interface{}{"id": id}, true
+		}
+		values, _ := value.([]interface{})
+		ids := []interface{}{}
+		for _, v := range values {
+			id, ok := field.allowedValueID(v)
+			if !ok {
+				// A partial copy would go unnoticed, the field is dropped.
+				return nil, false
+			}
+			ids = append(ids, map[string]interface{}{"id": id})
+		}
+		return ids, len(ids) > 0
+	}
+
+	switch {
+	case field.Schema.Type == "string", field.Schema.Custom == epicLinkFieldSchema:
+		s, ok := value.(string)
+		return s, ok
+	case field.Schema.Type == "array" && field.Schema.Items == "string":
+		values, ok := value.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		for _, v := range values {
+			if _, ok = v.(string); !ok {
+				return nil, false
+			}
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// copyTemplateFields copies the fields of the template that are on the create
+// screen of the issue type, and returns the names of those that were dropped.
+func copyTemplateFields(template *rawIssue, issueType *jira.MetaIssueType) (map[string]interface{}, []string, error) {
+	fields := map[string]interface{}{}
+	dropped := []string{}
+	for key, value := range template.Fields {
+		if !strings.HasPrefix(key, "customfield_") && !templateSystemFields.ContainsAny(key) {
+			continue
+		}
+		if isEmptyFieldValue(value) {
+			continue
+		}
+
+		name := template.Names[key]
+		if name == "" {
+			name = key
+		}
+		raw, ok := issueType.Fields[key]
+		if !ok {
+			dropped = append(dropped, name)
+			continue
+		}
+		bb, err := json.Marshal(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		field := createMetaField{}
+		if err = json.Unmarshal(bb, &field); err != nil {
+			return nil, nil, errors.WithMessagef(err, "failed to read the description of field %s", key)
+		}
+		copied, ok := templateFieldValue(field, value)
+		if !ok {
+			dropped = append(dropped, name)
+			continue
+		}
+		fields[key] = copied
+	}
+	sort.Strings(dropped)
+	return fields, dropped, nil
+}
+
+// GetIssueTemplate returns the fields of the template issue to create an issue
+// in the project with, of the given issue type or else of the type of the
+// template, when the project has it.
+func (p *Plugin) GetIssueTemplate(instanceID, mattermostUserID types.ID, issueKey, projectKey, issueTypeID string) (*issueTemplate, error) {
+	client, _, _, err := p.getClient(instanceID, mattermostUserID)
+	if err != nil {
+		return nil, err
+	}
+	issueKey = strings.ToUpper(issueKey)
+
+	template := &rawIssue{}
+	if err = client.RESTGet("/2/issue/"+issueKey, map[string]string{"expand": "names"}, template); err != nil {
+		return nil, errors.WithMessagef(err, "failed to get the template issue %s", issueKey)
+	}
+
+	metaInfo, err := client.GetCreateMetaInfo(p.API, &jira.GetQueryOptions{
+		Expand:      "projects.issuetypes.fields",
+		ProjectKeys: projectKey,
+	})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to get the create screen of project %s", projectKey)
+	}
+	project := metaInfo.GetProjectWithKey(projectKey)
+	if project == nil {
+		return nil, errors.Errorf("project %s was not found, or you do not have permission to create issues in it", projectKey)
+	}
+
+	var issueType *jira.MetaIssueType
+	templateType, _ := template.Fields["issuetype"].(map[string]interface{})
+	templateTypeName, _ := templateType["name"].(string)
+	for _, it := range project.IssueTypes {
+		if (issueTypeID != "" && it.Id == issueTypeID) || (issueTypeID == "" && strings.EqualFold(it.Name, templateTypeName)) {
+			issueType = it
+			break
+		}
+	}
+	if issueType == nil {
+		// Without the issue type, only its fields are unknown.
+		return &issueTemplate{IssueKey: issueKey, Fields: map[string]interface{}{}, Dropped: []string{}}, nil
+	}
+
+	fields, dropped, err := copyTemplateFields(template, issueType)
+	if err != nil {
+		return nil, err
+	}
+	return &issueTemplate{
+		IssueKey:    issueKey,
+		IssueTypeID: issueType.Id,
+		Fields:      fields,
+		Dropped:     dropped,
+	}, nil
+}
+
+func (p *Plugin) httpGetIssueTemplate(w http.ResponseWriter, r *http.Request) (int, error) {
+	mattermostUserID := r.Header.Get("Mattermost-User-Id")
+	issueKey := r.FormValue("issue_key")
+	projectKey := r.FormValue("project_key")
+	if issueKey == "" || projectKey == "" {
+		return respondErr(w, http.StatusBadRequest, errors.New("issue_key and project_key query params are required"))
+	}
+
+	template, err := p.GetIssueTemplate(types.ID(r.FormValue("instance_id")), types.ID(mattermostUserID),
+		issueKey, strings.ToUpper(projectKey), r.FormValue("issue_type_id"))
+	if err != nil {
+		return respondErr(w, http.StatusInternalServerError, err)
+	}
+	return respondJSON(w, template)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyTemplateFields(t *testing.T) {
+	template := &rawIssue{
+		Key: "OPS-7",
+		Fields: map[string]interface{}{
+			"summary":     "Rotate the certificates",
+			"description": "Follow the runbook.",
+			"issuetype":   map[string]interface{}{"id": "10001", "name": "Task"},
+			"labels":      []interface{}{"security", "recurring"},
+			"components": []interface{}{
+				map[string]interface{}{"id": "200", "name": "Backend"},
+			},
+			"priority": map[string]interface{}{"id": "2", "name": "High"},
+			"fixVersions": []interface{}{
+				map[string]interface{}{"id": "300", "name": "2.0"},
+				map[string]interface{}{"id": "301", "name": "2.1"},
+			},
+			"customfield_10100": map[string]interface{}{"id": "1", "value": "Production"},
+			"customfield_10200": "https://runbooks.example.com",
+			"customfield_10300": map[string]interface{}{"id": "9", "value": "kept"},
+			"customfield_10400": nil,
+			"environment":       "not copied",
+		},
+		Names: map[string]string{
+			"fixVersions":       "Fix Version/s",
+			"customfield_10100": "Environment type",
+			"customfield_10300": "Team",
+		},
+	}
+	issueType := &jira.MetaIssueType{Fields: map[string]interface{}{
+		"summary":     map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+		"description": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+		"labels":      map[string]interface{}{"schema": map[string]interface{}{"type": "array", "items": "string"}},
+		"components": map[string]interface{}{
+			"schema":        map[string]interface{}{"type": "array", "items": "component"},
+			"allowedValues": []interface{}{map[string]interface{}{"id": "250", "name": "backend"}},
+		},
+		"priority": map[string]interface{}{
+			"schema":        map[string]interface{}{"type": "priority"},
+			"allowedValues": []interface{}{map[string]interface{}{"id": "2", "name": "High"}},
+		},
+		"fixVersions": map[string]interface{}{
+			"schema":        map[string]interface{}{"type": "array", "items": "version"},
+			"allowedValues": []interface{}{map[string]interface{}{"id": "350", "name": "2.0"}},
+		},
+		"customfield_10100": map[string]interface{}{
+			"schema":        map[string]interface{}{"type": "option", "custom": "com.atlassian.jira.plugin.system.customfieldtypes:select"},
+			"allowedValues": []interface{}{map[string]interface{}{"id": "1", "value": "Production"}},
+		},
+		"customfield_10200": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+	}}
+
+	fields, dropped, err := copyTemplateFields(template, issueType)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"summary":           "Rotate the certificates",
+		"description":       "Follow the runbook.",
+		"labels":            []interface{}{"security", "recurring"},
+		"components":        []interface{}{map[string]interface{}{"id": "250"}},
+		"priority":          map[string]interface{}{"id": "2"},
+		"customfield_10100": map[string]interface{}{"id": "1"},
+		"customfield_10200": "https://runbooks.example.com",
+	}, fields)
+	assert.Equal(t, []string{"Fix Version/s", "Team"}, dropped,
+		"the fields that are not on the create screen, or whose values it does not allow, are dropped")
+}
@@ -19,9 +19,11 @@ import (
 	"github.com/mattermost/mattermost/server/public/pluginapi"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/trivago/tgo/tcontainer"
 
 	"github.com/mattermost/mattermost-plugin-jira/server/utils/kvstore"
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
 )
 
 const (
@@ -29,6 +31,7 @@ const (
 	noPermissionsIssueKey = "SUDO-1"
 	attachCommentErrorKey = "ATTACH-1"
 	existingIssueKey      = "REAL-1"
+	securedIssueKey       = "SECURE-1"
 	nonExistantProjectKey = "FP"
 	noIssueFoundError     = "We couldn't find the issue key. Please confirm the issue key and try again. You may not have permissions to access this issue."
 	noPermissionsError    = "You do not have the appropriate permissions to perform this action. Please contact your Jira administrator."
@@ -40,6 +43,7 @@ type testClient struct {
 	ProjectService
 	SearchService
 	IssueService
+	BoardService
 }
 
 func (client testClient) GetProject(key string) (*jira.Project, error) {
@@ -67,16 +71,34 @@ func (client testClient) DoTransition(issueKey string, transitionID string) erro
 	return nil
 }
 
+func (client testClient) UpdateAssignee(issueKey string, user *jira.User) error {
+	return nil
+}
+
 func (client testClient) GetIssue(issueKey string, options *jira.GetQueryOptions) (*jira.Issue, error) {
 	if issueKey == nonExistantIssueKey {
 		return nil, kvstore.ErrNotFound
 	}
-	return &jira.Issue{
+	issue := &jira.Issue{
 		Fields: &jira.IssueFields{
 			Reporter: &jira.User{},
 			Status:   &jira.Status{},
 		},
-	}, nil
+	}
+	if issueKey == securedIssueKey {
+		issue.Fields.Unknowns = tcontainer.MarshalMap{
+			securityLevelField: map[string]interface{}{"id": "10001", "name": "Internal"},
+		}
+	}
+	return issue, nil
+}
+
+func (client testClient) GetFields() ([]jira.Field, error) {
+	return []jira.Field{}, nil
+}
+
+func (client testClient) GetRemoteLinks(issueKey string) ([]jira.RemoteLink, error) {
+	return nil, nil
 }
 
 func (client testClient) AddComment(issueKey string, comment *jira.Comment) (*jira.Comment, error) {
@@ -89,6 +111,13 @@ func (client testClient) AddComment(issueKey string, comment *jira.Comment) (*ji
 	return nil, nil
 }
 
+func (client testClient) DeleteComment(issueKey, commentID string) error {
+	if issueKey == noPermissionsIssueKey {
+		return errors.New("you do not have the permission to delete this comment")
+	}
+	return nil
+}
+
 func (client testClient) GetCreateMetaInfo(api plugin.API, options *jira.GetQueryOptions) (*jira.CreateMetaInfo, error) {
 	return &jira.CreateMetaInfo{
 		Projects: []*jira.MetaProject{
@@ -114,6 +143,8 @@ func (client testClient) GetCreateMetaInfo(api plugin.API, options *jira.GetQuer
 func TestTransitionJiraIssue(t *testing.T) {
 	api := &plugintest.API{}
 	api.On("SendEphemeralPost", mock.AnythingOfType("string"), mock.AnythingOfType("*model.Post")).Return(&model.Post{})
+	api.On("KVSetWithOptions", mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+	api.On("KVGet", keyWithInstanceID(testInstance1.InstanceID, JiraSubscriptionsKey)).Return(nil, nil)
 	p := Plugin{}
 	p.initializeRouter()
 	p.SetAPI(api)
@@ -225,6 +256,9 @@ func TestRouteIssueTransition(t *testing.T) {
 			assert.Nil(t, err)
 
 			request := httptest.NewRequest("POST", makeAPIRoute(routeIssueTransition), strings.NewReader(string(bb)))
+			if tt.request != nil {
+				request.Header.Set("Mattermost-User-Id", tt.request.UserId)
+			}
 			w := httptest.NewRecorder()
 			p.ServeHTTP(&plugin.Context{}, w, request)
 			assert.Equal(t, tt.expectedCode, w.Result().StatusCode, "no request data")
@@ -304,6 +338,9 @@ func TestRouteShareIssuePublicly(t *testing.T) {
 			assert.Nil(t, err)
 
 			request := httptest.NewRequest("POST", makeAPIRoute(routeSharePublicly), strings.NewReader(string(bb)))
+			if tt.request != nil {
+				request.Header.Set("Mattermost-User-Id", tt.request.UserId)
+			}
 			w := httptest.NewRecorder()
 			p.ServeHTTP(&plugin.Context{}, w, request)
 			assert.Equal(t, tt.expectedCode, w.Result().StatusCode, "no request data")
@@ -311,6 +348,180 @@ func TestRouteShareIssuePublicly(t *testing.T) {
 	}
 }
 
+func TestRouteAssignToMe(t *testing.T) {
+	api := &plugintest.API{}
+	p := Plugin{}
+	p.initializeRouter()
+	api.On("SendEphemeralPost", mock.AnythingOfType("string"), mock.AnythingOfType("*model.Post")).Return(&model.Post{})
+	api.On("LogWarn", mockAnythingOfTypeBatch("string", 13)...).Return(nil)
+	api.On("LogDebug", mockAnythingOfTypeBatch("string", 11)...).Return(nil)
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.instanceStore = p.getMockInstanceStoreKV(1)
+	p.userStore = getMockUserStoreKV()
+
+	tests := map[string]struct {
+		request      *model.PostActionIntegrationRequest
+		headerUserID string
+		expectedCode int
+	}{
+		"No request data": {
+			request:      nil,
+			expectedCode: http.StatusUnauthorized,
+		},
+		"No issueKey": {
+			request: &model.PostActionIntegrationRequest{
+				UserId: "userID",
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		"Another user": {
+			request: &model.PostActionIntegrationRequest{
+				UserId: "connected_user",
+				Context: map[string]interface{}{
+					"issue_key":   "TEST-10",
+					"instance_id": testInstance1.InstanceID.String(),
+				},
+			},
+			headerUserID: "someone_else",
+			expectedCode: http.StatusUnauthorized,
+		},
+		"No instanceId": {
+			request: &model.PostActionIntegrationRequest{
+				UserId: "userID",
+				Context: map[string]interface{}{
+					"issue_key": "TEST-10",
+				},
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		"Not connected": {
+			request: &model.PostActionIntegrationRequest{
+				UserId: "not_connected_user",
+				Context: map[string]interface{}{
+					"issue_key":   "TEST-10",
+					"instance_id": testInstance1.InstanceID.String(),
+				},
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+		"Happy Path": {
+			request: &model.PostActionIntegrationRequest{
+				UserId: "connected_user",
+				Context: map[string]interface{}{
+					"issue_key":   "TEST-10",
+					"instance_id": testInstance1.InstanceID.String(),
+				},
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			bb, err := json.Marshal(tt.request)
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest("POST", makeAPIRoute(routeIssueAssignToMe), strings.NewReader(string(bb)))
+			if tt.request != nil {
+				request.Header.Set("Mattermost-User-Id", tt.request.UserId)
+			}
+			if tt.headerUserID != "" {
+				request.Header.Set("Mattermost-User-Id", tt.headerUserID)
+			}
+			w := httptest.NewRecorder()
+			p.ServeHTTP(&plugin.Context{}, w, request)
+			assert.Equal(t, tt.expectedCode, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestRouteTransitionPicker(t *testing.T) {
+	api := &plugintest.API{}
+	p := Plugin{}
+	p.initializeRouter()
+	api.On("SendEphemeralPost", mock.AnythingOfType("string"), mock.AnythingOfType("*model.Post")).Return(&model.Post{})
+	api.On("LogWarn", mockAnythingOfTypeBatch("string", 13)...).Return(nil)
+	api.On("LogDebug", mockAnythingOfTypeBatch("string", 11)...).Return(nil)
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.instanceStore = p.getMockInstanceStoreKV(1)
+	p.userStore = getMockUserStoreKV()
+
+	tests := map[string]struct {
+		request      *model.PostActionIntegrationRequest
+		headerUserID string
+		expectedCode int
+	}{
+		"No request data": {
+			request:      nil,
+			expectedCode: http.StatusUnauthorized,
+		},
+		"No issueKey": {
+			request: &model.PostActionIntegrationRequest{
+				UserId: "userID",
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		"Another user": {
+			request: &model.PostActionIntegrationRequest{
+				UserId: "connected_user",
+				Context: map[string]interface{}{
+					"issue_key":   "TEST-10",
+					"instance_id": testInstance1.InstanceID.String(),
+				},
+			},
+			headerUserID: "someone_else",
+			expectedCode: http.StatusUnauthorized,
+		},
+		"No instanceId": {
+			request: &model.PostActionIntegrationRequest{
+				UserId: "userID",
+				Context: map[string]interface{}{
+					"issue_key": "TEST-10",
+				},
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		"Not connected": {
+			request: &model.PostActionIntegrationRequest{
+				UserId: "not_connected_user",
+				Context: map[string]interface{}{
+					"issue_key":   "TEST-10",
+					"instance_id": testInstance1.GetID().String(),
+				},
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+		"Fetches the transitions": {
+			request: &model.PostActionIntegrationRequest{
+				UserId: "connected_user",
+				Context: map[string]interface{}{
+					"issue_key":   "TEST-10",
+					"instance_id": testInstance1.GetID().String(),
+				},
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			bb, err := json.Marshal(tt.request)
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest("POST", makeAPIRoute(routeIssueTransitionPicker), strings.NewReader(string(bb)))
+			if tt.request != nil {
+				request.Header.Set("Mattermost-User-Id", tt.request.UserId)
+			}
+			if tt.headerUserID != "" {
+				request.Header.Set("Mattermost-User-Id", tt.headerUserID)
+			}
+			w := httptest.NewRecorder()
+			p.ServeHTTP(&plugin.Context{}, w, request)
+			assert.Equal(t, tt.expectedCode, w.Result().StatusCode)
+		})
+	}
+}
+
 func TestRouteAttachCommentToIssue(t *testing.T) {
 	api := &plugintest.API{}
 
@@ -437,3 +648,247 @@ func TestRouteAttachCommentToIssue(t *testing.T) {
 		})
 	}
 }
+
+func TestAttachmentErrorMessage(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err      error
+		expected string
+	}{
+		"attachments disabled": {
+			err:      RESTError{errors.New(" - Attachments are disabled"), http.StatusForbidden},
+			expected: "attachments are disabled in Jira, or you do not have permission to add attachments to this issue",
+		},
+		"size limit": {
+			err:      RESTError{errors.New(" - The field file exceeds its maximum permitted size"), http.StatusRequestEntityTooLarge},
+			expected: "the file exceeds the Jira attachment size limit",
+		},
+		"plugin size limit": {
+			err:      errors.New("Maximum attachment size 10MiB exceeded, file size 12MiB"),
+			expected: "Maximum attachment size 10MiB exceeded, file size 12MiB",
+		},
+		"other error": {
+			err:      RESTError{errors.New(" - something broke"), http.StatusInternalServerError},
+			expected: "failed to attach: - something broke",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, attachmentErrorMessage(tc.err))
+		})
+	}
+}
+
+type subtaskTestClient struct {
+	testClient
+}
+
+func (client subtaskTestClient) GetCreateMetaInfo(api plugin.API, options *jira.GetQueryOptions) (*jira.CreateMetaInfo, error) {
+	return &jira.CreateMetaInfo{
+		Projects: []*jira.MetaProject{
+			{
+				Key: "TEST",
+				IssueTypes: []*jira.MetaIssueType{
+					{Id: "10001", Name: "Task"},
+					{Id: "10002", Name: "Sub-task", Subtasks: true},
+				},
+			},
+		},
+	}, nil
+}
+
+func (client subtaskTestClient) GetIssue(issueKey string, options *jira.GetQueryOptions) (*jira.Issue, error) {
+	switch issueKey {
+	case "TEST-1":
+		return &jira.Issue{Key: issueKey, Fields: &jira.IssueFields{Project: jira.Project{Key: "TEST"}}}, nil
+	case "OTHER-1":
+		return &jira.Issue{Key: issueKey, Fields: &jira.IssueFields{Project: jira.Project{Key: "OTHER"}}}, nil
+	}
+	return nil, kvstore.ErrNotFound
+}
+
+func TestValidateIssueParent(t *testing.T) {
+	p := &Plugin{}
+	for name, tc := range map[string]struct {
+		issueType     jira.IssueType
+		parentKey     string
+		expectedError string
+	}{
+		"sub-task of a parent in the same project": {
+			issueType: jira.IssueType{ID: "10002"},
+			parentKey: "test-1",
+		},
+		"sub-task type given by name": {
+			issueType: jira.IssueType{Name: "sub-task"},
+			parentKey: "TEST-1",
+		},
+		"not a sub-task type": {
+			issueType:     jira.IssueType{ID: "10001"},
+			parentKey:     "TEST-1",
+			expectedError: "a parent can only be set on a sub-task, and the \"Task\" issue type is not a sub-task type in project TEST",
+		},
+		"unknown issue type": {
+			issueType:     jira.IssueType{ID: "99999"},
+			parentKey:     "TEST-1",
+			expectedError: "issue type \"99999\" was not found in project TEST",
+		},
+		"parent in another project": {
+			issueType:     jira.IssueType{ID: "10002"},
+			parentKey:     "OTHER-1",
+			expectedError: "the parent issue OTHER-1 is not in project TEST",
+		},
+		"parent does not exist": {
+			issueType:     jira.IssueType{ID: "10002"},
+			parentKey:     "TEST-404",
+			expectedError: "failed to find the parent issue TEST-404",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			fields := &jira.IssueFields{
+				Project: jira.Project{Key: "TEST"},
+				Type:    tc.issueType,
+				Parent:  &jira.Parent{Key: tc.parentKey},
+			}
+			err := p.validateIssueParent(subtaskTestClient{}, fields)
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "TEST-1", fields.Parent.Key)
+		})
+	}
+}
+
+func TestResolveCreateAssignee(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetUserByUsername", "cloud.user").Return(&model.User{Id: "cloudUserID"}, nil)
+	api.On("GetUserByUsername", "server.user").Return(&model.User{Id: "serverUserID"}, nil)
+	api.On("GetUserByUsername", "not.connected").Return(&model.User{Id: "notConnectedUserID"}, nil)
+	api.On("GetUserByUsername", "unknown").Return(nil, &model.AppError{Message: "not found", StatusCode: http.StatusNotFound})
+
+	p := Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.userStore = mockUserStoreKV{
+		connections: map[types.ID]*Connection{
+			"cloudUserID":  {User: jira.User{AccountID: "cloud-account", Name: "ignored"}},
+			"serverUserID": {User: jira.User{Name: "server-user"}},
+		},
+	}
+
+	assignee, err := p.resolveCreateAssignee(testInstance1.InstanceID, "@cloud.user")
+	require.NoError(t, err)
+	assert.Equal(t, &jira.User{AccountID: "cloud-account"}, assignee)
+
+	assignee, err = p.resolveCreateAssignee(testInstance1.InstanceID, "server.user")
+	require.NoError(t, err)
+	assert.Equal(t, &jira.User{Name: "server-user"}, assignee)
+
+	_, err = p.resolveCreateAssignee(testInstance1.InstanceID, "@not.connected")
+	assert.EqualError(t, err, "@not.connected is not connected to "+testInstance1.InstanceID.String())
+
+	_, err = p.resolveCreateAssignee(testInstance1.InstanceID, "@unknown")
+	assert.EqualError(t, err, "@unknown was not found")
+}
+
+type reporterTestClient struct {
+	testClient
+	canModifyReporter bool
+	users             string
+	searched          map[string]string
+}
+
+func (client reporterTestClient) RESTGet(endpoint string, params map[string]string, dest interface{}) error {
+	if endpoint == "2/mypermissions" {
+		return json.Unmarshal([]byte(fmt.Sprintf(`{"permissions":{"MODIFY_REPORTER":{"havePermission":%v}}}`, client.canModifyReporter)), dest)
+	}
+	for k, v := range params {
+		client.searched[k] = v
+	}
+	return json.Unmarshal([]byte(client.users), dest)
+}
+
+func TestResolveCreateReporter(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetUserByUsername", "server.user").Return(&model.User{Id: "serverUserID"}, nil)
+
+	p := Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.userStore = mockUserStoreKV{
+		connections: map[types.ID]*Connection{
+			"serverUserID": {User: jira.User{Name: "server-user"}},
+		},
+	}
+
+	client := reporterTestClient{
+		canModifyReporter: true,
+		users:             `[{"name":"jdoe","emailAddress":"john@example.com"},{"name":"jdoe2","emailAddress":"jane@example.com"}]`,
+		searched:          map[string]string{},
+	}
+	reporter, err := p.resolveCreateReporter(testInstance1, client, "TEST", "@server.user")
+	require.NoError(t, err)
+	assert.Equal(t, &jira.User{Name: "server-user"}, reporter)
+
+	reporter, err = p.resolveCreateReporter(testInstance1, client, "TEST", "John@Example.com")
+	require.NoError(t, err)
+	assert.Equal(t, &jira.User{Name: "jdoe"}, reporter)
+	assert.Equal(t, "John@Example.com", client.searched["username"])
+
+	_, err = p.resolveCreateReporter(testInstance1, client, "TEST", "jdo")
+	assert.EqualError(t, err, "jdo matches 2 or more Jira users, please use their Jira username or email address")
+
+	client.users = `[{"accountId":"cloud-account","displayName":"Customer"}]`
+	reporter, err = p.resolveCreateReporter(testInstance1, client, "TEST", "customer")
+	require.NoError(t, err)
+	assert.Equal(t, &jira.User{AccountID: "cloud-account"}, reporter)
+
+	client.users = `[]`
+	_, err = p.resolveCreateReporter(testInstance1, client, "TEST", "nobody")
+	assert.EqualError(t, err, "nobody was not found in Jira")
+
+	client.canModifyReporter = false
+	_, err = p.resolveCreateReporter(testInstance1, client, "TEST", "@server.user")
+	assert.EqualError(t, err, "you do not have the Modify Reporter permission in TEST")
+}
+
+func TestFindExactJiraUser(t *testing.T) {
+	users := []jira.User{
+		{DisplayName: "John Doe", Name: "john", EmailAddress: "john@example.com"},
+		{DisplayName: "Johnny Smith", Name: "johnny"},
+		{DisplayName: "Contractor", AccountID: "5b10ac8d82e05b22cc7d4ef5"},
+	}
+
+	for name, tc := range map[string]struct {
+		search   string
+		expected string
+	}{
+		"username":                 {search: "john", expected: "John Doe"},
+		"username, any case":       {search: "JOHNNY", expected: "Johnny Smith"},
+		"email address":            {search: "john@example.com", expected: "John Doe"},
+		"account ID":               {search: "5b10ac8d82e05b22cc7d4ef5", expected: "Contractor"},
+		"part of a name":           {search: "joh"},
+		"display name is not used": {search: "Contractor"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			user := findExactJiraUser(users, tc.search)
+			if tc.expected == "" {
+				assert.Nil(t, user)
+				return
+			}
+			require.NotNil(t, user)
+			assert.Equal(t, tc.expected, user.DisplayName)
+		})
+	}
+
+	assert.Nil(t, findExactJiraUser(append(users, jira.User{Name: "john"}), "john"), "an ambiguous match should not pick a user")
+}
+
+func TestJiraCloudAccountIDPattern(t *testing.T) {
+	for _, accountID := range []string{"5b10ac8d82e05b22cc7d4ef5", "712020:8d2e9a4c-4b39-4e2b-9f7e-1c2b3a4d5e6f"} {
+		assert.True(t, jiraCloudAccountIDPattern.MatchString(accountID), accountID)
+	}
+	for _, search := range []string{"john", "john@example.com", "5b10ac8d82e05b22cc7d4ef", "712020:john"} {
+		assert.False(t, jiraCloudAccountIDPattern.MatchString(search), search)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	prefixIssueThreads = "issue_threads_"
+
+	// The oldest threads of an issue are forgotten once it has this many.
+	issueThreadsMax = 20
+)
+
+// issueThread is the root post of a Mattermost thread about an issue: a card
+// of the issue, or a message linking to it.
+type issueThread struct {
+	PostID    string `json:"post_id"`
+	ChannelID string `json:"channel_id"`
+}
+
+// issueThreads are the threads about an issue, across the channels, oldest
+// first.
+type issueThreads struct {
+	Threads []issueThread `json:"threads"`
+}
+
+func issueThreadsKey(instanceID types.ID, issueKey string) string {
+	return hashkey(prefixIssueThreads, instanceID.String()+"/"+strings.ToUpper(issueKey))
+}
+
+// add remembers the thread, forgetting the oldest ones beyond
+// issueThreadsMax.
+func (t *issueThreads) add(thread issueThread) {
+	for _, known := range t.Threads {
+		if known.PostID == thread.PostID {
+			return
+		}
+	}
+	t.Threads = append(t.Threads, thread)
+	if len(t.Threads) > issueThreadsMax {
+		t.Threads = t.Threads[len(t.Threads)-issueThreadsMax:]
+	}
+}
+
+func (t *issueThreads) remove(postIDs ...string) {
+	removed := NewStringSet(postIDs...)
+	kept := []issueThread{}
+	for _, thread := range t.Threads {
+		if !removed.ContainsAny(thread.PostID) {
+			kept = append(kept, thread)
+		}
+	}
+	t.Threads = kept
+}
+
+func (p *Plugin) updateIssueThreads(instanceID types.ID, issueKey string, update func(threads *issueThreads)) error {
+	return p.client.KV.SetAtomicWithRetries(issueThreadsKey(instanceID, issueKey), func(initialBytes []byte) (interface{}, error) {
+		threads := issueThreads{}
+		if len(initialBytes) != 0 {
+			if err := json.Unmarshal(initialBytes, &threads); err != nil {
+				return nil, err
+			}
+		}
+		update(&threads)
+		if len(threads.Threads) == 0 {
+			return nil, nil
+		}
+		return json.Marshal(&threads)
+	})
+}
+
+func (p *Plugin) loadIssueThreads(instanceID types.ID, issueKey string) (*issueThreads, error) {
+	threads := &issueThreads{}
+	var data []byte
+	if err := p.client.KV.Get(issueThreadsKey(instanceID, issueKey), &data); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return threads, nil
+	}
+	if err := json.Unmarshal(data, threads); err != nil {
+		return nil, err
+	}
+	return threads, nil
+}
+
+// issueThreadRootID returns the root post that the update of a subscription
+// is posted under in the channel: that of the most recent thread about the
+// issue, forgetting the deleted ones. It is empty for a new root post when the
+// channel has no thread, for the creation of the issue, or when the threads
+// are not followed.
+func (p *Plugin) issueThreadRootID(instanceID types.ID, wh *webhook, channelID string) string {
+	if !p.getConfig().DeliverUpdatesToIssueThreads || wh.Issue.Key == "" || wh.eventTypes.ContainsAny(eventCreated) {
+		return ""
+	}
+	threads, err := p.loadIssueThreads(instanceID, wh.Issue.Key)
+	if err != nil {
+		p.client.Log.Warn("Failed to load the threads of the issue", "IssueKey", wh.Issue.Key, "Error", err.Error())
+		return ""
+	}
+
+	rootID := ""
+	deleted := []string{}
+	for i := len(threads.Threads) - 1; i >= 0 && rootID == ""; i-- {
+		thread := threads.Threads[i]
+		if thread.ChannelID != channelID {
+			continue
+		}
+		post, err := p.client.Post.GetPost(thread.PostID)
+		if err != nil || post.DeleteAt > 0 {
+			deleted = append(deleted, thread.PostID)
+			continue
+		}
+		rootID = thread.PostID
+	}
+	if len(deleted) > 0 {
+		if err = p.updateIssueThreads(instanceID, wh.Issue.Key, func(threads *issueThreads) { threads.remove(deleted...) }); err != nil {
+			p.client.Log.Warn("Failed to forget the deleted threads of the issue", "IssueKey", wh.Issue.Key, "Error", err.Error())
+		}
+	}
+	return rootID
+}
+
+// issueKeyLinkRegexps are the compiled issueKeyLinkRegexp, by Jira base URL.
+var issueKeyLinkRegexps sync.Map
+
+// issueKeyLinkRegexp matches the links to the issues of a Jira instance,
+// e.g. https://jira.example.com/browse/KT-12. It is compiled once for each
+// instance.
+func issueKeyLinkRegexp(jiraBaseURL string) *regexp.Regexp {
+	if re, ok := issueKeyLinkRegexps.Load(jiraBaseURL); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(regexp.QuoteMeta(strings.TrimRight(jiraBaseURL, "/")) + `/browse/([[:alnum:]_]+-[[:digit:]]+)\b`)
+	issueKeyLinkRegexps.Store(jiraBaseURL, re)
+	return re
+}
+
+// postIssues returns the issues that the post is about, by instance: the
+// issue of its card, or the issues it links to.
+func (p *Plugin) postIssues(post *model.Post) map[types.ID][]string {
+	if instanceID, issueKey, ok := issuePostProps(post); ok {
+		return map[types.ID][]string{instanceID: {strings.ToUpper(issueKey)}}
+	}
+	if post.UserId == p.getUserID() || post.Message == "" {
+		return nil
+	}
+	instances := p.loadedInstances()
+	if instances == nil {
+		return nil
+	}
+
+	issues := map[types.ID][]string{}
+	for _, instanceID := range instances.IDs() {
+		seen := NewStringSet()
+		for _, match := range issueKeyLinkRegexp(instanceID.String()).FindAllStringSubmatch(post.Message, -1) {
+			key := strings.ToUpper(match[1])
+			if !seen.ContainsAny(key) {
+				seen = seen.Add(key)
+				issues[instanceID] = append(issues[instanceID], key)
+			}
+		}
+	}
+	return issues
+}
+
+// MessageHasBeenPosted follows the threads about the issues, so that the
+// updates of the subscriptions are posted in them.
+func (p *Plugin) MessageHasBeenPosted(c *plugin.Context, post *model.Post) {
+	if !p.getConfig().DeliverUpdatesToIssueThreads {
+		return
+	}
+	issues := p.postIssues(post)
+	if len(issues) == 0 {
+		return
+	}
+	channel, err := p.client.Channel.Get(post.ChannelId)
+	if err != nil || channel.IsGroupOrDirect() {
+		return
+	}
+
+	thread := issueThread{PostID: threadRootID(post, post.RootId), ChannelID: post.ChannelId}
+	for instanceID, issueKeys := range issues {
+		for _, issueKey := range issueKeys {
+			if err = p.updateIssueThreads(instanceID, issueKey, func(threads *issueThreads) { threads.add(thread) }); err != nil {
+				p.client.Log.Warn("Failed to follow the thread of the issue", "IssueKey", issueKey, "PostID", thread.PostID, "Error", err.Error())
+			}
+		}
+	}
+}
+
+// MessageHasBeenDeleted forgets the threads whose root post was deleted.
+func (p *Plugin) MessageHasBeenDeleted(c *plugin.Context, post *model.Post) {
+	if !p.getConfig().DeliverUpdatesToIssueThreads || post.RootId != "" {
+		return
+	}
+	for instanceID, issueKeys := range p.postIssues(post) {
+		for _, issueKey := range issueKeys {
+			if err := p.updateIssueThreads(instanceID, issueKey, func(threads *issueThreads) { threads.remove(post.Id) }); err != nil {
+				p.client.Log.Warn("Failed to forget the thread of the issue", "IssueKey", issueKey, "PostID", post.Id, "Error", err.Error())
+			}
+		}
+	}
+}
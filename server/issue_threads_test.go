@@ -0,0 +1,204 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+func issueThreadsData(t *testing.T, threads ...issueThread) []byte {
+	data, err := json.Marshal(issueThreads{Threads: threads})
+	require.NoError(t, err)
+	return data
+}
+
+func TestIssueThreadsAdd(t *testing.T) {
+	threads := &issueThreads{}
+	for i := 0; i < issueThreadsMax+2; i++ {
+		threads.add(issueThread{PostID: fmt.Sprintf("post%d", i), ChannelID: "channel1"})
+	}
+	threads.add(issueThread{PostID: "post5", ChannelID: "channel1"})
+
+	require.Len(t, threads.Threads, issueThreadsMax)
+	assert.Equal(t, "post2", threads.Threads[0].PostID, "the oldest threads are forgotten")
+	assert.Equal(t, fmt.Sprintf("post%d", issueThreadsMax+1), threads.Threads[issueThreadsMax-1].PostID, "a known thread is not added again")
+
+	threads.remove("post2", "post3", "unknown")
+	require.Len(t, threads.Threads, issueThreadsMax-2)
+	assert.Equal(t, "post4", threads.Threads[0].PostID)
+}
+
+func TestPostIssues(t *testing.T) {
+	p := &Plugin{}
+	p.instances = NewInstances(testInstance1.Common(), testInstance2.Common())
+	p.updateConfig(func(conf *config) {})
+
+	card := &model.Post{UserId: "botUserID"}
+	setIssuePostProps(card, testInstance1.InstanceID, "test-1")
+	assert.Equal(t, map[types.ID][]string{testInstance1.InstanceID: {"TEST-1"}}, p.postIssues(card))
+
+	message := &model.Post{UserId: "userID", Message: fmt.Sprintf("See %s/browse/TEST-2, %s/browse/TEST-2 and %s/browse/OTHER-3, not %s/browse/TEST-4.",
+		testInstance1.InstanceID, testInstance1.InstanceID, testInstance2.InstanceID, "https://elsewhere.example.com")}
+	assert.Equal(t, map[types.ID][]string{
+		testInstance1.InstanceID: {"TEST-2"},
+		testInstance2.InstanceID: {"OTHER-3"},
+	}, p.postIssues(message))
+
+	assert.Empty(t, p.postIssues(&model.Post{UserId: "userID", Message: "Nothing about TEST-2"}))
+}
+
+func TestMessageHasBeenPostedFollowsIssueThreads(t *testing.T) {
+	key := issueThreadsKey(testInstance1.InstanceID, "TEST-1")
+	api := &plugintest.API{}
+	api.On("GetChannel", "channel1").Return(&model.Channel{Id: "channel1", Type: model.ChannelTypeOpen}, nil)
+	api.On("GetChannel", "dm").Return(&model.Channel{Id: "dm", Type: model.ChannelTypeDirect}, nil)
+	api.On("KVGet", key).Return(issueThreadsData(t, issueThread{PostID: "root0", ChannelID: "channel1"}), nil)
+	api.On("KVSetWithOptions", key, mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.updateConfig(func(conf *config) {
+		conf.DeliverUpdatesToIssueThreads = true
+	})
+
+	reply := &model.Post{Id: "reply1", RootId: "root1", ChannelId: "channel1", UserId: "userID"}
+	setIssuePostProps(reply, testInstance1.InstanceID, "TEST-1")
+	p.MessageHasBeenPosted(nil, reply)
+	api.AssertCalled(t, "KVSetWithOptions", key, issueThreadsData(t,
+		issueThread{PostID: "root0", ChannelID: "channel1"},
+		issueThread{PostID: "root1", ChannelID: "channel1"},
+	), mock.AnythingOfType("model.PluginKVSetOptions"))
+
+	direct := &model.Post{Id: "post2", ChannelId: "dm", UserId: "userID"}
+	setIssuePostProps(direct, testInstance1.InstanceID, "TEST-1")
+	p.MessageHasBeenPosted(nil, direct)
+	api.AssertNumberOfCalls(t, "KVSetWithOptions", 1)
+}
+
+func TestIssueThreadRootID(t *testing.T) {
+	key := issueThreadsKey(testInstance1.InstanceID, "TEST-1")
+	threads := issueThreadsData(t,
+		issueThread{PostID: "root1", ChannelID: "channel1"},
+		issueThread{PostID: "root2", ChannelID: "channel2"},
+		issueThread{PostID: "root3", ChannelID: "channel1"},
+		issueThread{PostID: "deleted", ChannelID: "channel1"},
+		issueThread{PostID: "missing", ChannelID: "channel1"},
+	)
+	api := &plugintest.API{}
+	api.On("KVGet", key).Return(threads, nil)
+	api.On("KVSetWithOptions", key, mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+	api.On("GetPost", "root2").Return(&model.Post{Id: "root2"}, nil)
+	api.On("GetPost", "root3").Return(&model.Post{Id: "root3"}, nil)
+	api.On("GetPost", "deleted").Return(&model.Post{Id: "deleted", DeleteAt: 1}, nil)
+	api.On("GetPost", "missing").Return(nil, &model.AppError{Message: "not found"})
+
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.updateConfig(func(conf *config) {
+		conf.DeliverUpdatesToIssueThreads = true
+	})
+
+	wh := &webhook{
+		JiraWebhook: &JiraWebhook{Issue: jira.Issue{Key: "TEST-1"}},
+		eventTypes:  NewStringSet(eventUpdatedStatus),
+	}
+	assert.Equal(t, "root3", p.issueThreadRootID(testInstance1.InstanceID, wh, "channel1"), "the most recent thread of the channel")
+	api.AssertNotCalled(t, "GetPost", "root1")
+	api.AssertCalled(t, "KVSetWithOptions", key, issueThreadsData(t,
+		issueThread{PostID: "root1", ChannelID: "channel1"},
+		issueThread{PostID: "root2", ChannelID: "channel2"},
+		issueThread{PostID: "root3", ChannelID: "channel1"},
+	), mock.AnythingOfType("model.PluginKVSetOptions"))
+
+	assert.Equal(t, "root2", p.issueThreadRootID(testInstance1.InstanceID, wh, "channel2"))
+	assert.Equal(t, "", p.issueThreadRootID(testInstance1.InstanceID, wh, "channel3"), "a channel without threads gets a new post")
+
+	wh.eventTypes = NewStringSet(eventCreated)
+	assert.Equal(t, "", p.issueThreadRootID(testInstance1.InstanceID, wh, "channel1"), "a new issue has no threads yet")
+
+	wh.eventTypes = NewStringSet(eventUpdatedStatus)
+	p.updateConfig(func(conf *config) {
+		conf.DeliverUpdatesToIssueThreads = false
+	})
+	assert.Equal(t, "", p.issueThreadRootID(testInstance1.InstanceID, wh, "channel1"))
+}
+
+func TestIssueThreadsFanOut(t *testing.T) {
+	bb, err := os.ReadFile(filepath.Join("testdata", "webhook-issue-updated-labels.json"))
+	require.NoError(t, err)
+	parsed, err := ParseWebhook(bb)
+	require.NoError(t, err)
+	issueKey := parsed.(*webhook).Issue.Key
+
+	subscription := func(id, channelID string) ChannelSubscription {
+		return ChannelSubscription{
+			ID:        id,
+			ChannelID: channelID,
+			Filters: SubscriptionFilters{
+				Events:     NewStringSet("event_updated_any"),
+				Projects:   NewStringSet("TES"),
+				IssueTypes: NewStringSet("10001"),
+			},
+		}
+	}
+	subs, err := json.Marshal(withExistingChannelSubscriptions([]ChannelSubscription{
+		subscription("sub1", "channel1"),
+		subscription("sub2", "channel2"),
+		subscription("sub3", "channel3"),
+	}))
+	require.NoError(t, err)
+
+	api := &plugintest.API{}
+	api.On("KVGet", keyWithInstanceID(testInstance1.InstanceID, JiraSubscriptionsKey)).Return(subs, nil)
+	api.On("KVGet", issueThreadsKey(testInstance1.InstanceID, issueKey)).Return(issueThreadsData(t,
+		issueThread{PostID: "card1", ChannelID: "channel1"},
+		issueThread{PostID: "card2", ChannelID: "channel2"},
+		issueThread{PostID: "link1", ChannelID: "channel1"},
+	), nil)
+	api.On("KVGet", mock.AnythingOfType("string")).Return(nil, nil)
+	api.On("KVSetWithOptions", mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+	api.On("GetPost", mock.AnythingOfType("string")).Return(&model.Post{}, nil)
+	api.On("GetChannel", mock.AnythingOfType("string")).Return(&model.Channel{}, nil)
+	api.On("LogDebug", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	posts := []*model.Post{}
+	api.On("CreatePost", mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+		posts = append(posts, args.Get(0).(*model.Post).Clone())
+	}).Return(func(post *model.Post) *model.Post {
+		return post.Clone()
+	}, nil)
+
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.instanceStore = p.getMockInstanceStoreKV(1)
+	p.userStore = mockUserStore{}
+	p.updateConfig(func(conf *config) {
+		conf.DeliverUpdatesToIssueThreads = true
+	})
+
+	require.NoError(t, webhookWorker{1, p}.process(&webhookMessage{InstanceID: testInstance1.InstanceID, Data: bb}))
+
+	replies := []string{}
+	for _, post := range posts {
+		replies = append(replies, post.ChannelId+"/"+post.RootId)
+		assert.Equal(t, issueKey, post.GetProp(postPropIssueKey))
+	}
+	assert.ElementsMatch(t, []string{"channel1/link1", "channel2/card2", "channel3/"}, replies, "one reply per channel, in its most recent thread")
+}
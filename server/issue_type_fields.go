@@ -0,0 +1,173 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+)
+
+const (
+	issueTypeFieldsPerPage     = 25
+	issueTypeFieldsMaxAllowed  = 10
+	issueTypeFieldsPageFlagKey = "--page="
+)
+
+// createMetaField is the part of a createmeta field description that is
+// shown by `/jira issue-type-fields`.
+type createMetaField struct {
+	Required bool   `json:"required"`
+	Name     string `json:"name"`
+	Schema   struct {
+		Type   string `json:"type"`
+		Items  string `json:"items"`
+		Custom string `json:"custom"`
+	} `json:"schema"`
+	AllowedValues []struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"allowedValues"`
+}
+
+func (f createMetaField) typeName() string {
+	t := f.Schema.Type
+	if t == "array" && f.Schema.Items != "" {
+		t = "array of " + f.Schema.Items
+	}
+	if f.Schema.Custom != "" {
+		custom := f.Schema.Custom
+		if i := strings.LastIndex(custom, ":"); i >= 0 {
+			custom = custom[i+1:]
+		}
+		t += " (" + custom + ")"
+	}
+	return t
+}
+
+func (f createMetaField) allowedValues() string {
+	values := []string{}
+	for _, v := range f.AllowedValues {
+		label := v.Name
+		if label == "" {
+			label = v.Value
+		}
+		if label == "" {
+			label = v.ID
+		}
+		values = append(values, label)
+	}
+	if len(values) > issueTypeFieldsMaxAllowed {
+		more := len(values) - issueTypeFieldsMaxAllowed
+		values = append(values[:issueTypeFieldsMaxAllowed], fmt.Sprintf("and %d more", more))
+	}
+	return strings.Join(values, ", ")
+}
+
+// parseIssueTypeFieldsArgs splits `<project> <issue type> [--page=N]` into its
+// parts; the issue type may contain spaces.
+func parseIssueTypeFieldsArgs(args []string) (projectKey, issueTypeName string, page int, err error) {
+	page = 1
+	rest := []string{}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, issueTypeFieldsPageFlagKey) {
+			_, err = fmt.Sscanf(strings.TrimPrefix(arg, issueTypeFieldsPageFlagKey), "%d", &page)
+			if err != nil || page < 1 {
+				return "", "", 0, errors.Errorf("invalid page %q", strings.TrimPrefix(arg, issueTypeFieldsPageFlagKey))
+			}
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	if len(rest) < 2 {
+		return "", "", 0, errors.New("please specify a project key and an issue type")
+	}
+	return strings.ToUpper(rest[0]), strings.Join(rest[1:], " "), page, nil
+}
+
+// formatIssueTypeFields renders one page of the fields that can be set when
+// creating an issue of the given type in the given project.
+func formatIssueTypeFields(metaInfo *jira.CreateMetaInfo, projectKey, issueTypeName string, page int) (string, error) {
+	var project *jira.MetaProject
+	for _, p := range metaInfo.Projects {
+		if strings.EqualFold(p.Key, projectKey) {
+			project = p
+		}
+	}
+	if project == nil {
+		return "", errors.Errorf("project %s was not found, or you do not have permission to create issues in it", projectKey)
+	}
+
+	var issueType *jira.MetaIssueType
+	names := []string{}
+	for _, it := range project.IssueTypes {
+		names = append(names, it.Name)
+		if strings.EqualFold(it.Name, issueTypeName) || it.Id == issueTypeName {
+			issueType = it
+		}
+	}
+	if issueType == nil {
+		return "", errors.Errorf("issue type %q was not found in project %s, please use one of: %s", issueTypeName, project.Key, strings.Join(names, ", "))
+	}
+
+	type row struct {
+		id    string
+		field createMetaField
+	}
+	rows := []row{}
+	for id, raw := range issueType.Fields {
+		bb, err := json.Marshal(raw)
+		if err != nil {
+			return "", err
+		}
+		field := createMetaField{}
+		if err = json.Unmarshal(bb, &field); err != nil {
+			return "", errors.WithMessagef(err, "failed to read the description of field %s", id)
+		}
+		rows = append(rows, row{id, field})
+	}
+	if len(rows) == 0 {
+		return fmt.Sprintf("Issue type %s of project %s has no fields.", issueType.Name, project.Key), nil
+	}
+
+	// Required fields first, then by label.
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].field.Required != rows[j].field.Required {
+			return rows[i].field.Required
+		}
+		return strings.ToLower(rows[i].field.Name) < strings.ToLower(rows[j].field.Name)
+	})
+
+	pages := (len(rows) + issueTypeFieldsPerPage - 1) / issueTypeFieldsPerPage
+	if page > pages {
+		return "", errors.Errorf("there are only %d page(s) of fields", pages)
+	}
+	start := (page - 1) * issueTypeFieldsPerPage
+	end := start + issueTypeFieldsPerPage
+	if end > len(rows) {
+		end = len(rows)
+	}
+
+	msg := fmt.Sprintf("#### Fields of %s issues in project %s\n\n", issueType.Name, project.Key)
+	msg += "| Field | ID | Required | Type | Allowed values |\n|:--|:--|:--|:--|:--|\n"
+	for _, r := range rows[start:end] {
+		required := ""
+		if r.field.Required {
+			required = "Yes"
+		}
+		msg += fmt.Sprintf("| %s | `%s` | %s | %s | %s |\n", r.field.Name, r.id, required, r.field.typeName(), r.field.allowedValues())
+	}
+	if pages > 1 {
+		msg += fmt.Sprintf("\nPage %d of %d.", page, pages)
+		if page < pages {
+			msg += fmt.Sprintf(" Add `%s%d` to see the next page.", issueTypeFieldsPageFlagKey, page+1)
+		}
+	}
+	return msg, nil
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trivago/tgo/tcontainer"
+)
+
+func testIssueTypeFieldsMeta(fields tcontainer.MarshalMap) *jira.CreateMetaInfo {
+	return &jira.CreateMetaInfo{
+		Projects: []*jira.MetaProject{
+			{
+				Key: "TEST",
+				IssueTypes: []*jira.MetaIssueType{
+					{Id: "10001", Name: "Bug", Fields: fields},
+					{Id: "10002", Name: "Task"},
+				},
+			},
+		},
+	}
+}
+
+func TestParseIssueTypeFieldsArgs(t *testing.T) {
+	projectKey, issueType, page, err := parseIssueTypeFieldsArgs([]string{"test", "New", "Feature", "--page=2"})
+	require.NoError(t, err)
+	assert.Equal(t, "TEST", projectKey)
+	assert.Equal(t, "New Feature", issueType)
+	assert.Equal(t, 2, page)
+
+	_, _, page, err = parseIssueTypeFieldsArgs([]string{"TEST", "Bug"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page)
+
+	_, _, _, err = parseIssueTypeFieldsArgs([]string{"TEST"})
+	require.Error(t, err)
+
+	_, _, _, err = parseIssueTypeFieldsArgs([]string{"TEST", "Bug", "--page=0"})
+	require.Error(t, err)
+}
+
+func TestFormatIssueTypeFields(t *testing.T) {
+	meta := testIssueTypeFieldsMeta(tcontainer.MarshalMap{
+		"summary": map[string]interface{}{
+			"required": true,
+			"name":     "Summary",
+			"schema":   map[string]interface{}{"type": "string"},
+		},
+		"customfield_10010": map[string]interface{}{
+			"required": false,
+			"name":     "Environment kind",
+			"schema": map[string]interface{}{
+				"type":   "option",
+				"custom": "com.atlassian.jira.plugin.system.customfieldtypes:select",
+			},
+			"allowedValues": []interface{}{
+				map[string]interface{}{"id": "1", "value": "Staging"},
+				map[string]interface{}{"id": "2", "value": "Production"},
+			},
+		},
+		"labels": map[string]interface{}{
+			"required": false,
+			"name":     "Labels",
+			"schema":   map[string]interface{}{"type": "array", "items": "string"},
+		},
+	})
+
+	t.Run("renders the fields, required first", func(t *testing.T) {
+		msg, err := formatIssueTypeFields(meta, "test", "bug", 1)
+		require.NoError(t, err)
+		assert.Equal(t, "#### Fields of Bug issues in project TEST\n\n"+
+			"| Field | ID | Required | Type | Allowed values |\n|:--|:--|:--|:--|:--|\n"+
+			"| Summary | `summary` | Yes | string |  |\n"+
+			"| Environment kind | `customfield_10010` |  | option (select) | Staging, Production |\n"+
+			"| Labels | `labels` |  | array of string |  |\n", msg)
+	})
+
+	t.Run("unknown project and issue type", func(t *testing.T) {
+		_, err := formatIssueTypeFields(meta, "NOPE", "Bug", 1)
+		require.Error(t, err)
+
+		_, err = formatIssueTypeFields(meta, "TEST", "Epic", 1)
+		require.EqualError(t, err, "issue type \"Epic\" was not found in project TEST, please use one of: Bug, Task")
+	})
+
+	t.Run("paginates", func(t *testing.T) {
+		fields := tcontainer.MarshalMap{}
+		for i := 0; i < issueTypeFieldsPerPage+5; i++ {
+			fields[fmt.Sprintf("customfield_%d", 10000+i)] = map[string]interface{}{
+				"name":   fmt.Sprintf("Field %02d", i),
+				"schema": map[string]interface{}{"type": "string"},
+			}
+		}
+		meta := testIssueTypeFieldsMeta(fields)
+
+		msg, err := formatIssueTypeFields(meta, "TEST", "Bug", 1)
+		require.NoError(t, err)
+		assert.Contains(t, msg, "| Field 00 |")
+		assert.NotContains(t, msg, "| Field 25 |")
+		assert.Contains(t, msg, "Page 1 of 2. Add `--page=2` to see the next page.")
+
+		msg, err = formatIssueTypeFields(meta, "TEST", "Bug", 2)
+		require.NoError(t, err)
+		assert.Contains(t, msg, "| Field 29 |")
+		assert.Contains(t, msg, "Page 2 of 2.")
+
+		_, err = formatIssueTypeFields(meta, "TEST", "Bug", 3)
+		require.Error(t, err)
+	})
+
+	t.Run("truncates long lists of allowed values", func(t *testing.T) {
+		values := []interface{}{}
+		for i := 0; i < issueTypeFieldsMaxAllowed+3; i++ {
+			values = append(values, map[string]interface{}{"id": fmt.Sprintf("%d", i), "name": fmt.Sprintf("v%d", i)})
+		}
+		meta := testIssueTypeFieldsMeta(tcontainer.MarshalMap{
+			"priority": map[string]interface{}{"name": "Priority", "allowedValues": values},
+		})
+		msg, err := formatIssueTypeFields(meta, "TEST", "Bug", 1)
+		require.NoError(t, err)
+		assert.Contains(t, msg, "v9, and 3 more |")
+	})
+}
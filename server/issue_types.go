@@ -0,0 +1,112 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// issueTypesTTL is how long the issue types of a project are remembered. They
+// are changed by the Jira admins, seldom.
+const issueTypesTTL = 10 * time.Minute
+
+// projectIssueTypes are the issue types that can be created in a project.
+type projectIssueTypes struct {
+	ProjectKey  string
+	ProjectName string
+	IssueTypes  []*jira.MetaIssueType
+}
+
+// getProjectIssueTypes returns the issue types that the user can create in the
+// project, from the createmeta without the fields. They depend on the
+// permissions of the user, so they are cached for each user.
+func (p *Plugin) getProjectIssueTypes(instanceID, mattermostUserID types.ID, client Client, projectKey string) (projectIssueTypes, error) {
+	cacheKey := mattermostUserID.String() + "/" + projectKey
+	if project, ok := p.issueTypesCache.get(instanceID, cacheKey); ok {
+		return project, nil
+	}
+
+	metaInfo, err := client.GetCreateMetaInfo(p.API, &jira.GetQueryOptions{
+		Expand:      "projects.issuetypes",
+		ProjectKeys: projectKey,
+	})
+	if err != nil {
+		return projectIssueTypes{}, errors.WithMessagef(err, "failed to get the issue types of project %s", projectKey)
+	}
+	for _, metaProject := range metaInfo.Projects {
+		if !strings.EqualFold(metaProject.Key, projectKey) {
+			continue
+		}
+		project := projectIssueTypes{
+			ProjectKey:  projectKey,
+			ProjectName: metaProject.Name,
+			IssueTypes:  metaProject.IssueTypes,
+		}
+		p.issueTypesCache.set(instanceID, cacheKey, project, issueTypesTTL)
+		return project, nil
+	}
+	return projectIssueTypes{}, errors.Errorf("project %s was not found, or you do not have permission to create issues in it", projectKey)
+}
+
+// formatProjectIssueTypes renders the issue types of a project as a list, with
+// their icons, the standard types first.
+func formatProjectIssueTypes(project projectIssueTypes) string {
+	title := project.ProjectKey
+	if project.ProjectName != "" {
+		title = fmt.Sprintf("%s (%s)", project.ProjectName, project.ProjectKey)
+	}
+	msg := fmt.Sprintf("#### Issue types of project %s\n", title)
+	if len(project.IssueTypes) == 0 {
+		return msg + "You cannot create issues of any type in this project."
+	}
+
+	standard, subtasks := []string{}, []string{}
+	for _, issueType := range project.IssueTypes {
+		line := "* "
+		if issueType.IconUrl != "" {
+			line += fmt.Sprintf("![%s](%s =16x16) ", issueType.Name, issueType.IconUrl)
+		}
+		line += "**" + issueType.Name + "**"
+		if issueType.Subtasks {
+			subtasks = append(subtasks, line+" (sub-task)")
+			continue
+		}
+		standard = append(standard, line)
+	}
+	msg += strings.Join(append(standard, subtasks...), "\n")
+	if len(subtasks) > 0 {
+		msg += "\n\nSub-tasks are created with `/jira create --parent [issue-key] [text]`."
+	}
+	return msg
+}
+
+func executeIssueTypes(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	_, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	if len(args) != 1 {
+		return p.responsef(header, "Please specify a project key. Usage: `/jira issue-types <project-key>`.")
+	}
+	projectKey := strings.ToUpper(args[0])
+
+	client, _, _, err := p.getClient(instance.GetID(), types.ID(header.UserId))
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	project, err := p.getProjectIssueTypes(instance.GetID(), types.ID(header.UserId), client, projectKey)
+	if err != nil {
+		return p.responsef(header, "%v.", err)
+	}
+	return p.responsef(header, "%s", formatProjectIssueTypes(project))
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type issueTypesTestClient struct {
+	testClient
+	calls *int
+}
+
+func (client issueTypesTestClient) GetCreateMetaInfo(api plugin.API, options *jira.GetQueryOptions) (*jira.CreateMetaInfo, error) {
+	*client.calls++
+	return &jira.CreateMetaInfo{
+		Projects: []*jira.MetaProject{
+			{
+				Key:  "TEST",
+				Name: "Test project",
+				IssueTypes: []*jira.MetaIssueType{
+					{Id: "10002", Name: "Sub-task", Subtasks: true, IconUrl: "https://jiraurl1.com/subtask.svg"},
+					{Id: "10001", Name: "Bug", IconUrl: "https://jiraurl1.com/bug.svg"},
+					{Id: "10003", Name: "Task"},
+				},
+			},
+		},
+	}, nil
+}
+
+func TestGetProjectIssueTypes(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	p := &Plugin{}
+	p.issueTypesCache.now = func() time.Time { return now }
+	calls := 0
+	client := issueTypesTestClient{calls: &calls}
+
+	project, err := p.getProjectIssueTypes(testInstance1.InstanceID, "user1", client, "TEST")
+	require.NoError(t, err)
+	assert.Equal(t, "Test project", project.ProjectName)
+	assert.Len(t, project.IssueTypes, 3)
+
+	_, err = p.getProjectIssueTypes(testInstance1.InstanceID, "user1", client, "TEST")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "the issue types are cached")
+
+	_, err = p.getProjectIssueTypes(testInstance1.InstanceID, "user2", client, "TEST")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls, "the issue types are cached for each user")
+
+	now = now.Add(issueTypesTTL + time.Second)
+	_, err = p.getProjectIssueTypes(testInstance1.InstanceID, "user1", client, "TEST")
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	_, err = p.getProjectIssueTypes(testInstance1.InstanceID, "user1", client, "NOPE")
+	assert.EqualError(t, err, "project NOPE was not found, or you do not have permission to create issues in it")
+}
+
+func TestFormatProjectIssueTypes(t *testing.T) {
+	calls := 0
+	meta, err := issueTypesTestClient{calls: &calls}.GetCreateMetaInfo(nil, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "#### Issue types of project Test project (TEST)\n"+
+		"* ![Bug](https://jiraurl1.com/bug.svg =16x16) **Bug**\n"+
+		"* **Task**\n"+
+		"* ![Sub-task](https://jiraurl1.com/subtask.svg =16x16) **Sub-task** (sub-task)\n"+
+		"\nSub-tasks are created with `/jira create --parent [issue-key] [text]`.",
+		formatProjectIssueTypes(projectIssueTypes{ProjectKey: "TEST", ProjectName: "Test project", IssueTypes: meta.Projects[0].IssueTypes}))
+
+	assert.Equal(t, "#### Issue types of project TEST\nYou cannot create issues of any type in this project.",
+		formatProjectIssueTypes(projectIssueTypes{ProjectKey: "TEST"}))
+}
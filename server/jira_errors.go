@@ -0,0 +1,99 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// jiraErrorEnvelope is the standard error body of the Jira REST API: the
+// errors of the fields, by field ID, and the errors of the request.
+type jiraErrorEnvelope struct {
+	Errors        map[string]string `json:"errors,omitempty"`
+	ErrorMessages []string          `json:"errorMessages,omitempty"`
+}
+
+func (e *jiraErrorEnvelope) fieldIDs() []string {
+	ids := []string{}
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (e *jiraErrorEnvelope) Error() string {
+	message := ""
+	for _, id := range e.fieldIDs() {
+		message += fmt.Sprintf(" - %s: %s\n", id, e.Errors[id])
+	}
+	for _, m := range e.ErrorMessages {
+		message += fmt.Sprintf(" - %s\n", m)
+	}
+	return message
+}
+
+// describe renders the errors on one line, with the names of the fields,
+// e.g. `Summary: You must specify a summary of the issue.; Priority: Priority
+// name 'Foo' is not valid`.
+func (e *jiraErrorEnvelope) describe(fieldNames map[string]string) string {
+	parts := []string{}
+	for _, id := range e.fieldIDs() {
+		name := fieldNames[id]
+		if name == "" {
+			name = id
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", name, strings.TrimSpace(e.Errors[id])))
+	}
+	for _, m := range e.ErrorMessages {
+		parts = append(parts, strings.TrimSpace(m))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// isFieldRejected reports whether Jira refused the request because of the
+// value of the field, e.g. a user who cannot be assigned issues.
+func isFieldRejected(err error, fieldID string) bool {
+	var envelope *jiraErrorEnvelope
+	if StatusCode(err) != http.StatusBadRequest || !errors.As(err, &envelope) {
+		return false
+	}
+	_, ok := envelope.Errors[fieldID]
+	return ok
+}
+
+// explainJiraError logs the errors that Jira returned for a failed request,
+// in full for the admins, and returns them with the names of the fields for
+// the user. Other errors are returned as they are.
+func (p *Plugin) explainJiraError(instanceID types.ID, client Client, err error, action string, keyValuePairs ...interface{}) error {
+	var envelope *jiraErrorEnvelope
+	if !errors.As(err, &envelope) {
+		return err
+	}
+
+	body, _ := json.Marshal(envelope)
+	p.client.Log.Warn("Jira refused to "+action, append([]interface{}{
+		"instance", instanceID.String(),
+		"status", StatusCode(err),
+		"response", string(body),
+	}, keyValuePairs...)...)
+
+	fieldNames := map[string]string{}
+	if len(envelope.Errors) > 0 {
+		if fields, fieldsErr := client.GetFields(); fieldsErr == nil {
+			for _, field := range fields {
+				fieldNames[field.ID] = field.Name
+			}
+		}
+	}
+	return RESTError{errors.New(envelope.describe(fieldNames)), StatusCode(err)}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jiraErrorsTestClient struct {
+	testClient
+}
+
+func (client jiraErrorsTestClient) GetFields() ([]jira.Field, error) {
+	return []jira.Field{
+		{ID: "summary", Name: "Summary"},
+		{ID: "priority", Name: "Priority"},
+		{ID: "customfield_10020", Name: "Sprint"},
+	}, nil
+}
+
+func jiraErrorResponse(status int, contentType, body string) (*jira.Response, error) {
+	resp := &jira.Response{Response: &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{contentType}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}}
+	return resp, errors.New("request failed. Please analyze the request body for more details. Status code: 400")
+}
+
+func TestExplainJiraError(t *testing.T) {
+	for name, tc := range map[string]struct {
+		status      int
+		contentType string
+		body        string
+		expected    string
+		logged      string
+	}{
+		"field errors": {
+			status:      http.StatusBadRequest,
+			contentType: "application/json;charset=UTF-8",
+			body: `{"errorMessages":[],"errors":{"summary":"You must specify a summary of the issue.",` +
+				`"priority":"Priority name 'Foo' is not valid","customfield_10020":"Number value expected as the Sprint id."}}`,
+			expected: "Sprint: Number value expected as the Sprint id.; Priority: Priority name 'Foo' is not valid; " +
+				"Summary: You must specify a summary of the issue.",
+			logged: `{"errors":{"customfield_10020":"Number value expected as the Sprint id.","priority":"Priority name 'Foo' is not valid","summary":"You must specify a summary of the issue."}}`,
+		},
+		"workflow validator": {
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+			body:        `{"errorMessages":["The resolution must be set before closing the issue."],"errors":{}}`,
+			expected:    "The resolution must be set before closing the issue.",
+			logged:      `{"errorMessages":["The resolution must be set before closing the issue."]}`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			logged := ""
+			api.On("LogWarn", "Jira refused to transition an issue", "instance", testInstance1.InstanceID.String(), "status", tc.status,
+				"response", mock.AnythingOfType("string"), "issue", "TES-41").Run(func(args mock.Arguments) {
+				logged = args.Get(6).(string)
+			}).Return()
+			p := &Plugin{}
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+
+			err := userFriendlyJiraError(jiraErrorResponse(tc.status, tc.contentType, tc.body))
+			explained := p.explainJiraError(testInstance1.InstanceID, jiraErrorsTestClient{}, err, "transition an issue", "issue", "TES-41")
+			require.Error(t, explained)
+			assert.Equal(t, tc.expected, explained.Error())
+			assert.Equal(t, tc.status, StatusCode(explained))
+			assert.Equal(t, tc.logged, logged)
+		})
+	}
+
+	t.Run("not a Jira error envelope", func(t *testing.T) {
+		p := &Plugin{}
+		err := userFriendlyJiraError(jiraErrorResponse(http.StatusBadGateway, "text/html", "<html>Bad gateway</html>"))
+		assert.Equal(t, err, p.explainJiraError(testInstance1.InstanceID, jiraErrorsTestClient{}, err, "create an issue"))
+		assert.Equal(t, http.StatusBadGateway, StatusCode(err))
+	})
+}
+
+func TestIsFieldRejected(t *testing.T) {
+	rejected := userFriendlyJiraError(jiraErrorResponse(http.StatusBadRequest, "application/json",
+		`{"errors": {"assignee": "User 'jdoe' cannot be assigned issues."}}`))
+	assert.True(t, isFieldRejected(rejected, "assignee"))
+	assert.False(t, isFieldRejected(rejected, "reporter"))
+
+	// An error that only mentions the field in its message is not about it.
+	forbidden := userFriendlyJiraError(jiraErrorResponse(http.StatusForbidden, "application/json",
+		`{"errorMessages": ["You cannot change the assignee of this issue."]}`))
+	assert.False(t, isFieldRejected(forbidden, "assignee"))
+	assert.False(t, isFieldRejected(errors.New("assignee is not valid"), "assignee"))
+	assert.False(t, isFieldRejected(nil, "assignee"))
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// jqlTokenRegexp matches the tokens of a JQL query: quoted strings, the
+// operators, the parentheses and commas, and the words, i.e. field names,
+// keywords, values and function names.
+var jqlTokenRegexp = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|!=|!~|>=|<=|[=~<>(),]|[^\s"'=~<>!(),]+`)
+
+// jqlTokens splits a JQL query into lowercase tokens. It is a lightweight scan,
+// not a parser: a function call is the single token "name()", its arguments
+// following it, and quoted strings are kept whole, so that values such as
+// "text ~" do not look like clauses.
+func jqlTokens(jql string) []string {
+	raw := jqlTokenRegexp.FindAllString(jql, -1)
+	tokens := make([]string, 0, len(raw))
+	for i, token := range raw {
+		token = strings.ToLower(token)
+		switch {
+		case strings.HasPrefix(token, "'"):
+			token = `"` + strings.Trim(token, "'") + `"`
+		case !strings.HasPrefix(token, `"`) && i+1 < len(raw) && raw[i+1] == "(" && isJQLWord(token):
+			token += "()"
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+func isJQLWord(token string) bool {
+	switch token {
+	case "and", "or", "not", "in", "is", "was", "changed":
+		return false
+	}
+	return !strings.ContainsAny(token, "=~<>!()")
+}
+
+// parseJQLBlocklist parses the comma separated JQL tokens, functions and
+// clauses that subscriptions must not use, e.g. "text ~, issueHistory()".
+func parseJQLBlocklist(setting string) [][]string {
+	blocklist := [][]string{}
+	for _, entry := range strings.Split(setting, ",") {
+		tokens := jqlTokens(entry)
+		for len(tokens) > 0 && (tokens[len(tokens)-1] == "(" || tokens[len(tokens)-1] == ")") {
+			tokens = tokens[:len(tokens)-1]
+		}
+		if len(tokens) > 0 {
+			blocklist = append(blocklist, tokens)
+		}
+	}
+	return blocklist
+}
+
+// blockedJQL returns the first entry of the blocklist that the query uses, as
+// a run of consecutive tokens, or "" if it uses none. A word of the blocklist
+// also matches the function of that name.
+func blockedJQL(tokens []string, blocklist [][]string) string {
+	for _, entry := range blocklist {
+		for i := 0; i+len(entry) <= len(tokens); i++ {
+			match := true
+			for j := range entry {
+				if tokens[i+j] != entry[j] && tokens[i+j] != entry[j]+"()" {
+					match = false
+					break
+				}
+			}
+			if match {
+				return strings.Join(entry, " ")
+			}
+		}
+	}
+	return ""
+}
+
+// jqlScopedToProject reports whether a query is limited to some projects: it
+// must have a "project =" or "project in" clause, and no OR, outside of any
+// parentheses. A query wrapped in parentheses is not recognized, nor are
+// clauses equivalent to a project one, both err on the side of rejecting.
+func jqlScopedToProject(tokens []string) bool {
+	depth := 0
+	scoped := false
+	for i, token := range tokens {
+		switch token {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case "order":
+			if i+1 < len(tokens) && tokens[i+1] == "by" && depth == 0 {
+				return scoped
+			}
+		case "or":
+			if depth == 0 {
+				return false
+			}
+		case "project":
+			if depth == 0 && i+1 < len(tokens) && (tokens[i+1] == "=" || tokens[i+1] == "in") {
+				scoped = true
+			}
+		}
+	}
+	return scoped
+}
+
+// checkSubscriptionJQL enforces the rules of the system administrator on the
+// JQL of a subscription: it must not use the blocklisted tokens, and, unless
+// the subscription is already limited to some projects, it may be required to
+// have a project clause.
+func (p *Plugin) checkSubscriptionJQL(filters SubscriptionFilters) error {
+	if strings.TrimSpace(filters.JQL) == "" {
+		return nil
+	}
+	conf := p.getConfig()
+	tokens := jqlTokens(filters.JQL)
+
+	if blocked := blockedJQL(tokens, conf.subscriptionJQLBlocklist); blocked != "" {
+		return errors.Errorf("the JQL of subscriptions cannot use `%s`, it is blocked by your system administrator", blocked)
+	}
+	if conf.SubscriptionJQLRequireProject && filters.Projects.Len() == 0 && !jqlScopedToProject(tokens) {
+		return errors.New("the JQL of subscriptions must be limited to some projects, e.g. with `project = KT AND ...` or `project in (KT, ABC) AND ...`, as required by your system administrator")
+	}
+	return nil
+}
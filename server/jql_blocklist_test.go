@@ -0,0 +1,94 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJQLTokens(t *testing.T) {
+	assert.Equal(t, []string{"project", "=", "kt", "and", "text", "~", `"text ~ foo"`},
+		jqlTokens(`project = KT AND text~"text ~ foo"`))
+	assert.Equal(t, []string{"issuekey", "in", "(", "issuehistory()", "(", ")", ")", "and", "status", "!=", `"in progress"`},
+		jqlTokens(`issuekey in (issueHistory()) AND status != 'In Progress'`))
+	assert.Equal(t, []string{"status", "not", "in", "(", "done", ",", "closed", ")"},
+		jqlTokens(`status not in(Done, Closed)`))
+}
+
+func TestParseJQLBlocklist(t *testing.T) {
+	assert.Equal(t, [][]string{{"text", "~"}, {"issuehistory()"}, {"updatedby"}},
+		parseJQLBlocklist(" text ~ , issueHistory(), , updatedBy"))
+	assert.Empty(t, parseJQLBlocklist(""))
+}
+
+func TestBlockedJQL(t *testing.T) {
+	blocklist := parseJQLBlocklist("text ~, issueHistory(), updatedBy, \"Story Points\"")
+
+	for jql, expected := range map[string]string{
+		"project = KT AND status = Open":                     "",
+		"project = KT AND summary ~ login":                   "",
+		`project = KT AND summary ~ "text ~"`:                "",
+		"project = KT AND text ~ login":                      "text ~",
+		"TEXT~login":                                         "text ~",
+		"key in issueHistory()":                              "issuehistory()",
+		"key in issueHistory ( )":                            "issuehistory()",
+		"issuekey in updatedBy(jsmith, -1d)":                 "updatedby",
+		`project = KT AND "story points" > 3`:                `"story points"`,
+		"project = KT AND text !~ login":                     "",
+		"project = KT ORDER BY text":                         "",
+		"project = KT AND textfield ~ login":                 "",
+		"project = KT AND labels = text AND summary ~ login": "",
+	} {
+		assert.Equal(t, expected, blockedJQL(jqlTokens(jql), blocklist), jql)
+	}
+}
+
+func TestJQLScopedToProject(t *testing.T) {
+	for jql, expected := range map[string]bool{
+		"project = KT":                                   true,
+		"project in (KT, ABC) AND status = Open":         true,
+		"status = Open AND PROJECT = KT":                 true,
+		"project = KT AND (status = Open OR labels = x)": true,
+		"project = KT ORDER BY updated DESC":             true,
+		"status = Open":                                  false,
+		"text ~ login":                                   false,
+		"project = KT OR text ~ login":                   false,
+		"project != KT":                                  false,
+		"project not in (KT)":                            false,
+		"(project = KT AND status = Open)":               false,
+		"status = Open ORDER BY project":                 false,
+		`summary ~ "project = KT"`:                       false,
+	} {
+		assert.Equal(t, expected, jqlScopedToProject(jqlTokens(jql)), jql)
+	}
+}
+
+func TestCheckSubscriptionJQL(t *testing.T) {
+	p := &Plugin{}
+	p.updateConfig(func(conf *config) {
+		conf.SubscriptionJQLRequireProject = true
+		conf.subscriptionJQLBlocklist = parseJQLBlocklist("text ~")
+	})
+
+	assert.NoError(t, p.checkSubscriptionJQL(SubscriptionFilters{}))
+	assert.NoError(t, p.checkSubscriptionJQL(SubscriptionFilters{JQL: "project = KT AND status = Open"}))
+	assert.NoError(t, p.checkSubscriptionJQL(SubscriptionFilters{
+		Projects: NewStringSet("KT"),
+		JQL:      "status = Open",
+	}))
+
+	err := p.checkSubscriptionJQL(SubscriptionFilters{JQL: "project = KT AND text ~ login"})
+	assert.EqualError(t, err, "the JQL of subscriptions cannot use `text ~`, it is blocked by your system administrator")
+
+	err = p.checkSubscriptionJQL(SubscriptionFilters{JQL: "status = Open"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "must be limited to some projects")
+
+	p.updateConfig(func(conf *config) {
+		conf.SubscriptionJQLRequireProject = false
+	})
+	assert.NoError(t, p.checkSubscriptionJQL(SubscriptionFilters{JQL: "status = Open"}))
+}
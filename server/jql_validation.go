@@ -0,0 +1,52 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// jqlValidationTTL is how long the outcome of validating a JQL query against
+// Jira is remembered. It is kept short since the validity of a query may
+// change as projects, fields and permissions change in Jira.
+const jqlValidationTTL = 2 * time.Minute
+
+func normalizeJQL(jql string) string {
+	return strings.Join(strings.Fields(jql), " ")
+}
+
+// validateJQL checks that a JQL query is accepted by Jira. Both valid and
+// invalid outcomes are cached, so that repeated saves of the same query do not
+// hit Jira again; transient failures are not cached.
+func (p *Plugin) validateJQL(instanceID types.ID, client Client, jql string) error {
+	if strings.TrimSpace(jql) == "" {
+		return errors.New("please provide a JQL query")
+	}
+
+	if cached, ok := p.jqlCache.get(instanceID, normalizeJQL(jql)); ok {
+		return cached
+	}
+
+	_, err := client.SearchIssues(normalizeJQL(jql), &jira.SearchOptions{
+		MaxResults:    1,
+		Fields:        []string{"key"},
+		ValidateQuery: "strict",
+	})
+	if err != nil {
+		if StatusCode(err) != http.StatusBadRequest {
+			return errors.WithMessage(err, "failed to validate JQL query")
+		}
+		err = errors.WithMessage(err, "invalid JQL query")
+	}
+
+	p.jqlCache.set(instanceID, normalizeJQL(jql), err, jqlValidationTTL)
+	return err
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+type jqlSearchClient struct {
+	testClient
+	calls *int
+	err   error
+}
+
+func (client jqlSearchClient) SearchIssues(jql string, options *jira.SearchOptions) ([]jira.Issue, error) {
+	*client.calls++
+	return nil, client.err
+}
+
+func TestValidateJQL(t *testing.T) {
+	const instanceID = types.ID("jiraurl1")
+
+	t.Run("valid query is cached", func(t *testing.T) {
+		p := &Plugin{}
+		calls := 0
+		client := jqlSearchClient{calls: &calls}
+
+		require.NoError(t, p.validateJQL(instanceID, client, "project = TEST"))
+		require.NoError(t, p.validateJQL(instanceID, client, "  project  =   TEST "))
+		assert.Equal(t, 1, calls)
+
+		require.NoError(t, p.validateJQL("other", client, "project = TEST"))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("invalid query is cached", func(t *testing.T) {
+		p := &Plugin{}
+		calls := 0
+		client := jqlSearchClient{calls: &calls, err: RESTError{errors.New("bad field"), http.StatusBadRequest}}
+
+		require.Error(t, p.validateJQL(instanceID, client, "bogus = 1"))
+		require.Error(t, p.validateJQL(instanceID, client, "bogus = 1"))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("transient failure is not cached", func(t *testing.T) {
+		p := &Plugin{}
+		calls := 0
+		client := jqlSearchClient{calls: &calls, err: RESTError{errors.New("unavailable"), http.StatusServiceUnavailable}}
+
+		require.Error(t, p.validateJQL(instanceID, client, "project = TEST"))
+		require.Error(t, p.validateJQL(instanceID, client, "project = TEST"))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("entries expire and can be invalidated", func(t *testing.T) {
+		now := time.Now()
+		p := &Plugin{}
+		p.jqlCache.now = func() time.Time { return now }
+		calls := 0
+		client := jqlSearchClient{calls: &calls}
+
+		require.NoError(t, p.validateJQL(instanceID, client, "project = TEST"))
+		now = now.Add(jqlValidationTTL + time.Second)
+		require.NoError(t, p.validateJQL(instanceID, client, "project = TEST"))
+		assert.Equal(t, 2, calls)
+
+		p.jqlCache.invalidate(instanceID)
+		require.NoError(t, p.validateJQL(instanceID, client, "project = TEST"))
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("entries are invalidated when the instance is stored", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSetWithOptions", mock.Anything, mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+		api.On("PublishPluginClusterEvent", model.PluginClusterEvent{
+			Id:   clusterEventInstanceChanged,
+			Data: []byte(testInstance1.GetID()),
+		}, mock.AnythingOfType("model.PluginClusterEventSendOptions")).Return(nil)
+		p := &Plugin{}
+		p.SetAPI(api)
+		p.client = pluginapi.NewClient(api, p.Driver)
+		calls := 0
+		client := jqlSearchClient{calls: &calls}
+
+		require.NoError(t, p.validateJQL(testInstance1.GetID(), client, "project = TEST"))
+		require.NoError(t, NewStore(p).StoreInstance(testInstance1))
+		require.NoError(t, p.validateJQL(testInstance1.GetID(), client, "project = TEST"))
+		assert.Equal(t, 2, calls)
+		api.AssertCalled(t, "PublishPluginClusterEvent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		p := &Plugin{}
+		calls := 0
+		require.Error(t, p.validateJQL(instanceID, jqlSearchClient{calls: &calls}, "  "))
+		assert.Equal(t, 0, calls)
+	})
+}
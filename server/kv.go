@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -388,7 +389,7 @@ func (store store) EnsureRSAKey() (rsaKey *rsa.PrivateKey, returnErr error) {
 func (store store) StoreOneTimeSecret(token, secret string) error {
 	// Expire in 15 minutes
 	_, err := store.plugin.client.KV.Set(
-		hashkey(prefixOneTimeSecret, token), []byte(secret), pluginapi.SetExpiry(15*60))
+		hashkey(prefixOneTimeSecret, token), []byte(secret), pluginapi.SetExpiry(15*time.Minute))
 	if err != nil {
 		return errors.WithMessage(err, "failed to store one-ttime secret "+token)
 	}
@@ -415,7 +416,7 @@ func (store store) StoreOauth1aTemporaryCredentials(mmUserID string, credentials
 		return err
 	}
 	// Expire in 15 minutes
-	_, err = store.plugin.client.KV.Set(hashkey(prefixOneTimeSecret, mmUserID), data, pluginapi.SetExpiry(15*60))
+	_, err = store.plugin.client.KV.Set(hashkey(prefixOneTimeSecret, mmUserID), data, pluginapi.SetExpiry(15*time.Minute))
 	if err != nil {
 		return errors.WithMessage(err, "failed to store oauth temporary credentials for "+mmUserID)
 	}
@@ -440,6 +441,11 @@ func (store store) OneTimeLoadOauth1aTemporaryCredentials(mmUserID string) (*OAu
 	return &credentials, nil
 }
 
+// CreateInactiveCloudInstance stores a Jira Cloud instance waiting for its
+// installed callback, for 15 minutes. The record is only created, or
+// replaced by a new one while the instance is still waiting, never over an
+// installed instance. It fails if the record was changed meanwhile, e.g. by
+// the callback.
 func (store *store) CreateInactiveCloudInstance(jiraURL types.ID, actingUserID string) (returnErr error) {
 	ci := newCloudInstance(store.plugin, jiraURL, false,
 		fmt.Sprintf(`{"BaseURL": "%s"}`, jiraURL),
@@ -452,12 +458,31 @@ func (store *store) CreateInactiveCloudInstance(jiraURL types.ID, actingUserID s
 	}
 	ci.PluginVersion = manifest.Version
 
-	// Expire in 15 minutes
 	key := hashkey(prefixInstance, ci.GetURL())
-	_, err = store.plugin.client.KV.Set(key, data, pluginapi.SetExpiry(15*60))
+	var existing []byte
+	if err = store.plugin.client.KV.Get(key, &existing); err != nil {
+		return errors.WithMessagef(err, "failed to store new Jira Cloud instance:%s", jiraURL)
+	}
+	if existing != nil {
+		stored := struct {
+			Type      InstanceType
+			Installed bool
+		}{}
+		if err = json.Unmarshal(existing, &stored); err != nil {
+			return errors.WithMessagef(err, "failed to store new Jira Cloud instance:%s", jiraURL)
+		}
+		if stored.Type != CloudInstanceType || stored.Installed {
+			return errors.Errorf("Jira instance %s is already installed", jiraURL)
+		}
+	}
+
+	set, err := store.plugin.client.KV.Set(key, data, pluginapi.SetAtomic(existing), pluginapi.SetExpiry(15*time.Minute))
 	if err != nil {
 		return errors.WithMessagef(err, "failed to store new Jira Cloud instance:%s", jiraURL)
 	}
+	if !set {
+		return errors.Errorf("Jira instance %s was changed while it was being set up, please try again", jiraURL)
+	}
 	store.plugin.debugf("Stored: new Jira Cloud instance: %s as %s", ci.GetURL(), key)
 	return nil
 }
@@ -526,7 +551,15 @@ func (store *store) LoadInstanceFullKey(fullkey string) (Instance, error) {
 func (store *store) StoreInstance(instance Instance) error {
 	kv := kvstore.NewStore(kvstore.NewPluginStore(store.plugin.client))
 	instance.Common().PluginVersion = manifest.Version
-	return kv.Entity(prefixInstance).Store(instance.GetID(), instance)
+	err := kv.Entity(prefixInstance).Store(instance.GetID(), instance)
+	if err != nil {
+		return err
+	}
+
+	// The settings of the instance changed, e.g. its timeout or its default
+	// JQL, what was cached with the previous ones is fetched again.
+	store.plugin.instanceChanged(instance.GetID())
+	return nil
 }
 
 func (store *store) DeleteInstance(id types.ID) error {
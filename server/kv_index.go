@@ -0,0 +1,48 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// An index is a sorted list of strings stored under a key, e.g. the keys of
+// the records that a job processes, so that the job does not have to list
+// all the keys of the plugin to find them.
+
+func (p *Plugin) loadIndex(key string) ([]string, error) {
+	members := []string{}
+	if err := p.client.KV.Get(key, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// updateIndex adds the member to the index, or removes it. The index is
+// deleted once it is empty.
+func (p *Plugin) updateIndex(key, member string, add bool) error {
+	return p.client.KV.SetAtomicWithRetries(key, func(initialBytes []byte) (interface{}, error) {
+		members := []string{}
+		if len(initialBytes) > 0 {
+			if err := json.Unmarshal(initialBytes, &members); err != nil {
+				return nil, err
+			}
+		}
+		updated := []string{}
+		for _, m := range members {
+			if m != member {
+				updated = append(updated, m)
+			}
+		}
+		if add {
+			updated = append(updated, member)
+		}
+		if len(updated) == 0 {
+			return nil, nil
+		}
+		sort.Strings(updated)
+		return json.Marshal(updated)
+	})
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateIndex(t *testing.T) {
+	for name, tc := range map[string]struct {
+		initial  string
+		member   string
+		add      bool
+		expected string
+	}{
+		"add to a new index":         {member: "b", add: true, expected: `["b"]`},
+		"add in order":               {initial: `["a","c"]`, member: "b", add: true, expected: `["a","b","c"]`},
+		"add a member again":         {initial: `["a","b"]`, member: "b", add: true, expected: `["a","b"]`},
+		"remove":                     {initial: `["a","b"]`, member: "a", expected: `["b"]`},
+		"remove the last member":     {initial: `["a"]`, member: "a"},
+		"remove a member not listed": {initial: `["a"]`, member: "b", expected: `["a"]`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			var initial []byte
+			if tc.initial != "" {
+				initial = []byte(tc.initial)
+			}
+			api.On("KVGet", "index").Return(initial, nil)
+			var stored []byte
+			api.On("KVSetWithOptions", "index", mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).
+				Run(func(args mock.Arguments) { stored, _ = args.Get(1).([]byte) }).
+				Return(true, (*model.AppError)(nil))
+			p := &Plugin{}
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+
+			require.NoError(t, p.updateIndex("index", tc.member, tc.add))
+			if tc.expected == "" {
+				assert.Nil(t, stored)
+			} else {
+				assert.JSONEq(t, tc.expected, string(stored))
+			}
+		})
+	}
+}
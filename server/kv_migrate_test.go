@@ -59,6 +59,7 @@ func TestMigrateV2Instances(t *testing.T) {
 
 			api.On("LogError", mock.AnythingOfType("string")).Return(nil)
 			api.On("LogDebug", mock.AnythingOfType("string")).Return(nil)
+			api.On("PublishPluginClusterEvent", mock.AnythingOfType("model.PluginClusterEvent"), mock.AnythingOfType("model.PluginClusterEventSendOptions")).Return(nil)
 
 			api.On("KVGet", keyInstances).Return(nil, nil)
 			api.On("KVGet", v2keyKnownJiraInstances).Return([]byte(tc.known), nil)
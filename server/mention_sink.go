@@ -0,0 +1,247 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	settingNotifyChannelOnMention = "notify-channel-on-mention"
+
+	keyMentionSinks = "mention_sinks"
+
+	// mentionSinkMembersPerPage is the page size used to list the members of
+	// a channel registered without naming its accounts.
+	mentionSinkMembersPerPage = 200
+)
+
+// mentionSinkAccount is a Jira account whose mentions are posted to a
+// channel.
+type mentionSinkAccount struct {
+	MattermostUserID types.ID `json:"mattermost_user_id"`
+	AccountID        string   `json:"account_id,omitempty"`
+	Name             string   `json:"name,omitempty"`
+}
+
+// mentionSink is a channel, e.g. the triage channel of a support rotation,
+// where the events mentioning any of its accounts are posted, in addition to
+// the DMs to the mentioned users.
+type mentionSink struct {
+	ChannelID string               `json:"channel_id"`
+	CreatedBy types.ID             `json:"created_by"`
+	Accounts  []mentionSinkAccount `json:"accounts"`
+}
+
+// mentionSinks are the mention sinks of an instance, by channel.
+type mentionSinks map[string]*mentionSink
+
+func (p *Plugin) loadMentionSinks(instanceID types.ID) (mentionSinks, error) {
+	sinks := mentionSinks{}
+	if err := p.client.KV.Get(keyWithInstanceID(instanceID, keyMentionSinks), &sinks); err != nil {
+		return nil, err
+	}
+	return sinks, nil
+}
+
+// updateMentionSinks stores the change, nil to remove the sink of the channel.
+func (p *Plugin) updateMentionSinks(instanceID types.ID, channelID string, sink *mentionSink) error {
+	return p.client.KV.SetAtomicWithRetries(keyWithInstanceID(instanceID, keyMentionSinks), func(initialBytes []byte) (interface{}, error) {
+		sinks := mentionSinks{}
+		if len(initialBytes) > 0 {
+			if err := json.Unmarshal(initialBytes, &sinks); err != nil {
+				return nil, err
+			}
+		}
+		if sink == nil {
+			delete(sinks, channelID)
+		} else {
+			sinks[channelID] = sink
+		}
+		return json.Marshal(sinks)
+	})
+}
+
+// mentioned returns the accounts of the sink that the event mentions.
+func (sink *mentionSink) mentioned(wh *webhook) []mentionSinkAccount {
+	mentioned := []mentionSinkAccount{}
+	for _, account := range sink.Accounts {
+		connection := &Connection{}
+		connection.AccountID = account.AccountID
+		connection.Name = account.Name
+		if wh.mentions(connection) {
+			mentioned = append(mentioned, account)
+		}
+	}
+	return mentioned
+}
+
+// notifyMentionSinks posts the event to the channels registered for the
+// accounts it mentions, unless a subscription of the channel already posted
+// it. The comments restricted to a group or a role are not posted, since the
+// members of the channels may not be allowed to see them.
+func (p *Plugin) notifyMentionSinks(instanceID types.ID, wh *webhook, deliveries []*channelDelivery) {
+	if wh.Comment.Visibility.Value != "" {
+		return
+	}
+
+	sinks, err := p.loadMentionSinks(instanceID)
+	if err != nil {
+		p.client.Log.Warn("Failed to load the mention sinks", "InstanceID", instanceID.String(), "error", err.Error())
+		return
+	}
+	if len(sinks) == 0 {
+		return
+	}
+
+	posted := map[string]bool{}
+	for _, delivery := range deliveries {
+		posted[delivery.ChannelID] = true
+	}
+	for channelID, sink := range sinks {
+		if posted[channelID] || len(sink.mentioned(wh)) == 0 {
+			continue
+		}
+		channel, err := p.client.Channel.Get(channelID)
+		if err != nil || channel.DeleteAt > 0 {
+			continue
+		}
+		if _, _, err = wh.PostToChannel(p, instanceID, channelID, p.getUserID(), "", RenderStyleFull, "", nil); err != nil {
+			p.client.Log.Warn("Failed to post a mention to its channel", "ChannelID", channelID, "Issue", wh.Issue.Key, "error", err.Error())
+		}
+	}
+}
+
+// resolveMentionSinkAccounts returns the Jira accounts of the users, or of
+// the members of the channel when no users are named, that are connected to
+// the instance. The named users must be members of the channel. It also
+// returns the named users that are not connected.
+func (p *Plugin) resolveMentionSinkAccounts(instanceID types.ID, channelID string, usernames []string) ([]mentionSinkAccount, []string, error) {
+	userIDs := []string{}
+	notConnected := []string{}
+	if len(usernames) == 0 {
+		for page := 0; ; page++ {
+			members, err := p.client.Channel.ListMembers(channelID, page, mentionSinkMembersPerPage)
+			if err != nil {
+				return nil, nil, errors.WithMessage(err, "failed to list the members of the channel")
+			}
+			for _, member := range members {
+				userIDs = append(userIDs, member.UserId)
+			}
+			if len(members) < mentionSinkMembersPerPage {
+				break
+			}
+		}
+	}
+	for _, username := range usernames {
+		username = strings.TrimPrefix(username, "@")
+		user, err := p.client.User.GetByUsername(username)
+		if err != nil || user == nil {
+			return nil, nil, errors.Errorf("user @%s was not found", username)
+		}
+		if _, err = p.client.Channel.GetMember(channelID, user.Id); err != nil {
+			return nil, nil, errors.Errorf("user @%s is not a member of this channel", username)
+		}
+		userIDs = append(userIDs, user.Id)
+	}
+
+	accounts := []mentionSinkAccount{}
+	seen := map[string]bool{}
+	for i, userID := range userIDs {
+		if seen[userID] || userID == p.getUserID() {
+			continue
+		}
+		seen[userID] = true
+		connection, err := p.userStore.LoadConnection(instanceID, types.ID(userID))
+		if err != nil || (connection.AccountID == "" && connection.Name == "") {
+			if len(usernames) > 0 {
+				notConnected = append(notConnected, "@"+strings.TrimPrefix(usernames[i], "@"))
+			}
+			continue
+		}
+		accounts = append(accounts, mentionSinkAccount{
+			MattermostUserID: types.ID(userID),
+			AccountID:        connection.AccountID,
+			Name:             connection.Name,
+		})
+	}
+	return accounts, notConnected, nil
+}
+
+func (p *Plugin) mdMentionSinkUsers(accounts []mentionSinkAccount) string {
+	names := []string{}
+	for _, account := range accounts {
+		if user, err := p.client.User.Get(account.MattermostUserID.String()); err == nil {
+			names = append(names, "@"+user.Username)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// settingsNotifyChannelOnMention registers the channel as the mention sink of
+// its connected members, or of the named users.
+func (p *Plugin) settingsNotifyChannelOnMention(header *model.CommandArgs, instanceID types.ID, args []string) *model.CommandResponse {
+	const helpText = "`/jira settings notify-channel-on-mention [on|off] [@username...]`"
+	if len(args) == 1 {
+		sinks, err := p.loadMentionSinks(instanceID)
+		if err != nil {
+			return p.responsef(header, "Failed to load the settings of this channel. Error: %v.", err)
+		}
+		sink := sinks[header.ChannelId]
+		if sink == nil {
+			return p.responsef(header, "The Jira mentions are not posted to this channel. Use %s to turn it on.", helpText)
+		}
+		return p.responsef(header, "The Jira mentions of %s are posted to this channel.", p.mdMentionSinkUsers(sink.Accounts))
+	}
+	if args[1] != settingOn && args[1] != settingOff {
+		return p.responsef(header, "Please use %s.", helpText)
+	}
+
+	if err := p.hasPermissionToManageSubscription(instanceID, header.UserId, header.ChannelId); err != nil {
+		return p.responsef(header, "You do not have permission to manage the Jira notifications of this channel: %v.", err)
+	}
+
+	if args[1] == settingOff {
+		if len(args) > 2 {
+			return p.responsef(header, "Please use %s.", helpText)
+		}
+		if err := p.updateMentionSinks(instanceID, header.ChannelId, nil); err != nil {
+			return p.responsef(header, "Failed to update the settings of this channel. Error: %v.", err)
+		}
+		return p.responsef(header, "The Jira mentions are no longer posted to this channel.")
+	}
+
+	accounts, notConnected, err := p.resolveMentionSinkAccounts(instanceID, header.ChannelId, args[2:])
+	if err != nil {
+		return p.responsef(header, "Failed to find the Jira accounts. Error: %v.", err)
+	}
+	if len(notConnected) > 0 {
+		return p.responsef(header, "%s must connect to Jira first.", strings.Join(notConnected, ", "))
+	}
+	if len(accounts) == 0 {
+		return p.responsef(header, "No member of this channel is connected to Jira.")
+	}
+	err = p.updateMentionSinks(instanceID, header.ChannelId, &mentionSink{
+		ChannelID: header.ChannelId,
+		CreatedBy: types.ID(header.UserId),
+		Accounts:  accounts,
+	})
+	if err != nil {
+		return p.responsef(header, "Failed to update the settings of this channel. Error: %v.", err)
+	}
+	msg := fmt.Sprintf("The Jira mentions of %s are now posted to this channel.", p.mdMentionSinkUsers(accounts))
+	if len(args) == 2 {
+		msg += " Run the command again to update the accounts when the members of the channel change."
+	}
+	return p.responsef(header, "%s", msg)
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+var (
+	mentionSinkAlice = mentionSinkAccount{MattermostUserID: "alice_id", AccountID: "alice-account"}
+	mentionSinkBob   = mentionSinkAccount{MattermostUserID: "bob_id", Name: "bob"}
+)
+
+func newMentionTestWebhook(event, body string) *webhook {
+	jwh := &JiraWebhook{Issue: jira.Issue{Key: "TES-41", Fields: &jira.IssueFields{}}}
+	if event == eventCreated {
+		jwh.Issue.Fields.Description = body
+	} else {
+		jwh.Comment.Body = body
+	}
+	return &webhook{JiraWebhook: jwh, eventTypes: NewStringSet(event), headline: "Test User mentioned someone"}
+}
+
+func TestMentionSinkMentioned(t *testing.T) {
+	sink := &mentionSink{ChannelID: "triage", Accounts: []mentionSinkAccount{mentionSinkAlice, mentionSinkBob}}
+	for name, tc := range map[string]struct {
+		wh       *webhook
+		expected []mentionSinkAccount
+	}{
+		"comment mentioning an account": {
+			wh:       newMentionTestWebhook(eventCreatedComment, "[~accountid:alice-account] can you have a look?"),
+			expected: []mentionSinkAccount{mentionSinkAlice},
+		},
+		"description mentioning an account by name": {
+			wh:       newMentionTestWebhook(eventCreated, "Reported by a customer, cc [~Bob]"),
+			expected: []mentionSinkAccount{mentionSinkBob},
+		},
+		"comment mentioning both": {
+			wh:       newMentionTestWebhook(eventCreatedComment, "[~bob] and [~accountid:alice-account]"),
+			expected: []mentionSinkAccount{mentionSinkAlice, mentionSinkBob},
+		},
+		"comment mentioning someone else": {
+			wh:       newMentionTestWebhook(eventCreatedComment, "[~accountid:carol-account] [~bobby] alice-account"),
+			expected: []mentionSinkAccount{},
+		},
+		"event without a body": {
+			wh:       newMentionTestWebhook(eventUpdatedStatus, "[~accountid:alice-account]"),
+			expected: []mentionSinkAccount{},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, sink.mentioned(tc.wh))
+		})
+	}
+}
+
+func setupMentionSinkTest(t *testing.T, sinks mentionSinks) (*Plugin, *plugintest.API) {
+	api := &plugintest.API{}
+	p := &Plugin{}
+	p.updateConfig(func(conf *config) {
+		conf.botUserID = "bot_id"
+	})
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.userStore = mockUserStoreKV{connections: map[types.ID]*Connection{
+		"admin_id": {User: jira.User{AccountID: "admin-account"}},
+		"alice_id": {User: jira.User{AccountID: "alice-account"}},
+		"bob_id":   {User: jira.User{Name: "bob"}},
+	}}
+
+	var data []byte
+	if sinks != nil {
+		var err error
+		data, err = json.Marshal(sinks)
+		require.NoError(t, err)
+	}
+	api.On("KVGet", keyWithInstanceID(testInstance1.InstanceID, keyMentionSinks)).Return(data, nil)
+	return p, api
+}
+
+func TestNotifyMentionSinks(t *testing.T) {
+	p, api := setupMentionSinkTest(t, mentionSinks{
+		"triage":     {ChannelID: "triage", Accounts: []mentionSinkAccount{mentionSinkAlice}},
+		"subscribed": {ChannelID: "subscribed", Accounts: []mentionSinkAccount{mentionSinkAlice}},
+		"other":      {ChannelID: "other", Accounts: []mentionSinkAccount{mentionSinkBob}},
+		"archived":   {ChannelID: "archived", Accounts: []mentionSinkAccount{mentionSinkAlice}},
+	})
+	api.On("GetChannel", "triage").Return(&model.Channel{Id: "triage"}, nil)
+	api.On("GetChannel", "archived").Return(&model.Channel{Id: "archived", DeleteAt: 1}, nil)
+	posts := []*model.Post{}
+	api.On("CreatePost", mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+		posts = append(posts, args.Get(0).(*model.Post))
+	}).Return(func(post *model.Post) *model.Post { return post.Clone() }, nil)
+
+	wh := newMentionTestWebhook(eventCreatedComment, "[~accountid:alice-account] can you have a look?")
+	p.notifyMentionSinks(testInstance1.InstanceID, wh, []*channelDelivery{{ChannelID: "subscribed"}})
+
+	require.Len(t, posts, 1, "the channels whose subscriptions posted the event, and the other accounts, are skipped")
+	assert.Equal(t, "triage", posts[0].ChannelId)
+	assert.Equal(t, "bot_id", posts[0].UserId)
+	assert.Equal(t, "Test User mentioned someone", posts[0].Message)
+
+	posts = posts[:0]
+	p.notifyMentionSinks(testInstance1.InstanceID, newMentionTestWebhook(eventCreatedComment, "nobody"), nil)
+	assert.Empty(t, posts)
+
+	restricted := newMentionTestWebhook(eventCreatedComment, "[~accountid:alice-account] can you have a look?")
+	restricted.Comment.Visibility = jira.CommentVisibility{Type: "role", Value: "Developers"}
+	p.notifyMentionSinks(testInstance1.InstanceID, restricted, nil)
+	assert.Empty(t, posts, "the restricted comments are not posted")
+}
+
+func TestSettingsNotifyChannelOnMention(t *testing.T) {
+	header := &model.CommandArgs{UserId: "admin_id", ChannelId: "triage"}
+	setup := func(t *testing.T, sinks mentionSinks) (*Plugin, *plugintest.API, *[]string) {
+		p, api := setupMentionSinkTest(t, sinks)
+		p.instanceStore = p.getMockInstanceStoreKV(1)
+		api.On("HasPermissionTo", "admin_id", model.PermissionManageSystem).Return(true)
+		api.On("GetUserByUsername", "alice").Return(&model.User{Id: "alice_id", Username: "alice"}, nil)
+		api.On("GetUserByUsername", "carol").Return(&model.User{Id: "carol_id", Username: "carol"}, nil)
+		api.On("GetUserByUsername", "dave").Return(&model.User{Id: "dave_id", Username: "dave"}, nil)
+		api.On("GetChannelMember", "triage", "alice_id").Return(&model.ChannelMember{}, nil)
+		api.On("GetChannelMember", "triage", "carol_id").Return(&model.ChannelMember{}, nil)
+		api.On("GetChannelMember", "triage", "dave_id").Return(nil, &model.AppError{Message: "not found"})
+		api.On("GetUser", "alice_id").Return(&model.User{Id: "alice_id", Username: "alice"}, nil)
+		api.On("GetUser", "bob_id").Return(&model.User{Id: "bob_id", Username: "bob"}, nil)
+		replies := []string{}
+		api.On("SendEphemeralPost", "admin_id", mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+			replies = append(replies, args.Get(1).(*model.Post).Message)
+		}).Return(func(_ string, post *model.Post) *model.Post { return post.Clone() })
+		return p, api, &replies
+	}
+	stored := func(api *plugintest.API) mentionSinks {
+		for _, call := range api.Calls {
+			if call.Method == "KVSetWithOptions" {
+				sinks := mentionSinks{}
+				_ = json.Unmarshal(call.Arguments.Get(1).([]byte), &sinks)
+				return sinks
+			}
+		}
+		return nil
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		p, _, replies := setup(t, nil)
+		p.settingsNotifyChannelOnMention(header, testInstance1.InstanceID, []string{settingNotifyChannelOnMention})
+		assert.Equal(t, []string{"The Jira mentions are not posted to this channel. Use `/jira settings notify-channel-on-mention [on|off] [@username...]` to turn it on."}, *replies)
+	})
+
+	t.Run("on for the connected members of the channel", func(t *testing.T) {
+		p, api, replies := setup(t, nil)
+		api.On("GetChannelMembers", "triage", 0, mentionSinkMembersPerPage).Return(model.ChannelMembers{
+			{UserId: "alice_id"}, {UserId: "bob_id"}, {UserId: "carol_id"}, {UserId: "bot_id"},
+		}, nil)
+		api.On("KVSetWithOptions", keyWithInstanceID(testInstance1.InstanceID, keyMentionSinks), mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+
+		p.settingsNotifyChannelOnMention(header, testInstance1.InstanceID, []string{settingNotifyChannelOnMention, settingOn})
+		assert.Equal(t, []string{"The Jira mentions of @alice, @bob are now posted to this channel. " +
+			"Run the command again to update the accounts when the members of the channel change."}, *replies)
+		assert.Equal(t, mentionSinks{"triage": {
+			ChannelID: "triage",
+			CreatedBy: "admin_id",
+			Accounts:  []mentionSinkAccount{mentionSinkAlice, mentionSinkBob},
+		}}, stored(api))
+	})
+
+	t.Run("on for users that are not connected", func(t *testing.T) {
+		p, api, replies := setup(t, nil)
+		p.settingsNotifyChannelOnMention(header, testInstance1.InstanceID, []string{settingNotifyChannelOnMention, settingOn, "@alice", "@carol"})
+		assert.Equal(t, []string{"@carol must connect to Jira first."}, *replies)
+		api.AssertNotCalled(t, "KVSetWithOptions", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("on for users that are not members of the channel", func(t *testing.T) {
+		p, api, replies := setup(t, nil)
+		p.settingsNotifyChannelOnMention(header, testInstance1.InstanceID, []string{settingNotifyChannelOnMention, settingOn, "@alice", "@dave"})
+		assert.Equal(t, []string{"Failed to find the Jira accounts. Error: user @dave is not a member of this channel."}, *replies)
+		api.AssertNotCalled(t, "KVSetWithOptions", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("off", func(t *testing.T) {
+		p, api, replies := setup(t, mentionSinks{"triage": {ChannelID: "triage", Accounts: []mentionSinkAccount{mentionSinkAlice}}})
+		api.On("KVSetWithOptions", keyWithInstanceID(testInstance1.InstanceID, keyMentionSinks), mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+		p.settingsNotifyChannelOnMention(header, testInstance1.InstanceID, []string{settingNotifyChannelOnMention})
+		p.settingsNotifyChannelOnMention(header, testInstance1.InstanceID, []string{settingNotifyChannelOnMention, settingOff})
+		assert.Equal(t, []string{"The Jira mentions of @alice are posted to this channel.", "The Jira mentions are no longer posted to this channel."}, *replies)
+		assert.Equal(t, mentionSinks{}, stored(api))
+	})
+
+	t.Run("not allowed to manage the subscriptions of the channel", func(t *testing.T) {
+		p, api, replies := setup(t, nil)
+		p.updateConfig(func(conf *config) {
+			conf.RolesAllowedToEditJiraSubscriptions = "channel_admin"
+		})
+		header := &model.CommandArgs{UserId: "admin_id", ChannelId: "other"}
+		api.On("GetChannel", "other").Return(&model.Channel{Id: "other", Type: model.ChannelTypeOpen}, nil)
+		api.On("HasPermissionToChannel", "admin_id", "other", model.PermissionManagePublicChannelProperties).Return(false)
+		p.settingsNotifyChannelOnMention(header, testInstance1.InstanceID, []string{settingNotifyChannelOnMention, settingOn})
+		assert.Equal(t, []string{"You do not have permission to manage the Jira notifications of this channel: is not channel admin."}, *replies)
+	})
+}
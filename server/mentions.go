@@ -0,0 +1,100 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// mattermostMentionRegexp matches @username mentions, using the characters
+// Mattermost allows in usernames. Email addresses are not matched since the
+// @ must not follow a word character.
+var mattermostMentionRegexp = regexp.MustCompile(`\B@([a-zA-Z0-9.\-_]+)`)
+
+// jiraMention returns the Jira wiki markup mentioning the Jira user of the
+// connection. Jira Cloud identifies users by account ID, Jira Server by
+// username.
+func jiraMention(connection *Connection) string {
+	if connection.AccountID != "" {
+		return "[~accountid:" + connection.AccountID + "]"
+	}
+	if connection.Name != "" {
+		return "[~" + connection.Name + "]"
+	}
+	return ""
+}
+
+// mapMentions replaces the @mentions of Mattermost users that are connected to
+// the instance with mentions of their Jira users. Mentions of users that are
+// not found, or not connected, are left as they are.
+func (p *Plugin) mapMentions(instanceID types.ID, text string) string {
+	resolved := map[string]string{}
+	return mattermostMentionRegexp.ReplaceAllStringFunc(text, func(mention string) string {
+		// A mention at the end of a sentence is followed by a period, which
+		// Mattermost does not treat as a part of the username either.
+		username := strings.TrimRight(mention[1:], ".")
+		trailing := mention[1+len(username):]
+
+		mapped, ok := resolved[username]
+		if !ok {
+			mapped = p.jiraMentionForUsername(instanceID, username)
+			resolved[username] = mapped
+		}
+		if mapped == "" {
+			return mention
+		}
+		return mapped + trailing
+	})
+}
+
+func (p *Plugin) jiraMentionForUsername(instanceID types.ID, username string) string {
+	if username == "" {
+		return ""
+	}
+	mmUser, err := p.client.User.GetByUsername(username)
+	if err != nil || mmUser == nil {
+		return ""
+	}
+	connection, err := p.userStore.LoadConnection(instanceID, types.ID(mmUser.Id))
+	if err != nil {
+		return ""
+	}
+	return jiraMention(connection)
+}
+
+// mentionsJiraUser reports whether Jira wiki markup mentions the Jira user of
+// the connection, by account ID on Jira Cloud or by username on Jira Server.
+func mentionsJiraUser(text string, connection *Connection) bool {
+	for _, u := range parseJIRAUsernamesFromText(text) {
+		if strings.HasPrefix(u, "accountid:") {
+			if connection.AccountID != "" && u[len("accountid:"):] == connection.AccountID {
+				return true
+			}
+			continue
+		}
+		if connection.Name != "" && strings.EqualFold(u, connection.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// mentions reports whether the body of the event mentions the Jira user of
+// the connection: the comment for comment events, and the description of the
+// issue when it is created or its description is edited. Other events have
+// no body to mention anyone in.
+func (wh *webhook) mentions(connection *Connection) bool {
+	events := wh.Events()
+	switch {
+	case events.Intersection(commentEvents).Len() > 0:
+		return mentionsJiraUser(wh.JiraWebhook.Comment.Body, connection)
+	case events.ContainsAny(eventCreated, eventUpdatedDescription):
+		return wh.JiraWebhook.Issue.Fields != nil && mentionsJiraUser(wh.JiraWebhook.Issue.Fields.Description, connection)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+func TestMapMentions(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetUserByUsername", "cloud.user").Return(&model.User{Id: "cloud_user_id"}, nil)
+	api.On("GetUserByUsername", "server_user").Return(&model.User{Id: "server_user_id"}, nil)
+	api.On("GetUserByUsername", "not-connected").Return(&model.User{Id: "not_connected_id"}, nil)
+	api.On("GetUserByUsername", mock.AnythingOfType("string")).Return(nil, &model.AppError{Message: "not found"})
+
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.userStore = mockUserStoreKV{
+		connections: map[types.ID]*Connection{
+			"cloud_user_id":  {User: jira.User{AccountID: "5b10ac8d82e05b22cc7d4ef5"}},
+			"server_user_id": {User: jira.User{Name: "jdoe"}},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		text     string
+		expected string
+	}{
+		"no mentions": {
+			text:     "A plain description",
+			expected: "A plain description",
+		},
+		"Cloud user": {
+			text:     "Reported by @cloud.user",
+			expected: "Reported by [~accountid:5b10ac8d82e05b22cc7d4ef5]",
+		},
+		"Server user, at the end of a sentence": {
+			text:     "Ask @server_user.",
+			expected: "Ask [~jdoe].",
+		},
+		"unmapped mentions are left as they are": {
+			text:     "cc @not-connected and @unknown",
+			expected: "cc @not-connected and @unknown",
+		},
+		"mapped and unmapped": {
+			text:     "@cloud.user and @unknown, then @cloud.user again",
+			expected: "[~accountid:5b10ac8d82e05b22cc7d4ef5] and @unknown, then [~accountid:5b10ac8d82e05b22cc7d4ef5] again",
+		},
+		"email addresses are not mentions": {
+			text:     "Write to support@cloud.user",
+			expected: "Write to support@cloud.user",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, p.mapMentions(testInstance1.InstanceID, tc.text))
+		})
+	}
+}
+
+func TestWebhookMentions(t *testing.T) {
+	cloudUser := &Connection{User: jira.User{AccountID: "5f1a2b3c"}}
+	serverUser := &Connection{User: jira.User{Name: "jdoe"}}
+	makeWebhook := func(event, comment, description string) *webhook {
+		return &webhook{
+			JiraWebhook: &JiraWebhook{
+				Comment: jira.Comment{Body: comment},
+				Issue:   jira.Issue{Fields: &jira.IssueFields{Description: description}},
+			},
+			eventTypes: NewStringSet(event),
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		webhook    *webhook
+		connection *Connection
+		expected   bool
+	}{
+		"comment mentions the account ID": {
+			webhook:    makeWebhook(eventCreatedComment, "Can you look, [~accountid:5f1a2b3c]?", ""),
+			connection: cloudUser,
+			expected:   true,
+		},
+		"comment mentions someone else": {
+			webhook:    makeWebhook(eventCreatedComment, "Can you look, [~accountid:deadbeef]?", ""),
+			connection: cloudUser,
+		},
+		"comment mentions the username": {
+			webhook:    makeWebhook(eventUpdatedComment, "Thanks [~JDoe]", ""),
+			connection: serverUser,
+			expected:   true,
+		},
+		"username is not an account ID": {
+			webhook:    makeWebhook(eventCreatedComment, "Thanks [~accountid:jdoe]", ""),
+			connection: serverUser,
+		},
+		"a comment event ignores the description": {
+			webhook:    makeWebhook(eventCreatedComment, "No mentions", "Owner: [~jdoe]"),
+			connection: serverUser,
+		},
+		"description of a new issue": {
+			webhook:    makeWebhook(eventCreated, "", "Owner: [~accountid:5f1a2b3c]"),
+			connection: cloudUser,
+			expected:   true,
+		},
+		"edited description": {
+			webhook:    makeWebhook(eventUpdatedDescription, "", "Owner: [~jdoe]"),
+			connection: serverUser,
+			expected:   true,
+		},
+		"other events have no body": {
+			webhook:    makeWebhook(eventUpdatedStatus, "", "Owner: [~jdoe]"),
+			connection: serverUser,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.webhook.mentions(tc.connection))
+		})
+	}
+}
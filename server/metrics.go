@@ -0,0 +1,226 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+// jiraAPIDurationBuckets are the upper bounds, in seconds, of the histogram
+// of the duration of the Jira API calls.
+var jiraAPIDurationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// pluginMetrics counts the activity of the plugin on this server since it
+// was activated. Its zero value is ready to use.
+type pluginMetrics struct {
+	lock sync.Mutex
+
+	startedAt          time.Time
+	commands           map[string]int64
+	jiraAPICalls       map[string]int64
+	jiraAPIDurations   []int64
+	jiraAPIDurationSum time.Duration
+	webhookEvents      map[string]int64
+	notificationErrors int64
+
+	// the webhook events posted, or not, to a subscribed channel
+	webhookDeliveries       int64
+	webhookDeliveryFailures int64
+}
+
+type metricsHistogramBucket struct {
+	LessOrEqual string `json:"le"`
+	Count       int64  `json:"count"`
+}
+
+type metricsHistogram struct {
+	Buckets []metricsHistogramBucket `json:"buckets"`
+	Count   int64                    `json:"count"`
+	Sum     float64                  `json:"sum"`
+}
+
+// metricsSnapshot is the JSON export of the metrics.
+type metricsSnapshot struct {
+	StartedAt               time.Time        `json:"started_at"`
+	ExportedAt              time.Time        `json:"exported_at"`
+	Commands                map[string]int64 `json:"commands"`
+	JiraAPICalls            map[string]int64 `json:"jira_api_calls"`
+	JiraAPIDurationSeconds  metricsHistogram `json:"jira_api_call_duration_seconds"`
+	WebhookEvents           map[string]int64 `json:"webhook_events"`
+	WebhookDeliveries       int64            `json:"webhook_deliveries"`
+	WebhookDeliveryFailures int64            `json:"webhook_delivery_failures"`
+	NotificationErrors      int64            `json:"notification_errors"`
+}
+
+func (m *pluginMetrics) start(now time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.startedAt = now
+}
+
+func increment(counters *map[string]int64, key string) {
+	if *counters == nil {
+		*counters = map[string]int64{}
+	}
+	(*counters)[key]++
+}
+
+// countCommand counts a run of the command, by its subcommand path e.g.
+// "subscribe list".
+func (m *pluginMetrics) countCommand(command string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	increment(&m.commands, command)
+}
+
+// countJiraAPICall counts a call to the Jira API by the status code of its
+// response, or "error" when none was received.
+func (m *pluginMetrics) countJiraAPICall(status string, duration time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	increment(&m.jiraAPICalls, status)
+
+	if m.jiraAPIDurations == nil {
+		m.jiraAPIDurations = make([]int64, len(jiraAPIDurationBuckets)+1)
+	}
+	i := 0
+	for i < len(jiraAPIDurationBuckets) && duration.Seconds() > jiraAPIDurationBuckets[i] {
+		i++
+	}
+	m.jiraAPIDurations[i]++
+	m.jiraAPIDurationSum += duration
+}
+
+func (m *pluginMetrics) countWebhookEvent(event string) {
+	if event == "" {
+		event = "unknown"
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	increment(&m.webhookEvents, event)
+}
+
+// countWebhookDelivery counts a webhook event posted to a subscribed channel.
+func (m *pluginMetrics) countWebhookDelivery() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.webhookDeliveries++
+}
+
+// countWebhookDeliveryFailure counts a webhook event that could not be posted
+// to a subscribed channel.
+func (m *pluginMetrics) countWebhookDeliveryFailure() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.webhookDeliveryFailures++
+}
+
+// countNotificationError counts a user notification that could not be sent.
+func (m *pluginMetrics) countNotificationError() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.notificationErrors++
+}
+
+func copyCounters(counters map[string]int64) map[string]int64 {
+	c := map[string]int64{}
+	for k, v := range counters {
+		c[k] = v
+	}
+	return c
+}
+
+func (m *pluginMetrics) snapshot() *metricsSnapshot {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	histogram := metricsHistogram{
+		Buckets: []metricsHistogramBucket{},
+		Sum:     m.jiraAPIDurationSum.Seconds(),
+	}
+	for i := 0; i <= len(jiraAPIDurationBuckets); i++ {
+		if i < len(m.jiraAPIDurations) {
+			histogram.Count += m.jiraAPIDurations[i]
+		}
+		le := "+Inf"
+		if i < len(jiraAPIDurationBuckets) {
+			le = strconv.FormatFloat(jiraAPIDurationBuckets[i], 'f', -1, 64)
+		}
+		// The buckets are cumulative, as in the Prometheus histograms.
+		histogram.Buckets = append(histogram.Buckets, metricsHistogramBucket{LessOrEqual: le, Count: histogram.Count})
+	}
+
+	return &metricsSnapshot{
+		StartedAt:               m.startedAt,
+		Commands:                copyCounters(m.commands),
+		JiraAPICalls:            copyCounters(m.jiraAPICalls),
+		JiraAPIDurationSeconds:  histogram,
+		WebhookEvents:           copyCounters(m.webhookEvents),
+		WebhookDeliveries:       m.webhookDeliveries,
+		WebhookDeliveryFailures: m.webhookDeliveryFailures,
+		NotificationErrors:      m.notificationErrors,
+	}
+}
+
+// metricsTransport counts the requests of an HTTP client to Jira.
+type metricsTransport struct {
+	metrics *pluginMetrics
+	http.RoundTripper
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.RoundTripper.RoundTrip(req)
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	t.metrics.countJiraAPICall(status, time.Since(start))
+	return resp, err
+}
+
+// withMetrics returns a copy of the HTTP client that counts its requests.
+func (m *pluginMetrics) withMetrics(c *http.Client) *http.Client {
+	client := *c
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	client.Transport = &metricsTransport{metrics: m, RoundTripper: next}
+	return &client
+}
+
+func (p *Plugin) exportMetrics(now time.Time) ([]byte, error) {
+	snapshot := p.metrics.snapshot()
+	snapshot.ExportedAt = now
+	return json.MarshalIndent(snapshot, "", "  ")
+}
+
+func executeAdminExportMetrics(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	authorized, err := authorizedSysAdmin(p, header.UserId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if !authorized {
+		return p.responsef(header, "`/jira admin export-metrics` can only be run by a system administrator.")
+	}
+	if len(args) != 0 {
+		return p.help(header)
+	}
+
+	data, err := p.exportMetrics(time.Now().UTC())
+	if err != nil {
+		return p.responsef(header, "Failed to export the metrics. Error: %v.", err)
+	}
+	return p.responsef(header, "%s", "The counters since the plugin started on this server:\n```json\n"+
+		strings.TrimSpace(string(data))+"\n```")
+}
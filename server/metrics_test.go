@@ -0,0 +1,120 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsSnapshot(t *testing.T) {
+	m := &pluginMetrics{}
+	snapshot := m.snapshot()
+	assert.Empty(t, snapshot.Commands)
+	assert.Equal(t, int64(0), snapshot.JiraAPIDurationSeconds.Count)
+	require.Len(t, snapshot.JiraAPIDurationSeconds.Buckets, len(jiraAPIDurationBuckets)+1)
+
+	m.countCommand("subscribe list")
+	m.countCommand("subscribe list")
+	m.countCommand("view")
+	m.countJiraAPICall("200", 50*time.Millisecond)
+	m.countJiraAPICall("200", 700*time.Millisecond)
+	m.countJiraAPICall("error", 20*time.Second)
+	m.countWebhookEvent("jira:issue_updated")
+	m.countWebhookEvent("")
+	m.countNotificationError()
+
+	snapshot = m.snapshot()
+	assert.Equal(t, map[string]int64{"subscribe list": 2, "view": 1}, snapshot.Commands)
+	assert.Equal(t, map[string]int64{"200": 2, "error": 1}, snapshot.JiraAPICalls)
+	assert.Equal(t, map[string]int64{"jira:issue_updated": 1, "unknown": 1}, snapshot.WebhookEvents)
+	assert.Equal(t, int64(1), snapshot.NotificationErrors)
+
+	histogram := snapshot.JiraAPIDurationSeconds
+	assert.Equal(t, int64(3), histogram.Count)
+	assert.InDelta(t, 20.75, histogram.Sum, 0.001)
+	assert.Equal(t, []metricsHistogramBucket{
+		{"0.1", 1}, {"0.25", 1}, {"0.5", 1}, {"1", 2}, {"2.5", 2}, {"5", 2}, {"10", 2}, {"+Inf", 3},
+	}, histogram.Buckets)
+
+	snapshot.Commands["view"] = 10
+	assert.Equal(t, int64(1), m.snapshot().Commands["view"], "the snapshot is a copy")
+}
+
+func TestMetricsTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	m := &pluginMetrics{}
+	client := m.withMetrics(&http.Client{})
+	for _, path := range []string{"/", "/missing", "/"} {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	_, err := client.Get("http://127.0.0.1:0")
+	require.Error(t, err)
+
+	assert.Equal(t, map[string]int64{"200": 2, "404": 1, "error": 1}, m.snapshot().JiraAPICalls)
+}
+
+func TestExecuteAdminExportMetrics(t *testing.T) {
+	setup := func(roles string) (*Plugin, *[]string) {
+		api := &plugintest.API{}
+		p := &Plugin{}
+		p.SetAPI(api)
+		p.client = pluginapi.NewClient(api, p.Driver)
+		api.On("GetUser", "user_id").Return(&model.User{Id: "user_id", Roles: roles}, nil)
+		replies := []string{}
+		api.On("SendEphemeralPost", "user_id", mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+			replies = append(replies, args.Get(1).(*model.Post).Message)
+		}).Return(func(_ string, post *model.Post) *model.Post { return post.Clone() })
+		return p, &replies
+	}
+
+	t.Run("not a system administrator", func(t *testing.T) {
+		p, replies := setup(model.SystemUserRoleId)
+		executeAdminExportMetrics(p, nil, &model.CommandArgs{UserId: "user_id"})
+		require.Len(t, *replies, 1)
+		assert.Equal(t, "`/jira admin export-metrics` can only be run by a system administrator.", (*replies)[0])
+	})
+
+	t.Run("export", func(t *testing.T) {
+		p, replies := setup(model.SystemAdminRoleId)
+		p.metrics.countCommand("view")
+		for i := 0; i < 5; i++ {
+			p.metrics.countWebhookDelivery()
+		}
+		p.metrics.countWebhookDeliveryFailure()
+
+		executeAdminExportMetrics(p, nil, &model.CommandArgs{UserId: "user_id"})
+		require.Len(t, *replies, 1)
+		reply := (*replies)[0]
+		require.True(t, strings.HasPrefix(reply, "The counters since the plugin started on this server:\n```json\n"), reply)
+		require.True(t, strings.HasSuffix(reply, "\n```"), reply)
+
+		data := strings.TrimSuffix(strings.SplitN(reply, "```json\n", 2)[1], "\n```")
+		snapshot := metricsSnapshot{}
+		require.NoError(t, json.Unmarshal([]byte(data), &snapshot))
+		assert.Equal(t, map[string]int64{"view": 1}, snapshot.Commands)
+		assert.Equal(t, int64(5), snapshot.WebhookDeliveries)
+		assert.Equal(t, int64(1), snapshot.WebhookDeliveryFailures)
+		assert.False(t, snapshot.ExportedAt.IsZero())
+	})
+}
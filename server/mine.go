@@ -0,0 +1,82 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+const (
+	mineAllFlag = "--all"
+
+	mineMaxResults = 50
+)
+
+// mineJQL returns the query of the issues assigned to the user, of the
+// project if any, the done ones included with all.
+func mineJQL(projectKey string, all bool) string {
+	clauses := []string{"assignee = currentUser()"}
+	if projectKey != "" {
+		clauses = append(clauses, fmt.Sprintf("project = %q", projectKey))
+	}
+	if !all {
+		clauses = append(clauses, "statusCategory != Done")
+	}
+	return strings.Join(clauses, " AND ") + " ORDER BY status ASC, updated DESC"
+}
+
+func executeMine(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	all := false
+	projectKey := ""
+	for _, arg := range args {
+		switch {
+		case arg == mineAllFlag:
+			all = true
+		case projectKey == "":
+			projectKey = strings.ToUpper(arg)
+		default:
+			return p.responsef(header, "Please use `/jira mine [project-key] [--all]`.")
+		}
+	}
+
+	client, _, _, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	where := instance.GetID().String()
+	if projectKey != "" {
+		project, err := client.GetProject(projectKey)
+		if err != nil {
+			return p.responsef(header, "Failed to find the project %s. Error: %v.", projectKey, err)
+		}
+		projectKey = project.Key
+		where = fmt.Sprintf("**%s** (`%s`)", project.Name, project.Key)
+	}
+
+	issues, total, err := client.SearchIssuesWithTotal(mineJQL(projectKey, all), &jira.SearchOptions{
+		MaxResults: mineMaxResults,
+		Fields:     []string{"summary", "status"},
+	})
+	if err != nil {
+		return p.responsef(header, "Failed to search the issues. Error: %v.", err)
+	}
+
+	kind := "open issue(s)"
+	if all {
+		kind = "issue(s)"
+	}
+	if total == 0 {
+		return p.responsef(header, "You have no %s assigned to you in %s.", kind, where)
+	}
+	return p.responsef(header, "You have %d %s assigned to you in %s.\n%s", total, kind, where, mdIssuesByStatus(instance, issues, total))
+}
@@ -0,0 +1,16 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMineJQL(t *testing.T) {
+	assert.Equal(t, "assignee = currentUser() AND statusCategory != Done ORDER BY status ASC, updated DESC", mineJQL("", false))
+	assert.Equal(t, `assignee = currentUser() AND project = "KT" AND statusCategory != Done ORDER BY status ASC, updated DESC`, mineJQL("KT", false))
+	assert.Equal(t, `assignee = currentUser() AND project = "KT" ORDER BY status ASC, updated DESC`, mineJQL("KT", true))
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const settingNotificationFooter = "notification-footer"
+
+// notificationFooterData is what the NotificationFooter template can use,
+// e.g. `{{.IssueKey}}`.
+type notificationFooterData struct {
+	IssueKey string
+	IssueURL string
+	JiraURL  string
+}
+
+// parseNotificationFooter parses the NotificationFooter setting, a text
+// template, or returns nil when it is empty.
+func parseNotificationFooter(setting string) (*template.Template, error) {
+	setting = strings.TrimSpace(setting)
+	if setting == "" {
+		return nil, nil
+	}
+	footer, err := template.New("notification-footer").Option("missingkey=error").Parse(setting)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid notification footer")
+	}
+	// Catch the unknown fields now rather than on every notification.
+	if err = footer.Execute(&bytes.Buffer{}, notificationFooterData{}); err != nil {
+		return nil, errors.Wrap(err, "invalid notification footer")
+	}
+	return footer, nil
+}
+
+// jiraBaseURL returns the URL of the Jira instance that sent the event.
+func (jwh *JiraWebhook) jiraBaseURL() string {
+	pos := strings.LastIndex(jwh.Issue.Self, "/rest/api")
+	if pos < 0 {
+		return ""
+	}
+	return jwh.Issue.Self[:pos]
+}
+
+// notificationFooter renders the footer of the admins for the posts about the
+// issue of the event, or returns "" when there is none.
+func (p *Plugin) notificationFooter(instanceID types.ID, jwh *JiraWebhook) string {
+	footer := p.getConfig().notificationFooter
+	if footer == nil {
+		return ""
+	}
+	jiraURL := jwh.jiraBaseURL()
+	if jiraURL == "" {
+		jiraURL = instanceID.String()
+	}
+	data := notificationFooterData{
+		IssueKey: jwh.Issue.Key,
+		JiraURL:  jiraURL,
+	}
+	if jwh.Issue.Key != "" {
+		data.IssueURL = jiraURL + "/browse/" + jwh.Issue.Key
+	}
+
+	buf := &bytes.Buffer{}
+	if err := footer.Execute(buf, data); err != nil {
+		p.client.Log.Warn("Failed to render the notification footer", "IssueKey", jwh.Issue.Key, "Error", err.Error())
+		return ""
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// withNotificationFooter appends the footer to the DM notification of the
+// user, unless they turned it off or get the compact notifications.
+func (p *Plugin) withNotificationFooter(instanceID types.ID, jwh *JiraWebhook, settings *ConnectionSettings, message string) string {
+	if settings != nil && (settings.CompactNotifications || settings.HideNotificationFooter) {
+		return message
+	}
+	footer := p.notificationFooter(instanceID, jwh)
+	if footer == "" {
+		return message
+	}
+	return message + "\n\n" + footer
+}
+
+func (p *Plugin) settingsNotificationFooter(header *model.CommandArgs, instanceID, mattermostUserID types.ID, connection *Connection, args []string) *model.CommandResponse {
+	return p.settingsToggle(header, instanceID, mattermostUserID, connection, args, settingNotificationFooter, "Notification footer",
+		func(s *ConnectionSettings, value bool) { s.HideNotificationFooter = !value })
+}
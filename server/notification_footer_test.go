@@ -0,0 +1,104 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNotificationFooter = "View it with `/jira view {{.IssueKey}}` or [in Jira]({{.IssueURL}})."
+
+func TestParseNotificationFooter(t *testing.T) {
+	footer, err := parseNotificationFooter("  ")
+	require.NoError(t, err)
+	assert.Nil(t, footer)
+
+	footer, err = parseNotificationFooter(testNotificationFooter)
+	require.NoError(t, err)
+	assert.NotNil(t, footer)
+
+	_, err = parseNotificationFooter("{{.IssueKey")
+	assert.Error(t, err)
+	_, err = parseNotificationFooter("{{.Assignee}}")
+	assert.Error(t, err, "unknown fields are rejected")
+}
+
+func TestWithNotificationFooter(t *testing.T) {
+	footer, err := parseNotificationFooter(testNotificationFooter)
+	require.NoError(t, err)
+	p := &Plugin{}
+	p.updateConfig(func(conf *config) {
+		conf.notificationFooter = footer
+	})
+	jwh := &JiraWebhook{Issue: jira.Issue{Key: "TEST-1", Self: "https://jira.example.com/rest/api/2/issue/10001"}}
+
+	assert.Equal(t, "Assigned to you\n\nView it with `/jira view TEST-1` or [in Jira](https://jira.example.com/browse/TEST-1).",
+		p.withNotificationFooter(testInstance1.InstanceID, jwh, nil, "Assigned to you"))
+	assert.Equal(t, "TEST-1 Assigned", p.withNotificationFooter(testInstance1.InstanceID, jwh, &ConnectionSettings{CompactNotifications: true}, "TEST-1 Assigned"))
+	assert.Equal(t, "Assigned to you", p.withNotificationFooter(testInstance1.InstanceID, jwh, &ConnectionSettings{HideNotificationFooter: true}, "Assigned to you"))
+
+	p.updateConfig(func(conf *config) {
+		conf.notificationFooter = nil
+	})
+	assert.Equal(t, "Assigned to you", p.withNotificationFooter(testInstance1.InstanceID, jwh, nil, "Assigned to you"))
+}
+
+func TestPostToChannelNotificationFooter(t *testing.T) {
+	bb, err := os.ReadFile(filepath.Join("testdata", "webhook-issue-created.json"))
+	require.NoError(t, err)
+	footer, err := parseNotificationFooter(testNotificationFooter)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		inCards     bool
+		renderStyle string
+		expected    bool
+	}{
+		"in the cards":             {inCards: true, renderStyle: RenderStyleFull, expected: true},
+		"not in the cards":         {renderStyle: RenderStyleFull},
+		"not in the compact posts": {inCards: true, renderStyle: RenderStyleCompact},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(func(post *model.Post) *model.Post {
+				return post.Clone()
+			}, nil)
+
+			p := Plugin{}
+			p.updateConfig(func(conf *config) {
+				conf.notificationFooter = footer
+				conf.NotificationFooterInCards = tc.inCards
+			})
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+			p.userStore = mockUserStore{}
+
+			wh, err := ParseWebhook(bb)
+			require.NoError(t, err)
+			post, _, err := wh.PostToChannel(&p, "", "thechannelid", "theuserid", "", tc.renderStyle, "", nil)
+			require.NoError(t, err)
+
+			text := post.Message
+			if attachments := post.Attachments(); len(attachments) > 0 {
+				text = attachments[0].Text
+			}
+			const rendered = "View it with `/jira view TES-41` or [in Jira](https://some-instance-test.atlassian.net/browse/TES-41)."
+			if tc.expected {
+				assert.Contains(t, text, rendered)
+			} else {
+				assert.NotContains(t, text, rendered)
+			}
+		})
+	}
+}
@@ -14,9 +14,12 @@ import (
 	"net/url"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	textTemplate "text/template"
+	"time"
 
 	"github.com/andygrunwald/go-jira"
 	"github.com/gorilla/mux"
@@ -25,6 +28,7 @@ import (
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/plugin"
 	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/mattermost/mattermost/server/public/pluginapi/cluster"
 	"github.com/mattermost/mattermost/server/public/pluginapi/experimental/flow"
 
 	"github.com/mattermost-community/mattermost-plugin-autolink/server/autolink"
@@ -42,7 +46,13 @@ const (
 
 	autolinkPluginID = "mattermost-autolink"
 
-	// Move these two to the plugin settings if admins need to adjust them.
+	// clusterEventInstanceChanged tells the other servers of the cluster that
+	// an instance was installed, changed or uninstalled. Its data is the ID of
+	// the instance.
+	clusterEventInstanceChanged = "instance_changed"
+
+	// WebhookMaxProcsPerServer is the default of the WebhookMaxConcurrency setting.
+	// Move WebhookBufferSize to the plugin settings if admins need to adjust it.
 	WebhookMaxProcsPerServer = 20
 	WebhookBufferSize        = 10000
 	PluginRepo               = "https://github.com/mattermost/mattermost-plugin-jira"
@@ -65,9 +75,68 @@ type externalConfig struct {
 	// number, optionally followed by one of [b, kb, mb, gb, tb]
 	MaxAttachmentSize string
 
+	// Maximum number of webhook events whose notifications are delivered at
+	// the same time
+	WebhookMaxConcurrency string
+
+	// Number of times the creation of a notification post is retried after
+	// a transient Mattermost error
+	PostCreateRetries string
+
+	// Comma separated list of Jira priority=Mattermost post priority pairs,
+	// e.g. "Blocker=urgent, High=important"
+	JiraPriorityPostPriorities string
+
+	// Comma separated list of Jira status or label=Mattermost custom status
+	// pairs, set for the assignees who opted in, e.g.
+	// "label:incident=:rotating_light: On incident"
+	JiraCustomStatuses string
+
+	// Comma separated list of condition=status pairs, the statuses the issues
+	// are moved to by the service account, e.g. "label:ready-for-qa=In QA"
+	JiraAutoTransitions string
+
+	// Comma separated list of the fields shown in the cards of Jira links,
+	// in order, e.g. "status, assignee"
+	UnfurlFields string
+
+	// Maximum number of characters of the summary in the cards of Jira links
+	UnfurlSummaryMaxLength string
+
+	// Number of days before the due date of an unresolved issue from which
+	// its cards show that it is due soon, 0 to never show it
+	DueSoonDays string
+
+	// Comma separated list of team name=language pairs, for the labels of
+	// the subscription cards posted in the channels of the team, e.g.
+	// "sales=de, support-fr=fr"
+	TeamLanguages string
+
 	// Additional Help Text to be shown in the output of '/jira help' command
 	JiraAdminAdditionalHelpText string
 
+	// Comma separated list of the JQL tokens, functions and clauses that
+	// the JQL of subscriptions must not use, e.g. "text ~, issueHistory()"
+	SubscriptionJQLBlocklist string
+
+	// Require the JQL of subscriptions that are not limited to some
+	// projects to have a project clause
+	SubscriptionJQLRequireProject bool
+
+	// Comma separated list of the Jira account IDs or usernames of service
+	// accounts, whose events are skipped by the subscriptions excluding self
+	// triggered events
+	ServiceAccountIDs string
+
+	// Name of the emoji that, added to an issue card, prompts for a comment
+	// to add to the issue, e.g. "speech_balloon"
+	CommentReactionEmoji string
+
+	// The ID of the channel that the health summary of the Jira instances
+	// is posted to, and the number of hours between two summaries
+	HealthSummaryChannelID     string
+	HealthSummaryIntervalHours string
+
 	// When enabled, a subscription without security level rules will filter out an issue that has a security level assigned
 	SecurityLevelEmptyForJiraSubscriptions bool
 
@@ -83,6 +152,27 @@ type externalConfig struct {
 	// Display subscription name in notifications
 	DisplaySubscriptionNameInNotifications bool
 
+	// Show the parent and epic of the issues in the subscription cards
+	ShowIssueBreadcrumbInCards bool
+
+	// Post an event once for each subscription of a channel it matches,
+	// instead of once per channel
+	AllowDuplicateChannelNotifications bool
+
+	// Post the updates of an issue in the threads about it, in the channels
+	// of the subscriptions
+	DeliverUpdatesToIssueThreads bool
+
+	// A text template appended to the DM notifications, e.g. with hints of
+	// commands
+	NotificationFooter string
+
+	// Append the NotificationFooter to the subscription cards too
+	NotificationFooterInCards bool
+
+	// The notifications of new connections: on, assigned or off
+	DefaultNotifications string
+
 	// The encryption key used to encrypt stored api tokens
 	EncryptionKey string
 
@@ -115,6 +205,47 @@ type config struct {
 	// Maximum attachment size allowed to be uploaded to Jira
 	maxAttachmentSize types.ByteSize
 
+	// Number of workers delivering webhook events
+	webhookMaxConcurrency int
+
+	// Number of times the creation of a notification post is retried after
+	// a transient error
+	postCreateRetries int
+
+	// Mattermost post priorities, by lowercase Jira priority name
+	postPriorities map[string]string
+
+	// The custom statuses of the assignees of the issues, in order
+	customStatuses []customStatusRule
+
+	// The rules that transition the issues on the Jira events, in order
+	autoTransitions []autoTransitionRule
+
+	// The lowercase tokens of each JQL entry that subscriptions must not use
+	subscriptionJQLBlocklist [][]string
+
+	// The Jira account IDs or usernames of the service accounts
+	serviceAccountIDs StringSet
+
+	// Number of days before the due date from which an issue is due soon
+	dueSoonDays int
+
+	// The footer of the notifications, or nil
+	notificationFooter *textTemplate.Template
+
+	// Time between two health summaries
+	healthSummaryInterval time.Duration
+
+	// The fields shown in the cards of Jira links, in order, and the
+	// maximum length of their summary
+	unfurlFields           []string
+	unfurlSummaryMaxLength int
+
+	// The languages of the subscription cards, by lowercase team name, and
+	// the default language of the server for the other teams
+	teamLanguages       map[string]string
+	defaultServerLocale string
+
 	mattermostSiteURL string
 	rsaKey            *rsa.PrivateKey
 }
@@ -132,6 +263,11 @@ type Plugin struct {
 	otsStore      OTSStore
 	secretsStore  SecretsStore
 
+	// the instances as last loaded, on activation or on a configuration
+	// change, and a mutex that serializes the reloads
+	instances     *Instances
+	instancesLock sync.Mutex
+
 	setupFlow  *flow.Flow
 	oauth2Flow *flow.Flow
 
@@ -144,8 +280,55 @@ type Plugin struct {
 	htmlTemplates map[string]*htmlTemplate.Template
 	textTemplates map[string]*textTemplate.Template
 
-	// channel to distribute work to the webhook processors
-	webhookQueue chan *webhookMessage
+	// distributes work to the webhook processors
+	webhookDispatcher *webhookDispatcher
+
+	// the subscription events counted since the last flush to the KV store
+	subscriptionStats subscriptionStatsBuffer
+
+	// number of posts whose creation is being retried in the background
+	pendingPostRetries atomic.Int32
+
+	// the timers of the posts being retried
+	postRetries postRetries
+
+	// counts the commands, Jira API calls and events since the plugin was
+	// activated, for /jira admin export-metrics
+	metrics pluginMetrics
+
+	// delivers the notifications held during the users' quiet hours
+	quietHoursJob *cluster.Job
+
+	// posts the number of events suppressed during the quiet windows of the
+	// channels, once they end
+	channelQuietJob *cluster.Job
+
+	// sends the users their daily summary of open issues
+	dailySummaryJob *cluster.Job
+
+	// posts the health summary of the Jira instances to the ops channel
+	healthSummaryJob *cluster.Job
+
+	// recent JQL validation outcomes, per instance
+	jqlCache instanceCache[error]
+
+	// the versions of the Jira instances, per instance
+	serverInfoCache instanceCache[jiraServerInfo]
+
+	// the IDs of the custom fields, e.g. sprint or epic link, per instance
+	customFieldCache instanceCache[map[string]string]
+
+	// the issue types of the projects, per instance
+	issueTypesCache instanceCache[projectIssueTypes]
+
+	// the parents of the issues shown in breadcrumbs, per instance
+	issueAncestorCache instanceCache[issueAncestor]
+
+	// the priorities, from the highest, per instance
+	priorityOrderCache instanceCache[[]jira.Priority]
+
+	// the development information of the issues, per instance and user
+	devInfoCache instanceCache[string]
 
 	// service that determines if this Mattermost instance has access to
 	// enterprise features
@@ -195,7 +378,8 @@ func (p *Plugin) OnConfigurationChange() error {
 
 	ec.MaxAttachmentSize = strings.TrimSpace(ec.MaxAttachmentSize)
 	maxAttachmentSize := defaultMaxAttachmentSize
-	mattermostMaxAttachmentSize := p.API.GetConfig().FileSettings.MaxFileSize
+	serverConfig := p.API.GetConfig()
+	mattermostMaxAttachmentSize := serverConfig.FileSettings.MaxFileSize
 	if mattermostMaxAttachmentSize != nil {
 		maxAttachmentSize = types.ByteSize(*mattermostMaxAttachmentSize)
 	}
@@ -206,6 +390,69 @@ func (p *Plugin) OnConfigurationChange() error {
 		}
 	}
 
+	ec.WebhookMaxConcurrency = strings.TrimSpace(ec.WebhookMaxConcurrency)
+	webhookMaxConcurrency := WebhookMaxProcsPerServer
+	if len(ec.WebhookMaxConcurrency) > 0 {
+		webhookMaxConcurrency, err = strconv.Atoi(ec.WebhookMaxConcurrency)
+		if err != nil || webhookMaxConcurrency < 1 {
+			return errors.Errorf("failed to load plugin configuration: invalid webhook concurrency %q, it must be a positive number", ec.WebhookMaxConcurrency)
+		}
+	}
+
+	postCreateRetries, err := parsePostCreateRetries(ec.PostCreateRetries)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load plugin configuration")
+	}
+
+	postPriorities, err := parsePostPriorities(ec.JiraPriorityPostPriorities)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load plugin configuration")
+	}
+
+	customStatuses, err := parseCustomStatuses(ec.JiraCustomStatuses)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load plugin configuration")
+	}
+
+	autoTransitions, err := parseAutoTransitions(ec.JiraAutoTransitions)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load plugin configuration")
+	}
+
+	unfurlFields, err := parseUnfurlFields(ec.UnfurlFields)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load plugin configuration")
+	}
+
+	dueSoonDays, err := parseDueSoonDays(ec.DueSoonDays)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load plugin configuration")
+	}
+
+	notificationFooter, err := parseNotificationFooter(ec.NotificationFooter)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load plugin configuration")
+	}
+
+	unfurlSummaryMaxLength, err := parseUnfurlSummaryMaxLength(ec.UnfurlSummaryMaxLength)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load plugin configuration")
+	}
+
+	healthSummaryInterval, err := parseHealthSummaryInterval(ec.HealthSummaryIntervalHours)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load plugin configuration")
+	}
+
+	teamLanguages, err := parseTeamLanguages(ec.TeamLanguages)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load plugin configuration")
+	}
+	defaultServerLocale := ""
+	if serverConfig.LocalizationSettings.DefaultServerLocale != nil {
+		defaultServerLocale = *serverConfig.LocalizationSettings.DefaultServerLocale
+	}
+
 	jsonBytes, err := json.Marshal(ec.AdminAPIToken)
 	if err != nil {
 		p.client.Log.Warn("Error marshaling the admin API token", "error", err.Error())
@@ -282,17 +529,27 @@ func (p *Plugin) OnConfigurationChange() error {
 	p.updateConfig(func(conf *config) {
 		conf.externalConfig = ec
 		conf.maxAttachmentSize = maxAttachmentSize
+		conf.webhookMaxConcurrency = webhookMaxConcurrency
+		conf.postCreateRetries = postCreateRetries
+		conf.postPriorities = postPriorities
+		conf.customStatuses = customStatuses
+		conf.autoTransitions = autoTransitions
+		conf.subscriptionJQLBlocklist = parseJQLBlocklist(ec.SubscriptionJQLBlocklist)
+		conf.serviceAccountIDs = parseServiceAccountIDs(ec.ServiceAccountIDs)
+		conf.healthSummaryInterval = healthSummaryInterval
+		conf.unfurlFields = unfurlFields
+		conf.unfurlSummaryMaxLength = unfurlSummaryMaxLength
+		conf.dueSoonDays = dueSoonDays
+		conf.notificationFooter = notificationFooter
+		conf.teamLanguages = teamLanguages
+		conf.defaultServerLocale = defaultServerLocale
 	})
 
 	// OnConfigurationChanged is first called before the plugin is activated,
-	// in this case don't register the command, let Activate do it, it has the instanceStore.
+	// in this case don't load the instances, let Activate do it, it has the instanceStore.
 	// TODO: consider moving (some? stores? all?) initialization into the first OnConfig instead of OnActivate.
-	if prev.EnableAutocomplete != ec.EnableAutocomplete && p.instanceStore != nil {
-		instances, err := p.instanceStore.LoadInstances()
-		if err != nil {
-			return err
-		}
-		err = p.registerJiraCommand(ec.EnableAutocomplete, instances.Len() > 1)
+	if p.instanceStore != nil {
+		err = p.reloadInstances(prev.EnableAutocomplete != ec.EnableAutocomplete)
 		if err != nil {
 			return err
 		}
@@ -307,6 +564,30 @@ func (p *Plugin) OnConfigurationChange() error {
 }
 
 func (p *Plugin) OnDeactivate() error {
+	if p.quietHoursJob != nil {
+		if err := p.quietHoursJob.Close(); err != nil {
+			p.client.Log.Warn("Failed to close the quiet hours job", "error", err.Error())
+		}
+	}
+	if p.channelQuietJob != nil {
+		if err := p.channelQuietJob.Close(); err != nil {
+			p.client.Log.Warn("Failed to close the channel quiet windows job", "error", err.Error())
+		}
+	}
+	if p.dailySummaryJob != nil {
+		if err := p.dailySummaryJob.Close(); err != nil {
+			p.client.Log.Warn("Failed to close the daily summary job", "error", err.Error())
+		}
+	}
+	if p.healthSummaryJob != nil {
+		if err := p.healthSummaryJob.Close(); err != nil {
+			p.client.Log.Warn("Failed to close the health summary job", "error", err.Error())
+		}
+	}
+
+	p.stopPostRetries()
+	p.stopSubscriptionStatsFlush()
+
 	// close the tracker on plugin deactivation
 	if p.telemetryClient != nil {
 		err := p.telemetryClient.Close()
@@ -332,12 +613,8 @@ func (p *Plugin) OnActivate() error {
 		return errors.Wrap(err, "couldn't get bundle path")
 	}
 
-	botUserID, err := p.client.Bot.EnsureBot(&model.Bot{
-		OwnerId:     manifest.Id, // Workaround to support older server version affected by https://github.com/mattermost/mattermost-server/pull/21560
-		Username:    botUserName,
-		DisplayName: botDisplayName,
-		Description: botDescription,
-	}, pluginapi.ProfileImagePath(filepath.Join("assets", "profile.png")))
+	bot, botIcon := p.botEnsureOptions()
+	botUserID, err := p.client.Bot.EnsureBot(bot, botIcon)
 	if err != nil {
 		return errors.Wrap(err, "failed to ensure bot account")
 	}
@@ -370,6 +647,7 @@ func (p *Plugin) OnActivate() error {
 	if err != nil {
 		return errors.WithMessage(err, "OnActivate: failed to migrate from previous version of the Jira plugin")
 	}
+	p.setInstances(instances)
 
 	htmlTemplates, textTemplates, err := p.loadTemplates(filepath.Join(bundlePath, "assets", "templates"))
 	if err != nil {
@@ -397,14 +675,40 @@ func (p *Plugin) OnActivate() error {
 		return errors.Wrap(err, "OnActivate")
 	}
 
-	// Create our queue of webhook events waiting to be processed.
-	p.webhookQueue = make(chan *webhookMessage, WebhookBufferSize)
+	// Spin up our webhook workers, and their queues of webhook events
+	// waiting to be processed.
+	p.metrics.start(time.Now().UTC())
+	p.webhookDispatcher = newWebhookDispatcher(p.getConfig().webhookMaxConcurrency, WebhookBufferSize,
+		func(workerID int, msg *webhookMessage) error {
+			return webhookWorker{workerID, p}.handle(msg)
+		})
+
+	p.quietHoursJob, err = cluster.Schedule(p.API, "QuietHoursDelivery", cluster.MakeWaitForRoundedInterval(quietHoursFlushInterval),
+		func() { p.deliverQuietHoursQueues(time.Now()) })
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the quiet hours job")
+	}
+
+	p.channelQuietJob, err = cluster.Schedule(p.API, "ChannelQuietSummary", cluster.MakeWaitForRoundedInterval(channelQuietSummaryInterval),
+		func() { p.postChannelQuietSummaries(time.Now()) })
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the channel quiet windows job")
+	}
 
-	// Spin up our webhook workers.
-	for i := 0; i < WebhookMaxProcsPerServer; i++ {
-		go webhookWorker{i, p, p.webhookQueue}.work()
+	p.dailySummaryJob, err = cluster.Schedule(p.API, "DailySummary", cluster.MakeWaitForRoundedInterval(dailySummaryInterval),
+		func() { p.sendDailySummaries(time.Now()) })
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the daily summary job")
 	}
 
+	p.healthSummaryJob, err = cluster.Schedule(p.API, "HealthSummary", cluster.MakeWaitForRoundedInterval(healthSummaryCheckInterval),
+		func() { p.postScheduledHealthSummary(time.Now()) })
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule the health summary job")
+	}
+
+	p.startSubscriptionStatsFlush(subscriptionStatsFlushInterval)
+
 	p.enterpriseChecker = enterprise.NewEnterpriseChecker(p.API)
 
 	go func() {
@@ -666,3 +970,17 @@ func (p *Plugin) OnInstall(c *plugin.Context, event model.OnInstallEvent) error
 
 	return nil
 }
+
+// OnPluginClusterEvent drops what is cached about an instance changed on
+// another server of the cluster, and reloads the instances, so that the change
+// takes effect on this server too.
+func (p *Plugin) OnPluginClusterEvent(c *plugin.Context, ev model.PluginClusterEvent) {
+	if ev.Id != clusterEventInstanceChanged {
+		return
+	}
+
+	p.invalidateInstanceCaches(types.ID(ev.Data))
+	if err := p.reloadInstances(false); err != nil {
+		p.errorf("OnPluginClusterEvent: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+// postPriorityImportant is the Mattermost "important" post priority, which the
+// model package has no constant for.
+const postPriorityImportant = "important"
+
+// parsePostPriorities parses the JiraPriorityPostPriorities setting, e.g.
+// "Blocker=urgent, High=important", into post priorities by lowercase Jira
+// priority name.
+func parsePostPriorities(setting string) (map[string]string, error) {
+	priorities := map[string]string{}
+	for _, pair := range strings.Split(setting, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, errors.Errorf("invalid post priority %q, expected the form `Jira priority=post priority`", pair)
+		}
+
+		postPriority := strings.ToLower(strings.TrimSpace(parts[1]))
+		switch postPriority {
+		case model.PostPriorityUrgent, postPriorityImportant:
+		default:
+			return nil, errors.Errorf("invalid post priority %q for Jira priority %q, it must be `urgent` or `important`", parts[1], strings.TrimSpace(parts[0]))
+		}
+		priorities[strings.ToLower(strings.TrimSpace(parts[0]))] = postPriority
+	}
+	return priorities, nil
+}
+
+// postPriority returns the Mattermost post priority of notifications about
+// issues with the Jira priority, or "" for the normal priority.
+func (p *Plugin) postPriority(jiraPriority string) string {
+	if jiraPriority == "" {
+		return ""
+	}
+	return p.getConfig().postPriorities[strings.ToLower(jiraPriority)]
+}
+
+func setPostPriority(post *model.Post, postPriority string) {
+	if postPriority == "" {
+		return
+	}
+	if post.Metadata == nil {
+		post.Metadata = &model.PostMetadata{}
+	}
+	post.Metadata.Priority = &model.PostPriority{
+		Priority: model.NewPointer(postPriority),
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePostPriorities(t *testing.T) {
+	priorities, err := parsePostPriorities(" Blocker=urgent, Highest = URGENT ,High=important,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"blocker": model.PostPriorityUrgent,
+		"highest": model.PostPriorityUrgent,
+		"high":    postPriorityImportant,
+	}, priorities)
+
+	priorities, err = parsePostPriorities("")
+	require.NoError(t, err)
+	assert.Empty(t, priorities)
+
+	for _, invalid := range []string{"Blocker", "=urgent", "Blocker=critical"} {
+		_, err = parsePostPriorities(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestPostToChannelPostPriority(t *testing.T) {
+	// The issue of the event has the High priority.
+	bb, err := os.ReadFile(filepath.Join("testdata", "webhook-issue-created.json"))
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		setting  string
+		expected string
+	}{
+		"mapped priority": {
+			setting:  "Blocker=urgent, high=important",
+			expected: postPriorityImportant,
+		},
+		"unmapped priority": {
+			setting: "Blocker=urgent",
+		},
+		"no mapping": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(func(post *model.Post) *model.Post {
+				return post.Clone()
+			}, nil)
+
+			postPriorities, err := parsePostPriorities(tc.setting)
+			require.NoError(t, err)
+			p := Plugin{}
+			p.updateConfig(func(conf *config) {
+				conf.postPriorities = postPriorities
+			})
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+			p.userStore = mockUserStore{}
+
+			wh, err := ParseWebhook(bb)
+			require.NoError(t, err)
+
+			post, _, err := wh.PostToChannel(&p, "", "thechannelid", "theuserid", "", RenderStyleFull, "", nil)
+			require.NoError(t, err)
+			if tc.expected == "" {
+				assert.True(t, post.Metadata == nil || post.Metadata.Priority == nil)
+				return
+			}
+			require.NotNil(t, post.Metadata)
+			require.NotNil(t, post.Metadata.Priority)
+			assert.Equal(t, tc.expected, *post.Metadata.Priority.Priority)
+		})
+	}
+}
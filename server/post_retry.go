@@ -0,0 +1,166 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultPostCreateRetries = 2
+	maxPostCreateRetries     = 5
+
+	// maxPendingPostRetries bounds the posts being retried in the background.
+	// Beyond it, a transient error is returned like a permanent one.
+	maxPendingPostRetries = 100
+)
+
+// postCreateBackoff is the wait before the first retry of the creation of a
+// post, doubled before each of the next ones.
+var postCreateBackoff = 250 * time.Millisecond
+
+// postRetries tracks the timers of the posts being retried, so that they are
+// stopped when the plugin is deactivated. Its zero value is ready to use.
+type postRetries struct {
+	lock    sync.Mutex
+	timers  map[*time.Timer]struct{}
+	running sync.WaitGroup
+	stopped bool
+}
+
+func parsePostCreateRetries(setting string) (int, error) {
+	setting = strings.TrimSpace(setting)
+	if setting == "" {
+		return defaultPostCreateRetries, nil
+	}
+	n, err := strconv.Atoi(setting)
+	if err != nil || n < 0 || n > maxPostCreateRetries {
+		return 0, errors.Errorf("invalid number of post creation retries %q, it must be between 0 and %d", setting, maxPostCreateRetries)
+	}
+	return n, nil
+}
+
+// isTransientPostError reports whether Mattermost failed to create a post
+// because of a temporary condition, such as rate limiting or an internal
+// error, which is worth retrying. Failures such as the channel having been
+// deleted or the user not existing are permanent.
+func isTransientPostError(err error) bool {
+	if err == nil || errors.Is(err, pluginapi.ErrNotFound) {
+		return false
+	}
+	var appErr *model.AppError
+	if !errors.As(err, &appErr) {
+		return false
+	}
+	return appErr.StatusCode == http.StatusTooManyRequests || appErr.StatusCode >= http.StatusInternalServerError
+}
+
+// createPost creates a post of a notification. When it fails with a transient
+// error, the creation is retried in the background with backoff, as
+// configured, and nil is returned: the caller, often handling a request, does
+// not wait for the retries, and the post has no ID yet. Permanent errors, and
+// transient ones once the plugin is deactivated, are returned right away.
+func (p *Plugin) createPost(post *model.Post) error {
+	err := p.client.Post.CreatePost(post)
+	retries := p.getConfig().postCreateRetries
+	if err == nil || retries == 0 || !isTransientPostError(err) {
+		return err
+	}
+	if p.pendingPostRetries.Add(1) > maxPendingPostRetries {
+		p.pendingPostRetries.Add(-1)
+		return err
+	}
+
+	p.client.Log.Debug("Retrying to create a post after a transient error",
+		"ChannelID", post.ChannelId,
+		"Attempt", 1,
+		"Error", err.Error())
+	if !p.retryCreatePost(post.Clone(), 2, retries, postCreateBackoff) {
+		return err
+	}
+	return nil
+}
+
+// retryCreatePost creates the post after the backoff, and retries again with
+// twice the backoff while it fails with a transient error, up to the number
+// of retries. It returns false, and does not retry, once the retries are
+// stopped.
+func (p *Plugin) retryCreatePost(post *model.Post, attempt, retries int, backoff time.Duration) bool {
+	r := &p.postRetries
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.stopped {
+		p.pendingPostRetries.Add(-1)
+		return false
+	}
+	if r.timers == nil {
+		r.timers = map[*time.Timer]struct{}{}
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(backoff, func() {
+		// The lock is held until timer is assigned.
+		r.lock.Lock()
+		delete(r.timers, timer)
+		stopped := r.stopped
+		if !stopped {
+			r.running.Add(1)
+		}
+		r.lock.Unlock()
+		if stopped {
+			return
+		}
+		defer r.running.Done()
+
+		err := p.client.Post.CreatePost(post)
+		if err == nil {
+			p.pendingPostRetries.Add(-1)
+			return
+		}
+		if attempt > retries || !isTransientPostError(err) {
+			p.pendingPostRetries.Add(-1)
+			p.client.Log.Warn("Failed to create a post after retrying",
+				"ChannelID", post.ChannelId,
+				"Attempts", attempt,
+				"Error", err.Error())
+			return
+		}
+
+		p.client.Log.Debug("Retrying to create a post after a transient error",
+			"ChannelID", post.ChannelId,
+			"Attempt", attempt,
+			"Error", err.Error())
+		p.retryCreatePost(post, attempt+1, retries, backoff*2)
+	})
+	r.timers[timer] = struct{}{}
+	return true
+}
+
+// stopPostRetries drops the posts waiting to be retried, and waits for the
+// retries in progress to end. No post is retried after it returns.
+func (p *Plugin) stopPostRetries() {
+	r := &p.postRetries
+	r.lock.Lock()
+	r.stopped = true
+	for timer := range r.timers {
+		timer.Stop()
+	}
+	dropped := len(r.timers)
+	r.timers = nil
+	r.lock.Unlock()
+
+	p.pendingPostRetries.Add(-int32(dropped))
+	if dropped > 0 {
+		p.client.Log.Warn("Dropped the posts being retried on deactivation", "Count", dropped)
+	}
+	r.running.Wait()
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePostCreateRetries(t *testing.T) {
+	for setting, expected := range map[string]int{"": defaultPostCreateRetries, " 0 ": 0, "5": 5} {
+		n, err := parsePostCreateRetries(setting)
+		require.NoError(t, err, setting)
+		assert.Equal(t, expected, n, setting)
+	}
+	for _, setting := range []string{"-1", "6", "twice"} {
+		_, err := parsePostCreateRetries(setting)
+		assert.Error(t, err, setting)
+	}
+}
+
+func TestIsTransientPostError(t *testing.T) {
+	appErr := func(status int) error {
+		return model.NewAppError("CreatePost", "app.post.save", nil, "", status)
+	}
+	assert.True(t, isTransientPostError(appErr(http.StatusTooManyRequests)))
+	assert.True(t, isTransientPostError(appErr(http.StatusInternalServerError)))
+	assert.True(t, isTransientPostError(errors.WithMessage(appErr(http.StatusServiceUnavailable), "failed to post")))
+	assert.False(t, isTransientPostError(appErr(http.StatusBadRequest)))
+	assert.False(t, isTransientPostError(appErr(http.StatusForbidden)))
+	assert.False(t, isTransientPostError(pluginapi.ErrNotFound))
+	assert.False(t, isTransientPostError(errors.New("boom")))
+	assert.False(t, isTransientPostError(nil))
+}
+
+func TestCreatePostRetries(t *testing.T) {
+	backoff := postCreateBackoff
+	postCreateBackoff = 0
+	defer func() { postCreateBackoff = backoff }()
+
+	transient := model.NewAppError("CreatePost", "api.context.rate_limited", nil, "", http.StatusTooManyRequests)
+	setup := func(retries int) (*Plugin, *plugintest.API) {
+		api := &plugintest.API{}
+		api.On("LogDebug", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+		api.On("LogWarn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+		p := &Plugin{}
+		p.SetAPI(api)
+		p.client = pluginapi.NewClient(api, p.Driver)
+		p.updateConfig(func(conf *config) {
+			conf.postCreateRetries = retries
+		})
+		return p, api
+	}
+
+	t.Run("a transient error is retried in the background", func(t *testing.T) {
+		p, api := setup(2)
+		api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, transient).Once()
+		api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(&model.Post{Id: "post1"}, nil).Once()
+
+		require.NoError(t, p.createPost(&model.Post{ChannelId: "channel1"}))
+		require.Eventually(t, func() bool { return p.pendingPostRetries.Load() == 0 }, time.Second, time.Millisecond)
+		api.AssertNumberOfCalls(t, "CreatePost", 2)
+		api.AssertNotCalled(t, "LogWarn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("retries are bounded", func(t *testing.T) {
+		p, api := setup(2)
+		api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, transient)
+
+		require.NoError(t, p.createPost(&model.Post{ChannelId: "channel1"}))
+		require.Eventually(t, func() bool { return p.pendingPostRetries.Load() == 0 }, time.Second, time.Millisecond)
+		api.AssertNumberOfCalls(t, "CreatePost", 3)
+		api.AssertCalled(t, "LogWarn", "Failed to create a post after retrying", "ChannelID", "channel1", "Attempts", 3, "Error", mock.Anything)
+	})
+
+	t.Run("retries are stopped on deactivation", func(t *testing.T) {
+		postCreateBackoff = time.Hour
+		defer func() { postCreateBackoff = 0 }()
+
+		p, api := setup(2)
+		api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, transient)
+
+		require.NoError(t, p.createPost(&model.Post{ChannelId: "channel1"}))
+		assert.Equal(t, int32(1), p.pendingPostRetries.Load())
+
+		p.stopPostRetries()
+		assert.Equal(t, int32(0), p.pendingPostRetries.Load())
+		assert.Empty(t, p.postRetries.timers)
+		api.AssertCalled(t, "LogWarn", "Dropped the posts being retried on deactivation", "Count", 1)
+
+		// A post failing after the deactivation is not retried.
+		postCreateBackoff = 0
+		assert.Equal(t, transient, p.createPost(&model.Post{ChannelId: "channel1"}))
+		assert.Equal(t, int32(0), p.pendingPostRetries.Load())
+		api.AssertNumberOfCalls(t, "CreatePost", 2)
+	})
+
+	t.Run("too many posts being retried", func(t *testing.T) {
+		p, api := setup(2)
+		p.pendingPostRetries.Store(maxPendingPostRetries)
+		api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, transient)
+
+		assert.Equal(t, transient, p.createPost(&model.Post{ChannelId: "channel1"}))
+		api.AssertNumberOfCalls(t, "CreatePost", 1)
+	})
+
+	t.Run("a permanent error is not retried", func(t *testing.T) {
+		p, api := setup(2)
+		api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, model.NewAppError("CreatePost", "api.post.create_post.channel_deleted", nil, "", http.StatusBadRequest))
+
+		assert.Error(t, p.createPost(&model.Post{ChannelId: "channel1"}))
+		api.AssertNumberOfCalls(t, "CreatePost", 1)
+	})
+
+	t.Run("no retries when disabled", func(t *testing.T) {
+		p, api := setup(0)
+		api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, transient)
+
+		assert.Error(t, p.createPost(&model.Post{ChannelId: "channel1"}))
+		api.AssertNumberOfCalls(t, "CreatePost", 1)
+	})
+}
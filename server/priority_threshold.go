@@ -0,0 +1,163 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	settingNotifyPriorityThreshold = "notify-priority-threshold"
+
+	flagSkipUnprioritized = "--skip-unprioritized"
+
+	// priorityOrderTTL is how long the priorities of an instance are
+	// remembered. They are changed by the Jira admins, seldom.
+	priorityOrderTTL = 30 * time.Minute
+)
+
+// getPriorityOrder returns the priorities of the instance, from the highest,
+// in the order of the Jira admins.
+func (p *Plugin) getPriorityOrder(instanceID types.ID, client Client) ([]jira.Priority, error) {
+	if priorities, ok := p.priorityOrderCache.get(instanceID, ""); ok {
+		return priorities, nil
+	}
+
+	priorities := []jira.Priority{}
+	if err := client.RESTGet("2/priority", nil, &priorities); err != nil {
+		return nil, errors.WithMessage(err, "failed to get the priorities")
+	}
+	p.priorityOrderCache.set(instanceID, "", priorities, priorityOrderTTL)
+	return priorities, nil
+}
+
+// priorityRankOf returns the rank of the priority, 0 for the highest, by ID
+// and else by name, or -1 if the instance does not have it.
+func priorityRankOf(priorities []jira.Priority, id, name string) int {
+	for i, priority := range priorities {
+		if id != "" && priority.ID == id {
+			return i
+		}
+	}
+	for i, priority := range priorities {
+		if name != "" && strings.EqualFold(priority.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// meetsPriorityThreshold tells whether an issue with the priority, nil if it
+// has none, is notified to a user whose threshold is the name of a priority.
+// The issues without a priority pass unless skipUnprioritized is set. A
+// threshold or a priority that the instance no longer has does not filter
+// anything out.
+func meetsPriorityThreshold(priorities []jira.Priority, threshold string, priority *jira.Priority, skipUnprioritized bool) bool {
+	if threshold == "" {
+		return true
+	}
+	if priority == nil || (priority.ID == "" && priority.Name == "") {
+		return !skipUnprioritized
+	}
+	thresholdRank := priorityRankOf(priorities, "", threshold)
+	rank := priorityRankOf(priorities, priority.ID, priority.Name)
+	if thresholdRank < 0 || rank < 0 {
+		return true
+	}
+	return rank <= thresholdRank
+}
+
+// notifiesPriority tells whether the DM notifications of the event are sent to
+// the user with the settings, by their priority threshold.
+func (p *Plugin) notifiesPriority(instanceID types.ID, client Client, settings *ConnectionSettings, jwh *JiraWebhook) bool {
+	if settings == nil || settings.NotifyPriorityThreshold == "" {
+		return true
+	}
+	var priority *jira.Priority
+	if jwh.Issue.Fields != nil {
+		priority = jwh.Issue.Fields.Priority
+	}
+	if priority == nil {
+		return !settings.SkipUnprioritized
+	}
+
+	priorities, err := p.getPriorityOrder(instanceID, client)
+	if err != nil {
+		p.client.Log.Debug("Failed to get the priorities, the priority threshold is ignored", "InstanceID", instanceID, "Error", err.Error())
+		return true
+	}
+	return meetsPriorityThreshold(priorities, settings.NotifyPriorityThreshold, priority, settings.SkipUnprioritized)
+}
+
+func (p *Plugin) settingsNotifyPriorityThreshold(header *model.CommandArgs, instanceID, mattermostUserID types.ID, connection *Connection, args []string) *model.CommandResponse {
+	const helpText = "`/jira settings notify-priority-threshold [priority|off] [--skip-unprioritized]`\n" +
+		"* [priority] is the lowest priority of the issues you are notified about, e.g. `High`, or `off` to be notified about all of them.\n" +
+		"* `--skip-unprioritized` also leaves out the issues without a priority, which are notified by default."
+
+	skipUnprioritized := false
+	words := []string{}
+	for _, arg := range args[1:] {
+		if arg == flagSkipUnprioritized {
+			skipUnprioritized = true
+			continue
+		}
+		words = append(words, arg)
+	}
+	name := strings.Join(words, " ")
+	if name == "" {
+		return p.responsef(header, "%s", helpText)
+	}
+
+	if connection.Settings == nil {
+		connection.Settings = &ConnectionSettings{}
+	}
+	if name == settingOff {
+		connection.Settings.NotifyPriorityThreshold = ""
+		connection.Settings.SkipUnprioritized = false
+	} else {
+		client, _, _, err := p.getClient(instanceID, mattermostUserID)
+		if err != nil {
+			return p.responsef(header, "%v", err)
+		}
+		priorities, err := p.getPriorityOrder(instanceID, client)
+		if err != nil {
+			return p.responsef(header, "Failed to check the priority. Error: %v.", err)
+		}
+		rank := priorityRankOf(priorities, "", name)
+		if rank < 0 {
+			names := []string{}
+			for _, priority := range priorities {
+				names = append(names, priority.Name)
+			}
+			return p.responsef(header, "Unknown priority %q, the priorities are, from the highest: %s.\n%s", name, strings.Join(names, ", "), helpText)
+		}
+		connection.Settings.NotifyPriorityThreshold = priorities[rank].Name
+		connection.Settings.SkipUnprioritized = skipUnprioritized
+	}
+
+	if err := p.userStore.StoreConnection(instanceID, mattermostUserID, connection); err != nil {
+		p.errorf("settingsNotifyPriorityThreshold, err: %v", err)
+		return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
+	}
+	if connection.Settings.NotifyPriorityThreshold == "" {
+		return p.responsef(header, "Settings updated. You will be notified about the issues of all priorities.")
+	}
+	return p.responsef(header, "Settings updated. %s.", connection.Settings.priorityThresholdString())
+}
+
+// priorityThresholdString describes the priority threshold of the settings.
+func (s *ConnectionSettings) priorityThresholdString() string {
+	str := "You will only be notified about the issues of priority " + s.NotifyPriorityThreshold + " and above"
+	if s.SkipUnprioritized {
+		return str + ", not those without a priority"
+	}
+	return str + ", and those without a priority"
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPriorities = `[
+	{"id": "1", "name": "Highest"},
+	{"id": "2", "name": "High"},
+	{"id": "3", "name": "Medium"},
+	{"id": "4", "name": "Low"},
+	{"id": "5", "name": "Lowest"}
+]`
+
+type priorityTestClient struct {
+	testClient
+	calls *int
+}
+
+func (client priorityTestClient) RESTGet(endpoint string, params map[string]string, dest interface{}) error {
+	*client.calls++
+	return json.Unmarshal([]byte(testPriorities), dest)
+}
+
+func TestMeetsPriorityThreshold(t *testing.T) {
+	priorities := []jira.Priority{}
+	require.NoError(t, json.Unmarshal([]byte(testPriorities), &priorities))
+
+	for name, tc := range map[string]struct {
+		threshold         string
+		priority          *jira.Priority
+		skipUnprioritized bool
+		expected          bool
+	}{
+		"no threshold":                {"", &jira.Priority{ID: "5"}, false, true},
+		"above the threshold":         {"High", &jira.Priority{ID: "1"}, false, true},
+		"at the threshold":            {"high", &jira.Priority{ID: "2"}, false, true},
+		"below the threshold":         {"High", &jira.Priority{ID: "3"}, false, false},
+		"lowest below the threshold":  {"Medium", &jira.Priority{ID: "5"}, false, false},
+		"lowest threshold":            {"Lowest", &jira.Priority{ID: "5"}, false, true},
+		"by name without ID":          {"High", &jira.Priority{Name: "Low"}, false, false},
+		"no priority":                 {"High", nil, false, true},
+		"no priority skipped":         {"High", nil, true, false},
+		"unknown priority":            {"High", &jira.Priority{ID: "99", Name: "Custom"}, false, true},
+		"threshold no longer defined": {"Blocker", &jira.Priority{ID: "5"}, false, true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, meetsPriorityThreshold(priorities, tc.threshold, tc.priority, tc.skipUnprioritized))
+		})
+	}
+}
+
+func TestNotifiesPriority(t *testing.T) {
+	p := &Plugin{}
+	calls := 0
+	client := priorityTestClient{calls: &calls}
+	jwh := func(priority *jira.Priority) *JiraWebhook {
+		return &JiraWebhook{Issue: jira.Issue{Fields: &jira.IssueFields{Priority: priority}}}
+	}
+	settings := &ConnectionSettings{NotifyPriorityThreshold: "Medium"}
+
+	assert.True(t, p.notifiesPriority(mockInstance1URL, client, nil, jwh(&jira.Priority{ID: "5"})))
+	assert.True(t, p.notifiesPriority(mockInstance1URL, client, &ConnectionSettings{}, jwh(&jira.Priority{ID: "5"})))
+	assert.Equal(t, 0, calls)
+
+	assert.True(t, p.notifiesPriority(mockInstance1URL, client, settings, jwh(&jira.Priority{ID: "3"})))
+	assert.False(t, p.notifiesPriority(mockInstance1URL, client, settings, jwh(&jira.Priority{ID: "4"})))
+	assert.Equal(t, 1, calls, "the priorities are cached per instance")
+
+	assert.True(t, p.notifiesPriority(mockInstance1URL, client, settings, jwh(nil)))
+	settings.SkipUnprioritized = true
+	assert.False(t, p.notifiesPriority(mockInstance1URL, client, settings, jwh(nil)))
+
+	now := time.Now()
+	p.priorityOrderCache.now = func() time.Time { return now.Add(priorityOrderTTL + time.Minute) }
+	assert.False(t, p.notifiesPriority(mockInstance1URL, client, settings, jwh(&jira.Priority{ID: "4"})))
+	assert.Equal(t, 2, calls, "the priorities are fetched again once expired")
+
+	p.priorityOrderCache.invalidate(mockInstance1URL)
+	assert.True(t, p.notifiesPriority(mockInstance1URL, client, settings, jwh(&jira.Priority{ID: "1"})))
+	assert.Equal(t, 3, calls)
+}
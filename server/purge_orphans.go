@@ -0,0 +1,279 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/kvstore"
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// orphanConnection is the connection of a user to an instance that no longer
+// exists.
+type orphanConnection struct {
+	MattermostUserID types.ID
+	InstanceID       types.ID
+}
+
+// orphanInstanceData are the subscriptions and subscription templates stored
+// for an instance that no longer exists.
+type orphanInstanceData struct {
+	InstanceID    types.ID
+	Subscriptions int
+	Templates     int
+}
+
+type orphanReport struct {
+	Connections []orphanConnection
+	Instances   []orphanInstanceData
+	// ListedInstances are the instances of the instance list that no longer
+	// exist.
+	ListedInstances []types.ID
+}
+
+func (r orphanReport) isEmpty() bool {
+	return len(r.Connections) == 0 && len(r.Instances) == 0 && len(r.ListedInstances) == 0
+}
+
+func (r orphanReport) subscriptions() int {
+	n := 0
+	for _, data := range r.Instances {
+		n += data.Subscriptions
+	}
+	return n
+}
+
+func (r orphanReport) templates() int {
+	n := 0
+	for _, data := range r.Instances {
+		n += data.Templates
+	}
+	return n
+}
+
+// Markdown lists the orphaned data, by instance.
+func (r orphanReport) Markdown() string {
+	if r.isEmpty() {
+		return "No connections, subscriptions or instance list entries reference an instance that no longer exists."
+	}
+
+	connections := map[types.ID]int{}
+	ids := []types.ID{}
+	for _, c := range r.Connections {
+		if connections[c.InstanceID] == 0 {
+			ids = append(ids, c.InstanceID)
+		}
+		connections[c.InstanceID]++
+	}
+	data := map[types.ID]orphanInstanceData{}
+	for _, d := range r.Instances {
+		if connections[d.InstanceID] == 0 {
+			ids = append(ids, d.InstanceID)
+		}
+		data[d.InstanceID] = d
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	msg := ""
+	if len(ids) > 0 {
+		msg = fmt.Sprintf("Found %d connection(s), %d subscription(s) and %d subscription template(s) of instances that no longer exist:\n",
+			len(r.Connections), r.subscriptions(), r.templates())
+		for _, id := range ids {
+			msg += fmt.Sprintf("* %s: %d connection(s), %d subscription(s), %d subscription template(s)\n",
+				id, connections[id], data[id].Subscriptions, data[id].Templates)
+		}
+	}
+	if len(r.ListedInstances) > 0 {
+		msg += "Found instances that no longer exist in the instance list:\n"
+		for _, id := range r.ListedInstances {
+			msg += fmt.Sprintf("* %s\n", id)
+		}
+	}
+	return strings.TrimSuffix(msg, "\n")
+}
+
+// isOrphanInstance reports whether an instance no longer exists. Errors other
+// than the instance not being found are returned, so that nothing is purged
+// because of a failure to read the store.
+func (p *Plugin) isOrphanInstance(instanceID types.ID, checked map[types.ID]bool) (bool, error) {
+	if orphan, ok := checked[instanceID]; ok {
+		return orphan, nil
+	}
+	_, err := p.instanceStore.LoadInstance(instanceID)
+	if err != nil && errors.Cause(err) != kvstore.ErrNotFound {
+		return false, errors.WithMessagef(err, "failed to load instance %s", instanceID)
+	}
+	checked[instanceID] = err != nil
+	return checked[instanceID], nil
+}
+
+// findOrphans cross-references the connections of the users, and the
+// instances they and the instance list refer to, against the instance store.
+// The subscriptions of an instance are stored under its ID, so the ones of
+// the instances no one refers to anymore cannot be found.
+func (p *Plugin) findOrphans() (*orphanReport, error) {
+	report := &orphanReport{}
+	checked := map[types.ID]bool{}
+	candidates := []types.ID{}
+
+	err := p.userStore.MapUsers(func(user *User) error {
+		if user.ConnectedInstances.IsEmpty() {
+			return nil
+		}
+		for _, instanceID := range user.ConnectedInstances.IDs() {
+			if _, ok := checked[instanceID]; !ok {
+				candidates = append(candidates, instanceID)
+			}
+			orphan, err := p.isOrphanInstance(instanceID, checked)
+			if err != nil {
+				return err
+			}
+			if orphan {
+				report.Connections = append(report.Connections, orphanConnection{
+					MattermostUserID: user.MattermostUserID,
+					InstanceID:       instanceID,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	instances, err := p.instanceStore.LoadInstances()
+	if err != nil {
+		return nil, err
+	}
+	for _, instanceID := range instances.IDs() {
+		if _, ok := checked[instanceID]; ok {
+			continue
+		}
+		candidates = append(candidates, instanceID)
+		orphan, err := p.isOrphanInstance(instanceID, checked)
+		if err != nil {
+			return nil, err
+		}
+		if orphan {
+			report.ListedInstances = append(report.ListedInstances, instanceID)
+		}
+	}
+
+	for _, instanceID := range candidates {
+		if !checked[instanceID] {
+			continue
+		}
+		subs, err := p.getSubscriptions(instanceID)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to load the subscriptions of %s", instanceID)
+		}
+		templates, err := p.getTemplates(instanceID)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to load the subscription templates of %s", instanceID)
+		}
+		data := orphanInstanceData{
+			InstanceID:    instanceID,
+			Subscriptions: len(subs.Channel.ByID),
+			Templates:     len(templates.Templates.ByID),
+		}
+		if data.Subscriptions > 0 || data.Templates > 0 {
+			report.Instances = append(report.Instances, data)
+		}
+	}
+	return report, nil
+}
+
+// purgeOrphans deletes the orphaned data of the report. It keeps going when a
+// deletion fails, and returns the first error.
+func (p *Plugin) purgeOrphans(report *orphanReport) (orphanReport, error) {
+	purged := orphanReport{}
+	var firstErr error
+	fail := func(err error) {
+		p.errorf("purgeOrphans: %v", err)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for _, c := range report.Connections {
+		err := p.userStore.DeleteConnection(c.InstanceID, c.MattermostUserID)
+		if err != nil && errors.Cause(err) != kvstore.ErrNotFound {
+			fail(err)
+			continue
+		}
+
+		user, err := p.userStore.LoadUser(c.MattermostUserID)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		if !user.ConnectedInstances.IsEmpty() {
+			user.ConnectedInstances.Delete(c.InstanceID)
+		}
+		if user.DefaultInstanceID == c.InstanceID {
+			user.DefaultInstanceID = ""
+		}
+		if err = p.userStore.StoreUser(user); err != nil {
+			fail(err)
+			continue
+		}
+		purged.Connections = append(purged.Connections, c)
+	}
+
+	for _, data := range report.Instances {
+		if subs, err := p.getSubscriptions(data.InstanceID); err == nil {
+			for subscriptionID := range subs.Channel.ByID {
+				if err = p.deleteAnnouncedIssues(data.InstanceID, subscriptionID); err != nil {
+					p.errorf("purgeOrphans: failed to delete the issues announced by subscription %s: %v", subscriptionID, err)
+				}
+			}
+		}
+		if err := p.client.KV.Delete(keyWithInstanceID(data.InstanceID, JiraSubscriptionsKey)); err != nil {
+			fail(errors.WithMessagef(err, "failed to delete the subscriptions of %s", data.InstanceID))
+			continue
+		}
+		if err := p.client.KV.Delete(keyWithInstanceID(data.InstanceID, templateKey)); err != nil {
+			fail(errors.WithMessagef(err, "failed to delete the subscription templates of %s", data.InstanceID))
+			continue
+		}
+		purged.Instances = append(purged.Instances, data)
+	}
+
+	if len(report.ListedInstances) > 0 {
+		var updated *Instances
+		err := UpdateInstances(p.instanceStore, func(instances *Instances) error {
+			for _, instanceID := range report.ListedInstances {
+				instances.Delete(instanceID)
+			}
+			updated = instances
+			return nil
+		})
+		if err != nil {
+			fail(errors.WithMessage(err, "failed to update the instance list"))
+		} else {
+			p.setInstances(updated)
+			for _, instanceID := range report.ListedInstances {
+				p.instanceChanged(instanceID)
+			}
+			if err = p.registerJiraCommand(p.getConfig().EnableAutocomplete, updated.Len() > 1); err != nil {
+				p.errorf("purgeOrphans: failed to re-register `/%s` command; please re-activate the plugin using the System Console. Error: %s",
+					commandTrigger, err.Error())
+			}
+			p.wsInstancesChanged(updated)
+			purged.ListedInstances = report.ListedInstances
+		}
+	}
+
+	p.client.Log.Info("Purged the data of instances that no longer exist",
+		"connections", len(purged.Connections),
+		"subscriptions", purged.subscriptions(),
+		"templates", purged.templates(),
+		"listed_instances", len(purged.ListedInstances))
+	return purged, firstErr
+}
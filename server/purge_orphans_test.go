@@ -0,0 +1,188 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/kvstore"
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	orphanInstanceURL = "https://gone.example.com"
+	listedInstanceURL = "https://listed.example.com"
+)
+
+type purgeUserStore struct {
+	mockUserStore
+	users   map[types.ID]*User
+	deleted *[]orphanConnection
+}
+
+func (store purgeUserStore) MapUsers(f func(*User) error) error {
+	ids := []string{}
+	for id := range store.users {
+		ids = append(ids, id.String())
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := f(store.users[types.ID(id)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store purgeUserStore) LoadUser(id types.ID) (*User, error) {
+	return store.users[id], nil
+}
+
+func (store purgeUserStore) StoreUser(user *User) error {
+	store.users[user.MattermostUserID] = user
+	return nil
+}
+
+func (store purgeUserStore) DeleteConnection(instanceID, mattermostUserID types.ID) error {
+	*store.deleted = append(*store.deleted, orphanConnection{MattermostUserID: mattermostUserID, InstanceID: instanceID})
+	return nil
+}
+
+type purgeInstanceStore struct {
+	mockInstanceStore
+	instances *Instances
+	broken    types.ID
+}
+
+func (store *purgeInstanceStore) LoadInstance(id types.ID) (Instance, error) {
+	switch id {
+	case testInstance1.InstanceID:
+		return testInstance1, nil
+	case store.broken:
+		return nil, errors.New("database is unavailable")
+	default:
+		return nil, errors.Wrap(kvstore.ErrNotFound, id.String())
+	}
+}
+
+func (store *purgeInstanceStore) LoadInstances() (*Instances, error) {
+	return store.instances, nil
+}
+
+func purgeUser(id types.ID, defaultInstanceID types.ID, instanceIDs ...types.ID) *User {
+	user := NewUser(id)
+	for _, instanceID := range instanceIDs {
+		user.ConnectedInstances.Set(&InstanceCommon{InstanceID: instanceID})
+	}
+	user.DefaultInstanceID = defaultInstanceID
+	return user
+}
+
+func setupPurgeOrphans(t *testing.T, broken types.ID) (*Plugin, *plugintest.API, purgeUserStore, *[]orphanConnection) {
+	deleted := []orphanConnection{}
+	users := purgeUserStore{
+		users: map[types.ID]*User{
+			"user1": purgeUser("user1", orphanInstanceURL, testInstance1.InstanceID, orphanInstanceURL),
+			"user2": purgeUser("user2", "", orphanInstanceURL),
+			"user3": purgeUser("user3", "", testInstance1.InstanceID),
+		},
+		deleted: &deleted,
+	}
+
+	instances := NewInstances()
+	instances.Set(testInstance1.Common())
+	instances.Set(&InstanceCommon{InstanceID: listedInstanceURL, Type: ServerInstanceType})
+
+	subs := NewSubscriptions()
+	subs.Channel.ByID["sub1"] = ChannelSubscription{ID: "sub1", ChannelID: "channel1"}
+	subs.Channel.ByID["sub2"] = ChannelSubscription{ID: "sub2", ChannelID: "channel2"}
+	data, err := json.Marshal(subs)
+	require.NoError(t, err)
+
+	api := &plugintest.API{}
+	api.On("KVGet", keyWithInstanceID(orphanInstanceURL, JiraSubscriptionsKey)).Return(data, nil)
+	api.On("KVGet", keyWithInstanceID(orphanInstanceURL, templateKey)).Return(nil, nil)
+	api.On("KVGet", keyWithInstanceID(listedInstanceURL, JiraSubscriptionsKey)).Return(nil, nil)
+	api.On("KVGet", keyWithInstanceID(listedInstanceURL, templateKey)).Return(nil, nil)
+	api.On("LogInfo", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.userStore = users
+	p.instanceStore = &purgeInstanceStore{instances: instances, broken: broken}
+	return p, api, users, &deleted
+}
+
+func TestFindOrphans(t *testing.T) {
+	t.Run("connections and subscriptions of an instance that no longer exists", func(t *testing.T) {
+		p, _, _, _ := setupPurgeOrphans(t, "")
+
+		report, err := p.findOrphans()
+		require.NoError(t, err)
+		assert.Equal(t, []orphanConnection{
+			{MattermostUserID: "user1", InstanceID: orphanInstanceURL},
+			{MattermostUserID: "user2", InstanceID: orphanInstanceURL},
+		}, report.Connections)
+		assert.Equal(t, []orphanInstanceData{{InstanceID: orphanInstanceURL, Subscriptions: 2}}, report.Instances)
+		assert.Equal(t, []types.ID{listedInstanceURL}, report.ListedInstances)
+		assert.Equal(t, "Found 2 connection(s), 2 subscription(s) and 0 subscription template(s) of instances that no longer exist:\n"+
+			"* https://gone.example.com: 2 connection(s), 2 subscription(s), 0 subscription template(s)\n"+
+			"Found instances that no longer exist in the instance list:\n"+
+			"* https://listed.example.com", report.Markdown())
+	})
+
+	t.Run("failing to load an instance is not an orphan", func(t *testing.T) {
+		p, _, _, _ := setupPurgeOrphans(t, orphanInstanceURL)
+
+		_, err := p.findOrphans()
+		require.Error(t, err)
+	})
+
+	t.Run("nothing to purge", func(t *testing.T) {
+		assert.Equal(t, "No connections, subscriptions or instance list entries reference an instance that no longer exists.", orphanReport{}.Markdown())
+	})
+}
+
+func TestPurgeOrphans(t *testing.T) {
+	p, api, users, deleted := setupPurgeOrphans(t, "")
+	api.On("KVSetWithOptions", keyWithInstanceID(orphanInstanceURL, JiraSubscriptionsKey), []byte(nil), model.PluginKVSetOptions{}).Return(true, nil).Once()
+	api.On("KVSetWithOptions", keyWithInstanceID(orphanInstanceURL, templateKey), []byte(nil), model.PluginKVSetOptions{}).Return(true, nil).Once()
+	api.On("KVSetWithOptions", announcedIssuesKey(orphanInstanceURL, "sub1"), []byte(nil), model.PluginKVSetOptions{}).Return(true, nil).Once()
+	api.On("KVSetWithOptions", announcedIssuesKey(orphanInstanceURL, "sub2"), []byte(nil), model.PluginKVSetOptions{}).Return(true, nil).Once()
+	path, err := filepath.Abs("..")
+	require.NoError(t, err)
+	api.On("GetBundlePath").Return(path, nil)
+	api.On("UnregisterCommand", mock.Anything, mock.Anything).Return(nil)
+	api.On("RegisterCommand", mock.Anything).Return(nil)
+	api.On("PublishWebSocketEvent", websocketEventInstanceStatus, mock.Anything, mock.Anything)
+	api.On("PublishPluginClusterEvent", mock.AnythingOfType("model.PluginClusterEvent"), mock.AnythingOfType("model.PluginClusterEventSendOptions")).Return(nil)
+
+	report, err := p.findOrphans()
+	require.NoError(t, err)
+
+	purged, err := p.purgeOrphans(report)
+	require.NoError(t, err)
+	assert.Equal(t, *report, purged)
+	assert.Equal(t, report.Connections, *deleted)
+	api.AssertExpectations(t)
+
+	user1 := users.users["user1"]
+	assert.Equal(t, []types.ID{testInstance1.InstanceID}, user1.ConnectedInstances.IDs())
+	assert.Equal(t, types.ID(""), user1.DefaultInstanceID)
+	assert.True(t, users.users["user2"].ConnectedInstances.IsEmpty())
+	assert.Equal(t, []types.ID{testInstance1.InstanceID}, users.users["user3"].ConnectedInstances.IDs())
+	assert.Equal(t, []types.ID{testInstance1.InstanceID}, p.loadedInstances().IDs())
+}
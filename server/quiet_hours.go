@@ -0,0 +1,281 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	prefixQuietHoursQueue = "quiet_hours_queue_"
+
+	// keyQuietHoursQueues is the index of the keys of the queues.
+	keyQuietHoursQueues = "quiet_hours_queues"
+
+	quietHoursFlushInterval = 5 * time.Minute
+
+	// The oldest notifications are dropped once a queue holds this many.
+	quietHoursMaxQueued = 100
+
+	quietHoursClockLayout = "15:04"
+	quietHoursQueueFlag   = "--queue"
+)
+
+// QuietHours is a daily window, in the user's timezone, during which no
+// notifications are sent to the user. The window may span midnight.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+
+	// Queue holds the notifications until the window ends, instead of
+	// dropping them.
+	Queue bool `json:"queue,omitempty"`
+}
+
+func (q *QuietHours) String() string {
+	if q == nil {
+		return settingOff
+	}
+	s := q.Start + "-" + q.End
+	if q.Queue {
+		s += ", notifications are delivered afterwards"
+	}
+	return s
+}
+
+// parseQuietHoursClock returns the number of minutes since midnight.
+func parseQuietHoursClock(s string) (int, error) {
+	t, err := time.Parse(quietHoursClockLayout, s)
+	if err != nil {
+		return 0, errors.Errorf("%q is not a valid time, please use the 24-hour HH:MM format", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// IsActive reports whether t, in the user's timezone, falls within the window.
+func (q *QuietHours) IsActive(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+	start, err := parseQuietHoursClock(q.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseQuietHoursClock(q.End)
+	if err != nil {
+		return false
+	}
+
+	now := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return start <= now && now < end
+	}
+	return now >= start || now < end
+}
+
+// userLocation returns the timezone that the user chose in Mattermost, or the
+// timezone of the Mattermost server if the user did not choose any.
+func (p *Plugin) userLocation(mattermostUserID types.ID) *time.Location {
+	user, err := p.client.User.Get(mattermostUserID.String())
+	if err != nil {
+		return time.Local
+	}
+	name := user.GetPreferredTimezone()
+	if name == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+func (p *Plugin) inQuietHours(mattermostUserID types.ID, settings *ConnectionSettings, now time.Time) bool {
+	if settings == nil || settings.QuietHours == nil {
+		return false
+	}
+	return settings.QuietHours.IsActive(now.In(p.userLocation(mattermostUserID)))
+}
+
+type queuedNotification struct {
+	Message  string `json:"message"`
+	PostType string `json:"post_type,omitempty"`
+
+	PostPriority string `json:"post_priority,omitempty"`
+	IssueKey     string `json:"issue_key,omitempty"`
+}
+
+type quietHoursQueue struct {
+	InstanceID       types.ID             `json:"instance_id"`
+	MattermostUserID types.ID             `json:"mattermost_user_id"`
+	Notifications    []queuedNotification `json:"notifications"`
+}
+
+func quietHoursQueueKey(instanceID, mattermostUserID types.ID) string {
+	return prefixQuietHoursQueue + keyWithInstanceID(instanceID, mattermostUserID)
+}
+
+func (p *Plugin) queueQuietHoursNotification(instanceID, mattermostUserID types.ID, issueKey, message, postType, postPriority string) error {
+	key := quietHoursQueueKey(instanceID, mattermostUserID)
+	created := false
+	err := p.client.KV.SetAtomicWithRetries(key, func(initialBytes []byte) (interface{}, error) {
+		created = len(initialBytes) == 0
+		queue := quietHoursQueue{}
+		if len(initialBytes) != 0 {
+			if err := json.Unmarshal(initialBytes, &queue); err != nil {
+				return nil, err
+			}
+		}
+		queue.InstanceID = instanceID
+		queue.MattermostUserID = mattermostUserID
+		queue.Notifications = append(queue.Notifications, queuedNotification{message, postType, postPriority, issueKey})
+		if len(queue.Notifications) > quietHoursMaxQueued {
+			queue.Notifications = queue.Notifications[len(queue.Notifications)-quietHoursMaxQueued:]
+		}
+		return json.Marshal(&queue)
+	})
+	if err != nil || !created {
+		return err
+	}
+	return p.updateIndex(keyQuietHoursQueues, key, true)
+}
+
+// takeQuietHoursQueue removes the queue from the store, and returns it.
+func (p *Plugin) takeQuietHoursQueue(key string) (*quietHoursQueue, error) {
+	var queue *quietHoursQueue
+	err := p.client.KV.SetAtomicWithRetries(key, func(initialBytes []byte) (interface{}, error) {
+		queue = nil
+		if len(initialBytes) == 0 {
+			return nil, nil
+		}
+		queue = &quietHoursQueue{}
+		if err := json.Unmarshal(initialBytes, queue); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	})
+	return queue, err
+}
+
+// deliverQuietHoursQueues sends the notifications held for the users whose
+// quiet hours are over.
+func (p *Plugin) deliverQuietHoursQueues(now time.Time) {
+	keys, err := p.loadIndex(keyQuietHoursQueues)
+	if err != nil {
+		p.client.Log.Warn("Failed to list the notifications held during quiet hours", "error", err.Error())
+		return
+	}
+
+	for _, key := range keys {
+		var queue quietHoursQueue
+		if err = p.client.KV.Get(key, &queue); err != nil {
+			continue
+		}
+		if queue.MattermostUserID == "" {
+			p.removeQuietHoursQueueIndex(key)
+			continue
+		}
+
+		connection, err := p.userStore.LoadConnection(queue.InstanceID, queue.MattermostUserID)
+		if err == nil && p.inQuietHours(queue.MattermostUserID, connection.Settings, now) {
+			continue
+		}
+
+		// The queue is removed from the index first, a notification queued
+		// after it is taken adds it back.
+		p.removeQuietHoursQueueIndex(key)
+		taken, err := p.takeQuietHoursQueue(key)
+		if err != nil {
+			p.client.Log.Warn("Failed to take the notifications held during quiet hours", "user", queue.MattermostUserID, "error", err.Error())
+			if err = p.updateIndex(keyQuietHoursQueues, key, true); err != nil {
+				p.client.Log.Warn("Failed to index the notifications held during quiet hours", "user", queue.MattermostUserID, "error", err.Error())
+			}
+			continue
+		}
+		// Disconnected or muted since, these are no longer wanted.
+		if taken == nil || connection == nil {
+			continue
+		}
+		if on, _, _ := connection.Settings.notifications(p.userSettings(queue.MattermostUserID)); !on {
+			continue
+		}
+
+		p.deliverQuietHoursQueue(taken)
+	}
+}
+
+func (p *Plugin) removeQuietHoursQueueIndex(key string) {
+	if err := p.updateIndex(keyQuietHoursQueues, key, false); err != nil {
+		p.client.Log.Warn("Failed to update the index of the notifications held during quiet hours", "key", key, "error", err.Error())
+	}
+}
+
+func (p *Plugin) deliverQuietHoursQueue(queue *quietHoursQueue) {
+	if len(queue.Notifications) == 0 {
+		return
+	}
+	_, err := p.postBotDM(queue.MattermostUserID, fmt.Sprintf("Your quiet hours are over, here are the %d Jira notification(s) you received meanwhile.", len(queue.Notifications)), "", "")
+	if err != nil {
+		p.client.Log.Warn("Failed to deliver the notifications held during quiet hours", "user", queue.MattermostUserID, "error", err.Error())
+		return
+	}
+	for _, n := range queue.Notifications {
+		if n.IssueKey != "" && p.isIssueSnoozed(queue.InstanceID, queue.MattermostUserID, n.IssueKey) {
+			continue
+		}
+		if _, err = p.postBotIssueDM(queue.InstanceID, queue.MattermostUserID, n.IssueKey, n.Message, n.PostType, n.PostPriority); err != nil {
+			p.client.Log.Warn("Failed to deliver a notification held during quiet hours", "user", queue.MattermostUserID, "error", err.Error())
+		}
+	}
+}
+
+func (p *Plugin) settingsQuietHours(header *model.CommandArgs, instanceID, mattermostUserID types.ID, connection *Connection, args []string) *model.CommandResponse {
+	const helpText = "`/jira settings notifications quiet-hours [start] [end] [--queue]`, or `/jira settings notifications quiet-hours off`\n" +
+		"* [start] and [end] are times of the day in your timezone, in the 24-hour HH:MM format, e.g. `22:00 07:00`\n" +
+		"* With `--queue`, the notifications are delivered when the quiet hours end, instead of being dropped."
+
+	var quietHours *QuietHours
+	switch {
+	case len(args) == 1 && args[0] == settingOff:
+	case len(args) == 2 || (len(args) == 3 && args[2] == quietHoursQueueFlag):
+		for _, arg := range args[:2] {
+			if _, err := parseQuietHoursClock(arg); err != nil {
+				return p.responsef(header, "%v.\n%s", err, helpText)
+			}
+		}
+		if args[0] == args[1] {
+			return p.responsef(header, "The quiet hours must start and end at different times.\n%s", helpText)
+		}
+		quietHours = &QuietHours{
+			Start: args[0],
+			End:   args[1],
+			Queue: len(args) == 3,
+		}
+	default:
+		return p.responsef(header, helpText)
+	}
+
+	if connection.Settings == nil {
+		connection.Settings = &ConnectionSettings{}
+	}
+	connection.Settings.QuietHours = quietHours
+	if err := p.userStore.StoreConnection(instanceID, mattermostUserID, connection); err != nil {
+		p.errorf("settingsQuietHours, err: %v", err)
+		return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
+	}
+
+	if quietHours == nil {
+		return p.responsef(header, "Settings updated. Quiet hours off.")
+	}
+	return p.responsef(header, "Settings updated. Quiet hours %s, in the %s timezone.",
+		quietHours.String(), p.userLocation(mattermostUserID).String())
+}
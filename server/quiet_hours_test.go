@@ -0,0 +1,48 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuietHoursIsActive(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 3, 10, hour, minute, 0, 0, time.UTC)
+	}
+
+	for name, tc := range map[string]struct {
+		quietHours *QuietHours
+		now        time.Time
+		expected   bool
+	}{
+		"not set":                    {quietHours: nil, now: at(23, 0), expected: false},
+		"within a daytime window":    {quietHours: &QuietHours{Start: "12:00", End: "13:30"}, now: at(13, 29), expected: true},
+		"end of a daytime window":    {quietHours: &QuietHours{Start: "12:00", End: "13:30"}, now: at(13, 30), expected: false},
+		"before a daytime window":    {quietHours: &QuietHours{Start: "12:00", End: "13:30"}, now: at(11, 59), expected: false},
+		"overnight, before midnight": {quietHours: &QuietHours{Start: "22:00", End: "07:00"}, now: at(23, 15), expected: true},
+		"overnight, after midnight":  {quietHours: &QuietHours{Start: "22:00", End: "07:00"}, now: at(6, 59), expected: true},
+		"overnight, during the day":  {quietHours: &QuietHours{Start: "22:00", End: "07:00"}, now: at(7, 0), expected: false},
+		"invalid window":             {quietHours: &QuietHours{Start: "late", End: "07:00"}, now: at(23, 0), expected: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.quietHours.IsActive(tc.now))
+		})
+	}
+}
+
+func TestParseQuietHoursClock(t *testing.T) {
+	minutes, err := parseQuietHoursClock("07:45")
+	require.NoError(t, err)
+	assert.Equal(t, 7*60+45, minutes)
+
+	for _, invalid := range []string{"7pm", "25:00", "12:60", ""} {
+		_, err = parseQuietHoursClock(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
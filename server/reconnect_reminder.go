@@ -0,0 +1,148 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// reconnectReminderInterval is the minimum time between two reconnect
+// reminders sent to the same user for the same Jira instance.
+const reconnectReminderInterval = 7 * 24 * time.Hour
+
+const (
+	// reconnectReminderProbes is how many connections are probed at once.
+	reconnectReminderProbes = 8
+
+	// reconnectReminderProbeTimeout is how long a probe is waited for, a
+	// connection that does not answer in time is not reported as broken.
+	reconnectReminderProbeTimeout = 15 * time.Second
+)
+
+type reconnectReminderSummary struct {
+	Checked   int
+	Broken    int
+	Reminded  int
+	Throttled int
+}
+
+func (s reconnectReminderSummary) String() string {
+	return fmt.Sprintf("Checked %d connection(s), %d of them broken. Sent %d reconnect reminder(s); %d user(s) were reminded in the last %d days and were skipped.",
+		s.Checked, s.Broken, s.Reminded, s.Throttled, int(reconnectReminderInterval.Hours()/24))
+}
+
+// reconnectReminderDue reports whether a user with a broken connection may be
+// reminded again.
+func reconnectReminderDue(connection *Connection, now time.Time) bool {
+	if connection.LastReconnectReminder == 0 {
+		return true
+	}
+	return now.Sub(time.Unix(connection.LastReconnectReminder, 0)) >= reconnectReminderInterval
+}
+
+type reconnectReminderProbe struct {
+	instanceID       types.ID
+	mattermostUserID types.ID
+	status           string
+}
+
+// probeConnections probes the connections concurrently, and sets their
+// status. A probe that times out leaves the status of its connection as
+// failed.
+func probeConnections(probes []reconnectReminderProbe, probe func(instanceID, mattermostUserID types.ID) string, timeout time.Duration) {
+	queue := make(chan *reconnectReminderProbe)
+	var wg sync.WaitGroup
+	for i := 0; i < reconnectReminderProbes; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pr := range queue {
+				done := make(chan string, 1)
+				go func(pr *reconnectReminderProbe) {
+					done <- probe(pr.instanceID, pr.mattermostUserID)
+				}(pr)
+
+				select {
+				case pr.status = <-done:
+				case <-time.After(timeout):
+					pr.status = AuthStatusFailed
+				}
+			}
+		}()
+	}
+	for i := range probes {
+		queue <- &probes[i]
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// sendReconnectReminders probes every connection of every installed instance,
+// and sends a DM with a reconnect prompt to the users whose connection has
+// expired or was revoked. Connections that fail for other reasons, e.g. Jira
+// being unavailable or not answering in time, are not reported to the user.
+func (p *Plugin) sendReconnectReminders(now time.Time) (reconnectReminderSummary, error) {
+	summary := reconnectReminderSummary{}
+	probes := []reconnectReminderProbe{}
+	err := p.userStore.MapUsers(func(user *User) error {
+		for _, instanceID := range user.ConnectedInstances.IDs() {
+			probes = append(probes, reconnectReminderProbe{
+				instanceID:       instanceID,
+				mattermostUserID: user.MattermostUserID,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return summary, err
+	}
+
+	probeConnections(probes, func(instanceID, mattermostUserID types.ID) string {
+		status, _ := p.probeConnection(instanceID, mattermostUserID)
+		return status
+	}, reconnectReminderProbeTimeout)
+
+	for _, pr := range probes {
+		summary.Checked++
+		if pr.status != AuthStatusExpired && pr.status != AuthStatusRevoked {
+			continue
+		}
+		summary.Broken++
+
+		connection, err := p.userStore.LoadConnection(pr.instanceID, pr.mattermostUserID)
+		if err != nil {
+			p.infof("sendReconnectReminders: failed to load connection: %v", err)
+			continue
+		}
+		if !reconnectReminderDue(connection, now) {
+			summary.Throttled++
+			continue
+		}
+
+		err = p.sendReconnectReminder(pr.instanceID, pr.mattermostUserID, pr.status)
+		if err != nil {
+			p.infof("sendReconnectReminders: failed to send a reminder to %s: %v", pr.mattermostUserID, err)
+			continue
+		}
+		summary.Reminded++
+
+		connection.LastReconnectReminder = now.Unix()
+		err = p.userStore.StoreConnection(pr.instanceID, pr.mattermostUserID, connection)
+		if err != nil {
+			p.infof("sendReconnectReminders: failed to store the reminder time: %v", err)
+		}
+	}
+	return summary, nil
+}
+
+func (p *Plugin) sendReconnectReminder(instanceID, mattermostUserID types.ID, status string) error {
+	_, err := p.CreateBotDMtoMMUserID(mattermostUserID.String(),
+		"Your connection to the Jira instance %s is no longer working (**%s**), and Jira commands and actions in Mattermost will fail until you reconnect. %s",
+		instanceID, status, p.reconnectPromptMessage(instanceID))
+	return err
+}
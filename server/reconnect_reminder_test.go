@@ -0,0 +1,80 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+func TestReconnectReminderDue(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	for name, tc := range map[string]struct {
+		lastReminder time.Time
+		expected     bool
+	}{
+		"never reminded": {
+			expected: true,
+		},
+		"reminded recently": {
+			lastReminder: now.Add(-time.Hour),
+			expected:     false,
+		},
+		"reminded before the interval": {
+			lastReminder: now.Add(-reconnectReminderInterval),
+			expected:     true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			connection := &Connection{}
+			if !tc.lastReminder.IsZero() {
+				connection.LastReconnectReminder = tc.lastReminder.Unix()
+			}
+			assert.Equal(t, tc.expected, reconnectReminderDue(connection, now))
+		})
+	}
+}
+
+func TestReconnectReminderSummary(t *testing.T) {
+	summary := reconnectReminderSummary{Checked: 5, Broken: 3, Reminded: 2, Throttled: 1}
+	assert.Equal(t, "Checked 5 connection(s), 3 of them broken. Sent 2 reconnect reminder(s); 1 user(s) were reminded in the last 7 days and were skipped.", summary.String())
+}
+
+func TestProbeConnections(t *testing.T) {
+	probes := []reconnectReminderProbe{}
+	for _, userID := range []types.ID{"expired", "hung", "ok", "revoked"} {
+		probes = append(probes, reconnectReminderProbe{instanceID: "jiraurl1", mattermostUserID: userID})
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	var lock sync.Mutex
+	probed := map[types.ID]bool{}
+	probeConnections(probes, func(instanceID, mattermostUserID types.ID) string {
+		lock.Lock()
+		probed[mattermostUserID] = true
+		lock.Unlock()
+		switch mattermostUserID {
+		case "expired":
+			return AuthStatusExpired
+		case "revoked":
+			return AuthStatusRevoked
+		case "hung":
+			<-release
+		}
+		return AuthStatusOK
+	}, 50*time.Millisecond)
+
+	assert.Len(t, probed, 4)
+	assert.Equal(t, AuthStatusExpired, probes[0].status)
+	assert.Equal(t, AuthStatusFailed, probes[1].status)
+	assert.Equal(t, AuthStatusOK, probes[2].status)
+	assert.Equal(t, AuthStatusRevoked, probes[3].status)
+}
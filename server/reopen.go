@@ -0,0 +1,173 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const statusCategoryDone = "done"
+
+// reopenTransitionNames are the names usually given to the transitions that
+// reopen an issue, from the most to the least likely.
+var reopenTransitionNames = []string{"reopen", "todo", "inprogress"}
+
+func reopenScore(t jira.Transition) int {
+	names := []string{
+		strings.ToLower(strings.Join(strings.Fields(t.Name), "")),
+		strings.ToLower(strings.Join(strings.Fields(t.To.Name), "")),
+	}
+	for i, candidate := range reopenTransitionNames {
+		for _, name := range names {
+			if strings.Contains(name, candidate) {
+				return len(reopenTransitionNames) - i
+			}
+		}
+	}
+	return 0
+}
+
+// reopenTransitions returns the transitions to a status that is not done,
+// the most likely reopen transitions first.
+func reopenTransitions(transitions []jira.Transition) []jira.Transition {
+	candidates := []jira.Transition{}
+	for _, t := range transitions {
+		if t.To.StatusCategory.Key != statusCategoryDone {
+			candidates = append(candidates, t)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return reopenScore(candidates[i]) > reopenScore(candidates[j])
+	})
+	return candidates
+}
+
+// pickReopenTransition returns the transition to apply, or false if the user
+// needs to choose between the candidates.
+func pickReopenTransition(candidates []jira.Transition) (jira.Transition, bool) {
+	switch {
+	case len(candidates) == 1:
+		return candidates[0], true
+	case len(candidates) > 1 && reopenScore(candidates[0]) > 0 && reopenScore(candidates[0]) > reopenScore(candidates[1]):
+		return candidates[0], true
+	default:
+		return jira.Transition{}, false
+	}
+}
+
+func executeReopen(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	instanceURL, args, err := p.parseCommandFlagInstanceURL(args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	if len(args) != 1 {
+		return p.responsef(header, "Please specify an issue key in the form `/jira reopen <issue-key>`.")
+	}
+	issueKey := strings.ToUpper(args[0])
+	mattermostUserID := types.ID(header.UserId)
+
+	_, instanceID, err := p.ResolveUserInstanceURL(mattermostUserID, instanceURL)
+	if err != nil {
+		return p.responsef(header, "Failed to identify Jira instance %s. Error: %v.", instanceURL, err)
+	}
+
+	// On success, the user is sent either the reopened issue or a picker.
+	_, err = p.ReopenIssue(&InTransitionIssue{
+		InstanceID:       instanceID,
+		mattermostUserID: mattermostUserID,
+		PostToChannelID:  header.ChannelId,
+		IssueKey:         issueKey,
+	})
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	return &model.CommandResponse{}
+}
+
+// ReopenIssue moves a done issue back to a status that is not done. If it is
+// not clear which transition does that, the user is sent a picker, and an
+// empty message is returned.
+func (p *Plugin) ReopenIssue(in *InTransitionIssue) (string, error) {
+	client, instance, _, err := p.getClient(in.InstanceID, in.mattermostUserID)
+	if err != nil {
+		return "", err
+	}
+
+	issue, err := client.GetIssue(in.IssueKey, &jira.GetQueryOptions{Fields: "status"})
+	if err != nil {
+		if StatusCode(err) == http.StatusNotFound {
+			return "", errors.New("we couldn't find the issue key. Please confirm the issue key and try again. You may not have permissions to access this issue")
+		}
+		return "", errors.WithMessage(err, "request to Jira failed")
+	}
+	if issue.Fields != nil && issue.Fields.Status != nil && issue.Fields.Status.StatusCategory.Key != statusCategoryDone {
+		return "", errors.Errorf("%s is not done, its status is `%s`. There is nothing to reopen", in.IssueKey, issue.Fields.Status.Name)
+	}
+
+	transitions, err := client.GetTransitions(in.IssueKey)
+	if err != nil {
+		return "", errors.New("we couldn't find the issue key. Please confirm the issue key and try again. You may not have permissions to access this issue")
+	}
+
+	candidates := reopenTransitions(transitions)
+	if len(candidates) == 0 {
+		status := ""
+		if issue.Fields != nil && issue.Fields.Status != nil {
+			status = fmt.Sprintf(" from its current status `%s`", issue.Fields.Status.Name)
+		}
+		return "", errors.Errorf("%s can't be reopened%s: its workflow has no transition to an open status that you can use", in.IssueKey, status)
+	}
+
+	transition, ok := pickReopenTransition(candidates)
+	if !ok {
+		p.sendReopenPicker(in, instance, candidates)
+		return "", nil
+	}
+	return p.doTransition(in, client, instance, transition)
+}
+
+// sendReopenPicker lets the user pick the reopen transition; the choice is
+// handled like a transition picked on an issue card.
+func (p *Plugin) sendReopenPicker(in *InTransitionIssue, instance Instance, candidates []jira.Transition) {
+	options := []*model.PostActionOptions{}
+	for _, t := range candidates {
+		options = append(options, &model.PostActionOptions{
+			Text:  t.Name,
+			Value: t.To.Name,
+		})
+	}
+
+	post := makePost(p.getUserID(), in.PostToChannelID, "")
+	post.AddProp("attachments", []*model.SlackAttachment{
+		{
+			Text: fmt.Sprintf("[%s](%s/browse/%s) can be reopened in several ways. Please pick one:",
+				in.IssueKey, instance.GetJiraBaseURL(), in.IssueKey),
+			Actions: []*model.PostAction{
+				{
+					Name:    "Reopen issue",
+					Type:    "select",
+					Options: options,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("/plugins/%s%s%s", manifest.Id, routeAPI, routeIssueTransition),
+						Context: map[string]interface{}{
+							"issue_key":   in.IssueKey,
+							"instance_id": in.InstanceID.String(),
+						},
+					},
+				},
+			},
+		},
+	})
+	p.client.Post.SendEphemeralPost(in.mattermostUserID.String(), post)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeReopenTransition(name, toStatus, category string) jira.Transition {
+	return jira.Transition{
+		ID:   name,
+		Name: name,
+		To: jira.Status{
+			Name:           toStatus,
+			StatusCategory: jira.StatusCategory{Key: category},
+		},
+	}
+}
+
+func TestReopenTransitions(t *testing.T) {
+	for name, tc := range map[string]struct {
+		transitions []jira.Transition
+		expected    string
+		expectedOK  bool
+		candidates  int
+	}{
+		"reopen transition is preferred": {
+			transitions: []jira.Transition{
+				makeReopenTransition("Start progress", "In Progress", "indeterminate"),
+				makeReopenTransition("Reopen issue", "Reopened", "new"),
+				makeReopenTransition("Close", "Closed", statusCategoryDone),
+			},
+			expected:   "Reopen issue",
+			expectedOK: true,
+			candidates: 2,
+		},
+		"single candidate": {
+			transitions: []jira.Transition{
+				makeReopenTransition("Back to backlog", "Backlog", "new"),
+				makeReopenTransition("Close", "Closed", statusCategoryDone),
+			},
+			expected:   "Back to backlog",
+			expectedOK: true,
+			candidates: 1,
+		},
+		"several unknown candidates": {
+			transitions: []jira.Transition{
+				makeReopenTransition("Back to backlog", "Backlog", "new"),
+				makeReopenTransition("Needs review", "Review", "indeterminate"),
+			},
+			expectedOK: false,
+			candidates: 2,
+		},
+		"as likely candidates": {
+			transitions: []jira.Transition{
+				makeReopenTransition("Reopen", "Open", "new"),
+				makeReopenTransition("Reopen and start", "In Progress", "indeterminate"),
+			},
+			expectedOK: false,
+			candidates: 2,
+		},
+		"no candidate": {
+			transitions: []jira.Transition{
+				makeReopenTransition("Close", "Closed", statusCategoryDone),
+			},
+			expectedOK: false,
+			candidates: 0,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			candidates := reopenTransitions(tc.transitions)
+			assert.Len(t, candidates, tc.candidates)
+
+			transition, ok := pickReopenTransition(candidates)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expected, transition.Name)
+		})
+	}
+}
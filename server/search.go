@@ -0,0 +1,367 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	searchFormatCards = "cards"
+	searchFormatTable = "table"
+
+	// searchMaxCards and searchMaxRows are the number of issues of a page of
+	// results, the table being compact enough for more.
+	searchMaxCards = 10
+	searchMaxRows  = 25
+
+	searchSortKey      = "key"
+	searchSortSummary  = "summary"
+	searchSortStatus   = "status"
+	searchSortAssignee = "assignee"
+	searchSortPriority = "priority"
+)
+
+var searchSortFields = []string{searchSortKey, searchSortSummary, searchSortStatus, searchSortAssignee, searchSortPriority}
+
+type searchOptions struct {
+	JQL    string
+	Format string
+	Sort   string
+}
+
+// parseSearchArgs parses `[--format cards|table] [--sort field] <jql>`, the
+// flags taking their value after a space or an equal sign. Without a query,
+// the default JQL of the instance is used.
+func parseSearchArgs(args []string, defaultJQL string) (searchOptions, error) {
+	opts := searchOptions{Format: searchFormatCards}
+	jql := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var flag, value string
+		switch {
+		case arg == "--format" || arg == "--sort":
+			if i+1 >= len(args) {
+				return opts, errors.Errorf("%s requires a value", arg)
+			}
+			flag, value = arg, args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--format=") || strings.HasPrefix(arg, "--sort="):
+			flag, value, _ = strings.Cut(arg, "=")
+		default:
+			jql = append(jql, arg)
+			continue
+		}
+
+		value = strings.ToLower(value)
+		if flag == "--format" {
+			if value != searchFormatCards && value != searchFormatTable {
+				return opts, errors.Errorf("`%s` is not a format, use `cards` or `table`", value)
+			}
+			opts.Format = value
+			continue
+		}
+		valid := false
+		for _, field := range searchSortFields {
+			valid = valid || field == value
+		}
+		if !valid {
+			return opts, errors.Errorf("`%s` is not a column to sort by, use one of %s", value, strings.Join(searchSortFields, ", "))
+		}
+		opts.Sort = value
+	}
+
+	opts.JQL = strings.TrimSpace(strings.Join(jql, " "))
+	if opts.JQL == "" {
+		opts.JQL = defaultJQL
+	}
+	if opts.JQL == "" {
+		return opts, errors.New("please specify a JQL query")
+	}
+	return opts, nil
+}
+
+// searchColumn returns the value of an issue in a column of the table.
+func searchColumn(issue *jira.Issue, column string) string {
+	if issue.Fields == nil {
+		if column == searchSortKey {
+			return issue.Key
+		}
+		return ""
+	}
+	switch column {
+	case searchSortKey:
+		return issue.Key
+	case searchSortSummary:
+		return issue.Fields.Summary
+	case searchSortStatus:
+		if issue.Fields.Status != nil {
+			return issue.Fields.Status.Name
+		}
+	case searchSortAssignee:
+		if issue.Fields.Assignee != nil {
+			return issue.Fields.Assignee.DisplayName
+		}
+	case searchSortPriority:
+		if issue.Fields.Priority != nil {
+			return issue.Fields.Priority.Name
+		}
+	}
+	return ""
+}
+
+// issueKeyLess orders the issue keys by project, then by number, so that
+// KT-9 comes before KT-10.
+func issueKeyLess(a, b string) bool {
+	projectA, numberA, _ := strings.Cut(a, "-")
+	projectB, numberB, _ := strings.Cut(b, "-")
+	if projectA != projectB {
+		return projectA < projectB
+	}
+	na, errA := strconv.Atoi(numberA)
+	nb, errB := strconv.Atoi(numberB)
+	if errA != nil || errB != nil {
+		return numberA < numberB
+	}
+	return na < nb
+}
+
+// priorityRank returns the rank of the priority of an issue in the priorities
+// of the instance, from the highest. The priorities that the instance does not
+// list come next, and the issues without one last.
+func priorityRank(priorities []jira.Priority, issue *jira.Issue) int {
+	if issue.Fields == nil || issue.Fields.Priority == nil {
+		return math.MaxInt
+	}
+	if rank := priorityRankOf(priorities, issue.Fields.Priority.ID, issue.Fields.Priority.Name); rank >= 0 {
+		return rank
+	}
+	return math.MaxInt - 1
+}
+
+// sortSearchResults orders the fetched issues by a column, when sorting by
+// priority from the highest, in the order of the priorities of the instance.
+// Empty values come last.
+func sortSearchResults(issues []jira.Issue, column string, priorities []jira.Priority) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		switch column {
+		case searchSortKey:
+			return issueKeyLess(issues[i].Key, issues[j].Key)
+		case searchSortPriority:
+			return priorityRank(priorities, &issues[i]) < priorityRank(priorities, &issues[j])
+		}
+		a := strings.ToLower(searchColumn(&issues[i], column))
+		b := strings.ToLower(searchColumn(&issues[j], column))
+		if a == "" || b == "" {
+			return a != "" && b == ""
+		}
+		return a < b
+	})
+}
+
+var markdownTableCellReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"\r\n", " ",
+	"\n", " ",
+	"\r", " ",
+)
+
+// escapeTableCell keeps a value on one line, and from closing its cell.
+func escapeTableCell(value string) string {
+	return markdownTableCellReplacer.Replace(value)
+}
+
+// mdSearchTable renders the issues as a markdown table.
+func mdSearchTable(jiraBaseURL string, issues []jira.Issue) string {
+	var b strings.Builder
+	b.WriteString("| Key | Summary | Status | Assignee | Priority |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for i := range issues {
+		issue := &issues[i]
+		assignee := searchColumn(issue, searchSortAssignee)
+		if assignee == "" {
+			assignee = "Unassigned"
+		}
+		fmt.Fprintf(&b, "| [%s](%s/browse/%s) | %s | %s | %s | %s |\n",
+			escapeTableCell(issue.Key), jiraBaseURL, issue.Key,
+			escapeTableCell(truncate(searchColumn(issue, searchSortSummary), maxIssueSummaryLength)),
+			escapeTableCell(searchColumn(issue, searchSortStatus)),
+			escapeTableCell(assignee),
+			escapeTableCell(searchColumn(issue, searchSortPriority)))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// mdSearchTotal tells which of the issues matching the query are shown, the
+// page starting at the issue startAt, from 0.
+func mdSearchTotal(startAt, shown, total int) string {
+	if startAt == 0 && total <= shown {
+		return fmt.Sprintf("Found %d issue(s).", shown)
+	}
+	return fmt.Sprintf("Showing %d-%d of %d issues.", startAt+1, startAt+shown, total)
+}
+
+func searchPageSize(format string) int {
+	if format == searchFormatTable {
+		return searchMaxRows
+	}
+	return searchMaxCards
+}
+
+// searchPageAction returns the button that shows another page of the results.
+func searchPageAction(name string, instanceID types.ID, opts searchOptions, startAt int, rootID string) *model.PostAction {
+	return &model.PostAction{
+		Name: name,
+		Type: model.PostActionTypeButton,
+		Integration: &model.PostActionIntegration{
+			URL: fmt.Sprintf("/plugins/%s%s%s", manifest.Id, routeAPI, routeSearchPage),
+			Context: map[string]interface{}{
+				"instance_id": instanceID.String(),
+				"jql":         opts.JQL,
+				"format":      opts.Format,
+				"sort":        opts.Sort,
+				"start_at":    strconv.Itoa(startAt),
+				"root_id":     rootID,
+			},
+		},
+	}
+}
+
+// makeSearchPost renders the page of the issues matching the query that starts
+// at the issue startAt, with the buttons to the previous and next pages.
+func (p *Plugin) makeSearchPost(instance Instance, client Client, opts searchOptions, startAt int, channelID, rootID string) (*model.Post, error) {
+	pageSize := searchPageSize(opts.Format)
+	searchOpts := &jira.SearchOptions{StartAt: startAt, MaxResults: pageSize}
+	if opts.Format == searchFormatTable {
+		searchOpts.Fields = []string{"summary", "status", "assignee", "priority"}
+	}
+	issues, total, err := client.SearchIssuesWithTotal(opts.JQL, searchOpts)
+	if err != nil {
+		return nil, err
+	}
+	post := &model.Post{
+		UserId:    p.getUserID(),
+		ChannelId: channelID,
+		RootId:    rootID,
+	}
+	if len(issues) == 0 {
+		post.Message = "No issues match the query."
+		return post, nil
+	}
+	if opts.Sort != "" {
+		var priorities []jira.Priority
+		if opts.Sort == searchSortPriority {
+			priorities, err = p.getPriorityOrder(instance.GetID(), client)
+			if err != nil {
+				return nil, err
+			}
+		}
+		sortSearchResults(issues, opts.Sort, priorities)
+	}
+
+	footer := mdSearchTotal(startAt, len(issues), total)
+	if opts.Sort != "" && total > len(issues) {
+		footer += fmt.Sprintf(" Only the issues of the page are sorted by %s.", opts.Sort)
+	}
+
+	attachments := []*model.SlackAttachment{}
+	if opts.Format == searchFormatTable {
+		post.Message = mdSearchTable(instance.GetJiraBaseURL(), issues) + "\n\n" + footer
+	} else {
+		post.Message = footer
+		for i := range issues {
+			cards, err := asSlackAttachment(instance, client, &issues[i], false)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "failed to render %s", issues[i].Key)
+			}
+			attachments = append(attachments, cards...)
+		}
+	}
+
+	actions := []*model.PostAction{}
+	if startAt > 0 {
+		actions = append(actions, searchPageAction("Previous page", instance.GetID(), opts, max(startAt-pageSize, 0), rootID))
+	}
+	if next := startAt + len(issues); next < total {
+		actions = append(actions, searchPageAction("Next page", instance.GetID(), opts, next, rootID))
+	}
+	if len(actions) > 0 {
+		attachments = append(attachments, &model.SlackAttachment{Actions: actions})
+	}
+	if len(attachments) > 0 {
+		model.ParseSlackAttachment(post, attachments)
+	}
+	return post, nil
+}
+
+// httpSearchPage shows another page of the results of `/jira search`, in
+// place of the page of the post.
+func (p *Plugin) httpSearchPage(w http.ResponseWriter, r *http.Request) (int, error) {
+	var requestData model.PostActionIntegrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+		return respondErr(w, http.StatusBadRequest, errors.New("unmarshall the body"))
+	}
+	if requestData.UserId == "" {
+		return respondErr(w, http.StatusUnauthorized, errors.New("not authorized"))
+	}
+	instanceID, _ := requestData.Context["instance_id"].(string)
+	jql, _ := requestData.Context["jql"].(string)
+	format, _ := requestData.Context["format"].(string)
+	sortBy, _ := requestData.Context["sort"].(string)
+	startAtValue, _ := requestData.Context["start_at"].(string)
+	startAt, err := strconv.Atoi(startAtValue)
+	if instanceID == "" || jql == "" || err != nil || startAt < 0 {
+		return respondErr(w, http.StatusBadRequest, errors.New("no search was found in context data"))
+	}
+
+	client, instance, _, err := p.getClient(types.ID(instanceID), types.ID(requestData.UserId))
+	if err != nil {
+		return respondJSON(w, &model.PostActionIntegrationResponse{EphemeralText: err.Error()})
+	}
+	rootID, _ := requestData.Context["root_id"].(string)
+	post, err := p.makeSearchPost(instance, client, searchOptions{JQL: jql, Format: format, Sort: sortBy}, startAt, requestData.ChannelId, rootID)
+	if err != nil {
+		return respondJSON(w, &model.PostActionIntegrationResponse{EphemeralText: fmt.Sprintf("Failed to search the issues. Error: %v.", err)})
+	}
+	post.Id = requestData.PostId
+	p.client.Post.UpdateEphemeralPost(requestData.UserId, post)
+	return respondJSON(w, &model.PostActionIntegrationResponse{})
+}
+
+func executeSearch(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	opts, err := parseSearchArgs(args, instance.Common().DefaultJQL)
+	if err != nil {
+		return p.responsef(header, "%v. Usage: `/jira search [--format cards|table] [--sort field] <jql>`.", err)
+	}
+
+	client, _, _, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+
+	post, err := p.makeSearchPost(instance, client, opts, 0, header.ChannelId, header.RootId)
+	if err != nil {
+		return p.responsef(header, "Failed to search the issues. Error: %v.", err)
+	}
+	p.client.Post.SendEphemeralPost(header.UserId, post)
+	return &model.CommandResponse{}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSearchArgs(t *testing.T) {
+	for name, tc := range map[string]struct {
+		args        []string
+		defaultJQL  string
+		expected    searchOptions
+		expectError bool
+	}{
+		"jql only": {
+			args:     []string{"project", "=", "KT"},
+			expected: searchOptions{JQL: "project = KT", Format: searchFormatCards},
+		},
+		"table sorted by status": {
+			args:     []string{"--format", "table", "project", "=", "KT", "--sort=Status"},
+			expected: searchOptions{JQL: "project = KT", Format: searchFormatTable, Sort: searchSortStatus},
+		},
+		"unknown format": {
+			args:        []string{"--format=list", "project", "=", "KT"},
+			expectError: true,
+		},
+		"unknown column": {
+			args:        []string{"--sort", "created", "project", "=", "KT"},
+			expectError: true,
+		},
+		"missing value": {
+			args:        []string{"project", "=", "KT", "--sort"},
+			expectError: true,
+		},
+		"no jql": {
+			args:        []string{"--format", "table"},
+			expectError: true,
+		},
+		"default jql": {
+			args:       []string{"--format", "table"},
+			defaultJQL: "assignee = currentUser() AND resolution = Unresolved",
+			expected:   searchOptions{JQL: "assignee = currentUser() AND resolution = Unresolved", Format: searchFormatTable},
+		},
+		"jql given with a default": {
+			args:       []string{"project", "=", "KT"},
+			defaultJQL: "assignee = currentUser()",
+			expected:   searchOptions{JQL: "project = KT", Format: searchFormatCards},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts, err := parseSearchArgs(tc.args, tc.defaultJQL)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, opts)
+		})
+	}
+}
+
+func searchResults() []jira.Issue {
+	issue := func(key, summary, status, assignee, priorityID, priority string) jira.Issue {
+		fields := &jira.IssueFields{Summary: summary, Status: &jira.Status{Name: status}}
+		if assignee != "" {
+			fields.Assignee = &jira.User{DisplayName: assignee}
+		}
+		if priority != "" {
+			fields.Priority = &jira.Priority{ID: priorityID, Name: priority}
+		}
+		return jira.Issue{Key: key, Fields: fields}
+	}
+	return []jira.Issue{
+		issue("KT-10", "Fix the | pipe", "To Do", "Bob", "3", "Medium"),
+		issue("KT-9", "Ship\nthe release", "Done", "", "10001", "Highest"),
+		issue("ABC-2", "Update the docs", "In Progress", "alice", "", ""),
+	}
+}
+
+func TestSortSearchResults(t *testing.T) {
+	for column, expected := range map[string][]string{
+		searchSortKey:      {"ABC-2", "KT-9", "KT-10"},
+		searchSortSummary:  {"KT-10", "KT-9", "ABC-2"},
+		searchSortStatus:   {"KT-9", "ABC-2", "KT-10"},
+		searchSortAssignee: {"ABC-2", "KT-10", "KT-9"},
+		searchSortPriority: {"KT-9", "KT-10", "ABC-2"},
+	} {
+		t.Run(column, func(t *testing.T) {
+			issues := searchResults()
+			// The admins added Highest above the default priorities.
+			sortSearchResults(issues, column, []jira.Priority{{ID: "10001", Name: "Highest"}, {ID: "3", Name: "Medium"}})
+			keys := []string{}
+			for _, issue := range issues {
+				keys = append(keys, issue.Key)
+			}
+			assert.Equal(t, expected, keys)
+		})
+	}
+}
+
+func TestSearchTable(t *testing.T) {
+	assert.Equal(t, "| Key | Summary | Status | Assignee | Priority |\n"+
+		"| --- | --- | --- | --- | --- |\n"+
+		"| [KT-10](https://jira.example.com/browse/KT-10) | Fix the \\| pipe | To Do | Bob | Medium |\n"+
+		"| [KT-9](https://jira.example.com/browse/KT-9) | Ship the release | Done | Unassigned | Highest |\n"+
+		"| [ABC-2](https://jira.example.com/browse/ABC-2) | Update the docs | In Progress | alice |  |",
+		mdSearchTable("https://jira.example.com", searchResults()))
+
+	assert.Equal(t, "Found 3 issue(s).", mdSearchTotal(0, 3, 3))
+	assert.Equal(t, "Showing 1-3 of 40 issues.", mdSearchTotal(0, 3, 40))
+	assert.Equal(t, "Showing 11-20 of 40 issues.", mdSearchTotal(10, 10, 40))
+}
+
+type searchPageClient struct {
+	testClient
+	total int
+}
+
+func (client searchPageClient) SearchIssuesWithTotal(jql string, options *jira.SearchOptions) ([]jira.Issue, int, error) {
+	issues := []jira.Issue{}
+	for i := options.StartAt; i < options.StartAt+options.MaxResults && i < client.total; i++ {
+		issues = append(issues, jira.Issue{Key: fmt.Sprintf("KT-%d", i+1), Fields: &jira.IssueFields{Summary: "Issue"}})
+	}
+	return issues, client.total, nil
+}
+
+func searchPageActions(post *model.Post) map[string]string {
+	actions := map[string]string{}
+	for _, attachment := range post.Attachments() {
+		for _, action := range attachment.Actions {
+			actions[action.Name], _ = action.Integration.Context["start_at"].(string)
+		}
+	}
+	return actions
+}
+
+func TestMakeSearchPost(t *testing.T) {
+	p := &Plugin{}
+	opts := searchOptions{JQL: "project = KT", Format: searchFormatTable}
+	client := searchPageClient{total: 60}
+
+	post, err := p.makeSearchPost(testInstance1, client, opts, 0, "channel", "")
+	require.NoError(t, err)
+	assert.Contains(t, post.Message, "Showing 1-25 of 60 issues.")
+	assert.Equal(t, map[string]string{"Next page": "25"}, searchPageActions(post))
+
+	post, err = p.makeSearchPost(testInstance1, client, opts, 25, "channel", "")
+	require.NoError(t, err)
+	assert.Contains(t, post.Message, "[KT-26]")
+	assert.Equal(t, map[string]string{"Previous page": "0", "Next page": "50"}, searchPageActions(post))
+
+	post, err = p.makeSearchPost(testInstance1, client, opts, 50, "channel", "")
+	require.NoError(t, err)
+	assert.Contains(t, post.Message, "Showing 51-60 of 60 issues.")
+	assert.Equal(t, map[string]string{"Previous page": "25"}, searchPageActions(post))
+
+	post, err = p.makeSearchPost(testInstance1, searchPageClient{total: 3}, opts, 0, "channel", "")
+	require.NoError(t, err)
+	assert.Contains(t, post.Message, "Found 3 issue(s).")
+	assert.Empty(t, searchPageActions(post))
+}
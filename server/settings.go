@@ -4,18 +4,199 @@
 package main
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/mattermost/mattermost/server/public/model"
 
 	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
 )
 
 const (
-	settingOn  = "on"
-	settingOff = "off"
+	settingOn       = "on"
+	settingOff      = "off"
+	settingAssigned = "assigned"
+	settingInherit  = "inherit"
+
+	settingQuietHours = "quiet-hours"
+	settingCompact    = "compact"
+
+	settingNotifyOnSubscriptionMatch = "notify-dm-on-subscribe-match"
+	settingMentionOnly               = "mention-only"
+	settingReset                     = "reset"
 )
 
-func (p *Plugin) settingsNotifications(header *model.CommandArgs, instanceID, mattermostUserID types.ID, connection *Connection, args []string) *model.CommandResponse {
-	const helpText = "`/jira settings notifications [value]`\n* Invalid value. Accepted values are: `on` or `off`."
+// parseNotificationsSetting returns whether notifications are on, and whether
+// for the assigned issues only, for an `on`, `off` or `assigned` value.
+func parseNotificationsSetting(value string) (on, assignedOnly, ok bool) {
+	switch value {
+	case settingOn:
+		return true, false, true
+	case settingOff:
+		return false, false, true
+	case settingAssigned:
+		return true, true, true
+	default:
+		return false, false, false
+	}
+}
+
+func notificationsUpdatedString(on, assignedOnly bool) string {
+	switch {
+	case !on:
+		return settingOff
+	case assignedOnly:
+		return "on for the issues assigned to you only"
+	default:
+		return settingOn
+	}
+}
+
+// settingsNotifications updates the global notification settings of the
+// user, that apply to all the instances that do not override them.
+func (p *Plugin) settingsNotifications(header *model.CommandArgs, user *User, instanceID types.ID, connection *Connection, args []string) *model.CommandResponse {
+	const helpText = "`/jira settings notifications [value]`\n* Invalid value. Accepted values are: `on`, `off` or `assigned`."
+
+	if len(args) > 1 && args[1] == settingQuietHours {
+		return p.settingsQuietHours(header, instanceID, user.MattermostUserID, connection, args[2:])
+	}
+
+	if len(args) != 2 {
+		return p.responsef(header, helpText)
+	}
+	on, assignedOnly, ok := parseNotificationsSetting(args[1])
+	if !ok {
+		return p.responsef(header, helpText)
+	}
+
+	if user.Settings == nil {
+		if err := p.migrateNotificationsOverrides(user, instanceID, on, assignedOnly); err != nil {
+			p.errorf("settingsNotifications, err: %v", err)
+			return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
+		}
+	}
+	user.Settings = &UserSettings{
+		Notifications: on,
+		AssignedOnly:  assignedOnly,
+	}
+	if err := p.userStore.StoreUser(user); err != nil {
+		p.errorf("settingsNotifications, err: %v", err)
+		return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
+	}
+
+	msg := fmt.Sprintf("Settings updated. Notifications %s.", notificationsUpdatedString(on, assignedOnly))
+	if overrides := p.notificationsOverrides(user); len(overrides) > 0 {
+		msg += fmt.Sprintf("\nThe notification settings of %s override them. Use `/jira instance settings notifications inherit --instance=<jiraURL>` for an instance to follow these instead.",
+			strings.Join(overrides, ", "))
+	}
+	return p.responsef(header, "%s", msg)
+}
+
+// migrateNotificationsOverrides keeps the notifications of the other
+// connections of a user who has no global settings yet, as overrides of the
+// first ones. Until then each connection had its own, and they would all
+// follow the global settings from now on, losing them. The connection of the
+// instance the command is run for follows the global settings.
+func (p *Plugin) migrateNotificationsOverrides(user *User, instanceID types.ID, on, assignedOnly bool) error {
+	if user.ConnectedInstances == nil {
+		return nil
+	}
+	for _, id := range user.ConnectedInstances.IDs() {
+		if id == instanceID {
+			continue
+		}
+		connection, err := p.userStore.LoadConnection(id, user.MattermostUserID)
+		if err != nil {
+			continue
+		}
+		ownOn, ownAssignedOnly, _ := connection.Settings.notifications(nil)
+		if ownOn == on && ownAssignedOnly == assignedOnly {
+			continue
+		}
+		if connection.Settings == nil {
+			connection.Settings = &ConnectionSettings{}
+		}
+		connection.Settings.OverrideNotifications = true
+		if err = p.userStore.StoreConnection(id, user.MattermostUserID, connection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notificationsOverrides returns the instances of the user's connections that
+// override their global notification settings.
+func (p *Plugin) notificationsOverrides(user *User) []string {
+	overrides := []string{}
+	if user.ConnectedInstances == nil {
+		return overrides
+	}
+	for _, instanceID := range user.ConnectedInstances.IDs() {
+		connection, err := p.userStore.LoadConnection(instanceID, user.MattermostUserID)
+		if err != nil {
+			continue
+		}
+		if _, _, source := connection.Settings.notifications(user.Settings); source == notificationsOverridden {
+			overrides = append(overrides, instanceID.String())
+		}
+	}
+	return overrides
+}
+
+// settingsInstanceNotifications overrides the notification settings of the
+// user for an instance, or with `inherit` makes it follow the global ones
+// again.
+func (p *Plugin) settingsInstanceNotifications(header *model.CommandArgs, user *User, instanceID types.ID, connection *Connection, args []string) *model.CommandResponse {
+	const helpText = "`/jira instance settings notifications [value]`\n* Invalid value. Accepted values are: `on`, `off`, `assigned` or `inherit`."
+
+	if len(args) > 1 && args[1] == settingQuietHours {
+		return p.settingsQuietHours(header, instanceID, user.MattermostUserID, connection, args[2:])
+	}
+
+	if len(args) != 2 {
+		return p.responsef(header, helpText)
+	}
+
+	if connection.Settings == nil {
+		connection.Settings = &ConnectionSettings{}
+	}
+	inherit := args[1] == settingInherit
+	if inherit {
+		connection.Settings.OverrideNotifications = false
+	} else {
+		on, assignedOnly, ok := parseNotificationsSetting(args[1])
+		if !ok {
+			return p.responsef(header, helpText)
+		}
+		connection.Settings.Notifications = on
+		connection.Settings.AssignedOnly = assignedOnly
+		connection.Settings.OverrideNotifications = true
+	}
+	if err := p.userStore.StoreConnection(instanceID, user.MattermostUserID, connection); err != nil {
+		p.errorf("settingsInstanceNotifications, err: %v", err)
+		return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
+	}
+
+	// send back the actual value
+	updatedConnection, err := p.userStore.LoadConnection(instanceID, user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "Your username is not connected to Jira. Please type `jira connect`. %v", err)
+	}
+	on, assignedOnly, source := updatedConnection.Settings.notifications(user.Settings)
+	notifications := notificationsUpdatedString(on, assignedOnly)
+
+	switch {
+	case inherit && source == notificationsInherited:
+		return p.responsef(header, "Settings updated. Notifications %s, as in your global settings.", notifications)
+	case inherit:
+		return p.responsef(header, "Settings updated. Notifications stay %s until you set your global settings with `/jira settings notifications`.", notifications)
+	default:
+		return p.responsef(header, "Settings updated. Notifications %s.", notifications)
+	}
+}
+
+func (p *Plugin) settingsIgnoreOwnActions(header *model.CommandArgs, instanceID, mattermostUserID types.ID, connection *Connection, args []string) *model.CommandResponse {
+	const helpText = "`/jira settings ignore-own-actions [value]`\n* Invalid value. Accepted values are: `on` or `off`."
 
 	if len(args) != 2 {
 		return p.responsef(header, helpText)
@@ -34,21 +215,150 @@ func (p *Plugin) settingsNotifications(header *model.CommandArgs, instanceID, ma
 	if connection.Settings == nil {
 		connection.Settings = &ConnectionSettings{}
 	}
-	connection.Settings.Notifications = value
+	connection.Settings.IgnoreOwnActions = &value
 	if err := p.userStore.StoreConnection(instanceID, mattermostUserID, connection); err != nil {
-		p.errorf("settingsNotifications, err: %v", err)
-		p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
+		p.errorf("settingsIgnoreOwnActions, err: %v", err)
+		return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
 	}
 
-	// send back the actual value
-	updatedConnection, err := p.userStore.LoadConnection(instanceID, mattermostUserID)
-	if err != nil {
-		return p.responsef(header, "Your username is not connected to Jira. Please type `jira connect`. %v", err)
+	ignoreOwnActions := settingOff
+	if connection.Settings.ShouldIgnoreOwnActions() {
+		ignoreOwnActions = settingOn
+	}
+
+	return p.responsef(header, "Settings updated. Ignore my own actions %s.", ignoreOwnActions)
+}
+
+func (p *Plugin) settingsCompact(header *model.CommandArgs, instanceID, mattermostUserID types.ID, connection *Connection, args []string) *model.CommandResponse {
+	return p.settingsToggle(header, instanceID, mattermostUserID, connection, args, settingCompact, "Compact notifications",
+		func(s *ConnectionSettings, value bool) { s.CompactNotifications = value })
+}
+
+func (p *Plugin) settingsNotifyOnSubscriptionMatch(header *model.CommandArgs, instanceID, mattermostUserID types.ID, connection *Connection, args []string) *model.CommandResponse {
+	return p.settingsToggle(header, instanceID, mattermostUserID, connection, args, settingNotifyOnSubscriptionMatch, "Notify me when subscriptions post about my issues",
+		func(s *ConnectionSettings, value bool) { s.NotifyOnSubscriptionMatch = value })
+}
+
+func (p *Plugin) settingsMentionOnly(header *model.CommandArgs, instanceID, mattermostUserID types.ID, connection *Connection, args []string) *model.CommandResponse {
+	return p.settingsToggle(header, instanceID, mattermostUserID, connection, args, settingMentionOnly, "Only notify me when I'm mentioned",
+		func(s *ConnectionSettings, value bool) { s.MentionOnly = value })
+}
+
+// settingsToggle turns an on/off setting of the connection on or off.
+func (p *Plugin) settingsToggle(header *model.CommandArgs, instanceID, mattermostUserID types.ID, connection *Connection, args []string,
+	setting, label string, set func(s *ConnectionSettings, value bool)) *model.CommandResponse {
+	helpText := fmt.Sprintf("`/jira settings %s [value]`\n* Invalid value. Accepted values are: `on` or `off`.", setting)
+
+	if len(args) != 2 {
+		return p.responsef(header, helpText)
+	}
+
+	var value bool
+	switch args[1] {
+	case settingOn:
+		value = true
+	case settingOff:
+		value = false
+	default:
+		return p.responsef(header, helpText)
+	}
+
+	if connection.Settings == nil {
+		connection.Settings = &ConnectionSettings{}
+	}
+	set(connection.Settings, value)
+	if err := p.userStore.StoreConnection(instanceID, mattermostUserID, connection); err != nil {
+		p.errorf("settingsToggle %s, err: %v", setting, err)
+		return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
 	}
-	notifications := settingOff
-	if updatedConnection.Settings.Notifications {
-		notifications = settingOn
+
+	return p.responsef(header, "Settings updated. %s %s.", label, args[1])
+}
+
+// settingsChanges lists the settings that differ between two listings of
+// them by fields.
+func settingsChanges(before, after []settingField) []string {
+	afterValues := map[string]string{}
+	for _, field := range after {
+		afterValues[field.label] = field.value
 	}
 
-	return p.responsef(header, "Settings updated. Notifications %s.", notifications)
+	changes := []string{}
+	for _, field := range before {
+		from, to := field.value, afterValues[field.label]
+		if from == to {
+			continue
+		}
+		if from == "" {
+			from = settingOff
+		}
+		if to == "" {
+			to = settingOff
+		}
+		changes = append(changes, fmt.Sprintf("\t%s: %s → %s", field.label, from, to))
+	}
+	return changes
+}
+
+// settingsReset restores the settings of the user to the defaults of a new
+// connection, keeping the connections themselves. At the instance level only
+// the settings of that instance are reset, otherwise the global settings and
+// those of all the connected instances are.
+func (p *Plugin) settingsReset(header *model.CommandArgs, user *User, instanceID types.ID, instanceLevel bool, args []string) *model.CommandResponse {
+	if len(args) != 1 {
+		return p.responsef(header, "`/jira settings reset` restores all your settings to their defaults, it takes no value.")
+	}
+
+	instanceIDs := []types.ID{instanceID}
+	globalBefore := user.Settings
+	if !instanceLevel {
+		if !user.ConnectedInstances.IsEmpty() {
+			instanceIDs = user.ConnectedInstances.IDs()
+		}
+		if user.Settings != nil {
+			user.Settings = nil
+			if err := p.userStore.StoreUser(user); err != nil {
+				p.errorf("settingsReset, err: %v", err)
+				return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
+			}
+		}
+	}
+
+	msg := ""
+	for _, id := range instanceIDs {
+		connection, err := p.userStore.LoadConnection(id, user.MattermostUserID)
+		if err != nil {
+			continue
+		}
+		before := connection.Settings.fields(globalBefore)
+		hadStatusEntryRules := connection.Settings != nil && len(connection.Settings.StatusEntryRules) > 0
+		hadDailySummary := connection.Settings != nil && connection.Settings.DailySummary != ""
+		connection.Settings = p.defaultConnectionSettings()
+		if err = p.userStore.StoreConnection(id, user.MattermostUserID, connection); err != nil {
+			p.errorf("settingsReset, err: %v", err)
+			return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
+		}
+		if hadStatusEntryRules {
+			if err = p.updateStatusEntryUsers(id, user.MattermostUserID, false); err != nil {
+				p.errorf("settingsReset, err: %v", err)
+			}
+		}
+		if hadDailySummary {
+			if err = p.updateDailySummaryUsers(id, user.MattermostUserID, false); err != nil {
+				p.errorf("settingsReset, err: %v", err)
+			}
+		}
+		after := connection.Settings.fields(user.Settings)
+
+		if len(instanceIDs) > 1 {
+			msg += fmt.Sprintf("\n**%s**", id)
+		}
+		if changes := settingsChanges(before, after); len(changes) > 0 {
+			msg += "\nChanged:\n" + strings.Join(changes, "\n")
+		} else {
+			msg += "\nNothing changed, your settings were the defaults."
+		}
+		msg += "\nCurrent settings:\n" + connection.Settings.stringWith(user.Settings)
+	}
+	return p.responsef(header, "%s", "Settings reset to the defaults."+msg)
 }
@@ -0,0 +1,119 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// instanceConnectionsStore keeps a connection of the user per instance.
+type instanceConnectionsStore struct {
+	mockUserStore
+	connections map[types.ID]*Connection
+}
+
+func (store instanceConnectionsStore) LoadConnection(instanceID, _ types.ID) (*Connection, error) {
+	return store.connections[instanceID], nil
+}
+
+func TestMigrateNotificationsOverrides(t *testing.T) {
+	user := NewUser("connected_user")
+	user.ConnectedInstances.Set(testInstance1.Common())
+	user.ConnectedInstances.Set(testInstance2.Common())
+	store := instanceConnectionsStore{
+		connections: map[types.ID]*Connection{
+			testInstance1.GetID(): {Settings: &ConnectionSettings{}},
+			testInstance2.GetID(): {Settings: &ConnectionSettings{Notifications: true}},
+		},
+	}
+	p := &Plugin{userStore: store}
+
+	require.NoError(t, p.migrateNotificationsOverrides(user, testInstance1.GetID(), true, false))
+	assert.False(t, store.connections[testInstance2.GetID()].Settings.OverrideNotifications)
+
+	require.NoError(t, p.migrateNotificationsOverrides(user, testInstance2.GetID(), true, false))
+	assert.True(t, store.connections[testInstance1.GetID()].Settings.OverrideNotifications)
+	assert.False(t, store.connections[testInstance2.GetID()].Settings.OverrideNotifications)
+
+	global := &UserSettings{Notifications: true}
+	on, _, source := store.connections[testInstance1.GetID()].Settings.notifications(global)
+	assert.False(t, on)
+	assert.Equal(t, notificationsOverridden, source)
+}
+
+func TestSettingsChanges(t *testing.T) {
+	ignoreOwnActions := false
+	before := &ConnectionSettings{
+		Notifications:    true,
+		AssignedOnly:     true,
+		IgnoreOwnActions: &ignoreOwnActions,
+		MentionOnly:      true,
+		DailySummary:     "08:30",
+	}
+	after := &ConnectionSettings{Notifications: true}
+
+	assert.Equal(t, []string{
+		"\tNotifications: assigned issues only → on",
+		"\tIgnore my own actions: off → on",
+		"\tOnly notify me when I'm mentioned: on → off",
+		"\tDaily summary: 08:30 → off",
+	}, settingsChanges(before.fields(nil), after.fields(nil)))
+
+	assert.Empty(t, settingsChanges(after.fields(nil), after.fields(nil)))
+}
+
+func TestParseImportedSettings(t *testing.T) {
+	settings, err := parseImportedSettings("```\n" + `{"notifications":true,"daily_summary":"8:05","custom_statuses":[{"match":"LABEL","value":"incident","emoji":"rotating_light","text":"On incident"}]}` + "\n```")
+	require.NoError(t, err)
+	assert.True(t, settings.Notifications)
+	assert.Equal(t, "08:05", settings.DailySummary)
+	assert.Equal(t, []customStatusRule{{Match: customStatusMatchLabel, Value: "incident", Emoji: "rotating_light", Text: "On incident"}}, settings.CustomStatuses)
+
+	for name, invalid := range map[string]string{
+		"not JSON":           "notifications on",
+		"unknown setting":    `{"notifications":true,"digest":"weekly"}`,
+		"trailing text":      `{"notifications":true} {}`,
+		"invalid quiet time": `{"quiet_hours":{"start":"22:00","end":"7pm"}}`,
+		"empty quiet hours":  `{"quiet_hours":{"start":"22:00","end":"22:00"}}`,
+		"invalid summary":    `{"daily_summary":"noon"}`,
+		"invalid status":     `{"custom_statuses":[{"match":"priority","value":"High","emoji":"fire","text":"Busy"}]}`,
+	} {
+		_, err = parseImportedSettings(invalid)
+		assert.Error(t, err, name)
+	}
+}
+
+type importedSettingsTestClient struct {
+	testClient
+}
+
+func (client importedSettingsTestClient) RESTGet(endpoint string, params map[string]string, dest interface{}) error {
+	if endpoint == "2/status" {
+		return json.Unmarshal([]byte(`[{"id": "10", "name": "To Do"}, {"id": "31", "name": "Ready for QA"}]`), dest)
+	}
+	return json.Unmarshal([]byte(testPriorities), dest)
+}
+
+func TestResolveImportedSettings(t *testing.T) {
+	p := &Plugin{}
+	settings := &ConnectionSettings{
+		NotifyPriorityThreshold: "high",
+		StatusEntryRules:        []statusEntryRule{{StatusID: "3", Status: "ready for qa"}},
+	}
+	require.NoError(t, p.resolveImportedSettings(testInstance1.GetID(), importedSettingsTestClient{}, settings))
+	assert.Equal(t, "High", settings.NotifyPriorityThreshold)
+	assert.Equal(t, []statusEntryRule{{StatusID: "31", Status: "Ready for QA"}}, settings.StatusEntryRules)
+
+	err := p.resolveImportedSettings(testInstance1.GetID(), importedSettingsTestClient{}, &ConnectionSettings{NotifyPriorityThreshold: "Blocker"})
+	assert.ErrorContains(t, err, `unknown priority threshold "Blocker"`)
+
+	err = p.resolveImportedSettings(testInstance1.GetID(), importedSettingsTestClient{}, &ConnectionSettings{StatusEntryRules: []statusEntryRule{{Status: "Deployed"}}})
+	assert.ErrorContains(t, err, `unknown status "Deployed"`)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	settingExport = "export"
+	settingImport = "import"
+)
+
+// settingsExport shows the settings of the user for the instance as JSON, to
+// be imported to another instance with settingsImport.
+func (p *Plugin) settingsExport(header *model.CommandArgs, instanceID types.ID, connection *Connection, args []string) *model.CommandResponse {
+	if len(args) != 1 {
+		return p.responsef(header, "`/jira settings export` shows your settings, it takes no value.")
+	}
+	settings := connection.Settings
+	if settings == nil {
+		settings = &ConnectionSettings{}
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return p.responsef(header, "Failed to export your settings. Error: %v.", err)
+	}
+	return p.responsef(header, "Your settings for %s:\n```\n%s\n```\nTo copy them to another Jira instance, run `/jira settings import --instance [jiraURL]` followed by them.",
+		instanceID, data)
+}
+
+// parseImportedSettings parses the JSON of settingsExport, e.g. pasted as a
+// code block, rejecting the unknown settings.
+func parseImportedSettings(s string) (*ConnectionSettings, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(s, "```"), "```"))
+	s = strings.TrimSpace(strings.Trim(s, "`"))
+
+	settings := &ConnectionSettings{}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(s)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(settings); err != nil {
+		return nil, errors.Errorf("the settings are not the JSON of `/jira settings export`: %v", err)
+	}
+	if decoder.More() {
+		return nil, errors.New("the settings are followed by more text")
+	}
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// validate checks the settings that were not set by their commands,
+// normalizing the times of the day and the custom statuses they set.
+func (s *ConnectionSettings) validate() error {
+	if s.QuietHours != nil {
+		for _, clock := range []string{s.QuietHours.Start, s.QuietHours.End} {
+			if _, err := parseQuietHoursClock(clock); err != nil {
+				return errors.Wrap(err, "invalid quiet hours")
+			}
+		}
+		if s.QuietHours.Start == s.QuietHours.End {
+			return errors.New("invalid quiet hours, they must start and end at different times")
+		}
+	}
+	if s.DailySummary != "" {
+		minutes, err := parseQuietHoursClock(s.DailySummary)
+		if err != nil {
+			return errors.Wrap(err, "invalid daily summary")
+		}
+		s.DailySummary = fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+	}
+	rules := []customStatusRule{}
+	for _, imported := range s.CustomStatuses {
+		rule, err := newCustomStatusRule(imported.Match+":"+imported.Value, ":"+imported.Emoji+":", imported.Text)
+		if err != nil {
+			return errors.Wrap(err, "invalid custom status")
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) > 0 {
+		s.CustomStatuses = rules
+	}
+	return nil
+}
+
+// settingsImport replaces the settings of the user for the instance with
+// those exported from another instance by settingsExport.
+func (p *Plugin) settingsImport(header *model.CommandArgs, user *User, instanceID types.ID, connection *Connection, args []string) *model.CommandResponse {
+	const helpText = "`/jira settings import --instance [jiraURL] [settings]`\n* [settings] are those shown by `/jira settings export --instance [jiraURL]` for another Jira instance."
+
+	if len(args) < 2 {
+		return p.responsef(header, "%s", helpText)
+	}
+	settings, err := parseImportedSettings(strings.Join(args[1:], " "))
+	if err != nil {
+		return p.responsef(header, "Failed to import the settings: %v.\n%s", err, helpText)
+	}
+
+	if settings.NotifyPriorityThreshold != "" || len(settings.StatusEntryRules) > 0 {
+		client, _, _, err := p.getClient(instanceID, user.MattermostUserID)
+		if err != nil {
+			return p.responsef(header, "%v", err)
+		}
+		if err = p.resolveImportedSettings(instanceID, client, settings); err != nil {
+			return p.responsef(header, "Failed to import the settings: %v.", err)
+		}
+	}
+
+	before := connection.Settings.fields(user.Settings)
+	hadStatusEntryRules := connection.Settings != nil && len(connection.Settings.StatusEntryRules) > 0
+	hadDailySummary := connection.Settings != nil && connection.Settings.DailySummary != ""
+	connection.Settings = settings
+	if err = p.userStore.StoreConnection(instanceID, user.MattermostUserID, connection); err != nil {
+		p.errorf("settingsImport, err: %v", err)
+		return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
+	}
+	if hasDailySummary := settings.DailySummary != ""; hasDailySummary || hadDailySummary {
+		if err = p.startDailySummary(instanceID, user.MattermostUserID, settings); err != nil {
+			p.errorf("settingsImport, err: %v", err)
+		}
+	}
+	if hasStatusEntryRules := len(settings.StatusEntryRules) > 0; hasStatusEntryRules || hadStatusEntryRules {
+		if err = p.updateStatusEntryUsers(instanceID, user.MattermostUserID, hasStatusEntryRules); err != nil {
+			p.errorf("settingsImport, err: %v", err)
+		}
+	}
+	after := connection.Settings.fields(user.Settings)
+
+	msg := fmt.Sprintf("Settings imported to %s.", instanceID)
+	if changes := settingsChanges(before, after); len(changes) > 0 {
+		msg += "\nChanged:\n" + strings.Join(changes, "\n")
+	} else {
+		msg += "\nNothing changed, your settings were the same."
+	}
+	return p.responsef(header, "%s", msg+"\nCurrent settings:\n"+connection.Settings.stringWith(user.Settings))
+}
+
+// resolveImportedSettings checks the priority threshold and the status entry
+// rules of settings exported from another instance against this one. They are
+// named as in this instance, and the IDs of the statuses are replaced by its
+// own.
+func (p *Plugin) resolveImportedSettings(instanceID types.ID, client Client, settings *ConnectionSettings) error {
+	if settings.NotifyPriorityThreshold != "" {
+		priorities, err := p.getPriorityOrder(instanceID, client)
+		if err != nil {
+			return errors.WithMessage(err, "failed to check the priority threshold")
+		}
+		rank := priorityRankOf(priorities, "", settings.NotifyPriorityThreshold)
+		if rank < 0 {
+			names := []string{}
+			for _, priority := range priorities {
+				names = append(names, priority.Name)
+			}
+			return errors.Errorf("unknown priority threshold %q, the priorities are, from the highest: %s", settings.NotifyPriorityThreshold, strings.Join(names, ", "))
+		}
+		settings.NotifyPriorityThreshold = priorities[rank].Name
+	}
+
+	for i, rule := range settings.StatusEntryRules {
+		status, err := resolveStatus(client, rule.Status, rule.Project)
+		if err != nil {
+			return errors.WithMessagef(err, "invalid status entry rule %s", rule)
+		}
+		settings.StatusEntryRules[i].StatusID = status.ID
+		settings.StatusEntryRules[i].Status = status.Name
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	// sprintFieldSchema identifies the sprint field of Jira Software, whose
+	// custom field ID differs from one instance to another.
+	sprintFieldSchema = "com.pyxis.greenhopper.jira:gh-sprint"
+
+	sprintCurrent     = "current"
+	sprintStateActive = "active"
+	openSprintStates  = "active,future"
+
+	// customFieldTTL is how long the custom fields of an instance are
+	// remembered, so that a field added or removed by the Jira admins is
+	// eventually found without changing the instance.
+	customFieldTTL = time.Hour
+)
+
+// getCustomFieldID returns the ID of the custom field of the instance with
+// the schema, e.g. "customfield_10020", found among its fields every
+// customFieldTTL.
+// It is empty when the instance has no such field.
+func (p *Plugin) getCustomFieldID(instanceID types.ID, client Client, schema string) (string, error) {
+	if fieldIDs, ok := p.customFieldCache.get(instanceID, ""); ok {
+		return fieldIDs[schema], nil
+	}
+
+	fields, err := client.GetFields()
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to get the fields of the Jira instance")
+	}
+	fieldIDs := map[string]string{}
+	for _, field := range fields {
+		if field.Schema.Custom != "" && fieldIDs[field.Schema.Custom] == "" {
+			fieldIDs[field.Schema.Custom] = field.ID
+		}
+	}
+	p.customFieldCache.set(instanceID, "", fieldIDs, customFieldTTL)
+	return fieldIDs[schema], nil
+}
+
+// getSprintFieldID returns the ID of the sprint field of the instance.
+func (p *Plugin) getSprintFieldID(instanceID types.ID, client Client) (string, error) {
+	fieldID, err := p.getCustomFieldID(instanceID, client, sprintFieldSchema)
+	if err != nil {
+		return "", err
+	}
+	if fieldID == "" {
+		return "", errors.New("the Jira instance has no sprint field, is Jira Software installed?")
+	}
+	return fieldID, nil
+}
+
+// resolveSprint returns the open sprint of a Scrum board of the project
+// given by value: `current` for the active sprint, or else the ID or the name
+// of the sprint.
+func resolveSprint(client Client, projectKey, value string) (*jira.Sprint, error) {
+	boards, err := client.ListProjectBoards(projectKey, "scrum")
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to get the boards of project %s", projectKey)
+	}
+	if len(boards) == 0 {
+		return nil, errors.Errorf("project %s has no Scrum board, so it has no sprints", projectKey)
+	}
+
+	sprintID, _ := strconv.Atoi(value)
+	for _, board := range boards {
+		sprints, err := client.ListBoardSprints(board.ID, openSprintStates)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to get the sprints of board %s", board.Name)
+		}
+		for i := range sprints {
+			sprint := &sprints[i]
+			switch {
+			case strings.EqualFold(value, sprintCurrent):
+				if sprint.State == sprintStateActive {
+					return sprint, nil
+				}
+			case sprintID != 0:
+				if sprint.ID == sprintID {
+					return sprint, nil
+				}
+			case strings.EqualFold(sprint.Name, value):
+				return sprint, nil
+			}
+		}
+	}
+
+	if strings.EqualFold(value, sprintCurrent) {
+		return nil, errors.Errorf("the boards of project %s have no active sprint", projectKey)
+	}
+	return nil, errors.Errorf("%q is not an open sprint of the boards of project %s", value, projectKey)
+}
+
+// setIssueSprint puts the issue in the sprint of the project given by value,
+// see resolveSprint.
+func (p *Plugin) setIssueSprint(instanceID types.ID, client Client, fields *jira.IssueFields, value string) error {
+	sprint, err := resolveSprint(client, fields.Project.Key, value)
+	if err != nil {
+		return err
+	}
+	fieldID, err := p.getSprintFieldID(instanceID, client)
+	if err != nil {
+		return err
+	}
+
+	if fields.Unknowns == nil {
+		fields.Unknowns = map[string]interface{}{}
+	}
+	fields.Unknowns[fieldID] = sprint.ID
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sprintTestClient struct {
+	testClient
+	fieldCalls *int
+}
+
+func (client sprintTestClient) ListProjectBoards(projectKey, boardType string) ([]jira.Board, error) {
+	if projectKey != "TEST" || boardType != "scrum" {
+		return nil, nil
+	}
+	return []jira.Board{{ID: 1, Name: "Team A"}, {ID: 2, Name: "Team B"}}, nil
+}
+
+func (client sprintTestClient) ListBoardSprints(boardID int, states string) ([]jira.Sprint, error) {
+	if states != openSprintStates {
+		return nil, nil
+	}
+	switch boardID {
+	case 1:
+		return []jira.Sprint{{ID: 11, Name: "A Sprint 4", State: "future"}}, nil
+	case 2:
+		return []jira.Sprint{{ID: 21, Name: "B Sprint 7", State: "active"}, {ID: 22, Name: "B Sprint 8", State: "future"}}, nil
+	}
+	return nil, nil
+}
+
+func (client sprintTestClient) GetFields() ([]jira.Field, error) {
+	*client.fieldCalls++
+	return []jira.Field{
+		{ID: "summary", Name: "Summary"},
+		{ID: "customfield_10020", Name: "Sprint", Custom: true, Schema: jira.FieldSchema{Custom: sprintFieldSchema}},
+	}, nil
+}
+
+func TestGetCustomFieldID(t *testing.T) {
+	now := time.Now()
+	p := &Plugin{}
+	p.customFieldCache.now = func() time.Time { return now }
+	client := sprintTestClient{fieldCalls: new(int)}
+
+	fieldID, err := p.getCustomFieldID(testInstance1.InstanceID, client, sprintFieldSchema)
+	require.NoError(t, err)
+	assert.Equal(t, "customfield_10020", fieldID)
+	fieldID, err = p.getCustomFieldID(testInstance1.InstanceID, client, epicLinkFieldSchema)
+	require.NoError(t, err)
+	assert.Empty(t, fieldID)
+	assert.Equal(t, 1, *client.fieldCalls, "the fields are cached")
+
+	now = now.Add(customFieldTTL + time.Second)
+	_, err = p.getCustomFieldID(testInstance1.InstanceID, client, sprintFieldSchema)
+	require.NoError(t, err)
+	assert.Equal(t, 2, *client.fieldCalls, "the cached fields expire")
+}
+
+func TestResolveSprint(t *testing.T) {
+	client := sprintTestClient{fieldCalls: new(int)}
+	for name, tc := range map[string]struct {
+		projectKey    string
+		value         string
+		expectedID    int
+		expectedError string
+	}{
+		"current":        {projectKey: "TEST", value: "current", expectedID: 21},
+		"by ID":          {projectKey: "TEST", value: "22", expectedID: 22},
+		"by name":        {projectKey: "TEST", value: "a sprint 4", expectedID: 11},
+		"unknown ID":     {projectKey: "TEST", value: "99", expectedError: `"99" is not an open sprint of the boards of project TEST`},
+		"unknown name":   {projectKey: "TEST", value: "Sprint 1", expectedError: `"Sprint 1" is not an open sprint of the boards of project TEST`},
+		"no Scrum board": {projectKey: "OTHER", value: "current", expectedError: "project OTHER has no Scrum board, so it has no sprints"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			sprint, err := resolveSprint(client, tc.projectKey, tc.value)
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedID, sprint.ID)
+		})
+	}
+}
+
+func TestSetIssueSprint(t *testing.T) {
+	p := &Plugin{}
+	client := sprintTestClient{fieldCalls: new(int)}
+
+	for i := 0; i < 2; i++ {
+		fields := &jira.IssueFields{Project: jira.Project{Key: "TEST"}}
+		require.NoError(t, p.setIssueSprint(testInstance1.InstanceID, client, fields, "current"))
+		assert.Equal(t, 21, fields.Unknowns["customfield_10020"])
+	}
+	assert.Equal(t, 1, *client.fieldCalls, "the sprint field is looked up once per instance")
+}
@@ -0,0 +1,294 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	settingNotifyStatusEntry = "notify-status-entry"
+
+	// keyStatusEntryUsers lists the users of an instance with status entry
+	// rules, so that the events are only matched against theirs.
+	keyStatusEntryUsers = "status_entry_users"
+
+	flagStatusEntryProject = "--project"
+)
+
+// statusEntryRule notifies the user when an issue, of the project if any,
+// transitions into the status.
+type statusEntryRule struct {
+	StatusID string `json:"status_id,omitempty"`
+	Status   string `json:"status"`
+	Project  string `json:"project,omitempty"`
+}
+
+func (r statusEntryRule) String() string {
+	if r.Project == "" {
+		return r.Status
+	}
+	return fmt.Sprintf("%s in %s", r.Status, r.Project)
+}
+
+// statusTransition returns the status that the issue of the event
+// transitioned into, if it did.
+func (jwh *JiraWebhook) statusTransition() (toID, toName string, ok bool) {
+	for _, item := range jwh.ChangeLog.Items {
+		if item.Field == statusField && item.From != item.To {
+			return item.To, item.ToString, true
+		}
+	}
+	return "", "", false
+}
+
+// matches tells whether the issue of the project transitioned into the status
+// of the rule. The statuses of the team-managed projects have IDs of their
+// own, so they also match by name.
+func (r statusEntryRule) matches(projectKey, toID, toName string) bool {
+	if r.Project != "" && !strings.EqualFold(r.Project, projectKey) {
+		return false
+	}
+	return (r.StatusID != "" && r.StatusID == toID) || strings.EqualFold(r.Status, toName)
+}
+
+func (p *Plugin) loadStatusEntryUsers(instanceID types.ID) ([]types.ID, error) {
+	userIDs := []types.ID{}
+	if err := p.client.KV.Get(keyWithInstanceID(instanceID, keyStatusEntryUsers), &userIDs); err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// updateStatusEntryUsers adds the user to the users with status entry rules,
+// or removes them.
+func (p *Plugin) updateStatusEntryUsers(instanceID, mattermostUserID types.ID, hasRules bool) error {
+	return p.client.KV.SetAtomicWithRetries(keyWithInstanceID(instanceID, keyStatusEntryUsers), func(initialBytes []byte) (interface{}, error) {
+		userIDs := []types.ID{}
+		if len(initialBytes) > 0 {
+			if err := json.Unmarshal(initialBytes, &userIDs); err != nil {
+				return nil, err
+			}
+		}
+		updated := []types.ID{}
+		for _, userID := range userIDs {
+			if userID != mattermostUserID {
+				updated = append(updated, userID)
+			}
+		}
+		if hasRules {
+			updated = append(updated, mattermostUserID)
+		}
+		if len(updated) == 0 {
+			return nil, nil
+		}
+		sort.Slice(updated, func(i, j int) bool { return updated[i] < updated[j] })
+		return json.Marshal(updated)
+	})
+}
+
+// statusEntryNotifications returns the notifications of the users whose
+// status entry rules match the transition of the event. The users already
+// notified about the event are left out.
+func (p *Plugin) statusEntryNotifications(instanceID types.ID, wh *webhook) []webhookUserNotification {
+	toID, toName, ok := wh.JiraWebhook.statusTransition()
+	if !ok {
+		return nil
+	}
+	userIDs, err := p.loadStatusEntryUsers(instanceID)
+	if err != nil {
+		p.client.Log.Warn("Failed to load the users with status entry rules", "InstanceID", instanceID.String(), "Error", err.Error())
+		return nil
+	}
+
+	projectKey := ""
+	if wh.Issue.Fields != nil && wh.Issue.Fields.Project.Key != "" {
+		projectKey = wh.Issue.Fields.Project.Key
+	} else {
+		projectKey, _, _ = strings.Cut(wh.Issue.Key, "-")
+	}
+
+	notifications := []webhookUserNotification{}
+	for _, userID := range userIDs {
+		c, err := p.userStore.LoadConnection(instanceID, userID)
+		if err != nil || c.Settings == nil || isNotified(wh.notifications, c) {
+			continue
+		}
+		for _, rule := range c.Settings.StatusEntryRules {
+			if !rule.matches(projectKey, toID, toName) {
+				continue
+			}
+			notifications = append(notifications, webhookUserNotification{
+				jiraUsername:  c.Name,
+				jiraAccountID: c.AccountID,
+				message:       fmt.Sprintf("%s **moved** %s into **%s**", wh.JiraWebhook.mdUser(), wh.JiraWebhook.mdKeySummaryLink(), toName),
+				statusEntry:   true,
+			})
+			break
+		}
+	}
+	return notifications
+}
+
+// isNotified tells whether one of the notifications is for the Jira account
+// of the connection.
+func isNotified(notifications []webhookUserNotification, c *Connection) bool {
+	for _, notification := range notifications {
+		if (notification.jiraAccountID != "" && notification.jiraAccountID == c.AccountID) ||
+			(notification.jiraAccountID == "" && notification.jiraUsername != "" && notification.jiraUsername == c.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveStatus returns the status of the instance, or of the project if
+// any, with the name.
+func resolveStatus(client Client, name, projectKey string) (*jira.Status, error) {
+	statuses := []*jira.Status{}
+	if projectKey == "" {
+		if err := client.RESTGet("2/status", nil, &statuses); err != nil {
+			return nil, errors.WithMessage(err, "failed to get the statuses")
+		}
+	} else {
+		issueTypes, err := client.ListProjectStatuses(projectKey)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to get the statuses of %s", projectKey)
+		}
+		for _, issueType := range issueTypes {
+			statuses = append(statuses, issueType.Statuses...)
+		}
+	}
+
+	names := []string{}
+	for _, status := range statuses {
+		if strings.EqualFold(status.Name, name) {
+			return status, nil
+		}
+		if !containsFold(names, status.Name) {
+			names = append(names, status.Name)
+		}
+	}
+	sort.Strings(names)
+	return nil, errors.Errorf("unknown status %q, the statuses are: %s", name, strings.Join(names, ", "))
+}
+
+// parseStatusEntryRuleArgs returns the status name and the project key of
+// `add` and `remove`, e.g. `Ready for QA --project QA`.
+func parseStatusEntryRuleArgs(args []string) (status, projectKey string, err error) {
+	words := []string{}
+	for i := 0; i < len(args); i++ {
+		if args[i] != flagStatusEntryProject {
+			words = append(words, args[i])
+			continue
+		}
+		if i+1 == len(args) {
+			return "", "", errors.New("`--project` needs a project key")
+		}
+		i++
+		projectKey = strings.ToUpper(args[i])
+	}
+	status = strings.Join(words, " ")
+	if status == "" {
+		return "", "", errors.New("please specify a status")
+	}
+	return status, projectKey, nil
+}
+
+func (p *Plugin) settingsNotifyStatusEntry(header *model.CommandArgs, instanceID, mattermostUserID types.ID, connection *Connection, args []string) *model.CommandResponse {
+	const helpText = "`/jira settings notify-status-entry [add|remove|list|clear] [status] [--project key]`\n" +
+		"* `add Ready for QA` gets you a DM when an issue you can see moves into the status, `add Ready for QA --project QA` only for the issues of a project.\n" +
+		"* `remove Ready for QA` removes the rule, with the same `--project` if any. `clear` removes all of them."
+
+	if len(args) < 2 {
+		return p.responsef(header, "%s", helpText)
+	}
+	if connection.Settings == nil {
+		connection.Settings = &ConnectionSettings{}
+	}
+	rules := connection.Settings.StatusEntryRules
+
+	switch args[1] {
+	case "list":
+		if len(rules) == 0 {
+			return p.responsef(header, "You have no status entry rules.\n%s", helpText)
+		}
+		lines := []string{}
+		for _, rule := range rules {
+			lines = append(lines, "* "+rule.String())
+		}
+		return p.responsef(header, "You get a DM when an issue moves into:\n%s", strings.Join(lines, "\n"))
+
+	case "clear":
+		rules = nil
+
+	case "add":
+		name, projectKey, err := parseStatusEntryRuleArgs(args[2:])
+		if err != nil {
+			return p.responsef(header, "%v.\n%s", err, helpText)
+		}
+		client, _, _, err := p.getClient(instanceID, mattermostUserID)
+		if err != nil {
+			return p.responsef(header, "%v", err)
+		}
+		status, err := resolveStatus(client, name, projectKey)
+		if err != nil {
+			return p.responsef(header, "%v.", err)
+		}
+		rule := statusEntryRule{StatusID: status.ID, Status: status.Name, Project: projectKey}
+		for _, existing := range rules {
+			if strings.EqualFold(existing.Status, rule.Status) && existing.Project == rule.Project {
+				return p.responsef(header, "You already have a rule for %s.", rule)
+			}
+		}
+		rules = append(rules, rule)
+
+	case "remove":
+		name, projectKey, err := parseStatusEntryRuleArgs(args[2:])
+		if err != nil {
+			return p.responsef(header, "%v.\n%s", err, helpText)
+		}
+		kept := []statusEntryRule{}
+		for _, existing := range rules {
+			if !strings.EqualFold(existing.Status, name) || existing.Project != projectKey {
+				kept = append(kept, existing)
+			}
+		}
+		if len(kept) == len(rules) {
+			return p.responsef(header, "You have no rule for %s.", statusEntryRule{Status: name, Project: projectKey})
+		}
+		rules = kept
+
+	default:
+		return p.responsef(header, "%s", helpText)
+	}
+
+	connection.Settings.StatusEntryRules = rules
+	if err := p.userStore.StoreConnection(instanceID, mattermostUserID, connection); err != nil {
+		p.errorf("settingsNotifyStatusEntry, err: %v", err)
+		return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
+	}
+	if err := p.updateStatusEntryUsers(instanceID, mattermostUserID, len(rules) > 0); err != nil {
+		p.errorf("settingsNotifyStatusEntry, err: %v", err)
+		return p.responsef(header, "Could not store new settings. Please contact your system administrator. error: %v", err)
+	}
+
+	if len(rules) == 0 {
+		return p.responsef(header, "Settings updated. You have no status entry rules.")
+	}
+	names := []string{}
+	for _, rule := range rules {
+		names = append(names, rule.String())
+	}
+	return p.responsef(header, "Settings updated. You will get a DM when an issue moves into: %s.", strings.Join(names, "; "))
+}
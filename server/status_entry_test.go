@@ -0,0 +1,102 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+func testStatusEntryWebhook(t *testing.T, changelog string) *webhook {
+	jwh := &JiraWebhook{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"issue": {"key": "QA-7", "fields": {"summary": "Fix the login", "issuetype": {"name": "Bug"}, "project": {"key": "QA"}}},
+		"user": {"displayName": "Jane", "accountId": "jane"},
+		"changelog": `+changelog+`}`), jwh))
+	return &webhook{JiraWebhook: jwh}
+}
+
+func TestStatusTransition(t *testing.T) {
+	toID, toName, ok := testStatusEntryWebhook(t, `{"items": [
+		{"field": "assignee", "from": "a", "to": "b"},
+		{"field": "status", "from": "1", "fromString": "To Do", "to": "3", "toString": "Ready for QA"}]}`).JiraWebhook.statusTransition()
+	assert.True(t, ok)
+	assert.Equal(t, "3", toID)
+	assert.Equal(t, "Ready for QA", toName)
+
+	_, _, ok = testStatusEntryWebhook(t, `{"items": [{"field": "assignee", "from": "a", "to": "b"}]}`).JiraWebhook.statusTransition()
+	assert.False(t, ok)
+}
+
+func TestStatusEntryRuleMatches(t *testing.T) {
+	rule := statusEntryRule{StatusID: "3", Status: "Ready for QA"}
+	assert.True(t, rule.matches("QA", "3", "Renamed"))
+	assert.True(t, rule.matches("QA", "10042", "ready for qa"))
+	assert.False(t, rule.matches("QA", "4", "Done"))
+
+	rule.Project = "QA"
+	assert.True(t, rule.matches("QA", "3", "Ready for QA"))
+	assert.False(t, rule.matches("WEB", "3", "Ready for QA"))
+}
+
+func TestParseStatusEntryRuleArgs(t *testing.T) {
+	status, projectKey, err := parseStatusEntryRuleArgs([]string{"Ready", "for", "QA", "--project", "qa"})
+	require.NoError(t, err)
+	assert.Equal(t, "Ready for QA", status)
+	assert.Equal(t, "QA", projectKey)
+
+	status, projectKey, err = parseStatusEntryRuleArgs([]string{"Done"})
+	require.NoError(t, err)
+	assert.Equal(t, "Done", status)
+	assert.Empty(t, projectKey)
+
+	for _, args := range [][]string{{}, {"--project", "QA"}, {"Done", "--project"}} {
+		_, _, err = parseStatusEntryRuleArgs(args)
+		assert.Error(t, err, args)
+	}
+}
+
+func TestStatusEntryNotifications(t *testing.T) {
+	api := &plugintest.API{}
+	userIDs, err := json.Marshal([]types.ID{"qa-lead", "web-dev", "assignee"})
+	require.NoError(t, err)
+	api.On("KVGet", keyWithInstanceID(testInstance1.GetID(), keyStatusEntryUsers)).Return(userIDs, nil)
+
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	rule := statusEntryRule{StatusID: "3", Status: "Ready for QA"}
+	p.userStore = mockUserStoreKV{
+		connections: map[types.ID]*Connection{
+			"qa-lead":  {User: jira.User{AccountID: "qa-lead-account"}, Settings: &ConnectionSettings{StatusEntryRules: []statusEntryRule{rule}}},
+			"web-dev":  {User: jira.User{AccountID: "web-dev-account"}, Settings: &ConnectionSettings{StatusEntryRules: []statusEntryRule{{Status: rule.Status, Project: "WEB"}}}},
+			"assignee": {User: jira.User{AccountID: "assignee-account"}, Settings: &ConnectionSettings{StatusEntryRules: []statusEntryRule{rule}}},
+		},
+	}
+
+	t.Run("into the status", func(t *testing.T) {
+		wh := testStatusEntryWebhook(t, `{"items": [{"field": "status", "from": "1", "fromString": "To Do", "to": "3", "toString": "Ready for QA"}]}`)
+		wh.notifications = []webhookUserNotification{{jiraAccountID: "assignee-account", message: "assigned"}}
+
+		notifications := p.statusEntryNotifications(testInstance1.GetID(), wh)
+		require.Len(t, notifications, 1)
+		assert.Equal(t, "qa-lead-account", notifications[0].jiraAccountID)
+		assert.True(t, notifications[0].statusEntry)
+		assert.Contains(t, notifications[0].message, "**moved**")
+		assert.Contains(t, notifications[0].message, "**Ready for QA**")
+	})
+
+	t.Run("out of the status", func(t *testing.T) {
+		wh := testStatusEntryWebhook(t, `{"items": [{"field": "status", "from": "3", "fromString": "Ready for QA", "to": "4", "toString": "Done"}]}`)
+		assert.Empty(t, p.statusEntryNotifications(testInstance1.GetID(), wh))
+	})
+}
@@ -40,6 +40,16 @@ const (
 	CommentVisibility          = "commentVisibility"
 	TeamFilter                 = "teamField"
 	CommentVisibilityGroupType = "group"
+
+	// Built-in styles a subscription can use to render its posts.
+	RenderStyleFull    = "full"
+	RenderStyleCompact = "compact"
+	RenderStyleTitle   = "title"
+
+	// The text of the link to the issue in the headline of the cards.
+	TitleLinkKey        = "key"
+	TitleLinkSummary    = "summary"
+	TitleLinkKeySummary = "key_summary"
 )
 
 type FieldFilter struct {
@@ -53,14 +63,58 @@ type SubscriptionFilters struct {
 	Projects   StringSet     `json:"projects"`
 	IssueTypes StringSet     `json:"issue_types"`
 	Fields     []FieldFilter `json:"fields"`
+
+	// JQL limits the subscription to the issues matching the query, e.g.
+	// the one of a saved Jira filter.
+	JQL string `json:"jql,omitempty"`
 }
 
 type ChannelSubscription struct {
-	ID         string              `json:"id"`
-	ChannelID  string              `json:"channel_id"`
-	Filters    SubscriptionFilters `json:"filters"`
-	Name       string              `json:"name"`
-	InstanceID types.ID            `json:"instance_id"`
+	ID          string              `json:"id"`
+	ChannelID   string              `json:"channel_id"`
+	Filters     SubscriptionFilters `json:"filters"`
+	Name        string              `json:"name"`
+	InstanceID  types.ID            `json:"instance_id"`
+	RenderStyle string              `json:"render_style,omitempty"`
+	TitleLink   string              `json:"title_link,omitempty"`
+	// LinkTypes are the issue links listed in the full cards of the
+	// subscription, by link description, e.g. "is blocked by".
+	LinkTypes StringSet `json:"link_types,omitempty"`
+
+	// JiraFilterID is the saved Jira filter whose JQL the subscription can
+	// be resynced with.
+	JiraFilterID string `json:"jira_filter_id,omitempty"`
+	// CreatedBy is the Mattermost user whose Jira connection is used to
+	// match issues against the JQL of the subscription.
+	CreatedBy types.ID `json:"created_by,omitempty"`
+	// ExcludeSelf skips the events triggered by service accounts, or by the
+	// changes made with the plugin.
+	ExcludeSelf bool `json:"exclude_self,omitempty"`
+	// OnCreateOnlyNew skips the creation events of the issues that the
+	// subscription already posted the creation of.
+	OnCreateOnlyNew bool `json:"on_create_only_new,omitempty"`
+	// WebhookToken is the token of the webhook of the subscription, whose
+	// events are only matched against it. A subscription with a webhook of
+	// its own is skipped by the subscriptions webhook of the instance.
+	WebhookToken string `json:"webhook_token,omitempty"`
+}
+
+// GetRenderStyle returns the style used to render the subscription's posts,
+// defaulting to the full card.
+func (s ChannelSubscription) GetRenderStyle() string {
+	if s.RenderStyle == "" {
+		return RenderStyleFull
+	}
+	return s.RenderStyle
+}
+
+// GetTitleLink returns the text of the link to the issue in the subscription's
+// posts, defaulting to the key and summary.
+func (s ChannelSubscription) GetTitleLink() string {
+	if s.TitleLink == "" {
+		return TitleLinkKeySummary
+	}
+	return s.TitleLink
 }
 
 type SubscriptionTemplate struct {
@@ -181,6 +235,12 @@ func (p *Plugin) getUserID() string {
 }
 
 func (p *Plugin) matchesSubsciptionFilters(wh *webhook, filters SubscriptionFilters) bool {
+	return p.subscriptionFiltersMismatch(wh, filters) == ""
+}
+
+// subscriptionFiltersMismatch returns why the event does not match the
+// filters, or "" if it does.
+func (p *Plugin) subscriptionFiltersMismatch(wh *webhook, filters SubscriptionFilters) string {
 	webhookEvents := wh.Events()
 	foundEvent := false
 	eventTypes := filters.Events
@@ -195,17 +255,17 @@ func (p *Plugin) matchesSubsciptionFilters(wh *webhook, filters SubscriptionFilt
 	}
 
 	if !foundEvent {
-		return false
+		return "the event is not one of its events"
 	}
 
 	issue := &wh.JiraWebhook.Issue
 
 	if filters.IssueTypes.Len() != 0 && !filters.IssueTypes.ContainsAny(issue.Fields.Type.ID) {
-		return false
+		return fmt.Sprintf("the issue type %s is not one of its issue types", issue.Fields.Type.Name)
 	}
 
 	if filters.Projects.Len() != 0 && !filters.Projects.ContainsAny(issue.Fields.Project.Key) {
-		return false
+		return fmt.Sprintf("the project %s is not one of its projects", issue.Fields.Project.Key)
 	}
 
 	containsSecurityLevelFilter := false
@@ -215,7 +275,7 @@ func (p *Plugin) matchesSubsciptionFilters(wh *webhook, filters SubscriptionFilt
 
 		// Broken filter, values must be provided
 		if inclusion == "" || (field.Values.Len() == 0 && inclusion != FilterEmpty) {
-			return false
+			return fmt.Sprintf("its filter on %s has no values", field.Key)
 		}
 
 		if field.Key == securityLevelField {
@@ -239,18 +299,18 @@ func (p *Plugin) matchesSubsciptionFilters(wh *webhook, filters SubscriptionFilt
 		}
 
 		if !isValidFieldInclusion(field, value, inclusion) {
-			return false
+			return fmt.Sprintf("its filter `%s %s` excludes it", field.Key, inclusion)
 		}
 	}
 
 	if !containsSecurityLevelFilter && useEmptySecurityLevel {
 		securityLevel := getIssueFieldValue(issue, securityLevelField)
 		if securityLevel.Len() > 0 {
-			return false
+			return "the issue has a security level, and the subscription has no security level filter"
 		}
 	}
 
-	return true
+	return ""
 }
 
 func updateCommentVisibilityValue(value StringSet, wh *webhook) StringSet {
@@ -311,6 +371,13 @@ func isValidFieldInclusion(field FieldFilter, value StringSet, inclusion string)
 }
 
 func (p *Plugin) getChannelsSubscribed(wh *webhook, instanceID types.ID) ([]ChannelSubscription, error) {
+	return p.getChannelsSubscribedVia(wh, instanceID, "")
+}
+
+// getChannelsSubscribedVia returns the subscriptions matching the event
+// received by the webhook of the subscription, or by the subscriptions
+// webhook of the instance when subscriptionID is empty.
+func (p *Plugin) getChannelsSubscribedVia(wh *webhook, instanceID types.ID, subscriptionID string) ([]ChannelSubscription, error) {
 	subs, err := p.getSubscriptions(instanceID)
 	if err != nil {
 		return nil, err
@@ -319,8 +386,25 @@ func (p *Plugin) getChannelsSubscribed(wh *webhook, instanceID types.ID) ([]Chan
 	var channelSubscriptions []ChannelSubscription
 	subscriptionMap := make(map[string]bool)
 	subIds := subs.Channel.ByID
+	var selfTriggered *bool
+	jqlMatched := map[string]bool{}
 	for _, sub := range subIds {
-		if p.matchesSubsciptionFilters(wh, sub.Filters) {
+		if subscriptionID != "" && sub.ID != subscriptionID {
+			continue
+		}
+		if subscriptionID == "" && sub.WebhookToken != "" {
+			continue
+		}
+		if sub.ExcludeSelf {
+			if selfTriggered == nil {
+				triggered := p.isSelfTriggered(instanceID, wh.JiraWebhook)
+				selfTriggered = &triggered
+			}
+			if *selfTriggered {
+				continue
+			}
+		}
+		if p.matchesSubsciptionFilters(wh, sub.Filters) && p.subscriptionJQLMatches(instanceID, sub, wh.Issue.Key, jqlMatched) {
 			if !subscriptionMap[sub.ChannelID] {
 				subscriptionMap[sub.ChannelID] = true
 				channelSubscriptions = append(channelSubscriptions, sub)
@@ -403,7 +487,7 @@ func (p *Plugin) getChannelSubscription(instanceID types.ID, subscriptionID stri
 
 func (p *Plugin) removeChannelSubscription(instanceID types.ID, subscriptionID string) error {
 	subKey := keyWithInstanceID(instanceID, JiraSubscriptionsKey)
-	return p.client.KV.SetAtomicWithRetries(subKey, func(initialBytes []byte) (interface{}, error) {
+	err := p.client.KV.SetAtomicWithRetries(subKey, func(initialBytes []byte) (interface{}, error) {
 		subs, err := SubscriptionsFromJSON(initialBytes, instanceID)
 		if err != nil {
 			return nil, err
@@ -423,6 +507,14 @@ func (p *Plugin) removeChannelSubscription(instanceID types.ID, subscriptionID s
 
 		return modifiedBytes, nil
 	})
+	if err != nil {
+		return err
+	}
+
+	if err = p.deleteAnnouncedIssues(instanceID, subscriptionID); err != nil {
+		p.client.Log.Warn("Failed to delete the issues announced by the removed subscription", "SubscriptionID", subscriptionID, "Error", err.Error())
+	}
+	return nil
 }
 
 func (p *Plugin) addChannelSubscription(instanceID types.ID, newSubscription *ChannelSubscription, client Client) error {
@@ -572,6 +664,10 @@ func (p *Plugin) validateSubscriptionTemplate(subscriptionTemplate *Subscription
 		return errors.New("please provide a project identifier")
 	}
 
+	if err := p.checkSubscriptionJQL(*subscriptionTemplate.Filters); err != nil {
+		return err
+	}
+
 	if _, err := client.GetProject(projectKey); err != nil {
 		return errors.WithMessagef(err, "failed to get project %q", projectKey)
 	}
@@ -599,10 +695,38 @@ func (p *Plugin) validateSubscription(instanceID types.ID, subscription *Channel
 		return errors.Errorf("please provide a name less than %d characters", MaxSubscriptionNameLength)
 	}
 
+	switch subscription.RenderStyle {
+	case "", RenderStyleFull, RenderStyleCompact, RenderStyleTitle:
+	default:
+		return errors.Errorf("unknown render style %q, please use one of: %s, %s, %s",
+			subscription.RenderStyle, RenderStyleFull, RenderStyleCompact, RenderStyleTitle)
+	}
+
+	switch subscription.TitleLink {
+	case "", TitleLinkKey, TitleLinkSummary, TitleLinkKeySummary:
+	default:
+		return errors.Errorf("unknown title link %q, please use one of: %s, %s, %s",
+			subscription.TitleLink, TitleLinkKey, TitleLinkSummary, TitleLinkKeySummary)
+	}
+
 	if len(subscription.Filters.Events) == 0 {
 		return errors.New("please provide at least one event type")
 	}
 
+	// The JQL of a subscription can take the place of its projects and
+	// issue types.
+	if subscription.Filters.JQL != "" {
+		if err := p.checkSubscriptionJQL(subscription.Filters); err != nil {
+			return err
+		}
+		if err := p.validateJQL(instanceID, client, subscription.Filters.JQL); err != nil {
+			return err
+		}
+		if subscription.Filters.Projects.Len() == 0 {
+			return p.validateSubscriptionNameUnique(instanceID, subscription)
+		}
+	}
+
 	if len(subscription.Filters.IssueTypes) == 0 {
 		return errors.New("please provide at least one issue type")
 	}
@@ -642,8 +766,19 @@ func (p *Plugin) validateSubscription(instanceID types.ID, subscription *Channel
 		}
 	}
 
-	channelID := subscription.ChannelID
-	subs, err := p.getSubscriptionsForChannel(instanceID, channelID)
+	if err := p.validateSubscriptionNameUnique(instanceID, subscription); err != nil {
+		return err
+	}
+
+	if _, err := client.GetProject(projectKey); err != nil {
+		return errors.WithMessagef(err, "failed to get project %q", projectKey)
+	}
+
+	return nil
+}
+
+func (p *Plugin) validateSubscriptionNameUnique(instanceID types.ID, subscription *ChannelSubscription) error {
+	subs, err := p.getSubscriptionsForChannel(instanceID, subscription.ChannelID)
 	if err != nil {
 		return err
 	}
@@ -653,12 +788,6 @@ func (p *Plugin) validateSubscription(instanceID types.ID, subscription *Channel
 			return errors.Errorf("Subscription name, '%s', already exists. Please choose another name.", subs[subID].Name)
 		}
 	}
-
-	_, err = client.GetProject(projectKey)
-	if err != nil {
-		return errors.WithMessagef(err, "failed to get project %q", projectKey)
-	}
-
 	return nil
 }
 
@@ -715,6 +844,16 @@ func (p *Plugin) editChannelSubscription(instanceID types.ID, modifiedSubscripti
 		if !ok {
 			return nil, errors.New("existing subscription does not exist")
 		}
+		// The subscription modal does not edit these.
+		if modifiedSubscription.JiraFilterID == "" {
+			modifiedSubscription.JiraFilterID = oldSub.JiraFilterID
+		}
+		if modifiedSubscription.CreatedBy == "" {
+			modifiedSubscription.CreatedBy = oldSub.CreatedBy
+		}
+		modifiedSubscription.ExcludeSelf = oldSub.ExcludeSelf
+		modifiedSubscription.OnCreateOnlyNew = oldSub.OnCreateOnlyNew
+		modifiedSubscription.WebhookToken = oldSub.WebhookToken
 
 		err = p.validateSubscription(instanceID, modifiedSubscription, client)
 		if err != nil {
@@ -749,7 +888,9 @@ type SubsGroupedByChannel struct {
 	SubIds     []string
 }
 
-func (p *Plugin) listChannelSubscriptions(instanceID types.ID, teamID string) (string, error) {
+// listChannelSubscriptions lists the subscriptions of the channels, with their
+// filters resolved to names with the Jira connection of the user.
+func (p *Plugin) listChannelSubscriptions(instanceID types.ID, teamID string, mattermostUserID types.ID) (string, error) {
 	sortedSubs, err := p.getSortedSubscriptions(instanceID)
 	if err != nil {
 		return "", err
@@ -762,6 +903,7 @@ func (p *Plugin) listChannelSubscriptions(instanceID types.ID, teamID string) (s
 		return strings.Join(rows, "\n"), nil
 	}
 	rows = append(rows, "The following channels have subscribed to Jira notifications. To modify a subscription, navigate to the channel and type `/jira subscribe edit`")
+	filterNames := map[types.ID]*subscriptionFilterNames{}
 
 	for _, teamSubs := range sortedSubs {
 		// create header for each Team, DM and GM channels
@@ -787,6 +929,9 @@ func (p *Plugin) listChannelSubscriptions(instanceID types.ID, teamID string) (s
 					return "", errors.New("failed to get subs")
 				}
 				rows = append(rows, fmt.Sprintf("\t* (%d) %s", len(subsIDs), instanceID))
+				if filterNames[instanceID] == nil {
+					filterNames[instanceID] = p.newSubscriptionFilterNames(instanceID, mattermostUserID)
+				}
 
 				channelSubscriptions := []ChannelSubscription{}
 				for _, subID := range subsIDs {
@@ -803,7 +948,15 @@ func (p *Plugin) listChannelSubscriptions(instanceID types.ID, teamID string) (s
 				})
 
 				for _, channelSubscription := range channelSubscriptions {
-					rows = append(rows, fmt.Sprintf("\t\t* %s - %s", channelSubscription.Filters.Projects.Elems()[0], channelSubscription.Name))
+					scope := "JQL"
+					if channelSubscription.Filters.Projects.Len() > 0 {
+						scope = channelSubscription.Filters.Projects.Elems()[0]
+					}
+					row := fmt.Sprintf("\t\t* %s - %s", scope, channelSubscription.Name)
+					if filters := mdSubscriptionFilters(channelSubscription.Filters, filterNames[instanceID]); filters != "" {
+						row += " - " + filters
+					}
+					rows = append(rows, row)
 				}
 			}
 		}
@@ -1061,17 +1214,19 @@ func (p *Plugin) httpSubscribeWebhook(w http.ResponseWriter, r *http.Request, in
 		p.client.Log.Debug("Webhook Event Log", "event", string(bb))
 	}
 
+	if r.FormValue(testWebhookParam) == "true" {
+		return p.httpTestWebhook(w, bb, instanceID)
+	}
+
 	// If there is space in the queue, immediately return a 200; we will process the webhook event async.
 	// If the queue is full, return a 503; we will not process that webhook event.
-	select {
-	case p.webhookQueue <- &webhookMessage{
+	if !p.webhookDispatcher.Enqueue(&webhookMessage{
 		InstanceID: instanceID,
 		Data:       bb,
-	}:
-		return http.StatusOK, nil
-	default:
+	}) {
 		return respondErr(w, http.StatusServiceUnavailable, nil)
 	}
+	return http.StatusOK, nil
 }
 
 func (p *Plugin) httpChannelCreateSubscription(w http.ResponseWriter, r *http.Request) (int, error) {
@@ -1124,7 +1279,7 @@ func (p *Plugin) httpChannelCreateSubscription(w http.ResponseWriter, r *http.Re
 		return code, err
 	}
 
-	err = p.client.Post.CreatePost(&model.Post{
+	err = p.createPost(&model.Post{
 		UserId:    p.getConfig().botUserID,
 		ChannelId: subscription.ChannelID,
 		Message:   fmt.Sprintf("Jira subscription, \"%v\", was added to this channel by %v", subscription.Name, connection.DisplayName),
@@ -1186,7 +1341,7 @@ func (p *Plugin) httpChannelEditSubscription(w http.ResponseWriter, r *http.Requ
 		return code, err
 	}
 
-	err = p.client.Post.CreatePost(&model.Post{
+	err = p.createPost(&model.Post{
 		UserId:    p.getConfig().botUserID,
 		ChannelId: subscription.ChannelID,
 		Message:   fmt.Sprintf("Jira subscription, \"%v\", was updated by %v", subscription.Name, connection.DisplayName),
@@ -1242,7 +1397,7 @@ func (p *Plugin) httpChannelDeleteSubscription(w http.ResponseWriter, r *http.Re
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
-	err = p.client.Post.CreatePost(&model.Post{
+	err = p.createPost(&model.Post{
 		UserId:    p.getConfig().botUserID,
 		ChannelId: subscription.ChannelID,
 		Message:   fmt.Sprintf("Jira subscription, \"%v\", was removed from this channel by %v", subscription.Name, connection.DisplayName),
@@ -0,0 +1,76 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	jira "github.com/andygrunwald/go-jira"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// maxProjectKeyCandidates bounds the number of words of a channel header and
+// purpose that are looked up as Jira projects.
+const maxProjectKeyCandidates = 10
+
+// projectKeyCandidateRegexp matches the words that look like a Jira project
+// key, on their own or as the prefix of an issue key.
+var projectKeyCandidateRegexp = regexp.MustCompile(`\b([A-Z][A-Z0-9_]{1,9})(?:-[0-9]+)?\b`)
+
+// projectKeyCandidates returns the words of the texts that look like a Jira
+// project key, in order and without duplicates.
+func projectKeyCandidates(texts ...string) []string {
+	seen := map[string]bool{}
+	candidates := []string{}
+	for _, text := range texts {
+		for _, match := range projectKeyCandidateRegexp.FindAllStringSubmatch(text, -1) {
+			key := match[1]
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			candidates = append(candidates, key)
+			if len(candidates) == maxProjectKeyCandidates {
+				return candidates
+			}
+		}
+	}
+	return candidates
+}
+
+// findProjectInTexts returns the first project of the Jira instance whose key
+// appears in the texts, or nil if there is none.
+func findProjectInTexts(client Client, texts ...string) *jira.Project {
+	for _, key := range projectKeyCandidates(texts...) {
+		project, err := client.GetProject(key)
+		if err == nil && project != nil {
+			return project
+		}
+	}
+	return nil
+}
+
+// newAutoSubscription returns the default subscription of a channel to a
+// project: its issues of any type, when created or updated, which includes
+// their transitions.
+func newAutoSubscription(instanceID types.ID, channelID string, project *jira.Project) *ChannelSubscription {
+	issueTypeIDs := []string{}
+	for _, issueType := range project.IssueTypes {
+		issueTypeIDs = append(issueTypeIDs, issueType.ID)
+	}
+
+	return &ChannelSubscription{
+		ChannelID:  channelID,
+		InstanceID: instanceID,
+		Name:       fmt.Sprintf("%s issues", project.Key),
+		Filters: SubscriptionFilters{
+			Events:     NewStringSet(eventCreated, eventUpdatedAny),
+			Projects:   NewStringSet(project.Key),
+			IssueTypes: NewStringSet(issueTypeIDs...),
+			Fields:     []FieldFilter{},
+		},
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type projectsClient struct {
+	testClient
+	projects map[string]*jira.Project
+}
+
+func (client projectsClient) GetProject(key string) (*jira.Project, error) {
+	project, ok := client.projects[key]
+	if !ok {
+		return nil, errors.New("Project " + key + " not found")
+	}
+	return project, nil
+}
+
+func TestProjectKeyCandidates(t *testing.T) {
+	assert.Equal(t, []string{}, projectKeyCandidates("", "no keys here"))
+	assert.Equal(t, []string{"KT", "MM", "OPS_2"},
+		projectKeyCandidates("Team KT, see KT-12 and MM-7", "Escalations go to OPS_2. A"))
+}
+
+func TestFindProjectInTexts(t *testing.T) {
+	client := projectsClient{
+		projects: map[string]*jira.Project{
+			"MM": {Key: "MM", Name: "Mattermost", IssueTypes: []jira.IssueType{{ID: "10001"}, {ID: "10002"}}},
+		},
+	}
+
+	t.Run("the first key of an existing project is found", func(t *testing.T) {
+		project := findProjectInTexts(client, "RFC: the WIP board", "Our issues: MM-1234")
+		require.NotNil(t, project)
+		assert.Equal(t, "MM", project.Key)
+	})
+
+	t.Run("no project", func(t *testing.T) {
+		assert.Nil(t, findProjectInTexts(client, "FAQ is pinned", ""))
+	})
+
+	t.Run("the default subscription", func(t *testing.T) {
+		sub := newAutoSubscription(testInstance1.InstanceID, "channel1", client.projects["MM"])
+		assert.Equal(t, "MM issues", sub.Name)
+		assert.Equal(t, "channel1", sub.ChannelID)
+		assert.Equal(t, testInstance1.InstanceID, sub.InstanceID)
+		assert.True(t, sub.Filters.Events.Equals(NewStringSet(eventCreated, eventUpdatedAny)))
+		assert.True(t, sub.Filters.Projects.Equals(NewStringSet("MM")))
+		assert.True(t, sub.Filters.IssueTypes.Equals(NewStringSet("10001", "10002")))
+	})
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// jqlClauses splits a JQL query into its top-level clauses: those joined by
+// OR if there are any, since AND binds tighter, or else those joined by AND.
+// It also returns the query without its ORDER BY, which cannot be nested in
+// another query.
+func jqlClauses(jql string) (query string, clauses []string, connective string) {
+	spans := jqlTokenRegexp.FindAllStringIndex(jql, -1)
+	end := len(jql)
+	depth := 0
+	ands, ors := [][]int{}, [][]int{}
+	for i, span := range spans {
+		token := strings.ToLower(jql[span[0]:span[1]])
+		switch {
+		case token == "(":
+			depth++
+		case token == ")":
+			depth--
+		case depth != 0:
+		case token == "and":
+			ands = append(ands, span)
+		case token == "or":
+			ors = append(ors, span)
+		case token == "order" && i+1 < len(spans) && strings.EqualFold(jql[spans[i+1][0]:spans[i+1][1]], "by"):
+			end = span[0]
+		}
+		if end != len(jql) {
+			break
+		}
+	}
+
+	separators, connective := ands, "AND"
+	if len(ors) > 0 {
+		separators, connective = ors, "OR"
+	}
+	start := 0
+	for _, span := range separators {
+		clauses = append(clauses, jql[start:span[0]])
+		start = span[1]
+	}
+	clauses = append(clauses, jql[start:end])
+
+	nonEmpty := []string{}
+	for _, clause := range clauses {
+		if clause = normalizeJQL(clause); clause != "" {
+			nonEmpty = append(nonEmpty, clause)
+		}
+	}
+	return normalizeJQL(jql[:end]), nonEmpty, connective
+}
+
+// jqlMatchesIssue tells whether the issue matches the JQL query.
+func jqlMatchesIssue(client Client, issueKey, jql string) (bool, error) {
+	// Only the total is needed, but the client leaves out a maxResults of 0.
+	_, total, err := client.SearchIssuesWithTotal(fmt.Sprintf("issuekey = %s AND (%s)", issueKey, jql), &jira.SearchOptions{
+		MaxResults: 1,
+		Fields:     []string{"key"},
+	})
+	if err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// dryRunJQL explains whether the issue matches the JQL query, and which of its
+// clauses the issue satisfies, as seen by the user of the client. It does not
+// change anything.
+func (p *Plugin) dryRunJQL(instanceID types.ID, client Client, issueKey, jql string) (string, error) {
+	if err := p.validateJQL(instanceID, client, jql); err != nil {
+		return "", err
+	}
+	if _, err := client.GetIssue(issueKey, &jira.GetQueryOptions{Fields: "key"}); err != nil {
+		return "", errors.Errorf("the issue %s does not exist, or you do not have access to it", issueKey)
+	}
+
+	query, clauses, connective := jqlClauses(jql)
+	matches, err := jqlMatchesIssue(client, issueKey, query)
+	if err != nil {
+		return "", errors.WithMessagef(err, "failed to match %s", issueKey)
+	}
+
+	msg := fmt.Sprintf("#### Dry run of `%s` against %s\n", query, issueKey)
+	if matches {
+		msg += fmt.Sprintf(":white_check_mark: %s matches the query, a subscription with it would be notified of the events of %s.\n", issueKey, issueKey)
+	} else {
+		msg += fmt.Sprintf(":x: %s does not match the query, a subscription with it would not be notified of the events of %s.\n", issueKey, issueKey)
+	}
+	if len(clauses) < 2 {
+		return msg, nil
+	}
+
+	if connective == "OR" {
+		msg += "\nThe issue matches the query if it satisfies any of its clauses:\n"
+	} else {
+		msg += "\nThe issue matches the query if it satisfies all of its clauses:\n"
+	}
+	for _, clause := range clauses {
+		satisfied, err := jqlMatchesIssue(client, issueKey, clause)
+		switch {
+		case err != nil:
+			msg += fmt.Sprintf("* :warning: `%s` could not be checked: %v\n", clause, err)
+		case satisfied:
+			msg += fmt.Sprintf("* :white_check_mark: `%s`\n", clause)
+		default:
+			msg += fmt.Sprintf("* :x: `%s`\n", clause)
+		}
+	}
+	return msg, nil
+}
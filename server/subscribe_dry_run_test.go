@@ -0,0 +1,100 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dryRunSearchClient matches the issue with the queries ending with one of
+// the satisfied clauses, or with the whole query.
+type dryRunSearchClient struct {
+	jqlSearchClient
+	satisfied []string
+	queries   *[]string
+}
+
+func (client dryRunSearchClient) SearchIssuesWithTotal(jql string, options *jira.SearchOptions) ([]jira.Issue, int, error) {
+	*client.queries = append(*client.queries, jql)
+	for _, clause := range client.satisfied {
+		if strings.HasSuffix(jql, "("+clause+")") {
+			return []jira.Issue{{Key: "KT-1"}}, 1, nil
+		}
+	}
+	return nil, 0, nil
+}
+
+func TestJQLClauses(t *testing.T) {
+	for jql, expected := range map[string]struct {
+		query      string
+		clauses    []string
+		connective string
+	}{
+		"project = KT": {"project = KT", []string{"project = KT"}, "AND"},
+		"project = KT AND status = Open AND labels in (a, b)": {
+			"project = KT AND status = Open AND labels in (a, b)", []string{"project = KT", "status = Open", "labels in (a, b)"}, "AND",
+		},
+		"project = KT AND (status = Open OR status = Done) ORDER BY created DESC": {
+			"project = KT AND (status = Open OR status = Done)", []string{"project = KT", "(status = Open OR status = Done)"}, "AND",
+		},
+		`project = KT AND status = Open or summary ~ "a and b"`: {
+			`project = KT AND status = Open or summary ~ "a and b"`, []string{"project = KT AND status = Open", `summary ~ "a and b"`}, "OR",
+		},
+	} {
+		query, clauses, connective := jqlClauses(jql)
+		assert.Equal(t, expected.query, query, jql)
+		assert.Equal(t, expected.clauses, clauses, jql)
+		assert.Equal(t, expected.connective, connective, jql)
+	}
+}
+
+func TestDryRunJQL(t *testing.T) {
+	t.Run("explains the clauses", func(t *testing.T) {
+		p := &Plugin{}
+		calls := 0
+		queries := []string{}
+		client := dryRunSearchClient{jqlSearchClient: jqlSearchClient{calls: &calls}, satisfied: []string{"project = KT"}, queries: &queries}
+
+		msg, err := p.dryRunJQL("jiraurl1", client, "KT-1", "project = KT AND status = Open ORDER BY key")
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"issuekey = KT-1 AND (project = KT AND status = Open)",
+			"issuekey = KT-1 AND (project = KT)",
+			"issuekey = KT-1 AND (status = Open)",
+		}, queries)
+		assert.Contains(t, msg, ":x: KT-1 does not match the query")
+		assert.Contains(t, msg, "all of its clauses")
+		assert.Contains(t, msg, "* :white_check_mark: `project = KT`\n* :x: `status = Open`\n")
+	})
+
+	t.Run("matches a single clause", func(t *testing.T) {
+		p := &Plugin{}
+		calls := 0
+		queries := []string{}
+		client := dryRunSearchClient{jqlSearchClient: jqlSearchClient{calls: &calls}, satisfied: []string{"project = KT"}, queries: &queries}
+
+		msg, err := p.dryRunJQL("jiraurl1", client, "KT-1", "project = KT")
+		require.NoError(t, err)
+		assert.Len(t, queries, 1)
+		assert.Contains(t, msg, ":white_check_mark: KT-1 matches the query")
+		assert.NotContains(t, msg, "clauses")
+	})
+
+	t.Run("the issue does not exist", func(t *testing.T) {
+		p := &Plugin{}
+		calls := 0
+		queries := []string{}
+		client := dryRunSearchClient{jqlSearchClient: jqlSearchClient{calls: &calls}, queries: &queries}
+
+		_, err := p.dryRunJQL("jiraurl1", client, nonExistantIssueKey, "project = KT")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not exist, or you do not have access to it")
+		assert.Empty(t, queries)
+	})
+}
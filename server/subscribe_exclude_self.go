@@ -0,0 +1,154 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	prefixSelfChange = "self_change_"
+
+	// selfChangeExpiry is how long a change made with the plugin is waited
+	// for to come back as a webhook event.
+	selfChangeExpiry = 2 * time.Minute
+)
+
+func parseServiceAccountIDs(setting string) StringSet {
+	ids := NewStringSet()
+	for _, id := range strings.Split(setting, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = ids.Add(id)
+		}
+	}
+	return ids
+}
+
+func selfChangeKey(instanceID types.ID, issueKey string, mattermostUserID types.ID) string {
+	return hashkey(prefixSelfChange, instanceID.String()+"/"+strings.ToUpper(issueKey)+"/"+mattermostUserID.String())
+}
+
+// recordSelfChange remembers, for a little while, that the user changed the
+// issue with the plugin, so that the webhook event it triggers can be told
+// apart from the changes made in Jira. Nothing is recorded when no
+// subscription of the instance excludes these events.
+func (p *Plugin) recordSelfChange(instanceID types.ID, issueKey string, mattermostUserID types.ID) {
+	if !p.hasExcludeSelfSubscription(instanceID) {
+		return
+	}
+	_, err := p.client.KV.Set(selfChangeKey(instanceID, issueKey, mattermostUserID), true, pluginapi.SetExpiry(selfChangeExpiry))
+	if err != nil {
+		p.client.Log.Warn("Failed to record a change made with the plugin", "IssueKey", issueKey, "Error", err.Error())
+	}
+}
+
+// hasExcludeSelfSubscription reports whether a subscription of the instance
+// excludes the events triggered with the plugin. It errs on the side of yes
+// when the subscriptions cannot be read.
+func (p *Plugin) hasExcludeSelfSubscription(instanceID types.ID) bool {
+	subs, err := p.getSubscriptions(instanceID)
+	if err != nil {
+		return true
+	}
+	for _, sub := range subs.Channel.ByID {
+		if sub.ExcludeSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookActors returns the Jira users who triggered the event: the user of
+// the event, and the author of its comment, if any.
+func (jwh *JiraWebhook) webhookActors() []string {
+	actors := []string{}
+	for _, user := range []struct{ accountID, name string }{
+		{jwh.User.AccountID, jwh.User.Name},
+		{jwh.Comment.UpdateAuthor.AccountID, jwh.Comment.UpdateAuthor.Name},
+		{jwh.Comment.Author.AccountID, jwh.Comment.Author.Name},
+	} {
+		switch {
+		case user.accountID != "":
+			actors = append(actors, user.accountID)
+		case user.name != "":
+			actors = append(actors, user.name)
+		}
+	}
+	return actors
+}
+
+// isSelfTriggered reports whether the event was triggered by a service
+// account, or by a change that a connected user made with the plugin.
+func (p *Plugin) isSelfTriggered(instanceID types.ID, jwh *JiraWebhook) bool {
+	serviceAccounts := p.getConfig().serviceAccountIDs
+	for _, actor := range jwh.webhookActors() {
+		if serviceAccounts.ContainsAny(actor) {
+			return true
+		}
+		mattermostUserID, err := p.userStore.LoadMattermostUserID(instanceID, actor)
+		if err != nil || mattermostUserID == "" {
+			continue
+		}
+		var changed bool
+		err = p.client.KV.Get(selfChangeKey(instanceID, jwh.Issue.Key, mattermostUserID), &changed)
+		if err == nil && changed {
+			return true
+		}
+	}
+	return false
+}
+
+// setSubscriptionExcludeSelf turns on or off the exclusion of self triggered
+// events for a subscription.
+func (p *Plugin) setSubscriptionExcludeSelf(instanceID types.ID, subscriptionID string, excludeSelf bool) error {
+	return p.updateSubscription(instanceID, subscriptionID, func(sub *ChannelSubscription) {
+		sub.ExcludeSelf = excludeSelf
+	})
+}
+
+func executeSubscribeExcludeSelf(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	_, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to identify the Jira instance. Error: %v.", err)
+	}
+	if len(args) < 2 || (args[0] != settingOn && args[0] != settingOff) {
+		return p.responsef(header, "Please use `/jira subscribe exclude-self [on|off] [subscription]`.")
+	}
+
+	if err = p.hasPermissionToManageSubscription(instance.GetID(), header.UserId, header.ChannelId); err != nil {
+		return p.responsef(header, "You do not have permission to manage the Jira subscriptions of this channel: %v.", err)
+	}
+
+	subs, err := p.getSubscriptionsForChannel(instance.GetID(), header.ChannelId)
+	if err != nil {
+		return p.responsef(header, "Failed to load the subscriptions of this channel. Error: %v.", err)
+	}
+	search := strings.Join(args[1:], " ")
+	var subscription *ChannelSubscription
+	for i := range subs {
+		if subs[i].ID == search || subs[i].Name == search {
+			subscription = &subs[i]
+			break
+		}
+	}
+	if subscription == nil {
+		return p.responsef(header, "This channel has no subscription `%s`.", search)
+	}
+
+	excludeSelf := args[0] == settingOn
+	if err = p.setSubscriptionExcludeSelf(instance.GetID(), subscription.ID, excludeSelf); err != nil {
+		return p.responsef(header, "Failed to update the subscription. Error: %v.", err)
+	}
+	if excludeSelf {
+		return p.responsef(header, "The subscription **%s** now skips the events triggered by service accounts, and by the changes made with the plugin.", subscription.Name)
+	}
+	return p.responsef(header, "The subscription **%s** now posts all the events it matches.", subscription.Name)
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+type selfChangeUserStore struct {
+	mockUserStore
+	mattermostUserIDs map[string]types.ID
+}
+
+func (store selfChangeUserStore) LoadMattermostUserID(instanceID types.ID, jiraUserNameOrID string) (types.ID, error) {
+	return store.mattermostUserIDs[jiraUserNameOrID], nil
+}
+
+func TestParseServiceAccountIDs(t *testing.T) {
+	assert.Equal(t, NewStringSet("automation", "5b10ac8d82e05b22cc7d4ef5"), parseServiceAccountIDs(" automation, ,5b10ac8d82e05b22cc7d4ef5 "))
+	assert.Equal(t, NewStringSet(), parseServiceAccountIDs(""))
+}
+
+func TestIsSelfTriggered(t *testing.T) {
+	for name, tc := range map[string]struct {
+		actor     jira.User
+		comment   jira.Comment
+		issueKey  string
+		recorded  bool
+		triggered bool
+	}{
+		"external user": {
+			actor:    jira.User{AccountID: "external"},
+			issueKey: "TEST-1",
+		},
+		"service account": {
+			actor:     jira.User{AccountID: "service"},
+			issueKey:  "TEST-1",
+			triggered: true,
+		},
+		"service account by username": {
+			actor:     jira.User{Name: "automation"},
+			issueKey:  "TEST-1",
+			triggered: true,
+		},
+		"comment by a service account": {
+			actor:     jira.User{AccountID: "external"},
+			comment:   jira.Comment{Author: jira.User{AccountID: "service"}},
+			issueKey:  "TEST-1",
+			triggered: true,
+		},
+		"change made with the plugin": {
+			actor:     jira.User{AccountID: "connected"},
+			issueKey:  "TEST-1",
+			recorded:  true,
+			triggered: true,
+		},
+		"change made in Jira by a connected user": {
+			actor:    jira.User{AccountID: "connected"},
+			issueKey: "TEST-1",
+		},
+		"change made with the plugin to another issue": {
+			actor:    jira.User{AccountID: "connected"},
+			issueKey: "TEST-2",
+			recorded: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := &Plugin{}
+			p.updateConfig(func(conf *config) {
+				conf.serviceAccountIDs = parseServiceAccountIDs("service,automation")
+			})
+			p.userStore = selfChangeUserStore{mattermostUserIDs: map[string]types.ID{"connected": "mm_user"}}
+
+			api := &plugintest.API{}
+			if tc.recorded {
+				api.On("KVGet", selfChangeKey(testInstance1.InstanceID, "TEST-1", "mm_user")).Return([]byte("true"), nil)
+			}
+			api.On("KVGet", mock.AnythingOfType("string")).Return(nil, nil)
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+
+			jwh := &JiraWebhook{
+				User:    tc.actor,
+				Comment: tc.comment,
+				Issue:   jira.Issue{Key: tc.issueKey},
+			}
+			assert.Equal(t, tc.triggered, p.isSelfTriggered(testInstance1.InstanceID, jwh))
+		})
+	}
+}
+
+func TestRecordSelfChange(t *testing.T) {
+	for name, excludeSelf := range map[string]bool{
+		"a subscription excludes self triggered events": true,
+		"no subscription excludes them":                 false,
+	} {
+		t.Run(name, func(t *testing.T) {
+			subs := NewSubscriptions()
+			subs.Channel.add(&ChannelSubscription{ID: "sub1", ChannelID: "channel1", ExcludeSelf: excludeSelf})
+			data, err := json.Marshal(subs)
+			require.NoError(t, err)
+
+			api := &plugintest.API{}
+			api.On("KVGet", keyWithInstanceID(testInstance1.InstanceID, JiraSubscriptionsKey)).Return(data, (*model.AppError)(nil))
+			api.On("KVSetWithOptions", mock.Anything, mock.Anything, mock.Anything).Return(true, (*model.AppError)(nil))
+			p := &Plugin{}
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+
+			p.recordSelfChange(testInstance1.InstanceID, "TEST-1", "user1")
+			if excludeSelf {
+				api.AssertCalled(t, "KVSetWithOptions", selfChangeKey(testInstance1.InstanceID, "TEST-1", "user1"), mock.Anything, mock.Anything)
+			} else {
+				api.AssertNotCalled(t, "KVSetWithOptions", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
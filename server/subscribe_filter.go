@@ -0,0 +1,213 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const subscribeFilterPinFlag = "--pin"
+
+// jqlOrderByRegexp matches the ORDER BY clause that ends the JQL of most
+// saved filters, which has no use when matching a single issue.
+var jqlOrderByRegexp = regexp.MustCompile(`(?is)\s*\border\s+by\s.*$`)
+
+func stripJQLOrderBy(jql string) string {
+	return strings.TrimSpace(jqlOrderByRegexp.ReplaceAllString(jql, ""))
+}
+
+// newFilterSubscription returns the subscription of a channel to the issues,
+// created or updated, that match the JQL of a saved Jira filter. With pin,
+// it remembers the filter so that its JQL can be resynced later.
+func newFilterSubscription(instanceID types.ID, channelID string, mattermostUserID types.ID, filter *jira.Filter, pin bool) *ChannelSubscription {
+	subscription := &ChannelSubscription{
+		ChannelID:  channelID,
+		InstanceID: instanceID,
+		Name:       truncate("Filter: "+filter.Name, MaxSubscriptionNameLength),
+		CreatedBy:  mattermostUserID,
+		Filters: SubscriptionFilters{
+			Events:     NewStringSet(eventCreated, eventUpdatedAny),
+			Projects:   NewStringSet(),
+			IssueTypes: NewStringSet(),
+			Fields:     []FieldFilter{},
+			JQL:        stripJQLOrderBy(filter.Jql),
+		},
+	}
+	if pin {
+		subscription.JiraFilterID = filter.ID
+	}
+	return subscription
+}
+
+// getJiraFilter returns a saved filter that the user of the client can see.
+func getJiraFilter(client Client, filterID string) (*jira.Filter, error) {
+	id, err := strconv.Atoi(filterID)
+	if err != nil || id <= 0 {
+		return nil, errors.Errorf("`%s` is not a Jira filter ID, the ID is the number in the URL of the filter, e.g. 10042 in `filter=10042`", filterID)
+	}
+	filter, err := client.GetFilter(id)
+	if err != nil {
+		switch StatusCode(err) {
+		case http.StatusNotFound, http.StatusBadRequest, http.StatusForbidden, http.StatusUnauthorized:
+			return nil, errors.Errorf("the Jira filter %d does not exist, or you do not have access to it", id)
+		}
+		return nil, errors.WithMessagef(err, "failed to get the Jira filter %d", id)
+	}
+	if strings.TrimSpace(filter.Jql) == "" {
+		return nil, errors.Errorf("the Jira filter %d has no JQL query", id)
+	}
+	return filter, nil
+}
+
+// subscriptionJQLMatches reports whether the issue matches the JQL of the
+// subscription, as seen by the user who created the subscription. The results
+// are kept in matched, by user and query, so that the subscriptions of an
+// event that share them are evaluated with a single search.
+func (p *Plugin) subscriptionJQLMatches(instanceID types.ID, sub ChannelSubscription, issueKey string, matched map[string]bool) bool {
+	if sub.Filters.JQL == "" {
+		return true
+	}
+	if issueKey == "" {
+		return false
+	}
+	if sub.CreatedBy == "" {
+		p.client.Log.Warn("A subscription with JQL has no user to evaluate it as, nothing is posted for it", "subscription", sub.ID, "channel", sub.ChannelID)
+		return false
+	}
+
+	key := sub.CreatedBy.String() + "/" + sub.Filters.JQL
+	if result, ok := matched[key]; ok {
+		return result
+	}
+	result := p.searchSubscriptionJQL(instanceID, sub, issueKey)
+	matched[key] = result
+	return result
+}
+
+func (p *Plugin) searchSubscriptionJQL(instanceID types.ID, sub ChannelSubscription, issueKey string) bool {
+	client, _, _, err := p.getClient(instanceID, sub.CreatedBy)
+	if err != nil {
+		p.client.Log.Warn("The user of a subscription with JQL is not connected to Jira, nothing is posted for it until they reconnect",
+			"subscription", sub.ID, "channel", sub.ChannelID, "user", sub.CreatedBy, "error", err.Error())
+		return false
+	}
+	issues, err := client.SearchIssues(fmt.Sprintf("issuekey = %s AND (%s)", issueKey, sub.Filters.JQL), &jira.SearchOptions{
+		MaxResults: 1,
+		Fields:     []string{"key"},
+	})
+	if err != nil {
+		p.client.Log.Debug("Failed to match the JQL of a subscription", "subscription", sub.ID, "error", err.Error())
+		return false
+	}
+	return len(issues) > 0
+}
+
+func executeSubscribeImportFromFilter(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to identify the Jira instance. Error: %v.", err)
+	}
+	pin := len(args) == 2 && args[1] == subscribeFilterPinFlag
+	if len(args) != 1 && !pin {
+		return p.responsef(header, "Please specify the ID of a saved Jira filter, e.g. `/jira subscribe import-from-filter 10042 [--pin]`.")
+	}
+
+	if err = p.hasPermissionToManageSubscription(instance.GetID(), header.UserId, header.ChannelId); err != nil {
+		return p.responsef(header, "You do not have permission to manage the Jira subscriptions of this channel: %v.", err)
+	}
+
+	client, _, connection, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+
+	filter, err := getJiraFilter(client, args[0])
+	if err != nil {
+		return p.responsef(header, "Failed to import the filter: %v.", err)
+	}
+
+	subscription := newFilterSubscription(instance.GetID(), header.ChannelId, user.MattermostUserID, filter, pin)
+	if err = p.addChannelSubscription(instance.GetID(), subscription, client); err != nil {
+		return p.responsef(header, "Failed to create the subscription. Error: %v.", err)
+	}
+
+	err = p.createPost(&model.Post{
+		UserId:    p.getConfig().botUserID,
+		ChannelId: subscription.ChannelID,
+		Message:   fmt.Sprintf("Jira subscription, \"%v\", was added to this channel by %v", subscription.Name, connection.DisplayName),
+	})
+	if err != nil {
+		p.client.Log.Warn("Failed to post about the new subscription", "error", err.Error())
+	}
+
+	msg := fmt.Sprintf("This channel is now subscribed to the created and updated issues matching the Jira filter **%s**:\n```\n%s\n```", filter.Name, subscription.Filters.JQL)
+	if pin {
+		msg += fmt.Sprintf("\nUse `/jira subscribe resync %s` to pick up the changes made to the filter in Jira.", subscription.ID)
+	}
+	return p.responsef(header, "%s", msg)
+}
+
+func executeSubscribeResync(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to identify the Jira instance. Error: %v.", err)
+	}
+	if len(args) == 0 {
+		return p.responsef(header, "Please specify the ID or the name of a subscription of this channel, e.g. `/jira subscribe resync [subscription]`.")
+	}
+
+	if err = p.hasPermissionToManageSubscription(instance.GetID(), header.UserId, header.ChannelId); err != nil {
+		return p.responsef(header, "You do not have permission to manage the Jira subscriptions of this channel: %v.", err)
+	}
+
+	subs, err := p.getSubscriptionsForChannel(instance.GetID(), header.ChannelId)
+	if err != nil {
+		return p.responsef(header, "Failed to load the subscriptions of this channel. Error: %v.", err)
+	}
+	search := strings.Join(args, " ")
+	var subscription *ChannelSubscription
+	for i := range subs {
+		if subs[i].ID == search || subs[i].Name == search {
+			subscription = &subs[i]
+			break
+		}
+	}
+	if subscription == nil {
+		return p.responsef(header, "This channel has no subscription `%s`.", search)
+	}
+	if subscription.JiraFilterID == "" {
+		return p.responsef(header, "The subscription **%s** is not pinned to a Jira filter. Import the filter with `/jira subscribe import-from-filter [filter-id] --pin` to be able to resync it.", subscription.Name)
+	}
+
+	client, _, _, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	filter, err := getJiraFilter(client, subscription.JiraFilterID)
+	if err != nil {
+		return p.responsef(header, "Failed to resync the subscription: %v.", err)
+	}
+
+	jql := stripJQLOrderBy(filter.Jql)
+	if jql == subscription.Filters.JQL {
+		return p.responsef(header, "The subscription **%s** is up to date with the Jira filter **%s**.", subscription.Name, filter.Name)
+	}
+	subscription.Filters.JQL = jql
+	subscription.CreatedBy = user.MattermostUserID
+	if err = p.editChannelSubscription(instance.GetID(), subscription, client); err != nil {
+		return p.responsef(header, "Failed to update the subscription. Error: %v.", err)
+	}
+	return p.responsef(header, "The subscription **%s** now follows the JQL of the Jira filter **%s**:\n```\n%s\n```", subscription.Name, filter.Name, jql)
+}
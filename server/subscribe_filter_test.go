@@ -0,0 +1,105 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type filterClient struct {
+	testClient
+	filters map[int]*jira.Filter
+}
+
+func (client filterClient) GetFilter(filterID int) (*jira.Filter, error) {
+	filter, ok := client.filters[filterID]
+	if !ok {
+		return nil, RESTError{errors.New("not found"), http.StatusNotFound}
+	}
+	return filter, nil
+}
+
+func TestStripJQLOrderBy(t *testing.T) {
+	for jql, expected := range map[string]string{
+		"project = KT":                               "project = KT",
+		"project = KT ORDER BY created DESC":         "project = KT",
+		"project = KT order by priority, updated":    "project = KT",
+		"project = KT\nORDER BY Rank ASC":            "project = KT",
+		"summary ~ \"order\" AND project = KT":       "summary ~ \"order\" AND project = KT",
+		"reporter = bordery ORDER BY created":        "reporter = bordery",
+		"  assignee = currentUser()  ORDER  BY key ": "assignee = currentUser()",
+	} {
+		assert.Equal(t, expected, stripJQLOrderBy(jql), jql)
+	}
+}
+
+func TestNewFilterSubscription(t *testing.T) {
+	filter := &jira.Filter{ID: "10042", Name: "Release blockers", Jql: "priority = Blocker ORDER BY created DESC"}
+
+	sub := newFilterSubscription(testInstance1.InstanceID, "channelID", "userID", filter, false)
+	assert.Equal(t, "Filter: Release blockers", sub.Name)
+	assert.Equal(t, "priority = Blocker", sub.Filters.JQL)
+	assert.Equal(t, NewStringSet(eventCreated, eventUpdatedAny), sub.Filters.Events)
+	assert.Empty(t, sub.Filters.Projects)
+	assert.Equal(t, "userID", sub.CreatedBy.String())
+	assert.Empty(t, sub.JiraFilterID)
+
+	sub = newFilterSubscription(testInstance1.InstanceID, "channelID", "userID", filter, true)
+	assert.Equal(t, "10042", sub.JiraFilterID)
+}
+
+func TestGetJiraFilter(t *testing.T) {
+	client := filterClient{filters: map[int]*jira.Filter{
+		10042: {ID: "10042", Name: "Release blockers", Jql: "priority = Blocker"},
+		10043: {ID: "10043", Name: "Empty"},
+	}}
+
+	filter, err := getJiraFilter(client, "10042")
+	require.NoError(t, err)
+	assert.Equal(t, "Release blockers", filter.Name)
+
+	_, err = getJiraFilter(client, "10044")
+	assert.EqualError(t, err, "the Jira filter 10044 does not exist, or you do not have access to it")
+
+	_, err = getJiraFilter(client, "10043")
+	assert.EqualError(t, err, "the Jira filter 10043 has no JQL query")
+
+	for _, invalid := range []string{"blockers", "-1", "0"} {
+		_, err = getJiraFilter(client, invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSubscriptionJQLMatchesWithoutJQL(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("LogWarn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	matched := map[string]bool{}
+
+	assert.True(t, p.subscriptionJQLMatches(testInstance1.InstanceID, ChannelSubscription{}, "KT-1", matched))
+	assert.False(t, p.subscriptionJQLMatches(testInstance1.InstanceID, ChannelSubscription{Filters: SubscriptionFilters{JQL: "project = KT"}}, "KT-1", matched),
+		"a subscription with JQL and no one to evaluate it as should not match")
+	api.AssertCalled(t, "LogWarn", mock.Anything, "subscription", "", "channel", "")
+}
+
+func TestSubscriptionJQLMatchesOncePerUserAndQuery(t *testing.T) {
+	p := &Plugin{}
+	matched := map[string]bool{"user1/project = KT": true}
+
+	// The result of another subscription of the event is reused, without a
+	// search.
+	sub := ChannelSubscription{ID: "sub2", CreatedBy: "user1", Filters: SubscriptionFilters{JQL: "project = KT"}}
+	assert.True(t, p.subscriptionJQLMatches(testInstance1.InstanceID, sub, "KT-1", matched))
+}
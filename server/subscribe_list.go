@@ -0,0 +1,170 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// filterInclusionText phrases how a field filter matches its values.
+var filterInclusionText = map[string]string{
+	FilterIncludeAny:     "any of",
+	FilterIncludeAll:     "all of",
+	FilterExcludeAny:     "none of",
+	FilterIncludeOrEmpty: "empty or any of",
+}
+
+// subscriptionFilterNames resolves the IDs stored in the filters of the
+// subscriptions of an instance to names, fetching each project and the
+// fields at most once. Without a client, or when Jira cannot be reached, the
+// IDs are shown as they are.
+type subscriptionFilterNames struct {
+	client   Client
+	projects map[string]*jira.Project
+	fields   map[string]string
+}
+
+func (p *Plugin) newSubscriptionFilterNames(instanceID, mattermostUserID types.ID) *subscriptionFilterNames {
+	names := &subscriptionFilterNames{projects: map[string]*jira.Project{}}
+	if client, _, _, err := p.getClient(instanceID, mattermostUserID); err == nil {
+		names.client = client
+	}
+	return names
+}
+
+func (names *subscriptionFilterNames) project(key string) *jira.Project {
+	if names.client == nil {
+		return nil
+	}
+	project, ok := names.projects[key]
+	if !ok {
+		project, _ = names.client.GetProject(key)
+		names.projects[key] = project
+	}
+	return project
+}
+
+func (names *subscriptionFilterNames) fieldName(key string) string {
+	if names.fields == nil {
+		names.fields = map[string]string{}
+		if names.client != nil {
+			if fields, err := names.client.GetFields(); err == nil {
+				for _, field := range fields {
+					names.fields[field.ID] = field.Name
+				}
+			}
+		}
+	}
+	if name := names.fields[key]; name != "" {
+		return name
+	}
+	return key
+}
+
+// valueName returns the name of a value of a field filter, e.g. of a
+// component, found in the projects of the subscription.
+func (names *subscriptionFilterNames) valueName(projectKeys []string, fieldKey, id string) string {
+	for _, key := range projectKeys {
+		project := names.project(key)
+		if project == nil {
+			continue
+		}
+		switch strings.ToLower(fieldKey) {
+		case "components":
+			for _, component := range project.Components {
+				if component.ID == id {
+					return component.Name
+				}
+			}
+		case "fixversions", "versions":
+			for _, version := range project.Versions {
+				if version.ID == id {
+					return version.Name
+				}
+			}
+		}
+	}
+	return id
+}
+
+func (names *subscriptionFilterNames) issueTypeName(projectKeys []string, id string) string {
+	for _, key := range projectKeys {
+		if project := names.project(key); project != nil {
+			for _, issueType := range project.IssueTypes {
+				if issueType.ID == id {
+					return issueType.Name
+				}
+			}
+		}
+	}
+	return id
+}
+
+func mdEventTypes(events StringSet) string {
+	labels := []string{}
+	for _, event := range events.Elems() {
+		labels = append(labels, strings.ReplaceAll(strings.TrimPrefix(event, "event_"), "_", " "))
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, ", ")
+}
+
+// mdSubscriptionFilters renders the filters of a subscription in plain
+// language, e.g. `Project: Platform (PLAT); Types: Bug, Task; Labels: any of
+// [urgent]`.
+func mdSubscriptionFilters(filters SubscriptionFilters, names *subscriptionFilterNames) string {
+	projectKeys := filters.Projects.Elems()
+	sort.Strings(projectKeys)
+	parts := []string{}
+
+	if len(projectKeys) > 0 {
+		projects := []string{}
+		for _, key := range projectKeys {
+			if project := names.project(key); project != nil && project.Name != "" {
+				projects = append(projects, fmt.Sprintf("%s (%s)", project.Name, key))
+			} else {
+				projects = append(projects, key)
+			}
+		}
+		parts = append(parts, "Project: "+strings.Join(projects, ", "))
+	}
+	if filters.Events.Len() > 0 {
+		parts = append(parts, "Events: "+mdEventTypes(filters.Events))
+	}
+	if filters.IssueTypes.Len() > 0 {
+		issueTypes := []string{}
+		for _, id := range filters.IssueTypes.Elems() {
+			issueTypes = append(issueTypes, names.issueTypeName(projectKeys, id))
+		}
+		sort.Strings(issueTypes)
+		parts = append(parts, "Types: "+strings.Join(issueTypes, ", "))
+	}
+	for _, field := range filters.Fields {
+		name := names.fieldName(field.Key)
+		if field.Inclusion == FilterEmpty {
+			parts = append(parts, name+": empty")
+			continue
+		}
+		values := []string{}
+		for _, id := range field.Values.Elems() {
+			values = append(values, names.valueName(projectKeys, field.Key, id))
+		}
+		sort.Strings(values)
+		inclusion := filterInclusionText[field.Inclusion]
+		if inclusion == "" {
+			inclusion = field.Inclusion
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s [%s]", name, inclusion, strings.Join(values, ", ")))
+	}
+	if filters.JQL != "" {
+		parts = append(parts, fmt.Sprintf("JQL: `%s`", filters.JQL))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/stretchr/testify/assert"
+)
+
+type filterNamesTestClient struct {
+	testClient
+	projectCalls map[string]int
+}
+
+func (client filterNamesTestClient) GetProject(key string) (*jira.Project, error) {
+	client.projectCalls[key]++
+	if key != "PLAT" {
+		return nil, nil
+	}
+	return &jira.Project{
+		Key:        "PLAT",
+		Name:       "Platform",
+		IssueTypes: []jira.IssueType{{ID: "10001", Name: "Task"}, {ID: "10004", Name: "Bug"}},
+		Components: []jira.ProjectComponent{{ID: "200", Name: "Backend"}},
+		Versions:   []jira.Version{{ID: "300", Name: "2.0"}},
+	}, nil
+}
+
+func (client filterNamesTestClient) GetFields() ([]jira.Field, error) {
+	return []jira.Field{
+		{ID: "labels", Name: "Labels"},
+		{ID: "components", Name: "Components"},
+		{ID: "customfield_10100", Name: "Team"},
+	}, nil
+}
+
+func TestMdSubscriptionFilters(t *testing.T) {
+	filters := SubscriptionFilters{
+		Events:     NewStringSet(eventCreated, eventUpdatedStatus),
+		Projects:   NewStringSet("PLAT"),
+		IssueTypes: NewStringSet("10004", "10001", "10009"),
+		Fields: []FieldFilter{
+			{Key: "labels", Inclusion: FilterIncludeAny, Values: NewStringSet("urgent")},
+			{Key: "components", Inclusion: FilterExcludeAny, Values: NewStringSet("200", "201")},
+			{Key: "customfield_10100", Inclusion: FilterEmpty},
+		},
+		JQL: "priority = Highest",
+	}
+
+	t.Run("resolved", func(t *testing.T) {
+		client := filterNamesTestClient{projectCalls: map[string]int{}}
+		names := &subscriptionFilterNames{client: client, projects: map[string]*jira.Project{}}
+		assert.Equal(t, "Project: Platform (PLAT); Events: created, updated status; Types: 10009, Bug, Task; "+
+			"Labels: any of [urgent]; Components: none of [201, Backend]; Team: empty; JQL: `priority = Highest`",
+			mdSubscriptionFilters(filters, names))
+		assert.Equal(t, 1, client.projectCalls["PLAT"], "the project is fetched once")
+	})
+
+	t.Run("not connected", func(t *testing.T) {
+		names := &subscriptionFilterNames{projects: map[string]*jira.Project{}}
+		assert.Equal(t, "Project: PLAT; Events: created, updated status; Types: 10001, 10004, 10009; "+
+			"labels: any of [urgent]; components: none of [200, 201]; customfield_10100: empty; JQL: `priority = Highest`",
+			mdSubscriptionFilters(filters, names))
+	})
+}
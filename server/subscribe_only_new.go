@@ -0,0 +1,179 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	prefixAnnouncedIssues = "announced_issues_"
+
+	// The oldest issues are forgotten once a subscription remembers this
+	// many.
+	announcedIssuesMax = 1000
+)
+
+// announcedIssues are the keys of the issues whose creation a subscription
+// posted, oldest first.
+type announcedIssues struct {
+	Keys []string `json:"keys"`
+}
+
+func announcedIssuesKey(instanceID types.ID, subscriptionID string) string {
+	return hashkey(prefixAnnouncedIssues, instanceID.String()+"/"+subscriptionID)
+}
+
+func (a *announcedIssues) contains(issueKey string) bool {
+	for _, key := range a.Keys {
+		if key == issueKey {
+			return true
+		}
+	}
+	return false
+}
+
+// add remembers the issue, forgetting the oldest ones beyond
+// announcedIssuesMax.
+func (a *announcedIssues) add(issueKey string) {
+	if a.contains(issueKey) {
+		return
+	}
+	a.Keys = append(a.Keys, issueKey)
+	if len(a.Keys) > announcedIssuesMax {
+		a.Keys = a.Keys[len(a.Keys)-announcedIssuesMax:]
+	}
+}
+
+func (p *Plugin) loadAnnouncedIssues(instanceID types.ID, subscriptionID string) (*announcedIssues, error) {
+	announced := &announcedIssues{}
+	var data []byte
+	if err := p.client.KV.Get(announcedIssuesKey(instanceID, subscriptionID), &data); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return announced, nil
+	}
+	if err := json.Unmarshal(data, announced); err != nil {
+		return nil, err
+	}
+	return announced, nil
+}
+
+// recordAnnouncedIssue remembers that the subscription posted the creation of
+// the issue.
+func (p *Plugin) recordAnnouncedIssue(instanceID types.ID, subscriptionID, issueKey string) error {
+	return p.client.KV.SetAtomicWithRetries(announcedIssuesKey(instanceID, subscriptionID), func(initialBytes []byte) (interface{}, error) {
+		announced := announcedIssues{}
+		if len(initialBytes) != 0 {
+			if err := json.Unmarshal(initialBytes, &announced); err != nil {
+				return nil, err
+			}
+		}
+		announced.add(issueKey)
+		return json.Marshal(&announced)
+	})
+}
+
+// deleteAnnouncedIssues forgets the issues that the subscription posted the
+// creation of, once it no longer posts only the new ones or is removed.
+func (p *Plugin) deleteAnnouncedIssues(instanceID types.ID, subscriptionID string) error {
+	return p.client.KV.Delete(announcedIssuesKey(instanceID, subscriptionID))
+}
+
+func isOnlyNewCreation(sub ChannelSubscription, wh *webhook) bool {
+	return sub.OnCreateOnlyNew && wh.eventTypes.ContainsAny(eventCreated) && wh.Issue.Key != ""
+}
+
+// skipAnnouncedIssues drops the subscriptions posting only the creation of
+// new issues from those matching a creation event of an issue that they
+// already posted, e.g. when the automation of a reopened issue creates it
+// once more. An issue that cannot be checked is posted.
+func (p *Plugin) skipAnnouncedIssues(instanceID types.ID, wh *webhook, subs []ChannelSubscription) []ChannelSubscription {
+	kept := []ChannelSubscription{}
+	for _, sub := range subs {
+		if isOnlyNewCreation(sub, wh) {
+			announced, err := p.loadAnnouncedIssues(instanceID, sub.ID)
+			if err != nil {
+				p.client.Log.Warn("Failed to load the issues announced by the subscription", "SubscriptionID", sub.ID, "Error", err.Error())
+			} else if announced.contains(strings.ToUpper(wh.Issue.Key)) {
+				continue
+			}
+		}
+		kept = append(kept, sub)
+	}
+	return kept
+}
+
+// recordAnnouncedIssues remembers the issue of a creation event for the
+// subscriptions posting only the creation of new issues that posted it.
+func (p *Plugin) recordAnnouncedIssues(instanceID types.ID, wh *webhook, deliveries []*channelDelivery) {
+	for _, delivery := range deliveries {
+		for _, sub := range delivery.Subscriptions {
+			if !isOnlyNewCreation(sub, wh) {
+				continue
+			}
+			if err := p.recordAnnouncedIssue(instanceID, sub.ID, strings.ToUpper(wh.Issue.Key)); err != nil {
+				p.client.Log.Warn("Failed to remember the issue announced by the subscription", "SubscriptionID", sub.ID, "IssueKey", wh.Issue.Key, "Error", err.Error())
+			}
+		}
+	}
+}
+
+// setSubscriptionOnCreateOnlyNew turns on or off posting only the creation of
+// new issues for a subscription. Turning it off forgets the issues it posted.
+func (p *Plugin) setSubscriptionOnCreateOnlyNew(instanceID types.ID, subscriptionID string, onlyNew bool) error {
+	err := p.updateSubscription(instanceID, subscriptionID, func(sub *ChannelSubscription) {
+		sub.OnCreateOnlyNew = onlyNew
+	})
+	if err != nil || onlyNew {
+		return err
+	}
+	return p.deleteAnnouncedIssues(instanceID, subscriptionID)
+}
+
+func executeSubscribeOnCreateOnlyNew(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	_, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to identify the Jira instance. Error: %v.", err)
+	}
+	if len(args) < 2 || (args[0] != settingOn && args[0] != settingOff) {
+		return p.responsef(header, "Please use `/jira subscribe on-create-only-new [on|off] [subscription]`.")
+	}
+
+	if err = p.hasPermissionToManageSubscription(instance.GetID(), header.UserId, header.ChannelId); err != nil {
+		return p.responsef(header, "You do not have permission to manage the Jira subscriptions of this channel: %v.", err)
+	}
+
+	subs, err := p.getSubscriptionsForChannel(instance.GetID(), header.ChannelId)
+	if err != nil {
+		return p.responsef(header, "Failed to load the subscriptions of this channel. Error: %v.", err)
+	}
+	search := strings.Join(args[1:], " ")
+	var subscription *ChannelSubscription
+	for i := range subs {
+		if subs[i].ID == search || subs[i].Name == search {
+			subscription = &subs[i]
+			break
+		}
+	}
+	if subscription == nil {
+		return p.responsef(header, "This channel has no subscription `%s`.", search)
+	}
+
+	onlyNew := args[0] == settingOn
+	if err = p.setSubscriptionOnCreateOnlyNew(instance.GetID(), subscription.ID, onlyNew); err != nil {
+		return p.responsef(header, "Failed to update the subscription. Error: %v.", err)
+	}
+	if onlyNew {
+		return p.responsef(header, "The subscription **%s** now posts the creation of an issue once: the issues it already posted are not posted again when they are created anew, e.g. by the automation of a reopened issue.", subscription.Name)
+	}
+	return p.responsef(header, "The subscription **%s** now posts every creation event it matches.", subscription.Name)
+}
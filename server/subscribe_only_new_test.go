@@ -0,0 +1,129 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnouncedIssuesAdd(t *testing.T) {
+	announced := &announcedIssues{}
+	for i := 0; i < announcedIssuesMax+2; i++ {
+		announced.add(fmt.Sprintf("TEST-%d", i))
+	}
+	announced.add("TEST-5")
+
+	assert.Len(t, announced.Keys, announcedIssuesMax)
+	assert.False(t, announced.contains("TEST-0"), "the oldest issues are forgotten")
+	assert.False(t, announced.contains("TEST-1"))
+	assert.True(t, announced.contains("TEST-2"))
+	assert.True(t, announced.contains(fmt.Sprintf("TEST-%d", announcedIssuesMax+1)))
+	assert.Equal(t, "TEST-2", announced.Keys[0], "a known issue is not added again")
+}
+
+func TestSkipAnnouncedIssues(t *testing.T) {
+	onlyNew := ChannelSubscription{ID: "only-new", ChannelID: "channel1", OnCreateOnlyNew: true}
+	all := ChannelSubscription{ID: "all", ChannelID: "channel2"}
+
+	for name, tc := range map[string]struct {
+		eventType string
+		announced []string
+		expected  []string
+	}{
+		"first creation": {
+			eventType: eventCreated,
+			announced: []string{"TEST-1"},
+			expected:  []string{"only-new", "all"},
+		},
+		"created again": {
+			eventType: eventCreated,
+			announced: []string{"TEST-1", "TEST-2"},
+			expected:  []string{"all"},
+		},
+		"not a creation": {
+			eventType: eventUpdatedStatus,
+			announced: []string{"TEST-2"},
+			expected:  []string{"only-new", "all"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(announcedIssues{Keys: tc.announced})
+			require.NoError(t, err)
+			api := &plugintest.API{}
+			api.On("KVGet", announcedIssuesKey(testInstance1.InstanceID, "only-new")).Return(data, nil)
+
+			p := &Plugin{}
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+
+			wh := &webhook{
+				JiraWebhook: &JiraWebhook{Issue: jira.Issue{Key: "test-2"}},
+				eventTypes:  NewStringSet(tc.eventType),
+			}
+			ids := []string{}
+			for _, sub := range p.skipAnnouncedIssues(testInstance1.InstanceID, wh, []ChannelSubscription{onlyNew, all}) {
+				ids = append(ids, sub.ID)
+			}
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
+func TestRecordAnnouncedIssues(t *testing.T) {
+	key := announcedIssuesKey(testInstance1.InstanceID, "only-new")
+	api := &plugintest.API{}
+	api.On("KVGet", key).Return([]byte(`{"keys":["TEST-1"]}`), nil)
+	api.On("KVSetWithOptions", key, mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+
+	wh := &webhook{
+		JiraWebhook: &JiraWebhook{Issue: jira.Issue{Key: "TEST-2"}},
+		eventTypes:  NewStringSet(eventCreated),
+	}
+	p.recordAnnouncedIssues(testInstance1.InstanceID, wh, []*channelDelivery{{
+		ChannelID: "channel1",
+		Subscriptions: []ChannelSubscription{
+			{ID: "only-new", OnCreateOnlyNew: true},
+			{ID: "all"},
+		},
+	}})
+
+	api.AssertNumberOfCalls(t, "KVSetWithOptions", 1)
+	api.AssertCalled(t, "KVSetWithOptions", key, []byte(`{"keys":["TEST-1","TEST-2"]}`), mock.MatchedBy(func(options model.PluginKVSetOptions) bool {
+		return options.Atomic
+	}))
+}
+
+func TestRemoveChannelSubscriptionForgetsAnnouncedIssues(t *testing.T) {
+	subs := NewSubscriptions()
+	subs.Channel.add(&ChannelSubscription{ID: "only-new", ChannelID: "channel1", OnCreateOnlyNew: true})
+	data, err := json.Marshal(subs)
+	require.NoError(t, err)
+
+	subKey := keyWithInstanceID(testInstance1.InstanceID, JiraSubscriptionsKey)
+	api := &plugintest.API{}
+	api.On("KVGet", subKey).Return(data, nil)
+	api.On("KVSetWithOptions", subKey, mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+	api.On("KVSetWithOptions", announcedIssuesKey(testInstance1.InstanceID, "only-new"), []byte(nil), model.PluginKVSetOptions{}).Return(true, nil)
+
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+
+	require.NoError(t, p.removeChannelSubscription(testInstance1.InstanceID, "only-new"))
+	api.AssertExpectations(t)
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	subscriptionPreviewSampleSize = 5
+
+	// A query matching more issues than this is likely too broad to be
+	// subscribed to.
+	subscriptionPreviewBroadThreshold = 1000
+)
+
+// previewJQL reports how many issues currently match a JQL query, along with
+// a few of them. It does not change anything.
+func (p *Plugin) previewJQL(instanceID types.ID, client Client, jql string) (string, error) {
+	if err := p.validateJQL(instanceID, client, jql); err != nil {
+		return "", err
+	}
+
+	issues, total, err := client.SearchIssuesWithTotal(normalizeJQL(jql), &jira.SearchOptions{
+		MaxResults: subscriptionPreviewSampleSize,
+		Fields:     []string{"key"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	keys := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		keys = append(keys, issue.Key)
+	}
+	return formatSubscriptionPreview(jql, total, keys), nil
+}
+
+func formatSubscriptionPreview(jql string, total int, sampleKeys []string) string {
+	msg := fmt.Sprintf("`%s` currently matches **%d** issue(s).", normalizeJQL(jql), total)
+	if len(sampleKeys) > 0 {
+		msg += " For example: " + strings.Join(sampleKeys, ", ") + "."
+	}
+	if total > subscriptionPreviewBroadThreshold {
+		msg += fmt.Sprintf("\n\nThis query is probably too broad for a subscription: it matches more than %d issues, "+
+			"so it may flood the channel with notifications. Consider narrowing it down, "+
+			"e.g. to fewer projects, issue types or statuses.", subscriptionPreviewBroadThreshold)
+	}
+	return msg
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type previewSearchClient struct {
+	jqlSearchClient
+	total int
+	keys  []string
+}
+
+func (client previewSearchClient) SearchIssuesWithTotal(jql string, options *jira.SearchOptions) ([]jira.Issue, int, error) {
+	issues := []jira.Issue{}
+	for _, key := range client.keys {
+		issues = append(issues, jira.Issue{Key: key})
+	}
+	return issues, client.total, nil
+}
+
+func TestPreviewJQL(t *testing.T) {
+	t.Run("reports the count and samples", func(t *testing.T) {
+		p := &Plugin{}
+		calls := 0
+		client := previewSearchClient{jqlSearchClient: jqlSearchClient{calls: &calls}, total: 42, keys: []string{"KT-1", "KT-2"}}
+
+		msg, err := p.previewJQL("jiraurl1", client, " project  = KT ")
+		require.NoError(t, err)
+		assert.Equal(t, "`project = KT` currently matches **42** issue(s). For example: KT-1, KT-2.", msg)
+	})
+
+	t.Run("warns about broad queries", func(t *testing.T) {
+		p := &Plugin{}
+		calls := 0
+		client := previewSearchClient{jqlSearchClient: jqlSearchClient{calls: &calls}, total: subscriptionPreviewBroadThreshold + 1}
+
+		msg, err := p.previewJQL("jiraurl1", client, "project = KT")
+		require.NoError(t, err)
+		assert.Contains(t, msg, "too broad")
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		p := &Plugin{}
+		calls := 0
+		client := previewSearchClient{jqlSearchClient: jqlSearchClient{calls: &calls, err: RESTError{errors.New("bad field"), http.StatusBadRequest}}}
+
+		_, err := p.previewJQL("jiraurl1", client, "bogus = 1")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,440 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	prefixChannelQuiet           = "channel_quiet_windows_"
+	prefixChannelQuietSuppressed = "channel_quiet_suppressed_"
+
+	// keyChannelQuietPending lists the channels with events suppressed
+	// during a quiet window, whose summary is not posted yet.
+	keyChannelQuietPending = "channel_quiet_pending"
+
+	channelQuietSummaryInterval = 5 * time.Minute
+
+	// A channel has at most this many quiet windows.
+	channelQuietMaxWindows = 10
+)
+
+var quietWeekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// quietWindow is a recurring window, on some days of the week in a timezone,
+// during which the subscriptions of a channel do not post. A window spanning
+// midnight belongs to the day it starts. The windows are those of the
+// channel, for all its subscriptions; a subscription that must keep posting
+// belongs in another channel.
+type quietWindow struct {
+	Days     []time.Weekday `json:"days"`
+	Start    string         `json:"start"`
+	End      string         `json:"end"`
+	Timezone string         `json:"timezone"`
+}
+
+// channelQuietSchedule are the quiet windows of a channel. With Summary, the
+// number of events suppressed is posted once the window ends.
+type channelQuietSchedule struct {
+	Windows []quietWindow `json:"windows"`
+	Summary bool          `json:"summary,omitempty"`
+}
+
+type channelQuietSuppressed struct {
+	ChannelID string `json:"channel_id"`
+	Count     int    `json:"count"`
+}
+
+func mdQuietDays(days []time.Weekday) string {
+	switch len(days) {
+	case 7:
+		return "every day"
+	case 5:
+		if !containsWeekday(days, time.Saturday) && !containsWeekday(days, time.Sunday) {
+			return "weekdays"
+		}
+	case 2:
+		if containsWeekday(days, time.Saturday) && containsWeekday(days, time.Sunday) {
+			return "weekends"
+		}
+	}
+	names := []string{}
+	for _, day := range days {
+		names = append(names, quietWeekdays[day])
+	}
+	return strings.Join(names, ",")
+}
+
+func (w quietWindow) String() string {
+	return fmt.Sprintf("%s %s-%s %s", mdQuietDays(w.Days), w.Start, w.End, w.Timezone)
+}
+
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func parseQuietWeekday(s string) (time.Weekday, error) {
+	for i, name := range quietWeekdays {
+		if strings.HasPrefix(strings.ToLower(s), name) {
+			return time.Weekday(i), nil
+		}
+	}
+	return 0, errors.Errorf("%q is not a day of the week", s)
+}
+
+// parseQuietDays parses the days of a window: `daily`, `weekdays`,
+// `weekends`, or days and ranges of days such as `mon-fri` or `mon,wed`.
+func parseQuietDays(s string) ([]time.Weekday, error) {
+	switch strings.ToLower(s) {
+	case "daily":
+		s = "sun-sat"
+	case "weekdays":
+		s = "mon-fri"
+	case "weekends":
+		s = "sat,sun"
+	}
+
+	set := map[time.Weekday]bool{}
+	for _, part := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := parseQuietWeekday(from)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if isRange {
+			if last, err = parseQuietWeekday(to); err != nil {
+				return nil, err
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			set[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+
+	days := []time.Weekday{}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if set[day] {
+			days = append(days, day)
+		}
+	}
+	return days, nil
+}
+
+// parseQuietWindow parses `[days] [HH:MM-HH:MM] [timezone]`, e.g. `mon-fri
+// 14:00-15:00 Europe/Paris`, the timezone being by default defaultTimezone.
+func parseQuietWindow(args []string, defaultTimezone string) (*quietWindow, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, errors.New("please specify the days, the times and optionally the timezone of the window")
+	}
+	days, err := parseQuietDays(args[0])
+	if err != nil {
+		return nil, err
+	}
+	start, end, ok := strings.Cut(args[1], "-")
+	if !ok {
+		return nil, errors.Errorf("%q is not a window of time, please use HH:MM-HH:MM", args[1])
+	}
+	for _, clock := range []string{start, end} {
+		if _, err = parseQuietHoursClock(clock); err != nil {
+			return nil, err
+		}
+	}
+	if start == end {
+		return nil, errors.New("the window must start and end at different times")
+	}
+
+	timezone := defaultTimezone
+	if len(args) == 3 {
+		timezone = args[2]
+	}
+	if _, err = time.LoadLocation(timezone); err != nil {
+		return nil, errors.Errorf("%q is not a timezone, please use a name such as Europe/Paris", timezone)
+	}
+	return &quietWindow{Days: days, Start: start, End: end, Timezone: timezone}, nil
+}
+
+// IsActive reports whether t falls within the window, from its start
+// included to its end excluded.
+func (w quietWindow) IsActive(t time.Time) bool {
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return false
+	}
+	start, err := parseQuietHoursClock(w.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseQuietHoursClock(w.End)
+	if err != nil {
+		return false
+	}
+
+	t = t.In(loc)
+	now := t.Hour()*60 + t.Minute()
+	today := containsWeekday(w.Days, t.Weekday())
+	if start <= end {
+		return today && start <= now && now < end
+	}
+	yesterday := containsWeekday(w.Days, (t.Weekday()+6)%7)
+	return (today && now >= start) || (yesterday && now < end)
+}
+
+func (s *channelQuietSchedule) IsActive(t time.Time) bool {
+	if s == nil {
+		return false
+	}
+	for _, w := range s.Windows {
+		if w.IsActive(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Plugin) loadChannelQuietSchedule(channelID string) (*channelQuietSchedule, error) {
+	schedule := &channelQuietSchedule{}
+	if err := p.client.KV.Get(prefixChannelQuiet+channelID, schedule); err != nil {
+		return nil, errors.WithMessage(err, "failed to load the quiet windows of the channel")
+	}
+	return schedule, nil
+}
+
+func (p *Plugin) storeChannelQuietSchedule(channelID string, schedule *channelQuietSchedule) error {
+	if len(schedule.Windows) == 0 && !schedule.Summary {
+		return p.client.KV.Delete(prefixChannelQuiet + channelID)
+	}
+	_, err := p.client.KV.Set(prefixChannelQuiet+channelID, schedule)
+	return errors.WithMessage(err, "failed to store the quiet windows of the channel")
+}
+
+// suppressChannelQuiet reports whether the subscriptions of the channel are
+// quiet at the time, and counts the event for the summary.
+func (p *Plugin) suppressChannelQuiet(channelID string, now time.Time) bool {
+	schedule, err := p.loadChannelQuietSchedule(channelID)
+	if err != nil {
+		p.client.Log.Warn("Failed to load the quiet windows of the channel, the event is posted", "ChannelID", channelID, "Error", err.Error())
+		return false
+	}
+	if !schedule.IsActive(now) {
+		return false
+	}
+	if schedule.Summary {
+		first := false
+		err = p.client.KV.SetAtomicWithRetries(prefixChannelQuietSuppressed+channelID, func(initialBytes []byte) (interface{}, error) {
+			suppressed := channelQuietSuppressed{}
+			if len(initialBytes) > 0 {
+				if err := json.Unmarshal(initialBytes, &suppressed); err != nil {
+					return nil, err
+				}
+			}
+			suppressed.ChannelID = channelID
+			suppressed.Count++
+			first = suppressed.Count == 1
+			return json.Marshal(&suppressed)
+		})
+		if err == nil && first {
+			err = p.updateChannelQuietPending(channelID, true)
+		}
+		if err != nil {
+			p.client.Log.Warn("Failed to count an event suppressed during a quiet window", "ChannelID", channelID, "Error", err.Error())
+		}
+	}
+	return true
+}
+
+// updateChannelQuietPending adds the channel to, or removes it from, the
+// channels whose summary is not posted yet.
+func (p *Plugin) updateChannelQuietPending(channelID string, pending bool) error {
+	return p.client.KV.SetAtomicWithRetries(keyChannelQuietPending, func(initialBytes []byte) (interface{}, error) {
+		channelIDs := []string{}
+		if len(initialBytes) > 0 {
+			if err := json.Unmarshal(initialBytes, &channelIDs); err != nil {
+				return nil, err
+			}
+		}
+		updated := []string{}
+		for _, id := range channelIDs {
+			if id != channelID {
+				updated = append(updated, id)
+			}
+		}
+		if pending {
+			updated = append(updated, channelID)
+		}
+		if len(updated) == 0 {
+			return nil, nil
+		}
+		sort.Strings(updated)
+		return json.Marshal(updated)
+	})
+}
+
+func (p *Plugin) loadChannelQuietPending() ([]string, error) {
+	channelIDs := []string{}
+	if err := p.client.KV.Get(keyChannelQuietPending, &channelIDs); err != nil {
+		return nil, err
+	}
+	return channelIDs, nil
+}
+
+// takeChannelQuietSuppressed removes the count from the store, and returns it.
+func (p *Plugin) takeChannelQuietSuppressed(key string) (*channelQuietSuppressed, error) {
+	var suppressed *channelQuietSuppressed
+	err := p.client.KV.SetAtomicWithRetries(key, func(initialBytes []byte) (interface{}, error) {
+		suppressed = nil
+		if len(initialBytes) == 0 {
+			return nil, nil
+		}
+		suppressed = &channelQuietSuppressed{}
+		if err := json.Unmarshal(initialBytes, suppressed); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	})
+	return suppressed, err
+}
+
+// postChannelQuietSummaries posts the number of events suppressed in the
+// channels whose quiet window is over.
+func (p *Plugin) postChannelQuietSummaries(now time.Time) {
+	channelIDs, err := p.loadChannelQuietPending()
+	if err != nil {
+		p.client.Log.Warn("Failed to load the channels with events suppressed during quiet windows", "error", err.Error())
+		return
+	}
+
+	for _, channelID := range channelIDs {
+		schedule, err := p.loadChannelQuietSchedule(channelID)
+		if err != nil || schedule.IsActive(now) {
+			continue
+		}
+		// Leave the list first, an event suppressed after the count is
+		// taken adds the channel again.
+		if err = p.updateChannelQuietPending(channelID, false); err != nil {
+			p.client.Log.Warn("Failed to update the channels with events suppressed during quiet windows", "ChannelID", channelID, "error", err.Error())
+			continue
+		}
+		suppressed, err := p.takeChannelQuietSuppressed(prefixChannelQuietSuppressed + channelID)
+		if err != nil {
+			p.client.Log.Warn("Failed to take the events suppressed during a quiet window", "ChannelID", channelID, "error", err.Error())
+			if err = p.updateChannelQuietPending(channelID, true); err != nil {
+				p.client.Log.Warn("Failed to update the channels with events suppressed during quiet windows", "ChannelID", channelID, "error", err.Error())
+			}
+			continue
+		}
+		// Turned off since, the summary is no longer wanted.
+		if suppressed == nil || suppressed.Count == 0 || !schedule.Summary {
+			continue
+		}
+		if err = p.createPost(makePost(p.getUserID(), channelID, mdChannelQuietSummary(suppressed.Count))); err != nil {
+			p.client.Log.Warn("Failed to post the summary of a quiet window", "ChannelID", channelID, "error", err.Error())
+		}
+	}
+}
+
+func mdChannelQuietSummary(count int) string {
+	if count == 1 {
+		return "1 Jira update was suppressed during the quiet window of this channel."
+	}
+	return fmt.Sprintf("%d Jira updates were suppressed during the quiet window of this channel.", count)
+}
+
+func mdChannelQuietSchedule(schedule *channelQuietSchedule) string {
+	if len(schedule.Windows) == 0 {
+		return "The Jira subscriptions of this channel have no quiet windows."
+	}
+	s := "The Jira subscriptions of this channel do not post during:\n"
+	for i, w := range schedule.Windows {
+		s += fmt.Sprintf("%d. %s\n", i+1, w.String())
+	}
+	if schedule.Summary {
+		s += "The number of updates suppressed is posted once a window ends."
+	} else {
+		s += "The updates are dropped."
+	}
+	return s
+}
+
+func executeSubscribeQuiet(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	const helpText = "`/jira subscribe quiet [add|remove|list|clear|summary]`\n" +
+		"* `add [days] [HH:MM-HH:MM] [timezone]` silences all the subscriptions of this channel during a recurring window, e.g. `add mon-fri 14:00-15:00 Europe/Paris`. The days are `daily`, `weekdays`, `weekends`, or days such as `mon,wed` or `tue-thu`; the timezone is by default yours\n" +
+		"* `remove [number]` removes a window of `list`, `clear` removes all of them\n" +
+		"* `summary [on|off]` posts the number of updates suppressed once a window ends, instead of only dropping them"
+
+	_, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to identify the Jira instance. Error: %v.", err)
+	}
+	schedule, err := p.loadChannelQuietSchedule(header.ChannelId)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	if len(args) == 0 || args[0] == "list" {
+		return p.responsef(header, "%s", mdChannelQuietSchedule(schedule))
+	}
+
+	if err = p.hasPermissionToManageSubscription(instance.GetID(), header.UserId, header.ChannelId); err != nil {
+		return p.responsef(header, "You do not have permission to manage the Jira subscriptions of this channel: %v.", err)
+	}
+
+	switch args[0] {
+	case "add":
+		if len(schedule.Windows) >= channelQuietMaxWindows {
+			return p.responsef(header, "This channel already has %d quiet windows, please remove one first.", channelQuietMaxWindows)
+		}
+		window, err := parseQuietWindow(args[1:], p.userLocation(types.ID(header.UserId)).String())
+		if err != nil {
+			return p.responsef(header, "%v.\n%s", err, helpText)
+		}
+		schedule.Windows = append(schedule.Windows, *window)
+
+	case "remove":
+		n := 0
+		if len(args) == 2 {
+			n, _ = strconv.Atoi(args[1])
+		}
+		if n < 1 || n > len(schedule.Windows) {
+			return p.responsef(header, "Please specify the number of a window of `/jira subscribe quiet list`.")
+		}
+		schedule.Windows = append(schedule.Windows[:n-1], schedule.Windows[n:]...)
+
+	case "clear":
+		schedule.Windows = nil
+
+	case "summary":
+		if len(args) != 2 || (args[1] != settingOn && args[1] != settingOff) {
+			return p.responsef(header, "%s", helpText)
+		}
+		schedule.Summary = args[1] == settingOn
+
+	default:
+		return p.responsef(header, "%s", helpText)
+	}
+
+	if err = p.storeChannelQuietSchedule(header.ChannelId, schedule); err != nil {
+		return p.responsef(header, "Failed to update the channel. Error: %v.", err)
+	}
+	return p.responsef(header, "%s", mdChannelQuietSchedule(schedule))
+}
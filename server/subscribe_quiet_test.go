@@ -0,0 +1,124 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuietDays(t *testing.T) {
+	for s, expected := range map[string][]time.Weekday{
+		"daily":    {time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+		"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		"weekends": {time.Sunday, time.Saturday},
+		"mon,wed":  {time.Monday, time.Wednesday},
+		"Fri-Mon":  {time.Sunday, time.Monday, time.Friday, time.Saturday},
+		"tue":      {time.Tuesday},
+	} {
+		days, err := parseQuietDays(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, days, s)
+	}
+
+	for _, s := range []string{"", "someday", "mon-", "mon,,tue"} {
+		_, err := parseQuietDays(s)
+		assert.Error(t, err, s)
+	}
+
+	days, _ := parseQuietDays("mon-fri")
+	assert.Equal(t, "weekdays", mdQuietDays(days))
+	days, _ = parseQuietDays("mon,thu")
+	assert.Equal(t, "mon,thu", mdQuietDays(days))
+}
+
+func TestParseQuietWindow(t *testing.T) {
+	w, err := parseQuietWindow([]string{"weekdays", "14:00-15:00"}, "UTC")
+	require.NoError(t, err)
+	assert.Equal(t, "weekdays 14:00-15:00 UTC", w.String())
+
+	w, err = parseQuietWindow([]string{"sat", "22:00-06:00", "Europe/Paris"}, "UTC")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Paris", w.Timezone)
+
+	for _, args := range [][]string{
+		{"weekdays"},
+		{"weekdays", "14:00"},
+		{"weekdays", "14:00-14:00"},
+		{"weekdays", "14:00-25:00"},
+		{"weekdays", "14:00-15:00", "Mars/Olympus"},
+		{"weekdays", "14:00-15:00", "UTC", "extra"},
+	} {
+		_, err = parseQuietWindow(args, "UTC")
+		assert.Error(t, err, args)
+	}
+}
+
+func TestQuietWindowIsActive(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	at := func(day, clock string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04", day+" "+clock, paris)
+		require.NoError(t, err)
+		return tm
+	}
+	// 2026-10-12 is a Monday, 2026-10-17 a Saturday.
+	demo := quietWindow{Days: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, Start: "14:00", End: "15:00", Timezone: "Europe/Paris"}
+	night := quietWindow{Days: []time.Weekday{time.Friday}, Start: "22:00", End: "06:00", Timezone: "Europe/Paris"}
+
+	for name, tc := range map[string]struct {
+		window   quietWindow
+		time     time.Time
+		expected bool
+	}{
+		"before the start":           {demo, at("2026-10-12", "13:59"), false},
+		"at the start":               {demo, at("2026-10-12", "14:00"), true},
+		"in the window":              {demo, at("2026-10-12", "14:30"), true},
+		"at the end":                 {demo, at("2026-10-12", "15:00"), false},
+		"another day":                {demo, at("2026-10-17", "14:30"), false},
+		"in another timezone":        {demo, at("2026-10-12", "14:30").UTC(), true},
+		"overnight on the start day": {night, at("2026-10-16", "23:00"), true},
+		"overnight the next day":     {night, at("2026-10-17", "05:59"), true},
+		"overnight at the end":       {night, at("2026-10-17", "06:00"), false},
+		"overnight before the day":   {night, at("2026-10-16", "05:00"), false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.window.IsActive(tc.time))
+		})
+	}
+
+	schedule := &channelQuietSchedule{Windows: []quietWindow{demo, night}}
+	assert.True(t, schedule.IsActive(at("2026-10-17", "01:00")))
+	assert.False(t, schedule.IsActive(at("2026-10-17", "14:30")))
+	assert.False(t, (*channelQuietSchedule)(nil).IsActive(at("2026-10-12", "14:30")))
+}
+
+func TestPostChannelQuietSummaries(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	api := &plugintest.API{}
+	api.On("KVGet", keyChannelQuietPending).Return([]byte(`["chan-over","chan-quiet"]`), nil)
+	api.On("KVGet", prefixChannelQuiet+"chan-over").Return([]byte(`{"windows":[{"days":[0,1,2,3,4,5,6],"start":"10:00","end":"11:00","timezone":"UTC"}],"summary":true}`), nil)
+	api.On("KVGet", prefixChannelQuiet+"chan-quiet").Return([]byte(`{"windows":[{"days":[0,1,2,3,4,5,6],"start":"11:00","end":"13:00","timezone":"UTC"}],"summary":true}`), nil)
+	api.On("KVSetWithOptions", keyChannelQuietPending, []byte(`["chan-quiet"]`), mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+	api.On("KVGet", prefixChannelQuietSuppressed+"chan-over").Return([]byte(`{"channel_id":"chan-over","count":3}`), nil)
+	api.On("KVSetWithOptions", prefixChannelQuietSuppressed+"chan-over", []byte(nil), mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+	api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+		return post.ChannelId == "chan-over" && post.Message == "3 Jira updates were suppressed during the quiet window of this channel."
+	})).Return(&model.Post{ChannelId: "chan-over"}, nil)
+
+	p := Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	p.postChannelQuietSummaries(now)
+
+	api.AssertNumberOfCalls(t, "CreatePost", 1)
+	api.AssertNotCalled(t, "KVGet", prefixChannelQuietSuppressed+"chan-quiet")
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+)
+
+const (
+	// backfillMaxIssues caps the number of issues posted by a backfill, the
+	// most recently updated first.
+	backfillMaxIssues = 10
+
+	backfillPretext = "(backfill)"
+)
+
+// backfillDurationRegexp matches the durations that Jira accepts in relative
+// dates, e.g. 90m, 24h, 2d or 1w.
+var backfillDurationRegexp = regexp.MustCompile(`^(\d+)([mhdw])$`)
+
+// parseBackfillDuration returns the Jira relative date of a duration, e.g.
+// -24h for 24h.
+func parseBackfillDuration(duration string) (string, error) {
+	m := backfillDurationRegexp.FindStringSubmatch(strings.ToLower(strings.TrimSpace(duration)))
+	if m == nil {
+		return "", errors.Errorf("`%s` is not a duration, use a number of minutes, hours, days or weeks, e.g. `90m`, `24h`, `2d` or `1w`", duration)
+	}
+	if n, err := strconv.Atoi(m[1]); err != nil || n <= 0 {
+		return "", errors.Errorf("`%s` is not a duration, it must be greater than zero", duration)
+	}
+	return "-" + m[1] + m[2], nil
+}
+
+// backfillJQL returns the query of the issues of the projects and issue types
+// of the subscription, and matching its JQL, updated since a relative date.
+// The field filters of the subscription cannot all be expressed in JQL, so
+// the issues are still to be matched against them.
+func backfillJQL(filters SubscriptionFilters, since string) string {
+	clauses := []string{}
+	if filters.Projects.Len() != 0 {
+		projects := []string{}
+		for _, key := range filters.Projects.Elems() {
+			projects = append(projects, fmt.Sprintf("%q", key))
+		}
+		sort.Strings(projects)
+		clauses = append(clauses, fmt.Sprintf("project in (%s)", strings.Join(projects, ", ")))
+	}
+	if filters.IssueTypes.Len() != 0 {
+		issueTypes := filters.IssueTypes.Elems()
+		sort.Strings(issueTypes)
+		clauses = append(clauses, fmt.Sprintf("issuetype in (%s)", strings.Join(issueTypes, ", ")))
+	}
+	if jql := stripJQLOrderBy(filters.JQL); jql != "" {
+		clauses = append(clauses, "("+jql+")")
+	}
+	clauses = append(clauses, "updated >= "+since)
+	return strings.Join(clauses, " AND ") + " ORDER BY updated DESC"
+}
+
+// backfillMatches reports whether the current state of an issue matches the
+// field filters of a subscription. Its event filters are left out, since an
+// issue may have been updated many times.
+func (p *Plugin) backfillMatches(issue *jira.Issue, filters SubscriptionFilters) bool {
+	if issue.Fields == nil {
+		return false
+	}
+	wh := &webhook{
+		JiraWebhook: &JiraWebhook{
+			WebhookEvent: "jira:issue_updated",
+			Issue:        *issue,
+		},
+		eventTypes: simulatedUpdateEvents(),
+	}
+	filters.Events = NewStringSet(eventUpdatedAny)
+	return p.subscriptionFiltersMismatch(wh, filters) == ""
+}
+
+// findBackfillIssues returns the issues updated since a relative date that
+// match any of the subscriptions, the most recently updated first, and up to
+// backfillMaxIssues of them. more reports whether some were left out.
+func (p *Plugin) findBackfillIssues(client Client, subs []ChannelSubscription, since string) (issues []jira.Issue, more bool, err error) {
+	seen := map[string]bool{}
+	for _, sub := range subs {
+		found, total, err := client.SearchIssuesWithTotal(backfillJQL(sub.Filters, since), &jira.SearchOptions{
+			MaxResults: backfillMaxIssues,
+		})
+		if err != nil {
+			return nil, false, errors.WithMessagef(err, "failed to search the issues of the subscription %s", subscriptionLabel(sub))
+		}
+		if total > len(found) {
+			more = true
+		}
+		for i := range found {
+			if seen[found[i].Key] || !p.backfillMatches(&found[i], sub.Filters) {
+				continue
+			}
+			seen[found[i].Key] = true
+			issues = append(issues, found[i])
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return time.Time(issues[i].Fields.Updated).After(time.Time(issues[j].Fields.Updated))
+	})
+	if len(issues) > backfillMaxIssues {
+		issues = issues[:backfillMaxIssues]
+		more = true
+	}
+	return issues, more, nil
+}
+
+// backfill posts the current state of the issues to the channel, once. It
+// returns the number of issues posted.
+func (p *Plugin) backfill(instance Instance, client Client, channelID string, issues []jira.Issue) (int, error) {
+	posted := 0
+	for i := range issues {
+		attachments, err := asSlackAttachment(instance, client, &issues[i], false)
+		if err != nil {
+			return posted, errors.WithMessagef(err, "failed to render %s", issues[i].Key)
+		}
+		if len(attachments) > 0 {
+			attachments[0].Pretext = backfillPretext
+		}
+
+		post := makePost(p.getUserID(), channelID, "")
+		model.ParseSlackAttachment(post, attachments)
+		setIssuePostProps(post, instance.GetID(), issues[i].Key)
+		if err = p.createPost(post); err != nil {
+			return posted, errors.WithMessagef(err, "failed to post %s", issues[i].Key)
+		}
+		posted++
+	}
+	return posted, nil
+}
+
+func executeSubscribeSince(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to identify the Jira instance. Error: %v.", err)
+	}
+	if len(args) == 0 {
+		return p.responsef(header, "Please specify a duration, e.g. `/jira subscribe since 24h [subscription]`.")
+	}
+	since, err := parseBackfillDuration(args[0])
+	if err != nil {
+		return p.responsef(header, "%v.", err)
+	}
+
+	if err = p.hasPermissionToManageSubscription(instance.GetID(), header.UserId, header.ChannelId); err != nil {
+		return p.responsef(header, "You do not have permission to manage the Jira subscriptions of this channel: %v.", err)
+	}
+
+	subs, err := p.getSubscriptionsForChannel(instance.GetID(), header.ChannelId)
+	if err != nil {
+		return p.responsef(header, "Failed to load the subscriptions of this channel. Error: %v.", err)
+	}
+	if search := strings.Join(args[1:], " "); search != "" {
+		var subscription *ChannelSubscription
+		for i := range subs {
+			if subs[i].ID == search || subs[i].Name == search {
+				subscription = &subs[i]
+				break
+			}
+		}
+		if subscription == nil {
+			return p.responsef(header, "This channel has no subscription `%s`.", search)
+		}
+		subs = []ChannelSubscription{*subscription}
+	}
+	if len(subs) == 0 {
+		return p.responsef(header, "This channel has no Jira subscriptions to backfill.")
+	}
+
+	client, _, _, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+
+	issues, more, err := p.findBackfillIssues(client, subs, since)
+	if err != nil {
+		return p.responsef(header, "Failed to backfill the channel. Error: %v.", err)
+	}
+	if len(issues) == 0 {
+		return p.responsef(header, "No issue matching the subscriptions of this channel was updated in the last %s.", args[0])
+	}
+
+	posted, err := p.backfill(instance, client, header.ChannelId, issues)
+	if err != nil {
+		return p.responsef(header, "Backfilled %d issue(s), then failed. Error: %v.", posted, err)
+	}
+	msg := fmt.Sprintf("Backfilled %d issue(s) updated in the last %s.", posted, args[0])
+	if more {
+		msg += fmt.Sprintf(" Only the %d most recently updated ones were posted.", backfillMaxIssues)
+	}
+	return p.responsef(header, "%s", msg)
+}
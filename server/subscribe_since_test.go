@@ -0,0 +1,119 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type backfillTestClient struct {
+	testClient
+	issues map[string][]jira.Issue
+	jql    []string
+}
+
+func (client *backfillTestClient) SearchIssuesWithTotal(jql string, options *jira.SearchOptions) ([]jira.Issue, int, error) {
+	client.jql = append(client.jql, jql)
+	issues := client.issues[jql]
+	return issues, len(issues), nil
+}
+
+func TestParseBackfillDuration(t *testing.T) {
+	for duration, expected := range map[string]string{
+		"24h":  "-24h",
+		"90m":  "-90m",
+		"2d":   "-2d",
+		" 1W ": "-1w",
+	} {
+		since, err := parseBackfillDuration(duration)
+		require.NoError(t, err, duration)
+		assert.Equal(t, expected, since, duration)
+	}
+
+	for _, duration := range []string{"", "24", "h", "0h", "-1d", "1y", "1.5h", "24h OR 1=1"} {
+		_, err := parseBackfillDuration(duration)
+		assert.Error(t, err, duration)
+	}
+}
+
+func TestBackfillJQL(t *testing.T) {
+	assert.Equal(t, `project in ("KT", "TES") AND issuetype in (10001, 10002) AND updated >= -24h ORDER BY updated DESC`,
+		backfillJQL(SubscriptionFilters{
+			Projects:   NewStringSet("TES", "KT"),
+			IssueTypes: NewStringSet("10002", "10001"),
+		}, "-24h"))
+
+	assert.Equal(t, `(project = KT OR labels = ops) AND updated >= -2d ORDER BY updated DESC`,
+		backfillJQL(SubscriptionFilters{
+			Projects:   NewStringSet(),
+			IssueTypes: NewStringSet(),
+			JQL:        "project = KT OR labels = ops ORDER BY created",
+		}, "-2d"))
+}
+
+func TestFindBackfillIssues(t *testing.T) {
+	api := &plugintest.API{}
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+
+	issue := func(key, priorityID string, updated time.Time) jira.Issue {
+		return jira.Issue{
+			Key: key,
+			Fields: &jira.IssueFields{
+				Type:     jira.IssueType{ID: "10001"},
+				Project:  jira.Project{Key: "TES"},
+				Priority: &jira.Priority{ID: priorityID},
+				Updated:  jira.Time(updated),
+			},
+		}
+	}
+	now := time.Now()
+	bugs := SubscriptionFilters{
+		Events:     NewStringSet(eventCreated),
+		Projects:   NewStringSet("TES"),
+		IssueTypes: NewStringSet("10001"),
+	}
+	urgent := SubscriptionFilters{
+		Events:   NewStringSet(eventUpdatedPriority),
+		Projects: NewStringSet("TES"),
+		Fields: []FieldFilter{
+			{Key: priorityField, Inclusion: FilterIncludeAny, Values: NewStringSet("1")},
+		},
+	}
+	client := &backfillTestClient{
+		issues: map[string][]jira.Issue{
+			backfillJQL(bugs, "-24h"): {
+				issue("TES-1", "3", now.Add(-3*time.Hour)),
+				issue("TES-2", "1", now.Add(-2*time.Hour)),
+			},
+			backfillJQL(urgent, "-24h"): {
+				issue("TES-2", "1", now.Add(-2*time.Hour)),
+				issue("TES-3", "1", now.Add(-time.Hour)),
+				issue("TES-4", "3", now),
+			},
+		},
+	}
+
+	issues, more, err := p.findBackfillIssues(client, []ChannelSubscription{
+		{ID: "bugs", Filters: bugs},
+		{ID: "urgent", Filters: urgent},
+	}, "-24h")
+	require.NoError(t, err)
+	assert.False(t, more)
+	assert.Len(t, client.jql, 2)
+
+	keys := []string{}
+	for _, issue := range issues {
+		keys = append(keys, issue.Key)
+	}
+	assert.Equal(t, []string{"TES-3", "TES-2", "TES-1"}, keys)
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	subscriptionStatsKey = "subscription_stats"
+
+	// Counters are kept in hourly buckets, and buckets older than the
+	// retention window are dropped whenever the counters are updated.
+	subscriptionStatsRetention = 7 * 24 * time.Hour
+
+	// subscriptionStatsFlushInterval is how often the counters made on this
+	// server are added to the ones stored in the KV store.
+	subscriptionStatsFlushInterval = time.Minute
+)
+
+// SubscriptionCounter holds the number of posts a subscription made, bucketed
+// by the start of the hour (unix seconds) in which they were made.
+type SubscriptionCounter struct {
+	Hourly map[int64]int `json:"hourly"`
+}
+
+// SubscriptionStats holds the event counters of all subscriptions of an instance.
+type SubscriptionStats struct {
+	ByID map[string]*SubscriptionCounter `json:"by_id"`
+}
+
+func NewSubscriptionStats() *SubscriptionStats {
+	return &SubscriptionStats{
+		ByID: map[string]*SubscriptionCounter{},
+	}
+}
+
+func SubscriptionStatsFromJSON(bytes []byte) (*SubscriptionStats, error) {
+	stats := NewSubscriptionStats()
+	if len(bytes) == 0 {
+		return stats, nil
+	}
+
+	if err := json.Unmarshal(bytes, stats); err != nil {
+		return nil, err
+	}
+	if stats.ByID == nil {
+		stats.ByID = map[string]*SubscriptionCounter{}
+	}
+	return stats, nil
+}
+
+func (s *SubscriptionStats) increment(subscriptionID string, now time.Time) {
+	counter := s.ByID[subscriptionID]
+	if counter == nil {
+		counter = &SubscriptionCounter{Hourly: map[int64]int{}}
+		s.ByID[subscriptionID] = counter
+	}
+	counter.Hourly[now.Truncate(time.Hour).Unix()]++
+	s.rollover(now)
+}
+
+// rollover drops the buckets that fell out of the retention window, and the
+// counters of subscriptions that did not fire within it.
+func (s *SubscriptionStats) rollover(now time.Time) {
+	oldest := now.Add(-subscriptionStatsRetention).Truncate(time.Hour).Unix()
+	for id, counter := range s.ByID {
+		for hour := range counter.Hourly {
+			if hour < oldest {
+				delete(counter.Hourly, hour)
+			}
+		}
+		if len(counter.Hourly) == 0 {
+			delete(s.ByID, id)
+		}
+	}
+}
+
+func (s *SubscriptionStats) countSince(subscriptionID string, since time.Time) int {
+	counter := s.ByID[subscriptionID]
+	if counter == nil {
+		return 0
+	}
+
+	from := since.Truncate(time.Hour).Unix()
+	total := 0
+	for hour, n := range counter.Hourly {
+		if hour >= from {
+			total += n
+		}
+	}
+	return total
+}
+
+// add adds the counters of other to s.
+func (s *SubscriptionStats) add(other *SubscriptionStats, now time.Time) {
+	for id, otherCounter := range other.ByID {
+		counter := s.ByID[id]
+		if counter == nil {
+			counter = &SubscriptionCounter{Hourly: map[int64]int{}}
+			s.ByID[id] = counter
+		}
+		for hour, n := range otherCounter.Hourly {
+			counter.Hourly[hour] += n
+		}
+	}
+	s.rollover(now)
+}
+
+// subscriptionStatsBuffer holds the subscription events counted on this
+// server since its last flush, per instance. The webhook workers only touch
+// memory, so that they do not compete for the KV store value of the
+// instance. Its zero value is ready to use.
+type subscriptionStatsBuffer struct {
+	lock    sync.Mutex
+	pending map[types.ID]*SubscriptionStats
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func (p *Plugin) recordSubscriptionEvent(instanceID types.ID, subscriptionID string) {
+	b := &p.subscriptionStats
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.pending == nil {
+		b.pending = map[types.ID]*SubscriptionStats{}
+	}
+	stats := b.pending[instanceID]
+	if stats == nil {
+		stats = NewSubscriptionStats()
+		b.pending[instanceID] = stats
+	}
+	stats.increment(subscriptionID, time.Now())
+}
+
+// flushSubscriptionStats adds the counters buffered on this server to the
+// ones in the KV store. The counters that could not be stored are kept for
+// the next flush.
+func (p *Plugin) flushSubscriptionStats() {
+	b := &p.subscriptionStats
+	b.lock.Lock()
+	pending := b.pending
+	b.pending = nil
+	b.lock.Unlock()
+
+	for instanceID, stats := range pending {
+		if err := p.storeSubscriptionStats(instanceID, stats); err != nil {
+			p.client.Log.Warn("Failed to update the subscription event counters", "InstanceID", instanceID, "Error", err.Error())
+
+			b.lock.Lock()
+			if b.pending == nil {
+				b.pending = map[types.ID]*SubscriptionStats{}
+			}
+			if b.pending[instanceID] == nil {
+				b.pending[instanceID] = NewSubscriptionStats()
+			}
+			b.pending[instanceID].add(stats, time.Now())
+			b.lock.Unlock()
+		}
+	}
+}
+
+func (p *Plugin) storeSubscriptionStats(instanceID types.ID, delta *SubscriptionStats) error {
+	statsKey := keyWithInstanceID(instanceID, subscriptionStatsKey)
+	return p.client.KV.SetAtomicWithRetries(statsKey, func(initialBytes []byte) (interface{}, error) {
+		stats, err := SubscriptionStatsFromJSON(initialBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		stats.add(delta, time.Now())
+
+		modifiedBytes, marshalErr := json.Marshal(stats)
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+
+		return modifiedBytes, nil
+	})
+}
+
+// startSubscriptionStatsFlush flushes the buffered counters every interval,
+// until stopSubscriptionStatsFlush is called. Every server buffers its own
+// counters, so this is not a cluster job.
+func (p *Plugin) startSubscriptionStatsFlush(interval time.Duration) {
+	b := &p.subscriptionStats
+	b.stop = make(chan struct{})
+	b.done = make(chan struct{})
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.flushSubscriptionStats()
+			case <-stop:
+				return
+			}
+		}
+	}(b.stop, b.done)
+}
+
+// stopSubscriptionStatsFlush stops the flush loop, then flushes the counters
+// buffered since its last run.
+func (p *Plugin) stopSubscriptionStatsFlush() {
+	b := &p.subscriptionStats
+	if b.stop != nil {
+		close(b.stop)
+		<-b.done
+		b.stop = nil
+	}
+	p.flushSubscriptionStats()
+}
+
+func (p *Plugin) getSubscriptionStats(instanceID types.ID) (*SubscriptionStats, error) {
+	// Include the events counted on this server since the last flush.
+	p.flushSubscriptionStats()
+
+	statsKey := keyWithInstanceID(instanceID, subscriptionStatsKey)
+	var data []byte
+	err := p.client.KV.Get(statsKey, &data)
+	if err != nil {
+		return nil, err
+	}
+	return SubscriptionStatsFromJSON(data)
+}
+
+// formatSubscriptionStats renders the channel subscriptions ranked by the
+// number of posts they made in the last 24 hours, then in the last 7 days.
+func formatSubscriptionStats(subs []ChannelSubscription, stats *SubscriptionStats, now time.Time) string {
+	if len(subs) == 0 {
+		return "There are no Jira subscriptions in this channel."
+	}
+
+	type row struct {
+		name      string
+		lastDay   int
+		lastWeek  int
+		sortIndex int
+	}
+
+	rows := make([]row, 0, len(subs))
+	for i, sub := range subs {
+		name := escapeTableCell(sub.Name)
+		if name == "" {
+			name = sub.ID
+		}
+		rows = append(rows, row{
+			name:      name,
+			lastDay:   stats.countSince(sub.ID, now.Add(-24*time.Hour)),
+			lastWeek:  stats.countSince(sub.ID, now.Add(-subscriptionStatsRetention)),
+			sortIndex: i,
+		})
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].lastDay != rows[j].lastDay {
+			return rows[i].lastDay > rows[j].lastDay
+		}
+		if rows[i].lastWeek != rows[j].lastWeek {
+			return rows[i].lastWeek > rows[j].lastWeek
+		}
+		return rows[i].sortIndex < rows[j].sortIndex
+	})
+
+	msg := "#### Jira subscription activity in this channel\n\n" +
+		"| # | Subscription | Last 24h | Last 7d |\n" +
+		"|:--|:--|--:|--:|\n"
+	for i, r := range rows {
+		msg += fmt.Sprintf("| %d | %s | %d | %d |\n", i+1, r.name, r.lastDay, r.lastWeek)
+	}
+	return msg
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+func TestSubscriptionStats(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 30, 0, 0, time.UTC)
+
+	stats := NewSubscriptionStats()
+	stats.increment("sub1", now.Add(-8*24*time.Hour))
+	stats.increment("sub1", now.Add(-3*24*time.Hour))
+	stats.increment("sub1", now.Add(-time.Hour))
+	stats.increment("sub2", now.Add(-2*time.Hour))
+	stats.increment("sub2", now)
+	stats.increment("sub2", now)
+
+	t.Run("counts within window", func(t *testing.T) {
+		assert.Equal(t, 1, stats.countSince("sub1", now.Add(-24*time.Hour)))
+		assert.Equal(t, 2, stats.countSince("sub1", now.Add(-subscriptionStatsRetention)))
+		assert.Equal(t, 3, stats.countSince("sub2", now.Add(-24*time.Hour)))
+		assert.Equal(t, 0, stats.countSince("unknown", now.Add(-24*time.Hour)))
+	})
+
+	t.Run("rollover drops expired buckets", func(t *testing.T) {
+		stats.rollover(now.Add(5 * 24 * time.Hour))
+		assert.Len(t, stats.ByID["sub1"].Hourly, 1)
+		require.Contains(t, stats.ByID, "sub2")
+
+		stats.rollover(now.Add(30 * 24 * time.Hour))
+		assert.Empty(t, stats.ByID)
+	})
+
+	t.Run("JSON round trip", func(t *testing.T) {
+		empty, err := SubscriptionStatsFromJSON(nil)
+		require.NoError(t, err)
+		assert.NotNil(t, empty.ByID)
+
+		loaded, err := SubscriptionStatsFromJSON([]byte(`{"by_id":{"sub1":{"hourly":{"1710072000":4}}}}`))
+		require.NoError(t, err)
+		assert.Equal(t, 4, loaded.countSince("sub1", now.Add(-24*time.Hour)))
+	})
+}
+
+func TestFlushSubscriptionStats(t *testing.T) {
+	instanceID := types.ID("jiraurl1")
+	statsKey := keyWithInstanceID(instanceID, subscriptionStatsKey)
+
+	stored := NewSubscriptionStats()
+	stored.increment("sub1", time.Now())
+	storedBytes, err := json.Marshal(stored)
+	require.NoError(t, err)
+
+	t.Run("buffered events are added to the stored counters in one write", func(t *testing.T) {
+		api := &plugintest.API{}
+		p := Plugin{}
+		p.SetAPI(api)
+		p.client = pluginapi.NewClient(api, p.Driver)
+
+		api.On("KVGet", statsKey).Return(storedBytes, nil)
+		api.On("KVSetWithOptions", statsKey, mock.MatchedBy(func(data []byte) bool {
+			saved, err := SubscriptionStatsFromJSON(data)
+			return err == nil &&
+				saved.countSince("sub1", time.Now().Add(-time.Hour)) == 3 &&
+				saved.countSince("sub2", time.Now().Add(-time.Hour)) == 1
+		}), mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil).Once()
+
+		p.recordSubscriptionEvent(instanceID, "sub1")
+		p.recordSubscriptionEvent(instanceID, "sub1")
+		p.recordSubscriptionEvent(instanceID, "sub2")
+		api.AssertNotCalled(t, "KVSetWithOptions", mock.Anything, mock.Anything, mock.Anything)
+
+		p.flushSubscriptionStats()
+		api.AssertExpectations(t)
+		assert.Empty(t, p.subscriptionStats.pending)
+
+		// Nothing is left to write.
+		p.flushSubscriptionStats()
+		api.AssertNumberOfCalls(t, "KVSetWithOptions", 1)
+	})
+
+	t.Run("failed writes are kept for the next flush", func(t *testing.T) {
+		api := &plugintest.API{}
+		p := Plugin{}
+		p.SetAPI(api)
+		p.client = pluginapi.NewClient(api, p.Driver)
+
+		api.On("KVGet", statsKey).Return(nil, &model.AppError{Message: "unavailable"}).Once()
+		api.On("LogWarn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+		p.recordSubscriptionEvent(instanceID, "sub1")
+		p.flushSubscriptionStats()
+		require.Contains(t, p.subscriptionStats.pending, instanceID)
+		assert.Equal(t, 1, p.subscriptionStats.pending[instanceID].countSince("sub1", time.Now().Add(-time.Hour)))
+	})
+}
+
+func TestFormatSubscriptionStats(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 30, 0, 0, time.UTC)
+
+	stats := NewSubscriptionStats()
+	stats.increment("quiet", now.Add(-48*time.Hour))
+	stats.increment("noisy", now)
+	stats.increment("noisy", now)
+
+	subs := []ChannelSubscription{
+		{ID: "idle", Name: "Idle | old"},
+		{ID: "quiet", Name: "Quiet"},
+		{ID: "noisy", Name: "Noisy"},
+	}
+
+	assert.Equal(t, "#### Jira subscription activity in this channel\n\n"+
+		"| # | Subscription | Last 24h | Last 7d |\n"+
+		"|:--|:--|--:|--:|\n"+
+		"| 1 | Noisy | 2 | 2 |\n"+
+		"| 2 | Quiet | 0 | 1 |\n"+
+		"| 3 | Idle \\| old | 0 | 0 |\n",
+		formatSubscriptionStats(subs, stats, now))
+
+	assert.Equal(t, "There are no Jira subscriptions in this channel.", formatSubscriptionStats(nil, stats, now))
+}
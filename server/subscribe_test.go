@@ -75,6 +75,21 @@ func TestValidateSubscription(t *testing.T) {
 			},
 			errorMessage: "please provide at least one issue type",
 		},
+		"unknown render style": {
+			subscription: &ChannelSubscription{
+				ID:          "id",
+				Name:        "name",
+				ChannelID:   "channelid",
+				InstanceID:  "instance_id",
+				RenderStyle: "fancy",
+				Filters: SubscriptionFilters{
+					Events:     NewStringSet("issue_created"),
+					Projects:   NewStringSet("project"),
+					IssueTypes: NewStringSet("10001"),
+				},
+			},
+			errorMessage: "unknown render style \"fancy\", please use one of: full, compact, title",
+		},
 		"valid subscription": {
 			subscription: &ChannelSubscription{
 				ID:         "id",
@@ -238,7 +253,7 @@ func TestListChannelSubscriptions(t *testing.T) {
 				},
 			}),
 			RunAssertions: func(t *testing.T, actual string) {
-				expected := "The following channels have subscribed to Jira notifications. To modify a subscription, navigate to the channel and type `/jira subscribe edit`\n\n#### Team 1 Display Name\n* **~channel-1-name** (1):\n\t* (1) https://jiraurl1.com\n\t\t* PROJ - Sub Name X"
+				expected := "The following channels have subscribed to Jira notifications. To modify a subscription, navigate to the channel and type `/jira subscribe edit`\n\n#### Team 1 Display Name\n* **~channel-1-name** (1):\n\t* (1) https://jiraurl1.com\n\t\t* PROJ - Sub Name X - Project: PROJ"
 				assert.Equal(t, expected, actual)
 			},
 		},
@@ -262,7 +277,7 @@ func TestListChannelSubscriptions(t *testing.T) {
 				},
 			}),
 			RunAssertions: func(t *testing.T, actual string) {
-				expected := "The following channels have subscribed to Jira notifications. To modify a subscription, navigate to the channel and type `/jira subscribe edit`\n\n#### Group and Direct Messages\n* **channel-2-name-DM** (1):\n\t* (1) https://jiraurl1.com\n\t\t* PROJ - Sub Name X"
+				expected := "The following channels have subscribed to Jira notifications. To modify a subscription, navigate to the channel and type `/jira subscribe edit`\n\n#### Group and Direct Messages\n* **channel-2-name-DM** (1):\n\t* (1) https://jiraurl1.com\n\t\t* PROJ - Sub Name X - Project: PROJ"
 				assert.Equal(t, expected, actual)
 			},
 		},
@@ -454,7 +469,7 @@ func TestListChannelSubscriptions(t *testing.T) {
 			})).Return(nil)
 
 			p.client = pluginapi.NewClient(api, p.Driver)
-			actual, err := p.listChannelSubscriptions(testInstance1.InstanceID, team1.Id)
+			actual, err := p.listChannelSubscriptions(testInstance1.InstanceID, team1.Id, mockUserIDWithNotifications)
 			assert.Nil(t, err)
 			assert.NotNil(t, actual)
 
@@ -0,0 +1,179 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	subscriptionWebhookTokenLength = 24
+
+	subscriptionWebhookRegenerate = "regenerate"
+	subscriptionWebhookRevoke     = "revoke"
+)
+
+// newSubscriptionWebhookToken returns a random token for the webhook of a
+// subscription. It is the only credential of the webhook, there is no secret.
+func newSubscriptionWebhookToken() (string, error) {
+	buf := make([]byte, subscriptionWebhookTokenLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func (p *Plugin) getSubscriptionWebhookURL(instanceID types.ID, token string) string {
+	v := url.Values{}
+	v.Add("token", token)
+	return p.GetPluginURL() + instancePath(makeAPIRoute(routeAPISubscriptionWebhook), instanceID) + "?" + v.Encode()
+}
+
+// getSubscriptionByWebhookToken returns the subscription whose webhook has
+// the token.
+func (p *Plugin) getSubscriptionByWebhookToken(instanceID types.ID, token string) (*ChannelSubscription, error) {
+	subs, err := p.getSubscriptions(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs.Channel.ByID {
+		if sub.WebhookToken != "" && subtle.ConstantTimeCompare([]byte(sub.WebhookToken), []byte(token)) == 1 {
+			return &sub, nil
+		}
+	}
+	return nil, errors.New("the token does not match any subscription")
+}
+
+// updateSubscription stores the change of a subscription.
+func (p *Plugin) updateSubscription(instanceID types.ID, subscriptionID string, update func(sub *ChannelSubscription)) error {
+	subKey := keyWithInstanceID(instanceID, JiraSubscriptionsKey)
+	return p.client.KV.SetAtomicWithRetries(subKey, func(initialBytes []byte) (interface{}, error) {
+		subs, err := SubscriptionsFromJSON(initialBytes, instanceID)
+		if err != nil {
+			return nil, err
+		}
+		sub, ok := subs.Channel.ByID[subscriptionID]
+		if !ok {
+			return nil, errors.New("the subscription does not exist")
+		}
+		update(&sub)
+		subs.Channel.ByID[subscriptionID] = sub
+
+		modifiedBytes, marshalErr := json.Marshal(&subs)
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+		return modifiedBytes, nil
+	})
+}
+
+// httpSubscriptionWebhook receives the events of the webhook of a single
+// subscription, e.g. set in the webhooks of a Jira project by its admin. The
+// events are only matched against that subscription.
+func (p *Plugin) httpSubscriptionWebhook(w http.ResponseWriter, r *http.Request, instanceID types.ID) (int, error) {
+	token := r.FormValue("token")
+	if token == "" {
+		return respondErr(w, http.StatusForbidden, errors.New("request URL: token is missing"))
+	}
+	sub, err := p.getSubscriptionByWebhookToken(instanceID, token)
+	if err != nil {
+		return respondErr(w, http.StatusForbidden, errors.WithMessage(err, "request URL"))
+	}
+
+	bb, err := io.ReadAll(r.Body)
+	if err != nil {
+		return respondErr(w, http.StatusInternalServerError, err)
+	}
+	if p.getConfig().EnableWebhookEventLogging {
+		p.client.Log.Debug("Webhook Event Log", "event", string(bb), "SubscriptionID", sub.ID)
+	}
+
+	if !p.webhookDispatcher.Enqueue(&webhookMessage{
+		InstanceID:     instanceID,
+		SubscriptionID: sub.ID,
+		Data:           bb,
+	}) {
+		return respondErr(w, http.StatusServiceUnavailable, nil)
+	}
+	return http.StatusOK, nil
+}
+
+func executeSubscribeWebhook(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	_, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to identify the Jira instance. Error: %v.", err)
+	}
+	action := ""
+	if len(args) > 0 && (args[0] == subscriptionWebhookRegenerate || args[0] == subscriptionWebhookRevoke) {
+		action, args = args[0], args[1:]
+	}
+	if len(args) == 0 {
+		return p.responsef(header, "Please use `/jira subscribe webhook [regenerate|revoke] [subscription]`.")
+	}
+
+	if err = p.hasPermissionToManageSubscription(instance.GetID(), header.UserId, header.ChannelId); err != nil {
+		return p.responsef(header, "You do not have permission to manage the Jira subscriptions of this channel: %v.", err)
+	}
+
+	subs, err := p.getSubscriptionsForChannel(instance.GetID(), header.ChannelId)
+	if err != nil {
+		return p.responsef(header, "Failed to load the subscriptions of this channel. Error: %v.", err)
+	}
+	search := strings.Join(args, " ")
+	var subscription *ChannelSubscription
+	for i := range subs {
+		if subs[i].ID == search || subs[i].Name == search {
+			subscription = &subs[i]
+			break
+		}
+	}
+	if subscription == nil {
+		return p.responsef(header, "This channel has no subscription `%s`.", search)
+	}
+
+	if action == subscriptionWebhookRevoke {
+		if subscription.WebhookToken == "" {
+			return p.responsef(header, "The subscription **%s** has no webhook of its own.", subscription.Name)
+		}
+		if err = p.updateSubscription(instance.GetID(), subscription.ID, func(sub *ChannelSubscription) { sub.WebhookToken = "" }); err != nil {
+			return p.responsef(header, "Failed to update the subscription. Error: %v.", err)
+		}
+		return p.responsef(header, "The webhook of the subscription **%s** was revoked. The subscription receives the events of the subscriptions webhook of the instance again.", subscription.Name)
+	}
+
+	token := subscription.WebhookToken
+	if token == "" || action == subscriptionWebhookRegenerate {
+		token, err = newSubscriptionWebhookToken()
+		if err != nil {
+			return p.responsef(header, "Failed to create the webhook token. Error: %v.", err)
+		}
+		if err = p.updateSubscription(instance.GetID(), subscription.ID, func(sub *ChannelSubscription) { sub.WebhookToken = token }); err != nil {
+			return p.responsef(header, "Failed to update the subscription. Error: %v.", err)
+		}
+	}
+
+	msg := fmt.Sprintf("The events sent to this webhook are only matched against the subscription **%s**:\n`%s`\n",
+		subscription.Name, p.getSubscriptionWebhookURL(instance.GetID(), token))
+	if action == subscriptionWebhookRegenerate {
+		msg += "The previous URL no longer works, please update the webhook in Jira.\n"
+	}
+	msg += "Add it as a webhook of your Jira project, keep it secret as it needs no other credentials. " +
+		"The subscription no longer receives the events of the subscriptions webhook of the instance.\n" +
+		fmt.Sprintf("Use `/jira subscribe webhook regenerate %s` to replace the URL, or `/jira subscribe webhook revoke %s` to remove it.", subscription.Name, subscription.Name)
+	return p.responsef(header, "%s", msg)
+}
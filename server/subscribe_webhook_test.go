@@ -0,0 +1,98 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSubscriptionWebhookTest(t *testing.T) *Plugin {
+	filters := SubscriptionFilters{
+		Events:     NewStringSet("event_created"),
+		Projects:   NewStringSet("TES"),
+		IssueTypes: NewStringSet("10001"),
+	}
+	subs := withExistingChannelSubscriptions([]ChannelSubscription{
+		{ID: "shared", ChannelID: "shared_channel", Filters: filters},
+		{ID: "own", ChannelID: "own_channel", Filters: filters, WebhookToken: "own_token"},
+		{ID: "other", ChannelID: "other_channel", Filters: filters, WebhookToken: "other_token"},
+	})
+	subscriptionBytes, err := json.Marshal(subs)
+	require.NoError(t, err)
+
+	api := &plugintest.API{}
+	api.On("KVGet", testSubKey).Return(subscriptionBytes, nil)
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+	return p
+}
+
+func TestNewSubscriptionWebhookToken(t *testing.T) {
+	token, err := newSubscriptionWebhookToken()
+	require.NoError(t, err)
+	assert.Len(t, token, 32)
+	other, err := newSubscriptionWebhookToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestGetChannelsSubscribedVia(t *testing.T) {
+	p := setupSubscriptionWebhookTest(t)
+	data, err := getJiraTestData("webhook-issue-created.json")
+	require.NoError(t, err)
+	wh, err := ParseWebhook(data)
+	require.NoError(t, err)
+
+	channelIDs := func(subscriptionID string) []string {
+		subs, err := p.getChannelsSubscribedVia(wh.(*webhook), testInstance1.InstanceID, subscriptionID)
+		require.NoError(t, err)
+		ids := []string{}
+		for _, sub := range subs {
+			ids = append(ids, sub.ChannelID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"shared_channel"}, channelIDs(""), "the subscriptions with a webhook of their own are skipped")
+	assert.Equal(t, []string{"own_channel"}, channelIDs("own"))
+}
+
+func TestHTTPSubscriptionWebhook(t *testing.T) {
+	for name, tc := range map[string]struct {
+		token      string
+		statusCode int
+	}{
+		"no token":      {statusCode: http.StatusForbidden},
+		"unknown token": {token: "nope", statusCode: http.StatusForbidden},
+		"token":         {token: "own_token", statusCode: http.StatusOK},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := setupSubscriptionWebhookTest(t)
+			received := make(chan *webhookMessage, 1)
+			p.webhookDispatcher = newWebhookDispatcher(1, 1, func(_ int, msg *webhookMessage) error {
+				received <- msg
+				return nil
+			})
+
+			url := "/webhook?token=" + tc.token
+			w := httptest.NewRecorder()
+			status, _ := p.httpSubscriptionWebhook(w, httptest.NewRequest(http.MethodPost, url, strings.NewReader("{}")), testInstance1.InstanceID)
+			assert.Equal(t, tc.statusCode, status)
+			if tc.statusCode == http.StatusOK {
+				msg := <-received
+				assert.Equal(t, "own", msg.SubscriptionID)
+				assert.Equal(t, testInstance1.InstanceID, msg.InstanceID)
+			}
+		})
+	}
+}
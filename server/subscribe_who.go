@@ -0,0 +1,135 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// subscriptionMatch is the outcome of evaluating a subscription's filters
+// against an issue. Reason is empty if the subscription matches.
+type subscriptionMatch struct {
+	Subscription ChannelSubscription
+	Reason       string
+}
+
+// simulatedUpdateEvents are the events of a simulated update touching every
+// field of the issue.
+func simulatedUpdateEvents() StringSet {
+	events := NewStringSet()
+	for _, event := range allEvents.Elems() {
+		if strings.HasPrefix(event, "event_updated") {
+			events = events.Add(event)
+		}
+	}
+	return events
+}
+
+// evaluateSubscriptions evaluates the filters of every subscription of the
+// instance against the issue, as if it had been updated. It only reads the
+// subscriptions, nothing is posted. Subscriptions to other projects are
+// left out, and only counted.
+func (p *Plugin) evaluateSubscriptions(instanceID types.ID, issue *jira.Issue) (matches []subscriptionMatch, otherProjects int, err error) {
+	subs, err := p.getSubscriptions(instanceID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	wh := &webhook{
+		JiraWebhook: &JiraWebhook{
+			WebhookEvent: "jira:issue_updated",
+			Issue:        *issue,
+		},
+		eventTypes: simulatedUpdateEvents(),
+	}
+
+	jqlMatched := map[string]bool{}
+	for _, sub := range subs.Channel.ByID {
+		reason := p.subscriptionFiltersMismatch(wh, sub.Filters)
+		if reason == "" && !p.subscriptionJQLMatches(instanceID, sub, issue.Key, jqlMatched) {
+			reason = "the issue does not match its JQL"
+		}
+		if reason != "" && sub.Filters.Projects.Len() != 0 && !sub.Filters.Projects.ContainsAny(issue.Fields.Project.Key) {
+			otherProjects++
+			continue
+		}
+		matches = append(matches, subscriptionMatch{
+			Subscription: sub,
+			Reason:       reason,
+		})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if (matches[i].Reason == "") != (matches[j].Reason == "") {
+			return matches[i].Reason == ""
+		}
+		return matches[i].Subscription.Name < matches[j].Subscription.Name
+	})
+	return matches, otherProjects, nil
+}
+
+func (p *Plugin) subscribeWho(instanceID types.ID, client Client, issueKey string) (string, error) {
+	issue, err := client.GetIssue(issueKey, nil)
+	if err != nil {
+		return "", err
+	}
+	if issue.Fields == nil || issue.Fields.Type.ID == "" || issue.Fields.Project.Key == "" {
+		return "", errors.Errorf("the issue %s has no project or issue type", issueKey)
+	}
+
+	matches, otherProjects, err := p.evaluateSubscriptions(instanceID, issue)
+	if err != nil {
+		return "", err
+	}
+	return p.formatSubscribeWho(issueKey, matches, otherProjects), nil
+}
+
+func (p *Plugin) formatSubscribeWho(issueKey string, matches []subscriptionMatch, otherProjects int) string {
+	matched := []string{}
+	missed := []string{}
+	for _, m := range matches {
+		row := fmt.Sprintf("* %s in %s", subscriptionLabel(m.Subscription), p.channelLabel(m.Subscription.ChannelID))
+		if m.Reason == "" {
+			matched = append(matched, row)
+		} else {
+			missed = append(missed, row+": "+m.Reason)
+		}
+	}
+
+	msg := fmt.Sprintf("#### Subscriptions notified of an update of %s\n", issueKey)
+	if len(matched) == 0 {
+		msg += "No subscription would be notified.\n"
+	} else {
+		msg += strings.Join(matched, "\n") + "\n"
+	}
+	if len(missed) > 0 {
+		msg += fmt.Sprintf("\n#### Subscriptions to the same project that would not be notified\n%s\n", strings.Join(missed, "\n"))
+	}
+	if otherProjects > 0 {
+		msg += fmt.Sprintf("\n%d subscription(s) to other projects were left out.\n", otherProjects)
+	}
+	return msg
+}
+
+func subscriptionLabel(sub ChannelSubscription) string {
+	if sub.Name == "" {
+		return fmt.Sprintf("(No Name) `%s`", sub.ID)
+	}
+	return fmt.Sprintf("**%s**", sub.Name)
+}
+
+func (p *Plugin) channelLabel(channelID string) string {
+	channel, err := p.client.Channel.Get(channelID)
+	if err != nil {
+		return "channel " + channelID
+	}
+	return "~" + channel.Name
+}
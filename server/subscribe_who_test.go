@@ -0,0 +1,100 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateSubscriptions(t *testing.T) {
+	api := &plugintest.API{}
+	p := &Plugin{}
+	p.SetAPI(api)
+	p.client = pluginapi.NewClient(api, p.Driver)
+
+	subs := withExistingChannelSubscriptions([]ChannelSubscription{
+		{
+			ID:        "sub_match",
+			Name:      "Bugs",
+			ChannelID: "channel1",
+			Filters: SubscriptionFilters{
+				Events:     NewStringSet(eventUpdatedAny),
+				Projects:   NewStringSet("TES"),
+				IssueTypes: NewStringSet("10001"),
+			},
+		},
+		{
+			ID:        "sub_created_only",
+			Name:      "New issues",
+			ChannelID: "channel2",
+			Filters: SubscriptionFilters{
+				Events:     NewStringSet(eventCreated),
+				Projects:   NewStringSet("TES"),
+				IssueTypes: NewStringSet("10001"),
+			},
+		},
+		{
+			ID:        "sub_priority",
+			Name:      "Urgent",
+			ChannelID: "channel2",
+			Filters: SubscriptionFilters{
+				Events:     NewStringSet(eventUpdatedPriority),
+				Projects:   NewStringSet("TES"),
+				IssueTypes: NewStringSet("10001"),
+				Fields: []FieldFilter{
+					{Key: priorityField, Inclusion: FilterIncludeAny, Values: NewStringSet("1")},
+				},
+			},
+		},
+		{
+			ID:        "sub_other_project",
+			Name:      "Other",
+			ChannelID: "channel3",
+			Filters: SubscriptionFilters{
+				Events:     NewStringSet(eventUpdatedAny),
+				Projects:   NewStringSet("OTHER"),
+				IssueTypes: NewStringSet("10001"),
+			},
+		},
+	})
+	subscriptionBytes, err := json.Marshal(subs)
+	require.NoError(t, err)
+	api.On("KVGet", keyWithInstanceID(testInstance1.InstanceID, JiraSubscriptionsKey)).Return(subscriptionBytes, nil)
+
+	issue := &jira.Issue{
+		Key: "TES-1",
+		Fields: &jira.IssueFields{
+			Type:     jira.IssueType{ID: "10001", Name: "Bug"},
+			Project:  jira.Project{Key: "TES"},
+			Priority: &jira.Priority{ID: "3", Name: "Medium"},
+		},
+	}
+
+	matches, otherProjects, err := p.evaluateSubscriptions(testInstance1.InstanceID, issue)
+	require.NoError(t, err)
+	assert.Equal(t, 1, otherProjects)
+	require.Len(t, matches, 3)
+
+	assert.Equal(t, "sub_match", matches[0].Subscription.ID)
+	assert.Empty(t, matches[0].Reason)
+	assert.Equal(t, "sub_created_only", matches[1].Subscription.ID)
+	assert.Contains(t, matches[1].Reason, "not one of its events")
+	assert.Equal(t, "sub_priority", matches[2].Subscription.ID)
+	assert.Contains(t, matches[2].Reason, "priority include_any")
+
+	api.On("GetChannel", mock.AnythingOfType("string")).Return(&model.Channel{Name: "town-square"}, nil)
+	msg := p.formatSubscribeWho("TES-1", matches, otherProjects)
+	assert.Contains(t, msg, "* **Bugs** in ~town-square\n")
+	assert.Contains(t, msg, "* **Urgent** in ~town-square: its filter `priority include_any` excludes it")
+	assert.Contains(t, msg, "1 subscription(s) to other projects were left out.")
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// subscriptionMatchRecipient is a Jira user with a role in an issue that a
+// subscription posted about.
+type subscriptionMatchRecipient struct {
+	user *jira.User
+	role string
+}
+
+func sameJiraUser(user *jira.User, accountID, name string) bool {
+	if user.AccountID != "" {
+		return user.AccountID == accountID
+	}
+	return user.Name != "" && user.Name == name
+}
+
+// subscriptionMatchRecipients returns the assignee and the reporter of the
+// issue of the event, unless they already get a DM about it.
+func subscriptionMatchRecipients(wh *webhook) []subscriptionMatchRecipient {
+	if wh.Issue.Fields == nil {
+		return nil
+	}
+
+	recipients := []subscriptionMatchRecipient{}
+	for _, candidate := range []subscriptionMatchRecipient{
+		{wh.Issue.Fields.Assignee, "assigned to you"},
+		{wh.Issue.Fields.Reporter, "reported by you"},
+	} {
+		if candidate.user == nil || (candidate.user.AccountID == "" && candidate.user.Name == "") {
+			continue
+		}
+		notified := false
+		for _, n := range wh.notifications {
+			if sameJiraUser(candidate.user, n.jiraAccountID, n.jiraUsername) {
+				notified = true
+				break
+			}
+		}
+		for _, r := range recipients {
+			if sameJiraUser(candidate.user, r.user.AccountID, r.user.Name) {
+				notified = true
+				break
+			}
+		}
+		if !notified {
+			recipients = append(recipients, candidate)
+		}
+	}
+	return recipients
+}
+
+// notifySubscriptionMatch sends a DM to the assignee and the reporter of the
+// issue, if they opted in, when subscriptions of channels they are a member
+// of posted the event.
+func (p *Plugin) notifySubscriptionMatch(instanceID types.ID, wh *webhook, deliveries []*channelDelivery, channels map[string]*model.Channel) {
+	if len(deliveries) == 0 {
+		return
+	}
+
+	for _, recipient := range subscriptionMatchRecipients(wh) {
+		jiraUserID := recipient.user.AccountID
+		if jiraUserID == "" {
+			jiraUserID = recipient.user.Name
+		}
+		mattermostUserID, err := p.userStore.LoadMattermostUserID(instanceID, jiraUserID)
+		if err != nil {
+			continue
+		}
+		c, err := p.userStore.LoadConnection(instanceID, mattermostUserID)
+		if err != nil || c.Settings == nil || !c.Settings.NotifyOnSubscriptionMatch {
+			continue
+		}
+		if c.Settings.ShouldIgnoreOwnActions() && wh.JiraWebhook.isTriggeredBy(c) {
+			continue
+		}
+
+		channelNames := []string{}
+		for _, delivery := range deliveries {
+			channel := channels[delivery.ChannelID]
+			if channel == nil {
+				continue
+			}
+			if _, err = p.client.Channel.GetMember(channel.Id, mattermostUserID.String()); err != nil {
+				continue
+			}
+			channelNames = append(channelNames, "~"+channel.Name)
+		}
+		if len(channelNames) == 0 {
+			continue
+		}
+
+		message := fmt.Sprintf("A subscription posted about %s, %s, in %s", wh.mdKeySummaryLink(), recipient.role, strings.Join(channelNames, ", "))
+		if _, err = p.CreateBotIssueDMPost(instanceID, mattermostUserID, wh.Issue.Key, message, "", p.postPriority(wh.JiraWebhook.issuePriority())); err != nil {
+			p.errorf("notifySubscriptionMatch: failed to create notification post, err: %v", err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/kvstore"
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// subscriptionMatchUserStore maps the Jira account IDs to the connections of
+// their Mattermost users, whose ID is "mm-" and the account ID.
+type subscriptionMatchUserStore struct {
+	mockUserStore
+	connections map[string]*Connection
+}
+
+func (store subscriptionMatchUserStore) LoadMattermostUserID(instanceID types.ID, jiraUserID string) (types.ID, error) {
+	if _, ok := store.connections[jiraUserID]; !ok {
+		return "", kvstore.ErrNotFound
+	}
+	return types.ID("mm-" + jiraUserID), nil
+}
+
+func (store subscriptionMatchUserStore) LoadConnection(instanceID, mattermostUserID types.ID) (*Connection, error) {
+	c, ok := store.connections[mattermostUserID.String()[len("mm-"):]]
+	if !ok {
+		return nil, kvstore.ErrNotFound
+	}
+	return c, nil
+}
+
+func subscriptionMatchWebhook(assignee, reporter *jira.User, notified ...string) *webhook {
+	wh := &webhook{JiraWebhook: &JiraWebhook{
+		User: jira.User{AccountID: "actor"},
+		Issue: jira.Issue{
+			Key:  "KT-1",
+			Self: "https://jira.example.com/rest/api/2/issue/1",
+			Fields: &jira.IssueFields{
+				Summary:  "Fix the login",
+				Type:     jira.IssueType{Name: "Bug"},
+				Assignee: assignee,
+				Reporter: reporter,
+			},
+		},
+	}}
+	for _, accountID := range notified {
+		wh.notifications = append(wh.notifications, webhookUserNotification{jiraAccountID: accountID})
+	}
+	return wh
+}
+
+func TestSubscriptionMatchRecipients(t *testing.T) {
+	alice := &jira.User{AccountID: "alice"}
+	bob := &jira.User{AccountID: "bob"}
+
+	roles := func(recipients []subscriptionMatchRecipient) map[string]string {
+		out := map[string]string{}
+		for _, r := range recipients {
+			out[r.user.AccountID] = r.role
+		}
+		return out
+	}
+
+	assert.Equal(t, map[string]string{"alice": "assigned to you", "bob": "reported by you"},
+		roles(subscriptionMatchRecipients(subscriptionMatchWebhook(alice, bob))))
+	assert.Equal(t, map[string]string{"alice": "assigned to you"},
+		roles(subscriptionMatchRecipients(subscriptionMatchWebhook(alice, alice))))
+	assert.Equal(t, map[string]string{"bob": "reported by you"},
+		roles(subscriptionMatchRecipients(subscriptionMatchWebhook(alice, bob, "alice"))))
+	assert.Equal(t, map[string]string{},
+		roles(subscriptionMatchRecipients(subscriptionMatchWebhook(nil, &jira.User{}))))
+}
+
+func TestNotifySubscriptionMatch(t *testing.T) {
+	optedIn := &Connection{Settings: &ConnectionSettings{Notifications: true, NotifyOnSubscriptionMatch: true}}
+	deliveries := []*channelDelivery{{ChannelID: "channel1"}, {ChannelID: "channel2"}}
+	channels := map[string]*model.Channel{
+		"channel1": {Id: "channel1", Name: "town-square"},
+		"channel2": {Id: "channel2", Name: "bugs"},
+	}
+
+	for name, tc := range map[string]struct {
+		connections     map[string]*Connection
+		members         map[string]bool
+		expectedMessage string
+	}{
+		"assignee opted in": {
+			connections:     map[string]*Connection{"alice": optedIn},
+			members:         map[string]bool{"channel1": true, "channel2": true},
+			expectedMessage: "A subscription posted about bug [KT-1: Fix the login](https://jira.example.com/browse/KT-1), assigned to you, in ~town-square, ~bugs",
+		},
+		"only the channels the user is a member of": {
+			connections:     map[string]*Connection{"alice": optedIn},
+			members:         map[string]bool{"channel2": true},
+			expectedMessage: "A subscription posted about bug [KT-1: Fix the login](https://jira.example.com/browse/KT-1), assigned to you, in ~bugs",
+		},
+		"not a member of the channels": {
+			connections: map[string]*Connection{"alice": optedIn},
+		},
+		"not opted in": {
+			connections: map[string]*Connection{"alice": {Settings: &ConnectionSettings{Notifications: true}}},
+			members:     map[string]bool{"channel1": true},
+		},
+		"not connected": {
+			members: map[string]bool{"channel1": true},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			p := &Plugin{}
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+			p.userStore = subscriptionMatchUserStore{connections: tc.connections}
+			p.updateConfig(func(conf *config) {
+				conf.botUserID = "bot"
+			})
+
+			for channelID := range channels {
+				if tc.members[channelID] {
+					api.On("GetChannelMember", channelID, "mm-alice").Return(&model.ChannelMember{}, nil)
+				} else {
+					api.On("GetChannelMember", channelID, "mm-alice").Return(nil, &model.AppError{Message: "not a member"})
+				}
+			}
+			api.On("GetDirectChannel", "mm-alice", "bot").Return(&model.Channel{Id: "dm"}, nil)
+			api.On("KVGet", mock.AnythingOfType("string")).Return(nil, nil)
+			messages := []string{}
+			api.On("CreatePost", mock.AnythingOfType("*model.Post")).Run(func(args mock.Arguments) {
+				messages = append(messages, args.Get(0).(*model.Post).Message)
+			}).Return(&model.Post{}, nil)
+
+			p.notifySubscriptionMatch("jiraurl1", subscriptionMatchWebhook(&jira.User{AccountID: "alice"}, nil), deliveries, channels)
+
+			if tc.expectedMessage == "" {
+				assert.Empty(t, messages)
+				return
+			}
+			assert.Equal(t, []string{tc.expectedMessage}, messages)
+		})
+	}
+
+	t.Run("nothing was posted", func(t *testing.T) {
+		p := &Plugin{}
+		p.userStore = subscriptionMatchUserStore{connections: map[string]*Connection{"alice": optedIn}}
+		p.notifySubscriptionMatch("jiraurl1", subscriptionMatchWebhook(&jira.User{AccountID: "alice"}, nil), nil, channels)
+	})
+}
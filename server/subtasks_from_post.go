@@ -0,0 +1,198 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// maxSummaryLength is the longest summary Jira accepts for an issue.
+const maxSummaryLength = 255
+
+// checklistItemRegexp matches the items of a Markdown task or bullet list,
+// e.g. "- [ ] Write the docs", "* Ship it" or "2. Announce it", capturing
+// the text of the item.
+var checklistItemRegexp = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?(.+?)\s*$`)
+
+// parseChecklistItems returns the text of the list items of a post, in order.
+// Lines that are not list items, such as a heading, are ignored.
+func parseChecklistItems(message string) []string {
+	items := []string{}
+	for _, line := range strings.Split(message, "\n") {
+		match := checklistItemRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		items = append(items, match[1])
+	}
+	return items
+}
+
+type InCreateSubtasksFromPost struct {
+	mattermostUserID types.ID
+	InstanceID       types.ID `json:"instance_id"`
+	PostID           string   `json:"post_id"`
+	ParentIssueKey   string   `json:"parent_issue_key"`
+}
+
+type subtaskFailure struct {
+	Summary string `json:"summary"`
+	Error   string `json:"error"`
+}
+
+type OutCreateSubtasksFromPost struct {
+	Created []string         `json:"created"`
+	Failed  []subtaskFailure `json:"failed"`
+}
+
+func (p *Plugin) httpCreateSubtasksFromPost(w http.ResponseWriter, r *http.Request) (int, error) {
+	in := InCreateSubtasksFromPost{}
+	err := json.NewDecoder(r.Body).Decode(&in)
+	if err != nil {
+		return respondErr(w, http.StatusBadRequest,
+			errors.WithMessage(err, "failed to decode incoming request"))
+	}
+
+	in.mattermostUserID = types.ID(r.Header.Get("Mattermost-User-Id"))
+	out, err := p.CreateSubtasksFromPost(&in)
+	if err != nil {
+		return respondErr(w, http.StatusInternalServerError,
+			errors.WithMessage(err, "failed to create the sub-tasks"))
+	}
+
+	return respondJSON(w, out)
+}
+
+// findSubtaskIssueType returns the first sub-task issue type of a project.
+func (p *Plugin) findSubtaskIssueType(client Client, projectKey string) (*jira.MetaIssueType, error) {
+	metaInfo, err := client.GetCreateMetaInfo(p.API, &jira.GetQueryOptions{
+		ProjectKeys: projectKey,
+	})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to get the issue types of project %q", projectKey)
+	}
+	for _, project := range metaInfo.Projects {
+		if project.Key != projectKey {
+			continue
+		}
+		for _, issueType := range project.IssueTypes {
+			if issueType.Subtasks {
+				return issueType, nil
+			}
+		}
+	}
+	return nil, errors.Errorf("project %s has no sub-task issue type", projectKey)
+}
+
+// createSubtasks creates a sub-task of the parent issue for each of the
+// summaries, with the first sub-task issue type of the project of the parent.
+// An error is returned only if the parent cannot have sub-tasks; the failures
+// to create some of them are part of the outcome.
+func (p *Plugin) createSubtasks(client Client, parentKey string, summaries []string) (*OutCreateSubtasksFromPost, error) {
+	if parentKey == "" {
+		return nil, errors.New("please provide the key of the parent issue")
+	}
+	parent, err := client.GetIssue(parentKey, &jira.GetQueryOptions{Fields: "project,issuetype"})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to find the parent issue %s", parentKey)
+	}
+	if parent.Fields == nil {
+		return nil, errors.Errorf("failed to find the parent issue %s", parentKey)
+	}
+	if parent.Fields.Type.Subtask {
+		return nil, errors.Errorf("%s is a sub-task, and a sub-task cannot have sub-tasks", parentKey)
+	}
+	projectKey := parent.Fields.Project.Key
+	issueType, err := p.findSubtaskIssueType(client, projectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OutCreateSubtasksFromPost{
+		Created: []string{},
+		Failed:  []subtaskFailure{},
+	}
+	for _, summary := range summaries {
+		summary = truncate(summary, maxSummaryLength)
+		created, err := client.CreateIssue(&jira.Issue{
+			Fields: &jira.IssueFields{
+				Project: jira.Project{Key: projectKey},
+				Type:    jira.IssueType{ID: issueType.Id},
+				Summary: summary,
+				Parent:  &jira.Parent{Key: parentKey},
+			},
+		})
+		if err != nil {
+			out.Failed = append(out.Failed, subtaskFailure{Summary: summary, Error: err.Error()})
+			continue
+		}
+		out.Created = append(out.Created, created.Key)
+	}
+	return out, nil
+}
+
+// CreateSubtasksFromPost creates a sub-task of the parent issue for each item
+// of the checklist of a post, and reports the created keys and the failures
+// to the user.
+func (p *Plugin) CreateSubtasksFromPost(in *InCreateSubtasksFromPost) (*OutCreateSubtasksFromPost, error) {
+	client, instance, _, err := p.getClient(in.InstanceID, in.mattermostUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := p.client.Post.GetPost(in.PostID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to load post "+in.PostID)
+	}
+	if post == nil {
+		return nil, errors.New("failed to load post " + in.PostID + ": not found")
+	}
+	if p.isChannelReadOnly(post.ChannelId) {
+		return nil, errors.New(channelReadOnlyMessage)
+	}
+
+	items := parseChecklistItems(post.Message)
+	if len(items) == 0 {
+		return nil, errors.New("the message has no checklist or list items")
+	}
+
+	parentKey := strings.ToUpper(strings.TrimSpace(in.ParentIssueKey))
+	out, err := p.createSubtasks(client, parentKey, items)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range out.Created {
+		p.recordSelfChange(instance.GetID(), key, in.mattermostUserID)
+	}
+
+	p.client.Post.SendEphemeralPost(in.mattermostUserID.String(), makePost(p.getUserID(), post.ChannelId,
+		subtasksFromPostSummary(instance.GetJiraBaseURL(), parentKey, out)))
+	return out, nil
+}
+
+func subtasksFromPostSummary(jiraBaseURL, parentKey string, out *OutCreateSubtasksFromPost) string {
+	links := []string{}
+	for _, key := range out.Created {
+		links = append(links, fmt.Sprintf("[%s](%s/browse/%s)", key, jiraBaseURL, key))
+	}
+
+	msg := fmt.Sprintf("Created %d of %d sub-tasks of [%s](%s/browse/%s)",
+		len(out.Created), len(out.Created)+len(out.Failed), parentKey, jiraBaseURL, parentKey)
+	if len(links) > 0 {
+		msg += ": " + strings.Join(links, ", ")
+	}
+	for _, failure := range out.Failed {
+		msg += fmt.Sprintf("\n* Failed to create %q: %s", failure.Summary, failure.Error)
+	}
+	return msg
+}
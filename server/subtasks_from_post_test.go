@@ -0,0 +1,99 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChecklistItems(t *testing.T) {
+	message := "Release plan:\n" +
+		"- [ ] Freeze the branch\n" +
+		"- [x]   Write the release notes  \n" +
+		"* Tag the release\n" +
+		"\n" +
+		"1. Announce it\n" +
+		"2) Celebrate\n" +
+		"-not an item\n" +
+		"Thanks!"
+	assert.Equal(t, []string{
+		"Freeze the branch",
+		"Write the release notes",
+		"Tag the release",
+		"Announce it",
+		"Celebrate",
+	}, parseChecklistItems(message))
+	assert.Empty(t, parseChecklistItems("No list here"))
+}
+
+type subtaskCreateTestClient struct {
+	subtaskTestClient
+	created []*jira.Issue
+}
+
+func (client *subtaskCreateTestClient) GetIssue(issueKey string, options *jira.GetQueryOptions) (*jira.Issue, error) {
+	if issueKey == "TEST-2" {
+		return &jira.Issue{Key: issueKey, Fields: &jira.IssueFields{
+			Project: jira.Project{Key: "TEST"},
+			Type:    jira.IssueType{Name: "Sub-task", Subtask: true},
+		}}, nil
+	}
+	return client.subtaskTestClient.GetIssue(issueKey, options)
+}
+
+func (client *subtaskCreateTestClient) CreateIssue(issue *jira.Issue) (*jira.Issue, error) {
+	if issue.Fields.Summary == "Break it" {
+		return nil, errors.New("summary is not allowed")
+	}
+	client.created = append(client.created, issue)
+	return &jira.Issue{Key: fmt.Sprintf("TEST-%d", 10+len(client.created))}, nil
+}
+
+func TestCreateSubtasks(t *testing.T) {
+	p := &Plugin{}
+
+	t.Run("one sub-task per item, with partial failures", func(t *testing.T) {
+		client := &subtaskCreateTestClient{}
+		out, err := p.createSubtasks(client, "TEST-1", []string{"Freeze the branch", "Break it", "Tag the release"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"TEST-11", "TEST-12"}, out.Created)
+		assert.Equal(t, []subtaskFailure{{Summary: "Break it", Error: "summary is not allowed"}}, out.Failed)
+
+		require.Len(t, client.created, 2)
+		fields := client.created[0].Fields
+		assert.Equal(t, "TEST", fields.Project.Key)
+		assert.Equal(t, "10002", fields.Type.ID)
+		assert.Equal(t, "TEST-1", fields.Parent.Key)
+		assert.Equal(t, "Freeze the branch", fields.Summary)
+
+		assert.Equal(t, "Created 2 of 3 sub-tasks of [TEST-1](https://jira/browse/TEST-1): "+
+			"[TEST-11](https://jira/browse/TEST-11), [TEST-12](https://jira/browse/TEST-12)\n"+
+			"* Failed to create \"Break it\": summary is not allowed",
+			subtasksFromPostSummary("https://jira", "TEST-1", out))
+	})
+
+	t.Run("parent is a sub-task", func(t *testing.T) {
+		_, err := p.createSubtasks(&subtaskCreateTestClient{}, "TEST-2", []string{"Freeze the branch"})
+		require.Error(t, err)
+		assert.Equal(t, "TEST-2 is a sub-task, and a sub-task cannot have sub-tasks", err.Error())
+	})
+
+	t.Run("project without a sub-task issue type", func(t *testing.T) {
+		_, err := p.createSubtasks(&subtaskCreateTestClient{}, "OTHER-1", []string{"Freeze the branch"})
+		require.Error(t, err)
+		assert.Equal(t, "project OTHER has no sub-task issue type", err.Error())
+	})
+
+	t.Run("parent does not exist", func(t *testing.T) {
+		_, err := p.createSubtasks(&subtaskCreateTestClient{}, "TEST-404", []string{"Freeze the branch"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to find the parent issue TEST-404")
+	})
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// statusChangedAt returns when the issue entered its current status: the
+// latest status change of its changelog, or its creation if it never changed
+// status. It returns false without a changelog, i.e. when the issue was not
+// fetched with expand=changelog.
+func statusChangedAt(issue *jira.Issue) (time.Time, bool) {
+	if issue == nil || issue.Fields == nil || issue.Changelog == nil {
+		return time.Time{}, false
+	}
+
+	var changedAt time.Time
+	for _, history := range issue.Changelog.Histories {
+		for _, item := range history.Items {
+			if item.Field != "status" {
+				continue
+			}
+			created, err := history.CreatedTime()
+			if err == nil && created.After(changedAt) {
+				changedAt = created
+			}
+		}
+	}
+	if changedAt.IsZero() {
+		changedAt = time.Time(issue.Fields.Created)
+	}
+	return changedAt, !changedAt.IsZero()
+}
+
+// formatTimeInStatus renders a duration with its two largest units, e.g.
+// "3d 4h", "4h 12m" or "12m".
+func formatTimeInStatus(d time.Duration) string {
+	minutes := int(d.Minutes())
+	days, hours := minutes/(24*60), minutes/60%24
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes%60)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// mdTimeInStatus returns how long the issue has been in its current status,
+// e.g. "In Progress for 3d 4h", or "" if it cannot be told.
+func mdTimeInStatus(issue *jira.Issue, now time.Time) string {
+	changedAt, ok := statusChangedAt(issue)
+	if !ok || issue.Fields.Status == nil || changedAt.After(now) {
+		return ""
+	}
+	return fmt.Sprintf("%s for %s", issue.Fields.Status.Name, formatTimeInStatus(now.Sub(changedAt)))
+}
+
+// timeInStatusField returns the card field with the time in status of the
+// issue, or nil if it cannot be told.
+func timeInStatusField(issue *jira.Issue, now time.Time) *model.SlackAttachmentField {
+	value := mdTimeInStatus(issue, now)
+	if value == "" {
+		return nil
+	}
+	return &model.SlackAttachmentField{
+		Title: cardLabelTimeInStatus,
+		Value: value,
+		Short: true,
+	}
+}
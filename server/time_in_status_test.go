@@ -0,0 +1,80 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func statusHistory(created string, field string) jira.ChangelogHistory {
+	return jira.ChangelogHistory{Created: created, Items: []jira.ChangelogItems{{Field: field}}}
+}
+
+func TestTimeInStatus(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	created := jira.Time(time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC))
+
+	for name, tc := range map[string]struct {
+		changelog *jira.Changelog
+		expected  string
+	}{
+		"no changelog": {},
+		"never changed status": {
+			changelog: &jira.Changelog{Histories: []jira.ChangelogHistory{
+				statusHistory("2024-03-05T10:00:00.000+0000", "assignee"),
+			}},
+			expected: "In Progress for 9d 2h",
+		},
+		"latest status change": {
+			changelog: &jira.Changelog{Histories: []jira.ChangelogHistory{
+				statusHistory("2024-03-07T08:00:00.000+0000", "status"),
+				statusHistory("2024-03-02T08:00:00.000+0000", "status"),
+				statusHistory("2024-03-10T09:00:00.000+0000", "assignee"),
+			}},
+			expected: "In Progress for 3d 4h",
+		},
+		"in another timezone": {
+			changelog: &jira.Changelog{Histories: []jira.ChangelogHistory{
+				statusHistory("2024-03-10T10:15:00.000+0200", "status"),
+			}},
+			expected: "In Progress for 3h 45m",
+		},
+		"minutes": {
+			changelog: &jira.Changelog{Histories: []jira.ChangelogHistory{
+				statusHistory("2024-03-10T11:48:00.000+0000", "status"),
+			}},
+			expected: "In Progress for 12m",
+		},
+		"change in the future": {
+			changelog: &jira.Changelog{Histories: []jira.ChangelogHistory{
+				statusHistory("2024-03-10T13:00:00.000+0000", "status"),
+			}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			issue := &jira.Issue{
+				Fields: &jira.IssueFields{
+					Created: created,
+					Status:  &jira.Status{Name: "In Progress"},
+				},
+				Changelog: tc.changelog,
+			}
+			assert.Equal(t, tc.expected, mdTimeInStatus(issue, now))
+
+			field := timeInStatusField(issue, now)
+			if tc.expected == "" {
+				assert.Nil(t, field)
+				return
+			}
+			require.NotNil(t, field)
+			assert.Equal(t, cardLabelTimeInStatus, field.Title)
+			assert.Equal(t, tc.expected, field.Value)
+		})
+	}
+}
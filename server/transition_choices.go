@@ -0,0 +1,135 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+const (
+	prefixTransitionChoices = "transition_choices_"
+
+	// transitionChoicesExpiry is how long the numbered transitions shown to
+	// a user can be picked by their number.
+	transitionChoicesExpiry = 10 * time.Minute
+)
+
+// transitionChoice is a transition of the numbered list shown to the user.
+type transitionChoice struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	To   string `json:"to"`
+}
+
+func transitionChoicesKey(instanceID, mattermostUserID types.ID, issueKey string) string {
+	return hashkey(prefixTransitionChoices, instanceID.String()+"/"+strings.ToUpper(issueKey)+"/"+mattermostUserID.String())
+}
+
+// parseTransitionNumber returns the number of a transition of the list, given
+// as `#2` or `2`.
+func parseTransitionNumber(s string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(s, "#"))
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return n, true
+}
+
+// getIssueTransitions returns the transitions that the user can apply to the
+// issue.
+func getIssueTransitions(client Client, issueKey string) ([]jira.Transition, error) {
+	transitions, err := client.GetTransitions(issueKey)
+	if err != nil {
+		return nil, errors.New("we couldn't find the issue key. Please confirm the issue key and try again. You may not have permissions to access this issue")
+	}
+	if len(transitions) < 1 {
+		return nil, errors.New("you do not have the appropriate permissions to perform this action. Please contact your Jira administrator")
+	}
+	return transitions, nil
+}
+
+// mdTransitionChoices renders the numbered list of the transitions of the
+// issue.
+func mdTransitionChoices(instance Instance, issueKey string, choices []transitionChoice) string {
+	msg := fmt.Sprintf("Transitions of [%s](%s/browse/%s), use `/jira transition %s #N` to apply one:\n",
+		issueKey, instance.GetJiraBaseURL(), issueKey, issueKey)
+	for i, choice := range choices {
+		line := fmt.Sprintf("%d. `%s`", i+1, choice.To)
+		if choice.Name != "" && !strings.EqualFold(choice.Name, choice.To) {
+			line = fmt.Sprintf("%d. %s → `%s`", i+1, choice.Name, choice.To)
+		}
+		msg += line + "\n"
+	}
+	return strings.TrimSuffix(msg, "\n")
+}
+
+// showTransitionChoices lists the transitions of the issue, numbered, and
+// remembers the list for a little while so that a number picks the
+// transition that the user saw.
+func (p *Plugin) showTransitionChoices(in *InTransitionIssue, instance Instance, transitions []jira.Transition) (string, error) {
+	choices := []transitionChoice{}
+	for _, t := range transitions {
+		choices = append(choices, transitionChoice{ID: t.ID, Name: t.Name, To: t.To.Name})
+	}
+	_, err := p.client.KV.Set(transitionChoicesKey(instance.GetID(), in.mattermostUserID, in.IssueKey), choices, pluginapi.SetExpiry(transitionChoicesExpiry))
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to store the transitions")
+	}
+	return mdTransitionChoices(instance, in.IssueKey, choices), nil
+}
+
+// ListTransitions shows the user the numbered transitions of the issue.
+func (p *Plugin) ListTransitions(in *InTransitionIssue) (string, error) {
+	client, instance, _, err := p.getClient(in.InstanceID, in.mattermostUserID)
+	if err != nil {
+		return "", err
+	}
+	transitions, err := getIssueTransitions(client, in.IssueKey)
+	if err != nil {
+		return "", err
+	}
+	return p.showTransitionChoices(in, instance, transitions)
+}
+
+// TransitionIssueByNumber applies the transition of the list last shown to
+// the user. When the list expired, or the transition is no longer available,
+// the current list is shown again instead.
+func (p *Plugin) TransitionIssueByNumber(in *InTransitionIssue, number int) (string, error) {
+	client, instance, _, err := p.getClient(in.InstanceID, in.mattermostUserID)
+	if err != nil {
+		return "", err
+	}
+	transitions, err := getIssueTransitions(client, in.IssueKey)
+	if err != nil {
+		return "", err
+	}
+
+	choices := []transitionChoice{}
+	if err = p.client.KV.Get(transitionChoicesKey(instance.GetID(), in.mattermostUserID, in.IssueKey), &choices); err != nil {
+		return "", errors.WithMessage(err, "failed to load the transitions")
+	}
+	if number <= len(choices) {
+		choice := choices[number-1]
+		for _, t := range transitions {
+			if t.ID == choice.ID && t.To.Name == choice.To {
+				return p.doTransition(in, client, instance, t)
+			}
+		}
+	}
+
+	msg, err := p.showTransitionChoices(in, instance, transitions)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Transition #%d is no longer available, the transitions may have changed since they were listed.\n%s", number, msg), nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTransitionNumber(t *testing.T) {
+	for s, expected := range map[string]int{"#2": 2, "3": 3, "#0": 0, "0": 0, "-1": 0, "#": 0, "Done": 0, "#2a": 0} {
+		n, ok := parseTransitionNumber(s)
+		assert.Equal(t, expected != 0, ok, s)
+		assert.Equal(t, expected, n, s)
+	}
+}
+
+func TestTransitionChoices(t *testing.T) {
+	key := transitionChoicesKey(testInstance1.InstanceID, "connected_user", existingIssueKey)
+	listed := fmt.Sprintf("Transitions of [%s](%s/browse/%s), use `/jira transition %s #N` to apply one:\n1. `To Do`\n2. `In Progress`\n3. `In Testing`",
+		existingIssueKey, mockInstance1URL, existingIssueKey, existingIssueKey)
+
+	setup := func(stored []transitionChoice) (*Plugin, *plugintest.API) {
+		api := &plugintest.API{}
+		api.On("SendEphemeralPost", mock.AnythingOfType("string"), mock.AnythingOfType("*model.Post")).Return(&model.Post{})
+		api.On("KVSetWithOptions", mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(true, nil)
+		api.On("KVGet", keyWithInstanceID(testInstance1.InstanceID, JiraSubscriptionsKey)).Return(nil, nil)
+		var data []byte
+		if stored != nil {
+			var err error
+			data, err = json.Marshal(stored)
+			require.NoError(t, err)
+		}
+		api.On("KVGet", key).Return(data, nil)
+		p := &Plugin{}
+		p.SetAPI(api)
+		p.client = pluginapi.NewClient(api, p.Driver)
+		p.userStore = getMockUserStoreKV()
+		p.instanceStore = p.getMockInstanceStoreKV(1)
+		return p, api
+	}
+	in := func() *InTransitionIssue {
+		return &InTransitionIssue{
+			InstanceID:       testInstance1.InstanceID,
+			mattermostUserID: "connected_user",
+			IssueKey:         existingIssueKey,
+		}
+	}
+
+	t.Run("list", func(t *testing.T) {
+		p, api := setup(nil)
+		msg, err := p.ListTransitions(in())
+		require.NoError(t, err)
+		assert.Equal(t, listed, msg)
+		api.AssertCalled(t, "KVSetWithOptions", key, mock.Anything, model.PluginKVSetOptions{ExpireInSeconds: 10 * 60})
+	})
+
+	t.Run("the transition of the list", func(t *testing.T) {
+		p, _ := setup([]transitionChoice{{To: "To Do"}, {To: "In Progress"}})
+		msg, err := p.TransitionIssueByNumber(in(), 2)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("[%s](%s/browse/%s) transitioned to `In Progress`", existingIssueKey, mockInstance1URL, existingIssueKey), msg)
+	})
+
+	for name, stored := range map[string][]transitionChoice{
+		"the list expired":        nil,
+		"the number is not shown": {{To: "To Do"}},
+		"the list changed":        {{To: "To Do"}, {To: "Done"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p, _ := setup(stored)
+			msg, err := p.TransitionIssueByNumber(in(), 2)
+			require.NoError(t, err)
+			assert.Equal(t, "Transition #2 is no longer available, the transitions may have changed since they were listed.\n"+listed, msg)
+		})
+	}
+}
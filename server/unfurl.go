@@ -0,0 +1,75 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The fields the cards of Jira links can show, under the summary.
+const (
+	unfurlFieldStatus   = "status"
+	unfurlFieldAssignee = "assignee"
+	unfurlFieldPriority = "priority"
+	unfurlFieldType     = "type"
+	unfurlFieldReporter = "reporter"
+	unfurlFieldCreated  = "created"
+)
+
+var unfurlAllowedFields = []string{
+	unfurlFieldStatus,
+	unfurlFieldAssignee,
+	unfurlFieldPriority,
+	unfurlFieldType,
+	unfurlFieldReporter,
+	unfurlFieldCreated,
+}
+
+// defaultUnfurlFields are the fields of the cards of Jira links when the
+// UnfurlFields setting is empty.
+var defaultUnfurlFields = []string{unfurlFieldStatus, unfurlFieldAssignee}
+
+const defaultUnfurlSummaryMaxLength = 80
+
+// parseUnfurlFields parses the UnfurlFields setting, e.g. "type, status,
+// assignee", into the fields of the cards of Jira links, in order.
+func parseUnfurlFields(setting string) ([]string, error) {
+	allowed := NewStringSet(unfurlAllowedFields...)
+	fields := []string{}
+	seen := StringSet{}
+	for _, field := range strings.Split(setting, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		if !allowed.ContainsAny(field) {
+			return nil, errors.Errorf("invalid link card field %q, it must be one of: %s", field, strings.Join(unfurlAllowedFields, ", "))
+		}
+		if seen.ContainsAny(field) {
+			continue
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return defaultUnfurlFields, nil
+	}
+	return fields, nil
+}
+
+// parseUnfurlSummaryMaxLength parses the UnfurlSummaryMaxLength setting.
+func parseUnfurlSummaryMaxLength(setting string) (int, error) {
+	setting = strings.TrimSpace(setting)
+	if setting == "" {
+		return defaultUnfurlSummaryMaxLength, nil
+	}
+	n, err := strconv.Atoi(setting)
+	if err != nil || n < 1 {
+		return 0, errors.Errorf("invalid link card summary length %q, it must be a positive number", setting)
+	}
+	return n, nil
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUnfurlFields(t *testing.T) {
+	fields, err := parseUnfurlFields(" Type, status ,assignee, type,created,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{unfurlFieldType, unfurlFieldStatus, unfurlFieldAssignee, unfurlFieldCreated}, fields)
+
+	fields, err = parseUnfurlFields("")
+	require.NoError(t, err)
+	assert.Equal(t, defaultUnfurlFields, fields)
+
+	_, err = parseUnfurlFields("status, labels")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"labels"`)
+}
+
+func TestParseUnfurlSummaryMaxLength(t *testing.T) {
+	n, err := parseUnfurlSummaryMaxLength(" 120 ")
+	require.NoError(t, err)
+	assert.Equal(t, 120, n)
+
+	n, err = parseUnfurlSummaryMaxLength("")
+	require.NoError(t, err)
+	assert.Equal(t, defaultUnfurlSummaryMaxLength, n)
+
+	for _, invalid := range []string{"0", "-5", "long"} {
+		_, err = parseUnfurlSummaryMaxLength(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
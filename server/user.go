@@ -25,6 +25,16 @@ type User struct {
 	MattermostUserID   types.ID   `json:"mattermost_user_id"`
 	ConnectedInstances *Instances `json:"connected_instances,omitempty"`
 	DefaultInstanceID  types.ID   `json:"default_instance_id,omitempty"`
+
+	// Settings are the settings of the user for all their connections,
+	// unless a connection overrides them.
+	Settings *UserSettings `json:"settings,omitempty"`
+}
+
+// UserSettings are the global settings of a user.
+type UserSettings struct {
+	Notifications bool `json:"notifications"`
+	AssignedOnly  bool `json:"assigned_only,omitempty"`
 }
 
 type Connection struct {
@@ -36,6 +46,10 @@ type Connection struct {
 	Settings           *ConnectionSettings
 	SavedFieldValues   *SavedFieldValues `json:"saved_field_values,omitempty"`
 	MattermostUserID   types.ID          `json:"mattermost_user_id"`
+
+	// LastReconnectReminder is when the user was last sent a reminder to
+	// reconnect a broken connection, in unix seconds.
+	LastReconnectReminder int64 `json:"last_reconnect_reminder,omitempty"`
 }
 
 type SavedFieldValues struct {
@@ -53,14 +67,206 @@ func (c *Connection) JiraAccountID() types.ID {
 
 type ConnectionSettings struct {
 	Notifications bool `json:"notifications"`
+
+	// IgnoreOwnActions suppresses DM notifications for events the user
+	// triggered themselves. It is on unless explicitly turned off.
+	IgnoreOwnActions *bool `json:"ignore_own_actions,omitempty"`
+
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+
+	// AssignedOnly limits the notifications to the issues assigned to the
+	// user.
+	AssignedOnly bool `json:"assigned_only,omitempty"`
+
+	// OverrideNotifications makes Notifications and AssignedOnly apply to
+	// the instance regardless of the global settings of the user.
+	OverrideNotifications bool `json:"override_notifications,omitempty"`
+
+	// CompactNotifications renders the DM notifications as a single line.
+	CompactNotifications bool `json:"compact_notifications,omitempty"`
+
+	// NotifyOnSubscriptionMatch sends a DM when a subscription of a channel
+	// the user is a member of posts about an issue assigned to, or reported
+	// by, the user.
+	NotifyOnSubscriptionMatch bool `json:"notify_on_subscription_match,omitempty"`
+
+	// MentionOnly limits the notifications to the events whose comment or
+	// description mentions the user.
+	MentionOnly bool `json:"mention_only,omitempty"`
+
+	// DailySummary is the time of the day, HH:MM in the user's timezone, at
+	// which the user is sent the list of their open issues.
+	DailySummary string `json:"daily_summary,omitempty"`
+
+	// CustomStatus sets the Mattermost custom status of the user from the
+	// status or the labels of the issues assigned to them, by their
+	// CustomStatuses and then by those of the admins.
+	CustomStatus   bool               `json:"custom_status,omitempty"`
+	CustomStatuses []customStatusRule `json:"custom_statuses,omitempty"`
+
+	// HideNotificationFooter leaves the footer of the admins out of the DM
+	// notifications.
+	HideNotificationFooter bool `json:"hide_notification_footer,omitempty"`
+
+	// NotifyPriorityThreshold is the name of the lowest priority of the
+	// issues notified to the user, or "" for all of them. The issues without
+	// a priority are notified unless SkipUnprioritized is set.
+	NotifyPriorityThreshold string `json:"notify_priority_threshold,omitempty"`
+	SkipUnprioritized       bool   `json:"skip_unprioritized,omitempty"`
+
+	// StatusEntryRules notify the user when an issue moves into a status.
+	StatusEntryRules []statusEntryRule `json:"status_entry_rules,omitempty"`
+}
+
+const (
+	notificationsOwn        = ""
+	notificationsInherited  = "inherited"
+	notificationsOverridden = "overridden"
+)
+
+// notifications resolves the notification settings of the connection against
+// the global settings of the user: an override for the instance wins, then
+// the global settings apply. Connections made before the user had global
+// settings keep their own until the user sets some. It returns whether the
+// notifications are on, whether for the assigned issues only, and where that
+// comes from.
+func (s *ConnectionSettings) notifications(global *UserSettings) (on, assignedOnly bool, source string) {
+	switch {
+	case global == nil && s == nil:
+		return false, false, notificationsOwn
+	case global == nil:
+		return s.Notifications, s.AssignedOnly, notificationsOwn
+	case s != nil && s.OverrideNotifications:
+		return s.Notifications, s.AssignedOnly, notificationsOverridden
+	default:
+		return global.Notifications, global.AssignedOnly, notificationsInherited
+	}
+}
+
+func notificationsString(on, assignedOnly bool) string {
+	switch {
+	case !on:
+		return "off"
+	case assignedOnly:
+		return "assigned issues only"
+	default:
+		return "on"
+	}
 }
 
 func (s *ConnectionSettings) String() string {
-	notifications := "off"
-	if s != nil && s.Notifications {
-		notifications = "on"
+	return s.stringWith(nil)
+}
+
+// settingField is a setting as shown to the user, its value being "" when
+// it is off.
+type settingField struct {
+	label string
+	value string
+}
+
+// fields lists all the settings, with the notifications resolved against the
+// global settings of the user.
+func (s *ConnectionSettings) fields(global *UserSettings) []settingField {
+	on, assignedOnly, source := s.notifications(global)
+	notifications := notificationsString(on, assignedOnly)
+	switch source {
+	case notificationsInherited:
+		notifications += " (inherited from your global settings)"
+	case notificationsOverridden:
+		notifications += " (overridden for this instance)"
+	}
+	ignoreOwnActions := "off"
+	if s.ShouldIgnoreOwnActions() {
+		ignoreOwnActions = "on"
+	}
+	if s == nil {
+		s = &ConnectionSettings{}
+	}
+	onIf := func(set bool) string {
+		if set {
+			return "on"
+		}
+		return ""
+	}
+
+	quietHours := ""
+	if s.QuietHours != nil {
+		quietHours = s.QuietHours.String()
+	}
+	notificationFooter := ""
+	if s.HideNotificationFooter {
+		notificationFooter = "off"
+	}
+	priorityThreshold := ""
+	if s.NotifyPriorityThreshold != "" {
+		unprioritized := "notified"
+		if s.SkipUnprioritized {
+			unprioritized = "skipped"
+		}
+		priorityThreshold = fmt.Sprintf("%s and above, issues without a priority %s", s.NotifyPriorityThreshold, unprioritized)
+	}
+	rules := []string{}
+	for _, rule := range s.StatusEntryRules {
+		rules = append(rules, rule.String())
+	}
+
+	return []settingField{
+		{"Notifications", notifications},
+		{"Ignore my own actions", ignoreOwnActions},
+		{"Quiet hours", quietHours},
+		{"Compact notifications", onIf(s.CompactNotifications)},
+		{"Notify me when subscriptions post about my issues", onIf(s.NotifyOnSubscriptionMatch)},
+		{"Only notify me when I'm mentioned", onIf(s.MentionOnly)},
+		{"Daily summary", s.DailySummary},
+		{"Custom status from Jira", onIf(s.CustomStatus)},
+		{"Notification footer", notificationFooter},
+		{"Notification priority threshold", priorityThreshold},
+		{"Notify me when an issue moves into", strings.Join(rules, "; ")},
+	}
+}
+
+// stringWith describes the settings, with the notifications resolved against
+// the global settings of the user. The settings that are off are left out,
+// except the notifications.
+func (s *ConnectionSettings) stringWith(global *UserSettings) string {
+	lines := []string{}
+	for _, field := range s.fields(global) {
+		if field.value != "" {
+			lines = append(lines, fmt.Sprintf("\t%s: %s", field.label, field.value))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *ConnectionSettings) ShouldIgnoreOwnActions() bool {
+	if s == nil || s.IgnoreOwnActions == nil {
+		return true
+	}
+	return *s.IgnoreOwnActions
+}
+
+// userSettings returns the global settings of the user, or nil if they have
+// none.
+func (p *Plugin) userSettings(mattermostUserID types.ID) *UserSettings {
+	user, err := p.userStore.LoadUser(mattermostUserID)
+	if err != nil {
+		return nil
+	}
+	return user.Settings
+}
+
+// defaultConnectionSettings returns the settings of a new connection, as
+// configured by the DefaultNotifications setting.
+func (p *Plugin) defaultConnectionSettings() *ConnectionSettings {
+	switch p.getConfig().DefaultNotifications {
+	case settingOff:
+		return &ConnectionSettings{}
+	case settingAssigned:
+		return &ConnectionSettings{Notifications: true, AssignedOnly: true}
+	default:
+		return &ConnectionSettings{Notifications: true}
 	}
-	return fmt.Sprintf("\tNotifications: %s", notifications)
 }
 
 func NewUser(mattermostUserID types.ID) *User {
@@ -136,6 +342,44 @@ func (p *Plugin) httpUserDisconnect(w http.ResponseWriter, r *http.Request) (int
 	return http.StatusOK, nil
 }
 
+// httpForceReconnectPostAction handles the Reconnect button of the response to
+// an already connected user running `/jira connect`: it disconnects the user,
+// and replies with the link to connect again.
+func (p *Plugin) httpForceReconnectPostAction(w http.ResponseWriter, r *http.Request) (int, error) {
+	var requestData model.PostActionIntegrationRequest
+	err := json.NewDecoder(r.Body).Decode(&requestData)
+	if err != nil {
+		return respondErr(w, http.StatusBadRequest,
+			errors.Wrap(err, "unmarshall the body"))
+	}
+
+	jiraBotID := p.getUserID()
+	channelID := requestData.ChannelId
+	// The user who clicked is the one Mattermost authenticated, the body can
+	// name anyone.
+	mattermostUserID := r.Header.Get("Mattermost-User-Id")
+	if mattermostUserID == "" || mattermostUserID != requestData.UserId {
+		return respondErr(w, http.StatusUnauthorized, errors.New("not authorized"))
+	}
+
+	instanceID, ok := requestData.Context["instance_id"].(string)
+	if !ok {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			"No instance id was found in context data"), w, http.StatusInternalServerError)
+	}
+
+	_, err = p.DisconnectUser(instanceID, types.ID(mattermostUserID))
+	if err != nil && errors.Cause(err) != kvstore.ErrNotFound {
+		return p.respondErrWithFeedback(mattermostUserID, makePost(jiraBotID, channelID,
+			fmt.Sprintf("Could not complete the **disconnection** request. Error: %v", err)), w, http.StatusInternalServerError)
+	}
+
+	return respondJSON(w, &model.PostActionIntegrationResponse{
+		EphemeralText: fmt.Sprintf("You have been disconnected from %s. [Click here to link your Jira account](%s%s) again.",
+			instanceID, p.GetPluginURL(), instancePath(routeUserConnect, types.ID(instanceID))),
+	})
+}
+
 // TODO succinctly document the difference between start and connect
 func (p *Plugin) httpUserStart(w http.ResponseWriter, r *http.Request, instanceID types.ID) (int, error) {
 	mattermostUserID := r.Header.Get("Mattermost-User-Id")
@@ -200,22 +444,43 @@ func (p *Plugin) UpdateUserDefaults(mattermostUserID, instanceID types.ID, saved
 
 func (p *Plugin) httpGetSettingsInfo(w http.ResponseWriter, r *http.Request) (int, error) {
 	conf := p.getConfig()
+	unfurlFields := conf.unfurlFields
+	if len(unfurlFields) == 0 {
+		unfurlFields = defaultUnfurlFields
+	}
+	unfurlSummaryMaxLength := conf.unfurlSummaryMaxLength
+	if unfurlSummaryMaxLength == 0 {
+		unfurlSummaryMaxLength = defaultUnfurlSummaryMaxLength
+	}
 	return respondJSON(w, struct {
-		UIEnabled                              bool `json:"ui_enabled"`
-		SecurityLevelEmptyForJiraSubscriptions bool `json:"security_level_empty_for_jira_subscriptions"`
+		UIEnabled                              bool     `json:"ui_enabled"`
+		SecurityLevelEmptyForJiraSubscriptions bool     `json:"security_level_empty_for_jira_subscriptions"`
+		UnfurlFields                           []string `json:"unfurl_fields"`
+		UnfurlSummaryMaxLength                 int      `json:"unfurl_summary_max_length"`
 	}{
 		UIEnabled:                              conf.EnableJiraUI,
 		SecurityLevelEmptyForJiraSubscriptions: conf.SecurityLevelEmptyForJiraSubscriptions,
+		UnfurlFields:                           unfurlFields,
+		UnfurlSummaryMaxLength:                 unfurlSummaryMaxLength,
 	})
 }
 
 func (p *Plugin) connectUser(instance Instance, mattermostUserID types.ID, connection *Connection) error {
+	return p.storeUserConnection(instance, mattermostUserID, connection, true)
+}
+
+// storeUserConnection connects the user to the instance, and welcomes them if
+// welcome is set and it is their first connection, not each time they
+// reconnect.
+func (p *Plugin) storeUserConnection(instance Instance, mattermostUserID types.ID, connection *Connection, welcome bool) error {
+	firstConnection := false
 	user, err := p.userStore.LoadUser(mattermostUserID)
 	if err != nil {
 		if errors.Cause(err) != kvstore.ErrNotFound {
 			return err
 		}
 		user = NewUser(mattermostUserID)
+		firstConnection = true
 	}
 	user.ConnectedInstances.Set(instance.Common())
 
@@ -239,11 +504,36 @@ func (p *Plugin) connectUser(instance Instance, mattermostUserID types.ID, conne
 		&model.WebsocketBroadcast{UserId: mattermostUserID.String()},
 	)
 
+	if err = p.updateConnectAllPost(mattermostUserID, info); err != nil {
+		p.client.Log.Warn("Failed to update the list of Jira accounts left to link", "error", err.Error())
+	}
+
+	if welcome && firstConnection {
+		if _, err = p.postBotDM(mattermostUserID, connectedWelcomeMessage(instance, user.Settings, connection), "", ""); err != nil {
+			p.client.Log.Warn("Failed to send the welcome message", "error", err.Error())
+		}
+	}
+
 	p.TrackUserEvent("userConnected", mattermostUserID.String(), nil)
 
 	return nil
 }
 
+// connectedWelcomeMessage tells a newly connected user which notifications
+// they get, and how to change that.
+func connectedWelcomeMessage(instance Instance, global *UserSettings, connection *Connection) string {
+	notifications := "You will not receive any Jira notifications."
+	if on, assignedOnly, _ := connection.Settings.notifications(global); on {
+		notifications = "You will receive Jira notifications, e.g. when you are assigned to an issue or mentioned in a comment."
+		if assignedOnly {
+			notifications = "You will receive Jira notifications about the issues assigned to you."
+		}
+	}
+	return fmt.Sprintf("Welcome! Your Mattermost account is now connected to your Jira account on %s. %s\n"+
+		"Use `/jira settings notifications [on|off|assigned]` to change that, and `/jira help` to discover what else you can do.",
+		instance.GetURL(), notifications)
+}
+
 func (p *Plugin) DisconnectUser(instanceURL string, mattermostUserID types.ID) (*Connection, error) {
 	user, instance, err := p.LoadUserInstance(mattermostUserID, instanceURL)
 	if err != nil {
@@ -295,6 +585,10 @@ func (p *Plugin) disconnectUser(instance Instance, user *User) (*Connection, err
 		return nil, err
 	}
 
+	if oauthInstance, ok := instance.(*cloudOAuthInstance); ok {
+		oauthInstance.revokeTokenInBackground(conn)
+	}
+
 	info, err := p.GetUserInfo(user.MattermostUserID, user)
 	if err != nil {
 		return nil, err
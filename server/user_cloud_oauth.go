@@ -72,7 +72,7 @@ func (p *Plugin) httpOAuth2Complete(w http.ResponseWriter, r *http.Request, inst
 	connection.User = *jiraUser
 
 	// Set default settings when the user connects for the first time
-	connection.Settings = &ConnectionSettings{Notifications: true}
+	connection.Settings = p.defaultConnectionSettings()
 	connection.MattermostUserID = types.ID(mattermostUserID)
 
 	if err := p.connectUser(instance, types.ID(mattermostUserID), connection); err != nil {
@@ -76,7 +76,11 @@ func (p *Plugin) httpOAuth1aComplete(w http.ResponseWriter, r *http.Request, ins
 
 	// Although we pass the oauthTmpCredentials as required here. The JIRA server does not appar to validate it.
 	// We perform the check above for reuse so this is irrelevant to the security from our end.
-	accessToken, accessSecret, err := si.getOAuth1Config().AccessToken(requestToken, oauthTmpCredentials.Secret, verifier)
+	oauth1Config, err := si.getOAuth1TokenConfig()
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	accessToken, accessSecret, err := oauth1Config.AccessToken(requestToken, oauthTmpCredentials.Secret, verifier)
 	if err != nil {
 		return http.StatusInternalServerError,
 			errors.WithMessage(err, "failed to obtain oauth1 access token")
@@ -100,7 +104,7 @@ func (p *Plugin) httpOAuth1aComplete(w http.ResponseWriter, r *http.Request, ins
 	connection.User = *juser
 
 	// Set default settings the first time a user connects
-	connection.Settings = &ConnectionSettings{Notifications: true}
+	connection.Settings = p.defaultConnectionSettings()
 
 	err = p.connectUser(instance, types.ID(mattermostUserID), connection)
 	if err != nil {
@@ -6,6 +6,7 @@ package main
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	jira "github.com/andygrunwald/go-jira"
@@ -23,11 +24,27 @@ func TestUserSettings_String(t *testing.T) {
 	}{
 		"notifications on": {
 			settings:       ConnectionSettings{Notifications: false},
-			expectedOutput: "\tNotifications: off",
+			expectedOutput: "\tNotifications: off\n\tIgnore my own actions: on",
 		},
 		"notifications off": {
 			settings:       ConnectionSettings{Notifications: true},
-			expectedOutput: "\tNotifications: on",
+			expectedOutput: "\tNotifications: on\n\tIgnore my own actions: on",
+		},
+		"ignore own actions off": {
+			settings:       ConnectionSettings{Notifications: true, IgnoreOwnActions: &[]bool{false}[0]},
+			expectedOutput: "\tNotifications: on\n\tIgnore my own actions: off",
+		},
+		"quiet hours": {
+			settings:       ConnectionSettings{Notifications: true, QuietHours: &QuietHours{Start: "22:00", End: "07:00", Queue: true}},
+			expectedOutput: "\tNotifications: on\n\tIgnore my own actions: on\n\tQuiet hours: 22:00-07:00, notifications are delivered afterwards",
+		},
+		"assigned issues only": {
+			settings:       ConnectionSettings{Notifications: true, AssignedOnly: true},
+			expectedOutput: "\tNotifications: assigned issues only\n\tIgnore my own actions: on",
+		},
+		"compact notifications": {
+			settings:       ConnectionSettings{Notifications: true, CompactNotifications: true},
+			expectedOutput: "\tNotifications: on\n\tIgnore my own actions: on\n\tCompact notifications: on",
 		},
 	}
 	for name, tt := range tests {
@@ -37,6 +54,92 @@ func TestUserSettings_String(t *testing.T) {
 	}
 }
 
+func TestConnectionSettings_Notifications(t *testing.T) {
+	global := &UserSettings{Notifications: true, AssignedOnly: true}
+	tests := map[string]struct {
+		settings       *ConnectionSettings
+		global         *UserSettings
+		on, assigned   bool
+		source         string
+		expectedOutput string
+	}{
+		"no settings at all": {
+			source:         notificationsOwn,
+			expectedOutput: "off",
+		},
+		"no global settings, the connection keeps its own": {
+			settings:       &ConnectionSettings{Notifications: true},
+			on:             true,
+			source:         notificationsOwn,
+			expectedOutput: "on",
+		},
+		"the global settings are inherited": {
+			settings:       &ConnectionSettings{},
+			global:         global,
+			on:             true,
+			assigned:       true,
+			source:         notificationsInherited,
+			expectedOutput: "assigned issues only (inherited from your global settings)",
+		},
+		"the global settings are inherited by connections without settings": {
+			global:         global,
+			on:             true,
+			assigned:       true,
+			source:         notificationsInherited,
+			expectedOutput: "assigned issues only (inherited from your global settings)",
+		},
+		"the instance overrides the global settings": {
+			settings:       &ConnectionSettings{OverrideNotifications: true},
+			global:         global,
+			source:         notificationsOverridden,
+			expectedOutput: "off (overridden for this instance)",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			on, assigned, source := tt.settings.notifications(tt.global)
+			assert.Equal(t, tt.on, on)
+			assert.Equal(t, tt.assigned, assigned)
+			assert.Equal(t, tt.source, source)
+			assert.Equal(t, "\tNotifications: "+tt.expectedOutput+"\n\tIgnore my own actions: on", tt.settings.stringWith(tt.global))
+		})
+	}
+}
+
+func TestDefaultConnectionSettings(t *testing.T) {
+	for setting, expected := range map[string]*ConnectionSettings{
+		"":              {Notifications: true},
+		settingOn:       {Notifications: true},
+		settingAssigned: {Notifications: true, AssignedOnly: true},
+		settingOff:      {},
+	} {
+		p := &Plugin{}
+		p.updateConfig(func(conf *config) {
+			conf.DefaultNotifications = setting
+		})
+		assert.Equal(t, expected, p.defaultConnectionSettings(), "setting %q", setting)
+	}
+}
+
+func TestIsAssignedTo(t *testing.T) {
+	connection := &Connection{User: jira.User{AccountID: "account1", Name: "user1"}}
+
+	jwh := &JiraWebhook{}
+	assert.False(t, jwh.isAssignedTo(connection))
+
+	jwh.Issue.Fields = &jira.IssueFields{}
+	assert.False(t, jwh.isAssignedTo(connection))
+
+	jwh.Issue.Fields.Assignee = &jira.User{AccountID: "account1"}
+	assert.True(t, jwh.isAssignedTo(connection))
+
+	jwh.Issue.Fields.Assignee = &jira.User{AccountID: "account2", Name: "user1"}
+	assert.False(t, jwh.isAssignedTo(connection))
+
+	jwh.Issue.Fields.Assignee = &jira.User{Name: "user1"}
+	assert.True(t, jwh.isAssignedTo(connection))
+}
+
 func TestRouteUserStart(t *testing.T) {
 	tests := map[string]struct {
 		userID     string
@@ -129,3 +232,24 @@ func TestGetJiraUserFromMentions(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPForceReconnectPostActionChecksTheUser(t *testing.T) {
+	p := &Plugin{}
+	p.userStore = getMockUserStoreKV()
+
+	for name, headerUserID := range map[string]string{
+		"no authenticated user":    "",
+		"another user in the body": "non_connected_user",
+	} {
+		t.Run(name, func(t *testing.T) {
+			body := `{"user_id":"connected_user","context":{"instance_id":"` + testInstance1.InstanceID.String() + `"}}`
+			request := httptest.NewRequest(http.MethodPost, routeAPIUserForceReconnect, strings.NewReader(body))
+			request.Header.Set("Mattermost-User-Id", headerUserID)
+			w := httptest.NewRecorder()
+
+			status, err := p.httpForceReconnectPostAction(w, request)
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusUnauthorized, status)
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -20,7 +21,13 @@ import (
 	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
 )
 
-func (p *Plugin) CreateBotDMPost(instanceID, mattermostUserID types.ID, message, postType string) (post *model.Post, returnErr error) {
+func (p *Plugin) CreateBotDMPost(instanceID, mattermostUserID types.ID, message, postType, postPriority string) (post *model.Post, returnErr error) {
+	return p.CreateBotIssueDMPost(instanceID, mattermostUserID, "", message, postType, postPriority)
+}
+
+// CreateBotIssueDMPost sends a notification about an issue, unless the user
+// snoozed it, with the button that snoozes it.
+func (p *Plugin) CreateBotIssueDMPost(instanceID, mattermostUserID types.ID, issueKey, message, postType, postPriority string) (post *model.Post, returnErr error) {
 	defer func() {
 		if returnErr != nil {
 			returnErr = errors.WithMessage(returnErr,
@@ -34,24 +41,52 @@ func (p *Plugin) CreateBotDMPost(instanceID, mattermostUserID types.ID, message,
 		// not connected to Jira, so no need to send a DM, and no need to report an error
 		return nil, nil
 	}
-	if c.Settings == nil || !c.Settings.Notifications {
+	return p.createBotIssueDMPostFor(instanceID, mattermostUserID, c, p.userSettings(mattermostUserID), issueKey, message, postType, postPriority)
+}
+
+// createBotIssueDMPostFor is CreateBotIssueDMPost for a connection and global
+// settings that are already loaded.
+func (p *Plugin) createBotIssueDMPostFor(instanceID, mattermostUserID types.ID, c *Connection, global *UserSettings, issueKey, message, postType, postPriority string) (*model.Post, error) {
+	if on, _, _ := c.Settings.notifications(global); !on {
+		return nil, nil
+	}
+	if issueKey != "" && p.isIssueSnoozed(instanceID, mattermostUserID, issueKey) {
 		return nil, nil
 	}
 
+	if p.inQuietHours(mattermostUserID, c.Settings, time.Now()) {
+		if c.Settings.QuietHours.Queue {
+			return nil, p.queueQuietHoursNotification(instanceID, mattermostUserID, issueKey, message, postType, postPriority)
+		}
+		return nil, nil
+	}
+
+	return p.postBotIssueDM(instanceID, mattermostUserID, issueKey, message, postType, postPriority)
+}
+
+func (p *Plugin) postBotDM(mattermostUserID types.ID, message, postType, postPriority string) (*model.Post, error) {
+	return p.postBotIssueDM("", mattermostUserID, "", message, postType, postPriority)
+}
+
+func (p *Plugin) postBotIssueDM(instanceID, mattermostUserID types.ID, issueKey, message, postType, postPriority string) (*model.Post, error) {
 	conf := p.getConfig()
 	channel, err := p.client.Channel.GetDirect(mattermostUserID.String(), conf.botUserID)
 	if err != nil {
 		return nil, err
 	}
 
-	post = &model.Post{
+	post := &model.Post{
 		UserId:    conf.botUserID,
 		ChannelId: channel.Id,
 		Message:   message,
 		Type:      postType,
 	}
+	setPostPriority(post, postPriority)
+	if issueKey != "" {
+		addSnoozeIssueAction(post, instanceID, issueKey)
+	}
 
-	err = p.client.Post.CreatePost(post)
+	err = p.createPost(post)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +114,7 @@ func (p *Plugin) CreateBotDMtoMMUserID(mattermostUserID, format string, args ...
 		Message:   fmt.Sprintf(format, args...),
 	}
 
-	err = p.client.Post.CreatePost(post)
+	err = p.createPost(post)
 	if err != nil {
 		return nil, err
 	}
@@ -185,17 +220,26 @@ func getS256PKCEParams() (*PKCEParams, error) {
 	}, nil
 }
 
-func (p *Plugin) SetAdminAPITokenRequestHeader(req *http.Request) error {
+// adminAPIToken returns the decrypted Admin API Token.
+func (p *Plugin) adminAPIToken() (string, error) {
 	encryptedAdminAPIToken := p.getConfig().AdminAPIToken
 	jsonBytes, err := decrypt([]byte(encryptedAdminAPIToken), []byte(p.getConfig().EncryptionKey))
 	if err != nil {
 		p.client.Log.Warn("Error decrypting admin API token", "error", err.Error())
-		return err
+		return "", err
 	}
 	var adminAPIToken string
 	err = json.Unmarshal(jsonBytes, &adminAPIToken)
 	if err != nil {
 		p.client.Log.Warn("Error unmarshalling admin API token", "error", err.Error())
+		return "", err
+	}
+	return adminAPIToken, nil
+}
+
+func (p *Plugin) SetAdminAPITokenRequestHeader(req *http.Request) error {
+	adminAPIToken, err := p.adminAPIToken()
+	if err != nil {
 		return err
 	}
 
@@ -72,6 +72,11 @@ type JiraStatus struct {
 // CheckJiraURL checks if `/status` endpoint of the Jira URL is accessible
 // and responding with the correct state which is "RUNNING"
 func CheckJiraURL(mattermostSiteURL, jiraURL string, requireHTTPS bool) (_ string, err error) {
+	return CheckJiraURLWithClient(http.DefaultClient, mattermostSiteURL, jiraURL, requireHTTPS)
+}
+
+// CheckJiraURLWithClient is CheckJiraURL, calling Jira with httpClient.
+func CheckJiraURLWithClient(httpClient *http.Client, mattermostSiteURL, jiraURL string, requireHTTPS bool) (_ string, err error) {
 	jiraURL, err = NormalizeJiraURL(jiraURL)
 	if err != nil {
 		return "", err
@@ -87,7 +92,7 @@ func CheckJiraURL(mattermostSiteURL, jiraURL string, requireHTTPS bool) (_ strin
 		}
 	}()
 
-	resp, err := http.Get(jiraURL + "/status")
+	resp, err := httpClient.Get(jiraURL + "/status")
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,105 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/mattermost/mattermost/server/public/pluginapi/experimental/command"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/kvstore"
+	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
+)
+
+// serverInfoTTL is how long the version of a Jira instance is remembered.
+// Upgrades are rare, and a stale version is only ever shown for an hour.
+const serverInfoTTL = time.Hour
+
+// jiraServerInfo is the part of the response of /serverInfo about the
+// version of the Jira product.
+type jiraServerInfo struct {
+	Version        string `json:"version"`
+	DeploymentType string `json:"deploymentType"`
+}
+
+// getJiraServerInfo returns the version of a Jira instance, from the cache or
+// else from Jira using the connection of the user.
+func (p *Plugin) getJiraServerInfo(instanceID, mattermostUserID types.ID) (jiraServerInfo, error) {
+	if info, ok := p.serverInfoCache.get(instanceID, ""); ok {
+		return info, nil
+	}
+
+	client, _, _, err := p.getClient(instanceID, mattermostUserID)
+	if err != nil {
+		return jiraServerInfo{}, err
+	}
+
+	info := jiraServerInfo{}
+	if err = client.RESTGet("2/serverInfo", nil, &info); err != nil {
+		return jiraServerInfo{}, errors.WithMessage(err, "failed to fetch the Jira server info")
+	}
+	p.serverInfoCache.set(instanceID, "", info, serverInfoTTL)
+	return info, nil
+}
+
+// mdJiraVersion describes the version of a Jira instance, e.g. "Jira Server
+// 9.12.2".
+func mdJiraVersion(instanceType InstanceType, info jiraServerInfo) string {
+	deployment := info.DeploymentType
+	if deployment == "" {
+		deployment = "Server"
+		if instanceType == CloudInstanceType || instanceType == CloudOAuthInstanceType {
+			deployment = "Cloud"
+		}
+	}
+	return strings.TrimSpace(fmt.Sprintf("Jira %s %s", deployment, info.Version))
+}
+
+func executeVersion(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	if len(args) != 0 {
+		return p.help(header)
+	}
+
+	text, err := command.BuildInfo(model.Manifest{
+		Id:      manifest.Id,
+		Version: manifest.Version,
+		Name:    manifest.Name,
+	})
+	if err != nil {
+		text = fmt.Sprintf("%s plugin version: %s", manifest.Name, manifest.Version)
+	}
+
+	instances, err := p.instanceStore.LoadInstances()
+	if err != nil && errors.Cause(err) != kvstore.ErrNotFound {
+		return p.responsef(header, "%s\n\nFailed to load the Jira instances: %v", text, err)
+	}
+	if instances.IsEmpty() {
+		return p.responsef(header, "%s\n\nNo Jira instances are installed.", text)
+	}
+
+	text += "\n\n###### Jira instances:\n"
+	for _, instanceID := range instances.IDs() {
+		ic := instances.Get(instanceID)
+		name := instanceID.String()
+		if ic.Alias != "" {
+			name = fmt.Sprintf("%s (%s)", ic.Alias, instanceID)
+		}
+
+		info, err := p.getJiraServerInfo(instanceID, types.ID(header.UserId))
+		switch {
+		case err == nil:
+			text += fmt.Sprintf("* %s: %s\n", name, mdJiraVersion(ic.Type, info))
+		case errors.Cause(err) == kvstore.ErrNotFound:
+			text += fmt.Sprintf("* %s: %s, connect to it to see its version\n", name, mdJiraVersion(ic.Type, jiraServerInfo{}))
+		default:
+			text += fmt.Sprintf("* %s: %s, failed to get its version: %v\n", name, mdJiraVersion(ic.Type, jiraServerInfo{}), err)
+		}
+	}
+	return p.responsef(header, strings.TrimSuffix(text, "\n"))
+}
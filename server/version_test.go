@@ -0,0 +1,28 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetJiraServerInfoCached(t *testing.T) {
+	p := &Plugin{}
+	p.serverInfoCache.set(testInstance1.InstanceID, "", jiraServerInfo{Version: "1001.0.0-SNAPSHOT", DeploymentType: "Cloud"}, serverInfoTTL)
+
+	// Served from the cache, without loading the instance or the connection.
+	info, err := p.getJiraServerInfo(testInstance1.InstanceID, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, "Cloud", info.DeploymentType)
+}
+
+func TestMdJiraVersion(t *testing.T) {
+	assert.Equal(t, "Jira Server 9.12.2", mdJiraVersion(ServerInstanceType, jiraServerInfo{Version: "9.12.2", DeploymentType: "Server"}))
+	assert.Equal(t, "Jira Cloud 1001.0.0-SNAPSHOT", mdJiraVersion(CloudOAuthInstanceType, jiraServerInfo{Version: "1001.0.0-SNAPSHOT", DeploymentType: "Cloud"}))
+	assert.Equal(t, "Jira Server", mdJiraVersion(ServerInstanceType, jiraServerInfo{}))
+	assert.Equal(t, "Jira Cloud", mdJiraVersion(CloudOAuthInstanceType, jiraServerInfo{}))
+}
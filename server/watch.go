@@ -0,0 +1,96 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+// updateIssueWatch makes the user of the connection watch the issue, or stop
+// watching it. It returns whether that changed, and the watchers of the issue
+// afterwards.
+func updateIssueWatch(client Client, connection *Connection, issueKey string, watch bool) (bool, *IssueWatchers, error) {
+	watchers, err := client.GetWatchers(issueKey)
+	if err != nil {
+		return false, nil, err
+	}
+	if watchers.IsWatching == watch {
+		return false, watchers, nil
+	}
+
+	if watch {
+		err = client.AddWatcher(issueKey, connection)
+	} else {
+		err = client.RemoveWatcher(issueKey, connection)
+	}
+	if err != nil {
+		return false, nil, err
+	}
+
+	watchers, err = client.GetWatchers(issueKey)
+	if err != nil {
+		return true, nil, nil
+	}
+	return true, watchers, nil
+}
+
+func mdWatchCount(watchers *IssueWatchers) string {
+	switch {
+	case watchers == nil:
+		return ""
+	case watchers.WatchCount == 1:
+		return " It has 1 watcher."
+	default:
+		return fmt.Sprintf(" It has %d watchers.", watchers.WatchCount)
+	}
+}
+
+func executeWatch(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	return p.executeUpdateIssueWatch(header, true, args)
+}
+
+func executeUnwatch(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	return p.executeUpdateIssueWatch(header, false, args)
+}
+
+func (p *Plugin) executeUpdateIssueWatch(header *model.CommandArgs, watch bool, args []string) *model.CommandResponse {
+	command := "unwatch"
+	if watch {
+		command = "watch"
+	}
+
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	if len(args) != 1 {
+		return p.responsef(header, "Please specify an issue key in the form `/jira %s <issue-key>`.", command)
+	}
+	issueKey := strings.ToUpper(args[0])
+
+	client, _, connection, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	changed, watchers, err := updateIssueWatch(client, connection, issueKey, watch)
+	if err != nil {
+		return p.responsef(header, "Failed to %s the issue. Error: %v.", command, err)
+	}
+
+	link := fmt.Sprintf("[%s](%s/browse/%s)", issueKey, instance.GetJiraBaseURL(), issueKey)
+	switch {
+	case watch && changed:
+		return p.responsef(header, "You are now watching %s.%s", link, mdWatchCount(watchers))
+	case watch:
+		return p.responsef(header, "You are already watching %s.%s", link, mdWatchCount(watchers))
+	case changed:
+		return p.responsef(header, "You are no longer watching %s.%s", link, mdWatchCount(watchers))
+	default:
+		return p.responsef(header, "You are not watching %s.%s", link, mdWatchCount(watchers))
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type watchTestClient struct {
+	testClient
+	watchers *IssueWatchers
+}
+
+func (client watchTestClient) GetWatchers(issueKey string) (*IssueWatchers, error) {
+	watchers := *client.watchers
+	return &watchers, nil
+}
+
+func (client watchTestClient) AddWatcher(issueKey string, connection *Connection) error {
+	client.watchers.IsWatching = true
+	client.watchers.WatchCount++
+	return nil
+}
+
+func (client watchTestClient) RemoveWatcher(issueKey string, connection *Connection) error {
+	client.watchers.IsWatching = false
+	client.watchers.WatchCount--
+	return nil
+}
+
+func TestUpdateIssueWatch(t *testing.T) {
+	client := watchTestClient{watchers: &IssueWatchers{WatchCount: 2}}
+	connection := &Connection{User: jira.User{AccountID: "account"}}
+
+	changed, watchers, err := updateIssueWatch(client, connection, "KT-1", true)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, &IssueWatchers{WatchCount: 3, IsWatching: true}, watchers)
+	assert.Equal(t, " It has 3 watchers.", mdWatchCount(watchers))
+
+	changed, watchers, err = updateIssueWatch(client, connection, "KT-1", true)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 3, watchers.WatchCount)
+
+	changed, watchers, err = updateIssueWatch(client, connection, "KT-1", false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, &IssueWatchers{WatchCount: 2}, watchers)
+
+	changed, _, err = updateIssueWatch(client, connection, "KT-1", false)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestWatcherRequests(t *testing.T) {
+	type request struct {
+		method, query, body string
+	}
+	var got request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue/KT-1/watchers" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		got = request{r.Method, r.URL.RawQuery, string(body)}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	jiraClient, err := jira.NewClient(ts.Client(), ts.URL)
+	require.NoError(t, err)
+	connection := &Connection{User: jira.User{AccountID: "5b10a2844c20165700ede21g", Name: "jdoe"}}
+
+	for name, tc := range map[string]struct {
+		client         Client
+		expectedAdd    request
+		expectedRemove request
+	}{
+		"cloud": {
+			client:         newCloudClient(jiraClient),
+			expectedAdd:    request{http.MethodPost, "", `"5b10a2844c20165700ede21g"` + "\n"},
+			expectedRemove: request{http.MethodDelete, "accountId=5b10a2844c20165700ede21g", ""},
+		},
+		"server": {
+			client:         newServerClient(jiraClient),
+			expectedAdd:    request{http.MethodPost, "", `"jdoe"` + "\n"},
+			expectedRemove: request{http.MethodDelete, "username=jdoe", ""},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, tc.client.AddWatcher("KT-1", connection))
+			assert.Equal(t, tc.expectedAdd, got)
+			require.NoError(t, tc.client.RemoveWatcher("KT-1", connection))
+			assert.Equal(t, tc.expectedRemove, got)
+		})
+	}
+}
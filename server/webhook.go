@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -27,7 +29,7 @@ const (
 
 type Webhook interface {
 	Events() StringSet
-	PostToChannel(p *Plugin, instanceID types.ID, channelID, fromUserID, subscriptionName string) (*model.Post, int, error)
+	PostToChannel(p *Plugin, instanceID types.ID, channelID, fromUserID, subscriptionName, renderStyle, titleLink string, linkTypes StringSet) (*model.Post, int, error)
 	PostNotifications(p *Plugin, instanceID types.ID) ([]*model.Post, int, error)
 }
 
@@ -46,6 +48,22 @@ type webhook struct {
 	fields        []*model.SlackAttachmentField
 	notifications []webhookUserNotification
 	fieldInfo     webhookField
+
+	// headlineLinks are the links to the issue, by title link, as of the
+	// headline. The headline links on the key and summary.
+	headlineLinks map[string]string
+
+	// breadcrumb locates the issue among its parent and epic, see
+	// cardBreadcrumb. It is set for each post.
+	breadcrumb string
+
+	// devInfo is the development information of the issue, see cardDevInfo.
+	// It is set for each channel.
+	devInfo string
+
+	// rootID is the thread the post replies to, see issueThreadRootID. It is
+	// set for each channel.
+	rootID string
 }
 
 type webhookUserNotification struct {
@@ -54,45 +72,104 @@ type webhookUserNotification struct {
 	message       string
 	postType      string
 	commentSelf   string
+
+	// statusEntry is set for the notifications of the status entry rules of
+	// the users, which apply to all the issues they can see.
+	statusEntry bool
 }
 
 func (wh *webhook) Events() StringSet {
 	return wh.eventTypes
 }
 
-func (wh webhook) PostToChannel(p *Plugin, instanceID types.ID, channelID, fromUserID, subscriptionName string) (*model.Post, int, error) {
+func (wh webhook) PostToChannel(p *Plugin, instanceID types.ID, channelID, fromUserID, subscriptionName, renderStyle, titleLink string, linkTypes StringSet) (*model.Post, int, error) {
 	if wh.headline == "" {
 		return nil, http.StatusBadRequest, errors.Errorf("unsupported webhook")
-	} else if p.getConfig().DisplaySubscriptionNameInNotifications && subscriptionName != "" {
-		wh.headline = fmt.Sprintf("%s\nSubscription: **%s**", wh.headline, subscriptionName)
+	}
+
+	language := p.channelLanguage(channelID)
+	headline := wh.headline
+	customTitleLink := titleLink != "" && titleLink != TitleLinkKeySummary
+	switch {
+	case renderStyle == RenderStyleTitle && customTitleLink:
+		headline = wh.mdTitleLink(titleLink)
+	case renderStyle == RenderStyleTitle:
+		headline = wh.mdKeySummaryLink()
+	case customTitleLink && wh.headlineLinks[titleLink] != "":
+		headline = strings.Replace(headline, wh.headlineLinks[TitleLinkKeySummary], wh.headlineLinks[titleLink], 1)
+	}
+	if wh.breadcrumb != "" && renderStyle != RenderStyleTitle {
+		headline += "\n" + wh.breadcrumb
+	}
+	if p.getConfig().DisplaySubscriptionNameInNotifications && subscriptionName != "" {
+		headline = fmt.Sprintf("%s\n%s: **%s**", headline, localizeCardLabel(language, cardLabelSubscription), subscriptionName)
 	}
 
 	post := &model.Post{
 		ChannelId: channelID,
 		UserId:    fromUserID,
+		RootId:    wh.rootID,
 	}
+	setPostPriority(post, p.postPriority(wh.JiraWebhook.issuePriority()))
+	setIssuePostProps(post, instanceID, wh.Issue.Key)
 
 	text := ""
 	if wh.text != "" && !p.getConfig().HideDecriptionComment {
 		text = p.replaceJiraAccountIds(instanceID, wh.text)
 	}
 
-	if text != "" || len(wh.fields) != 0 {
+	fields := wh.fields
+	if linksField := wh.mdIssueLinksField(linkTypes); linksField != nil {
+		fields = append(append([]*model.SlackAttachmentField{}, wh.fields...), linksField)
+	}
+	if devField := devInfoField(wh.devInfo); devField != nil {
+		fields = append(append([]*model.SlackAttachmentField{}, fields...), devField)
+	}
+	// The status of a transition just changed, there is no time to show.
+	if !wh.eventTypes.ContainsAny(eventUpdatedStatus) {
+		if statusField := timeInStatusField(&wh.Issue, time.Now()); statusField != nil {
+			fields = append(append([]*model.SlackAttachmentField{}, fields...), statusField)
+		}
+	}
+	// A channel has no timezone, the due dates are those of the server.
+	dueState, due := dueDateState(&wh.Issue, time.Now(), p.getConfig().dueSoonDays)
+	if dueField := dueDateField(dueState, due); dueField != nil {
+		fields = append(append([]*model.SlackAttachmentField{}, fields...), dueField)
+	}
+	fields = localizeCardFields(language, fields)
+
+	footer := ""
+	if renderStyle == RenderStyleFull && p.getConfig().NotificationFooterInCards {
+		footer = p.notificationFooter(instanceID, wh.JiraWebhook)
+	}
+
+	if renderStyle == RenderStyleFull && (text != "" || len(fields) != 0) {
+		if footer != "" {
+			text = strings.TrimSpace(text + "\n\n" + footer)
+		}
+		color := "#95b7d0"
+		if dueState == dueDateOverdue {
+			color = overdueColor
+		}
 		model.ParseSlackAttachment(post, []*model.SlackAttachment{
 			{
 				// TODO is this supposed to be themed?
-				Color:    "#95b7d0",
-				Fallback: wh.headline,
-				Pretext:  wh.headline,
+				Color:    color,
+				Fallback: headline,
+				Pretext:  headline,
 				Text:     text,
-				Fields:   wh.fields,
+				Fields:   fields,
+				Actions:  subscriptionCardActions(instanceID, wh.Issue.Key),
 			},
 		})
 	} else {
-		post.Message = wh.headline
+		post.Message = headline + mdHeadlineDueDateBadge(dueState, due)
+		if footer != "" {
+			post.Message += "\n" + footer
+		}
 	}
 
-	err := p.client.Post.CreatePost(post)
+	err := p.createPost(post)
 	if err != nil {
 		return nil, http.StatusInternalServerError, err
 	}
@@ -101,7 +178,7 @@ func (wh webhook) PostToChannel(p *Plugin, instanceID types.ID, channelID, fromU
 }
 
 func (wh *webhook) PostNotifications(p *Plugin, instanceID types.ID) ([]*model.Post, int, error) {
-	if len(wh.notifications) == 0 {
+	if _, _, transitioned := wh.JiraWebhook.statusTransition(); len(wh.notifications) == 0 && !transitioned {
 		return nil, http.StatusOK, nil
 	}
 
@@ -112,8 +189,12 @@ func (wh *webhook) PostNotifications(p *Plugin, instanceID types.ID) ([]*model.P
 		return nil, http.StatusOK, nil
 	}
 
+	notifications := append(append([]webhookUserNotification{}, wh.notifications...), p.statusEntryNotifications(instance.GetID(), wh)...)
 	posts := []*model.Post{}
-	for _, notification := range wh.notifications {
+	// The global settings of a user are loaded once for the event, even when
+	// they are notified more than once about it.
+	globalSettings := map[types.ID]*UserSettings{}
+	for _, notification := range notifications {
 		var mattermostUserID types.ID
 		var err error
 
@@ -133,9 +214,28 @@ func (wh *webhook) PostNotifications(p *Plugin, instanceID types.ID) ([]*model.P
 			// Not connected to Jira, so can't check permissions
 			continue
 		}
+
+		if c.Settings.ShouldIgnoreOwnActions() && wh.JiraWebhook.isTriggeredBy(c) {
+			continue
+		}
+		global, ok := globalSettings[mattermostUserID]
+		if !ok {
+			global = p.userSettings(mattermostUserID)
+			globalSettings[mattermostUserID] = global
+		}
+		if _, assignedOnly, _ := c.Settings.notifications(global); assignedOnly && !notification.statusEntry && !wh.JiraWebhook.isAssignedTo(c) {
+			continue
+		}
+		if c.Settings != nil && c.Settings.MentionOnly && !notification.statusEntry && !wh.mentions(c) {
+			continue
+		}
 		client, err2 := instance.GetClient(c)
 		if err2 != nil {
 			p.errorf("PostNotifications: error while getting jiraClient, err: %v", err2)
+			p.metrics.countNotificationError()
+			continue
+		}
+		if !p.notifiesPriority(instance.GetID(), client, c.Settings, wh.JiraWebhook) {
 			continue
 		}
 		// If this is a comment-related webhook, we need to check if they have permissions to read that.
@@ -156,11 +256,17 @@ func (wh *webhook) PostNotifications(p *Plugin, instanceID types.ID) ([]*model.P
 			continue
 		}
 
+		if c.Settings != nil && c.Settings.CompactNotifications {
+			notification.message = wh.JiraWebhook.mdCompactNotification()
+		}
+		notification.message = p.withNotificationFooter(instance.GetID(), wh.JiraWebhook, c.Settings, notification.message)
 		notification.message = p.replaceJiraAccountIds(instance.GetID(), notification.message)
 
-		post, err := p.CreateBotDMPost(instance.GetID(), mattermostUserID, notification.message, notification.postType)
+		post, err := p.createBotIssueDMPostFor(instance.GetID(), mattermostUserID, c, global, wh.Issue.Key, notification.message, notification.postType,
+			p.postPriority(wh.JiraWebhook.issuePriority()))
 		if err != nil {
 			p.errorf("PostNotifications: failed to create notification post, err: %v", err)
+			p.metrics.countNotificationError()
 			continue
 		}
 		posts = append(posts, post)
@@ -176,22 +282,22 @@ func newWebhook(jwh *JiraWebhook, eventType string, format string, args ...inter
 	}
 }
 
-func (p *Plugin) GetWebhookURL(jiraURL string, teamID, channelID string) (subURL, legacyURL string, err error) {
+func (p *Plugin) GetWebhookURL(jiraURL string, teamID, channelID string) (subURL, legacyURL, automationURL string, err error) {
 	cf := p.getConfig()
 
 	instanceID, err := p.ResolveWebhookInstanceURL(jiraURL)
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 
 	team, err := p.client.Team.Get(teamID)
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 
 	channel, err := p.client.Channel.Get(channelID)
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 
 	v := url.Values{}
@@ -202,8 +308,9 @@ func (p *Plugin) GetWebhookURL(jiraURL string, teamID, channelID string) (subURL
 	v.Add("team", team.Name)
 	v.Add("channel", channel.Name)
 	legacyURL = p.GetPluginURL() + instancePath(routeIncomingWebhook, instanceID) + "?" + v.Encode()
+	automationURL = p.GetPluginURL() + instancePath(routeIncomingAutomationWebhook, instanceID) + "?" + v.Encode()
 
-	return subURL, legacyURL, nil
+	return subURL, legacyURL, automationURL, nil
 }
 
 func (p *Plugin) getSubscriptionsWebhookURL(instanceID types.ID) string {
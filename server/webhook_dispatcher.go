@@ -0,0 +1,187 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	webhookBackoffMin = 500 * time.Millisecond
+	webhookBackoffMax = 30 * time.Second
+)
+
+// webhookDispatcher delivers the incoming webhook events with a fixed number
+// of workers, which bounds the number of events whose notifications are being
+// delivered at the same time. The workers take the events from a single
+// queue. An event taken while another event of the same issue is being
+// delivered waits for it, so that the events of an issue are delivered in the
+// order they were received.
+type webhookDispatcher struct {
+	queue  chan *webhookMessage
+	handle func(workerID int, msg *webhookMessage) error
+
+	lock sync.Mutex
+	// queued is the number of events received and not delivered yet, it
+	// is at most size.
+	queued int
+	size   int
+	// busy holds, for each issue being delivered, the events of the issue
+	// waiting for it, in order.
+	busy map[string][]*webhookMessage
+}
+
+// newWebhookDispatcher starts the given number of workers. bufferSize is the
+// number of events that may wait to be delivered.
+func newWebhookDispatcher(workers, bufferSize int, handle func(workerID int, msg *webhookMessage) error) *webhookDispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+
+	d := &webhookDispatcher{
+		queue:  make(chan *webhookMessage, bufferSize),
+		handle: handle,
+		size:   bufferSize,
+		busy:   map[string][]*webhookMessage{},
+	}
+	for i := 0; i < workers; i++ {
+		go d.work(i)
+	}
+	return d
+}
+
+// Enqueue queues the event for delivery, and returns false if too many events
+// are waiting already.
+func (d *webhookDispatcher) Enqueue(msg *webhookMessage) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.queued >= d.size {
+		return false
+	}
+	d.queued++
+
+	// The channel has room for all the events counted in queued.
+	d.queue <- msg
+	return true
+}
+
+func webhookOrderingKey(msg *webhookMessage) string {
+	key := webhookIssueKey(msg.Data)
+	if key == "" {
+		return ""
+	}
+	return msg.InstanceID.String() + "/" + key
+}
+
+// claim reports whether the event may be delivered now. If another event of
+// its issue is being delivered, the event is queued behind it instead.
+func (d *webhookDispatcher) claim(key string, msg *webhookMessage) bool {
+	if key == "" {
+		return true
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if waiting, ok := d.busy[key]; ok {
+		d.busy[key] = append(waiting, msg)
+		return false
+	}
+	d.busy[key] = nil
+	return true
+}
+
+// done counts the event as delivered, and returns the next event of its
+// issue, if any.
+func (d *webhookDispatcher) done(key string) *webhookMessage {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.queued--
+	if key == "" {
+		return nil
+	}
+	waiting := d.busy[key]
+	if len(waiting) == 0 {
+		delete(d.busy, key)
+		return nil
+	}
+	d.busy[key] = waiting[1:]
+	return waiting[0]
+}
+
+// work delivers the events one at a time. When a delivery is throttled, the
+// worker waits before delivering the next event, doubling the wait while the
+// throttling continues.
+func (d *webhookDispatcher) work(id int) {
+	backoff := time.Duration(0)
+	for msg := range d.queue {
+		key := webhookOrderingKey(msg)
+		if !d.claim(key, msg) {
+			continue
+		}
+		for ; msg != nil; msg = d.done(key) {
+			err := d.handle(id, msg)
+			if !isThrottlingError(err) {
+				backoff = 0
+				continue
+			}
+
+			backoff = nextWebhookBackoff(backoff)
+			time.Sleep(backoff)
+		}
+	}
+}
+
+func nextWebhookBackoff(prev time.Duration) time.Duration {
+	next := prev * 2
+	if next < webhookBackoffMin {
+		return webhookBackoffMin
+	}
+	if next > webhookBackoffMax {
+		return webhookBackoffMax
+	}
+	return next
+}
+
+// webhookIssueKey extracts the ID, or else the key, of the issue a webhook
+// event is about, without parsing the whole event.
+func webhookIssueKey(data []byte) string {
+	event := struct {
+		Issue struct {
+			ID  string `json:"id"`
+			Key string `json:"key"`
+		} `json:"issue"`
+	}{}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return ""
+	}
+	if event.Issue.ID != "" {
+		return event.Issue.ID
+	}
+	return event.Issue.Key
+}
+
+// isThrottlingError reports whether Jira or Mattermost asked us to slow down.
+func isThrottlingError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	status := http.StatusInternalServerError
+	var coder StatusCoder
+	var appErr *model.AppError
+	if errors.As(err, &coder) {
+		status = coder.StatusCode()
+	} else if errors.As(err, &appErr) {
+		status = appErr.StatusCode
+	}
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testIssueWebhookMessage(issueID string, seq int) *webhookMessage {
+	return &webhookMessage{
+		InstanceID: "jiraurl1",
+		Data:       []byte(fmt.Sprintf(`{"issue":{"id":%q},"seq":%d}`, issueID, seq)),
+	}
+}
+
+func TestWebhookDispatcherBound(t *testing.T) {
+	const workers = 3
+	var running, maxRunning int32
+	started := make(chan struct{}, 30)
+	release := make(chan struct{})
+	done := sync.WaitGroup{}
+
+	d := newWebhookDispatcher(workers, 100, func(_ int, _ *webhookMessage) error {
+		defer done.Done()
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		started <- struct{}{}
+		<-release
+		atomic.AddInt32(&running, -1)
+		return nil
+	})
+
+	for i := 0; i < 30; i++ {
+		done.Add(1)
+		require.True(t, d.Enqueue(testIssueWebhookMessage(fmt.Sprintf("%d", 10000+i), 0)))
+	}
+
+	// All the workers are busy, the other events wait.
+	for i := 0; i < workers; i++ {
+		<-started
+	}
+	assert.Equal(t, int32(workers), atomic.LoadInt32(&maxRunning))
+	close(release)
+	done.Wait()
+	assert.Equal(t, int32(workers), atomic.LoadInt32(&maxRunning))
+}
+
+func TestWebhookDispatcherPerIssueOrdering(t *testing.T) {
+	const perIssue = 50
+	lock := sync.Mutex{}
+	received := map[string][]int{}
+	var decodeErrors []error
+	done := sync.WaitGroup{}
+
+	d := newWebhookDispatcher(4, 1000, func(_ int, msg *webhookMessage) error {
+		defer done.Done()
+		event := struct {
+			Issue struct {
+				ID string `json:"id"`
+			} `json:"issue"`
+			Seq int `json:"seq"`
+		}{}
+		err := json.Unmarshal(msg.Data, &event)
+
+		lock.Lock()
+		defer lock.Unlock()
+		if err != nil {
+			decodeErrors = append(decodeErrors, err)
+			return nil
+		}
+		received[event.Issue.ID] = append(received[event.Issue.ID], event.Seq)
+		return nil
+	})
+
+	issues := []string{"10001", "10002", "10003", "10004", "10005"}
+	for seq := 0; seq < perIssue; seq++ {
+		for _, issueID := range issues {
+			done.Add(1)
+			require.True(t, d.Enqueue(testIssueWebhookMessage(issueID, seq)))
+		}
+	}
+	done.Wait()
+
+	require.Empty(t, decodeErrors)
+	for _, issueID := range issues {
+		require.Len(t, received[issueID], perIssue)
+		for i, seq := range received[issueID] {
+			assert.Equal(t, i, seq, "events of issue %s were delivered out of order", issueID)
+		}
+	}
+}
+
+func TestWebhookDispatcherQueueFull(t *testing.T) {
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	defer close(release)
+	d := newWebhookDispatcher(1, 2, func(_ int, _ *webhookMessage) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+
+	// The first event is being delivered, the second waits for it.
+	require.True(t, d.Enqueue(testIssueWebhookMessage("10001", 0)))
+	<-started
+	require.True(t, d.Enqueue(testIssueWebhookMessage("10001", 1)))
+	assert.False(t, d.Enqueue(testIssueWebhookMessage("10001", 2)))
+}
+
+func TestWebhookDispatcherSharedQueue(t *testing.T) {
+	started := make(chan string, 10)
+	release := make(chan struct{})
+	defer close(release)
+	d := newWebhookDispatcher(2, 3, func(_ int, msg *webhookMessage) error {
+		issueID := webhookIssueKey(msg.Data)
+		started <- issueID
+		if issueID == "10001" {
+			<-release
+		}
+		return nil
+	})
+
+	// While a worker is stuck on an issue, the other one delivers the
+	// events of the other issues.
+	require.True(t, d.Enqueue(testIssueWebhookMessage("10001", 0)))
+	assert.Equal(t, "10001", <-started)
+	for seq := 0; seq < 5; seq++ {
+		require.True(t, d.Enqueue(testIssueWebhookMessage("10002", seq)))
+		assert.Equal(t, "10002", <-started)
+	}
+}
+
+func TestWebhookIssueKey(t *testing.T) {
+	assert.Equal(t, "10001", webhookIssueKey([]byte(`{"issue":{"id":"10001","key":"TEST-1"}}`)))
+	assert.Equal(t, "TEST-1", webhookIssueKey([]byte(`{"issue":{"key":"TEST-1"}}`)))
+	assert.Equal(t, "", webhookIssueKey([]byte(`{"webhookEvent":"comment_created"}`)))
+	assert.Equal(t, "", webhookIssueKey([]byte(`not json`)))
+}
+
+func TestNextWebhookBackoff(t *testing.T) {
+	assert.Equal(t, webhookBackoffMin, nextWebhookBackoff(0))
+	assert.Equal(t, 2*webhookBackoffMin, nextWebhookBackoff(webhookBackoffMin))
+	assert.Equal(t, webhookBackoffMax, nextWebhookBackoff(webhookBackoffMax))
+}
+
+func TestIsThrottlingError(t *testing.T) {
+	assert.False(t, isThrottlingError(nil))
+	assert.False(t, isThrottlingError(errors.New("failed")))
+	assert.True(t, isThrottlingError(RESTError{errors.New("slow down"), http.StatusTooManyRequests}))
+	assert.True(t, isThrottlingError(errors.WithMessage(RESTError{errors.New("unavailable"), http.StatusServiceUnavailable}, "failed to get issue")))
+	assert.True(t, isThrottlingError(model.NewAppError("CreatePost", "api.post.create", nil, "", http.StatusTooManyRequests)))
+	assert.False(t, isThrottlingError(model.NewAppError("CreatePost", "api.post.create", nil, "", http.StatusBadRequest)))
+}
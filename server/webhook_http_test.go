@@ -41,8 +41,8 @@ func (wh testWebhookWrapper) Events() StringSet {
 	return wh.Webhook.Events()
 }
 
-func (wh *testWebhookWrapper) PostToChannel(p *Plugin, instanceID types.ID, channelID, fromUserID, subscriptionName string) (*model.Post, int, error) {
-	post, status, err := wh.Webhook.PostToChannel(p, "", channelID, fromUserID, subscriptionName)
+func (wh *testWebhookWrapper) PostToChannel(p *Plugin, instanceID types.ID, channelID, fromUserID, subscriptionName, renderStyle, titleLink string, linkTypes StringSet) (*model.Post, int, error) {
+	post, status, err := wh.Webhook.PostToChannel(p, "", channelID, fromUserID, subscriptionName, renderStyle, titleLink, linkTypes)
 	if post != nil {
 		wh.postedToChannel = post
 	}
@@ -702,3 +702,103 @@ func TestWebhookHTTP(t *testing.T) {
 		})
 	}
 }
+
+func TestPostToChannelRenderStyle(t *testing.T) {
+	bb, err := os.ReadFile(filepath.Join("testdata", "webhook-issue-created.json"))
+	require.NoError(t, err)
+
+	const fullHeadline = "Test User **created** story [TES-41: Unit test summary](https://some-instance-test.atlassian.net/browse/TES-41)"
+	const titleHeadline = "story [TES-41: Unit test summary](https://some-instance-test.atlassian.net/browse/TES-41)"
+
+	for name, tc := range map[string]struct {
+		RenderStyle             string
+		ExpectedSlackAttachment bool
+		ExpectedMessage         string
+	}{
+		"full": {
+			RenderStyle:             RenderStyleFull,
+			ExpectedSlackAttachment: true,
+		},
+		"compact": {
+			RenderStyle:     RenderStyleCompact,
+			ExpectedMessage: fullHeadline + "\nSubscription: **release**",
+		},
+		"title": {
+			RenderStyle:     RenderStyleTitle,
+			ExpectedMessage: titleHeadline + "\nSubscription: **release**",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(func(post *model.Post) *model.Post {
+				return post.Clone()
+			}, nil)
+
+			p := Plugin{}
+			p.updateConfig(func(conf *config) {
+				conf.DisplaySubscriptionNameInNotifications = true
+			})
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+			p.userStore = mockUserStore{}
+
+			wh, err := ParseWebhook(bb)
+			require.NoError(t, err)
+
+			post, status, err := wh.PostToChannel(&p, "", "thechannelid", "theuserid", "release", tc.RenderStyle, "", nil)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, status)
+
+			if !tc.ExpectedSlackAttachment {
+				assert.Equal(t, tc.ExpectedMessage, post.Message)
+				assert.Nil(t, post.Props["attachments"])
+				return
+			}
+
+			attachments := post.Props["attachments"].([]*model.SlackAttachment)
+			require.Len(t, attachments, 1)
+			assert.Equal(t, fullHeadline+"\nSubscription: **release**", attachments[0].Pretext)
+			assert.Equal(t, "Unit test description, not that long", attachments[0].Text)
+		})
+	}
+}
+
+func TestPostToChannelTitleLink(t *testing.T) {
+	bb, err := os.ReadFile(filepath.Join("testdata", "webhook-issue-created.json"))
+	require.NoError(t, err)
+
+	for titleLink, expected := range map[string]string{
+		"":                  "Test User **created** story [TES-41: Unit test summary](https://some-instance-test.atlassian.net/browse/TES-41)",
+		TitleLinkKeySummary: "Test User **created** story [TES-41: Unit test summary](https://some-instance-test.atlassian.net/browse/TES-41)",
+		TitleLinkKey:        "Test User **created** story [TES-41](https://some-instance-test.atlassian.net/browse/TES-41)",
+		TitleLinkSummary:    "Test User **created** story [Unit test summary](https://some-instance-test.atlassian.net/browse/TES-41)",
+	} {
+		t.Run(titleLink, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(func(post *model.Post) *model.Post {
+				return post.Clone()
+			}, nil)
+
+			p := Plugin{}
+			p.SetAPI(api)
+			p.client = pluginapi.NewClient(api, p.Driver)
+			p.userStore = mockUserStore{}
+
+			wh, err := ParseWebhook(bb)
+			require.NoError(t, err)
+
+			post, _, err := wh.PostToChannel(&p, "", "thechannelid", "theuserid", "", RenderStyleCompact, titleLink, nil)
+			require.NoError(t, err)
+			assert.Equal(t, expected, post.Message)
+		})
+	}
+
+	t.Run("the summary is escaped", func(t *testing.T) {
+		wh, err := ParseWebhook(bb)
+		require.NoError(t, err)
+		jwh := wh.(*webhook).JiraWebhook
+		jwh.Issue.Fields.Summary = "Unit test [draft] summary"
+		assert.Equal(t, "story [Unit test \\[draft\\] summary](https://some-instance-test.atlassian.net/browse/TES-41)", jwh.mdTitleLink(TitleLinkSummary))
+		assert.Equal(t, "story [TES-41: Unit test \\[draft\\] summary](https://some-instance-test.atlassian.net/browse/TES-41)", jwh.mdTitleLink(TitleLinkKeySummary))
+	})
+}
@@ -99,7 +99,7 @@ func (jwh *JiraWebhook) expandIssue(p *Plugin, instanceID types.ID) error {
 				return err
 			}
 
-			issue, err := client.GetIssue(jwh.Issue.ID, nil)
+			issue, err := client.GetIssue(jwh.Issue.ID, &jira.GetQueryOptions{Expand: expandValueChangelog})
 			if err != nil {
 				return err
 			}
@@ -111,14 +111,48 @@ func (jwh *JiraWebhook) expandIssue(p *Plugin, instanceID types.ID) error {
 	return nil
 }
 
+// isTriggeredBy reports whether the Jira user of the connection is the one who
+// caused the event: the webhook user, or for comment events the comment's
+// (last) author.
+func (jwh *JiraWebhook) isTriggeredBy(connection *Connection) bool {
+	actors := []jira.User{jwh.User}
+	if jwh.Comment.UpdateAuthor.AccountID != "" || jwh.Comment.UpdateAuthor.Name != "" {
+		actors = append(actors, jwh.Comment.UpdateAuthor)
+	} else {
+		actors = append(actors, jwh.Comment.Author)
+	}
+
+	for _, actor := range actors {
+		if actor.AccountID != "" && actor.AccountID == connection.AccountID {
+			return true
+		}
+		if actor.Name != "" && actor.Name == connection.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// isAssignedTo reports whether the issue is assigned to the connected user.
+func (jwh *JiraWebhook) isAssignedTo(connection *Connection) bool {
+	if jwh.Issue.Fields == nil || jwh.Issue.Fields.Assignee == nil {
+		return false
+	}
+	assignee := jwh.Issue.Fields.Assignee
+	if assignee.AccountID != "" {
+		return assignee.AccountID == connection.AccountID
+	}
+	return assignee.Name != "" && assignee.Name == connection.Name
+}
+
 func (jwh *JiraWebhook) mdJiraLink(title, suffix string) string {
 	// Use Self URL only to extract the full hostname from it
-	pos := strings.LastIndex(jwh.Issue.Self, "/rest/api")
-	if pos < 0 {
+	jiraURL := jwh.jiraBaseURL()
+	if jiraURL == "" {
 		return ""
 	}
 	// TODO: For Jira OAuth, the Self URL is sent as https://api.atlassian.com/ instead of the Jira Instance URL - to check this and handle accordingly
-	return fmt.Sprintf("[%s](%s%s)", title, jwh.Issue.Self[:pos], suffix)
+	return fmt.Sprintf("[%s](%s%s)", title, jiraURL, suffix)
 }
 
 func (jwh *JiraWebhook) mdIssueDescription() string {
@@ -129,6 +163,13 @@ func (jwh *JiraWebhook) mdIssueSummary() string {
 	return truncate(jwh.Issue.Fields.Summary, 80)
 }
 
+func (jwh *JiraWebhook) issuePriority() string {
+	if jwh.Issue.Fields == nil || jwh.Issue.Fields.Priority == nil {
+		return ""
+	}
+	return jwh.Issue.Fields.Priority.Name
+}
+
 func (jwh *JiraWebhook) mdIssueAssignee() string {
 	if jwh.Issue.Fields.Assignee == nil {
 		return Nobody
@@ -150,6 +191,42 @@ func (jwh *JiraWebhook) mdKeyLink() string {
 	return jwh.mdIssueType() + " " + jwh.mdJiraLink(jwh.Issue.Key, "/browse/"+jwh.Issue.Key)
 }
 
+// mdTitleLink returns the link to the issue on the text chosen for the
+// headline of the cards, escaped so that a summary cannot break the link.
+func (jwh *JiraWebhook) mdTitleLink(titleLink string) string {
+	text := jwh.Issue.Key
+	switch titleLink {
+	case TitleLinkSummary:
+		text = escapeLinkText(jwh.mdIssueSummary())
+	case TitleLinkKeySummary:
+		text += ": " + escapeLinkText(jwh.mdIssueSummary())
+	}
+	return jwh.mdIssueType() + " " + jwh.mdJiraLink(text, "/browse/"+jwh.Issue.Key)
+}
+
+// escapeLinkText escapes the characters that would end the text of a markdown
+// link early.
+func escapeLinkText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(text)
+}
+
+// mdCompactNotification returns the single line DM notification about the
+// issue: `[KEY](link) Summary — Status (by Actor)`.
+func (jwh *JiraWebhook) mdCompactNotification() string {
+	key := jwh.mdJiraLink(jwh.Issue.Key, "/browse/"+jwh.Issue.Key)
+	if key == "" {
+		key = jwh.Issue.Key
+	}
+	msg := key + " " + jwh.mdIssueSummary()
+	if jwh.Issue.Fields.Status != nil && jwh.Issue.Fields.Status.Name != "" {
+		msg += " — " + jwh.Issue.Fields.Status.Name
+	}
+	if actor := jwh.mdUser(); actor != "" {
+		msg += fmt.Sprintf(" (by %s)", actor)
+	}
+	return msg
+}
+
 func (jwh *JiraWebhook) mdUser() string {
 	return mdUser(&jwh.User)
 }
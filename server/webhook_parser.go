@@ -91,6 +91,14 @@ func ParseWebhook(bb []byte) (wh Webhook, err error) {
 	if wh == nil {
 		return nil, errors.Wrapf(errWebhookeventUnsupported, "event: %v", jwh.WebhookEvent)
 	}
+	// The issue may be expanded before the headline is posted.
+	if w, ok := wh.(*webhook); ok {
+		w.headlineLinks = map[string]string{
+			TitleLinkKey:        jwh.mdTitleLink(TitleLinkKey),
+			TitleLinkSummary:    jwh.mdTitleLink(TitleLinkSummary),
+			TitleLinkKeySummary: jwh.mdKeySummaryLink(),
+		}
+	}
 
 	// For HTTP testing, so we can capture the output of the interface
 	if webhookWrapperFunc != nil {
@@ -200,14 +208,14 @@ func parseWebhookCreated(jwh *JiraWebhook) Webhook {
 	var fields []*model.SlackAttachmentField
 	if jwh.Issue.Fields.Assignee != nil {
 		fields = append(fields, &model.SlackAttachmentField{
-			Title: "Assignee",
+			Title: cardLabelAssignee,
 			Value: jwh.Issue.Fields.Assignee.DisplayName,
 			Short: true,
 		})
 	}
 	if jwh.Issue.Fields.Priority != nil {
 		fields = append(fields, &model.SlackAttachmentField{
-			Title: "Priority",
+			Title: cardLabelPriority,
 			Value: jwh.Issue.Fields.Priority.Name,
 			Short: true,
 		})
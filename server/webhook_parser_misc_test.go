@@ -79,6 +79,28 @@ func TestNotificationsFormat(t *testing.T) {
 	}
 }
 
+func TestCompactNotificationsFormat(t *testing.T) {
+	f, err := os.Open("testdata/webhook-server-issue-updated-commented-3.json")
+	require.NoError(t, err)
+	defer f.Close()
+	bb, err := io.ReadAll(f)
+	require.NoError(t, err)
+	wh, err := ParseWebhook(bb)
+	require.NoError(t, err)
+	w := wh.(*webhook)
+	require.NotEmpty(t, w.notifications)
+
+	compact := w.mdCompactNotification()
+	assert.Equal(t, "[PRJA-42](http://test-server.azure.com:8080/browse/PRJA-42) test for notifications — To Do (by Test User)", compact)
+	assert.NotContains(t, compact, "\n")
+	assert.NotEqual(t, w.notifications[0].message, compact)
+
+	w.Issue.Fields.Status = nil
+	w.User = jira.User{}
+	w.Issue.Self = ""
+	assert.Equal(t, w.Issue.Key+" "+w.mdIssueSummary(), w.mdCompactNotification())
+}
+
 func TestWebhookVariousErrors(t *testing.T) {
 	assert.Equal(t, "", mdUser(nil))
 
@@ -244,3 +266,52 @@ more text data in code block.{noformat}`,
 		})
 	}
 }
+
+func TestIsTriggeredBy(t *testing.T) {
+	cloudConnection := &Connection{User: jira.User{AccountID: "account-1"}}
+	serverConnection := &Connection{User: jira.User{Name: "jdoe"}}
+
+	for name, tc := range map[string]struct {
+		jwh        *JiraWebhook
+		connection *Connection
+		expected   bool
+	}{
+		"cloud user triggered the event": {
+			jwh:        &JiraWebhook{User: jira.User{AccountID: "account-1"}},
+			connection: cloudConnection,
+			expected:   true,
+		},
+		"server user triggered the event": {
+			jwh:        &JiraWebhook{User: jira.User{Name: "jdoe"}},
+			connection: serverConnection,
+			expected:   true,
+		},
+		"someone else triggered the event": {
+			jwh:        &JiraWebhook{User: jira.User{AccountID: "account-2", Name: "other"}},
+			connection: cloudConnection,
+			expected:   false,
+		},
+		"user wrote the comment": {
+			jwh:        &JiraWebhook{Comment: jira.Comment{Author: jira.User{AccountID: "account-1"}}},
+			connection: cloudConnection,
+			expected:   true,
+		},
+		"someone else edited the user's comment": {
+			jwh: &JiraWebhook{Comment: jira.Comment{
+				Author:       jira.User{AccountID: "account-1"},
+				UpdateAuthor: jira.User{AccountID: "account-2"},
+			}},
+			connection: cloudConnection,
+			expected:   false,
+		},
+		"empty actor does not match empty connection fields": {
+			jwh:        &JiraWebhook{},
+			connection: &Connection{},
+			expected:   false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.jwh.isTriggeredBy(tc.connection))
+		})
+	}
+}
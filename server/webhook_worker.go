@@ -4,33 +4,39 @@
 package main
 
 import (
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost-plugin-jira/server/utils/types"
 )
 
 type webhookWorker struct {
-	id        int
-	p         *Plugin
-	workQueue <-chan *webhookMessage
+	id int
+	p  *Plugin
 }
 
 type webhookMessage struct {
 	InstanceID types.ID
-	Data       []byte
+	// SubscriptionID is set for the events received by the webhook of a
+	// single subscription.
+	SubscriptionID string
+	Data           []byte
 }
 
-func (ww webhookWorker) work() {
-	for msg := range ww.workQueue {
-		err := ww.process(msg)
-		if err != nil {
-			if errors.Is(err, errWebhookeventUnsupported) {
-				ww.p.debugf("WebhookWorker id: %d, error processing, err: %v", ww.id, err)
-			} else {
-				ww.p.errorf("WebhookWorker id: %d, error processing, err: %v", ww.id, err)
-			}
+// handle processes a webhook event and logs any error, which it returns to the
+// dispatcher so that it can back off when throttled.
+func (ww webhookWorker) handle(msg *webhookMessage) error {
+	err := ww.process(msg)
+	if err != nil {
+		if errors.Is(err, errWebhookeventUnsupported) {
+			ww.p.debugf("WebhookWorker id: %d, error processing, err: %v", ww.id, err)
+		} else {
+			ww.p.errorf("WebhookWorker id: %d, error processing, err: %v", ww.id, err)
 		}
 	}
+	return err
 }
 
 func (ww webhookWorker) process(msg *webhookMessage) (err error) {
@@ -45,9 +51,14 @@ func (ww webhookWorker) process(msg *webhookMessage) (err error) {
 	if err != nil {
 		return err
 	}
+	ww.p.metrics.countWebhookEvent(wh.(*webhook).WebhookEvent)
 
-	if _, _, err = wh.PostNotifications(ww.p, msg.InstanceID); err != nil {
-		ww.p.errorf("WebhookWorker id: %d, error posting notifications, err: %v", ww.id, err)
+	// The user notifications are sent from the events of the subscriptions
+	// webhook only, not once more for each subscription webhook.
+	if msg.SubscriptionID == "" {
+		if _, _, err = wh.PostNotifications(ww.p, msg.InstanceID); err != nil {
+			ww.p.errorf("WebhookWorker id: %d, error posting notifications, err: %v", ww.id, err)
+		}
 	}
 
 	v := wh.(*webhook)
@@ -55,27 +66,134 @@ func (ww webhookWorker) process(msg *webhookMessage) (err error) {
 		return err
 	}
 
-	channelsSubscribed, err := ww.p.getChannelsSubscribed(v, msg.InstanceID)
+	channelsSubscribed, err := ww.p.getChannelsSubscribedVia(v, msg.InstanceID, msg.SubscriptionID)
 	if err != nil {
 		return err
 	}
+	channelsSubscribed = ww.p.skipAnnouncedIssues(msg.InstanceID, v, channelsSubscribed)
 
 	botUserID := ww.p.getUserID()
-	for _, channelSubscribed := range channelsSubscribed {
-		channel, err := ww.p.client.Channel.Get(channelSubscribed.ChannelID)
+	var throttled error
+	delivered := []*channelDelivery{}
+	channels := map[string]*model.Channel{}
+	for _, delivery := range groupChannelDeliveries(channelsSubscribed, !ww.p.getConfig().AllowDuplicateChannelNotifications) {
+		channel, err := ww.p.client.Channel.Get(delivery.ChannelID)
 		if err != nil {
-			ww.p.client.Log.Warn("Error occurred while getting the channel details while posting the webhook event", "ChannelID", channelSubscribed.ChannelID, "Error", err.Error())
+			ww.p.client.Log.Warn("Error occurred while getting the channel details while posting the webhook event", "ChannelID", delivery.ChannelID, "Error", err.Error())
 			return err
 		}
 
-		if channel.DeleteAt > 0 {
+		if channel.DeleteAt > 0 || ww.p.suppressChannelQuiet(channel.Id, time.Now()) {
 			continue
 		}
+		channels[channel.Id] = channel
 
-		if _, _, err1 := wh.PostToChannel(ww.p, msg.InstanceID, channelSubscribed.ChannelID, botUserID, channelSubscribed.Name); err1 != nil {
-			ww.p.errorf("WebhookWorker id: %d, error posting to channel, err: %v", ww.id, err1)
+		v.breadcrumb = ww.p.cardBreadcrumb(msg.InstanceID, delivery.Subscriptions[0], &v.Issue)
+		v.devInfo = ww.p.cardDevInfo(msg.InstanceID, delivery.ChannelID, delivery.Subscriptions[0], &v.Issue)
+		v.rootID = ww.p.issueThreadRootID(msg.InstanceID, v, delivery.ChannelID)
+		if _, _, err1 := wh.PostToChannel(ww.p, msg.InstanceID, delivery.ChannelID, botUserID, delivery.Name, delivery.RenderStyle, delivery.TitleLink, delivery.LinkTypes); err1 != nil {
+			ww.logDeliveryFailure(msg.InstanceID, delivery.Subscriptions[0], v, err1)
+			if isThrottlingError(err1) {
+				throttled = err1
+			}
+			continue
 		}
+		delivered = append(delivered, delivery)
+		ww.p.metrics.countWebhookDelivery()
+
+		for _, sub := range delivery.Subscriptions {
+			ww.p.recordSubscriptionEvent(msg.InstanceID, sub.ID)
+		}
+	}
+
+	v.rootID = ""
+	ww.p.recordAnnouncedIssues(msg.InstanceID, v, delivered)
+	ww.p.notifySubscriptionMatch(msg.InstanceID, v, delivered, channels)
+	if msg.SubscriptionID == "" {
+		v.breadcrumb = ""
+		ww.p.notifyMentionSinks(msg.InstanceID, v, delivered)
+		ww.p.updateAssigneeCustomStatus(msg.InstanceID, v)
+		ww.p.applyAutoTransitions(msg.InstanceID, v)
 	}
 
-	return nil
+	return throttled
+}
+
+// channelDelivery is a post of an event to a channel, on behalf of the
+// subscriptions of the channel that match the event.
+type channelDelivery struct {
+	ChannelID     string
+	Name          string
+	RenderStyle   string
+	TitleLink     string
+	LinkTypes     StringSet
+	Subscriptions []ChannelSubscription
+}
+
+// renderStyleRank orders the render styles from the least to the most
+// detailed.
+var renderStyleRank = map[string]int{
+	RenderStyleTitle:   1,
+	RenderStyleCompact: 2,
+	RenderStyleFull:    3,
+}
+
+// groupChannelDeliveries returns the posts of an event to the subscribed
+// channels, in the order of the subscriptions. With dedupe, the subscriptions
+// of a channel share a single post, named after all of them and in the most
+// detailed of their styles, listing the issue links any of them allows. The
+// title link is the one of the first subscription.
+func groupChannelDeliveries(subs []ChannelSubscription, dedupe bool) []*channelDelivery {
+	deliveries := []*channelDelivery{}
+	byChannel := map[string]*channelDelivery{}
+	for _, sub := range subs {
+		if delivery := byChannel[sub.ChannelID]; dedupe && delivery != nil {
+			delivery.Subscriptions = append(delivery.Subscriptions, sub)
+			if sub.Name != "" {
+				if delivery.Name != "" {
+					delivery.Name += ", "
+				}
+				delivery.Name += sub.Name
+			}
+			if renderStyleRank[sub.GetRenderStyle()] > renderStyleRank[delivery.RenderStyle] {
+				delivery.RenderStyle = sub.GetRenderStyle()
+			}
+			delivery.LinkTypes = delivery.LinkTypes.Union(sub.LinkTypes)
+			continue
+		}
+
+		delivery := &channelDelivery{
+			ChannelID:     sub.ChannelID,
+			Name:          sub.Name,
+			RenderStyle:   sub.GetRenderStyle(),
+			TitleLink:     sub.GetTitleLink(),
+			LinkTypes:     sub.LinkTypes,
+			Subscriptions: []ChannelSubscription{sub},
+		}
+		byChannel[sub.ChannelID] = delivery
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries
+}
+
+// logDeliveryFailure logs, with enough context to trace it, that the event
+// could not be posted to a subscribed channel, and counts it.
+func (ww webhookWorker) logDeliveryFailure(instanceID types.ID, sub ChannelSubscription, wh *webhook, err error) {
+	ww.p.metrics.countWebhookDeliveryFailure()
+
+	keyValuePairs := []interface{}{
+		"WorkerID", ww.id,
+		"SubscriptionID", sub.ID,
+		"ChannelID", sub.ChannelID,
+		"InstanceID", instanceID.String(),
+		"IssueKey", wh.JiraWebhook.Issue.Key,
+		"WebhookEvent", wh.JiraWebhook.WebhookEvent,
+		"IssueEventTypeName", wh.JiraWebhook.IssueEventTypeName,
+		"Error", err.Error(),
+	}
+	if isThrottlingError(err) {
+		ww.p.client.Log.Warn("Throttled while posting a Jira event to a subscribed channel", keyValuePairs...)
+		return
+	}
+	ww.p.client.Log.Error("Failed to post a Jira event to a subscribed channel", keyValuePairs...)
 }
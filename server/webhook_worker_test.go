@@ -0,0 +1,119 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookWorkerLogDeliveryFailure(t *testing.T) {
+	sub := ChannelSubscription{ID: "sub1", ChannelID: "channel1"}
+	wh := &webhook{JiraWebhook: &JiraWebhook{
+		WebhookEvent:       "jira:issue_updated",
+		IssueEventTypeName: "issue_generic",
+		Issue:              jira.Issue{Key: "TEST-1"},
+	}}
+	fields := func(err string) []interface{} {
+		return []interface{}{
+			"WorkerID", 3,
+			"SubscriptionID", "sub1",
+			"ChannelID", "channel1",
+			"InstanceID", "jiraurl1",
+			"IssueKey", "TEST-1",
+			"WebhookEvent", "jira:issue_updated",
+			"IssueEventTypeName", "issue_generic",
+			"Error", err,
+		}
+	}
+
+	t.Run("failure is logged as an error", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogError", append([]interface{}{"Failed to post a Jira event to a subscribed channel"}, fields("boom")...)...).Return()
+		p := &Plugin{}
+		p.SetAPI(api)
+		p.client = pluginapi.NewClient(api, p.Driver)
+
+		webhookWorker{3, p}.logDeliveryFailure("jiraurl1", sub, wh, errors.New("boom"))
+		api.AssertExpectations(t)
+		assert.Equal(t, int64(1), p.metrics.snapshot().WebhookDeliveryFailures)
+	})
+
+	t.Run("throttling is logged as a warning", func(t *testing.T) {
+		appErr := model.NewAppError("CreatePost", "api.context.rate_limited", nil, "", http.StatusTooManyRequests)
+		api := &plugintest.API{}
+		api.On("LogWarn", append([]interface{}{"Throttled while posting a Jira event to a subscribed channel"}, fields(appErr.Error())...)...).Return()
+		p := &Plugin{}
+		p.SetAPI(api)
+		p.client = pluginapi.NewClient(api, p.Driver)
+
+		webhookWorker{3, p}.logDeliveryFailure("jiraurl1", sub, wh, appErr)
+		api.AssertExpectations(t)
+		assert.Equal(t, int64(1), p.metrics.snapshot().WebhookDeliveryFailures)
+	})
+}
+
+func TestGroupChannelDeliveries(t *testing.T) {
+	subs := []ChannelSubscription{
+		{ID: "sub1", ChannelID: "channel1", Name: "Bugs", RenderStyle: RenderStyleTitle},
+		{ID: "sub2", ChannelID: "channel2", Name: "All"},
+		{ID: "sub3", ChannelID: "channel1", Name: "Releases", RenderStyle: RenderStyleCompact},
+		{ID: "sub4", ChannelID: "channel1"},
+	}
+
+	t.Run("overlapping subscriptions post once per channel", func(t *testing.T) {
+		deliveries := groupChannelDeliveries(subs, true)
+		assert.Len(t, deliveries, 2)
+
+		assert.Equal(t, "channel1", deliveries[0].ChannelID)
+		assert.Equal(t, "Bugs, Releases", deliveries[0].Name)
+		assert.Equal(t, RenderStyleFull, deliveries[0].RenderStyle)
+		assert.Equal(t, []ChannelSubscription{subs[0], subs[2], subs[3]}, deliveries[0].Subscriptions)
+
+		assert.Equal(t, "channel2", deliveries[1].ChannelID)
+		assert.Equal(t, "All", deliveries[1].Name)
+		assert.Equal(t, []ChannelSubscription{subs[1]}, deliveries[1].Subscriptions)
+	})
+
+	t.Run("the most detailed style wins", func(t *testing.T) {
+		deliveries := groupChannelDeliveries(subs[:3], true)
+		assert.Equal(t, RenderStyleCompact, deliveries[0].RenderStyle)
+	})
+
+	t.Run("the title link of the first subscription", func(t *testing.T) {
+		deliveries := groupChannelDeliveries([]ChannelSubscription{
+			{ID: "sub1", ChannelID: "channel1", TitleLink: TitleLinkSummary},
+			{ID: "sub2", ChannelID: "channel1", TitleLink: TitleLinkKey},
+			{ID: "sub3", ChannelID: "channel2"},
+		}, true)
+		assert.Equal(t, TitleLinkSummary, deliveries[0].TitleLink)
+		assert.Equal(t, TitleLinkKeySummary, deliveries[1].TitleLink)
+	})
+
+	t.Run("the issue links any subscription allows", func(t *testing.T) {
+		deliveries := groupChannelDeliveries([]ChannelSubscription{
+			{ID: "sub1", ChannelID: "channel1", LinkTypes: NewStringSet("blocks")},
+			{ID: "sub2", ChannelID: "channel1"},
+			{ID: "sub3", ChannelID: "channel1", LinkTypes: NewStringSet("is blocked by")},
+		}, true)
+		assert.Equal(t, NewStringSet("blocks", "is blocked by"), deliveries[0].LinkTypes)
+	})
+
+	t.Run("one post per subscription when duplicates are allowed", func(t *testing.T) {
+		deliveries := groupChannelDeliveries(subs, false)
+		assert.Len(t, deliveries, 4)
+		for i, delivery := range deliveries {
+			assert.Equal(t, subs[i].ChannelID, delivery.ChannelID)
+			assert.Equal(t, subs[i].Name, delivery.Name)
+			assert.Equal(t, subs[i].GetRenderStyle(), delivery.RenderStyle)
+		}
+	})
+}
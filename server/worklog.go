@@ -0,0 +1,99 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/pkg/errors"
+)
+
+// worklogDurationPartRegexp matches a part of a Jira duration, e.g. `2d`,
+// `1.5h` or `30m`.
+var worklogDurationPartRegexp = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)([wdhm])`)
+
+// worklogDurationUnits are the units of a Jira duration, from the largest.
+const worklogDurationUnits = "wdhm"
+
+// parseWorklogDuration parses a duration in the syntax of Jira, e.g. `1h 30m`,
+// `1h30m` or `2d`, at the start of the words. It returns the duration in the
+// normalized syntax that Jira accepts, e.g. `1h 30m`, and the words that
+// follow it.
+func parseWorklogDuration(words []string) (string, []string, error) {
+	parts := []string{}
+	lastUnit := -1
+	n := 0
+	for ; n < len(words); n++ {
+		word := words[n]
+		wordParts := []string{}
+		for word != "" {
+			match := worklogDurationPartRegexp.FindStringSubmatch(word)
+			if match == nil {
+				break
+			}
+			unit := strings.Index(worklogDurationUnits, strings.ToLower(match[2]))
+			if unit <= lastUnit {
+				return "", nil, errors.Errorf("`%s` is not a valid duration, please use each of the units w, d, h and m at most once, from the largest, e.g. `1h 30m`", words[n])
+			}
+			lastUnit = unit
+			wordParts = append(wordParts, match[1]+worklogDurationUnits[unit:unit+1])
+			word = word[len(match[0]):]
+		}
+		if len(wordParts) == 0 {
+			break
+		}
+		if word != "" {
+			return "", nil, errors.Errorf("`%s` is not a valid duration, e.g. `1h 30m`", words[n])
+		}
+		parts = append(parts, wordParts...)
+	}
+	if len(parts) == 0 {
+		return "", nil, errors.New("please specify the time spent, e.g. `1h 30m`")
+	}
+	for _, part := range parts {
+		if strings.Trim(part, "0.wdhm") == "" {
+			return "", nil, errors.New("the time spent must be more than 0")
+		}
+	}
+	return strings.Join(parts, " "), words[n:], nil
+}
+
+func executeWorklog(p *Plugin, c *plugin.Context, header *model.CommandArgs, args ...string) *model.CommandResponse {
+	const usage = "Please use `/jira worklog <issue-key> <duration> [comment]`, e.g. `/jira worklog KT-12 1h 30m Reviewed the fix`."
+
+	user, instance, args, err := p.loadFlagUserInstance(header.UserId, args)
+	if err != nil {
+		return p.responsef(header, "Failed to load your connection to Jira. Error: %v.", err)
+	}
+	if len(args) < 2 {
+		return p.responsef(header, usage)
+	}
+	issueKey := strings.ToUpper(args[0])
+	timeSpent, words, err := parseWorklogDuration(args[1:])
+	if err != nil {
+		return p.responsef(header, "%v. %s", err, usage)
+	}
+
+	client, _, _, err := p.getClient(instance.GetID(), user.MattermostUserID)
+	if err != nil {
+		return p.responsef(header, "%v", err)
+	}
+	started := jira.Time(time.Now())
+	_, err = client.AddWorklog(issueKey, &jira.WorklogRecord{
+		TimeSpent: timeSpent,
+		Started:   &started,
+		Comment:   p.mapMentions(instance.GetID(), strings.Join(words, " ")),
+	})
+	if err != nil {
+		return p.responsef(header, "Failed to log the time spent. Error: %v.", err)
+	}
+	p.recordSelfChange(instance.GetID(), issueKey, user.MattermostUserID)
+
+	return p.responsef(header, "Logged %s on [%s](%s/browse/%s).", timeSpent, issueKey, instance.GetJiraBaseURL(), issueKey)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorklogDuration(t *testing.T) {
+	for name, tc := range map[string]struct {
+		words             []string
+		expectedTimeSpent string
+		expectedComment   []string
+		expectedErr       string
+	}{
+		"hours and minutes": {
+			words:             []string{"1h", "30m", "Reviewed", "the", "fix"},
+			expectedTimeSpent: "1h 30m",
+			expectedComment:   []string{"Reviewed", "the", "fix"},
+		},
+		"in one word": {
+			words:             []string{"1W2d4H"},
+			expectedTimeSpent: "1w 2d 4h",
+			expectedComment:   []string{},
+		},
+		"fraction": {
+			words:             []string{"1.5h", "2", "reviews"},
+			expectedTimeSpent: "1.5h",
+			expectedComment:   []string{"2", "reviews"},
+		},
+		"no duration": {
+			words:       []string{"Reviewed"},
+			expectedErr: "please specify the time spent",
+		},
+		"unknown unit": {
+			words:       []string{"30s"},
+			expectedErr: "please specify the time spent",
+		},
+		"unknown unit after a valid one": {
+			words:       []string{"1h30s"},
+			expectedErr: "`1h30s` is not a valid duration",
+		},
+		"units out of order": {
+			words:       []string{"30m", "1h"},
+			expectedErr: "`1h` is not a valid duration",
+		},
+		"repeated unit": {
+			words:       []string{"1h1h"},
+			expectedErr: "`1h1h` is not a valid duration",
+		},
+		"zero": {
+			words:       []string{"0h", "0m"},
+			expectedErr: "more than 0",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			timeSpent, comment, err := parseWorklogDuration(tc.words)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTimeSpent, timeSpent)
+			assert.Equal(t, tc.expectedComment, comment)
+		})
+	}
+}
@@ -17,6 +17,7 @@ import {
     AutoCompleteParams,
     ChannelSubscription,
     CreateIssueRequest,
+    CreateSubtasksRequest,
     InstanceType,
     ProjectMetadata,
     SearchIssueParams,
@@ -59,11 +60,16 @@ export const openCreateModal = (postId: string) => {
     };
 };
 
-export const openCreateModalWithoutPost = (description: string, channelId: string) => (dispatch) => dispatch({
+export const openCreateModalWithoutPost = (description: string, channelId: string, parentKey = '', assigneeUsername = '', sprint = '', fromKey = '', reporter = '') => (dispatch) => dispatch({
     type: ActionTypes.OPEN_CREATE_ISSUE_MODAL_WITHOUT_POST,
     data: {
         description,
         channelId,
+        parentKey,
+        assigneeUsername,
+        sprint,
+        fromKey,
+        reporter,
     },
 });
 
@@ -88,12 +94,30 @@ export const closeAttachCommentToIssueModal = () => {
     };
 };
 
-export const fetchJiraIssueMetadataForProjects = (projectKeys: string[], instanceID: string) => {
+export const openCreateSubtasksModal = (postId: string) => {
+    return {
+        type: ActionTypes.OPEN_CREATE_SUBTASKS_MODAL,
+        data: {
+            postId,
+        },
+    };
+};
+
+export const closeCreateSubtasksModal = () => {
+    return {
+        type: ActionTypes.CLOSE_CREATE_SUBTASKS_MODAL,
+    };
+};
+
+export const fetchJiraIssueMetadataForProjects = (projectKeys: string[], instanceID: string, channelID = '') => {
     return async (dispatch: Dispatch, getState: GlobalState) => {
         const baseUrl = getPluginServerRoute(getState());
         const projectKeysParam = projectKeys.join(',');
         let data = null;
-        const params = `project-keys=${projectKeysParam}&instance_id=${instanceID}`;
+        let params = `project-keys=${projectKeysParam}&instance_id=${instanceID}`;
+        if (channelID) {
+            params += `&channel_id=${channelID}`;
+        }
         try {
             data = await doFetch(`${baseUrl}/api/v2/get-create-issue-metadata-for-project?${params}`, {
                 method: 'get',
@@ -110,6 +134,27 @@ export const fetchJiraIssueMetadataForProjects = (projectKeys: string[], instanc
     };
 };
 
+export const fetchIssueTemplate = (instanceID: string, issueKey: string, projectKey: string, issueTypeID = '') => {
+    return async (dispatch: Dispatch, getState: GlobalState) => {
+        const baseUrl = getPluginServerRoute(getState());
+        let data = null;
+        const params = `instance_id=${instanceID}&issue_key=${issueKey}&project_key=${projectKey}&issue_type_id=${issueTypeID}`;
+        try {
+            data = await doFetch(`${baseUrl}/api/v2/get-issue-template?${params}`, {
+                method: 'get',
+            });
+        } catch (error) {
+            return {error};
+        }
+
+        if (data.error) {
+            return {error: new Error(data.error)};
+        }
+
+        return {data};
+    };
+};
+
 export const fetchJiraProjectMetadata = (instanceID: string) => {
     return async (dispatch: Dispatch, getState: GlobalState) => {
         const baseUrl = getPluginServerRoute(getState());
@@ -179,6 +224,13 @@ export const searchCommentVisibilityFields = (params) => {
     };
 };
 
+export const fetchJiraIssueLinkTypes = (instanceID: string) => {
+    return async (dispatch: Dispatch, getState: GlobalState) => {
+        const url = `${getPluginServerRoute(getState())}/api/v2/get-issue-link-types`;
+        return doFetchWithResponse(`${url}${buildQueryString({instance_id: instanceID})}`);
+    };
+};
+
 export const searchTeamFields = (params) => {
     return async (dispatch: Dispatch, getState: GlobalState) => {
         const url = `${getPluginServerRoute(getState())}/api/v2/get-team-fields`;
@@ -225,6 +277,22 @@ export const attachCommentToIssue = (payload: AttachCommentRequest) => {
     };
 };
 
+export const createSubtasksFromPost = (payload: CreateSubtasksRequest) => {
+    return async (dispatch: Dispatch, getState: GlobalState) => {
+        const baseUrl = getPluginServerRoute(getState());
+        try {
+            const data = await doFetch(`${baseUrl}/api/v2/create-subtasks-from-post`, {
+                method: 'post',
+                body: JSON.stringify(payload),
+            });
+
+            return {data};
+        } catch (error) {
+            return {error};
+        }
+    };
+};
+
 export const createChannelSubscription = (subscription: ChannelSubscription) => {
     return async (dispatch: Dispatch, getState: GlobalState) => {
         const baseUrl = getPluginServerRoute(getState());
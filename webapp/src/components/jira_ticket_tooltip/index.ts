@@ -5,15 +5,21 @@ import {connect} from 'react-redux';
 import {Dispatch, bindActionCreators} from 'redux';
 import {GlobalState} from 'mattermost-redux/types/store';
 
-import {getUserConnectedInstances, isUserConnected} from 'selectors';
+import {getPluginSettings, getUserConnectedInstances, isUserConnected} from 'selectors';
 import {fetchIssueByKey} from 'actions';
 
 import TicketPopover from './jira_ticket_tooltip';
 
+const defaultUnfurlFields = ['status', 'assignee'];
+const defaultSummaryMaxLength = 80;
+
 const mapStateToProps = (state: GlobalState) => {
+    const pluginSettings = getPluginSettings(state);
     return {
         connected: isUserConnected(state),
         connectedInstances: getUserConnectedInstances(state),
+        unfurlFields: pluginSettings?.unfurl_fields || defaultUnfurlFields,
+        summaryMaxLength: pluginSettings?.unfurl_summary_max_length || defaultSummaryMaxLength,
     };
 };
 
@@ -6,6 +6,9 @@ import {shallow} from 'enzyme';
 
 import {Instance, InstanceType} from 'types/model';
 
+import {ticketData} from 'testdata/get-ticket-metadata-for-tooltip';
+import {getJiraTicketDetails} from 'utils/jira_issue_metadata';
+
 import TicketPopover, {Props} from './jira_ticket_tooltip';
 
 describe('components/jira_ticket_tooltip', () => {
@@ -26,6 +29,8 @@ describe('components/jira_ticket_tooltip', () => {
             show: false,
             connected: false,
             connectedInstances: mockConnectedInstances,
+            unfurlFields: ['status', 'assignee'],
+            summaryMaxLength: 80,
             fetchIssueByKey: jest.fn(),
         };
 
@@ -34,6 +39,8 @@ describe('components/jira_ticket_tooltip', () => {
             show: false,
             connected: false,
             connectedInstances: [],
+            unfurlFields: ['status', 'assignee'],
+            summaryMaxLength: 80,
             fetchIssueByKey: jest.fn(),
         };
 
@@ -94,4 +101,39 @@ describe('components/jira_ticket_tooltip', () => {
             expect(instance.getIssueKey()).toEqual(null);
         });
     });
+
+    describe('fields', () => {
+        const baseProps: Props = {
+            href: 'https://something-1.atlassian.net/browse/ABC-123',
+            show: true,
+            connected: true,
+            connectedInstances: [{instance_id: 'https://something-1.atlassian.net', type: InstanceType.CLOUD}],
+            unfurlFields: ['type', 'priority', 'status'],
+            summaryMaxLength: 10,
+            fetchIssueByKey: jest.fn().mockResolvedValue({}),
+        };
+
+        test('should render the configured fields in order, and truncate the summary', () => {
+            const wrapper = shallow<TicketPopover>(<TicketPopover {...baseProps}/>);
+            wrapper.setState({ticketDetails: getJiraTicketDetails(ticketData('Mock Name').data)});
+
+            const fields = wrapper.find('.popover-footer__field');
+            expect(fields.map((field) => field.key())).toEqual(['type', 'priority', 'status']);
+            expect(wrapper.find('.tooltip-ticket-summary').text()).toEqual('This is a ...');
+        });
+
+        test('should skip the empty fields', () => {
+            const wrapper = shallow<TicketPopover>(
+                <TicketPopover
+                    {...baseProps}
+                    unfurlFields={['reporter', 'assignee']}
+                />,
+            );
+            const ticketDetails = getJiraTicketDetails(ticketData(null).data);
+            wrapper.setState({ticketDetails: ticketDetails && {...ticketDetails, reporterName: ''}});
+
+            const fields = wrapper.find('.popover-footer__field');
+            expect(fields.map((field) => field.key())).toEqual(['assignee']);
+        });
+    });
 });
@@ -18,6 +18,8 @@ export type Props = {
     show: boolean;
     connected: boolean;
     connectedInstances: Instance[];
+    unfurlFields: string[];
+    summaryMaxLength: number;
     fetchIssueByKey: (issueKey: string, instanceID: string) => Promise<{data?: TicketData}>;
 }
 
@@ -29,7 +31,6 @@ export type State = {
 
 const isAssignedLabel = ' is assigned';
 const unAssignedLabel = 'Unassigned';
-const jiraTicketSummaryMaxLength = 80;
 const maxTicketDescriptionLength = 160;
 
 enum myStatus {
@@ -185,6 +186,88 @@ export default class TicketPopover extends React.PureComponent<Props, State> {
         );
     }
 
+    renderAssignee(ticketDetails: TicketDetails): ReactNode {
+        return (
+            <React.Fragment>
+                {ticketDetails.assigneeAvatar ? (
+                    <img
+                        className='popover-footer__assignee-profile'
+                        src={ticketDetails.assigneeAvatar}
+                        alt='jira assignee profile'
+                    />
+                ) : <DefaultAvatar/>
+                }
+                {ticketDetails.assigneeName ? (
+                    <span>
+                        <span className='popover-footer__assignee-name'>
+                            {ticketDetails.assigneeName}
+                        </span>
+                        <span>
+                            {isAssignedLabel}
+                        </span>
+                    </span>
+                ) : (
+                    <span>
+                        {unAssignedLabel}
+                    </span>
+                )
+                }
+            </React.Fragment>
+        );
+    }
+
+    renderLabeledField(label: string, value: string): ReactNode {
+        if (!value) {
+            return null;
+        }
+
+        return (
+            <span>
+                {`${label}: `}
+                <span className='popover-footer__field-value'>{value}</span>
+            </span>
+        );
+    }
+
+    renderField(field: string, ticketDetails: TicketDetails): ReactNode {
+        switch (field) {
+        case 'status':
+            return this.tagTicketStatus(ticketDetails.statusKey);
+        case 'assignee':
+            return this.renderAssignee(ticketDetails);
+        case 'priority':
+            return this.renderLabeledField('Priority', ticketDetails.priority);
+        case 'type':
+            return this.renderLabeledField('Type', ticketDetails.issueType);
+        case 'reporter':
+            return this.renderLabeledField('Reporter', ticketDetails.reporterName);
+        case 'created':
+            return this.renderLabeledField('Created', ticketDetails.created && new Date(ticketDetails.created).toLocaleDateString());
+        default:
+            return null;
+        }
+    }
+
+    renderFields(ticketDetails: TicketDetails): ReactNode {
+        const fields = this.props.unfurlFields.map((field) => ({field, node: this.renderField(field, ticketDetails)})).filter(({node}) => node);
+        if (!fields.length) {
+            return null;
+        }
+
+        return (
+            <div className='popover-footer'>
+                {fields.map(({field, node}) => (
+                    <div
+                        key={field}
+                        className={`popover-footer__field popover-footer__field--${field}`}
+                    >
+                        {node}
+                    </div>
+                ))}
+            </div>
+        );
+    }
+
     render() {
         if (!this.state.ticketId || (!this.state.ticketDetails && !this.props.show)) {
             return null;
@@ -208,9 +291,10 @@ export default class TicketPopover extends React.PureComponent<Props, State> {
             );
         }
 
-        // Format the ticket summary by trimming spaces, replacing multiple spaces with one, truncating to `jiraTicketSummaryMaxLength`, and adding '...' if it exceeds the limit.
+        // Format the ticket summary by trimming spaces, replacing multiple spaces with one, truncating to `summaryMaxLength`, and adding '...' if it exceeds the limit.
+        const {summaryMaxLength} = this.props;
         const formattedSummary = ticketDetails?.summary ? `${ticketDetails.summary.trim().split(/\s+/).join(' ')
-            .substring(0, jiraTicketSummaryMaxLength)}${ticketDetails.summary.trim().split(/\s+/).join(' ').length > jiraTicketSummaryMaxLength ? '...' : ''}` : '';
+            .substring(0, summaryMaxLength)}${ticketDetails.summary.trim().split(/\s+/).join(' ').length > summaryMaxLength ? '...' : ''}` : '';
 
         if (!ticketDetails) {
             // Display the spinner loader while ticket details are being fetched
@@ -252,9 +336,8 @@ export default class TicketPopover extends React.PureComponent<Props, State> {
                             title={ticketDetails?.summary}
                             rel='noopener noreferrer'
                         >
-                            <h5 className='tooltip-ticket-summary'>{ticketDetails.summary && ticketDetails.summary.substring(0, jiraTicketSummaryMaxLength)}</h5>
+                            <h5 className='tooltip-ticket-summary'>{formattedSummary}</h5>
                         </a>
-                        {this.tagTicketStatus(ticketDetails.statusKey)}
                     </div>
                     <div className='popover-body__description'>
                         <ReactMarkdown>{ticketDetails.description && `${ticketDetails.description.substring(0, maxTicketDescriptionLength).trim()}${ticketDetails.description.length > maxTicketDescriptionLength ? '...' : ''}`}</ReactMarkdown>
@@ -273,31 +356,7 @@ export default class TicketPopover extends React.PureComponent<Props, State> {
                         {this.renderLabelList(ticketDetails.labels)}
                     </div>
                 </div>
-                <div className='popover-footer'>
-                    {ticketDetails.assigneeAvatar ? (
-                        <img
-                            className='popover-footer__assignee-profile'
-                            src={ticketDetails.assigneeAvatar}
-                            alt='jira assignee profile'
-                        />
-                    ) : <DefaultAvatar/>
-                    }
-                    {ticketDetails.assigneeName ? (
-                        <span>
-                            <span className='popover-footer__assignee-name'>
-                                {ticketDetails.assigneeName}
-                            </span>
-                            <span>
-                                {isAssignedLabel}
-                            </span>
-                        </span>
-                    ) : (
-                        <span>
-                            {unAssignedLabel}
-                        </span>
-                    )
-                    }
-                </div>
+                {this.renderFields(ticketDetails)}
             </div>
         );
     }
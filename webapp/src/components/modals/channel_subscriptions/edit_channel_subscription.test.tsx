@@ -31,6 +31,7 @@ describe('components/EditChannelSubscription', () => {
         getConnected: jest.fn().mockResolvedValue({}),
         fetchJiraProjectMetadataForAllInstances: jest.fn().mockResolvedValue({}),
         fetchJiraIssueMetadataForProjects: jest.fn().mockResolvedValue({data: cloudIssueMetadata}),
+        fetchJiraIssueLinkTypes: jest.fn().mockResolvedValue({data: []}),
     };
 
     const channelSubscriptionForCloud = {
@@ -242,6 +243,9 @@ describe('components/EditChannelSubscription', () => {
                 filters: channelSubscriptionForCloud.filters,
                 name: channelSubscriptionForCloud.name,
                 instance_id: 'https://something.atlassian.net',
+                render_style: 'full',
+                title_link: 'key_summary',
+                link_types: [],
             },
         );
         expect(editChannelSubscription).not.toHaveBeenCalled();
@@ -294,6 +298,9 @@ describe('components/EditChannelSubscription', () => {
                 filters: channelSubscriptionForServer.filters,
                 name: null,
                 instance_id: 'https://something.atlassian.net',
+                render_style: 'full',
+                title_link: 'key_summary',
+                link_types: [],
             },
         );
         expect(editChannelSubscription).not.toHaveBeenCalled();
@@ -366,6 +373,9 @@ describe('components/EditChannelSubscription', () => {
                 },
                 name: 'SubTestName',
                 instance_id: 'https://something.atlassian.net',
+                render_style: 'full',
+                title_link: 'key_summary',
+                link_types: [],
             },
         );
     });
@@ -398,6 +408,9 @@ describe('components/EditChannelSubscription', () => {
                 filters: channelSubscriptionForCloud.filters,
                 name: channelSubscriptionForCloud.name,
                 instance_id: 'https://something.atlassian.net',
+                render_style: 'full',
+                title_link: 'key_summary',
+                link_types: [],
             },
         );
         expect(createChannelSubscription).not.toHaveBeenCalled();
@@ -419,6 +432,36 @@ describe('components/EditChannelSubscription', () => {
         expect(wrapper.state().error).toEqual('Failure');
     });
 
+    test('should keep the JQL of a subscription to a saved filter', async () => {
+        const editChannelSubscription = jest.fn().mockResolvedValue({});
+        const filterSubscription = {
+            ...channelSubscriptionForCloud,
+            filters: {
+                events: ['event_created', 'event_updated_any'],
+                projects: [],
+                issue_types: [],
+                fields: [],
+                jql: 'project = KT AND labels = release',
+            },
+        };
+        const props = {
+            ...baseProps,
+            editChannelSubscription,
+            channelSubscriptions: [filterSubscription],
+            selectedSubscription: filterSubscription,
+        };
+        const wrapper = shallow<EditChannelSubscription>(
+            <EditChannelSubscription {...props}/>,
+        );
+        wrapper.setState(baseState);
+        expect(wrapper.instance().isJQLOnly()).toBe(true);
+
+        wrapper.instance().handleCreate({preventDefault: jest.fn()});
+        expect(editChannelSubscription).toHaveBeenCalledWith(
+            expect.objectContaining({filters: filterSubscription.filters}),
+        );
+    });
+
     test('should produce subscription error when add conflicting issue type', async () => {
         // This test checks that adding an issue type with confilcting fields
         // will trigger an error message that lists the conflicting filter
@@ -34,6 +34,18 @@ import {
 import ChannelSubscriptionFilters from './channel_subscription_filters';
 import {SharedProps} from './shared_props';
 
+const RenderStyleOptions: ReactSelectOption[] = [
+    {value: 'full', label: 'Full card'},
+    {value: 'compact', label: 'Compact line'},
+    {value: 'title', label: 'Title only'},
+];
+
+const TitleLinkOptions: ReactSelectOption[] = [
+    {value: 'key_summary', label: 'Issue key and summary'},
+    {value: 'key', label: 'Issue key'},
+    {value: 'summary', label: 'Summary'},
+];
+
 const JiraEventOptions: ReactSelectOption[] = [
     {value: 'event_created', label: 'Issue Created'},
     {value: 'event_deleted', label: 'Issue Deleted'},
@@ -79,6 +91,10 @@ export type State = {
     submitting: boolean;
     submittingTemplate: boolean;
     subscriptionName: string | null;
+    renderStyle: string;
+    titleLink: string;
+    linkTypes: string[];
+    linkTypeOptions: ReactSelectOption[];
     showConfirmModal: boolean;
     confirmActionType: 'delete' | 'close' | null;
     conflictingError: string | null;
@@ -99,14 +115,23 @@ export default class EditChannelSubscription extends PureComponent<Props, State>
         };
 
         let subscriptionName = null;
+        let renderStyle = 'full';
+        let titleLink = 'key_summary';
+        let linkTypes: string[] = [];
         if (props.selectedSubscription) {
             filters = Object.assign({}, filters, props.selectedSubscription.filters);
             subscriptionName = props.selectedSubscription.name;
+            renderStyle = props.selectedSubscription.render_style || renderStyle;
+            titleLink = props.selectedSubscription.title_link || titleLink;
+            linkTypes = props.selectedSubscription.link_types || linkTypes;
         }
 
         if (props.selectedSubscriptionTemplate) {
             filters = Object.assign({}, filters, props.selectedSubscriptionTemplate.filters);
             subscriptionName = props.selectedSubscriptionTemplate.name;
+            renderStyle = props.selectedSubscriptionTemplate.render_style || renderStyle;
+            titleLink = props.selectedSubscriptionTemplate.title_link || titleLink;
+            linkTypes = props.selectedSubscriptionTemplate.link_types || linkTypes;
         }
 
         filters.fields = filters.fields || [];
@@ -124,6 +149,7 @@ export default class EditChannelSubscription extends PureComponent<Props, State>
         if (filters.projects.length && instanceID) {
             fetchingIssueMetadata = true;
             this.fetchIssueMetadata(filters.projects, instanceID);
+            this.fetchIssueLinkTypes(instanceID);
         }
 
         this.state = {
@@ -135,6 +161,10 @@ export default class EditChannelSubscription extends PureComponent<Props, State>
             fetchingIssueMetadata,
             jiraIssueMetadata: null,
             subscriptionName,
+            renderStyle,
+            titleLink,
+            linkTypes,
+            linkTypeOptions: [],
             showConfirmModal: false,
             confirmActionType: null,
             conflictingError: null,
@@ -178,6 +208,18 @@ export default class EditChannelSubscription extends PureComponent<Props, State>
         this.setState({subscriptionName: value});
     };
 
+    handleRenderStyleChange = (_: any, renderStyle: string) => {
+        this.setState({renderStyle});
+    };
+
+    handleTitleLinkChange = (_: any, titleLink: string) => {
+        this.setState({titleLink});
+    };
+
+    handleLinkTypesChange = (_: any, linkTypes: string[] | null) => {
+        this.setState({linkTypes: linkTypes || []});
+    };
+
     deleteChannelSubscription = () => {
         if (this.props.selectedSubscription) {
             this.props.deleteChannelSubscription(this.props.selectedSubscription).then((res) => {
@@ -300,6 +342,12 @@ export default class EditChannelSubscription extends PureComponent<Props, State>
         });
     };
 
+    fetchIssueLinkTypes = (instanceID: string) => {
+        this.props.fetchJiraIssueLinkTypes(instanceID).then(({data}) => {
+            this.setState({linkTypeOptions: data || []});
+        });
+    };
+
     fetchSubscriptionTemplateForProjectKey = (instanceId: string, projectId: string) => {
         this.setState({selectedTemplateID: null, fetchingIssueMetadata: true});
         this.props.fetchSubscriptionTemplatesForProjectKey(instanceId, projectId).then((subs) => {
@@ -349,6 +397,7 @@ export default class EditChannelSubscription extends PureComponent<Props, State>
             issue_types: [],
             events: [],
             fields: [],
+            jql: this.state.filters.jql,
         };
 
         let fetchingIssueMetadata = false;
@@ -356,6 +405,7 @@ export default class EditChannelSubscription extends PureComponent<Props, State>
         if (projects && projects.length) {
             fetchingIssueMetadata = true;
             this.fetchIssueMetadata(projects, this.state.instanceID);
+            this.fetchIssueLinkTypes(this.state.instanceID);
         }
 
         if (this.state.instanceID && projectID) {
@@ -402,6 +452,9 @@ export default class EditChannelSubscription extends PureComponent<Props, State>
             filters,
             name: this.state.subscriptionName,
             instance_id: this.state.instanceID,
+            render_style: this.state.renderStyle,
+            title_link: this.state.titleLink,
+            link_types: this.state.linkTypes,
         } as ChannelSubscription;
 
         if (this.props.selectedSubscriptionTemplate) {
@@ -451,11 +504,15 @@ export default class EditChannelSubscription extends PureComponent<Props, State>
         const templateChoosen = this.props.subscriptionTemplates.find((template) => template.id === templateId);
         this.handleProjectChange(templateChoosen.filters.projects[0]);
         this.setState({
-            filters: templateChoosen.filters,
+            filters: {...templateChoosen.filters, jql: this.state.filters.jql},
             selectedTemplateID: templateId,
         });
     };
 
+    // The JQL of a subscription imported from a saved Jira filter takes the
+    // place of its project, which it can then be saved without.
+    isJQLOnly = (): boolean => Boolean(this.state.filters.jql) && !this.state.filters.projects[0];
+
     render(): JSX.Element {
         const style = getModalStyles(this.props.theme);
 
@@ -467,6 +524,10 @@ export default class EditChannelSubscription extends PureComponent<Props, State>
 
         const eventOptions = JiraEventOptions.concat(customFields);
 
+        // Keep the saved link types selectable if the instance no longer lists them.
+        const savedLinkTypes = this.state.linkTypes.filter((linkType) => !this.state.linkTypeOptions.some((option) => option.value === linkType));
+        const linkTypeOptions = this.state.linkTypeOptions.concat(savedLinkTypes.map((linkType) => ({label: linkType, value: linkType})));
+
         let conflictingErrorComponent = null;
         if (this.state.conflictingError) {
             conflictingErrorComponent = (
@@ -518,6 +579,36 @@ export default class EditChannelSubscription extends PureComponent<Props, State>
                             addValidate={this.validator.addComponent}
                             removeValidate={this.validator.removeComponent}
                         />
+                        <ReactSelectSetting
+                            name='render_style'
+                            label='Render Style'
+                            required={false}
+                            onChange={this.handleRenderStyleChange}
+                            options={RenderStyleOptions}
+                            theme={this.props.theme}
+                            value={RenderStyleOptions.find((option) => option.value === this.state.renderStyle)}
+                        />
+                        <ReactSelectSetting
+                            name='title_link'
+                            label='Title Link'
+                            required={false}
+                            onChange={this.handleTitleLinkChange}
+                            options={TitleLinkOptions}
+                            theme={this.props.theme}
+                            value={TitleLinkOptions.find((option) => option.value === this.state.titleLink)}
+                        />
+                        {this.state.renderStyle === 'full' && (
+                            <ReactSelectSetting
+                                name='link_types'
+                                label='Issue Links'
+                                required={false}
+                                onChange={this.handleLinkTypesChange}
+                                options={linkTypeOptions}
+                                isMulti={true}
+                                theme={this.props.theme}
+                                value={linkTypeOptions.filter((option) => this.state.linkTypes.includes(option.value))}
+                            />
+                        )}
                         {conflictingErrorComponent}
                         <ChannelSubscriptionFilters
                             fields={filterFields}
@@ -558,6 +649,23 @@ export default class EditChannelSubscription extends PureComponent<Props, State>
                 );
             }
 
+            let jqlComponent = null;
+            if (this.state.filters.jql) {
+                jqlComponent = (
+                    <div>
+                        <label className='control-label margin-bottom'>
+                            {'JQL'}
+                        </label>
+                        <div style={getBaseStyles(this.props.theme).codeBlock}>
+                            <span>{this.state.filters.jql}</span>
+                        </div>
+                        <p className='help-text'>
+                            {'The subscription is limited to the issues matching this JQL, which is kept when the subscription is saved. Use `/jira subscribe resync` to update it from its saved Jira filter.'}
+                        </p>
+                    </div>
+                );
+            }
+
             component = (
                 <React.Fragment>
                     <div className='container-fluid'>
@@ -581,11 +689,13 @@ export default class EditChannelSubscription extends PureComponent<Props, State>
                             onInstanceChange={this.handleJiraInstanceChange}
                             onProjectChange={this.handleProjectChange}
                             onError={(error: string) => this.setState({error})}
+                            hideProjectSelector={this.isJQLOnly() || undefined}
 
                             theme={this.props.theme}
                             addValidate={this.validator.addComponent}
                             removeValidate={this.validator.removeComponent}
                         />
+                        {jqlComponent}
                         {innerComponent}
                     </div>
                 </React.Fragment>
@@ -630,7 +740,7 @@ export default class EditChannelSubscription extends PureComponent<Props, State>
             );
         }
 
-        const enableSubmitButton = Boolean(this.state.filters.projects[0]);
+        const enableSubmitButton = Boolean(this.state.filters.projects[0]) || this.isJQLOnly();
         const enableDeleteButton = Boolean(this.props.selectedSubscription || this.props.selectedSubscriptionTemplate);
         let saveSubscriptionButtonText = '';
         let headerText = '';
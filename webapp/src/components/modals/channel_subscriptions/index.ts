@@ -17,6 +17,7 @@ import {
     editSubscriptionTemplate,
     fetchAllSubscriptionTemplates,
     fetchChannelSubscriptions,
+    fetchJiraIssueLinkTypes,
     fetchJiraIssueMetadataForProjects,
     fetchJiraProjectMetadata,
     fetchJiraProjectMetadataForAllInstances,
@@ -71,6 +72,7 @@ const mapDispatchToProps = (dispatch) => bindActionCreators({
     fetchJiraProjectMetadata,
     fetchJiraProjectMetadataForAllInstances,
     fetchJiraIssueMetadataForProjects,
+    fetchJiraIssueLinkTypes,
     createChannelSubscription,
     createSubscriptionTemplate,
     fetchAllSubscriptionTemplates,
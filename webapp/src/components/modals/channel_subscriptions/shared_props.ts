@@ -11,6 +11,7 @@ import {
     GetConnectedResponse,
     Instance,
     IssueMetadata,
+    ReactSelectOption,
 } from 'types/model';
 
 export type SharedProps = {
@@ -30,6 +31,7 @@ export type SharedProps = {
     fetchSubscriptionTemplatesForProjectKey: (instanceId: string, projectKey: string) => Promise<APIResponse<ChannelSubscription[]>>;
     fetchJiraProjectMetadataForAllInstances: () => Promise<APIResponse<AllProjectMetadata>>;
     fetchJiraIssueMetadataForProjects: (projectKeys: string[], instanceID: string) => Promise<APIResponse<IssueMetadata>>;
+    fetchJiraIssueLinkTypes: (instanceID: string) => Promise<APIResponse<ReactSelectOption[]>>;
     fetchChannelSubscriptions: (channelId: string) => Promise<APIResponse<ChannelSubscription[]>>;
     fetchAllSubscriptionTemplates: () => Promise<APIResponse<ChannelSubscription[]>>;
     getConnected: () => Promise<GetConnectedResponse>;
@@ -19,6 +19,7 @@ import CreateIssueForm from './create_issue_form';
 describe('components/CreateIssue', () => {
     const baseActions = {
         clearIssueMetadata: jest.fn().mockResolvedValue({}),
+        fetchIssueTemplate: jest.fn().mockResolvedValue({}),
         fetchJiraIssueMetadataForProjects: jest.fn().mockResolvedValue({}),
         fetchJiraProjectMetadata: jest.fn().mockResolvedValue({}),
         create: jest.fn().mockResolvedValue({}),
@@ -173,4 +174,28 @@ describe('components/CreateIssue', () => {
         wrapper.instance().handleSubmit();
         expect(create).toHaveBeenCalled();
     });
+
+    test('should pick the default issue type of the project', () => {
+        const props = {...baseProps};
+        const wrapper = shallow<CreateIssueForm>(
+            <CreateIssueForm {...props}/>,
+        );
+        wrapper.setState({...baseState, projectKey: 'KT'});
+
+        wrapper.instance().applyDefaultIssueType('KT', '', {...issueMetadata, default_issue_type_id: '10004'} as IssueMetadata);
+        expect(wrapper.state('issueType')).toEqual('10004');
+        expect(wrapper.state('fields').issuetype).toEqual({id: '10004'});
+    });
+
+    test('should note that the default issue type is not in the project', () => {
+        const props = {...baseProps};
+        const wrapper = shallow<CreateIssueForm>(
+            <CreateIssueForm {...props}/>,
+        );
+        wrapper.setState({...baseState, projectKey: 'KT'});
+
+        wrapper.instance().applyDefaultIssueType('KT', '', {...issueMetadata, default_issue_type_note: 'Please choose another one.'} as IssueMetadata);
+        expect(wrapper.state('issueType')).toBeNull();
+        expect(wrapper.state('issueTypeNote')).toEqual('Please choose another one.');
+    });
 });
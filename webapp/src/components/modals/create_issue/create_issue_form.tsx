@@ -13,6 +13,7 @@ import {
     CreateIssueFields,
     CreateIssueRequest,
     IssueMetadata,
+    IssueTemplate,
     JiraField,
     JiraFieldCustomTypeEnums,
     JiraFieldTypeEnums,
@@ -65,11 +66,17 @@ type Props = {
     create: (issue: CreateIssueRequest) => Promise<APIResponse<{}>>;
     description?: string;
     channelId?: string;
+    parentKey?: string;
+    assigneeUsername?: string;
+    sprint?: string;
+    fromKey?: string;
+    reporter?: string;
     currentTeam: Team;
     post?: Post;
     theme: Theme;
     visible: boolean;
-    fetchJiraIssueMetadataForProjects: (projectKeys: string[], instanceID: string) => Promise<APIResponse<IssueMetadata>>;
+    fetchJiraIssueMetadataForProjects: (projectKeys: string[], instanceID: string, channelID?: string) => Promise<APIResponse<IssueMetadata>>;
+    fetchIssueTemplate: (instanceID: string, issueKey: string, projectKey: string, issueTypeID?: string) => Promise<APIResponse<IssueTemplate>>;
 };
 
 type State = {
@@ -81,6 +88,8 @@ type State = {
     error: string | null;
     jiraIssueMetadata: IssueMetadata | null;
     fetchingIssueMetadata: boolean;
+    templateNote: string | null;
+    issueTypeNote: string | null;
 };
 
 export default class CreateIssueForm extends React.PureComponent<Props, State> {
@@ -100,6 +109,8 @@ export default class CreateIssueForm extends React.PureComponent<Props, State> {
             error: null,
             fetchingIssueMetadata: false,
             jiraIssueMetadata: null,
+            templateNote: null,
+            issueTypeNote: null,
             submitting: false,
             fields: {
                 description,
@@ -127,9 +138,9 @@ export default class CreateIssueForm extends React.PureComponent<Props, State> {
 
     handleProjectChange = (fieldValues: SavedFieldValues) => {
         const projectKey = fieldValues.project_key ? fieldValues.project_key : '';
-        this.setState({projectKey, fetchingIssueMetadata: true, error: null});
+        this.setState({projectKey, fetchingIssueMetadata: true, error: null, issueTypeNote: null});
 
-        this.props.fetchJiraIssueMetadataForProjects([projectKey], this.state.instanceID as string).then(({data, error}) => {
+        this.props.fetchJiraIssueMetadataForProjects([projectKey], this.state.instanceID as string, this.getChannelId()).then(({data, error}) => {
             const state = {
                 fetchingIssueMetadata: false,
                 error: null,
@@ -141,6 +152,7 @@ export default class CreateIssueForm extends React.PureComponent<Props, State> {
             }
 
             this.setState(state);
+            this.applyDefaultIssueType(projectKey, fieldValues.issue_type || '', data);
         });
 
         const fields = {
@@ -154,7 +166,7 @@ export default class CreateIssueForm extends React.PureComponent<Props, State> {
                 id: fieldValues.issue_type,
             };
         } else {
-            const issueTypes = getIssueTypes(this.state.jiraIssueMetadata, projectKey, {includeSubtasks: false});
+            const issueTypes = this.getIssueTypeOptions(projectKey);
             const issueType = issueTypes.length ? issueTypes[0].id : '';
             fields.issuetype = {
                 id: issueType,
@@ -166,6 +178,63 @@ export default class CreateIssueForm extends React.PureComponent<Props, State> {
             issueType: fieldValues.issue_type ? fieldValues.issue_type : '',
             fields,
         });
+        this.applyIssueTemplate(projectKey, fieldValues.issue_type || '');
+    };
+
+    // When no issue type was chosen, the default one of the channel or of the instance is picked, if
+    // the project has it, or else a note asks to choose one. With `--from` that of the template is kept.
+    applyDefaultIssueType = (projectKey: string, issueTypeID: string, metadata: IssueMetadata | null) => {
+        if (!metadata || issueTypeID || this.props.fromKey || this.props.parentKey || projectKey !== this.state.projectKey) {
+            return;
+        }
+        if (metadata.default_issue_type_note) {
+            this.setState({issueTypeNote: metadata.default_issue_type_note});
+            return;
+        }
+
+        const issueType = metadata.default_issue_type_id;
+        if (!issueType || !getIssueTypes(metadata, projectKey, {includeSubtasks: false}).find((it) => it.id === issueType)) {
+            return;
+        }
+        this.setState({
+            issueType,
+            fields: {...this.state.fields, issuetype: {id: issueType}},
+        });
+    };
+
+    // With `--from`, the fields of the template issue that the create screen of the project has are
+    // pre-filled, with the issue type of the template unless one was chosen. The description of the
+    // command, when given, is kept over that of the template.
+    applyIssueTemplate = (projectKey: string, issueTypeID: string) => {
+        if (!this.props.fromKey || !projectKey) {
+            return;
+        }
+
+        this.props.fetchIssueTemplate(this.state.instanceID as string, this.props.fromKey, projectKey, issueTypeID).then(({data, error}) => {
+            if (error || !data) {
+                this.setState({templateNote: `Failed to copy the fields of ${this.props.fromKey}: ${error ? error.message : 'not found'}`});
+                return;
+            }
+            if (projectKey !== this.state.projectKey) {
+                return;
+            }
+
+            const issueType = this.state.issueType || data.issue_type_id;
+            const fields = {
+                ...data.fields,
+                project: {key: projectKey},
+                issuetype: {id: issueType},
+            } as CreateIssueFields;
+            if (this.props.description) {
+                fields.description = this.props.description;
+            }
+
+            let templateNote = `Pre-filled from ${data.issue_key}.`;
+            if (data.dropped.length) {
+                templateNote += ` These fields are not on the create screen of this project and were not copied: ${data.dropped.join(', ')}.`;
+            }
+            this.setState({issueType, fields, templateNote});
+        });
     };
 
     handleProjectFetchError = (error: string) => {
@@ -184,6 +253,15 @@ export default class CreateIssueForm extends React.PureComponent<Props, State> {
             issueType,
             fields,
         });
+        this.applyIssueTemplate(this.state.projectKey as string, issueType);
+    };
+
+    // When creating a sub-task of a parent issue, only the sub-task issue types can be chosen.
+    getIssueTypeOptions = (projectKey: string | null) => {
+        if (this.props.parentKey) {
+            return getIssueTypes(this.state.jiraIssueMetadata, projectKey, {includeSubtasks: true}).filter((it) => it.subtask);
+        }
+        return getIssueTypes(this.state.jiraIssueMetadata, projectKey, {includeSubtasks: false});
     };
 
     handleFieldChange = (key: string, value: JiraField) => {
@@ -225,6 +303,13 @@ export default class CreateIssueForm extends React.PureComponent<Props, State> {
         return fieldsNotCovered;
     };
 
+    getChannelId = () => {
+        if (this.props.post) {
+            return this.props.post.channel_id;
+        }
+        return this.props.channelId || '';
+    };
+
     handleSubmit = (e?: React.FormEvent) => {
         if (e && e.preventDefault) {
             e.preventDefault();
@@ -236,21 +321,31 @@ export default class CreateIssueForm extends React.PureComponent<Props, State> {
 
         const {post} = this.props;
         const postId = post ? post.id : '';
+        const channelId = this.getChannelId();
 
-        let channelId = this.props.channelId;
-        if (post) {
-            channelId = post.channel_id;
+        const fields = {...this.state.fields};
+        if (this.props.parentKey) {
+            fields.parent = {key: this.props.parentKey};
         }
 
         const requiredFieldsNotCovered = this.getFieldsNotCovered();
-        const issue = {
+        const issue: CreateIssueRequest = {
             post_id: postId,
             current_team: this.props.currentTeam.name,
-            fields: this.state.fields,
-            channel_id: channelId as string,
+            fields,
+            channel_id: channelId,
             instance_id: this.state.instanceID as string,
             required_fields_not_covered: requiredFieldsNotCovered,
         };
+        if (this.props.assigneeUsername) {
+            issue.assignee_mattermost_username = this.props.assigneeUsername;
+        }
+        if (this.props.sprint) {
+            issue.sprint = this.props.sprint;
+        }
+        if (this.props.reporter) {
+            issue.reporter = this.props.reporter;
+        }
 
         this.setState({submitting: true});
         this.props.create(issue).then(({error}) => {
@@ -268,7 +363,7 @@ export default class CreateIssueForm extends React.PureComponent<Props, State> {
     };
 
     renderForm = () => {
-        const issueTypes = getIssueTypes(this.state.jiraIssueMetadata, this.state.projectKey, {includeSubtasks: false});
+        const issueTypes = this.getIssueTypeOptions(this.state.projectKey);
         const issueOptions = issueTypes.map((it) => ({label: it.name, value: it.id}));
 
         return (
@@ -365,6 +460,24 @@ export default class CreateIssueForm extends React.PureComponent<Props, State> {
             );
         }
 
+        let templateNote;
+        if (this.state.templateNote) {
+            templateNote = (
+                <p className='alert alert-info'>
+                    {this.state.templateNote}
+                </p>
+            );
+        }
+
+        let issueTypeNote;
+        if (this.state.issueTypeNote) {
+            issueTypeNote = (
+                <p className='alert alert-info'>
+                    {this.state.issueTypeNote}
+                </p>
+            );
+        }
+
         return (
             <form
                 role='form'
@@ -374,6 +487,8 @@ export default class CreateIssueForm extends React.PureComponent<Props, State> {
                     style={style.modalBody}
                 >
                     {error}
+                    {templateNote}
+                    {issueTypeNote}
                     {instanceSelector}
                     {form}
                 </Modal.Body>
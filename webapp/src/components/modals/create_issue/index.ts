@@ -10,6 +10,7 @@ import {getCurrentTeam} from 'mattermost-redux/selectors/entities/teams';
 import {
     closeCreateModal,
     createIssue,
+    fetchIssueTemplate,
     fetchJiraIssueMetadataForProjects,
     redirectConnect,
 } from 'actions';
@@ -20,7 +21,7 @@ import {GlobalState} from 'types/store';
 import CreateIssue from './create_issue_modal';
 
 const mapStateToProps = (state: GlobalState) => {
-    const {postId, description, channelId} = getCreateModal(state);
+    const {postId, description, channelId, parentKey, assigneeUsername, sprint, fromKey, reporter} = getCreateModal(state);
     const post = (postId) ? getPost(state, postId) : null;
     const currentTeam = getCurrentTeam(state);
 
@@ -29,6 +30,11 @@ const mapStateToProps = (state: GlobalState) => {
         post,
         description,
         channelId,
+        parentKey,
+        assigneeUsername,
+        sprint,
+        fromKey,
+        reporter,
         currentTeam,
     };
 };
@@ -36,6 +42,7 @@ const mapStateToProps = (state: GlobalState) => {
 const mapDispatchToProps = (dispatch) => bindActionCreators({
     close: closeCreateModal,
     create: createIssue,
+    fetchIssueTemplate,
     fetchJiraIssueMetadataForProjects,
     redirectConnect,
 }, dispatch);
@@ -0,0 +1,171 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React, {PureComponent} from 'react';
+import {Modal} from 'react-bootstrap';
+
+import {Post} from 'mattermost-redux/types/posts';
+import {Theme} from 'mattermost-redux/types/preferences';
+
+import {APIResponse, CreateSubtasksRequest, CreateSubtasksResponse, SavedFieldValues} from 'types/model';
+
+import {getChecklistItems} from 'utils/checklist';
+import {getModalStyles} from 'utils/styles';
+
+import FormButton from 'components/form_button';
+import Input from 'components/input';
+import JiraIssueSelector from 'components/jira_issue_selector';
+import Validator from 'components/validator';
+
+import JiraInstanceAndProjectSelector from 'components/jira_instance_and_project_selector';
+
+export type Props = {
+    close: () => void;
+    createSubtasks: (payload: CreateSubtasksRequest) => Promise<APIResponse<CreateSubtasksResponse>>;
+    post: Post;
+    theme: Theme;
+}
+
+type State = {
+    submitting: boolean;
+    issueKey: string | null;
+    error: string | null;
+    instanceID: string;
+}
+
+export default class CreateSubtasksForm extends PureComponent<Props, State> {
+    private validator = new Validator();
+    state = {
+        submitting: false,
+        issueKey: null,
+        error: null,
+        instanceID: '',
+    } as State;
+
+    handleSubmit = (e: React.FormEvent) => {
+        if (e && e.preventDefault) {
+            e.preventDefault();
+        }
+
+        if (!this.validator.validate()) {
+            return;
+        }
+
+        const payload = {
+            post_id: this.props.post.id,
+            instance_id: this.state.instanceID,
+            parent_issue_key: this.state.issueKey as string,
+        };
+
+        this.setState({submitting: true});
+        this.props.createSubtasks(payload).then(({data, error}) => {
+            if (error) {
+                this.setState({error: error.message, submitting: false});
+                return;
+            }
+
+            // The created and failed sub-tasks are reported in an ephemeral
+            // post; keep the form open when none could be created.
+            if (data && !data.created.length && data.failed.length) {
+                this.setState({error: `Failed to create the sub-tasks: ${data.failed[0].error}`, submitting: false});
+                return;
+            }
+
+            this.handleClose();
+        });
+    };
+
+    handleClose = (e?: Event) => {
+        if (e && e.preventDefault) {
+            e.preventDefault();
+        }
+
+        this.props.close();
+    };
+
+    handleIssueKeyChange = (issueKey: string) => {
+        this.setState({issueKey});
+    };
+
+    render() {
+        const {theme} = this.props;
+        const {error, submitting} = this.state;
+        const style = getModalStyles(theme);
+        const items = getChecklistItems(this.props.post.message);
+
+        const instanceSelector = (
+            <JiraInstanceAndProjectSelector
+                selectedInstanceID={this.state.instanceID}
+                selectedProjectID={''}
+                hideProjectSelector={true}
+                onInstanceChange={(instanceID: string) => this.setState({instanceID})}
+                onProjectChange={(savedValues: SavedFieldValues) => {}}
+                theme={this.props.theme}
+                addValidate={this.validator.addComponent}
+                removeValidate={this.validator.removeComponent}
+                onError={(err: string) => this.setState({error: err})}
+            />
+        );
+
+        let form;
+        if (this.state.instanceID) {
+            form = (
+                <div>
+                    <JiraIssueSelector
+                        addValidate={this.validator.addComponent}
+                        removeValidate={this.validator.removeComponent}
+                        onChange={this.handleIssueKeyChange}
+                        required={true}
+                        theme={theme}
+                        error={error}
+                        value={this.state.issueKey}
+                        instanceID={this.state.instanceID}
+                    />
+                    <Input
+                        addValidate={this.validator.addComponent}
+                        removeValidate={this.validator.removeComponent}
+                        label={`Sub-tasks to Create (${items.length})`}
+                        type='textarea'
+                        isDisabled={true}
+                        value={items.join('\n')}
+                        disabled={false}
+                        readOnly={true}
+                    />
+                </div>
+            );
+        }
+
+        const disableSubmit = !(this.state.instanceID && this.state.issueKey && items.length);
+        return (
+            <form
+                role='form'
+                onSubmit={this.handleSubmit}
+            >
+                <Modal.Body
+                    style={style.modalBody}
+                >
+                    {instanceSelector}
+                    {form}
+                </Modal.Body>
+                <Modal.Footer style={style.modalFooter}>
+                    <FormButton
+                        type='button'
+                        btnClass='btn-link'
+                        defaultMessage='Cancel'
+                        onClick={this.handleClose}
+                    />
+                    <FormButton
+                        type='submit'
+                        btnClass='btn btn-primary'
+                        saving={submitting}
+                        defaultMessage='Create'
+                        savingMessage='Creating'
+                        disabled={disableSubmit}
+                    >
+                        {'Create'}
+                    </FormButton>
+                </Modal.Footer>
+            </form>
+        );
+    }
+}
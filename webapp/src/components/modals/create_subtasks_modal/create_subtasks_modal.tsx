@@ -0,0 +1,39 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import React from 'react';
+import {Modal} from 'react-bootstrap';
+
+import {Theme} from 'mattermost-redux/types/preferences';
+
+import CreateSubtasksForm, {Props as FormProps} from './create_subtasks_form';
+
+type Props = FormProps & {
+    visible: boolean;
+    theme: Theme;
+}
+
+export default function CreateSubtasksModal(props: Props) {
+    const {visible} = props;
+    if (!visible) {
+        return null;
+    }
+
+    return (
+        <Modal
+            dialogClassName='modal--scroll'
+            show={visible}
+            onHide={props.close}
+            onExited={props.close}
+            bsSize='large'
+            backdrop='static'
+        >
+            <Modal.Header closeButton={true}>
+                <Modal.Title>
+                    {'Create Jira Sub-tasks from Checklist'}
+                </Modal.Title>
+            </Modal.Header>
+            <CreateSubtasksForm {...props}/>
+        </Modal>
+    );
+}
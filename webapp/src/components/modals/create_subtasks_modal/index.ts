@@ -0,0 +1,31 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import {connect} from 'react-redux';
+import {bindActionCreators} from 'redux';
+
+import {getPost} from 'mattermost-redux/selectors/entities/posts';
+
+import {closeCreateSubtasksModal, createSubtasksFromPost} from 'actions';
+import {getCreateSubtasksModalForPostId, isCreateSubtasksModalVisible} from 'selectors';
+
+import {GlobalState} from 'types/store';
+
+import CreateSubtasksModal from './create_subtasks_modal';
+
+const mapStateToProps = (state: GlobalState) => {
+    const postId = getCreateSubtasksModalForPostId(state);
+    const post = getPost(state, postId);
+
+    return {
+        visible: isCreateSubtasksModalVisible(state),
+        post,
+    };
+};
+
+const mapDispatchToProps = (dispatch) => bindActionCreators({
+    close: closeCreateSubtasksModal,
+    createSubtasks: createSubtasksFromPost,
+}, dispatch);
+
+export default connect(mapStateToProps, mapDispatchToProps)(CreateSubtasksModal);
@@ -0,0 +1,31 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import {connect} from 'react-redux';
+
+import {GlobalState} from 'types/store';
+import {getCurrentUserLocale, isUserConnected} from 'selectors';
+
+import CreateSubtasksPostMenuAction from './create_subtasks';
+
+function mapStateToProps(state: GlobalState): {actionText: string} {
+    const locale = getCurrentUserLocale(state);
+    const userConnected = isUserConnected(state);
+
+    if (!userConnected) {
+        return {actionText: ''};
+    }
+
+    let actionText;
+    switch (locale) {
+    case 'es':
+        actionText = 'Crear subtareas desde la lista';
+        break;
+    default:
+        actionText = 'Create Sub-tasks from Checklist';
+    }
+
+    return {actionText};
+}
+
+export default connect(mapStateToProps)(CreateSubtasksPostMenuAction);
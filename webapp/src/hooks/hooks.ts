@@ -27,6 +27,21 @@ const instanceDisconnectCommand = '/jira instance disconnect';
 const subscribeCommand = '/jira subscribe';
 const subscribeEditCommand = '/jira subscribe edit';
 
+// Matches `--parent KEY-123` or `--parent=KEY-123` in the arguments of `/jira create`.
+const parentFlagRegex = /(?:^|\s)--parent(?:=|\s+)([A-Za-z][A-Za-z0-9_]*-\d+)(?=\s|$)/;
+
+// Matches `--assignee @username` or `--assignee=@username` in the arguments of `/jira create`.
+const assigneeFlagRegex = /(?:^|\s)--assignee(?:=|\s+)@?([A-Za-z0-9._-]+)(?=\s|$)/;
+
+// Matches `--sprint current`, `--sprint 42` or `--sprint "Sprint 12"` in the arguments of `/jira create`.
+const sprintFlagRegex = /(?:^|\s)--sprint(?:=|\s+)(?:"([^"]+)"|(\S+))(?=\s|$)/;
+
+// Matches `--from KEY-123` or `--from=KEY-123` in the arguments of `/jira create`.
+const fromFlagRegex = /(?:^|\s)--from(?:=|\s+)([A-Za-z][A-Za-z0-9_]*-\d+)(?=\s|$)/;
+
+// Matches `--reporter @username`, `--reporter jira.user` or `--reporter user@example.com` in the arguments of `/jira create`.
+const reporterFlagRegex = /(?:^|\s)--reporter(?:=|\s+)(@?[A-Za-z0-9._+@-]+)(?=\s|$)/;
+
 export default class Hooks {
     private store: any;
     private settings: any;
@@ -88,7 +103,42 @@ export default class Hooks {
         } else if (message.startsWith(issueCreateCommand)) {
             description = message.slice(issueCreateCommand.length).trim();
         }
-        this.store.dispatch(openCreateModalWithoutPost(description, contextArgs.channel_id));
+
+        let parentKey = '';
+        const parentFlag = description.match(parentFlagRegex);
+        if (parentFlag) {
+            parentKey = parentFlag[1].toUpperCase();
+            description = description.replace(parentFlagRegex, ' ').trim();
+        }
+
+        let assigneeUsername = '';
+        const assigneeFlag = description.match(assigneeFlagRegex);
+        if (assigneeFlag) {
+            assigneeUsername = assigneeFlag[1].toLowerCase();
+            description = description.replace(assigneeFlagRegex, ' ').trim();
+        }
+
+        let sprint = '';
+        const sprintFlag = description.match(sprintFlagRegex);
+        if (sprintFlag) {
+            sprint = (sprintFlag[1] || sprintFlag[2]).trim();
+            description = description.replace(sprintFlagRegex, ' ').trim();
+        }
+
+        let fromKey = '';
+        const fromFlag = description.match(fromFlagRegex);
+        if (fromFlag) {
+            fromKey = fromFlag[1].toUpperCase();
+            description = description.replace(fromFlagRegex, ' ').trim();
+        }
+
+        let reporter = '';
+        const reporterFlag = description.match(reporterFlagRegex);
+        if (reporterFlag) {
+            reporter = reporterFlag[1];
+            description = description.replace(reporterFlagRegex, ' ').trim();
+        }
+        this.store.dispatch(openCreateModalWithoutPost(description, contextArgs.channel_id, parentKey, assigneeUsername, sprint, fromKey, reporter));
         return Promise.resolve({});
     };
 
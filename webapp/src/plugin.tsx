@@ -17,10 +17,13 @@ import ChannelSubscriptionsModal from 'components/modals/channel_subscriptions';
 
 import AttachCommentToIssuePostMenuAction from 'components/post_menu_actions/attach_comment_to_issue';
 import AttachCommentToIssueModal from 'components/modals/attach_comment_modal';
+import CreateSubtasksPostMenuAction from 'components/post_menu_actions/create_subtasks';
+import CreateSubtasksModal from 'components/modals/create_subtasks_modal';
 import SetupUI from 'components/setup_ui';
 import LinkTooltip from 'components/jira_ticket_tooltip';
 import {canUserConnect, getInstalledInstances, isUserConnected} from 'selectors';
 import {isCombinedUserActivityPost} from 'utils/posts';
+import {getChecklistItems} from 'utils/checklist';
 import {GlobalState} from 'types/store';
 
 import manifest from './manifest';
@@ -34,6 +37,7 @@ import {
     handleInstanceStatusChange,
     openAttachCommentToIssueModal,
     openCreateModal,
+    openCreateSubtasksModal,
 } from './actions';
 
 import Hooks from './hooks/hooks';
@@ -109,6 +113,27 @@ const setupUILater = (registry: any, store: Store<object, Action<object>>): () =
                     return !systemMessage && userConnected;
                 },
             });
+            registry.registerRootComponent(CreateSubtasksModal);
+            registry.registerPostDropdownMenuAction({
+                text: CreateSubtasksPostMenuAction,
+                action: (postId: string) => {
+                    const state = store.getState() as GlobalState;
+                    if (!isUserConnected(state)) {
+                        return;
+                    }
+
+                    store.dispatch<any>(openCreateSubtasksModal(postId));
+                },
+                filter: (postId: string): boolean => {
+                    const state = store.getState() as GlobalState;
+                    const post = getPost(state, postId);
+                    const oldSystemMessageOrNull = post ? isSystemMessage(post) : true;
+                    const systemMessage = isCombinedUserActivityPost(post) || oldSystemMessageOrNull;
+                    const userConnected = isUserConnected(state);
+
+                    return !systemMessage && userConnected && getChecklistItems(post.message).length > 0;
+                },
+            });
             registry.registerLinkTooltipComponent(LinkTooltip);
         }
 
@@ -123,6 +123,11 @@ const createModal = (state = '', action = {} as AnyAction) => {
             postId: action.data.postId,
             description: action.data.description,
             channelId: action.data.channelId,
+            parentKey: action.data.parentKey,
+            assigneeUsername: action.data.assigneeUsername,
+            sprint: action.data.sprint,
+            fromKey: action.data.fromKey,
+            reporter: action.data.reporter,
         };
     case ActionTypes.CLOSE_CREATE_ISSUE_MODAL:
         return {};
@@ -153,6 +158,28 @@ const attachCommentToIssueModalForPostId = (state = '', action = {} as AnyAction
     }
 };
 
+const createSubtasksModalVisible = (state = false, action = {} as AnyAction) => {
+    switch (action.type) {
+    case ActionTypes.OPEN_CREATE_SUBTASKS_MODAL:
+        return true;
+    case ActionTypes.CLOSE_CREATE_SUBTASKS_MODAL:
+        return false;
+    default:
+        return state;
+    }
+};
+
+const createSubtasksModalForPostId = (state = '', action = {} as AnyAction) => {
+    switch (action.type) {
+    case ActionTypes.OPEN_CREATE_SUBTASKS_MODAL:
+        return action.data.postId;
+    case ActionTypes.CLOSE_CREATE_SUBTASKS_MODAL:
+        return '';
+    default:
+        return state;
+    }
+};
+
 const channelIdWithSettingsOpen = (state = '', action = {} as AnyAction) => {
     switch (action.type) {
     case ActionTypes.OPEN_CHANNEL_SETTINGS:
@@ -279,6 +306,8 @@ export default combineReducers({
     createModal,
     attachCommentToIssueModalVisible,
     attachCommentToIssueModalForPostId,
+    createSubtasksModalVisible,
+    createSubtasksModalForPostId,
     channelIdWithSettingsOpen,
     subscriptionTemplates,
     subscriptionTemplatesForProjectKey,
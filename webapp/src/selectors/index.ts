@@ -50,6 +50,10 @@ export const isAttachCommentToIssueModalVisible = (state: GlobalState) => getPlu
 
 export const getAttachCommentToIssueModalForPostId = (state: GlobalState) => getPluginState(state).attachCommentToIssueModalForPostId;
 
+export const isCreateSubtasksModalVisible = (state: GlobalState) => getPluginState(state).createSubtasksModalVisible;
+
+export const getCreateSubtasksModalForPostId = (state: GlobalState) => getPluginState(state).createSubtasksModalForPostId;
+
 export const getChannelIdWithSettingsOpen = (state: GlobalState) => getPluginState(state).channelIdWithSettingsOpen;
 
 export const getChannelSubscriptions = (state: GlobalState) => getPluginState(state).channelSubscriptions;
@@ -30,7 +30,15 @@ export const ticketData = (assigneeName: string | null): IssueAction => ({
             },
             issuetype: {
                 iconUrl: 'https://something.atlassian.net/issuetype.png',
+                name: 'Bug',
             },
+            priority: {
+                name: 'High',
+            },
+            reporter: {
+                displayName: 'Reporter Name',
+            },
+            created: '2024-03-10T12:00:00.000+0000',
         },
     },
     type: 'mockType',
@@ -77,6 +77,8 @@ export type IssueTypeWithStatuses = {
 export type IssueMetadata = {
     projects: Project[];
     issue_types_with_statuses: IssueTypeWithStatuses[];
+    default_issue_type_id?: string;
+    default_issue_type_note?: string;
 }
 
 export type Status = {
@@ -166,6 +168,7 @@ export type ChannelSubscriptionFilters = {
     events: string[];
     issue_types: string[];
     fields: FilterValue[];
+    jql?: string;
 };
 
 export type ChannelSubscription = {
@@ -174,6 +177,9 @@ export type ChannelSubscription = {
     filters: ChannelSubscriptionFilters;
     name: string;
     instance_id: string;
+    render_style?: string;
+    title_link?: string;
+    link_types?: string[];
 }
 
 export type SubscriptionTemplate = ChannelSubscription
@@ -213,6 +219,16 @@ export type CreateIssueRequest = {
     current_team: string;
     channel_id: string;
     fields: {};
+    assignee_mattermost_username?: string;
+    sprint?: string;
+    reporter?: string;
+};
+
+export type IssueTemplate = {
+    issue_key: string;
+    issue_type_id: string;
+    fields: {[key: string]: JiraField};
+    dropped: string[];
 };
 
 export type SearchIssueParams = {
@@ -241,6 +257,17 @@ export type AttachCommentRequest = {
     instance_id: string;
 };
 
+export type CreateSubtasksRequest = {
+    post_id: string;
+    instance_id: string;
+    parent_issue_key: string;
+};
+
+export type CreateSubtasksResponse = {
+    created: string[];
+    failed: {summary: string; error: string}[];
+};
+
 export type AllProjectMetadata = {
     instance_id: string;
     metadata: ProjectMetadata;
@@ -14,6 +14,10 @@ export type TicketDetails = {
     versions: string;
     statusKey: string;
     issueIcon: string;
+    issueType: string;
+    priority: string;
+    reporterName: string;
+    created: string;
 }
 
 export type TicketData = {
@@ -33,7 +37,10 @@ export type TicketDataFields = {
     project: {avatarUrls: AvatarUrls};
     versions: string[];
     status: {name: string};
-    issuetype: {iconUrl: string};
+    issuetype: {iconUrl: string; name?: string};
+    priority?: {name: string} | null;
+    reporter?: JiraUser | null;
+    created?: string;
 }
 
 export type IssueAction = {
@@ -0,0 +1,16 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+import {getChecklistItems} from './checklist';
+
+describe('utils/checklist', () => {
+    test('should return the list items of a message', () => {
+        const message = 'Release plan:\n- [ ] Freeze the branch\n- [x] Write the release notes\n* Tag the release\n1. Announce it\n-not an item';
+        expect(getChecklistItems(message)).toEqual(['Freeze the branch', 'Write the release notes', 'Tag the release', 'Announce it']);
+    });
+
+    test('should return no items without a list', () => {
+        expect(getChecklistItems('No list here')).toEqual([]);
+        expect(getChecklistItems('')).toEqual([]);
+    });
+});
@@ -0,0 +1,17 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+// Matches the items of a Markdown task or bullet list, like the server does
+// when it creates the sub-tasks of a checklist.
+const checklistItemRegex = /^\s*(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?(.+?)\s*$/;
+
+export function getChecklistItems(message: string): string[] {
+    if (!message) {
+        return [];
+    }
+
+    return message.split('\n')
+        .map((line) => checklistItemRegex.exec(line))
+        .filter((match): match is RegExpExecArray => Boolean(match))
+        .map((match) => match[1]);
+}
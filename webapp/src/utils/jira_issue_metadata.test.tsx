@@ -702,6 +702,10 @@ describe('utils/jira_issue_metadata', () => {
                 versions: 'Version 1.0',
                 statusKey: 'In Progress',
                 issueIcon: 'https://something.atlassian.net/issuetype.png',
+                issueType: 'Bug',
+                priority: 'High',
+                reporterName: 'Reporter Name',
+                created: '2024-03-10T12:00:00.000+0000',
             };
 
             const result = getJiraTicketDetails(action.data);
@@ -723,6 +727,10 @@ describe('utils/jira_issue_metadata', () => {
                 versions: 'Version 1.0',
                 statusKey: 'In Progress',
                 issueIcon: 'https://something.atlassian.net/issuetype.png',
+                issueType: 'Bug',
+                priority: 'High',
+                reporterName: 'Reporter Name',
+                created: '2024-03-10T12:00:00.000+0000',
             };
 
             const result = getJiraTicketDetails(action.data);
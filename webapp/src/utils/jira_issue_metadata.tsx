@@ -521,6 +521,10 @@ export function getJiraTicketDetails(data?: TicketData): TicketDetails | null {
         versions: data.fields && data.fields.versions && data.fields.versions.length ? data.fields.versions[0] : '',
         statusKey: data.fields && data.fields.status && data.fields.status.name,
         issueIcon: data.fields && data.fields.issuetype && data.fields.issuetype.iconUrl,
+        issueType: data.fields?.issuetype?.name || '',
+        priority: data.fields?.priority?.name || '',
+        reporterName: data.fields?.reporter?.displayName || '',
+        created: data.fields?.created || '',
     };
     return ticketDetails;
 }